@@ -1,6 +1,9 @@
 package config
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/spf13/viper"
 )
 
@@ -48,6 +51,31 @@ type Config struct {
 		FlushSize   int `yaml:"flushSize"`   // Size at which to flush batch immediately
 		FlushBytes  int `yaml:"flushBytes"`  // Bytes at which to flush batch immediately
 	} `yaml:"eventBatch"`
+	Experiment struct {
+		FinishPausedOnEnd bool `yaml:"finishPausedOnEnd"` // Allow paused experiments to be finished once their end date has passed
+	} `yaml:"experiment"`
+	SDK struct {
+		APIKey        string `yaml:"apiKey"`        // API key used by the embedded SDK client to call the /api/v1/sdk endpoints
+		RequireAPIKey bool   `yaml:"requireApiKey"` // Reject /api/v1/sdk requests without an API key (default false); keys that are sent are validated either way
+		Stream        struct {
+			PostgresNotify bool `yaml:"postgresNotify"` // Fan SDK change events out through Postgres LISTEN/NOTIFY so every API replica streams them
+		} `yaml:"stream"`
+		RateLimit struct {
+			RequestsPerSecond float64 `yaml:"requestsPerSecond"` // Sustained request rate allowed per client on the /api/v1/sdk endpoints; 0 disables rate limiting
+			Burst             int     `yaml:"burst"`             // Requests a client may send at once above the sustained rate; 0 allows one second worth of requests
+			ByAPIKey          bool    `yaml:"byApiKey"`          // Limit each API key instead of each client IP, e.g. when clients share an egress IP; rejected keys still count against their IP
+		} `yaml:"rateLimit"`
+	} `yaml:"sdk"`
+	Admin struct {
+		Emails []string `yaml:"emails"` // List of user emails granted the admin role
+	} `yaml:"admin"`
+	Webhook struct {
+		URL    string   `yaml:"url"`    // Endpoint notified of experiment status changes; empty disables notifications
+		Events []string `yaml:"events"` // Experiment statuses to notify, e.g. ["schedule", "abort", "finish"]; empty notifies every change
+	} `yaml:"webhook"`
+	Events struct {
+		RetentionDays int `yaml:"retentionDays"` // Evaluation events older than this are pruned; 0 keeps them forever
+	} `yaml:"events"`
 }
 
 func Load(configPath string) (*Config, error) {
@@ -66,6 +94,24 @@ func Load(configPath string) (*Config, error) {
 	return cfg, nil
 }
 
+// IsAdmin reports whether the given email belongs to an admin user
+func (c *Config) IsAdmin(email string) bool {
+	for _, adminEmail := range c.Admin.Emails {
+		if strings.EqualFold(adminEmail, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifiesExperimentStatus reports whether the webhook is notified when an experiment moves to status
+func (c *Config) NotifiesExperimentStatus(status string) bool {
+	if c.Webhook.URL == "" {
+		return false
+	}
+	return len(c.Webhook.Events) == 0 || slices.Contains(c.Webhook.Events, status)
+}
+
 func (c *Config) IsDevelopment() bool {
 	return c.Service.Env == "development"
 }
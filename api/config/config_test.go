@@ -0,0 +1,19 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifiesExperimentStatus(t *testing.T) {
+	var cfg Config
+	require.False(t, cfg.NotifiesExperimentStatus("finish"))
+
+	cfg.Webhook.URL = "https://hooks.example.com/aurora"
+	require.True(t, cfg.NotifiesExperimentStatus("finish"))
+
+	cfg.Webhook.Events = []string{"schedule", "abort"}
+	require.True(t, cfg.NotifiesExperimentStatus("abort"))
+	require.False(t, cfg.NotifiesExperimentStatus("finish"))
+}
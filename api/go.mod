@@ -12,7 +12,7 @@ require (
 	github.com/jackc/pgx/v5 v5.7.5
 	github.com/lib/pq v1.10.9
 	github.com/riverqueue/river v0.24.0
-	github.com/riverqueue/river/riverdriver/riverpgxv5 v0.24.0
+	github.com/riverqueue/river/riverdriver/riverdatabasesql v0.24.0
 	github.com/riverqueue/river/rivertype v0.24.0
 	github.com/rs/zerolog v1.34.0
 	github.com/spf13/viper v1.20.1
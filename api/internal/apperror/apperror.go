@@ -0,0 +1,160 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kind classifies an application error so transport layers can map it to a status code
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindBadRequest
+	KindUnauthorized
+	KindForbidden
+	KindTooManyRequests
+)
+
+// Error is an error with a kind and a message that is safe to return to clients
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+// Sentinel errors for use with errors.Is
+var (
+	ErrNotFound     = &Error{Kind: KindNotFound, Message: "not found"}
+	ErrConflict     = &Error{Kind: KindConflict, Message: "conflict"}
+	ErrBadRequest   = &Error{Kind: KindBadRequest, Message: "bad request"}
+	ErrUnauthorized = &Error{Kind: KindUnauthorized, Message: "unauthorized"}
+	ErrForbidden    = &Error{Kind: KindForbidden, Message: "forbidden"}
+	// ErrTooManyRequests matches the errors of callers over a rate limit
+	ErrTooManyRequests = &Error{Kind: KindTooManyRequests, Message: "too many requests"}
+
+	// ErrValidation matches the same errors as ErrBadRequest; validation failures are bad requests
+	ErrValidation = ErrBadRequest
+)
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an application error of the same kind
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind
+}
+
+// newf builds an error of the given kind; a %w verb in format keeps the wrapped error reachable
+func newf(kind Kind, format string, args ...any) error {
+	wrapped := fmt.Errorf(format, args...)
+	return &Error{Kind: kind, Message: wrapped.Error(), Err: errors.Unwrap(wrapped)}
+}
+
+// NotFound returns an error for a missing resource
+func NotFound(format string, args ...any) error {
+	return newf(KindNotFound, format, args...)
+}
+
+// Conflict returns an error for a request that conflicts with the current state
+func Conflict(format string, args ...any) error {
+	return newf(KindConflict, format, args...)
+}
+
+// BadRequest returns an error for invalid input
+func BadRequest(format string, args ...any) error {
+	return newf(KindBadRequest, format, args...)
+}
+
+// Unauthorized returns an error for a caller that could not be authenticated
+func Unauthorized(format string, args ...any) error {
+	return newf(KindUnauthorized, format, args...)
+}
+
+// Forbidden returns an error for a caller that is not allowed to perform the operation
+func Forbidden(format string, args ...any) error {
+	return newf(KindForbidden, format, args...)
+}
+
+// TooManyRequests returns an error for a caller that is over a rate limit
+func TooManyRequests(format string, args ...any) error {
+	return newf(KindTooManyRequests, format, args...)
+}
+
+// KindOf returns the kind of the first application error in err's chain, or KindInternal
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return KindInternal
+}
+
+// ExperimentConflict describes an existing experiment that conflicts with the one being created or approved
+type ExperimentConflict struct {
+	ID        int
+	Name      string
+	Status    string
+	SegmentID int
+	StartDate int64
+	EndDate   int64
+	// OverlapStart and OverlapEnd bound the period during which both experiments run
+	OverlapStart int64
+	OverlapEnd   int64
+}
+
+// ExperimentConflictError is a conflict error listing the experiments that conflict with an experiment
+type ExperimentConflictError struct {
+	Conflicts []ExperimentConflict
+}
+
+func (e *ExperimentConflictError) Error() string {
+	details := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		details[i] = fmt.Sprintf("Experiment '%s' (ID: %d, Status: %s, Segment: %d, Period: %d-%d)",
+			conflict.Name, conflict.ID, conflict.Status, conflict.SegmentID, conflict.StartDate, conflict.EndDate)
+	}
+	return fmt.Sprintf("experiment conflicts detected with %d existing experiment(s): [%s]", len(e.Conflicts), strings.Join(details, ", "))
+}
+
+// Unwrap exposes the error as a conflict, so KindOf and errors.Is treat it like any other conflict
+func (e *ExperimentConflictError) Unwrap() error {
+	return &Error{Kind: KindConflict, Message: e.Error()}
+}
+
+// ConditionProblem describes a rule condition that is invalid for the attribute it references
+type ConditionProblem struct {
+	Rule        string
+	AttributeID uint
+	Attribute   string
+	Operator    string
+	Value       string
+	Message     string
+}
+
+// ConditionValidationError is a bad request error listing every invalid condition of a request
+type ConditionValidationError struct {
+	Problems []ConditionProblem
+}
+
+func (e *ConditionValidationError) Error() string {
+	details := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		details[i] = fmt.Sprintf("rule '%s' condition on '%s': %s", problem.Rule, problem.Attribute, problem.Message)
+	}
+	return fmt.Sprintf("invalid conditions: %s", strings.Join(details, "; "))
+}
+
+// Unwrap exposes the error as a bad request, so KindOf and errors.Is treat it like any other bad request
+func (e *ConditionValidationError) Unwrap() error {
+	return &Error{Kind: KindBadRequest, Message: e.Error()}
+}
@@ -0,0 +1,28 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cause := errors.New("connection reset")
+	err := fmt.Errorf("failed to load: %w", NotFound("segment with ID %d not found: %w", 7, cause))
+
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.False(t, errors.Is(err, ErrConflict))
+	require.True(t, errors.Is(err, cause))
+	require.Equal(t, KindNotFound, KindOf(err))
+	require.Equal(t, "failed to load: segment with ID 7 not found: connection reset", err.Error())
+
+	var appErr *Error
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, KindNotFound, appErr.Kind)
+
+	require.Equal(t, KindInternal, KindOf(cause))
+
+	require.True(t, errors.Is(BadRequest("invalid regex pattern"), ErrValidation))
+}
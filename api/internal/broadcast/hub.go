@@ -0,0 +1,59 @@
+package broadcast
+
+import (
+	"api/internal/dto"
+	"context"
+	"sync"
+)
+
+// subscriberBuffer is the number of events kept for a subscriber that is not reading. Any event
+// makes an SDK client refresh, so events published while the buffer is full are dropped.
+const subscriberBuffer = 16
+
+// Publisher publishes SDK change events
+type Publisher interface {
+	Publish(ctx context.Context, event dto.SDKChangeEvent) error
+}
+
+// Hub fans SDK change events out to the subscribers of this process
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[chan dto.SDKChangeEvent]struct{}
+}
+
+// NewHub creates a hub without subscribers
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[chan dto.SDKChangeEvent]struct{})}
+}
+
+// Subscribe returns a channel receiving the events published from now on, and a function
+// that unsubscribes and closes the channel
+func (h *Hub) Subscribe() (<-chan dto.SDKChangeEvent, func()) {
+	events := make(chan dto.SDKChangeEvent, subscriberBuffer)
+	h.mu.Lock()
+	h.subscribers[events] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, events)
+			h.mu.Unlock()
+			close(events)
+		})
+	}
+}
+
+// Publish sends the event to every subscriber without waiting for slow ones
+func (h *Hub) Publish(_ context.Context, event dto.SDKChangeEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for events := range h.subscribers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package broadcast
+
+import (
+	"api/internal/dto"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// notifyChannel is the Postgres channel SDK change events are sent on
+const notifyChannel = "aurora_sdk_changes"
+
+// PostgresPublisher publishes SDK change events with NOTIFY, so that the hubs of every API
+// replica listening with Listen receive them
+type PostgresPublisher struct {
+	db *gorm.DB
+}
+
+// NewPostgresPublisher creates a publisher sending notifications through db
+func NewPostgresPublisher(db *gorm.DB) *PostgresPublisher {
+	return &PostgresPublisher{db: db}
+}
+
+// Publish sends the event as a notification on the SDK changes channel
+func (p *PostgresPublisher) Publish(ctx context.Context, event dto.SDKChangeEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", notifyChannel, string(payload)).Error
+}
+
+// Listen forwards the events notified on the SDK changes channel to hub until ctx is done.
+// The listener reconnects on its own when the connection is lost.
+func Listen(ctx context.Context, dsn string, hub *Hub, logger zerolog.Logger) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Error().Err(err).Int("event", int(event)).Msg("SDK changes listener connection event")
+		}
+	})
+	if err := listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		return err
+	}
+
+	go func() {
+		defer listener.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case notification := <-listener.Notify:
+				// A nil notification means the connection was re-established; changes may have been missed
+				if notification == nil {
+					hub.Publish(ctx, dto.SDKChangeEvent{Entity: dto.SDKChangeEntityParameter})
+					hub.Publish(ctx, dto.SDKChangeEvent{Entity: dto.SDKChangeEntityExperiment})
+					continue
+				}
+				var event dto.SDKChangeEvent
+				if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+					logger.Error().Err(err).Str("payload", notification.Extra).Msg("Failed to decode SDK change notification")
+					continue
+				}
+				hub.Publish(ctx, event)
+			}
+		}
+	}()
+	return nil
+}
@@ -7,4 +7,16 @@ const (
 	ExperimentStatusFinish   = "finish"
 	ExperimentStatusCancel   = "cancel"
 	ExperimentStatusAbort    = "abort"
+	ExperimentStatusPause    = "pause"
 )
+
+// ExperimentStatuses lists every experiment status
+var ExperimentStatuses = []string{
+	ExperimentStatusDraft,
+	ExperimentStatusSchedule,
+	ExperimentStatusRunning,
+	ExperimentStatusFinish,
+	ExperimentStatusCancel,
+	ExperimentStatusAbort,
+	ExperimentStatusPause,
+}
@@ -9,9 +9,9 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-// NewConnection creates a new database connection
-func NewConnection(cfg *config.Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+// DSN returns the connection string of the configured database
+func DSN(cfg *config.Config) string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
 		cfg.Database.Host,
 		cfg.Database.User,
 		cfg.Database.Password,
@@ -19,6 +19,11 @@ func NewConnection(cfg *config.Config) (*gorm.DB, error) {
 		cfg.Database.Port,
 		cfg.Database.SSLMode,
 	)
+}
+
+// NewConnection creates a new database connection
+func NewConnection(cfg *config.Config) (*gorm.DB, error) {
+	dsn := DSN(cfg)
 
 	// Configure GORM logger based on environment
 	var logLevel logger.LogLevel
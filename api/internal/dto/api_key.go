@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"api/internal/model"
+	"errors"
+	"strings"
+	"time"
+)
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Validate checks that the key has a name and, when it expires, an expiry in the future
+func (r *CreateAPIKeyRequest) Validate(now time.Time) error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(now) {
+		return errors.New("expiresAt must be in the future")
+	}
+	return nil
+}
+
+// APIKeyResponse represents an API key in API responses; the key itself is never included
+type APIKeyResponse struct {
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Prefix          string     `json:"prefix"`
+	CreatedByUserID uint       `json:"createdByUserId"`
+	ExpiresAt       *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt       *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+// CreateAPIKeyResponse is returned when a key is created and is the only response carrying its plaintext
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// ToAPIKeyResponse converts model.APIKey to APIKeyResponse
+func ToAPIKeyResponse(apiKey *model.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:              apiKey.ID,
+		Name:            apiKey.Name,
+		Prefix:          apiKey.Prefix,
+		CreatedByUserID: apiKey.CreatedByUserID,
+		ExpiresAt:       apiKey.ExpiresAt,
+		RevokedAt:       apiKey.RevokedAt,
+		CreatedAt:       apiKey.CreatedAt,
+	}
+}
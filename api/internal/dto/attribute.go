@@ -9,7 +9,7 @@ import (
 type CreateAttributeRequest struct {
 	Name          string         `json:"name" validate:"required"`
 	Description   string         `json:"description" validate:"required"`
-	DataType      model.DataType `json:"dataType" validate:"required,oneof=boolean string number enum"`
+	DataType      model.DataType `json:"dataType" validate:"required,oneof=boolean string number enum date"`
 	HashAttribute *bool          `json:"hashAttribute,omitempty"`
 	EnumOptions   []string       `json:"enumOptions,omitempty"`
 }
@@ -21,6 +21,8 @@ type UpdateAttributeRequest struct {
 	DataType      *model.DataType `json:"dataType,omitempty"`
 	HashAttribute *bool           `json:"hashAttribute,omitempty"`
 	EnumOptions   []string        `json:"enumOptions,omitempty"`
+	// Force deletes the conditions the new definition invalidates instead of rejecting the update
+	Force bool `json:"-"`
 }
 
 // AttributeResponse represents the response for attribute operations
@@ -32,6 +34,7 @@ type AttributeResponse struct {
 	HashAttribute bool           `json:"hashAttribute"`
 	EnumOptions   []string       `json:"enumOptions"`
 	UsageCount    int            `json:"usageCount"`
+	AliasOfID     *uint          `json:"aliasOfId,omitempty"`
 	CreatedAt     time.Time      `json:"createdAt"`
 	UpdatedAt     time.Time      `json:"updatedAt"`
 }
@@ -41,6 +44,26 @@ type AttributeListResponse struct {
 	Attributes []AttributeResponse `json:"attributes"`
 }
 
+// AttributePageResponse represents a page of attributes with pagination info
+type AttributePageResponse struct {
+	Items  []AttributeResponse `json:"items"`
+	Total  int64               `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+// MergeAttributeResponse represents the report of merging an attribute into another
+type MergeAttributeResponse struct {
+	SourceAttributeID     uint   `json:"sourceAttributeId"`
+	TargetAttributeID     uint   `json:"targetAttributeId"`
+	DryRun                bool   `json:"dryRun"`
+	SegmentConditionIDs   []uint `json:"segmentConditionIds"`
+	ParameterConditionIDs []uint `json:"parameterConditionIds"`
+	AffectedSegmentIDs    []uint `json:"affectedSegmentIds"`
+	AffectedParameterIDs  []uint `json:"affectedParameterIds"`
+	AffectedExperimentIDs []int  `json:"affectedExperimentIds"`
+}
+
 // ErrorResponse represents error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -57,6 +80,7 @@ func ToAttributeResponse(attr *model.Attribute) AttributeResponse {
 		HashAttribute: attr.HashAttribute,
 		EnumOptions:   attr.EnumOptions,
 		UsageCount:    attr.UsageCount,
+		AliasOfID:     attr.AliasOfID,
 		CreatedAt:     attr.CreatedAt,
 		UpdatedAt:     attr.UpdatedAt,
 	}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"api/internal/model"
+	"encoding/json"
+	"time"
+)
+
+// AuditLogResponse represents an audit log entry in API responses
+type AuditLogResponse struct {
+	ID          uint            `json:"id"`
+	ActorUserID uint            `json:"actorUserId"`
+	EntityType  string          `json:"entityType"`
+	EntityID    uint            `json:"entityId"`
+	Action      string          `json:"action"`
+	Before      json.RawMessage `json:"before,omitempty"`
+	After       json.RawMessage `json:"after,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// AuditLogPageResponse represents a page of audit log entries with pagination info
+type AuditLogPageResponse struct {
+	Items  []AuditLogResponse `json:"items"`
+	Total  int64              `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+}
+
+// ToAuditLogResponse converts model.AuditLog to AuditLogResponse
+func ToAuditLogResponse(auditLog *model.AuditLog) AuditLogResponse {
+	return AuditLogResponse{
+		ID:          auditLog.ID,
+		ActorUserID: auditLog.ActorUserID,
+		EntityType:  string(auditLog.EntityType),
+		EntityID:    auditLog.EntityID,
+		Action:      string(auditLog.Action),
+		Before:      auditLog.Before,
+		After:       auditLog.After,
+		CreatedAt:   auditLog.CreatedAt,
+	}
+}
@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"api/internal/model"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// environmentKeyPattern restricts environment keys to what SDK clients can pass around in configuration
+var environmentKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// CreateEnvironmentRequest represents the request to create an environment
+type CreateEnvironmentRequest struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// Validate checks that the environment has a name and a key made of lowercase letters, digits, '-' and '_'
+func (r *CreateEnvironmentRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if !environmentKeyPattern.MatchString(r.Key) {
+		return errors.New("key must be 1 to 64 lowercase letters, digits, '-' or '_', starting with a letter or digit")
+	}
+	return nil
+}
+
+// UpdateEnvironmentRequest represents the request to rename an environment. The key cannot change,
+// since deployed SDK clients refer to the environment by it.
+type UpdateEnvironmentRequest struct {
+	Name string `json:"name"`
+}
+
+// Validate checks that the environment keeps a name
+func (r *UpdateEnvironmentRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+// EnvironmentResponse represents an environment in API responses
+type EnvironmentResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ToEnvironmentResponse converts model.Environment to EnvironmentResponse
+func ToEnvironmentResponse(environment *model.Environment) EnvironmentResponse {
+	return EnvironmentResponse{
+		ID:        environment.ID,
+		Name:      environment.Name,
+		Key:       environment.Key,
+		CreatedAt: environment.CreatedAt,
+		UpdatedAt: environment.UpdatedAt,
+	}
+}
+
+// SetParameterEnvironmentOverrideRequest represents the request to override a parameter in an environment.
+// An omitted defaultRolloutValue keeps the base default; omitted rules keep the base rules, while an
+// empty list serves no rules in the environment.
+type SetParameterEnvironmentOverrideRequest struct {
+	DefaultRolloutValue interface{}                  `json:"defaultRolloutValue,omitempty"`
+	Rules               []CreateParameterRuleRequest `json:"rules,omitempty"`
+}
+
+// ParameterEnvironmentOverrideResponse represents the override of a parameter in an environment.
+// DefaultRolloutValue is nil when the base default applies, and Rules are only listed when OverrideRules is set.
+type ParameterEnvironmentOverrideResponse struct {
+	ID                  uint                    `json:"id"`
+	ParameterID         uint                    `json:"parameterId"`
+	EnvironmentID       uint                    `json:"environmentId"`
+	EnvironmentKey      string                  `json:"environmentKey,omitempty"`
+	DefaultRolloutValue interface{}             `json:"defaultRolloutValue"`
+	OverrideRules       bool                    `json:"overrideRules"`
+	Rules               []ParameterRuleResponse `json:"rules"`
+	UpdatedAt           time.Time               `json:"updatedAt"`
+}
+
+// ToParameterEnvironmentOverrideResponse converts model.ParameterEnvironmentOverride to ParameterEnvironmentOverrideResponse
+func ToParameterEnvironmentOverrideResponse(override *model.ParameterEnvironmentOverride) ParameterEnvironmentOverrideResponse {
+	response := ParameterEnvironmentOverrideResponse{
+		ID:            override.ID,
+		ParameterID:   override.ParameterID,
+		EnvironmentID: override.EnvironmentID,
+		OverrideRules: override.OverrideRules,
+		Rules:         make([]ParameterRuleResponse, len(override.Rules)),
+		UpdatedAt:     override.UpdatedAt,
+	}
+	if override.Environment != nil {
+		response.EnvironmentKey = override.Environment.Key
+	}
+	if override.DefaultRolloutValue != nil {
+		response.DefaultRolloutValue = override.DefaultRolloutValue.Data
+	}
+	for i, rule := range override.Rules {
+		response.Rules[i] = ToParameterRuleResponse(&rule)
+	}
+	return response
+}
@@ -1,6 +1,9 @@
 package dto
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 // EventType represents the type of event being tracked
 type EventType string
@@ -12,37 +15,85 @@ const (
 
 // TrackEventRequest represents the request to track an evaluation event
 type TrackEventRequest struct {
-	ID             string                 `json:"id" binding:"required"`
-	ServiceName    string                 `json:"serviceName" binding:"required"`
-	EventType      EventType              `json:"eventType" binding:"required"`
-	ParameterName  string                 `json:"parameterName" binding:"required"`
-	Source         string                 `json:"source" binding:"required"`
-	UserAttributes map[string]interface{} `json:"userAttributes" binding:"required"`
-	RolloutValue   *string                `json:"rolloutValue,omitempty"`
-	Error          *string                `json:"error,omitempty"`
-	Timestamp      time.Time              `json:"timestamp" binding:"required"`
-	ExperimentID   *int                   `json:"experimentId,omitempty"`
-	ExperimentUUID *string                `json:"experimentUuid,omitempty"`
-	VariantID      *int                   `json:"variantId,omitempty"`
-	VariantName    *string                `json:"variantName,omitempty"`
+	ID                string                 `json:"id" binding:"required"`
+	ServiceName       string                 `json:"serviceName" binding:"required"`
+	EventType         EventType              `json:"eventType" binding:"required"`
+	ParameterName     string                 `json:"parameterName" binding:"required"`
+	Source            string                 `json:"source" binding:"required"`
+	UserAttributes    map[string]interface{} `json:"userAttributes" binding:"required"`
+	RolloutValue      *string                `json:"rolloutValue,omitempty"`
+	Error             *string                `json:"error,omitempty"`
+	Timestamp         time.Time              `json:"timestamp" binding:"required"`
+	ExperimentID      *int                   `json:"experimentId,omitempty"`
+	ExperimentUUID    *string                `json:"experimentUuid,omitempty"`
+	VariantID         *int                   `json:"variantId,omitempty"`
+	VariantName       *string                `json:"variantName,omitempty"`
+	VariantOverridden bool                   `json:"variantOverridden,omitempty"`
+}
+
+// Validate checks the fields of an event, which binding does not check for the events of a batch
+func (r *TrackEventRequest) Validate() error {
+	switch {
+	case r.ID == "":
+		return errors.New("id is required")
+	case len(r.ID) > 255:
+		return errors.New("id must be at most 255 characters")
+	case r.ServiceName == "":
+		return errors.New("serviceName is required")
+	case r.EventType != EventTypeParameterEvaluation && r.EventType != EventTypeExperimentEvaluation:
+		return errors.New("eventType must be parameter_evaluation or experiment_evaluation")
+	case r.ParameterName == "":
+		return errors.New("parameterName is required")
+	case r.Source == "":
+		return errors.New("source is required")
+	case r.Timestamp.IsZero():
+		return errors.New("timestamp is required")
+	}
+	return nil
 }
 
+// EventStatus tells what became of a tracked event
+type EventStatus string
+
+const (
+	// EventStatusAccepted means the event was stored
+	EventStatusAccepted EventStatus = "accepted"
+	// EventStatusDuplicate means an event with the same ID was already stored, e.g. by a retried batch
+	EventStatusDuplicate EventStatus = "duplicate"
+	// EventStatusInvalid means the event was rejected and retrying it will not help
+	EventStatusInvalid EventStatus = "invalid"
+)
+
 // TrackEventResponse represents the response after tracking an event
 type TrackEventResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Status  EventStatus `json:"status,omitempty"`
 }
 
+// MaxTrackBatchEvents is the largest number of events a batch may hold
+const MaxTrackBatchEvents = 10000
+
 // TrackBatchEventRequest represents the request to track multiple evaluation events
 type TrackBatchEventRequest struct {
-	Events []TrackEventRequest `json:"events" binding:"required,min=1,max=1000"`
+	Events []TrackEventRequest `json:"events" binding:"required,min=1,max=10000"`
+}
+
+// TrackEventResult reports the status of an event of a batch. Accepted and duplicate events are
+// acknowledged, so senders can drop them.
+type TrackEventResult struct {
+	ID     string      `json:"id"`
+	Status EventStatus `json:"status"`
+	Error  string      `json:"error,omitempty"`
 }
 
 // TrackBatchEventResponse represents the response after tracking batch events
 type TrackBatchEventResponse struct {
-	Success      bool   `json:"success"`
-	Message      string `json:"message"`
-	Processed    int    `json:"processed"`
-	Failed       int    `json:"failed"`
-	FailedEvents []int  `json:"failedEvents,omitempty"` // Indices of failed events
+	Success      bool               `json:"success"`
+	Message      string             `json:"message"`
+	Processed    int                `json:"processed"`              // Accepted events
+	Duplicates   int                `json:"duplicates"`             // Events already stored
+	Failed       int                `json:"failed"`                 // Invalid events
+	FailedEvents []int              `json:"failedEvents,omitempty"` // Indices of failed events
+	Results      []TrackEventResult `json:"results,omitempty"`      // Status of each event, in request order
 }
@@ -2,7 +2,9 @@ package dto
 
 import (
 	"errors"
+	"time"
 
+	"api/internal/apperror"
 	"api/internal/model"
 
 	"github.com/go-playground/validator/v10"
@@ -19,6 +21,10 @@ type CreateExperimentRequest struct {
 	Strategy        string                           `json:"strategy" binding:"required" validate:"required,oneof=percentage_split"`
 	Variants        []CreateExperimentVariantRequest `json:"variants" binding:"required" validate:"required"`
 	SegmentID       int                              `json:"segmentId" `
+	// EnvironmentID limits the experiment to one environment; it runs in every environment when omitted
+	EnvironmentID *int `json:"environmentId,omitempty"`
+	// IdempotencyKey is taken from the Idempotency-Key header
+	IdempotencyKey string `json:"-"`
 }
 
 func (r *CreateExperimentRequest) Validate() error {
@@ -31,18 +37,23 @@ func (r *CreateExperimentRequest) Validate() error {
 		return errors.New("startDate must be before endDate")
 	}
 
-	if len(r.Variants) == 0 {
+	return validateExperimentVariants(r.Variants)
+}
+
+// validateExperimentVariants checks that every variant sets parameters and that the traffic allocations add up to 100
+func validateExperimentVariants(variants []CreateExperimentVariantRequest) error {
+	if len(variants) == 0 {
 		return errors.New("variants must be at least one")
 	}
 
-	for _, variant := range r.Variants {
+	for _, variant := range variants {
 		if len(variant.Parameters) == 0 {
 			return errors.New("parameters must be at least one")
 		}
 	}
 
 	totalTrafficAllocation := 0
-	for _, variant := range r.Variants {
+	for _, variant := range variants {
 		totalTrafficAllocation += variant.TrafficAllocation
 	}
 
@@ -61,12 +72,25 @@ type CreateExperimentVariantRequest struct {
 }
 
 type CreateExperimentVariantParameterRequest struct {
-	ParameterDataType string `json:"parameterDataType" binding:"required" validate:"required,oneof=string number boolean"`
+	ParameterDataType string `json:"parameterDataType" binding:"required" validate:"required,oneof=string number boolean date enum json"`
 	ParameterID       int    `json:"parameterId" binding:"required" validate:"required"`
 	ParameterName     string `json:"parameterName" binding:"required" validate:"required"`
 	RolloutValue      string `json:"rolloutValue" binding:"required" validate:"required"`
 }
 
+// UpdateExperimentVariantsRequest represents the request to replace the variants of a draft experiment
+type UpdateExperimentVariantsRequest struct {
+	Variants []CreateExperimentVariantRequest `json:"variants" binding:"required" validate:"required"`
+}
+
+func (r *UpdateExperimentVariantsRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	return validateExperimentVariants(r.Variants)
+}
+
 // ExperimentResponse represents the response for experiment operations (without variants)
 type ExperimentResponse struct {
 	ID              int    `json:"id"`
@@ -81,8 +105,26 @@ type ExperimentResponse struct {
 	Strategy        string `json:"strategy"`
 	CreatedAt       int64  `json:"createdAt"`
 	UpdatedAt       int64  `json:"updatedAt"`
+	CreatedByUserID *uint  `json:"createdByUserId,omitempty"`
+	UpdatedByUserID *uint  `json:"updatedByUserId,omitempty"`
 	Status          string `json:"status"`
 	SegmentID       int    `json:"segmentId"`
+	EnvironmentID   *int   `json:"environmentId,omitempty"`
+}
+
+// ExperimentSummaryResponse represents an experiment in list responses
+type ExperimentSummaryResponse struct {
+	ExperimentResponse
+	SegmentName  string `json:"segmentName"`
+	VariantCount int    `json:"variantCount"`
+}
+
+// ExperimentPageResponse represents a page of experiments with pagination info
+type ExperimentPageResponse struct {
+	Items  []ExperimentSummaryResponse `json:"items"`
+	Total  int64                       `json:"total"`
+	Limit  int                         `json:"limit"`
+	Offset int                         `json:"offset"`
 }
 
 // HashAttributeResponse represents the hash attribute in experiment responses
@@ -115,23 +157,27 @@ type ExperimentVariantParameterResponse struct {
 
 // ExperimentDetailResponse represents the detailed response for experiment operations (with variants and parameters)
 type ExperimentDetailResponse struct {
-	ID              int                         `json:"id"`
-	Name            string                      `json:"name"`
-	Uuid            string                      `json:"uuid"`
-	Hypothesis      string                      `json:"hypothesis"`
-	Description     string                      `json:"description"`
-	StartDate       int64                       `json:"startDate"`
-	EndDate         int64                       `json:"endDate"`
-	HashAttributeID int                         `json:"hashAttributeId"`
-	HashAttribute   HashAttributeResponse       `json:"hashAttribute"`
-	PopulationSize  int                         `json:"populationSize"`
-	Strategy        string                      `json:"strategy"`
-	CreatedAt       int64                       `json:"createdAt"`
-	UpdatedAt       int64                       `json:"updatedAt"`
-	Status          string                      `json:"status"`
-	SegmentID       int                         `json:"segmentId"`
-	Segment         SegmentResponse             `json:"segment"`
-	Variants        []ExperimentVariantResponse `json:"variants"`
+	ID              int                          `json:"id"`
+	Name            string                       `json:"name"`
+	Uuid            string                       `json:"uuid"`
+	Hypothesis      string                       `json:"hypothesis"`
+	Description     string                       `json:"description"`
+	StartDate       int64                        `json:"startDate"`
+	EndDate         int64                        `json:"endDate"`
+	HashAttributeID int                          `json:"hashAttributeId"`
+	HashAttribute   HashAttributeResponse        `json:"hashAttribute"`
+	PopulationSize  int                          `json:"populationSize"`
+	Strategy        string                       `json:"strategy"`
+	CreatedAt       int64                        `json:"createdAt"`
+	UpdatedAt       int64                        `json:"updatedAt"`
+	CreatedByUserID *uint                        `json:"createdByUserId,omitempty"`
+	UpdatedByUserID *uint                        `json:"updatedByUserId,omitempty"`
+	Status          string                       `json:"status"`
+	SegmentID       int                          `json:"segmentId"`
+	EnvironmentID   *int                         `json:"environmentId,omitempty"`
+	Segment         SegmentResponse              `json:"segment"`
+	Variants        []ExperimentVariantResponse  `json:"variants"`
+	Overrides       []ExperimentOverrideResponse `json:"overrides"`
 }
 
 // ToExperimentResponse converts a model.Experiment to ExperimentResponse
@@ -149,8 +195,20 @@ func ToExperimentResponse(experiment *model.Experiment) ExperimentResponse {
 		Strategy:        experiment.Strategy,
 		CreatedAt:       experiment.CreatedAt,
 		UpdatedAt:       experiment.UpdatedAt,
+		CreatedByUserID: experiment.CreatedByUserID,
+		UpdatedByUserID: experiment.UpdatedByUserID,
 		Status:          experiment.Status,
 		SegmentID:       experiment.SegmentID,
+		EnvironmentID:   experiment.EnvironmentID,
+	}
+}
+
+// ToExperimentSummaryResponse converts a model.ExperimentSummary to ExperimentSummaryResponse
+func ToExperimentSummaryResponse(experiment *model.ExperimentSummary) ExperimentSummaryResponse {
+	return ExperimentSummaryResponse{
+		ExperimentResponse: ToExperimentResponse(&experiment.Experiment),
+		SegmentName:        experiment.SegmentName,
+		VariantCount:       experiment.VariantCount,
 	}
 }
 
@@ -215,10 +273,17 @@ func ToExperimentDetailResponse(experiment *model.Experiment, variants []*model.
 		Strategy:        experiment.Strategy,
 		CreatedAt:       experiment.CreatedAt,
 		UpdatedAt:       experiment.UpdatedAt,
+		CreatedByUserID: experiment.CreatedByUserID,
+		UpdatedByUserID: experiment.UpdatedByUserID,
 		Status:          experiment.Status,
 		SegmentID:       experiment.SegmentID,
+		EnvironmentID:   experiment.EnvironmentID,
 		//Segment:         ToSegmentResponse(experiment.Segment),
-		Variants: variantResponses,
+		Variants:  variantResponses,
+		Overrides: make([]ExperimentOverrideResponse, len(experiment.Overrides)),
+	}
+	for i := range experiment.Overrides {
+		res.Overrides[i] = ToExperimentOverrideResponse(&experiment.Overrides[i])
 	}
 	if experiment.SegmentID > 0 {
 		res.Segment = ToSegmentResponse(experiment.Segment)
@@ -240,3 +305,172 @@ type ApproveExperimentRequest struct {
 type AbortExperimentRequest struct {
 	Reason string `json:"reason,omitempty"` // Optional reason for aborting
 }
+
+// PauseExperimentRequest represents the request to pause a running experiment
+type PauseExperimentRequest struct {
+	Reason string `json:"reason,omitempty"` // Optional reason for pausing
+}
+
+// ResumeExperimentRequest represents the request to resume a paused experiment
+type ResumeExperimentRequest struct {
+	Notes string `json:"notes,omitempty"` // Optional notes for resuming
+}
+
+// SetExperimentOverrideRequest represents the request to force the units whose hash attribute has
+// AttributeValue into a variant of the experiment
+type SetExperimentOverrideRequest struct {
+	AttributeValue string `json:"attributeValue" binding:"required" validate:"required"`
+	VariantID      int    `json:"variantId" binding:"required" validate:"required"`
+}
+
+// ExperimentOverrideResponse represents an experiment override
+type ExperimentOverrideResponse struct {
+	ID             uint      `json:"id"`
+	ExperimentID   int       `json:"experimentId"`
+	AttributeValue string    `json:"attributeValue"`
+	VariantID      int       `json:"variantId"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// ToExperimentOverrideResponse converts a model.ExperimentOverride to ExperimentOverrideResponse
+func ToExperimentOverrideResponse(override *model.ExperimentOverride) ExperimentOverrideResponse {
+	return ExperimentOverrideResponse{
+		ID:             override.ID,
+		ExperimentID:   override.ExperimentID,
+		AttributeValue: override.AttributeValue,
+		VariantID:      override.VariantID,
+		CreatedAt:      override.CreatedAt,
+	}
+}
+
+// SimulateExperimentRequest represents the request to preview the variant assigned to a set of attributes.
+// The experiment is identified by the route, either by ID or by UUID.
+type SimulateExperimentRequest struct {
+	ExperimentID   uint                       `json:"-"`
+	ExperimentUUID string                     `json:"-"`
+	Attributes     []SimulateAttributeRequest `json:"attributes" validate:"required"`
+}
+
+// SimulateExperimentParameterResponse represents a parameter value resolved by the simulated variant
+type SimulateExperimentParameterResponse struct {
+	ParameterID   int    `json:"parameterId"`
+	ParameterName string `json:"parameterName"`
+	DataType      string `json:"dataType"`
+	Value         string `json:"value"`
+}
+
+// SimulateExperimentResponse represents the outcome of a simulated experiment evaluation
+type SimulateExperimentResponse struct {
+	ExperimentID   int                                   `json:"experimentId"`
+	ExperimentUUID string                                `json:"experimentUuid"`
+	InPopulation   bool                                  `json:"inPopulation"`
+	VariantID      *int                                  `json:"variantId,omitempty"`
+	VariantName    *string                               `json:"variantName,omitempty"`
+	Parameters     []SimulateExperimentParameterResponse `json:"parameters"`
+}
+
+// ExperimentVariantAllocationResponse represents the configured traffic share of a variant
+type ExperimentVariantAllocationResponse struct {
+	VariantID         int    `json:"variantId"`
+	VariantName       string `json:"variantName"`
+	TrafficAllocation int    `json:"trafficAllocation"`
+}
+
+// ExperimentExposureDayResponse represents the exposures of a variant on a single day
+type ExperimentExposureDayResponse struct {
+	Date   string `json:"date"`
+	Users  int64  `json:"users"`
+	Events int64  `json:"events"`
+}
+
+// ExperimentVariantExposureResponse represents the observed exposures of a variant over the requested range
+type ExperimentVariantExposureResponse struct {
+	VariantID   int                             `json:"variantId"`
+	VariantName string                          `json:"variantName"`
+	Users       int64                           `json:"users"`
+	Events      int64                           `json:"events"`
+	Daily       []ExperimentExposureDayResponse `json:"daily"`
+}
+
+// ExperimentExposureResponse compares the configured split of an experiment with the exposures
+// observed in the ingested evaluation events. Exposures is empty when no events were recorded.
+type ExperimentExposureResponse struct {
+	ExperimentID   int                                   `json:"experimentId"`
+	PopulationSize int                                   `json:"populationSize"`
+	HashAttribute  string                                `json:"hashAttribute"`
+	Variants       []ExperimentVariantAllocationResponse `json:"variants"`
+	Exposures      []ExperimentVariantExposureResponse   `json:"exposures"`
+}
+
+// ExperimentConflictResponse is the error response listing the experiments an experiment conflicts with
+type ExperimentConflictResponse struct {
+	ErrorResponse
+	Conflicts []ConflictingExperimentResponse `json:"conflicts"`
+}
+
+// ConflictingExperimentResponse describes an experiment that conflicts and the period both experiments run
+type ConflictingExperimentResponse struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	SegmentID    int    `json:"segmentId"`
+	StartDate    int64  `json:"startDate"`
+	EndDate      int64  `json:"endDate"`
+	OverlapStart int64  `json:"overlapStart"`
+	OverlapEnd   int64  `json:"overlapEnd"`
+}
+
+// CheckExperimentConflictsRequest describes an experiment being filled in, to find the experiments it would conflict
+// with before it is submitted. ExcludeExperimentID leaves out the experiment being edited.
+type CheckExperimentConflictsRequest struct {
+	ParameterIDs        []int `json:"parameterIds" validate:"required,min=1"`
+	SegmentID           int   `json:"segmentId"`
+	StartDate           int64 `json:"startDate" validate:"required"`
+	EndDate             int64 `json:"endDate" validate:"required"`
+	ExcludeExperimentID int   `json:"excludeExperimentId"`
+	// EnvironmentID is the environment of the checked experiment, none meaning every environment
+	EnvironmentID *int `json:"environmentId,omitempty"`
+}
+
+func (r *CheckExperimentConflictsRequest) Validate() error {
+	if err := validator.New().Struct(r); err != nil {
+		return err
+	}
+
+	if r.StartDate >= r.EndDate {
+		return errors.New("startDate must be before endDate")
+	}
+
+	return nil
+}
+
+// ExperimentConflictsResponse lists the experiments an experiment would conflict with; Conflicts is empty when there are none
+type ExperimentConflictsResponse struct {
+	Conflicts []ConflictingExperimentResponse `json:"conflicts"`
+}
+
+// ToExperimentConflictResponse converts an experiment conflict error into its response
+func ToExperimentConflictResponse(err *apperror.ExperimentConflictError) ExperimentConflictResponse {
+	return ExperimentConflictResponse{
+		ErrorResponse: ErrorResponse{Error: "Conflict", Message: err.Error()},
+		Conflicts:     ToConflictingExperimentResponses(err.Conflicts),
+	}
+}
+
+// ToConflictingExperimentResponses converts conflicting experiments into their responses
+func ToConflictingExperimentResponses(experimentConflicts []apperror.ExperimentConflict) []ConflictingExperimentResponse {
+	conflicts := make([]ConflictingExperimentResponse, len(experimentConflicts))
+	for i, conflict := range experimentConflicts {
+		conflicts[i] = ConflictingExperimentResponse{
+			ID:           conflict.ID,
+			Name:         conflict.Name,
+			Status:       conflict.Status,
+			SegmentID:    conflict.SegmentID,
+			StartDate:    conflict.StartDate,
+			EndDate:      conflict.EndDate,
+			OverlapStart: conflict.OverlapStart,
+			OverlapEnd:   conflict.OverlapEnd,
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,193 @@
+package dto
+
+import (
+	"api/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PointInTimeParameter represents a parameter configuration as of the requested time
+type PointInTimeParameter struct {
+	ID              uint            `json:"id"`
+	Name            string          `json:"name"`
+	RawValue        json.RawMessage `json:"rawValue"`
+	Source          string          `json:"source"` // "version" or "current"
+	HistoryComplete bool            `json:"historyComplete"`
+}
+
+// PointInTimeExperiment represents an experiment status as of the requested time
+type PointInTimeExperiment struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	Uuid            string `json:"uuid"`
+	Status          string `json:"status"`
+	HistoryComplete bool   `json:"historyComplete"`
+}
+
+// PointInTimeReport summarizes how the point-in-time document was generated
+type PointInTimeReport struct {
+	ParameterCount                int    `json:"parameterCount"`
+	ExperimentCount               int    `json:"experimentCount"`
+	IncompleteParameterIDs        []uint `json:"incompleteParameterIds"`
+	IncompleteExperimentIDs       []int  `json:"incompleteExperimentIds"`
+	ParametersFromCurrentRawValue []uint `json:"parametersFromCurrentRawValue"`
+}
+
+// ParameterExportVersion is the version of the parameter export document format
+const ParameterExportVersion = 1
+
+// ParameterExportDocument is a portable description of parameters used to promote them between
+// environments. Rules reference segments and conditions reference attributes by name, since IDs
+// differ from one environment to another.
+type ParameterExportDocument struct {
+	Version    int                 `json:"version"`
+	ExportedAt time.Time           `json:"exportedAt"`
+	Attributes []ExportedAttribute `json:"attributes"`
+	Segments   []ExportedSegment   `json:"segments"`
+	Parameters []ExportedParameter `json:"parameters"`
+}
+
+// ExportedAttribute is an attribute referenced by an exported condition
+type ExportedAttribute struct {
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	DataType      model.DataType `json:"dataType"`
+	HashAttribute bool           `json:"hashAttribute"`
+	EnumOptions   []string       `json:"enumOptions"`
+}
+
+// ExportedSegment is a segment referenced by an exported parameter rule
+type ExportedSegment struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Rules       []ExportedSegmentRule `json:"rules"`
+}
+
+// ExportedSegmentRule is a rule of an exported segment; rules referencing another segment name it
+// along with whether they include or exclude its users, instead of holding conditions
+type ExportedSegmentRule struct {
+	Name          string                     `json:"name"`
+	Description   string                     `json:"description"`
+	Segment       string                     `json:"segment,omitempty"`
+	ReferenceType model.SegmentReferenceType `json:"referenceType,omitempty"`
+	Conditions    []ExportedCondition        `json:"conditions"`
+}
+
+// ExportedCondition is a condition on an attribute identified by name
+type ExportedCondition struct {
+	Attribute string                  `json:"attribute"`
+	Operator  model.ConditionOperator `json:"operator"`
+	Value     string                  `json:"value"`
+}
+
+// ExportedParameter is a parameter with its rules
+type ExportedParameter struct {
+	Name                string                   `json:"name"`
+	Description         string                   `json:"description"`
+	DataType            model.ParameterDataType  `json:"dataType"`
+	DefaultRolloutValue interface{}              `json:"defaultRolloutValue"`
+	EnumOptions         []string                 `json:"enumOptions"`
+	NumberConstraints   *model.NumberConstraints `json:"numberConstraints,omitempty"`
+	Rules               []ExportedParameterRule  `json:"rules"`
+}
+
+// ExportedParameterRule is a parameter rule; segment rules name their segment
+type ExportedParameterRule struct {
+	Name              string                    `json:"name"`
+	Description       string                    `json:"description"`
+	Type              model.RuleType            `json:"type"`
+	RolloutValue      interface{}               `json:"rolloutValue"`
+	Segment           string                    `json:"segment,omitempty"`
+	MatchType         *model.ConditionMatchType `json:"matchType,omitempty"`
+	ConditionLogic    model.ConditionLogic      `json:"conditionLogic"`
+	Priority          int                       `json:"priority"`
+	RolloutPercentage *int                      `json:"rolloutPercentage,omitempty"`
+	HashAttributeName *string                   `json:"hashAttributeName,omitempty"`
+	Negate            bool                      `json:"negate,omitempty"`
+	Conditions        []ExportedCondition       `json:"conditions"`
+}
+
+// ImportConflictStrategy decides what an import does with parameters that already exist
+type ImportConflictStrategy string
+
+const (
+	ImportConflictSkip      ImportConflictStrategy = "skip"
+	ImportConflictOverwrite ImportConflictStrategy = "overwrite"
+)
+
+// ImportParametersRequest represents the request to import a parameter export document
+type ImportParametersRequest struct {
+	Document ParameterExportDocument `json:"document"`
+	// CreateMissing creates attributes and segments defined in the document but missing in this
+	// environment instead of reporting them as errors
+	CreateMissing bool                   `json:"createMissing"`
+	OnConflict    ImportConflictStrategy `json:"onConflict"`
+}
+
+// Validate checks the document version, the conflict strategy and that names are set and unique
+func (r *ImportParametersRequest) Validate() error {
+	if r.Document.Version != ParameterExportVersion {
+		return fmt.Errorf("unsupported document version %d", r.Document.Version)
+	}
+	switch r.OnConflict {
+	case "", ImportConflictSkip, ImportConflictOverwrite:
+	default:
+		return fmt.Errorf("onConflict must be one of skip, overwrite")
+	}
+
+	seen := make(map[string]bool, len(r.Document.Parameters))
+	for _, parameter := range r.Document.Parameters {
+		if strings.TrimSpace(parameter.Name) == "" {
+			return errors.New("parameter name is required")
+		}
+		if seen[parameter.Name] {
+			return fmt.Errorf("duplicate parameter %q", parameter.Name)
+		}
+		seen[parameter.Name] = true
+	}
+	return nil
+}
+
+// ImportAction describes what an import does, or would do, with an item of the document
+type ImportAction string
+
+const (
+	ImportActionCreate    ImportAction = "create"
+	ImportActionUpdate    ImportAction = "update"
+	ImportActionSkip      ImportAction = "skip"
+	ImportActionUnchanged ImportAction = "unchanged"
+)
+
+// ImportItemResult is the outcome of importing an attribute or a segment
+type ImportItemResult struct {
+	Name   string       `json:"name"`
+	Action ImportAction `json:"action"`
+}
+
+// ImportParameterResult is the outcome of importing a parameter, with its configuration before and after
+type ImportParameterResult struct {
+	Name   string             `json:"name"`
+	Action ImportAction       `json:"action"`
+	Before *ExportedParameter `json:"before,omitempty"`
+	After  *ExportedParameter `json:"after,omitempty"`
+}
+
+// ImportItemError reports why an item of the document cannot be imported
+type ImportItemError struct {
+	Kind    string `json:"kind"` // "attribute", "segment" or "parameter"
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// ImportParametersResponse is the diff of an import. Nothing is written when Errors is not empty.
+type ImportParametersResponse struct {
+	DryRun     bool                    `json:"dryRun"`
+	Applied    bool                    `json:"applied"`
+	Attributes []ImportItemResult      `json:"attributes"`
+	Segments   []ImportItemResult      `json:"segments"`
+	Parameters []ImportParameterResult `json:"parameters"`
+	Errors     []ImportItemError       `json:"errors"`
+}
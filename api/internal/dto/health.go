@@ -0,0 +1,21 @@
+package dto
+
+// Health statuses reported by the health check
+const (
+	HealthStatusOK          = "OK"
+	HealthStatusUnavailable = "UNAVAILABLE"
+)
+
+// HealthChecks reports the status of each dependency the API needs to serve requests
+type HealthChecks struct {
+	DB    string `json:"db"`
+	River string `json:"river"`
+	// S3 is only checked when parameters are synced to S3
+	S3 string `json:"s3,omitempty"`
+}
+
+// HealthCheckResponse represents the health check result; Status is unavailable when any check fails
+type HealthCheckResponse struct {
+	Status string       `json:"status"`
+	Checks HealthChecks `json:"checks"`
+}
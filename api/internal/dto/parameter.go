@@ -5,48 +5,76 @@ import (
 	"time"
 )
 
-// CreateParameterRuleConditionRequest represents the request to create a parameter rule condition
+// CreateParameterRuleConditionRequest represents the request to create a parameter rule condition.
+// Value is ignored by the is_set and is_not_set operators, which only check that the attribute is present.
 type CreateParameterRuleConditionRequest struct {
 	AttributeID uint                    `json:"attributeId" validate:"required"`
-	Operator    model.ConditionOperator `json:"operator" validate:"required,oneof=equals not_equals contains not_contains greater_than less_than greater_than_or_equal less_than_or_equal in not_in"`
-	Value       string                  `json:"value" validate:"required"`
+	Operator    model.ConditionOperator `json:"operator" validate:"required,oneof=equals not_equals contains not_contains greater_than less_than greater_than_or_equal less_than_or_equal in not_in between not_between matches_regex not_matches_regex is_set is_not_set semver_gt semver_lt semver_gte semver_lte semver_eq"`
+	Value       string                  `json:"value" validate:"required_unless=Operator is_set Operator is_not_set"`
 }
 
 // CreateParameterRuleRequest represents the request to create a parameter rule
 type CreateParameterRuleRequest struct {
-	Name         string                                `json:"name" validate:"required"`
-	Description  string                                `json:"description,omitempty"`
-	Type         model.RuleType                        `json:"type" validate:"required,oneof=segment attribute"`
-	RolloutValue interface{}                           `json:"rolloutValue" validate:"required"`
-	SegmentID    *uint                                 `json:"segmentId,omitempty"`
-	MatchType    *model.ConditionMatchType             `json:"matchType,omitempty"`
-	Conditions   []CreateParameterRuleConditionRequest `json:"conditions,omitempty" validate:"dive"`
+	Name              string                                `json:"name" validate:"required"`
+	Description       string                                `json:"description,omitempty"`
+	Type              model.RuleType                        `json:"type" validate:"required,oneof=segment attribute"`
+	RolloutValue      interface{}                           `json:"rolloutValue" validate:"required"`
+	SegmentID         *uint                                 `json:"segmentId,omitempty"`
+	MatchType         *model.ConditionMatchType             `json:"matchType,omitempty"`
+	ConditionLogic    model.ConditionLogic                  `json:"conditionLogic,omitempty" validate:"omitempty,oneof=and or"`
+	Priority          *int                                  `json:"priority,omitempty" validate:"omitempty,min=0"`
+	RolloutPercentage *int                                  `json:"rolloutPercentage,omitempty" validate:"omitempty,min=0,max=100"`
+	HashAttributeName *string                               `json:"hashAttributeName,omitempty"`
+	Negate            bool                                  `json:"negate,omitempty"`
+	Conditions        []CreateParameterRuleConditionRequest `json:"conditions,omitempty" validate:"dive"`
 }
 
 // CreateParameterRequest represents the request to create a parameter
 type CreateParameterRequest struct {
-	Name                string                  `json:"name" validate:"required"`
-	Description         string                  `json:"description" validate:"required"`
-	DataType            model.ParameterDataType `json:"dataType" validate:"required,oneof=boolean string number"`
-	DefaultRolloutValue interface{}             `json:"defaultRolloutValue" validate:"required"`
+	Name                string                   `json:"name" validate:"required"`
+	Description         string                   `json:"description" validate:"required"`
+	DataType            model.ParameterDataType  `json:"dataType" validate:"required,oneof=boolean string number date enum json"`
+	DefaultRolloutValue interface{}              `json:"defaultRolloutValue" validate:"required"`
+	EnumOptions         []string                 `json:"enumOptions,omitempty"`
+	NumberConstraints   *model.NumberConstraints `json:"numberConstraints,omitempty"`
+	// IdempotencyKey is taken from the Idempotency-Key header
+	IdempotencyKey string `json:"-"`
+}
+
+// CloneParameterRequest represents the request to clone a parameter. The source's description is
+// kept when none is given.
+type CloneParameterRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description,omitempty"`
 }
 
 // UpdateParameterRuleConditionRequest represents the request to update a parameter rule condition
 type UpdateParameterRuleConditionRequest struct {
 	AttributeID uint                    `json:"attributeId" validate:"required"`
-	Operator    model.ConditionOperator `json:"operator" validate:"required,oneof=equals not_equals contains not_contains greater_than less_than greater_than_or_equal less_than_or_equal in not_in"`
-	Value       string                  `json:"value" validate:"required"`
+	Operator    model.ConditionOperator `json:"operator" validate:"required,oneof=equals not_equals contains not_contains greater_than less_than greater_than_or_equal less_than_or_equal in not_in between not_between matches_regex not_matches_regex is_set is_not_set semver_gt semver_lt semver_gte semver_lte semver_eq"`
+	Value       string                  `json:"value" validate:"required_unless=Operator is_set Operator is_not_set"`
 }
 
 // UpdateParameterRuleRequest represents the request to update a parameter rule
 type UpdateParameterRuleRequest struct {
-	Name         *string                               `json:"name,omitempty"`
-	Description  *string                               `json:"description,omitempty"`
-	Type         *model.RuleType                       `json:"type,omitempty"`
-	RolloutValue interface{}                           `json:"rolloutValue,omitempty"`
-	SegmentID    *uint                                 `json:"segmentId,omitempty"`
-	MatchType    *model.ConditionMatchType             `json:"matchType,omitempty"`
-	Conditions   []UpdateParameterRuleConditionRequest `json:"conditions,omitempty" validate:"dive"`
+	Name              *string                               `json:"name,omitempty"`
+	Description       *string                               `json:"description,omitempty"`
+	Type              *model.RuleType                       `json:"type,omitempty"`
+	RolloutValue      interface{}                           `json:"rolloutValue,omitempty"`
+	SegmentID         *uint                                 `json:"segmentId,omitempty"`
+	MatchType         *model.ConditionMatchType             `json:"matchType,omitempty"`
+	ConditionLogic    *model.ConditionLogic                 `json:"conditionLogic,omitempty" validate:"omitempty,oneof=and or"`
+	Priority          *int                                  `json:"priority,omitempty" validate:"omitempty,min=0"`
+	RolloutPercentage *int                                  `json:"rolloutPercentage,omitempty" validate:"omitempty,min=0,max=100"`
+	HashAttributeName *string                               `json:"hashAttributeName,omitempty"`
+	Negate            *bool                                 `json:"negate,omitempty"`
+	Conditions        []UpdateParameterRuleConditionRequest `json:"conditions,omitempty" validate:"dive"`
+}
+
+// ReorderParameterRulesRequest represents the request to reorder the rules of a parameter.
+// RuleIDs lists every rule of the parameter, highest priority first.
+type ReorderParameterRulesRequest struct {
+	RuleIDs []uint `json:"ruleIds" validate:"required,min=1"`
 }
 
 // UpdateParameterRequest represents the request to update a parameter
@@ -55,6 +83,9 @@ type UpdateParameterRequest struct {
 	Description         *string                  `json:"description,omitempty"`
 	DataType            *model.ParameterDataType `json:"dataType,omitempty"`
 	DefaultRolloutValue interface{}              `json:"defaultRolloutValue,omitempty"`
+	EnumOptions         []string                 `json:"enumOptions,omitempty"`
+	// NumberConstraints replaces the constraints of a number parameter; empty constraints remove them
+	NumberConstraints *model.NumberConstraints `json:"numberConstraints,omitempty"`
 }
 
 // UpdateParameterWithRulesRequest represents the comprehensive request to update a parameter with all its rules
@@ -63,6 +94,8 @@ type UpdateParameterWithRulesRequest struct {
 	Description         *string                      `json:"description,omitempty"`
 	DataType            *model.ParameterDataType     `json:"dataType,omitempty"`
 	DefaultRolloutValue interface{}                  `json:"defaultRolloutValue,omitempty"`
+	EnumOptions         []string                     `json:"enumOptions,omitempty"`
+	NumberConstraints   *model.NumberConstraints     `json:"numberConstraints,omitempty"`
 	Rules               []CreateParameterRuleRequest `json:"rules,omitempty" validate:"dive"`
 }
 
@@ -78,16 +111,21 @@ type ParameterRuleConditionResponse struct {
 
 // ParameterRuleResponse represents the response for parameter rule operations
 type ParameterRuleResponse struct {
-	ID           uint                             `json:"id"`
-	Name         string                           `json:"name"`
-	Description  string                           `json:"description"`
-	Type         model.RuleType                   `json:"type"`
-	RolloutValue interface{}                      `json:"rolloutValue"`
-	ParameterID  uint                             `json:"parameterId"`
-	SegmentID    *uint                            `json:"segmentId,omitempty"`
-	MatchType    *model.ConditionMatchType        `json:"matchType,omitempty"`
-	Segment      *SegmentResponse                 `json:"segment,omitempty"`
-	Conditions   []ParameterRuleConditionResponse `json:"conditions"`
+	ID                uint                             `json:"id"`
+	Name              string                           `json:"name"`
+	Description       string                           `json:"description"`
+	Type              model.RuleType                   `json:"type"`
+	RolloutValue      interface{}                      `json:"rolloutValue"`
+	ParameterID       uint                             `json:"parameterId"`
+	SegmentID         *uint                            `json:"segmentId,omitempty"`
+	MatchType         *model.ConditionMatchType        `json:"matchType,omitempty"`
+	ConditionLogic    model.ConditionLogic             `json:"conditionLogic"`
+	Priority          int                              `json:"priority"`
+	RolloutPercentage *int                             `json:"rolloutPercentage,omitempty"`
+	HashAttributeName *string                          `json:"hashAttributeName,omitempty"`
+	Negate            bool                             `json:"negate"`
+	Segment           *SegmentResponse                 `json:"segment,omitempty"`
+	Conditions        []ParameterRuleConditionResponse `json:"conditions"`
 }
 
 // ParameterConditionResponse represents the response for parameter condition operations (legacy)
@@ -100,6 +138,12 @@ type ParameterConditionResponse struct {
 	Segment      *SegmentResponse         `json:"segment,omitempty"`
 }
 
+// ParameterPageResponse represents a page of parameters; NextCursor is passed as after to get the next page
+type ParameterPageResponse struct {
+	Items      []ParameterResponse `json:"items"`
+	NextCursor *uint               `json:"nextCursor"`
+}
+
 // ParameterResponse represents the response for parameter operations
 type ParameterResponse struct {
 	ID                  uint                         `json:"id"`
@@ -107,9 +151,16 @@ type ParameterResponse struct {
 	Description         string                       `json:"description"`
 	DataType            model.ParameterDataType      `json:"dataType"`
 	DefaultRolloutValue interface{}                  `json:"defaultRolloutValue"`
+	EnumOptions         []string                     `json:"enumOptions"`
+	NumberConstraints   *model.NumberConstraints     `json:"numberConstraints,omitempty"`
 	UsageCount          int                          `json:"usageCount"`
+	Archived            bool                         `json:"archived"`
+	ArchivedAt          *time.Time                   `json:"archivedAt,omitempty"`
+	DeletedAt           *time.Time                   `json:"deletedAt,omitempty"`
 	CreatedAt           time.Time                    `json:"createdAt"`
 	UpdatedAt           time.Time                    `json:"updatedAt"`
+	CreatedByUserID     *uint                        `json:"createdByUserId,omitempty"`
+	UpdatedByUserID     *uint                        `json:"updatedByUserId,omitempty"`
 	Conditions          []ParameterConditionResponse `json:"conditions"`
 	Rules               []ParameterRuleResponse      `json:"rules"`
 }
@@ -145,15 +196,20 @@ func ToParameterRuleResponse(rule *model.ParameterRule) ParameterRuleResponse {
 	}
 
 	response := ParameterRuleResponse{
-		ID:           rule.ID,
-		Name:         rule.Name,
-		Description:  rule.Description,
-		Type:         rule.Type,
-		RolloutValue: rule.RolloutValue.Data,
-		ParameterID:  rule.ParameterID,
-		SegmentID:    rule.SegmentID,
-		MatchType:    rule.MatchType,
-		Conditions:   conditions,
+		ID:                rule.ID,
+		Name:              rule.Name,
+		Description:       rule.Description,
+		Type:              rule.Type,
+		RolloutValue:      rule.RolloutValue.Data,
+		ParameterID:       rule.ParameterID,
+		SegmentID:         rule.SegmentID,
+		MatchType:         rule.MatchType,
+		ConditionLogic:    rule.ConditionLogic,
+		Priority:          rule.Priority,
+		RolloutPercentage: rule.RolloutPercentage,
+		HashAttributeName: rule.HashAttributeName,
+		Negate:            rule.Negate,
+		Conditions:        conditions,
 	}
 
 	if rule.Segment != nil {
@@ -194,15 +250,27 @@ func ToParameterResponse(parameter *model.Parameter) ParameterResponse {
 		rules[i] = ToParameterRuleResponse(&rule)
 	}
 
+	var deletedAt *time.Time
+	if parameter.DeletedAt.Valid {
+		deletedAt = &parameter.DeletedAt.Time
+	}
+
 	return ParameterResponse{
 		ID:                  parameter.ID,
 		Name:                parameter.Name,
 		Description:         parameter.Description,
 		DataType:            parameter.DataType,
 		DefaultRolloutValue: parameter.DefaultRolloutValue.Data,
+		EnumOptions:         parameter.EnumOptions,
+		NumberConstraints:   parameter.NumberConstraints,
 		UsageCount:          parameter.UsageCount,
+		Archived:            parameter.ArchivedAt != nil,
+		ArchivedAt:          parameter.ArchivedAt,
+		DeletedAt:           deletedAt,
 		CreatedAt:           parameter.CreatedAt,
 		UpdatedAt:           parameter.UpdatedAt,
+		CreatedByUserID:     parameter.CreatedByUserID,
+		UpdatedByUserID:     parameter.UpdatedByUserID,
 		Conditions:          conditions,
 		Rules:               rules,
 	}
@@ -221,50 +289,148 @@ func ToParameterListResponse(parameters []*model.Parameter) ParameterListRespons
 
 type SimulateParameterRequest struct {
 	ParameterName string                     `json:"parameterName" validate:"required"`
-	ParameterType model.ParameterDataType    `json:"parameterType" validate:"required,oneof=boolean string number"`
+	ParameterType model.ParameterDataType    `json:"parameterType" validate:"required,oneof=boolean string number date enum json"`
 	Attributes    []SimulateAttributeRequest `json:"attributes" validate:"required"`
+	// Environment is the key of the environment to simulate; the data fetched by the server's SDK client is used without one
+	Environment string `json:"environment,omitempty"`
 }
 
 type SimulateAttributeRequest struct {
-	DataType model.DataType `json:"dataType" validate:"required,oneof=boolean string number"`
+	DataType model.DataType `json:"dataType" validate:"required,oneof=boolean string number date"`
 	Value    string         `json:"value" validate:"required"`
 	Name     string         `json:"name" validate:"required"`
 }
 
 type SimulateParameterResponse struct {
-	Value interface{} `json:"value"`
+	Value       interface{}             `json:"value"`
+	EnumOptions []string                `json:"enumOptions,omitempty"`
+	Trace       SimulationTraceResponse `json:"trace"`
+}
+
+// SimulationTraceResponse explains where a simulated value came from. Rules are only evaluated,
+// and listed, when no experiment resolved the parameter.
+type SimulationTraceResponse struct {
+	Source          string                        `json:"source"` // "parameter" or "experiment"
+	Reason          string                        `json:"reason"`
+	MatchedRuleID   *uint                         `json:"matchedRuleId,omitempty"`
+	MatchedRuleName string                        `json:"matchedRuleName,omitempty"`
+	ExperimentID    *int                          `json:"experimentId,omitempty"`
+	ExperimentUUID  *string                       `json:"experimentUuid,omitempty"`
+	VariantID       *int                          `json:"variantId,omitempty"`
+	VariantName     *string                       `json:"variantName,omitempty"`
+	Rules           []SimulationRuleTraceResponse `json:"rules"`
+}
+
+// SimulationRuleTraceResponse describes how a parameter rule was evaluated
+type SimulationRuleTraceResponse struct {
+	RuleID      uint                               `json:"ruleId"`
+	RuleName    string                             `json:"ruleName,omitempty"`
+	Type        string                             `json:"type"`
+	MatchType   string                             `json:"matchType,omitempty"`
+	SegmentID   *uint                              `json:"segmentId,omitempty"`
+	SegmentName string                             `json:"segmentName,omitempty"`
+	Matched     bool                               `json:"matched"`
+	Conditions  []SimulationConditionTraceResponse `json:"conditions"`
+}
+
+// SimulationConditionTraceResponse describes the outcome of a rule or segment condition.
+// Actual is null when the simulated attributes do not set the attribute.
+type SimulationConditionTraceResponse struct {
+	SegmentRuleID *uint       `json:"segmentRuleId,omitempty"`
+	Attribute     string      `json:"attribute"`
+	Operator      string      `json:"operator"`
+	Expected      string      `json:"expected"`
+	Actual        interface{} `json:"actual"`
+	Matched       bool        `json:"matched"`
 }
 
 // ========== Parameter Change Request DTOs ==========
 
-// CreateParameterChangeRequestRequest represents the request to create a parameter change request
+// CreateParameterChangeRequestRequest represents the request to create a parameter change request.
+// RequestType defaults to update; create requests have no ParameterID and carry the whole new parameter.
 type CreateParameterChangeRequestRequest struct {
-	ParameterID uint   `json:"parameterId" validate:"required"`
-	Description string `json:"description"`
+	ParameterID uint                             `json:"parameterId"`
+	RequestType model.ParameterChangeRequestType `json:"requestType,omitempty" validate:"omitempty,oneof=create update delete"`
+	Description string                           `json:"description"`
 	// The proposed changes - same structure as UpdateParameterWithRulesRequest
 	Name                 *string                      `json:"name,omitempty"`
 	DataType             *model.ParameterDataType     `json:"dataType,omitempty"`
 	ParameterDescription *string                      `json:"parameterDescription,omitempty"`
 	DefaultRolloutValue  interface{}                  `json:"defaultRolloutValue,omitempty"`
+	EnumOptions          []string                     `json:"enumOptions,omitempty"`
+	NumberConstraints    *model.NumberConstraints     `json:"numberConstraints,omitempty"`
 	Rules                []CreateParameterRuleRequest `json:"rules,omitempty" validate:"dive"`
 }
 
 // ParameterChangeRequestResponse represents the response for a parameter change request
 type ParameterChangeRequestResponse struct {
-	ID                uint                               `json:"id"`
-	ParameterID       uint                               `json:"parameterId"`
-	ParameterName     string                             `json:"parameterName"`
-	RequestedByUserID uint                               `json:"requestedByUserId"`
-	RequestedByUser   *UserInfo                          `json:"requestedByUser,omitempty"`
-	Status            model.ParameterChangeRequestStatus `json:"status"`
-	Description       string                             `json:"description"`
-	ChangeData        model.ParameterChangeData          `json:"changeData"`
-	CurrentConfig     model.ParameterCurrentConfig       `json:"currentConfig"`
-	ReviewedByUserID  *uint                              `json:"reviewedByUserId,omitempty"`
-	ReviewedByUser    *UserInfo                          `json:"reviewedByUser,omitempty"`
-	ReviewedAt        *time.Time                         `json:"reviewedAt,omitempty"`
-	CreatedAt         time.Time                          `json:"createdAt"`
-	UpdatedAt         time.Time                          `json:"updatedAt"`
+	ID                 uint                               `json:"id"`
+	RequestType        model.ParameterChangeRequestType   `json:"requestType"`
+	ParameterID        *uint                              `json:"parameterId,omitempty"`
+	ParameterName      string                             `json:"parameterName"`
+	RequestedByUserID  uint                               `json:"requestedByUserId"`
+	RequestedByUser    *UserInfo                          `json:"requestedByUser,omitempty"`
+	Status             model.ParameterChangeRequestStatus `json:"status"`
+	Description        string                             `json:"description"`
+	ChangeData         model.ParameterChangeData          `json:"changeData"`
+	CurrentConfig      model.ParameterCurrentConfig       `json:"currentConfig"`
+	ReviewedByUserID   *uint                              `json:"reviewedByUserId,omitempty"`
+	ReviewedByUser     *UserInfo                          `json:"reviewedByUser,omitempty"`
+	ReviewedAt         *time.Time                         `json:"reviewedAt,omitempty"`
+	CancellationReason string                             `json:"cancellationReason,omitempty"`
+	CreatedAt          time.Time                          `json:"createdAt"`
+	UpdatedAt          time.Time                          `json:"updatedAt"`
+	// Diff is set on approval to the changes applied to the parameter
+	Diff *ParameterChangeRequestDiff `json:"diff,omitempty"`
+}
+
+// DiffChangeType classifies a rule or condition in a change request diff
+type DiffChangeType string
+
+const (
+	DiffChangeAdded    DiffChangeType = "added"
+	DiffChangeRemoved  DiffChangeType = "removed"
+	DiffChangeModified DiffChangeType = "modified"
+)
+
+// ParameterChangeRequestDiff is what approving a change request does to the live parameter
+type ParameterChangeRequestDiff struct {
+	ChangeRequestID uint                             `json:"changeRequestId"`
+	RequestType     model.ParameterChangeRequestType `json:"requestType"`
+	// ParameterID is 0 for create requests that are not approved yet
+	ParameterID uint          `json:"parameterId"`
+	Fields      []FieldChange `json:"fields"`
+	// Rules is nil when the change request keeps the parameter's rules
+	Rules *RulesDiff `json:"rules,omitempty"`
+}
+
+// FieldChange is the live and proposed value of a changed field
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// RulesDiff classifies the rules of a change request against the live rules, matched by name
+type RulesDiff struct {
+	Added    []model.ParameterRuleRequest `json:"added"`
+	Removed  []model.ParameterRuleRequest `json:"removed"`
+	Modified []RuleDiff                   `json:"modified"`
+}
+
+// RuleDiff lists the changed fields and conditions of a rule present before and after the change
+type RuleDiff struct {
+	Name       string            `json:"name"`
+	Fields     []FieldChange     `json:"fields"`
+	Conditions []ConditionChange `json:"conditions"`
+}
+
+// ConditionChange is a condition added, removed or modified in a rule. Conditions are matched by attribute.
+type ConditionChange struct {
+	Change      DiffChangeType                       `json:"change"`
+	AttributeID uint                                 `json:"attributeId"`
+	Old         *model.ParameterRuleConditionRequest `json:"old,omitempty"`
+	New         *model.ParameterRuleConditionRequest `json:"new,omitempty"`
 }
 
 // ApproveParameterChangeRequestRequest represents the request to approve a change request
@@ -277,10 +443,16 @@ type RejectParameterChangeRequestRequest struct {
 	Comment string `json:"comment"`
 }
 
+// CancelParameterChangeRequestRequest represents the request to cancel a change request
+type CancelParameterChangeRequestRequest struct {
+	Reason string `json:"reason"`
+}
+
 // ParameterChangeRequestSummaryResponse represents a summary response for parameter change requests
 type ParameterChangeRequestSummaryResponse struct {
 	ID            uint                               `json:"id"`
-	ParameterID   uint                               `json:"parameterId"`
+	RequestType   model.ParameterChangeRequestType   `json:"requestType"`
+	ParameterID   *uint                              `json:"parameterId,omitempty"`
 	ParameterName string                             `json:"parameterName"`
 	Status        model.ParameterChangeRequestStatus `json:"status"`
 	Description   string                             `json:"description"`
@@ -305,22 +477,20 @@ type ParameterChangeRequestListResponse struct {
 // ToParameterChangeRequestResponse converts model.ParameterChangeRequest to ParameterChangeRequestResponse
 func ToParameterChangeRequestResponse(changeRequest *model.ParameterChangeRequest) ParameterChangeRequestResponse {
 	response := ParameterChangeRequestResponse{
-		ID:                changeRequest.ID,
-		ParameterID:       changeRequest.ParameterID,
-		RequestedByUserID: changeRequest.RequestedByUserID,
-		Status:            changeRequest.Status,
-		Description:       changeRequest.Description,
-		ChangeData:        changeRequest.ChangeData,
-		CurrentConfig:     changeRequest.CurrentConfig,
-		ReviewedByUserID:  changeRequest.ReviewedByUserID,
-		ReviewedAt:        changeRequest.ReviewedAt,
-		CreatedAt:         changeRequest.CreatedAt,
-		UpdatedAt:         changeRequest.UpdatedAt,
-	}
-
-	// Add parameter name if parameter is loaded
-	if changeRequest.Parameter != nil {
-		response.ParameterName = changeRequest.Parameter.Name
+		ID:                 changeRequest.ID,
+		RequestType:        changeRequest.RequestType,
+		ParameterID:        changeRequest.ParameterID,
+		ParameterName:      changeRequest.ParameterName(),
+		RequestedByUserID:  changeRequest.RequestedByUserID,
+		Status:             changeRequest.Status,
+		Description:        changeRequest.Description,
+		ChangeData:         changeRequest.ChangeData,
+		CurrentConfig:      changeRequest.CurrentConfig,
+		ReviewedByUserID:   changeRequest.ReviewedByUserID,
+		ReviewedAt:         changeRequest.ReviewedAt,
+		CancellationReason: changeRequest.CancellationReason,
+		CreatedAt:          changeRequest.CreatedAt,
+		UpdatedAt:          changeRequest.UpdatedAt,
 	}
 
 	// Add requested by user info
@@ -9,16 +9,76 @@ type GetMetadataSDKResponse struct {
 	EnableS3 bool `json:"enableS3"`
 }
 
+// GetAllParametersSDKRequest selects the environment whose parameters are fetched, by its key.
+// Parameters without an override in the environment, or all of them without an environment, are served as defined.
 type GetAllParametersSDKRequest struct {
+	Environment string `json:"environment,omitempty"`
 }
 
 type GetAllParametersSDKResponse struct {
 	Parameters []types.Parameter `json:"parameters"`
 }
 
+// GetAllExperimentsSDKRequest selects the environment whose experiments are fetched, by its key
 type GetAllExperimentsSDKRequest struct {
+	Environment string `json:"environment,omitempty"`
 }
 
 type GetAllExperimentsSDKResponse struct {
 	Experiments []types.Experiment `json:"experiments"`
 }
+
+// EvaluateSDKRequest evaluates parameters server-side for clients that cannot embed the SDK. Either
+// ParameterName or, for the batch form, ParameterNames is set. Attributes are typed JSON values and
+// ServiceName is recorded on the evaluation events.
+type EvaluateSDKRequest struct {
+	ServiceName    string                 `json:"serviceName" binding:"required"`
+	ParameterName  string                 `json:"parameterName,omitempty"`
+	ParameterNames []string               `json:"parameterNames,omitempty" binding:"max=100"`
+	Attributes     map[string]interface{} `json:"attributes"`
+	Environment    string                 `json:"environment,omitempty"`
+}
+
+// EvaluateSDKResult is the value a parameter evaluates to. Value is null and Error is set when the
+// parameter could not be evaluated.
+type EvaluateSDKResult struct {
+	ParameterName  string      `json:"parameterName"`
+	Value          interface{} `json:"value"`
+	DataType       string      `json:"dataType,omitempty"`
+	Source         string      `json:"source"`
+	Reason         string      `json:"reason"`
+	ExperimentID   *int        `json:"experimentId,omitempty"`
+	ExperimentUUID *string     `json:"experimentUuid,omitempty"`
+	VariantID      *int        `json:"variantId,omitempty"`
+	VariantName    *string     `json:"variantName,omitempty"`
+	Error          string      `json:"error,omitempty"`
+}
+
+// EvaluateSDKResponse lists the results of a batch evaluation in the order of the requested names
+type EvaluateSDKResponse struct {
+	Results []EvaluateSDKResult `json:"results"`
+}
+
+// RebuildRawValuesResponse reports the outcome of rebuilding the experiment raw values. Warnings
+// counts the active experiments whose raw_value still cannot be served to SDKs afterwards; they are
+// converted from their related rows, or left out of the SDK payload when that fails too.
+type RebuildRawValuesResponse struct {
+	RebuiltExperiments  int    `json:"rebuiltExperiments"`
+	FailedExperimentIDs []uint `json:"failedExperimentIds"`
+	ActiveExperiments   int    `json:"activeExperiments"`
+	ServedExperiments   int    `json:"servedExperiments"`
+	Warnings            int    `json:"warnings"`
+}
+
+// SDK change entities reported by SDKChangeEvent
+const (
+	SDKChangeEntityParameter  = "parameter"
+	SDKChangeEntityExperiment = "experiment"
+)
+
+// SDKChangeEvent is streamed to SDK clients once the data they fetch for an entity changed.
+// ID is 0 when the change is not limited to one entity.
+type SDKChangeEvent struct {
+	Entity string `json:"entity"`
+	ID     int    `json:"id,omitempty"`
+}
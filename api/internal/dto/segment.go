@@ -1,22 +1,28 @@
 package dto
 
 import (
+	"api/internal/apperror"
+	"api/internal/external/solver"
 	"api/internal/model"
 	"time"
 )
 
-// CreateSegmentRuleConditionRequest represents the request to create a segment rule condition
+// CreateSegmentRuleConditionRequest represents the request to create a segment rule condition.
+// Value is ignored by the is_set and is_not_set operators, which only check that the attribute is present.
 type CreateSegmentRuleConditionRequest struct {
 	AttributeID uint                    `json:"attributeId" validate:"required"`
-	Operator    model.ConditionOperator `json:"operator" validate:"required,oneof=equals not_equals contains not_contains greater_than less_than greater_than_or_equal less_than_or_equal in not_in"`
-	Value       string                  `json:"value" validate:"required"`
+	Operator    model.ConditionOperator `json:"operator" validate:"required,oneof=equals not_equals contains not_contains greater_than less_than greater_than_or_equal less_than_or_equal in not_in between not_between matches_regex not_matches_regex is_set is_not_set semver_gt semver_lt semver_gte semver_lte semver_eq"`
+	Value       string                  `json:"value" validate:"required_unless=Operator is_set Operator is_not_set"`
 }
 
-// CreateSegmentRuleRequest represents the request to create a segment rule
+// CreateSegmentRuleRequest represents the request to create a segment rule. A rule either holds conditions
+// or references another segment, whose users it includes or excludes.
 type CreateSegmentRuleRequest struct {
-	Name        string                              `json:"name" validate:"required"`
-	Description string                              `json:"description,omitempty"`
-	Conditions  []CreateSegmentRuleConditionRequest `json:"conditions" validate:"required,dive"`
+	Name                string                              `json:"name" validate:"required"`
+	Description         string                              `json:"description,omitempty"`
+	ReferencedSegmentID *uint                               `json:"referencedSegmentId,omitempty"`
+	ReferenceType       model.SegmentReferenceType          `json:"referenceType,omitempty" validate:"required_with=ReferencedSegmentID,omitempty,oneof=include exclude"`
+	Conditions          []CreateSegmentRuleConditionRequest `json:"conditions" validate:"required_without=ReferencedSegmentID,dive"`
 }
 
 // CreateSegmentRequest represents the request to create a segment
@@ -29,15 +35,18 @@ type CreateSegmentRequest struct {
 // UpdateSegmentRuleConditionRequest represents the request to update a segment rule condition
 type UpdateSegmentRuleConditionRequest struct {
 	AttributeID uint                    `json:"attributeId" validate:"required"`
-	Operator    model.ConditionOperator `json:"operator" validate:"required,oneof=equals not_equals contains not_contains greater_than less_than greater_than_or_equal less_than_or_equal in not_in"`
-	Value       string                  `json:"value" validate:"required"`
+	Operator    model.ConditionOperator `json:"operator" validate:"required,oneof=equals not_equals contains not_contains greater_than less_than greater_than_or_equal less_than_or_equal in not_in between not_between matches_regex not_matches_regex is_set is_not_set semver_gt semver_lt semver_gte semver_lte semver_eq"`
+	Value       string                  `json:"value" validate:"required_unless=Operator is_set Operator is_not_set"`
 }
 
-// UpdateSegmentRuleRequest represents the request to update a segment rule
+// UpdateSegmentRuleRequest represents the request to update a segment rule. A rule either holds conditions
+// or references another segment, whose users it includes or excludes.
 type UpdateSegmentRuleRequest struct {
-	Name        string                              `json:"name" validate:"required"`
-	Description string                              `json:"description,omitempty"`
-	Conditions  []UpdateSegmentRuleConditionRequest `json:"conditions" validate:"required,dive"`
+	Name                string                              `json:"name" validate:"required"`
+	Description         string                              `json:"description,omitempty"`
+	ReferencedSegmentID *uint                               `json:"referencedSegmentId,omitempty"`
+	ReferenceType       model.SegmentReferenceType          `json:"referenceType,omitempty" validate:"required_with=ReferencedSegmentID,omitempty,oneof=include exclude"`
+	Conditions          []UpdateSegmentRuleConditionRequest `json:"conditions" validate:"required_without=ReferencedSegmentID,dive"`
 }
 
 // UpdateSegmentRequest represents the request to update a segment
@@ -59,11 +68,13 @@ type SegmentRuleConditionResponse struct {
 
 // SegmentRuleResponse represents the response for segment rule operations
 type SegmentRuleResponse struct {
-	ID          uint                           `json:"id"`
-	Name        string                         `json:"name"`
-	Description string                         `json:"description"`
-	SegmentID   uint                           `json:"segmentId"`
-	Conditions  []SegmentRuleConditionResponse `json:"conditions"`
+	ID                  uint                           `json:"id"`
+	Name                string                         `json:"name"`
+	Description         string                         `json:"description"`
+	SegmentID           uint                           `json:"segmentId"`
+	ReferencedSegmentID *uint                          `json:"referencedSegmentId,omitempty"`
+	ReferenceType       model.SegmentReferenceType     `json:"referenceType,omitempty"`
+	Conditions          []SegmentRuleConditionResponse `json:"conditions"`
 }
 
 // SegmentResponse represents the response for segment operations
@@ -81,6 +92,14 @@ type SegmentListResponse struct {
 	Segments []SegmentResponse `json:"segments"`
 }
 
+// SegmentPageResponse represents a page of segments with pagination info
+type SegmentPageResponse struct {
+	Items  []SegmentResponse `json:"items"`
+	Total  int64             `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
 // ToSegmentRuleConditionResponse converts model.SegmentRuleCondition to SegmentRuleConditionResponse
 func ToSegmentRuleConditionResponse(condition *model.SegmentRuleCondition) SegmentRuleConditionResponse {
 	response := SegmentRuleConditionResponse{
@@ -107,11 +126,13 @@ func ToSegmentRuleResponse(rule *model.SegmentRule) SegmentRuleResponse {
 	}
 
 	return SegmentRuleResponse{
-		ID:          rule.ID,
-		Name:        rule.Name,
-		Description: rule.Description,
-		SegmentID:   rule.SegmentID,
-		Conditions:  conditions,
+		ID:                  rule.ID,
+		Name:                rule.Name,
+		Description:         rule.Description,
+		SegmentID:           rule.SegmentID,
+		ReferencedSegmentID: rule.ReferencedSegmentID,
+		ReferenceType:       rule.ReferenceType,
+		Conditions:          conditions,
 	}
 }
 
@@ -150,5 +171,84 @@ type CheckSegmentOverlapRequest struct {
 
 // CheckSegmentOverlapResponse represents the response for segment overlap check
 type CheckSegmentOverlapResponse struct {
-	Overlap bool `json:"overlap"`
+	Overlap     bool                        `json:"overlap"`
+	Explanation []SegmentOverlapExplanation `json:"explanation"`
+}
+
+// SegmentOverlapExplanation is an attribute on which the segments overlap, with a value
+// satisfying all of them and the conditions that constrain it
+type SegmentOverlapExplanation struct {
+	Attribute  string                    `json:"attribute"`
+	Value      interface{}               `json:"value"`
+	Conditions []SegmentOverlapCondition `json:"conditions"`
+}
+
+// SegmentOverlapCondition is a segment condition involved in an overlap
+type SegmentOverlapCondition struct {
+	SegmentID   uint                    `json:"segmentId"`
+	SegmentName string                  `json:"segmentName"`
+	RuleName    string                  `json:"ruleName"`
+	Operator    model.ConditionOperator `json:"operator"`
+	Value       string                  `json:"value"`
+}
+
+// ToCheckSegmentOverlapResponse converts the solver's conflict explanation to a CheckSegmentOverlapResponse
+func ToCheckSegmentOverlapResponse(overlap bool, explanation []solver.ConflictExplanation) *CheckSegmentOverlapResponse {
+	explanations := make([]SegmentOverlapExplanation, len(explanation))
+	for i, item := range explanation {
+		conditions := make([]SegmentOverlapCondition, len(item.Conditions))
+		for j, condition := range item.Conditions {
+			conditions[j] = SegmentOverlapCondition{
+				SegmentID:   condition.SegmentID,
+				SegmentName: condition.SegmentName,
+				RuleName:    condition.RuleName,
+				Operator:    condition.Operator,
+				Value:       condition.Value,
+			}
+		}
+		explanations[i] = SegmentOverlapExplanation{
+			Attribute:  item.Attribute,
+			Value:      item.Value,
+			Conditions: conditions,
+		}
+	}
+	return &CheckSegmentOverlapResponse{
+		Overlap:     overlap,
+		Explanation: explanations,
+	}
+}
+
+// ConditionValidationResponse is the error response listing the conditions that are invalid for their attributes
+type ConditionValidationResponse struct {
+	ErrorResponse
+	Conditions []InvalidConditionResponse `json:"conditions"`
+}
+
+// InvalidConditionResponse describes a condition that is invalid for the attribute it references
+type InvalidConditionResponse struct {
+	Rule        string `json:"rule"`
+	AttributeID uint   `json:"attributeId"`
+	Attribute   string `json:"attribute"`
+	Operator    string `json:"operator"`
+	Value       string `json:"value"`
+	Message     string `json:"message"`
+}
+
+// ToConditionValidationResponse converts a condition validation error into its response
+func ToConditionValidationResponse(err *apperror.ConditionValidationError) ConditionValidationResponse {
+	conditions := make([]InvalidConditionResponse, len(err.Problems))
+	for i, problem := range err.Problems {
+		conditions[i] = InvalidConditionResponse{
+			Rule:        problem.Rule,
+			AttributeID: problem.AttributeID,
+			Attribute:   problem.Attribute,
+			Operator:    problem.Operator,
+			Value:       problem.Value,
+			Message:     problem.Message,
+		}
+	}
+	return ConditionValidationResponse{
+		ErrorResponse: ErrorResponse{Error: "Bad Request", Message: err.Error()},
+		Conditions:    conditions,
+	}
 }
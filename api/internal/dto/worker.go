@@ -1,12 +1,18 @@
 package dto
 
-import "github.com/riverqueue/river"
+import (
+	"time"
+
+	"github.com/riverqueue/river"
+)
 
 type SyncParameterArgs struct {
 	ParameterID int
 }
 
 type SyncExperimentArgs struct {
+	// ExperimentID is the changed experiment, or 0 when several experiments changed
+	ExperimentID int
 }
 
 func (SyncExperimentArgs) Kind() string {
@@ -28,3 +34,61 @@ func (SyncExperimentArgs) InsertOpts() river.InsertOpts {
 		Queue: "sync_experiment",
 	}
 }
+
+// AdvanceExperimentStatusArgs advances the status of experiments whose start or end date has passed
+type AdvanceExperimentStatusArgs struct{}
+
+func (AdvanceExperimentStatusArgs) Kind() string {
+	return "advance_experiment_status"
+}
+
+// AdvanceExperimentStatusInterval is how often experiment statuses are advanced
+const AdvanceExperimentStatusInterval = time.Minute
+
+func (AdvanceExperimentStatusArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "advance_experiment_status",
+		// A single run per interval is enough, however many replicas schedule it
+		UniqueOpts: river.UniqueOpts{ByPeriod: AdvanceExperimentStatusInterval},
+	}
+}
+
+// ExperimentStatusWebhookArgs notifies the configured webhook that an experiment changed status.
+// The args are posted as the JSON payload of the notification.
+type ExperimentStatusWebhookArgs struct {
+	ExperimentID   int    `json:"experimentId"`
+	ExperimentName string `json:"experimentName"`
+	FromStatus     string `json:"fromStatus"`
+	ToStatus       string `json:"toStatus"`
+	// Timestamp is when the status changed, in unix seconds
+	Timestamp int64 `json:"timestamp"`
+}
+
+func (ExperimentStatusWebhookArgs) Kind() string {
+	return "experiment_status_webhook"
+}
+
+func (ExperimentStatusWebhookArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue: "webhook",
+		// Failed deliveries are retried with backoff; past a few hours the notification is stale
+		MaxAttempts: 10,
+	}
+}
+
+// PruneEvaluationEventsArgs deletes the evaluation events older than the configured retention window
+type PruneEvaluationEventsArgs struct{}
+
+func (PruneEvaluationEventsArgs) Kind() string {
+	return "prune_evaluation_events"
+}
+
+// PruneEvaluationEventsInterval is how often old evaluation events are pruned
+const PruneEvaluationEventsInterval = time.Hour
+
+func (PruneEvaluationEventsArgs) InsertOpts() river.InsertOpts {
+	return river.InsertOpts{
+		Queue:      "prune_evaluation_events",
+		UniqueOpts: river.UniqueOpts{ByPeriod: PruneEvaluationEventsInterval},
+	}
+}
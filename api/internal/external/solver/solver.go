@@ -4,16 +4,39 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
 	"api/internal/model"
+	"sdk/types"
 
 	"resty.dev/v3"
 )
 
 type CheckSegmentConflictResponse struct {
 	Valid bool
+	// Explanation lists, for every attribute in the solver's model, the conditions
+	// of each segment that a single user can satisfy together. It is empty when Valid is true.
+	Explanation []ConflictExplanation
+}
+
+// ConflictExplanation describes one attribute on which the segments overlap
+type ConflictExplanation struct {
+	Attribute  string
+	Value      interface{}
+	Conditions []ConflictCondition
+}
+
+// ConflictCondition is a segment condition on the attribute of a ConflictExplanation
+type ConflictCondition struct {
+	SegmentID   uint
+	SegmentName string
+	RuleName    string
+	Operator    model.ConditionOperator
+	Value       string
 }
 
 type SolverResponse struct {
@@ -95,11 +118,53 @@ func (s *solver) CheckSegmentsConflict(segments []model.Segment) (*CheckSegmentC
 		return nil, err
 	}
 
+	if result.CheckResult != "sat" {
+		return &CheckSegmentConflictResponse{
+			Valid: true,
+		}, nil
+	}
+
 	return &CheckSegmentConflictResponse{
-		Valid: result.CheckResult != "sat",
+		Valid:       false,
+		Explanation: explainConflict(segments, &result),
 	}, nil
 }
 
+// explainConflict pairs each attribute of a satisfiable model with the segment conditions
+// that constrain it, sorted by attribute name
+func explainConflict(segments []model.Segment, result *SolverResponse) []ConflictExplanation {
+	explanations := make([]ConflictExplanation, 0, len(result.Model))
+	for _, assignment := range result.Model {
+		explanation := ConflictExplanation{
+			Attribute:  assignment.Name,
+			Value:      assignment.Value,
+			Conditions: make([]ConflictCondition, 0),
+		}
+		for _, segment := range segments {
+			for _, rule := range segment.Rules {
+				for _, condition := range rule.Conditions {
+					if condition.Attribute == nil || condition.Attribute.Name != assignment.Name {
+						continue
+					}
+					explanation.Conditions = append(explanation.Conditions, ConflictCondition{
+						SegmentID:   segment.ID,
+						SegmentName: segment.Name,
+						RuleName:    rule.Name,
+						Operator:    condition.Operator,
+						Value:       condition.Value,
+					})
+				}
+			}
+		}
+		explanations = append(explanations, explanation)
+	}
+
+	sort.Slice(explanations, func(i, j int) bool {
+		return explanations[i].Attribute < explanations[j].Attribute
+	})
+	return explanations
+}
+
 func (s *solver) parse(segments []model.Segment) (string, error) {
 	rules := make([][]string, 0)
 	attributeMap := make(map[string]Attribute)
@@ -158,7 +223,7 @@ func (s *solver) getZ3Type(dataType model.DataType) string {
 	switch dataType {
 	case model.DataTypeString, model.DataTypeEnum:
 		return "String"
-	case model.DataTypeNumber:
+	case model.DataTypeNumber, model.DataTypeDate:
 		return "Int"
 	case model.DataTypeBoolean:
 		return "Bool"
@@ -167,8 +232,31 @@ func (s *solver) getZ3Type(dataType model.DataType) string {
 	}
 }
 
+// datesToEpochs replaces the comma separated dates of a date condition value with their unix epoch
+// seconds, so date attributes are compared as Int; values that are not dates are kept
+func datesToEpochs(value string) string {
+	dates := strings.Split(value, ",")
+	for i, date := range dates {
+		if parsed, err := types.ParseDate(strings.TrimSpace(date)); err == nil {
+			dates[i] = strconv.FormatInt(parsed.Unix(), 10)
+		}
+	}
+	return strings.Join(dates, ",")
+}
+
+// formatZ3Int formats a whole number as a Z3 Int literal, since Z3 has no negative literals
+func formatZ3Int(value float64) string {
+	if value < 0 {
+		return fmt.Sprintf("(- %s)", strconv.FormatFloat(-value, 'f', 0, 64))
+	}
+	return strconv.FormatFloat(value, 'f', 0, 64)
+}
+
 // conditionToZ3 converts a condition to Z3 expression string
 func (s *solver) conditionToZ3(attrName string, dataType model.DataType, operator model.ConditionOperator, value string) string {
+	if dataType == model.DataTypeDate {
+		value = datesToEpochs(value)
+	}
 	switch operator {
 	case model.ConditionOperatorEquals:
 		if dataType == model.DataTypeString || dataType == model.DataTypeEnum {
@@ -194,6 +282,22 @@ func (s *solver) conditionToZ3(attrName string, dataType model.DataType, operato
 	case model.ConditionOperatorLessThanOrEqual:
 		return fmt.Sprintf("(<= %s %s)", attrName, value)
 
+	case model.ConditionOperatorBetween:
+		// BETWEEN operator: (and (>= attr min) (<= attr max)), with bounds rounded inward for Int attributes
+		min, max, err := model.ParseBetweenBounds(value)
+		if err != nil {
+			return "false"
+		}
+		return fmt.Sprintf("(and (>= %s %s) (<= %s %s))", attrName, formatZ3Int(math.Ceil(min)), attrName, formatZ3Int(math.Floor(max)))
+
+	case model.ConditionOperatorNotBetween:
+		// NOT BETWEEN operator: (or (< attr min) (> attr max))
+		min, max, err := model.ParseBetweenBounds(value)
+		if err != nil {
+			return "false"
+		}
+		return fmt.Sprintf("(or (< %s %s) (> %s %s))", attrName, formatZ3Int(math.Ceil(min)), attrName, formatZ3Int(math.Floor(max)))
+
 	case model.ConditionOperatorIn:
 		// IN operator: (or (= attr "val1") (= attr "val2") ...)
 		values := strings.Split(value, ",")
@@ -237,6 +341,20 @@ func (s *solver) conditionToZ3(attrName string, dataType model.DataType, operato
 		// NOT CONTAINS: (not (str.contains attr "val"))
 		return fmt.Sprintf(`(not (str.contains %s "%s"))`, attrName, value)
 
+	case model.ConditionOperatorMatchesRegex, model.ConditionOperatorNotMatchesRegex:
+		// Regular expressions are not translated to Z3, so the condition is left unconstrained
+		// and overlapping segments are reported conservatively
+		return "true"
+
+	case model.ConditionOperatorSemverGt, model.ConditionOperatorSemverLt, model.ConditionOperatorSemverGte,
+		model.ConditionOperatorSemverLte, model.ConditionOperatorSemverEq:
+		// Z3 has no version sort, so version comparisons are left unconstrained like regular expressions
+		return "true"
+
+	case model.ConditionOperatorIsSet, model.ConditionOperatorIsNotSet:
+		// Z3 attributes always hold a value, so presence checks are left unconstrained like regular expressions
+		return "true"
+
 	default:
 		return ""
 	}
@@ -2,6 +2,7 @@ package solver
 
 import (
 	"api/internal/model"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -165,3 +166,44 @@ func TestParse(t *testing.T) {
 	}
 
 }
+
+func TestExplainConflict(t *testing.T) {
+	country := &model.Attribute{Name: "country", DataType: model.DataTypeString}
+	age := &model.Attribute{Name: "age", DataType: model.DataTypeNumber}
+	segments := []model.Segment{
+		{
+			ID:   1,
+			Name: "adults",
+			Rules: []model.SegmentRule{
+				{Name: "adult", Conditions: []model.SegmentRuleCondition{
+					{Operator: model.ConditionOperatorGreaterThanOrEqual, Value: "18", Attribute: age},
+				}},
+			},
+		},
+		{
+			ID:   2,
+			Name: "vietnam",
+			Rules: []model.SegmentRule{
+				{Name: "vn", Conditions: []model.SegmentRuleCondition{
+					{Operator: model.ConditionOperatorEquals, Value: "VN", Attribute: country},
+					{Operator: model.ConditionOperatorLessThan, Value: "30", Attribute: age},
+				}},
+			},
+		},
+	}
+
+	var result SolverResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"check_result":"sat","model":[{"name":"country","value":"VN"},{"name":"age","value":18}]}`), &result))
+
+	explanation := explainConflict(segments, &result)
+
+	require.Len(t, explanation, 2)
+	require.Equal(t, "age", explanation[0].Attribute)
+	require.EqualValues(t, 18, explanation[0].Value)
+	require.Equal(t, []ConflictCondition{
+		{SegmentID: 1, SegmentName: "adults", RuleName: "adult", Operator: model.ConditionOperatorGreaterThanOrEqual, Value: "18"},
+		{SegmentID: 2, SegmentName: "vietnam", RuleName: "vn", Operator: model.ConditionOperatorLessThan, Value: "30"},
+	}, explanation[0].Conditions)
+	require.Equal(t, "country", explanation[1].Attribute)
+	require.Len(t, explanation[1].Conditions, 1)
+}
@@ -25,6 +25,7 @@ func NewApp(configPath string) *fx.App {
 		LoggerModule,
 		DatabaseModule,
 		RepositoryModule,
+		BroadcastModule,
 		ServiceModule,
 		HandlerModule,
 		ServerModule,
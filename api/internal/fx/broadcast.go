@@ -0,0 +1,44 @@
+package fx
+
+import (
+	"api/config"
+	"api/internal/broadcast"
+	"api/internal/database"
+	"context"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+type BroadcastParams struct {
+	fx.In
+	Config *config.Config
+	DB     *gorm.DB
+	Logger zerolog.Logger
+	Hub    *broadcast.Hub
+}
+
+// ProvideBroadcastPublisher publishes SDK change events to the hub of this process, or through
+// Postgres when it fans them out to every replica. In that case the hub listens for them.
+func ProvideBroadcastPublisher(lc fx.Lifecycle, params BroadcastParams) broadcast.Publisher {
+	if !params.Config.SDK.Stream.PostgresNotify {
+		return params.Hub
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			return broadcast.Listen(ctx, database.DSN(params.Config), params.Hub, params.Logger)
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return broadcast.NewPostgresPublisher(params.DB)
+}
+
+var BroadcastModule = fx.Module("broadcast",
+	fx.Provide(broadcast.NewHub, ProvideBroadcastPublisher),
+)
@@ -2,6 +2,7 @@ package fx
 
 import (
 	"api/config"
+	"api/internal/broadcast"
 	"api/internal/handler"
 	"api/internal/service"
 
@@ -13,11 +14,12 @@ type HandlerParams struct {
 	fx.In
 	Service service.Service
 	Config  *config.Config
+	Hub     *broadcast.Hub
 }
 
 // ProvideHandler provides the handler instance
 func ProvideHandler(params HandlerParams) *handler.Handler {
-	return handler.New(params.Service, params.Config)
+	return handler.New(params.Service, params.Config, params.Hub)
 }
 
 // HandlerModule provides the handler module
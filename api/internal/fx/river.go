@@ -1,39 +1,59 @@
 package fx
 
 import (
-	"api/config"
+	"api/internal/dto"
 	"context"
-	"fmt"
+	"database/sql"
 
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/riverqueue/river"
-	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/riverdriver/riverdatabasesql"
 	"github.com/riverqueue/river/rivertype"
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
+	"gorm.io/gorm"
 )
 
 type RiverParams struct {
 	fx.In
-	Config  *config.Config
+	DB      *gorm.DB
 	Logger  zerolog.Logger
 	Workers *river.Workers
 }
 
-func ProvideRiver(lc fx.Lifecycle, params RiverParams) *river.Client[pgx.Tx] {
-	databaseUrl := fmt.Sprintf("postgres://%s:%s@%s:%d/%s", params.Config.Database.User, params.Config.Database.Password, params.Config.Database.Host, params.Config.Database.Port, params.Config.Database.DBName)
-	dbPool, err := pgxpool.New(context.Background(), databaseUrl)
+// ProvideRiver shares the GORM connection pool with river, so jobs can be inserted
+// in the same transaction as the data change they sync
+func ProvideRiver(lc fx.Lifecycle, params RiverParams) *river.Client[*sql.Tx] {
+	sqlDB, err := params.DB.DB()
 	if err != nil {
 		panic(err)
 	}
 	workers := params.Workers
-	riverClient, err := river.NewClient(riverpgxv5.New(dbPool), &river.Config{
+	riverClient, err := river.NewClient(riverdatabasesql.New(sqlDB), &river.Config{
 		Workers: workers,
 		Queues: map[string]river.QueueConfig{
-			river.QueueDefault: {MaxWorkers: 1},
-			"sync_experiment":  {MaxWorkers: 1},
-			"sync_parameter":   {MaxWorkers: 1},
+			river.QueueDefault:          {MaxWorkers: 1},
+			"sync_experiment":           {MaxWorkers: 1},
+			"sync_parameter":            {MaxWorkers: 1},
+			"advance_experiment_status": {MaxWorkers: 1},
+			"webhook":                   {MaxWorkers: 1},
+			"prune_evaluation_events":   {MaxWorkers: 1},
+		},
+		// Periodic jobs are only scheduled by the elected leader among the API replicas
+		PeriodicJobs: []*river.PeriodicJob{
+			river.NewPeriodicJob(
+				river.PeriodicInterval(dto.AdvanceExperimentStatusInterval),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return dto.AdvanceExperimentStatusArgs{}, nil
+				},
+				&river.PeriodicJobOpts{RunOnStart: true},
+			),
+			river.NewPeriodicJob(
+				river.PeriodicInterval(dto.PruneEvaluationEventsInterval),
+				func() (river.JobArgs, *river.InsertOpts) {
+					return dto.PruneEvaluationEventsArgs{}, nil
+				},
+				nil,
+			),
 		},
 		Middleware: []rivertype.Middleware{
 			&loggingMiddleware{
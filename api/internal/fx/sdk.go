@@ -21,10 +21,12 @@ func ProvideSDK(lc fx.Lifecycle, params SDKParams) sdk.Client {
 		S3BucketName: params.Cfg.S3.BucketName,
 		EndpointURL:  fmt.Sprintf("http://localhost:%d", params.Cfg.Service.Port),
 		ServiceName:  "aurora",
+		APIKey:       params.Cfg.SDK.APIKey,
 	},
 		sdk.WithPath("sdk-dump"),
 		sdk.WithLogLevel(slog.LevelError),
 		sdk.WithRefreshRate(1*time.Minute),
+		sdk.WithStreaming(true),
 		sdk.WithEnableS3(true),
 		sdk.WithBatchMaxSize(1),
 		sdk.WithBatchFlushSize(1),
@@ -40,8 +42,7 @@ func ProvideSDK(lc fx.Lifecycle, params SDKParams) sdk.Client {
 	slog.Info("before start")
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
-			client.Stop()
-			return nil
+			return client.Stop(ctx)
 		},
 		OnStart: func(ctx context.Context) error {
 			err := client.Start(context.WithoutCancel(ctx))
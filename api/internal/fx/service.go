@@ -1,12 +1,14 @@
 package fx
 
 import (
+	"api/config"
 	"api/internal/external/solver"
 	"api/internal/repository"
 	"api/internal/service"
+	"database/sql"
 	"sdk"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/riverqueue/river"
 	"go.uber.org/fx"
 )
@@ -15,14 +17,16 @@ import (
 type ServiceParams struct {
 	fx.In
 	Repository   repository.Repository
-	RiverClient  *river.Client[pgx.Tx]
+	RiverClient  *river.Client[*sql.Tx]
 	AuroraClient sdk.Client
 	Solver       solver.Solver
+	S3Client     *s3.Client
+	Config       *config.Config
 }
 
 // ProvideService provides the service instance
 func ProvideService(params ServiceParams) service.Service {
-	return service.New(params.Repository, params.RiverClient, params.AuroraClient, params.Solver)
+	return service.New(params.Repository, params.RiverClient, params.AuroraClient, params.Solver, params.S3Client, params.Config)
 }
 
 // ServiceModule provides the service module
@@ -2,6 +2,7 @@ package fx
 
 import (
 	"api/config"
+	"api/internal/broadcast"
 	"api/internal/repository"
 	internalWorkers "api/internal/workers"
 
@@ -15,6 +16,7 @@ type WorkerParams struct {
 	Repository repository.Repository
 	Cfg        *config.Config
 	S3         *s3.Client
+	Publisher  broadcast.Publisher
 }
 
 func ProvideWorker(params WorkerParams) *river.Workers {
@@ -23,11 +25,24 @@ func ProvideWorker(params WorkerParams) *river.Workers {
 		Repository: params.Repository,
 		Cfg:        *params.Cfg,
 		S3:         params.S3,
+		Publisher:  params.Publisher,
 	})
 	river.AddWorker(workers, &internalWorkers.SyncExperimentWorker{
 		Repository: params.Repository,
 		Cfg:        *params.Cfg,
 		S3:         params.S3,
+		Publisher:  params.Publisher,
+	})
+	river.AddWorker(workers, &internalWorkers.AdvanceExperimentStatusWorker{
+		Repository: params.Repository,
+		Cfg:        *params.Cfg,
+	})
+	river.AddWorker(workers, &internalWorkers.ExperimentStatusWebhookWorker{
+		Cfg: *params.Cfg,
+	})
+	river.AddWorker(workers, &internalWorkers.PruneEvaluationEventsWorker{
+		Repository: params.Repository,
+		Cfg:        *params.Cfg,
 	})
 	return workers
 }
@@ -2,9 +2,13 @@ package handler
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"api/config"
+	"api/internal/broadcast"
 	"api/internal/dto"
+	"api/internal/model"
 	"api/internal/service"
 
 	"github.com/rs/zerolog/log"
@@ -13,27 +17,34 @@ import (
 type Handler struct {
 	service service.Service
 	config  *config.Config
+	hub     *broadcast.Hub
 }
 
-func New(svc service.Service, cfg *config.Config) *Handler {
+func New(svc service.Service, cfg *config.Config, hub *broadcast.Hub) *Handler {
 	return &Handler{
 		service: svc,
 		config:  cfg,
+		hub:     hub,
 	}
 }
 
-func (h *Handler) HealthCheck(ctx context.Context) (string, error) {
+func (h *Handler) HealthCheck(ctx context.Context) *dto.HealthCheckResponse {
 	logger := log.Ctx(ctx).With().Str("handler", "health-check").Logger()
 	logger.Info().Msg("Health check")
-	return "OK", nil
+
+	response := h.service.HealthCheck(ctx)
+	if response.Status != dto.HealthStatusOK {
+		logger.Warn().Str("db", response.Checks.DB).Str("river", response.Checks.River).Str("s3", response.Checks.S3).Msg("Health check failed")
+	}
+	return &response
 }
 
 // CreateAttribute handles the business logic for creating an attribute
-func (h *Handler) CreateAttribute(ctx context.Context, req *dto.CreateAttributeRequest) (*dto.AttributeResponse, error) {
+func (h *Handler) CreateAttribute(ctx context.Context, userID uint, req *dto.CreateAttributeRequest) (*dto.AttributeResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "create-attribute").Logger()
 	logger.Info().Msg("Creating attribute")
 
-	attr, err := h.service.CreateAttribute(ctx, req)
+	attr, err := h.service.CreateAttribute(ctx, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to create attribute")
 		return nil, err
@@ -43,12 +54,12 @@ func (h *Handler) CreateAttribute(ctx context.Context, req *dto.CreateAttributeR
 	return &response, nil
 }
 
-// GetAllAttributes handles the business logic for getting all attributes
-func (h *Handler) GetAllAttributes(ctx context.Context) ([]dto.AttributeResponse, error) {
-	logger := log.Ctx(ctx).With().Str("handler", "get-all-attributes").Logger()
+// GetAllAttributes handles the business logic for listing attributes
+func (h *Handler) GetAllAttributes(ctx context.Context, filter model.AttributeFilter) (*dto.AttributePageResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-all-attributes").Str("dataType", string(filter.DataType)).Str("search", filter.Search).Bool("inUseOnly", filter.InUseOnly).Str("sort", filter.Sort).Int("limit", filter.Limit).Int("offset", filter.Offset).Logger()
 	logger.Info().Msg("Getting all attributes")
 
-	attrs, err := h.service.GetAllAttributes(ctx)
+	attrs, total, err := h.service.ListAttributes(ctx, filter)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to get all attributes")
 		return nil, err
@@ -59,7 +70,12 @@ func (h *Handler) GetAllAttributes(ctx context.Context) ([]dto.AttributeResponse
 		responses[i] = dto.ToAttributeResponse(attr)
 	}
 
-	return responses, nil
+	return &dto.AttributePageResponse{
+		Items:  responses,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
 }
 
 // GetAttributeByID handles the business logic for getting an attribute by ID
@@ -78,11 +94,11 @@ func (h *Handler) GetAttributeByID(ctx context.Context, id uint) (*dto.Attribute
 }
 
 // UpdateAttribute handles the business logic for updating an attribute
-func (h *Handler) UpdateAttribute(ctx context.Context, id uint, req *dto.UpdateAttributeRequest) (*dto.AttributeResponse, error) {
+func (h *Handler) UpdateAttribute(ctx context.Context, id uint, userID uint, req *dto.UpdateAttributeRequest) (*dto.AttributeResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "update-attribute").Uint("id", id).Logger()
 	logger.Info().Msg("Updating attribute")
 
-	attr, err := h.service.UpdateAttribute(ctx, id, req)
+	attr, err := h.service.UpdateAttribute(ctx, id, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to update attribute")
 		return nil, err
@@ -93,11 +109,11 @@ func (h *Handler) UpdateAttribute(ctx context.Context, id uint, req *dto.UpdateA
 }
 
 // DeleteAttribute handles the business logic for deleting an attribute
-func (h *Handler) DeleteAttribute(ctx context.Context, id uint) error {
+func (h *Handler) DeleteAttribute(ctx context.Context, id uint, userID uint) error {
 	logger := log.Ctx(ctx).With().Str("handler", "delete-attribute").Uint("id", id).Logger()
 	logger.Info().Msg("Deleting attribute")
 
-	err := h.service.DeleteAttribute(ctx, id)
+	err := h.service.DeleteAttribute(ctx, id, userID)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to delete attribute")
 		return err
@@ -134,12 +150,26 @@ func (h *Handler) DecrementAttributeUsageCount(ctx context.Context, id uint) err
 	return nil
 }
 
+// MergeAttribute handles the business logic for merging an attribute into another
+func (h *Handler) MergeAttribute(ctx context.Context, id uint, targetID uint, userID uint, dryRun bool) (*dto.MergeAttributeResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "merge-attribute").Uint("id", id).Uint("targetId", targetID).Bool("dryRun", dryRun).Logger()
+	logger.Info().Msg("Merging attribute")
+
+	response, err := h.service.MergeAttribute(ctx, id, targetID, userID, dryRun)
+	if err != nil {
+		logger.Error().Err(err).Uint("id", id).Msg("Failed to merge attribute")
+		return nil, err
+	}
+
+	return response, nil
+}
+
 // CreateSegment handles the business logic for creating a segment
-func (h *Handler) CreateSegment(ctx context.Context, req *dto.CreateSegmentRequest) (*dto.SegmentResponse, error) {
+func (h *Handler) CreateSegment(ctx context.Context, userID uint, req *dto.CreateSegmentRequest) (*dto.SegmentResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "create-segment").Logger()
 	logger.Info().Msg("Creating segment")
 
-	segment, err := h.service.CreateSegment(ctx, req)
+	segment, err := h.service.CreateSegment(ctx, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to create segment")
 		return nil, err
@@ -149,12 +179,12 @@ func (h *Handler) CreateSegment(ctx context.Context, req *dto.CreateSegmentReque
 	return &response, nil
 }
 
-// GetAllSegments handles the business logic for getting all segments
-func (h *Handler) GetAllSegments(ctx context.Context) ([]dto.SegmentResponse, error) {
-	logger := log.Ctx(ctx).With().Str("handler", "get-all-segments").Logger()
+// GetAllSegments handles the business logic for getting a page of segments
+func (h *Handler) GetAllSegments(ctx context.Context, limit, offset int, search string) (*dto.SegmentPageResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-all-segments").Int("limit", limit).Int("offset", offset).Str("search", search).Logger()
 	logger.Info().Msg("Getting all segments")
 
-	segments, err := h.service.GetAllSegments(ctx)
+	segments, total, err := h.service.GetAllSegments(ctx, limit, offset, search)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to get all segments")
 		return nil, err
@@ -165,7 +195,12 @@ func (h *Handler) GetAllSegments(ctx context.Context) ([]dto.SegmentResponse, er
 		responses[i] = dto.ToSegmentResponse(segment)
 	}
 
-	return responses, nil
+	return &dto.SegmentPageResponse{
+		Items:  responses,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
 }
 
 // GetSegmentByID handles the business logic for getting a segment by ID
@@ -184,11 +219,11 @@ func (h *Handler) GetSegmentByID(ctx context.Context, id uint) (*dto.SegmentResp
 }
 
 // UpdateSegment handles the business logic for updating a segment
-func (h *Handler) UpdateSegment(ctx context.Context, id uint, req *dto.UpdateSegmentRequest) (*dto.SegmentResponse, error) {
+func (h *Handler) UpdateSegment(ctx context.Context, id uint, userID uint, req *dto.UpdateSegmentRequest) (*dto.SegmentResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "update-segment").Uint("id", id).Logger()
 	logger.Info().Msg("Updating segment")
 
-	segment, err := h.service.UpdateSegment(ctx, id, req)
+	segment, err := h.service.UpdateSegment(ctx, id, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to update segment")
 		return nil, err
@@ -199,11 +234,11 @@ func (h *Handler) UpdateSegment(ctx context.Context, id uint, req *dto.UpdateSeg
 }
 
 // DeleteSegment handles the business logic for deleting a segment
-func (h *Handler) DeleteSegment(ctx context.Context, id uint) error {
+func (h *Handler) DeleteSegment(ctx context.Context, id uint, userID uint) error {
 	logger := log.Ctx(ctx).With().Str("handler", "delete-segment").Uint("id", id).Logger()
 	logger.Info().Msg("Deleting segment")
 
-	err := h.service.DeleteSegment(ctx, id)
+	err := h.service.DeleteSegment(ctx, id, userID)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to delete segment")
 		return err
@@ -217,23 +252,21 @@ func (h *Handler) CheckSegmentOverlap(ctx context.Context, req *dto.CheckSegment
 	logger := log.Ctx(ctx).With().Str("handler", "check-segment-overlap").Logger()
 	logger.Info().Msg("Checking segment overlap")
 
-	overlap, err := h.service.CheckSegmentOverlap(ctx, req.SegmentIDs)
+	response, err := h.service.CheckSegmentOverlap(ctx, req.SegmentIDs)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to check segment overlap")
 		return nil, err
 	}
 
-	return &dto.CheckSegmentOverlapResponse{
-		Overlap: overlap,
-	}, nil
+	return response, nil
 }
 
 // CreateParameter handles the business logic for creating a parameter
-func (h *Handler) CreateParameter(ctx context.Context, req *dto.CreateParameterRequest) (*dto.ParameterResponse, error) {
+func (h *Handler) CreateParameter(ctx context.Context, userID uint, req *dto.CreateParameterRequest) (*dto.ParameterResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "create-parameter").Logger()
 	logger.Info().Msg("Creating parameter")
 
-	parameter, err := h.service.CreateParameter(ctx, req)
+	parameter, err := h.service.CreateParameter(ctx, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to create parameter")
 		return nil, err
@@ -243,12 +276,12 @@ func (h *Handler) CreateParameter(ctx context.Context, req *dto.CreateParameterR
 	return &response, nil
 }
 
-// GetAllParameters handles the business logic for getting all parameters
-func (h *Handler) GetAllParameters(ctx context.Context) ([]dto.ParameterResponse, error) {
-	logger := log.Ctx(ctx).With().Str("handler", "get-all-parameters").Logger()
+// GetAllParameters handles the business logic for getting a page of parameters
+func (h *Handler) GetAllParameters(ctx context.Context, limit int, after uint, includeArchived bool) (*dto.ParameterPageResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-all-parameters").Int("limit", limit).Uint("after", after).Bool("includeArchived", includeArchived).Logger()
 	logger.Info().Msg("Getting all parameters")
 
-	parameters, err := h.service.GetAllParameters(ctx)
+	parameters, nextCursor, err := h.service.GetParametersPage(ctx, limit, after, includeArchived)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to get all parameters")
 		return nil, err
@@ -259,7 +292,32 @@ func (h *Handler) GetAllParameters(ctx context.Context) ([]dto.ParameterResponse
 		responses[i] = dto.ToParameterResponse(parameter)
 	}
 
-	return responses, nil
+	return &dto.ParameterPageResponse{
+		Items:      responses,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// GetDeletedParameters handles the business logic for listing deleted parameters page by page
+func (h *Handler) GetDeletedParameters(ctx context.Context, limit int, after uint) (*dto.ParameterPageResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-deleted-parameters").Int("limit", limit).Uint("after", after).Logger()
+	logger.Info().Msg("Getting deleted parameters")
+
+	parameters, nextCursor, err := h.service.GetDeletedParametersPage(ctx, limit, after)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get deleted parameters")
+		return nil, err
+	}
+
+	responses := make([]dto.ParameterResponse, len(parameters))
+	for i, parameter := range parameters {
+		responses[i] = dto.ToParameterResponse(parameter)
+	}
+
+	return &dto.ParameterPageResponse{
+		Items:      responses,
+		NextCursor: nextCursor,
+	}, nil
 }
 
 // GetParameterByID handles the business logic for getting a parameter by ID
@@ -278,11 +336,11 @@ func (h *Handler) GetParameterByID(ctx context.Context, id uint) (*dto.Parameter
 }
 
 // UpdateParameter handles the business logic for updating a parameter
-func (h *Handler) UpdateParameter(ctx context.Context, id uint, req *dto.UpdateParameterRequest) (*dto.ParameterResponse, error) {
+func (h *Handler) UpdateParameter(ctx context.Context, id uint, userID uint, req *dto.UpdateParameterRequest) (*dto.ParameterResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "update-parameter").Uint("id", id).Logger()
 	logger.Info().Msg("Updating parameter")
 
-	parameter, err := h.service.UpdateParameter(ctx, id, req)
+	parameter, err := h.service.UpdateParameter(ctx, id, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to update parameter")
 		return nil, err
@@ -293,11 +351,11 @@ func (h *Handler) UpdateParameter(ctx context.Context, id uint, req *dto.UpdateP
 }
 
 // UpdateParameterWithRules handles the business logic for comprehensive parameter update with rules
-func (h *Handler) UpdateParameterWithRules(ctx context.Context, id uint, req *dto.UpdateParameterWithRulesRequest) (*dto.ParameterResponse, error) {
+func (h *Handler) UpdateParameterWithRules(ctx context.Context, id uint, userID uint, req *dto.UpdateParameterWithRulesRequest) (*dto.ParameterResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "update-parameter-with-rules").Uint("id", id).Logger()
 	logger.Info().Msg("Updating parameter with rules")
 
-	parameter, err := h.service.UpdateParameterWithRules(ctx, id, req)
+	parameter, err := h.service.UpdateParameterWithRules(ctx, id, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to update parameter with rules")
 		return nil, err
@@ -308,11 +366,11 @@ func (h *Handler) UpdateParameterWithRules(ctx context.Context, id uint, req *dt
 }
 
 // DeleteParameter handles the business logic for deleting a parameter
-func (h *Handler) DeleteParameter(ctx context.Context, id uint) error {
+func (h *Handler) DeleteParameter(ctx context.Context, id uint, userID uint) error {
 	logger := log.Ctx(ctx).With().Str("handler", "delete-parameter").Uint("id", id).Logger()
 	logger.Info().Msg("Deleting parameter")
 
-	err := h.service.DeleteParameter(ctx, id)
+	err := h.service.DeleteParameter(ctx, id, userID)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to delete parameter")
 		return err
@@ -321,12 +379,72 @@ func (h *Handler) DeleteParameter(ctx context.Context, id uint) error {
 	return nil
 }
 
+// ArchiveParameter handles the business logic for archiving a parameter
+func (h *Handler) ArchiveParameter(ctx context.Context, id uint, userID uint) (*dto.ParameterResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "archive-parameter").Uint("id", id).Logger()
+	logger.Info().Msg("Archiving parameter")
+
+	parameter, err := h.service.ArchiveParameter(ctx, id, userID)
+	if err != nil {
+		logger.Error().Err(err).Uint("id", id).Msg("Failed to archive parameter")
+		return nil, err
+	}
+
+	response := dto.ToParameterResponse(parameter)
+	return &response, nil
+}
+
+// RestoreParameter handles the business logic for restoring a deleted parameter
+func (h *Handler) RestoreParameter(ctx context.Context, id uint, userID uint) (*dto.ParameterResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "restore-parameter").Uint("id", id).Logger()
+	logger.Info().Msg("Restoring parameter")
+
+	parameter, err := h.service.RestoreParameter(ctx, id, userID)
+	if err != nil {
+		logger.Error().Err(err).Uint("id", id).Msg("Failed to restore parameter")
+		return nil, err
+	}
+
+	response := dto.ToParameterResponse(parameter)
+	return &response, nil
+}
+
+// CloneParameter handles the business logic for cloning a parameter
+func (h *Handler) CloneParameter(ctx context.Context, id uint, userID uint, req *dto.CloneParameterRequest) (*dto.ParameterResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "clone-parameter").Uint("id", id).Logger()
+	logger.Info().Msg("Cloning parameter")
+
+	parameter, err := h.service.CloneParameter(ctx, id, userID, req)
+	if err != nil {
+		logger.Error().Err(err).Uint("id", id).Msg("Failed to clone parameter")
+		return nil, err
+	}
+
+	response := dto.ToParameterResponse(parameter)
+	return &response, nil
+}
+
+// UnarchiveParameter handles the business logic for unarchiving a parameter
+func (h *Handler) UnarchiveParameter(ctx context.Context, id uint, userID uint) (*dto.ParameterResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "unarchive-parameter").Uint("id", id).Logger()
+	logger.Info().Msg("Unarchiving parameter")
+
+	parameter, err := h.service.UnarchiveParameter(ctx, id, userID)
+	if err != nil {
+		logger.Error().Err(err).Uint("id", id).Msg("Failed to unarchive parameter")
+		return nil, err
+	}
+
+	response := dto.ToParameterResponse(parameter)
+	return &response, nil
+}
+
 // AddParameterRule handles the business logic for adding a rule to a parameter
-func (h *Handler) AddParameterRule(ctx context.Context, id uint, req *dto.CreateParameterRuleRequest) (*dto.ParameterResponse, error) {
+func (h *Handler) AddParameterRule(ctx context.Context, id uint, userID uint, req *dto.CreateParameterRuleRequest) (*dto.ParameterResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "add-parameter-rule").Uint("id", id).Logger()
 	logger.Info().Msg("Adding parameter rule")
 
-	parameter, err := h.service.AddParameterRule(ctx, id, req)
+	parameter, err := h.service.AddParameterRule(ctx, id, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to add parameter rule")
 		return nil, err
@@ -337,11 +455,11 @@ func (h *Handler) AddParameterRule(ctx context.Context, id uint, req *dto.Create
 }
 
 // UpdateParameterRule handles the business logic for updating a parameter rule
-func (h *Handler) UpdateParameterRule(ctx context.Context, id uint, ruleID uint, req *dto.UpdateParameterRuleRequest) (*dto.ParameterResponse, error) {
+func (h *Handler) UpdateParameterRule(ctx context.Context, id uint, ruleID uint, userID uint, req *dto.UpdateParameterRuleRequest) (*dto.ParameterResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "update-parameter-rule").Uint("id", id).Uint("ruleId", ruleID).Logger()
 	logger.Info().Msg("Updating parameter rule")
 
-	parameter, err := h.service.UpdateParameterRule(ctx, id, ruleID, req)
+	parameter, err := h.service.UpdateParameterRule(ctx, id, ruleID, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Uint("ruleId", ruleID).Msg("Failed to update parameter rule")
 		return nil, err
@@ -352,11 +470,11 @@ func (h *Handler) UpdateParameterRule(ctx context.Context, id uint, ruleID uint,
 }
 
 // DeleteParameterRule handles the business logic for deleting a parameter rule
-func (h *Handler) DeleteParameterRule(ctx context.Context, id uint, ruleID uint) (*dto.ParameterResponse, error) {
+func (h *Handler) DeleteParameterRule(ctx context.Context, id uint, ruleID uint, userID uint) (*dto.ParameterResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "delete-parameter-rule").Uint("id", id).Uint("ruleId", ruleID).Logger()
 	logger.Info().Msg("Deleting parameter rule")
 
-	parameter, err := h.service.DeleteParameterRule(ctx, id, ruleID)
+	parameter, err := h.service.DeleteParameterRule(ctx, id, ruleID, userID)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Uint("ruleId", ruleID).Msg("Failed to delete parameter rule")
 		return nil, err
@@ -366,12 +484,27 @@ func (h *Handler) DeleteParameterRule(ctx context.Context, id uint, ruleID uint)
 	return &response, nil
 }
 
+// ReorderParameterRules handles the business logic for reordering the rules of a parameter
+func (h *Handler) ReorderParameterRules(ctx context.Context, id uint, userID uint, req *dto.ReorderParameterRulesRequest) (*dto.ParameterResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "reorder-parameter-rules").Uint("id", id).Logger()
+	logger.Info().Msg("Reordering parameter rules")
+
+	parameter, err := h.service.ReorderParameterRules(ctx, id, userID, req)
+	if err != nil {
+		logger.Error().Err(err).Uint("id", id).Msg("Failed to reorder parameter rules")
+		return nil, err
+	}
+
+	response := dto.ToParameterResponse(parameter)
+	return &response, nil
+}
+
 // CreateExperiment handles the business logic for creating an experiment
-func (h *Handler) CreateExperiment(ctx context.Context, req *dto.CreateExperimentRequest) (string, error) {
+func (h *Handler) CreateExperiment(ctx context.Context, userID uint, req *dto.CreateExperimentRequest) (string, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "create-experiment").Logger()
 	logger.Info().Msg("Creating experiment")
 
-	message, err := h.service.CreateExperiment(ctx, req)
+	message, err := h.service.CreateExperiment(ctx, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to create experiment")
 		return "", err
@@ -382,22 +515,27 @@ func (h *Handler) CreateExperiment(ctx context.Context, req *dto.CreateExperimen
 }
 
 // GetAllExperiments handles the business logic for getting all experiments
-func (h *Handler) GetAllExperiments(ctx context.Context) ([]dto.ExperimentResponse, error) {
-	logger := log.Ctx(ctx).With().Str("handler", "get-all-experiments").Logger()
+func (h *Handler) GetAllExperiments(ctx context.Context, filter model.ExperimentFilter, limit, offset int) (*dto.ExperimentPageResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-all-experiments").Strs("statuses", filter.Statuses).Str("search", filter.Search).Int("limit", limit).Int("offset", offset).Logger()
 	logger.Info().Msg("Getting all experiments")
 
-	experiments, err := h.service.GetAllExperiments(ctx)
+	experiments, total, err := h.service.GetAllExperiments(ctx, filter, limit, offset)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to get all experiments")
 		return nil, err
 	}
 
-	responses := make([]dto.ExperimentResponse, len(experiments))
+	responses := make([]dto.ExperimentSummaryResponse, len(experiments))
 	for i, experiment := range experiments {
-		responses[i] = dto.ToExperimentResponse(experiment)
+		responses[i] = dto.ToExperimentSummaryResponse(experiment)
 	}
 
-	return responses, nil
+	return &dto.ExperimentPageResponse{
+		Items:  responses,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
 }
 
 // GetExperimentByID handles the business logic for getting an experiment by ID with variants and parameters
@@ -415,12 +553,27 @@ func (h *Handler) GetExperimentByID(ctx context.Context, id uint) (*dto.Experime
 	return &response, nil
 }
 
+// GetExperimentByUuid handles the business logic for getting an experiment by UUID with all details
+func (h *Handler) GetExperimentByUuid(ctx context.Context, experimentUUID string) (*dto.ExperimentDetailResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-experiment-by-uuid").Str("uuid", experimentUUID).Logger()
+	logger.Info().Msg("Getting experiment by UUID with details")
+
+	experiment, variants, variantParametersMap, hashAttribute, err := h.service.GetExperimentByUuid(ctx, experimentUUID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get experiment by UUID")
+		return nil, err
+	}
+
+	response := dto.ToExperimentDetailResponse(experiment, variants, variantParametersMap, hashAttribute)
+	return &response, nil
+}
+
 // RejectExperiment handles the business logic for rejecting an experiment
-func (h *Handler) RejectExperiment(ctx context.Context, id uint, req *dto.RejectExperimentRequest) (*dto.ExperimentResponse, error) {
+func (h *Handler) RejectExperiment(ctx context.Context, id uint, userID uint, req *dto.RejectExperimentRequest) (*dto.ExperimentResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "reject-experiment").Uint("id", id).Logger()
 	logger.Info().Msg("Rejecting experiment")
 
-	experiment, err := h.service.RejectExperiment(ctx, id, req)
+	experiment, err := h.service.RejectExperiment(ctx, id, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to reject experiment")
 		return nil, err
@@ -431,11 +584,11 @@ func (h *Handler) RejectExperiment(ctx context.Context, id uint, req *dto.Reject
 }
 
 // ApproveExperiment handles the business logic for approving an experiment
-func (h *Handler) ApproveExperiment(ctx context.Context, id uint, req *dto.ApproveExperimentRequest) (*dto.ExperimentResponse, error) {
+func (h *Handler) ApproveExperiment(ctx context.Context, id uint, userID uint, req *dto.ApproveExperimentRequest) (*dto.ExperimentResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "approve-experiment").Uint("id", id).Logger()
 	logger.Info().Msg("Approving experiment")
 
-	experiment, err := h.service.ApproveExperiment(ctx, id, req)
+	experiment, err := h.service.ApproveExperiment(ctx, id, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to approve experiment")
 		return nil, err
@@ -446,11 +599,11 @@ func (h *Handler) ApproveExperiment(ctx context.Context, id uint, req *dto.Appro
 }
 
 // AbortExperiment handles the business logic for aborting an experiment
-func (h *Handler) AbortExperiment(ctx context.Context, id uint, req *dto.AbortExperimentRequest) (*dto.ExperimentResponse, error) {
+func (h *Handler) AbortExperiment(ctx context.Context, id uint, userID uint, req *dto.AbortExperimentRequest) (*dto.ExperimentResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "abort-experiment").Uint("id", id).Logger()
 	logger.Info().Msg("Aborting experiment")
 
-	experiment, err := h.service.AbortExperiment(ctx, id, req)
+	experiment, err := h.service.AbortExperiment(ctx, id, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Uint("id", id).Msg("Failed to abort experiment")
 		return nil, err
@@ -460,6 +613,184 @@ func (h *Handler) AbortExperiment(ctx context.Context, id uint, req *dto.AbortEx
 	return &response, nil
 }
 
+// PauseExperiment handles the business logic for pausing an experiment
+func (h *Handler) PauseExperiment(ctx context.Context, id uint, userID uint, req *dto.PauseExperimentRequest) (*dto.ExperimentResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "pause-experiment").Uint("id", id).Logger()
+	logger.Info().Msg("Pausing experiment")
+
+	experiment, err := h.service.PauseExperiment(ctx, id, userID, req)
+	if err != nil {
+		logger.Error().Err(err).Uint("id", id).Msg("Failed to pause experiment")
+		return nil, err
+	}
+
+	response := dto.ToExperimentResponse(experiment)
+	return &response, nil
+}
+
+// ResumeExperiment handles the business logic for resuming a paused experiment
+func (h *Handler) ResumeExperiment(ctx context.Context, id uint, userID uint, req *dto.ResumeExperimentRequest) (*dto.ExperimentResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "resume-experiment").Uint("id", id).Logger()
+	logger.Info().Msg("Resuming experiment")
+
+	experiment, err := h.service.ResumeExperiment(ctx, id, userID, req)
+	if err != nil {
+		logger.Error().Err(err).Uint("id", id).Msg("Failed to resume experiment")
+		return nil, err
+	}
+
+	response := dto.ToExperimentResponse(experiment)
+	return &response, nil
+}
+
+// GetAuditLogs handles the business logic for listing audit log entries
+func (h *Handler) GetAuditLogs(ctx context.Context, filter model.AuditLogFilter, limit, offset int) (*dto.AuditLogPageResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-audit-logs").Str("entityType", string(filter.EntityType)).Uint("entityId", filter.EntityID).Uint("userId", filter.ActorUserID).Int("limit", limit).Int("offset", offset).Logger()
+	logger.Info().Msg("Getting audit logs")
+
+	auditLogs, total, err := h.service.GetAuditLogs(ctx, filter, limit, offset)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get audit logs")
+		return nil, err
+	}
+
+	responses := make([]dto.AuditLogResponse, len(auditLogs))
+	for i, auditLog := range auditLogs {
+		responses[i] = dto.ToAuditLogResponse(auditLog)
+	}
+
+	return &dto.AuditLogPageResponse{
+		Items:  responses,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// UpdateExperimentVariants handles the business logic for replacing the variants of a draft experiment
+func (h *Handler) UpdateExperimentVariants(ctx context.Context, id uint, userID uint, req *dto.UpdateExperimentVariantsRequest) (*dto.ExperimentDetailResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "update-experiment-variants").Uint("id", id).Logger()
+	logger.Info().Msg("Updating experiment variants")
+
+	experiment, err := h.service.UpdateExperimentVariants(ctx, id, userID, req)
+	if err != nil {
+		logger.Error().Err(err).Uint("id", id).Msg("Failed to update experiment variants")
+		return nil, err
+	}
+
+	variants := make([]*model.ExperimentVariant, len(experiment.Variants))
+	variantParametersMap := make(map[int][]*model.ExperimentVariantParameter, len(experiment.Variants))
+	for i := range experiment.Variants {
+		variant := &experiment.Variants[i]
+		variants[i] = variant
+		parameters := make([]*model.ExperimentVariantParameter, len(variant.Parameters))
+		for j := range variant.Parameters {
+			parameters[j] = &variant.Parameters[j]
+		}
+		variantParametersMap[variant.ID] = parameters
+	}
+
+	response := dto.ToExperimentDetailResponse(experiment, variants, variantParametersMap, experiment.HashAttribute)
+	return &response, nil
+}
+
+// DeleteExperiment handles the business logic for deleting an experiment
+func (h *Handler) DeleteExperiment(ctx context.Context, id uint, userID uint) error {
+	logger := log.Ctx(ctx).With().Str("handler", "delete-experiment").Uint("id", id).Logger()
+	logger.Info().Msg("Deleting experiment")
+
+	err := h.service.DeleteExperiment(ctx, id, userID)
+	if err != nil {
+		logger.Error().Err(err).Uint("id", id).Msg("Failed to delete experiment")
+		return err
+	}
+
+	return nil
+}
+
+// SetExperimentOverride handles the business logic for forcing an attribute value into a variant of an experiment
+func (h *Handler) SetExperimentOverride(ctx context.Context, id uint, req *dto.SetExperimentOverrideRequest) (*dto.ExperimentOverrideResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "set-experiment-override").Uint("id", id).Logger()
+	logger.Info().Msg("Setting experiment override")
+
+	override, err := h.service.SetExperimentOverride(ctx, id, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to set experiment override")
+		return nil, err
+	}
+
+	response := dto.ToExperimentOverrideResponse(override)
+	return &response, nil
+}
+
+// DeleteExperimentOverride handles the business logic for removing an override of an experiment
+func (h *Handler) DeleteExperimentOverride(ctx context.Context, id uint, overrideID uint) error {
+	logger := log.Ctx(ctx).With().Str("handler", "delete-experiment-override").Uint("id", id).Uint("overrideId", overrideID).Logger()
+	logger.Info().Msg("Deleting experiment override")
+
+	if err := h.service.DeleteExperimentOverride(ctx, id, overrideID); err != nil {
+		logger.Error().Err(err).Msg("Failed to delete experiment override")
+		return err
+	}
+	return nil
+}
+
+// SimulateExperiment handles the business logic for previewing an experiment assignment
+func (h *Handler) SimulateExperiment(ctx context.Context, req *dto.SimulateExperimentRequest) (*dto.SimulateExperimentResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "simulate-experiment").Uint("id", req.ExperimentID).Str("uuid", req.ExperimentUUID).Logger()
+	logger.Info().Msg("Simulating experiment")
+
+	response, err := h.service.SimulateExperiment(ctx, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to simulate experiment")
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// CheckExperimentConflicts handles the business logic for reporting the experiments an experiment would conflict with
+func (h *Handler) CheckExperimentConflicts(ctx context.Context, req *dto.CheckExperimentConflictsRequest) (*dto.ExperimentConflictsResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "check-experiment-conflicts").Int("segmentId", req.SegmentID).Logger()
+	logger.Info().Msg("Checking experiment conflicts")
+
+	response, err := h.service.CheckExperimentConflicts(ctx, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to check experiment conflicts")
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetExperimentExposures handles the business logic for reporting the observed exposures of an experiment
+func (h *Handler) GetExperimentExposures(ctx context.Context, id uint, filter model.ExposureFilter) (*dto.ExperimentExposureResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-experiment-exposures").Uint("id", id).Logger()
+	logger.Info().Msg("Getting experiment exposures")
+
+	response, err := h.service.GetExperimentExposures(ctx, id, filter)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get experiment exposures")
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetExperimentExposuresByUuid handles the business logic for reporting the observed exposures of an experiment by UUID
+func (h *Handler) GetExperimentExposuresByUuid(ctx context.Context, experimentUUID string, filter model.ExposureFilter) (*dto.ExperimentExposureResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-experiment-exposures-by-uuid").Str("uuid", experimentUUID).Logger()
+	logger.Info().Msg("Getting experiment exposures")
+
+	response, err := h.service.GetExperimentExposuresByUuid(ctx, experimentUUID, filter)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get experiment exposures")
+		return nil, err
+	}
+
+	return &response, nil
+}
+
 func (h *Handler) SimulateParameter(ctx context.Context, req *dto.SimulateParameterRequest) (*dto.SimulateParameterResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "simulate-parameter").Logger()
 	logger.Info().Msg("Simulating parameter")
@@ -473,6 +804,34 @@ func (h *Handler) SimulateParameter(ctx context.Context, req *dto.SimulateParame
 	return &response, nil
 }
 
+// ExportParameters handles the business logic for exporting parameters
+func (h *Handler) ExportParameters(ctx context.Context) (*dto.ParameterExportDocument, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "export-parameters").Logger()
+	logger.Info().Msg("Exporting parameters")
+
+	document, err := h.service.ExportParameters(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to export parameters")
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// ImportParameters handles the business logic for importing parameters
+func (h *Handler) ImportParameters(ctx context.Context, userID uint, req *dto.ImportParametersRequest, dryRun bool) (*dto.ImportParametersResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "import-parameters").Uint("userId", userID).Bool("dryRun", dryRun).Logger()
+	logger.Info().Msg("Importing parameters")
+
+	response, err := h.service.ImportParameters(ctx, userID, req, dryRun)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to import parameters")
+		return nil, err
+	}
+
+	return response, nil
+}
+
 func (h *Handler) GetMetdataSDK(ctx context.Context, req *dto.GetMetadataSDKRequest) (*dto.GetMetadataSDKResponse, error) {
 	return &dto.GetMetadataSDKResponse{
 		EnableS3: h.config.S3.Enable,
@@ -481,7 +840,7 @@ func (h *Handler) GetMetdataSDK(ctx context.Context, req *dto.GetMetadataSDKRequ
 }
 
 func (h *Handler) GetAllParametersSDK(ctx context.Context, req *dto.GetAllParametersSDKRequest) (*dto.GetAllParametersSDKResponse, error) {
-	parameters, err := h.service.GetAllParametersSDK(ctx)
+	parameters, err := h.service.GetAllParametersSDK(ctx, req.Environment)
 	if err != nil {
 		return nil, err
 	}
@@ -491,8 +850,32 @@ func (h *Handler) GetAllParametersSDK(ctx context.Context, req *dto.GetAllParame
 	}, nil
 }
 
+// EvaluateSDK handles the business logic for evaluating parameters server-side
+func (h *Handler) EvaluateSDK(ctx context.Context, req *dto.EvaluateSDKRequest) (*dto.EvaluateSDKResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "evaluate-sdk").Logger()
+
+	response, err := h.service.EvaluateSDK(ctx, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to evaluate parameters")
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// SubscribeSDKChanges returns the SDK change events published from now on, and a function to
+// call once the caller stops reading them
+func (h *Handler) SubscribeSDKChanges() (<-chan dto.SDKChangeEvent, func()) {
+	return h.hub.Subscribe()
+}
+
+// GetParametersSDKETag returns the ETag of the current SDK parameters payload of an environment
+func (h *Handler) GetParametersSDKETag(ctx context.Context, environment string) (string, error) {
+	return h.service.GetParametersSDKETag(ctx, environment)
+}
+
 func (h *Handler) GetAllExperimentsSDK(ctx context.Context, req *dto.GetAllExperimentsSDKRequest) (*dto.GetAllExperimentsSDKResponse, error) {
-	experiments, err := h.service.GetActiveExperimentsSDK(ctx)
+	experiments, err := h.service.GetActiveExperimentsSDK(ctx, req.Environment)
 	if err != nil {
 		return nil, err
 	}
@@ -612,3 +995,193 @@ func (h *Handler) TrackBatchEvent(ctx context.Context, req *dto.TrackBatchEventR
 		Msg("Batch events tracked successfully")
 	return response, nil
 }
+
+// ExportPointInTime streams the point-in-time reconstruction document, logging every invocation
+func (h *Handler) ExportPointInTime(ctx context.Context, userID uint, email string, at time.Time, w io.Writer) error {
+	logger := log.Ctx(ctx).With().Str("handler", "export-point-in-time").Uint("userID", userID).Str("email", email).Time("at", at).Logger()
+	logger.Info().Msg("Exporting point-in-time state")
+
+	if err := h.service.ExportPointInTime(ctx, at, w); err != nil {
+		logger.Error().Err(err).Msg("Failed to export point-in-time state")
+		return err
+	}
+
+	logger.Info().Msg("Exported point-in-time state")
+	return nil
+}
+
+// RebuildRawValues rebuilds the stored raw values served to SDKs, logging every invocation
+func (h *Handler) RebuildRawValues(ctx context.Context, userID uint, email string) (*dto.RebuildRawValuesResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "rebuild-raw-values").Uint("userID", userID).Str("email", email).Logger()
+	logger.Info().Msg("Rebuilding raw values")
+
+	response, err := h.service.RebuildRawValues(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to rebuild raw values")
+		return nil, err
+	}
+	return response, nil
+}
+
+// CreateAPIKey handles the business logic for creating an API key
+func (h *Handler) CreateAPIKey(ctx context.Context, userID uint, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "create-api-key").Uint("userId", userID).Logger()
+	logger.Info().Msg("Creating api key")
+
+	apiKey, key, err := h.service.CreateAPIKey(ctx, userID, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to create api key")
+		return nil, err
+	}
+
+	return &dto.CreateAPIKeyResponse{
+		APIKeyResponse: dto.ToAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+// GetAllAPIKeys handles the business logic for listing API keys
+func (h *Handler) GetAllAPIKeys(ctx context.Context) ([]dto.APIKeyResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-all-api-keys").Logger()
+	logger.Info().Msg("Getting all api keys")
+
+	apiKeys, err := h.service.GetAllAPIKeys(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get api keys")
+		return nil, err
+	}
+
+	responses := make([]dto.APIKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		responses[i] = dto.ToAPIKeyResponse(apiKey)
+	}
+	return responses, nil
+}
+
+// RevokeAPIKey handles the business logic for revoking an API key
+func (h *Handler) RevokeAPIKey(ctx context.Context, id uint) (*dto.APIKeyResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "revoke-api-key").Uint("id", id).Logger()
+	logger.Info().Msg("Revoking api key")
+
+	apiKey, err := h.service.RevokeAPIKey(ctx, id)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to revoke api key")
+		return nil, err
+	}
+
+	response := dto.ToAPIKeyResponse(apiKey)
+	return &response, nil
+}
+
+// ValidateAPIKey checks the API key presented by an SDK client
+func (h *Handler) ValidateAPIKey(ctx context.Context, key string) error {
+	return h.service.ValidateAPIKey(ctx, key)
+}
+
+// CreateEnvironment handles the business logic for creating an environment
+func (h *Handler) CreateEnvironment(ctx context.Context, req *dto.CreateEnvironmentRequest) (*dto.EnvironmentResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "create-environment").Str("key", req.Key).Logger()
+	logger.Info().Msg("Creating environment")
+
+	environment, err := h.service.CreateEnvironment(ctx, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to create environment")
+		return nil, err
+	}
+
+	response := dto.ToEnvironmentResponse(environment)
+	return &response, nil
+}
+
+// GetAllEnvironments handles the business logic for listing environments
+func (h *Handler) GetAllEnvironments(ctx context.Context) ([]dto.EnvironmentResponse, error) {
+	environments, err := h.service.GetAllEnvironments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.EnvironmentResponse, len(environments))
+	for i, environment := range environments {
+		responses[i] = dto.ToEnvironmentResponse(environment)
+	}
+	return responses, nil
+}
+
+// GetEnvironmentByID handles the business logic for retrieving an environment
+func (h *Handler) GetEnvironmentByID(ctx context.Context, id uint) (*dto.EnvironmentResponse, error) {
+	environment, err := h.service.GetEnvironmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.ToEnvironmentResponse(environment)
+	return &response, nil
+}
+
+// UpdateEnvironment handles the business logic for renaming an environment
+func (h *Handler) UpdateEnvironment(ctx context.Context, id uint, req *dto.UpdateEnvironmentRequest) (*dto.EnvironmentResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "update-environment").Uint("id", id).Logger()
+	logger.Info().Msg("Updating environment")
+
+	environment, err := h.service.UpdateEnvironment(ctx, id, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to update environment")
+		return nil, err
+	}
+
+	response := dto.ToEnvironmentResponse(environment)
+	return &response, nil
+}
+
+// DeleteEnvironment handles the business logic for deleting an environment
+func (h *Handler) DeleteEnvironment(ctx context.Context, id uint) error {
+	logger := log.Ctx(ctx).With().Str("handler", "delete-environment").Uint("id", id).Logger()
+	logger.Info().Msg("Deleting environment")
+
+	if err := h.service.DeleteEnvironment(ctx, id); err != nil {
+		logger.Error().Err(err).Msg("Failed to delete environment")
+		return err
+	}
+	return nil
+}
+
+// GetParameterEnvironmentOverrides handles the business logic for listing the environment overrides of a parameter
+func (h *Handler) GetParameterEnvironmentOverrides(ctx context.Context, parameterID uint) ([]dto.ParameterEnvironmentOverrideResponse, error) {
+	overrides, err := h.service.GetParameterEnvironmentOverrides(ctx, parameterID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ParameterEnvironmentOverrideResponse, len(overrides))
+	for i, override := range overrides {
+		responses[i] = dto.ToParameterEnvironmentOverrideResponse(override)
+	}
+	return responses, nil
+}
+
+// SetParameterEnvironmentOverride handles the business logic for overriding a parameter in an environment
+func (h *Handler) SetParameterEnvironmentOverride(ctx context.Context, parameterID uint, environmentID uint, userID uint, req *dto.SetParameterEnvironmentOverrideRequest) (*dto.ParameterEnvironmentOverrideResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "set-parameter-environment-override").Uint("parameterId", parameterID).Uint("environmentId", environmentID).Logger()
+	logger.Info().Msg("Setting parameter environment override")
+
+	override, err := h.service.SetParameterEnvironmentOverride(ctx, parameterID, environmentID, userID, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to set parameter environment override")
+		return nil, err
+	}
+
+	response := dto.ToParameterEnvironmentOverrideResponse(override)
+	return &response, nil
+}
+
+// DeleteParameterEnvironmentOverride handles the business logic for removing the override of a parameter in an environment
+func (h *Handler) DeleteParameterEnvironmentOverride(ctx context.Context, parameterID uint, environmentID uint, userID uint) error {
+	logger := log.Ctx(ctx).With().Str("handler", "delete-parameter-environment-override").Uint("parameterId", parameterID).Uint("environmentId", environmentID).Logger()
+	logger.Info().Msg("Deleting parameter environment override")
+
+	if err := h.service.DeleteParameterEnvironmentOverride(ctx, parameterID, environmentID, userID); err != nil {
+		logger.Error().Err(err).Msg("Failed to delete parameter environment override")
+		return err
+	}
+	return nil
+}
@@ -81,16 +81,30 @@ func (h *Handler) ApproveParameterChangeRequest(ctx context.Context, id uint, us
 	logger := log.Ctx(ctx).With().Str("handler", "approve-parameter-change-request").Uint("id", id).Logger()
 	logger.Info().Msg("Approving parameter change request")
 
-	changeRequest, err := h.service.ApproveParameterChangeRequest(ctx, id, userID, req)
+	changeRequest, diff, err := h.service.ApproveParameterChangeRequest(ctx, id, userID, req)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to approve parameter change request")
 		return nil, err
 	}
 
 	response := dto.ToParameterChangeRequestResponse(changeRequest)
+	response.Diff = diff
 	return &response, nil
 }
 
+// GetParameterChangeRequestDiff handles getting the changes a parameter change request applies to the live parameter
+func (h *Handler) GetParameterChangeRequestDiff(ctx context.Context, id uint) (*dto.ParameterChangeRequestDiff, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "get-parameter-change-request-diff").Uint("id", id).Logger()
+	logger.Info().Msg("Getting parameter change request diff")
+
+	diff, err := h.service.GetParameterChangeRequestDiff(ctx, id)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get parameter change request diff")
+		return nil, err
+	}
+	return diff, nil
+}
+
 // RejectParameterChangeRequest handles rejecting a parameter change request
 func (h *Handler) RejectParameterChangeRequest(ctx context.Context, id uint, userID uint, req *dto.RejectParameterChangeRequestRequest) (*dto.ParameterChangeRequestResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "reject-parameter-change-request").Uint("id", id).Logger()
@@ -106,6 +120,21 @@ func (h *Handler) RejectParameterChangeRequest(ctx context.Context, id uint, use
 	return &response, nil
 }
 
+// CancelParameterChangeRequest handles cancelling a parameter change request
+func (h *Handler) CancelParameterChangeRequest(ctx context.Context, id uint, userID uint, req *dto.CancelParameterChangeRequestRequest) (*dto.ParameterChangeRequestResponse, error) {
+	logger := log.Ctx(ctx).With().Str("handler", "cancel-parameter-change-request").Uint("id", id).Uint("userId", userID).Logger()
+	logger.Info().Msg("Cancelling parameter change request")
+
+	changeRequest, err := h.service.CancelParameterChangeRequest(ctx, id, userID, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to cancel parameter change request")
+		return nil, err
+	}
+
+	response := dto.ToParameterChangeRequestResponse(changeRequest)
+	return &response, nil
+}
+
 // GetParameterChangeRequestsByStatus handles getting parameter change requests by status with pagination
 func (h *Handler) GetParameterChangeRequestsByStatus(ctx context.Context, status model.ParameterChangeRequestStatus, limit, offset int) (*dto.ParameterChangeRequestListResponse, error) {
 	logger := log.Ctx(ctx).With().Str("handler", "get-parameter-change-requests-by-status").Str("status", string(status)).Logger()
@@ -2,10 +2,14 @@ package mapper
 
 import (
 	"api/internal/model"
+	"context"
 	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
 	"sdk/types"
+
+	"github.com/rs/zerolog/log"
 )
 
 // ExperimentToSDK converts a model.Experiment to sdk.Experiment with its variants
@@ -23,6 +27,11 @@ func ExperimentToSDK(experiment *model.Experiment) (types.Experiment, error) {
 		segment = &sdkSegment
 	}
 
+	var hashAttributeName string
+	if experiment.HashAttribute != nil {
+		hashAttributeName = experiment.HashAttribute.Name
+	}
+
 	// Convert variants with their parameters
 	var sdkVariants []types.ExperimentVariant
 	if experiment.Variants != nil {
@@ -49,7 +58,8 @@ func ExperimentToSDK(experiment *model.Experiment) (types.Experiment, error) {
 		SegmentID:         experiment.SegmentID,
 		Segment:           segment,
 		Variants:          sdkVariants,
-		HashAttributeName: experiment.HashAttribute.Name,
+		HashAttributeName: hashAttributeName,
+		Overrides:         experiment.OverrideMap(),
 	}, nil
 }
 
@@ -224,23 +234,70 @@ func ExperimentBatchToSDK(experiments []*model.Experiment, experimentsVariants m
 	return sdkExperiments, nil
 }
 
+// Reasons an experiment is skipped from SDK responses, by which SkippedExperiments is labelled
+const (
+	// SkipReasonUnsupportedConfig is for experiments whose configuration SDK clients cannot evaluate,
+	// such as a segment referencing other segments
+	SkipReasonUnsupportedConfig = "unsupported_config"
+	// SkipReasonInvalidConfig is for experiments whose configuration cannot be converted at all
+	SkipReasonInvalidConfig = "invalid_config"
+)
+
+// SkippedExperiments counts the experiments skipped from SDK responses by reason. It is published
+// with expvar, and served to admins under /api/v1/admin/debug/vars.
+var SkippedExperiments = expvar.NewMap("aurora_sdk_skipped_experiments")
+
+// skipReason returns the reason an experiment is skipped for, from the error of its conversion
+func skipReason(err error) string {
+	if errors.Is(err, ErrUnexpandedSegment) {
+		return SkipReasonUnsupportedConfig
+	}
+	return SkipReasonInvalidConfig
+}
+
 // ExperimentsToSDKFromRawValue converts experiments with raw values to SDK format
-// This function uses the raw_value field from the database for efficient conversion
-func ExperimentsToSDKFromRawValue(experiments []model.Experiment) ([]types.Experiment, error) {
+// This function uses the raw_value field from the database for efficient conversion.
+// A single malformed experiment never fails the whole batch: it falls back to the
+// ORM-based conversion using preloaded data and is skipped only if that also fails.
+func ExperimentsToSDKFromRawValue(ctx context.Context, experiments []model.Experiment) []types.Experiment {
+	sdkExperiments, _ := ExperimentsToSDKFromRawValueWithWarnings(ctx, experiments)
+	return sdkExperiments
+}
+
+// ExperimentsToSDKFromRawValueWithWarnings converts experiments like ExperimentsToSDKFromRawValue and
+// also returns the number of experiments whose raw_value could not be used, whether they were
+// converted from preloaded data or skipped
+func ExperimentsToSDKFromRawValueWithWarnings(ctx context.Context, experiments []model.Experiment) ([]types.Experiment, int) {
 	if experiments == nil {
-		return []types.Experiment{}, nil
+		return []types.Experiment{}, 0
 	}
 
-	sdkExperiments := make([]types.Experiment, len(experiments))
-	for i, experiment := range experiments {
-		sdkExp, err := ExperimentToSDKFromRawValue(&experiment)
+	logger := log.Ctx(ctx)
+	sdkExperiments := make([]types.Experiment, 0, len(experiments))
+	skipped, warnings := 0, 0
+	for i := range experiments {
+		experiment := &experiments[i]
+		sdkExp, err := ExperimentToSDKFromRawValue(experiment)
 		if err != nil {
-			return nil, err
+			warnings++
+			logger.Warn().Err(err).Int("experimentId", experiment.ID).Msg("Failed to convert experiment from raw_value, falling back to preloaded data")
+			sdkExp, err = ExperimentToSDK(experiment)
+			if err != nil {
+				skipped++
+				reason := skipReason(err)
+				SkippedExperiments.Add(reason, 1)
+				logger.Error().Err(err).Int("experimentId", experiment.ID).Str("reason", reason).Msg("Failed to convert experiment to SDK, skipping")
+				continue
+			}
 		}
-		sdkExperiments[i] = sdkExp
+		sdkExperiments = append(sdkExperiments, sdkExp)
 	}
 
-	return sdkExperiments, nil
+	if skipped > 0 {
+		logger.Error().Int("skipped_experiments_count", skipped).Int("experiments_count", len(experiments)).Msg("Skipped experiments during SDK conversion")
+	}
+
+	return sdkExperiments, warnings
 }
 
 // ExperimentToSDKFromRawValue converts a single experiment with raw value to SDK format
@@ -317,9 +374,13 @@ func convertRawDataToSDK(rawData map[string]interface{}) (types.Experiment, erro
 		return types.Experiment{}, errors.New("invalid experiment status")
 	}
 
-	segmentID, ok := rawData["segmentId"].(float64)
-	if !ok {
-		return types.Experiment{}, errors.New("invalid experiment segmentId")
+	// segmentId is optional: experiments without a segment legitimately use 0
+	var segmentID float64
+	if value, exists := rawData["segmentId"]; exists && value != nil {
+		segmentID, ok = value.(float64)
+		if !ok {
+			return types.Experiment{}, errors.New("invalid experiment segmentId")
+		}
 	}
 
 	// Extract hash attribute name
@@ -355,6 +416,19 @@ func convertRawDataToSDK(rawData map[string]interface{}) (types.Experiment, erro
 		}
 	}
 
+	// Extract overrides, missing from raw values written before overrides existed
+	var overrides map[string]int
+	if overridesData, ok := rawData["overrides"].(map[string]interface{}); ok {
+		overrides = make(map[string]int, len(overridesData))
+		for attributeValue, variantID := range overridesData {
+			id, ok := variantID.(float64)
+			if !ok {
+				return types.Experiment{}, fmt.Errorf("invalid variant id of override %q", attributeValue)
+			}
+			overrides[attributeValue] = int(id)
+		}
+	}
+
 	return types.Experiment{
 		ID:                int(id),
 		Name:              name,
@@ -369,6 +443,7 @@ func convertRawDataToSDK(rawData map[string]interface{}) (types.Experiment, erro
 		Segment:           segment,
 		Variants:          variants,
 		HashAttributeName: hashAttributeName,
+		Overrides:         overrides,
 	}, nil
 }
 
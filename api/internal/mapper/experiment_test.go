@@ -0,0 +1,139 @@
+package mapper
+
+import (
+	"api/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperimentToSDKFromRawValue(t *testing.T) {
+	tests := []struct {
+		name               string
+		rawValue           string
+		expectErr          bool
+		expectSegmentID    int
+		expectHasSegment   bool
+		expectVariants     int
+		expectHashAttrName string
+		expectOverrides    map[string]int
+	}{
+		{
+			name: "full payload with segment",
+			rawValue: `{"id":1,"name":"exp","uuid":"u-1","startDate":1,"endDate":2,"hashAttributeId":3,"populationSize":50,
+				"strategy":"percentage_split","status":"running","segmentId":7,
+				"segment":{"id":7,"name":"vn","rules":[{"id":1,"segmentId":7,"conditions":[{"id":1,"operator":"equals","value":"VN","attribute":{"name":"country","dataType":"string"}}]}]},
+				"hashAttribute":{"name":"user_id"},
+				"variants":[{"id":1,"experimentId":1,"name":"a","trafficAllocation":100,"parameters":[{"id":1,"parameterDataType":"string","parameterId":1,"parameterName":"p","rolloutValue":"x"}]}],
+				"overrides":{"qa-user":1}}`,
+			expectSegmentID:    7,
+			expectHasSegment:   true,
+			expectVariants:     1,
+			expectHashAttrName: "user_id",
+			expectOverrides:    map[string]int{"qa-user": 1},
+		},
+		{
+			name: "no segment with null segment",
+			rawValue: `{"id":1,"name":"exp","uuid":"u-1","startDate":1,"endDate":2,"hashAttributeId":3,"populationSize":50,
+				"strategy":"percentage_split","status":"running","segmentId":0,"segment":null,"variants":[]}`,
+			expectSegmentID: 0,
+			expectVariants:  0,
+		},
+		{
+			name: "legacy payload missing segmentId, segment and description",
+			rawValue: `{"id":1,"name":"exp","uuid":"u-1","startDate":1,"endDate":2,"hashAttributeId":3,"populationSize":50,
+				"strategy":"percentage_split","status":"schedule",
+				"variants":[{"id":1,"experimentId":1,"name":"a","trafficAllocation":100}]}`,
+			expectSegmentID: 0,
+			expectVariants:  1,
+		},
+		{
+			name: "missing required uuid",
+			rawValue: `{"id":1,"name":"exp","startDate":1,"endDate":2,"hashAttributeId":3,"populationSize":50,
+				"strategy":"percentage_split","status":"running"}`,
+			expectErr: true,
+		},
+		{
+			name: "override with invalid variant id",
+			rawValue: `{"id":1,"name":"exp","uuid":"u-1","startDate":1,"endDate":2,"hashAttributeId":3,"populationSize":50,
+				"strategy":"percentage_split","status":"running","variants":[],"overrides":{"qa-user":"a"}}`,
+			expectErr: true,
+		},
+		{
+			name:      "malformed json",
+			rawValue:  `{"id":`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			experiment := &model.Experiment{RawValue: json.RawMessage(tt.rawValue)}
+
+			result, err := ExperimentToSDKFromRawValue(experiment)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectSegmentID, result.SegmentID)
+			require.Equal(t, tt.expectHasSegment, result.Segment != nil)
+			require.Len(t, result.Variants, tt.expectVariants)
+			require.Equal(t, tt.expectHashAttrName, result.HashAttributeName)
+			require.Equal(t, tt.expectOverrides, result.Overrides)
+		})
+	}
+}
+
+func TestExperimentsToSDKFromRawValue(t *testing.T) {
+	valid := `{"id":1,"name":"valid","uuid":"u-1","startDate":1,"endDate":2,"hashAttributeId":3,"populationSize":50,
+		"strategy":"percentage_split","status":"running","variants":[]}`
+
+	experiments := []model.Experiment{
+		{ID: 1, RawValue: json.RawMessage(valid)},
+		// Malformed raw_value falls back to the preloaded data
+		{ID: 2, Name: "fallback", Uuid: "u-2", Status: "running", RawValue: json.RawMessage(`{"id":`)},
+	}
+
+	result := ExperimentsToSDKFromRawValue(context.Background(), experiments)
+	require.Len(t, result, 2)
+	require.Equal(t, "valid", result[0].Name)
+	require.Equal(t, "fallback", result[1].Name)
+	require.Equal(t, "", result[1].HashAttributeName)
+
+	// Only the experiment whose raw_value could not be used is reported
+	result, warnings := ExperimentsToSDKFromRawValueWithWarnings(context.Background(), experiments)
+	require.Len(t, result, 2)
+	require.Equal(t, 1, warnings)
+}
+
+func TestExperimentsToSDKFromRawValueCountsSkippedExperiments(t *testing.T) {
+	skipped := func(reason string) int64 {
+		if count, ok := SkippedExperiments.Get(reason).(*expvar.Int); ok {
+			return count.Value()
+		}
+		return 0
+	}
+	unsupported, invalid := skipped(SkipReasonUnsupportedConfig), skipped(SkipReasonInvalidConfig)
+
+	referencedID := uint(4)
+	experiments := []model.Experiment{
+		// The preloaded segment still references another segment, which SDK clients cannot evaluate
+		{ID: 1, Name: "nested", RawValue: json.RawMessage(`{"id":`), Segment: &model.Segment{ID: 3, Rules: []model.SegmentRule{
+			{ReferencedSegmentID: &referencedID, ReferenceType: model.SegmentReferenceInclude},
+		}}},
+		{ID: 2, Name: "fallback", Uuid: "u-2", Status: "running", RawValue: json.RawMessage(`{"id":`)},
+	}
+
+	result := ExperimentsToSDKFromRawValue(context.Background(), experiments)
+	require.Len(t, result, 1)
+	require.Equal(t, "fallback", result[0].Name)
+	require.Equal(t, unsupported+1, skipped(SkipReasonUnsupportedConfig))
+	require.Equal(t, invalid, skipped(SkipReasonInvalidConfig))
+	require.Equal(t, SkipReasonInvalidConfig, skipReason(errors.New("experiment is nil")))
+}
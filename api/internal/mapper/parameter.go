@@ -29,6 +29,8 @@ func ParameterToSDK(parameter *model.Parameter) (sdk.Parameter, error) {
 		Name:                parameter.Name,
 		DataType:            sdk.ParameterDataType(parameter.DataType),
 		DefaultRolloutValue: defaultRolloutValueStr,
+		EnumOptions:         parameter.EnumOptions,
+		NumberConstraints:   numberConstraintsToSDK(parameter.NumberConstraints),
 		Rules:               sdkRules,
 	}, nil
 }
@@ -87,20 +89,32 @@ func ParameterToSDKFromRawValue(parameter *model.Parameter) (sdk.Parameter, erro
 	var defaultRolloutValueStr string
 	if defaultRolloutData, ok := rawData["defaultRolloutValue"].(map[string]interface{}); ok {
 		if value, exists := defaultRolloutData["value"]; exists {
-			valueBytes, err := json.Marshal(value)
+			valueStr, err := rolloutDataToString(value)
 			if err != nil {
 				return sdk.Parameter{}, err
 			}
-			valueStr := string(valueBytes)
-			// Remove quotes if it's a simple string value
-			if len(valueStr) >= 2 && valueStr[0] == '"' && valueStr[len(valueStr)-1] == '"' {
-				defaultRolloutValueStr = valueStr[1 : len(valueStr)-1]
-			} else {
-				defaultRolloutValueStr = valueStr
+			defaultRolloutValueStr = valueStr
+		}
+	}
+
+	// Extract enum options from raw data
+	var enumOptions []string
+	if optionsData, ok := rawData["enumOptions"].([]interface{}); ok {
+		for _, option := range optionsData {
+			if optionStr, ok := option.(string); ok {
+				enumOptions = append(enumOptions, optionStr)
 			}
 		}
 	}
 
+	// Extract number constraints from raw data
+	var constraints struct {
+		NumberConstraints *sdk.NumberConstraints `json:"numberConstraints"`
+	}
+	if err := json.Unmarshal(parameter.RawValue, &constraints); err != nil {
+		return sdk.Parameter{}, err
+	}
+
 	// Extract and convert rules from raw data
 	var sdkRules []sdk.ParameterRule
 	if rulesData, ok := rawData["rules"].([]interface{}); ok {
@@ -115,10 +129,24 @@ func ParameterToSDKFromRawValue(parameter *model.Parameter) (sdk.Parameter, erro
 		Name:                name,
 		DataType:            sdk.ParameterDataType(dataType),
 		DefaultRolloutValue: defaultRolloutValueStr,
+		EnumOptions:         enumOptions,
+		NumberConstraints:   constraints.NumberConstraints,
 		Rules:               sdkRules,
 	}, nil
 }
 
+// numberConstraintsToSDK converts model.NumberConstraints to sdk.NumberConstraints
+func numberConstraintsToSDK(constraints *model.NumberConstraints) *sdk.NumberConstraints {
+	if constraints == nil {
+		return nil
+	}
+	return &sdk.NumberConstraints{
+		IntegerOnly: constraints.IntegerOnly,
+		Min:         constraints.Min,
+		Max:         constraints.Max,
+	}
+}
+
 // extractRulesFromRawData extracts and converts rules from raw JSON data
 func extractRulesFromRawData(rulesData []interface{}) ([]sdk.ParameterRule, error) {
 	sdkRules := make([]sdk.ParameterRule, len(rulesData))
@@ -147,17 +175,11 @@ func extractRulesFromRawData(rulesData []interface{}) ([]sdk.ParameterRule, erro
 		// Convert rollout value
 		if rolloutData, ok := ruleMap["rolloutValue"].(map[string]interface{}); ok {
 			if value, exists := rolloutData["value"]; exists {
-				valueBytes, err := json.Marshal(value)
+				valueStr, err := rolloutDataToString(value)
 				if err != nil {
 					return nil, err
 				}
-				valueStr := string(valueBytes)
-				// Remove quotes if it's a simple string value
-				if len(valueStr) >= 2 && valueStr[0] == '"' && valueStr[len(valueStr)-1] == '"' {
-					rolloutValue = valueStr[1 : len(valueStr)-1]
-				} else {
-					rolloutValue = valueStr
-				}
+				rolloutValue = valueStr
 			}
 		}
 
@@ -184,6 +206,29 @@ func extractRulesFromRawData(rulesData []interface{}) ([]sdk.ParameterRule, erro
 			matchType = sdk.ConditionMatchType(mType)
 		}
 
+		// Extract condition logic, absent in raw values stored before it existed
+		conditionLogic := sdk.ConditionLogicAnd
+		if logic, ok := ruleMap["conditionLogic"].(string); ok && logic != "" {
+			conditionLogic = sdk.ConditionLogic(logic)
+		}
+
+		// Extract priority, falling back to the stored order for raw values written before it existed
+		priority := i
+		if value, ok := ruleMap["priority"].(float64); ok {
+			priority = int(value)
+		}
+
+		// Extract the rollout percentage and the attribute users are bucketed by
+		var rolloutPercentage *int
+		if value, ok := ruleMap["rolloutPercentage"].(float64); ok {
+			percentage := int(value)
+			rolloutPercentage = &percentage
+		}
+		hashAttributeName, _ := ruleMap["hashAttributeName"].(string)
+
+		// Extract negation, absent in raw values stored before it existed
+		negate, _ := ruleMap["negate"].(bool)
+
 		// Extract conditions
 		var conditions []sdk.RuleCondition
 		if conditionsData, ok := ruleMap["conditions"].([]interface{}); ok {
@@ -230,38 +275,59 @@ func extractRulesFromRawData(rulesData []interface{}) ([]sdk.ParameterRule, erro
 		}
 
 		sdkRules[i] = sdk.ParameterRule{
-			ID:           ruleID,
-			Type:         ruleType,
-			RolloutValue: rolloutValue,
-			SegmentID:    segmentIDPtr,
-			MatchType:    matchType,
-			Conditions:   conditions,
-			Segment:      segment,
+			ID:                ruleID,
+			Type:              ruleType,
+			RolloutValue:      rolloutValue,
+			SegmentID:         segmentIDPtr,
+			MatchType:         matchType,
+			ConditionLogic:    conditionLogic,
+			Priority:          priority,
+			RolloutPercentage: rolloutPercentage,
+			HashAttributeName: hashAttributeName,
+			Negate:            negate,
+			Conditions:        conditions,
+			Segment:           segment,
 		}
 	}
 
 	return sdkRules, nil
 }
 
+// conditionLogicToSDK converts a rule's condition logic, treating an empty value as and
+func conditionLogicToSDK(logic model.ConditionLogic) sdk.ConditionLogic {
+	if logic == "" {
+		return sdk.ConditionLogicAnd
+	}
+	return sdk.ConditionLogic(logic)
+}
+
+// stringValue dereferences an optional string, treating nil as empty
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
 // rolloutValueToString converts a RolloutValue to its string representation
 func rolloutValueToString(rolloutValue model.RolloutValue) (string, error) {
 	if rolloutValue.Data == nil {
 		return "", nil
 	}
+	return rolloutDataToString(rolloutValue.Data)
+}
 
-	// Convert to JSON string representation
-	jsonBytes, err := json.Marshal(rolloutValue.Data)
+// rolloutDataToString returns strings unchanged, so that serialized JSON values and strings with
+// escaped characters reach the SDK intact, and the JSON representation of any other value
+func rolloutDataToString(value interface{}) (string, error) {
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	jsonBytes, err := json.Marshal(value)
 	if err != nil {
 		return "", err
 	}
-
-	// Remove quotes if it's a simple string value
-	jsonStr := string(jsonBytes)
-	if len(jsonStr) >= 2 && jsonStr[0] == '"' && jsonStr[len(jsonStr)-1] == '"' {
-		return jsonStr[1 : len(jsonStr)-1], nil
-	}
-
-	return jsonStr, nil
+	return string(jsonBytes), nil
 }
 
 // parameterRulesToSDK converts model parameter rules to SDK parameter rules
@@ -308,13 +374,18 @@ func parameterRulesToSDK(rules []model.ParameterRule) ([]sdk.ParameterRule, erro
 		}
 
 		sdkRules[i] = sdk.ParameterRule{
-			ID:           rule.ID,
-			Type:         sdk.RuleType(rule.Type),
-			RolloutValue: rolloutValueStr,
-			SegmentID:    segmentIDPtr,
-			MatchType:    matchType,
-			Conditions:   sdkConditions,
-			Segment:      segment,
+			ID:                rule.ID,
+			Type:              sdk.RuleType(rule.Type),
+			RolloutValue:      rolloutValueStr,
+			SegmentID:         segmentIDPtr,
+			MatchType:         matchType,
+			ConditionLogic:    conditionLogicToSDK(rule.ConditionLogic),
+			Priority:          rule.Priority,
+			RolloutPercentage: rule.RolloutPercentage,
+			HashAttributeName: stringValue(rule.HashAttributeName),
+			Negate:            rule.Negate,
+			Conditions:        sdkConditions,
+			Segment:           segment,
 		}
 	}
 
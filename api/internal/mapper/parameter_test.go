@@ -0,0 +1,120 @@
+package mapper
+
+import (
+	"api/internal/model"
+	"encoding/json"
+	sdk "sdk/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameterToSDKFromRawValueEnumOptions(t *testing.T) {
+	parameter := &model.Parameter{
+		Name:                "theme",
+		DataType:            model.ParameterDataTypeEnum,
+		DefaultRolloutValue: model.RolloutValue{Data: "light"},
+		EnumOptions:         []string{"light", "dark"},
+	}
+	require.NoError(t, parameter.PopulateRawValue())
+
+	fromRaw, err := ParameterToSDKFromRawValue(&model.Parameter{RawValue: json.RawMessage(parameter.RawValue)})
+	require.NoError(t, err)
+	require.Equal(t, []string{"light", "dark"}, fromRaw.EnumOptions)
+	require.Equal(t, "light", fromRaw.DefaultRolloutValue)
+
+	direct, err := ParameterToSDK(parameter)
+	require.NoError(t, err)
+	require.Equal(t, fromRaw.EnumOptions, direct.EnumOptions)
+}
+
+func TestParameterToSDKFromRawValueJSON(t *testing.T) {
+	pricing := map[string]interface{}{
+		"currency": "USD",
+		"tiers":    []interface{}{map[string]interface{}{"name": "pro", "price": 20.0}},
+	}
+	parameter := &model.Parameter{
+		Name:                "pricing_table",
+		DataType:            model.ParameterDataTypeJSON,
+		DefaultRolloutValue: model.RolloutValue{Data: pricing},
+	}
+	require.NoError(t, parameter.PopulateRawValue())
+
+	fromRaw, err := ParameterToSDKFromRawValue(&model.Parameter{RawValue: json.RawMessage(parameter.RawValue)})
+	require.NoError(t, err)
+	require.Equal(t, sdk.ParameterDataTypeJSON, fromRaw.DataType)
+
+	var decoded map[string]interface{}
+	require.NoError(t, sdk.ParseJSON(fromRaw.DefaultRolloutValue, &decoded))
+	require.Equal(t, pricing, decoded)
+
+	direct, err := ParameterToSDK(parameter)
+	require.NoError(t, err)
+	require.JSONEq(t, fromRaw.DefaultRolloutValue, direct.DefaultRolloutValue)
+}
+
+func TestParameterToSDKFromRawValueSerializedJSON(t *testing.T) {
+	layout := `{"sections":["hero","footer"],"title":"<b>Sale</b>"}`
+	parameter := &model.Parameter{
+		Name:                "feature_layout",
+		DataType:            model.ParameterDataTypeJSON,
+		DefaultRolloutValue: model.RolloutValue{Data: layout},
+	}
+	require.NoError(t, parameter.PopulateRawValue())
+
+	fromRaw, err := ParameterToSDKFromRawValue(&model.Parameter{RawValue: json.RawMessage(parameter.RawValue)})
+	require.NoError(t, err)
+	require.Equal(t, layout, fromRaw.DefaultRolloutValue)
+
+	direct, err := ParameterToSDK(parameter)
+	require.NoError(t, err)
+	require.Equal(t, layout, direct.DefaultRolloutValue)
+}
+
+func TestParameterToSDKFromRawValueNegatedRule(t *testing.T) {
+	parameter := &model.Parameter{
+		Name:                "banner",
+		DataType:            model.ParameterDataTypeString,
+		DefaultRolloutValue: model.RolloutValue{Data: "default"},
+		Rules: []model.ParameterRule{
+			{ID: 1, Type: model.RuleTypeAttribute, RolloutValue: model.RolloutValue{Data: "outside us"}, Negate: true},
+			{ID: 2, Type: model.RuleTypeAttribute, RolloutValue: model.RolloutValue{Data: "everyone"}, Priority: 1},
+		},
+	}
+	require.NoError(t, parameter.PopulateRawValue())
+
+	fromRaw, err := ParameterToSDKFromRawValue(&model.Parameter{RawValue: json.RawMessage(parameter.RawValue)})
+	require.NoError(t, err)
+	require.True(t, fromRaw.Rules[0].Negate)
+	require.False(t, fromRaw.Rules[1].Negate)
+
+	direct, err := ParameterToSDK(parameter)
+	require.NoError(t, err)
+	require.True(t, direct.Rules[0].Negate)
+	require.False(t, direct.Rules[1].Negate)
+}
+
+func TestParameterToSDKFromRawValueNumberConstraints(t *testing.T) {
+	min, max := 0.0, 10.0
+	parameter := &model.Parameter{
+		Name:                "max_retries",
+		DataType:            model.ParameterDataTypeNumber,
+		DefaultRolloutValue: model.RolloutValue{Data: 3.0},
+		NumberConstraints:   &model.NumberConstraints{IntegerOnly: true, Min: &min, Max: &max},
+	}
+	require.NoError(t, parameter.PopulateRawValue())
+
+	fromRaw, err := ParameterToSDKFromRawValue(&model.Parameter{RawValue: json.RawMessage(parameter.RawValue)})
+	require.NoError(t, err)
+	require.Equal(t, &sdk.NumberConstraints{IntegerOnly: true, Min: &min, Max: &max}, fromRaw.NumberConstraints)
+
+	direct, err := ParameterToSDK(parameter)
+	require.NoError(t, err)
+	require.Equal(t, fromRaw.NumberConstraints, direct.NumberConstraints)
+
+	parameter.NumberConstraints = nil
+	require.NoError(t, parameter.PopulateRawValue())
+	fromRaw, err = ParameterToSDKFromRawValue(&model.Parameter{RawValue: json.RawMessage(parameter.RawValue)})
+	require.NoError(t, err)
+	require.Nil(t, fromRaw.NumberConstraints)
+}
@@ -3,14 +3,22 @@ package mapper
 import (
 	"api/internal/model"
 	"errors"
+	"fmt"
 	sdk "sdk/types"
 )
 
-// SegmentToSDK converts a model.Segment to sdk.Segment
+// ErrUnexpandedSegment is returned when a segment sent to SDK clients still references other segments
+var ErrUnexpandedSegment = errors.New("segment references other segments and must be expanded")
+
+// SegmentToSDK converts a model.Segment to sdk.Segment. SDK clients only evaluate conditions, so the
+// references of the segment to other segments must have been expanded.
 func SegmentToSDK(segment *model.Segment) (sdk.Segment, error) {
 	if segment == nil {
 		return sdk.Segment{}, errors.New("segment is nil")
 	}
+	if segment.HasReferences() {
+		return sdk.Segment{}, fmt.Errorf("segment %d: %w", segment.ID, ErrUnexpandedSegment)
+	}
 
 	// Map rules
 	sdkRules, err := segmentRulesToSDK(segment.Rules)
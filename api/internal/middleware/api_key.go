@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// APIKeyHeader is the header SDK clients send their API key in
+const APIKeyHeader = "X-Aurora-Api-Key"
+
+// SDKKeyHeader is accepted as an alias of APIKeyHeader
+const SDKKeyHeader = "X-Aurora-SDK-Key"
+
+// apiKeyFromRequest returns the API key sent in APIKeyHeader, falling back to SDKKeyHeader
+func apiKeyFromRequest(c *gin.Context) string {
+	if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" {
+		return apiKey
+	}
+	return c.GetHeader(SDKKeyHeader)
+}
+
+// APIKeyValidator checks an API key, returning an unauthorized error for keys that must be rejected
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) error
+}
+
+// APIKeyMiddleware creates a middleware that rejects requests with an invalid API key, and requests
+// without one when required is set. Rejections are recorded with c.Error so the error handling
+// middleware renders them.
+func APIKeyMiddleware(validator APIKeyValidator, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := log.Ctx(c.Request.Context()).With().Str("middleware", "api-key").Logger()
+
+		apiKey := apiKeyFromRequest(c)
+		if !required && apiKey == "" {
+			c.Next()
+			return
+		}
+
+		if err := validator.ValidateAPIKey(c.Request.Context(), apiKey); err != nil {
+			logger.Warn().Err(err).Msg("API key rejected")
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
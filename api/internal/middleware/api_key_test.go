@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// keyValidator accepts a single API key
+type keyValidator string
+
+func (v keyValidator) ValidateAPIKey(_ context.Context, key string) error {
+	if key != string(v) {
+		return errors.New("invalid api key")
+	}
+	return nil
+}
+
+func TestAPIKeyMiddlewareOnlyRequiresKeyWhenConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	served := func(required bool, apiKey string) bool {
+		return servedWithHeader(required, APIKeyHeader, apiKey)
+	}
+
+	require.True(t, served(true, "valid"))
+	require.False(t, served(true, ""))
+	require.False(t, served(true, "wrong"))
+
+	require.True(t, served(false, "valid"))
+	require.True(t, served(false, ""))
+	require.False(t, served(false, "wrong"), "a key that is sent is validated even when keys are optional")
+}
+
+func TestAPIKeyMiddlewareAcceptsSDKKeyHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	require.True(t, servedWithHeader(true, SDKKeyHeader, "valid"))
+	require.False(t, servedWithHeader(true, SDKKeyHeader, "wrong"))
+	require.False(t, servedWithHeader(false, SDKKeyHeader, "wrong"))
+}
+
+// servedWithHeader reports whether a request sending apiKey in header reaches the handler behind the middleware
+func servedWithHeader(required bool, header string, apiKey string) bool {
+	engine := gin.New()
+	reached := false
+	engine.Use(APIKeyMiddleware(keyValidator("valid"), required))
+	engine.GET("/sdk", func(c *gin.Context) { reached = true })
+
+	req := httptest.NewRequest(http.MethodGet, "/sdk", nil)
+	if apiKey != "" {
+		req.Header.Set(header, apiKey)
+	}
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	return reached
+}
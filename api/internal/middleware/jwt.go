@@ -97,6 +97,23 @@ func JWTMiddleware(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
+// RequireAdmin creates a middleware that only lets admin users through. It must run after JWTMiddleware.
+func RequireAdmin(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := log.Ctx(c.Request.Context()).With().Str("middleware", "require-admin").Logger()
+
+		email, exists := GetUserEmailFromContext(c)
+		if !exists || !cfg.IsAdmin(email) {
+			logger.Warn().Str("email", email).Msg("Admin access denied")
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin role required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // GenerateJWT generates a new JWT token for a user
 func GenerateJWT(cfg *config.Config, userID uint, email, name string) (string, error) {
 	expirationTime := time.Now().Add(time.Duration(cfg.JWT.ExpireHour) * time.Hour)
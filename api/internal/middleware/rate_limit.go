@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"api/internal/apperror"
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// rateLimitSweepInterval is how often buckets of clients that stopped sending requests are dropped
+const rateLimitSweepInterval = time.Minute
+
+// RateLimiter limits each client to a sustained rate of requests, allowing short bursts above it,
+// with a token bucket per client
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket holds the tokens left to a client as of updated
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter creates a rate limiter allowing requestsPerSecond per client with bursts of burst requests.
+// A burst below 1 allows bursts of one second worth of requests.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	capacity := float64(burst)
+	if burst < 1 {
+		capacity = math.Max(1, math.Ceil(requestsPerSecond))
+	}
+	return &RateLimiter{
+		rate:    requestsPerSecond,
+		burst:   capacity,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Allow takes a token from the bucket of the client identified by key. When the bucket is empty,
+// it returns false and how long the client must wait for the next token.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket := l.refill(key, now)
+	if bucket.tokens < 1 {
+		return false, l.wait(bucket)
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// Exhausted reports whether the bucket of the client identified by key is empty, and how long the
+// client must wait for the next token, without taking a token
+func (l *RateLimiter) Exhausted(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket := l.refill(key, now)
+	if bucket.tokens < 1 {
+		return true, l.wait(bucket)
+	}
+	return false, 0
+}
+
+// refill returns the bucket of key, creating it full, with the tokens added since its last update
+func (l *RateLimiter) refill(key string, now time.Time) *tokenBucket {
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate)
+	bucket.updated = now
+	return bucket
+}
+
+// wait returns how long until an empty bucket holds a token
+func (l *RateLimiter) wait(bucket *tokenBucket) time.Duration {
+	return time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep drops the buckets that have refilled since the last request of their client, since a new
+// bucket starts full anyway
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updated) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimitMiddleware creates a middleware that rejects the requests of clients over the rate limit with
+// 429 Too Many Requests and a Retry-After header. Clients are identified by their API key when byAPIKey
+// is set, and by their IP address otherwise. It runs before the API key is checked, so requests whose
+// key is rejected also count against their IP address when byAPIKey is set: rotating invalid keys does
+// not escape the limit. Rejections are recorded with c.Error so the error handling middleware renders them.
+func RateLimitMiddleware(limiter *RateLimiter, byAPIKey bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := log.Ctx(c.Request.Context()).With().Str("middleware", "rate-limit").Logger()
+		reject := func(retryAfter time.Duration) {
+			logger.Warn().Str("clientIp", c.ClientIP()).Dur("retryAfter", retryAfter).Msg("Rate limit exceeded")
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.Error(apperror.TooManyRequests("too many requests"))
+			c.Abort()
+		}
+
+		ipKey := "ip:" + c.ClientIP()
+		apiKey := apiKeyFromRequest(c)
+		if !byAPIKey || apiKey == "" {
+			if allowed, retryAfter := limiter.Allow(ipKey); !allowed {
+				reject(retryAfter)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if exhausted, retryAfter := limiter.Exhausted(ipKey); exhausted {
+			reject(retryAfter)
+			return
+		}
+		if allowed, retryAfter := limiter.Allow("key:" + apiKey); !allowed {
+			reject(retryAfter)
+			return
+		}
+
+		c.Next()
+
+		if len(c.Errors) > 0 && errors.Is(c.Errors.Last().Err, apperror.ErrUnauthorized) {
+			limiter.Allow(ipKey)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"api/internal/apperror"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterRefillsAtSustainedRate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewRateLimiter(2, 3)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := limiter.Allow("client")
+		require.True(t, allowed, "request %d of the burst", i)
+	}
+	allowed, retryAfter := limiter.Allow("client")
+	require.False(t, allowed)
+	require.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// Other clients have buckets of their own
+	allowed, _ = limiter.Allow("other")
+	require.True(t, allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	allowed, _ = limiter.Allow("client")
+	require.True(t, allowed)
+	allowed, _ = limiter.Allow("client")
+	require.False(t, allowed)
+}
+
+// rateLimitedEngine serves /sdk behind the rate limit middleware and a check accepting the API key
+// "valid", answering rejections with the status of their application error
+func rateLimitedEngine(limiter *RateLimiter, byAPIKey bool) *gin.Engine {
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Next()
+		switch {
+		case len(c.Errors) == 0:
+		case errors.Is(c.Errors.Last().Err, apperror.ErrTooManyRequests):
+			c.Status(http.StatusTooManyRequests)
+		case errors.Is(c.Errors.Last().Err, apperror.ErrUnauthorized):
+			c.Status(http.StatusUnauthorized)
+		}
+	})
+	engine.Use(RateLimitMiddleware(limiter, byAPIKey))
+	engine.Use(func(c *gin.Context) {
+		if apiKey := apiKeyFromRequest(c); apiKey != "" && apiKey != "valid" {
+			c.Error(apperror.Unauthorized("invalid api key"))
+			c.Abort()
+		}
+	})
+	engine.GET("/sdk", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return engine
+}
+
+// requestWithKey sends a request to /sdk from a single client IP with apiKey, if set
+func requestWithKey(engine *gin.Engine, apiKey string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/sdk", nil)
+	if apiKey != "" {
+		req.Header.Set(APIKeyHeader, apiKey)
+	}
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddlewareRejectsWithRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	var rejection error
+	engine.Use(func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) > 0 {
+			rejection = c.Errors.Last().Err
+		}
+	})
+	engine.Use(RateLimitMiddleware(NewRateLimiter(0.5, 1), true))
+	engine.GET("/sdk", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	require.Equal(t, http.StatusOK, requestWithKey(engine, "key-a").Code)
+	rejected := requestWithKey(engine, "key-a")
+	require.Equal(t, "2", rejected.Header().Get("Retry-After"))
+	// The error handling middleware renders the rejection as a 429 ErrorResponse
+	require.ErrorIs(t, rejection, apperror.ErrTooManyRequests)
+	require.Equal(t, http.StatusOK, requestWithKey(engine, "key-b").Code)
+}
+
+func TestRateLimitMiddlewareThrottlesInvalidKeysBeforeTheyAreChecked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Without byAPIKey, every request of a client counts against its IP, whatever key it sends
+	engine := rateLimitedEngine(NewRateLimiter(0.5, 2), false)
+	require.Equal(t, http.StatusUnauthorized, requestWithKey(engine, "bogus-1").Code)
+	require.Equal(t, http.StatusUnauthorized, requestWithKey(engine, "bogus-2").Code)
+	require.Equal(t, http.StatusTooManyRequests, requestWithKey(engine, "bogus-3").Code)
+
+	// With byAPIKey, rotating invalid keys exhausts the bucket of the IP, while a valid key has a bucket of its own
+	engine = rateLimitedEngine(NewRateLimiter(0.5, 2), true)
+	require.Equal(t, http.StatusOK, requestWithKey(engine, "valid").Code)
+	require.Equal(t, http.StatusUnauthorized, requestWithKey(engine, "bogus-1").Code)
+	require.Equal(t, http.StatusUnauthorized, requestWithKey(engine, "bogus-2").Code)
+	require.Equal(t, http.StatusTooManyRequests, requestWithKey(engine, "bogus-3").Code)
+	require.Equal(t, http.StatusTooManyRequests, requestWithKey(engine, "valid").Code, "the IP is throttled whatever key it sends")
+}
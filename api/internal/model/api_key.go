@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// APIKey grants SDK clients access to the /api/v1/sdk endpoints. Only a hash of the key is stored;
+// the plaintext is returned once when the key is created.
+type APIKey struct {
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name            string     `gorm:"type:varchar(255);not null" json:"name"`
+	Prefix          string     `gorm:"type:varchar(32);not null" json:"prefix"`
+	KeyHash         string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	CreatedByUserID uint       `gorm:"not null" json:"createdByUserId"`
+	ExpiresAt       *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt       *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Active reports whether the key is neither revoked nor expired at the given time
+func (k *APIKey) Active(at time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || at.Before(*k.ExpiresAt)
+}
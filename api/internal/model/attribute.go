@@ -15,8 +15,34 @@ const (
 	DataTypeString  DataType = "string"
 	DataTypeNumber  DataType = "number"
 	DataTypeEnum    DataType = "enum"
+	// DataTypeDate attributes hold RFC3339 dates or unix epoch seconds
+	DataTypeDate DataType = "date"
 )
 
+// DataTypes lists every attribute data type
+var DataTypes = []DataType{DataTypeBoolean, DataTypeString, DataTypeNumber, DataTypeEnum, DataTypeDate}
+
+// Attribute list sort orders: by name alphabetically, newest first, or most used first
+const (
+	AttributeSortName       = "name"
+	AttributeSortCreatedAt  = "createdAt"
+	AttributeSortUsageCount = "usageCount"
+)
+
+// AttributeSorts lists every attribute list sort order
+var AttributeSorts = []string{AttributeSortName, AttributeSortCreatedAt, AttributeSortUsageCount}
+
+// AttributeFilter narrows and orders an attribute list query; zero values are ignored, and an empty
+// Sort lists the newest attributes first. A Limit of 0 returns all matching attributes.
+type AttributeFilter struct {
+	DataType  DataType
+	Search    string
+	InUseOnly bool
+	Sort      string
+	Limit     int
+	Offset    int
+}
+
 // Attribute represents the attributes table
 type Attribute struct {
 	ID            uint           `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -26,6 +52,7 @@ type Attribute struct {
 	HashAttribute bool           `gorm:"not null;default:false" json:"hashAttribute"`
 	EnumOptions   pq.StringArray `gorm:"type:text[];default:'{}'" json:"enumOptions"`
 	UsageCount    int            `gorm:"not null;default:0" json:"usageCount"`
+	AliasOfID     *uint          `gorm:"column:alias_of_id" json:"aliasOfId,omitempty"`
 	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
 }
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntityType identifies the kind of entity an audit log entry refers to
+type AuditEntityType string
+
+const (
+	AuditEntityParameter              AuditEntityType = "parameter"
+	AuditEntityExperiment             AuditEntityType = "experiment"
+	AuditEntityParameterChangeRequest AuditEntityType = "parameter_change_request"
+	AuditEntityAttribute              AuditEntityType = "attribute"
+	AuditEntitySegment                AuditEntityType = "segment"
+)
+
+// AuditAction describes the mutation recorded by an audit log entry
+type AuditAction string
+
+const (
+	AuditActionCreate               AuditAction = "create"
+	AuditActionUpdate               AuditAction = "update"
+	AuditActionDelete               AuditAction = "delete"
+	AuditActionAddRule              AuditAction = "add_rule"
+	AuditActionUpdateRule           AuditAction = "update_rule"
+	AuditActionDeleteRule           AuditAction = "delete_rule"
+	AuditActionReorderRules         AuditAction = "reorder_rules"
+	AuditActionApproveChangeRequest AuditAction = "approve_change_request"
+	AuditActionApprove              AuditAction = "approve"
+	AuditActionReject               AuditAction = "reject"
+	AuditActionAbort                AuditAction = "abort"
+	AuditActionPause                AuditAction = "pause"
+	AuditActionResume               AuditAction = "resume"
+	AuditActionArchive              AuditAction = "archive"
+	AuditActionUnarchive            AuditAction = "unarchive"
+	AuditActionRestore              AuditAction = "restore"
+	AuditActionMerge                AuditAction = "merge"
+	// Environment override actions are recorded on the parameter, with the environment's raw value as state
+	AuditActionSetEnvironmentOverride    AuditAction = "set_environment_override"
+	AuditActionDeleteEnvironmentOverride AuditAction = "delete_environment_override"
+)
+
+// AuditLog records who performed a mutation and the entity state before and after it
+type AuditLog struct {
+	ID          uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	ActorUserID uint            `gorm:"not null" json:"actorUserId"`
+	EntityType  AuditEntityType `gorm:"type:varchar(50);not null" json:"entityType"`
+	EntityID    uint            `gorm:"not null" json:"entityId"`
+	Action      AuditAction     `gorm:"type:varchar(50);not null" json:"action"`
+	Before      json.RawMessage `gorm:"type:jsonb" json:"before,omitempty"`
+	After       json.RawMessage `gorm:"type:jsonb" json:"after,omitempty"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditLogFilter narrows an audit log query; zero values are ignored
+type AuditLogFilter struct {
+	EntityType  AuditEntityType
+	EntityID    uint
+	ActorUserID uint
+	From        *time.Time
+	To          *time.Time
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Environment is a deployment environment, such as dev, staging or prod. SDK clients fetch the
+// view of their environment by its key.
+type Environment struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Key       string    `gorm:"type:varchar(64);not null;uniqueIndex" json:"key"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName specifies the table name for GORM
+func (Environment) TableName() string {
+	return "environments"
+}
+
+// ParameterEnvironmentOverride replaces the default rollout value and rules of a parameter in one
+// environment. A nil DefaultRolloutValue keeps the base default, and the base rules apply unless
+// OverrideRules is set, in which case Rules are served instead, even when there are none.
+type ParameterEnvironmentOverride struct {
+	ID                  uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	ParameterID         uint            `gorm:"not null" json:"parameterId"`
+	EnvironmentID       uint            `gorm:"not null" json:"environmentId"`
+	DefaultRolloutValue *RolloutValue   `gorm:"type:jsonb" json:"defaultRolloutValue,omitempty"`
+	OverrideRules       bool            `gorm:"not null;default:false" json:"overrideRules"`
+	RawValue            json.RawMessage `gorm:"type:jsonb;column:raw_value" json:"rawValue,omitempty"`
+	CreatedAt           time.Time       `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt           time.Time       `gorm:"autoUpdateTime" json:"updatedAt"`
+	Environment         *Environment    `gorm:"foreignKey:EnvironmentID" json:"environment,omitempty"`
+	Rules               []ParameterRule `gorm:"foreignKey:EnvironmentOverrideID" json:"rules"`
+}
+
+// TableName specifies the table name for GORM
+func (ParameterEnvironmentOverride) TableName() string {
+	return "parameter_environment_overrides"
+}
+
+// Apply returns a copy of the parameter as served in the override's environment. The override's
+// rules must be loaded when it overrides them.
+func (o *ParameterEnvironmentOverride) Apply(parameter *Parameter) *Parameter {
+	view := *parameter
+	view.RawValue = nil
+	if o.DefaultRolloutValue != nil {
+		view.DefaultRolloutValue = *o.DefaultRolloutValue
+	}
+	if o.OverrideRules {
+		view.Rules = o.Rules
+	}
+	return &view
+}
@@ -14,20 +14,36 @@ const (
 
 // EvaluationEvent represents an evaluation event stored in the database
 type EvaluationEvent struct {
-	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	EventID        string    `gorm:"uniqueIndex;not null;size:255" json:"eventId"`
-	ServiceName    string    `gorm:"not null;size:255" json:"serviceName"`
-	EventType      string    `gorm:"not null;size:50" json:"eventType"`
-	ParameterName  string    `gorm:"not null;size:255" json:"parameterName"`
-	Source         string    `gorm:"not null;size:50" json:"source"`
-	UserAttributes string    `gorm:"type:jsonb" json:"userAttributes"` // JSON string
-	RolloutValue   *string   `gorm:"type:text" json:"rolloutValue,omitempty"`
-	Error          *string   `gorm:"type:text" json:"error,omitempty"`
-	Timestamp      time.Time `gorm:"not null" json:"timestamp"`
-	ExperimentID   *int      `json:"experimentId,omitempty"`
-	ExperimentUUID *string   `gorm:"size:255" json:"experimentUuid,omitempty"`
-	VariantID      *int      `json:"variantId,omitempty"`
-	VariantName    *string   `gorm:"size:255" json:"variantName,omitempty"`
-	CreatedAt      time.Time `gorm:"autoCreateTime" json:"createdAt"`
-	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+	ID                uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventID           string    `gorm:"uniqueIndex;not null;size:255" json:"eventId"`
+	ServiceName       string    `gorm:"not null;size:255" json:"serviceName"`
+	EventType         string    `gorm:"not null;size:50" json:"eventType"`
+	ParameterName     string    `gorm:"not null;size:255" json:"parameterName"`
+	Source            string    `gorm:"not null;size:50" json:"source"`
+	UserAttributes    string    `gorm:"type:jsonb" json:"userAttributes"` // JSON string
+	RolloutValue      *string   `gorm:"type:text" json:"rolloutValue,omitempty"`
+	Error             *string   `gorm:"type:text" json:"error,omitempty"`
+	Timestamp         time.Time `gorm:"not null" json:"timestamp"`
+	ExperimentID      *int      `json:"experimentId,omitempty"`
+	ExperimentUUID    *string   `gorm:"size:255" json:"experimentUuid,omitempty"`
+	VariantID         *int      `json:"variantId,omitempty"`
+	VariantName       *string   `gorm:"size:255" json:"variantName,omitempty"`
+	VariantOverridden bool      `gorm:"not null;default:false" json:"variantOverridden"`
+	CreatedAt         time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// ExposureFilter narrows an experiment exposure query to a time range; nil bounds are ignored
+type ExposureFilter struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// ExperimentExposure counts the evaluation events recorded for a variant of an experiment.
+// Day is the start of the day the events were bucketed into, or nil for the variant total.
+type ExperimentExposure struct {
+	VariantID int
+	Day       *time.Time
+	Users     int64
+	Events    int64
 }
@@ -1,7 +1,9 @@
 package model
 
 import (
+	"api/internal/constant"
 	"encoding/json"
+	"time"
 )
 
 type Experiment struct {
@@ -12,23 +14,46 @@ type Experiment struct {
 	Description     string `json:"description"`
 	StartDate       int64  `json:"startDate"`
 	EndDate         int64
-	HashAttributeID int                 `json:"hashAttributeId"`
-	PopulationSize  int                 `json:"populationSize"`
-	Strategy        string              `json:"strategy"`
-	CreatedAt       int64               `json:"createdAt"`
-	UpdatedAt       int64               `json:"updatedAt"`
-	Status          string              `json:"status"`
-	SegmentID       int                 `json:"segmentId"`
-	RawValue        json.RawMessage     `gorm:"type:jsonb;column:raw_value" json:"rawValue,omitempty"`
-	Segment         *Segment            `json:"segment,omitempty"`
-	HashAttribute   *Attribute          `json:"hashAttribute,omitempty"`
-	Variants        []ExperimentVariant `json:"variants"`
+	HashAttributeID int    `json:"hashAttributeId"`
+	PopulationSize  int    `json:"populationSize"`
+	Strategy        string `json:"strategy"`
+	CreatedAt       int64  `json:"createdAt"`
+	UpdatedAt       int64  `json:"updatedAt"`
+	// CreatedByUserID and UpdatedByUserID are nil for experiments last written before they were recorded
+	CreatedByUserID *uint  `json:"createdByUserId,omitempty"`
+	UpdatedByUserID *uint  `json:"updatedByUserId,omitempty"`
+	Status          string `json:"status"`
+	SegmentID       int    `json:"segmentId"`
+	// EnvironmentID limits the experiment to one environment; experiments without one run in every environment
+	EnvironmentID *int                `json:"environmentId,omitempty"`
+	RawValue      json.RawMessage     `gorm:"type:jsonb;column:raw_value" json:"rawValue,omitempty"`
+	Segment       *Segment            `json:"segment,omitempty"`
+	HashAttribute *Attribute          `json:"hashAttribute,omitempty"`
+	Variants      []ExperimentVariant `json:"variants"`
+	// Overrides force values of the hash attribute into variants, e.g. for QA accounts
+	Overrides []ExperimentOverride `gorm:"foreignKey:ExperimentID" json:"overrides,omitempty"`
 }
 
 func (e *Experiment) TableName() string {
 	return "experiments"
 }
 
+// DueStatus returns the status the experiment moves to once its dates have passed at now, with the
+// time the transition took effect. It reports false when the experiment keeps its status.
+// Paused experiments are only finished when finishPausedOnEnd is set.
+func (e *Experiment) DueStatus(now int64, finishPausedOnEnd bool) (string, int64, bool) {
+	switch {
+	case e.Status == constant.ExperimentStatusSchedule && e.StartDate < now:
+		return constant.ExperimentStatusRunning, e.StartDate, true
+	case e.Status == constant.ExperimentStatusRunning && e.EndDate < now:
+		return constant.ExperimentStatusFinish, e.EndDate, true
+	case e.Status == constant.ExperimentStatusPause && e.EndDate < now && finishPausedOnEnd:
+		return constant.ExperimentStatusFinish, e.EndDate, true
+	default:
+		return "", 0, false
+	}
+}
+
 // PopulateRawValue creates a JSON representation of the experiment with all related fields
 // This should be called after all related entities are loaded via preloads
 func (e *Experiment) PopulateRawValue() error {
@@ -51,6 +76,7 @@ func (e *Experiment) PopulateRawValue() error {
 		"segment":         e.Segment,
 		"hashAttribute":   e.HashAttribute,
 		"variants":        e.Variants,
+		"overrides":       e.OverrideMap(),
 	}
 
 	// Marshal to JSON
@@ -63,6 +89,15 @@ func (e *Experiment) PopulateRawValue() error {
 	return nil
 }
 
+// OverrideMap maps the hash attribute values of the experiment's overrides to the ID of their variant
+func (e *Experiment) OverrideMap() map[string]int {
+	overrides := make(map[string]int, len(e.Overrides))
+	for _, override := range e.Overrides {
+		overrides[override.AttributeValue] = override.VariantID
+	}
+	return overrides
+}
+
 type ExperimentVariant struct {
 	ID                int                          `json:"id"`
 	ExperimentID      int                          `json:"experimentId"`
@@ -93,3 +128,35 @@ type ExperimentVariantParameter struct {
 func (e *ExperimentVariantParameter) TableName() string {
 	return "experiment_variant_parameters"
 }
+
+// ExperimentFilter narrows an experiment list query; zero values are ignored
+type ExperimentFilter struct {
+	Statuses []string
+	Search   string
+	// StartAfter and EndBefore are unix seconds bounding the start and end dates of the experiments
+	StartAfter int64
+	EndBefore  int64
+}
+
+// ExperimentSummary is an experiment list row with its segment name and number of variants,
+// loaded with a join instead of preloading the full experiment tree
+type ExperimentSummary struct {
+	Experiment
+	SegmentName  string
+	VariantCount int
+}
+
+// ExperimentOverride forces the units whose hash attribute has AttributeValue into a variant of the
+// experiment, regardless of its segment, population and traffic allocation
+type ExperimentOverride struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ExperimentID   int       `gorm:"not null" json:"experimentId"`
+	AttributeValue string    `gorm:"type:varchar(255);not null" json:"attributeValue"`
+	VariantID      int       `gorm:"not null" json:"variantId"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (ExperimentOverride) TableName() string {
+	return "experiment_overrides"
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ParameterVersion represents a snapshot of a parameter's raw_value
+type ParameterVersion struct {
+	ID          uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	ParameterID uint            `gorm:"not null" json:"parameterId"`
+	RawValue    json.RawMessage `gorm:"type:jsonb;not null" json:"rawValue"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (ParameterVersion) TableName() string {
+	return "parameter_versions"
+}
+
+// ExperimentStatusTransition represents a change of an experiment's status
+type ExperimentStatusTransition struct {
+	ID           int    `json:"id"`
+	ExperimentID int    `json:"experimentId"`
+	FromStatus   string `json:"fromStatus"`
+	ToStatus     string `json:"toStatus"`
+	CreatedAt    int64  `json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (ExperimentStatusTransition) TableName() string {
+	return "experiment_status_transitions"
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Idempotency key scopes, one per endpoint, so the same key can be reused across resources
+const (
+	IdempotencyScopeCreateExperiment = "create_experiment"
+	IdempotencyScopeCreateParameter  = "create_parameter"
+)
+
+// IdempotencyKey maps the Idempotency-Key header of a creation request to the resource it created
+type IdempotencyKey struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Scope      string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_idempotency_keys_scope_key" json:"scope"`
+	Key        string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_scope_key" json:"key"`
+	ResourceID uint      `gorm:"not null" json:"resourceId"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName specifies the table name for GORM
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
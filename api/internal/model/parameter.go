@@ -4,8 +4,11 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"time"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -16,6 +19,9 @@ const (
 	ParameterDataTypeBoolean ParameterDataType = "boolean"
 	ParameterDataTypeString  ParameterDataType = "string"
 	ParameterDataTypeNumber  ParameterDataType = "number"
+	ParameterDataTypeDate    ParameterDataType = "date"
+	ParameterDataTypeEnum    ParameterDataType = "enum"
+	ParameterDataTypeJSON    ParameterDataType = "json"
 )
 
 // ConditionMatchType represents the enum for condition match types
@@ -26,6 +32,14 @@ const (
 	ConditionMatchTypeNotMatch ConditionMatchType = "not_match"
 )
 
+// ConditionLogic represents how the conditions of an attribute rule are combined
+type ConditionLogic string
+
+const (
+	ConditionLogicAnd ConditionLogic = "and"
+	ConditionLogicOr  ConditionLogic = "or"
+)
+
 // RuleType represents the enum for rule types
 type RuleType string
 
@@ -61,6 +75,66 @@ func (rv RolloutValue) Value() (driver.Value, error) {
 	return json.Marshal(rv.Data)
 }
 
+// NumberConstraints restricts the values of a number parameter. Min and Max are inclusive.
+type NumberConstraints struct {
+	IntegerOnly bool     `json:"integerOnly,omitempty"`
+	Min         *float64 `json:"min,omitempty"`
+	Max         *float64 `json:"max,omitempty"`
+}
+
+// Scan implements the sql.Scanner interface
+func (c *NumberConstraints) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = NumberConstraints{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return errors.New("cannot scan NumberConstraints")
+	}
+}
+
+// Value implements the driver.Valuer interface
+func (c NumberConstraints) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Empty reports whether the constraints allow any number
+func (c *NumberConstraints) Empty() bool {
+	return c == nil || (!c.IntegerOnly && c.Min == nil && c.Max == nil)
+}
+
+// Validate checks that some number satisfies the constraints
+func (c *NumberConstraints) Validate() error {
+	if c.Min != nil && c.Max != nil && *c.Min > *c.Max {
+		return fmt.Errorf("min %v is greater than max %v", *c.Min, *c.Max)
+	}
+	if c.IntegerOnly && c.Min != nil && c.Max != nil && math.Ceil(*c.Min) > *c.Max {
+		return fmt.Errorf("no integer lies between min %v and max %v", *c.Min, *c.Max)
+	}
+	return nil
+}
+
+// Check returns an error describing how value violates the constraints, if it does
+func (c *NumberConstraints) Check(value float64) error {
+	if c == nil {
+		return nil
+	}
+	if c.IntegerOnly && value != math.Trunc(value) {
+		return fmt.Errorf("value %v must be an integer", value)
+	}
+	if c.Min != nil && value < *c.Min {
+		return fmt.Errorf("value %v must be at least %v", value, *c.Min)
+	}
+	if c.Max != nil && value > *c.Max {
+		return fmt.Errorf("value %v must be at most %v", value, *c.Max)
+	}
+	return nil
+}
+
 // Parameter represents the parameters table
 type Parameter struct {
 	ID                  uint                 `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -68,10 +142,16 @@ type Parameter struct {
 	Description         string               `gorm:"type:text;not null" json:"description"`
 	DataType            ParameterDataType    `gorm:"type:parameter_data_type;not null;default:'string'" json:"dataType"`
 	DefaultRolloutValue RolloutValue         `gorm:"type:jsonb;not null" json:"defaultRolloutValue"`
+	EnumOptions         pq.StringArray       `gorm:"type:text[];default:'{}'" json:"enumOptions"`
+	NumberConstraints   *NumberConstraints   `gorm:"type:jsonb;column:number_constraints" json:"numberConstraints,omitempty"`
 	UsageCount          int                  `gorm:"not null;default:0" json:"usageCount"`
 	CreatedAt           time.Time            `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt           time.Time            `gorm:"autoUpdateTime" json:"updatedAt"`
+	CreatedByUserID     *uint                `json:"createdByUserId,omitempty"`
+	UpdatedByUserID     *uint                `json:"updatedByUserId,omitempty"`
 	RawValue            json.RawMessage      `gorm:"type:jsonb;column:raw_value" json:"rawValue,omitempty"`
+	ArchivedAt          *time.Time           `gorm:"column:archived_at" json:"archivedAt,omitempty"`
+	DeletedAt           gorm.DeletedAt       `gorm:"index" json:"-"`
 	Conditions          []ParameterCondition `gorm:"foreignKey:ParameterID" json:"conditions"`
 	Rules               []ParameterRule      `gorm:"foreignKey:ParameterID" json:"rules"`
 }
@@ -95,7 +175,13 @@ func (p *Parameter) BeforeUpdate(tx *gorm.DB) error {
 func (p *Parameter) validate() error {
 	// Validate data type
 	switch p.DataType {
-	case ParameterDataTypeBoolean, ParameterDataTypeString, ParameterDataTypeNumber:
+	case ParameterDataTypeBoolean, ParameterDataTypeString, ParameterDataTypeNumber, ParameterDataTypeDate, ParameterDataTypeJSON:
+		return nil
+	case ParameterDataTypeEnum:
+		// Enum parameters must define their allowed options
+		if len(p.EnumOptions) == 0 {
+			return gorm.ErrInvalidData
+		}
 		return nil
 	default:
 		return gorm.ErrInvalidData
@@ -112,6 +198,8 @@ func (p *Parameter) PopulateRawValue() error {
 		"description":         p.Description,
 		"dataType":            p.DataType,
 		"defaultRolloutValue": p.DefaultRolloutValue,
+		"enumOptions":         p.EnumOptions,
+		"numberConstraints":   p.NumberConstraints,
 		"usageCount":          p.UsageCount,
 		"createdAt":           p.CreatedAt,
 		"updatedAt":           p.UpdatedAt,
@@ -168,17 +256,24 @@ func (pc *ParameterCondition) validate() error {
 
 // ParameterRule represents the parameter_rules table
 type ParameterRule struct {
-	ID           uint                     `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name         string                   `gorm:"not null;size:255" json:"name"`
-	Description  string                   `gorm:"type:text" json:"description"`
-	Type         RuleType                 `gorm:"type:rule_type;not null" json:"type"`
-	RolloutValue RolloutValue             `gorm:"type:jsonb;not null" json:"rolloutValue"`
-	ParameterID  uint                     `gorm:"not null" json:"parameterId"`
-	SegmentID    *uint                    `gorm:"" json:"segmentId,omitempty"`
-	MatchType    *ConditionMatchType      `gorm:"type:condition_match_type" json:"matchType,omitempty"`
-	Parameter    *Parameter               `gorm:"foreignKey:ParameterID" json:"parameter,omitempty"`
-	Segment      *Segment                 `gorm:"foreignKey:SegmentID" json:"segment,omitempty"`
-	Conditions   []ParameterRuleCondition `gorm:"foreignKey:RuleID" json:"conditions"`
+	ID                uint                `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name              string              `gorm:"not null;size:255" json:"name"`
+	Description       string              `gorm:"type:text" json:"description"`
+	Type              RuleType            `gorm:"type:rule_type;not null" json:"type"`
+	RolloutValue      RolloutValue        `gorm:"type:jsonb;not null" json:"rolloutValue"`
+	ParameterID       uint                `gorm:"not null" json:"parameterId"`
+	SegmentID         *uint               `gorm:"" json:"segmentId,omitempty"`
+	MatchType         *ConditionMatchType `gorm:"type:condition_match_type" json:"matchType,omitempty"`
+	ConditionLogic    ConditionLogic      `gorm:"type:varchar(10);not null;default:'and'" json:"conditionLogic"`
+	Priority          int                 `gorm:"not null;default:0" json:"priority"`
+	RolloutPercentage *int                `gorm:"" json:"rolloutPercentage,omitempty"`
+	HashAttributeName *string             `gorm:"size:255" json:"hashAttributeName,omitempty"`
+	Negate            bool                `gorm:"not null;default:false" json:"negate"`
+	// EnvironmentOverrideID is set on the rules of an environment override, and nil on the base rules
+	EnvironmentOverrideID *uint                    `gorm:"" json:"environmentOverrideId,omitempty"`
+	Parameter             *Parameter               `gorm:"foreignKey:ParameterID" json:"parameter,omitempty"`
+	Segment               *Segment                 `gorm:"foreignKey:SegmentID" json:"segment,omitempty"`
+	Conditions            []ParameterRuleCondition `gorm:"foreignKey:RuleID" json:"conditions"`
 }
 
 // TableName specifies the table name for GORM
@@ -198,6 +293,17 @@ func (pr *ParameterRule) BeforeUpdate(tx *gorm.DB) error {
 
 // validate performs validation on the parameter rule
 func (pr *ParameterRule) validate() error {
+	if pr.RolloutPercentage != nil && (*pr.RolloutPercentage < 0 || *pr.RolloutPercentage > 100) {
+		return gorm.ErrInvalidData
+	}
+
+	switch pr.ConditionLogic {
+	case "", ConditionLogicAnd, ConditionLogicOr:
+		// Valid
+	default:
+		return gorm.ErrInvalidData
+	}
+
 	// Validate rule type
 	switch pr.Type {
 	case RuleTypeSegment, RuleTypeAttribute:
@@ -254,7 +360,12 @@ func (prc *ParameterRuleCondition) validate() error {
 		ConditionOperatorContains, ConditionOperatorNotContains,
 		ConditionOperatorGreaterThan, ConditionOperatorLessThan,
 		ConditionOperatorGreaterThanOrEqual, ConditionOperatorLessThanOrEqual,
-		ConditionOperatorIn, ConditionOperatorNotIn:
+		ConditionOperatorIn, ConditionOperatorNotIn,
+		ConditionOperatorBetween, ConditionOperatorNotBetween,
+		ConditionOperatorMatchesRegex, ConditionOperatorNotMatchesRegex,
+		ConditionOperatorIsSet, ConditionOperatorIsNotSet,
+		ConditionOperatorSemverGt, ConditionOperatorSemverLt, ConditionOperatorSemverGte,
+		ConditionOperatorSemverLte, ConditionOperatorSemverEq:
 		return nil
 	default:
 		return gorm.ErrInvalidData
@@ -19,12 +19,23 @@ const (
 	ChangeRequestStatusCancelled ParameterChangeRequestStatus = "cancelled"
 )
 
-// ParameterChangeData holds the proposed changes to a parameter
+// ParameterChangeRequestType is what a change request does to parameters once approved
+type ParameterChangeRequestType string
+
+const (
+	ChangeRequestTypeCreate ParameterChangeRequestType = "create"
+	ChangeRequestTypeUpdate ParameterChangeRequestType = "update"
+	ChangeRequestTypeDelete ParameterChangeRequestType = "delete"
+)
+
+// ParameterChangeData holds the proposed changes to a parameter, or the whole new parameter for create requests
 type ParameterChangeData struct {
 	Name                *string                `json:"name,omitempty"`
 	Description         *string                `json:"description,omitempty"`
 	DataType            *ParameterDataType     `json:"dataType,omitempty"`
 	DefaultRolloutValue interface{}            `json:"defaultRolloutValue,omitempty"`
+	EnumOptions         []string               `json:"enumOptions,omitempty"`
+	NumberConstraints   *NumberConstraints     `json:"numberConstraints,omitempty"`
 	Rules               []ParameterRuleRequest `json:"rules,omitempty"`
 }
 
@@ -34,18 +45,25 @@ type ParameterCurrentConfig struct {
 	Description         string                 `json:"description"`
 	DataType            ParameterDataType      `json:"dataType"`
 	DefaultRolloutValue interface{}            `json:"defaultRolloutValue"`
+	EnumOptions         []string               `json:"enumOptions,omitempty"`
+	NumberConstraints   *NumberConstraints     `json:"numberConstraints,omitempty"`
 	Rules               []ParameterRuleRequest `json:"rules,omitempty"`
 }
 
 // ParameterRuleRequest represents a rule in the change request
 type ParameterRuleRequest struct {
-	Name         string                          `json:"name"`
-	Description  string                          `json:"description,omitempty"`
-	Type         RuleType                        `json:"type"`
-	RolloutValue interface{}                     `json:"rolloutValue"`
-	SegmentID    *uint                           `json:"segmentId,omitempty"`
-	MatchType    *ConditionMatchType             `json:"matchType,omitempty"`
-	Conditions   []ParameterRuleConditionRequest `json:"conditions,omitempty"`
+	Name              string                          `json:"name"`
+	Description       string                          `json:"description,omitempty"`
+	Type              RuleType                        `json:"type"`
+	RolloutValue      interface{}                     `json:"rolloutValue"`
+	SegmentID         *uint                           `json:"segmentId,omitempty"`
+	MatchType         *ConditionMatchType             `json:"matchType,omitempty"`
+	ConditionLogic    ConditionLogic                  `json:"conditionLogic,omitempty"`
+	Priority          *int                            `json:"priority,omitempty"`
+	RolloutPercentage *int                            `json:"rolloutPercentage,omitempty"`
+	HashAttributeName *string                         `json:"hashAttributeName,omitempty"`
+	Negate            bool                            `json:"negate,omitempty"`
+	Conditions        []ParameterRuleConditionRequest `json:"conditions,omitempty"`
 }
 
 // ParameterRuleConditionRequest represents a condition in a rule
@@ -97,22 +115,25 @@ func (pcc ParameterCurrentConfig) Value() (driver.Value, error) {
 	return json.Marshal(pcc)
 }
 
-// ParameterChangeRequest represents a request to change a parameter
+// ParameterChangeRequest represents a request to create, change or delete a parameter. ParameterID is
+// nil for create requests until they are approved, and then set to the created parameter.
 type ParameterChangeRequest struct {
-	ID                uint                         `gorm:"primaryKey;autoIncrement" json:"id"`
-	ParameterID       uint                         `gorm:"not null" json:"parameterId"`
-	RequestedByUserID uint                         `gorm:"not null" json:"requestedByUserId"`
-	Status            ParameterChangeRequestStatus `gorm:"type:parameter_change_request_status;not null;default:'pending'" json:"status"`
-	Description       string                       `gorm:"type:text" json:"description"`
-	ChangeData        ParameterChangeData          `gorm:"type:jsonb;not null;column:change_data" json:"changeData"`
-	CurrentConfig     ParameterCurrentConfig       `gorm:"type:jsonb;not null;column:current_config" json:"currentConfig"`
-	ReviewedByUserID  *uint                        `json:"reviewedByUserId,omitempty"`
-	ReviewedAt        *time.Time                   `json:"reviewedAt,omitempty"`
-	CreatedAt         time.Time                    `gorm:"autoCreateTime" json:"createdAt"`
-	UpdatedAt         time.Time                    `gorm:"autoUpdateTime" json:"updatedAt"`
-	Parameter         *Parameter                   `gorm:"foreignKey:ParameterID" json:"parameter,omitempty"`
-	RequestedByUser   *User                        `gorm:"foreignKey:RequestedByUserID" json:"requestedByUser,omitempty"`
-	ReviewedByUser    *User                        `gorm:"foreignKey:ReviewedByUserID" json:"reviewedByUser,omitempty"`
+	ID                 uint                         `gorm:"primaryKey;autoIncrement" json:"id"`
+	RequestType        ParameterChangeRequestType   `gorm:"type:varchar(16);not null;default:'update'" json:"requestType"`
+	ParameterID        *uint                        `json:"parameterId,omitempty"`
+	RequestedByUserID  uint                         `gorm:"not null" json:"requestedByUserId"`
+	Status             ParameterChangeRequestStatus `gorm:"type:parameter_change_request_status;not null;default:'pending'" json:"status"`
+	Description        string                       `gorm:"type:text" json:"description"`
+	ChangeData         ParameterChangeData          `gorm:"type:jsonb;not null;column:change_data" json:"changeData"`
+	CurrentConfig      ParameterCurrentConfig       `gorm:"type:jsonb;not null;column:current_config" json:"currentConfig"`
+	ReviewedByUserID   *uint                        `json:"reviewedByUserId,omitempty"`
+	ReviewedAt         *time.Time                   `json:"reviewedAt,omitempty"`
+	CancellationReason string                       `gorm:"type:text" json:"cancellationReason,omitempty"`
+	CreatedAt          time.Time                    `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt          time.Time                    `gorm:"autoUpdateTime" json:"updatedAt"`
+	Parameter          *Parameter                   `gorm:"foreignKey:ParameterID" json:"parameter,omitempty"`
+	RequestedByUser    *User                        `gorm:"foreignKey:RequestedByUserID" json:"requestedByUser,omitempty"`
+	ReviewedByUser     *User                        `gorm:"foreignKey:ReviewedByUserID" json:"reviewedByUser,omitempty"`
 }
 
 // TableName specifies the table name for GORM
@@ -135,8 +156,35 @@ func (pcr *ParameterChangeRequest) validate() error {
 	// Validate status
 	switch pcr.Status {
 	case ChangeRequestStatusPending, ChangeRequestStatusApproved, ChangeRequestStatusRejected, ChangeRequestStatusCancelled:
-		return nil
 	default:
 		return gorm.ErrInvalidData
 	}
+
+	// Only create requests may leave the parameter unset, until they are approved
+	switch pcr.RequestType {
+	case ChangeRequestTypeCreate:
+		if pcr.ParameterID == nil && pcr.Status == ChangeRequestStatusApproved {
+			return gorm.ErrInvalidData
+		}
+	case ChangeRequestTypeUpdate, ChangeRequestTypeDelete:
+		if pcr.ParameterID == nil {
+			return gorm.ErrInvalidData
+		}
+	default:
+		return gorm.ErrInvalidData
+	}
+	return nil
+}
+
+// ParameterName returns the name of the parameter the change request is about: the loaded parameter's,
+// or the one it was requested with when the parameter is not loaded, not created yet or deleted
+func (pcr *ParameterChangeRequest) ParameterName() string {
+	switch {
+	case pcr.Parameter != nil:
+		return pcr.Parameter.Name
+	case pcr.RequestType == ChangeRequestTypeCreate && pcr.ChangeData.Name != nil:
+		return *pcr.ChangeData.Name
+	default:
+		return pcr.CurrentConfig.Name
+	}
 }
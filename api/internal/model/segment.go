@@ -1,6 +1,9 @@
 package model
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -20,8 +23,58 @@ const (
 	ConditionOperatorLessThanOrEqual    ConditionOperator = "less_than_or_equal"
 	ConditionOperatorIn                 ConditionOperator = "in"
 	ConditionOperatorNotIn              ConditionOperator = "not_in"
+	ConditionOperatorBetween            ConditionOperator = "between"
+	ConditionOperatorNotBetween         ConditionOperator = "not_between"
+	ConditionOperatorMatchesRegex       ConditionOperator = "matches_regex"
+	ConditionOperatorNotMatchesRegex    ConditionOperator = "not_matches_regex"
+	// ConditionOperatorIsSet and ConditionOperatorIsNotSet check whether the attribute is present,
+	// whatever its value; the condition value is ignored
+	ConditionOperatorIsSet    ConditionOperator = "is_set"
+	ConditionOperatorIsNotSet ConditionOperator = "is_not_set"
+	// Semver operators compare string attributes such as app_version as semantic versions, so that
+	// 2.9.0 precedes 2.15.0
+	ConditionOperatorSemverGt  ConditionOperator = "semver_gt"
+	ConditionOperatorSemverLt  ConditionOperator = "semver_lt"
+	ConditionOperatorSemverGte ConditionOperator = "semver_gte"
+	ConditionOperatorSemverLte ConditionOperator = "semver_lte"
+	ConditionOperatorSemverEq  ConditionOperator = "semver_eq"
 )
 
+// ComparesSemver reports whether the operator compares the attribute and condition value as semantic versions
+func (o ConditionOperator) ComparesSemver() bool {
+	switch o {
+	case ConditionOperatorSemverGt, ConditionOperatorSemverLt, ConditionOperatorSemverGte,
+		ConditionOperatorSemverLte, ConditionOperatorSemverEq:
+		return true
+	}
+	return false
+}
+
+// ChecksPresence reports whether the operator only checks that the attribute is present, ignoring the condition value
+func (o ConditionOperator) ChecksPresence() bool {
+	return o == ConditionOperatorIsSet || o == ConditionOperatorIsNotSet
+}
+
+// ParseBetweenBounds parses the "min,max" value of a between or not_between condition
+func ParseBetweenBounds(value string) (float64, float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid between bounds %q: expected min,max", value)
+	}
+	min, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid between bounds %q: min is not a number", value)
+	}
+	max, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid between bounds %q: max is not a number", value)
+	}
+	if min > max {
+		return 0, 0, fmt.Errorf("invalid between bounds %q: min is greater than max", value)
+	}
+	return min, max, nil
+}
+
 // Segment represents the segments table
 type Segment struct {
 	ID          uint          `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -37,14 +90,32 @@ func (Segment) TableName() string {
 	return "segments"
 }
 
-// SegmentRule represents the segment_rules table
+// SegmentReferenceType tells whether a segment rule includes or excludes the users of the segment it references
+type SegmentReferenceType string
+
+const (
+	// SegmentReferenceInclude adds the users of the referenced segment to the segment
+	SegmentReferenceInclude SegmentReferenceType = "include"
+	// SegmentReferenceExclude removes the users of the referenced segment from those matched by the other rules
+	SegmentReferenceExclude SegmentReferenceType = "exclude"
+)
+
+// SegmentRule represents the segment_rules table. A rule either holds conditions or references
+// another segment, whose users it includes or excludes.
 type SegmentRule struct {
-	ID          uint                   `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name        string                 `gorm:"not null;size:255" json:"name"`
-	Description string                 `gorm:"type:text" json:"description"`
-	SegmentID   uint                   `gorm:"not null" json:"segmentId"`
-	Segment     *Segment               `gorm:"foreignKey:SegmentID" json:"segment,omitempty"`
-	Conditions  []SegmentRuleCondition `gorm:"foreignKey:RuleID" json:"conditions"`
+	ID                  uint                   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name                string                 `gorm:"not null;size:255" json:"name"`
+	Description         string                 `gorm:"type:text" json:"description"`
+	SegmentID           uint                   `gorm:"not null" json:"segmentId"`
+	Segment             *Segment               `gorm:"foreignKey:SegmentID" json:"segment,omitempty"`
+	ReferencedSegmentID *uint                  `json:"referencedSegmentId,omitempty"`
+	ReferenceType       SegmentReferenceType   `gorm:"size:16" json:"referenceType,omitempty"`
+	Conditions          []SegmentRuleCondition `gorm:"foreignKey:RuleID" json:"conditions"`
+}
+
+// References reports whether the rule references another segment rather than holding conditions
+func (sr *SegmentRule) References() bool {
+	return sr.ReferencedSegmentID != nil
 }
 
 // TableName specifies the table name for GORM
@@ -86,7 +157,12 @@ func (src *SegmentRuleCondition) validate() error {
 		ConditionOperatorContains, ConditionOperatorNotContains,
 		ConditionOperatorGreaterThan, ConditionOperatorLessThan,
 		ConditionOperatorGreaterThanOrEqual, ConditionOperatorLessThanOrEqual,
-		ConditionOperatorIn, ConditionOperatorNotIn:
+		ConditionOperatorIn, ConditionOperatorNotIn,
+		ConditionOperatorBetween, ConditionOperatorNotBetween,
+		ConditionOperatorMatchesRegex, ConditionOperatorNotMatchesRegex,
+		ConditionOperatorIsSet, ConditionOperatorIsNotSet,
+		ConditionOperatorSemverGt, ConditionOperatorSemverLt, ConditionOperatorSemverGte,
+		ConditionOperatorSemverLte, ConditionOperatorSemverEq:
 		return nil
 	default:
 		return gorm.ErrInvalidData
@@ -0,0 +1,224 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MaxSegmentReferenceDepth is how many levels deep segments may reference other segments
+const MaxSegmentReferenceDepth = 5
+
+// MaxExpandedSegmentRules bounds the number of rules a segment expands to. Excluding a segment
+// multiplies the rules of the segment by the conditions of every rule of the excluded segment.
+const MaxExpandedSegmentRules = 100
+
+// ErrInvalidSegmentReference is returned when the references of a segment cannot be expanded
+var ErrInvalidSegmentReference = errors.New("invalid segment reference")
+
+// referenceErrorf returns an ErrInvalidSegmentReference with the formatted details
+func referenceErrorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSegmentReference, fmt.Sprintf(format, args...))
+}
+
+// negatedOperators maps each operator to the operator matching the attribute values it does not match
+var negatedOperators = map[ConditionOperator]ConditionOperator{
+	ConditionOperatorEquals:             ConditionOperatorNotEquals,
+	ConditionOperatorNotEquals:          ConditionOperatorEquals,
+	ConditionOperatorContains:           ConditionOperatorNotContains,
+	ConditionOperatorNotContains:        ConditionOperatorContains,
+	ConditionOperatorGreaterThan:        ConditionOperatorLessThanOrEqual,
+	ConditionOperatorLessThanOrEqual:    ConditionOperatorGreaterThan,
+	ConditionOperatorLessThan:           ConditionOperatorGreaterThanOrEqual,
+	ConditionOperatorGreaterThanOrEqual: ConditionOperatorLessThan,
+	ConditionOperatorIn:                 ConditionOperatorNotIn,
+	ConditionOperatorNotIn:              ConditionOperatorIn,
+	ConditionOperatorBetween:            ConditionOperatorNotBetween,
+	ConditionOperatorNotBetween:         ConditionOperatorBetween,
+	ConditionOperatorMatchesRegex:       ConditionOperatorNotMatchesRegex,
+	ConditionOperatorNotMatchesRegex:    ConditionOperatorMatchesRegex,
+	ConditionOperatorSemverGt:           ConditionOperatorSemverLte,
+	ConditionOperatorSemverLte:          ConditionOperatorSemverGt,
+	ConditionOperatorSemverLt:           ConditionOperatorSemverGte,
+	ConditionOperatorSemverGte:          ConditionOperatorSemverLt,
+}
+
+// HasReferences reports whether any rule of the segment references another segment
+func (s *Segment) HasReferences() bool {
+	for i := range s.Rules {
+		if s.Rules[i].References() {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandSegmentRules returns the rules of segment with its references to other segments expanded, so that
+// every rule only holds conditions and SDK clients evaluate it like any other segment. segments holds every
+// segment referenced directly or indirectly by ID, with their rules, conditions and condition attributes.
+//
+// A rule including a segment is replaced by the expanded rules of that segment. The users of an excluded
+// segment are removed from the other rules by adding the negation of the excluded segment to each of them,
+// so a segment whose rules only exclude segments matches no one. A user without the attribute of a condition
+// matches its negation; attribute values of the wrong type, or outside the options of an enum attribute,
+// match neither a condition nor its negation.
+func ExpandSegmentRules(segment *Segment, segments map[uint]*Segment) ([]SegmentRule, error) {
+	rules, err := expandSegmentRules(segment, segments, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		rules[i].SegmentID = segment.ID
+	}
+	return rules, nil
+}
+
+// expandSegmentRules expands the rules of segment, which is referenced through the segments of path
+func expandSegmentRules(segment *Segment, segments map[uint]*Segment, path []*Segment) ([]SegmentRule, error) {
+	if slices.ContainsFunc(path, func(s *Segment) bool { return s.ID == segment.ID }) {
+		names := make([]string, 0, len(path)+1)
+		for _, s := range path {
+			names = append(names, s.Name)
+		}
+		return nil, referenceErrorf("%s -> %s is a cycle", strings.Join(names, " -> "), segment.Name)
+	}
+	if len(path) > MaxSegmentReferenceDepth {
+		return nil, referenceErrorf("segment '%s' nests segments more than %d levels deep", path[0].Name, MaxSegmentReferenceDepth)
+	}
+	path = append(path, segment)
+
+	rules := make([]SegmentRule, 0, len(segment.Rules))
+	var excluded [][]SegmentRule
+	for _, rule := range segment.Rules {
+		if !rule.References() {
+			rule.Conditions = slices.Clone(rule.Conditions)
+			rules = append(rules, rule)
+			continue
+		}
+
+		referenced, ok := segments[*rule.ReferencedSegmentID]
+		if !ok {
+			return nil, referenceErrorf("segment %d referenced by rule '%s' of segment '%s' not found", *rule.ReferencedSegmentID, rule.Name, segment.Name)
+		}
+		referencedRules, err := expandSegmentRules(referenced, segments, path)
+		if err != nil {
+			return nil, err
+		}
+		switch rule.ReferenceType {
+		case SegmentReferenceInclude:
+			rules = append(rules, referencedRules...)
+		case SegmentReferenceExclude:
+			excluded = append(excluded, referencedRules)
+		default:
+			return nil, referenceErrorf("rule '%s' of segment '%s' has reference type '%s', expected include or exclude", rule.Name, segment.Name, rule.ReferenceType)
+		}
+		if len(rules) > MaxExpandedSegmentRules {
+			return nil, referenceErrorf("segment '%s' expands to more than %d rules", segment.Name, MaxExpandedSegmentRules)
+		}
+	}
+
+	for _, excludedRules := range excluded {
+		negation, ok := negateSegmentRules(excludedRules)
+		if !ok {
+			return nil, referenceErrorf("segment '%s' excludes a segment whose negation takes more than %d rules", segment.Name, MaxExpandedSegmentRules)
+		}
+		rules = conjoinSegmentRules(rules, negation)
+		if len(rules) > MaxExpandedSegmentRules {
+			return nil, referenceErrorf("segment '%s' expands to more than %d rules", segment.Name, MaxExpandedSegmentRules)
+		}
+	}
+	return rules, nil
+}
+
+// negateSegmentRules returns rules matching the users that none of the given rules match, or false when
+// they exceed MaxExpandedSegmentRules. Rules match when any of them does, so the negation requires a
+// negated condition of every rule.
+func negateSegmentRules(rules []SegmentRule) ([]SegmentRule, bool) {
+	negation := []SegmentRule{{}}
+	for _, rule := range rules {
+		// A rule without conditions matches no one, so every user is outside of it
+		if len(rule.Conditions) == 0 {
+			continue
+		}
+		var alternatives []SegmentRuleCondition
+		for _, condition := range rule.Conditions {
+			alternatives = append(alternatives, negateCondition(condition)...)
+		}
+
+		next := make([]SegmentRule, 0, len(negation)*len(alternatives))
+		for _, partial := range negation {
+			for _, alternative := range alternatives {
+				next = append(next, SegmentRule{Conditions: append(slices.Clone(partial.Conditions), alternative)})
+			}
+		}
+		if len(next) > MaxExpandedSegmentRules {
+			return nil, false
+		}
+		negation = next
+	}
+	return negation, true
+}
+
+// conjoinSegmentRules returns rules matching the users matched by one of rules and one of negation
+func conjoinSegmentRules(rules, negation []SegmentRule) []SegmentRule {
+	conjoined := make([]SegmentRule, 0, len(rules)*len(negation))
+	for _, rule := range rules {
+		// A rule without conditions matches no one, which the added conditions must not change
+		if len(rule.Conditions) == 0 {
+			continue
+		}
+		for _, negated := range negation {
+			conjoinedRule := rule
+			conjoinedRule.Conditions = append(slices.Clone(rule.Conditions), negated.Conditions...)
+			conjoined = append(conjoined, conjoinedRule)
+		}
+	}
+	return conjoined
+}
+
+// negateCondition returns conditions of which any matches exactly when condition does not
+func negateCondition(condition SegmentRuleCondition) []SegmentRuleCondition {
+	withOperator := func(operator ConditionOperator, value string) SegmentRuleCondition {
+		negated := condition
+		negated.Operator = operator
+		negated.Value = value
+		return negated
+	}
+	absent := withOperator(ConditionOperatorIsNotSet, "")
+
+	switch condition.Operator {
+	case ConditionOperatorIsSet:
+		return []SegmentRuleCondition{absent}
+	case ConditionOperatorIsNotSet:
+		return []SegmentRuleCondition{withOperator(ConditionOperatorIsSet, "")}
+	case ConditionOperatorSemverEq:
+		return []SegmentRuleCondition{
+			withOperator(ConditionOperatorSemverLt, condition.Value),
+			withOperator(ConditionOperatorSemverGt, condition.Value),
+			absent,
+		}
+	case ConditionOperatorEquals, ConditionOperatorIn:
+		// Enum conditions only support equals and in, so the negation lists the other options
+		if condition.Attribute != nil && condition.Attribute.DataType == DataTypeEnum {
+			values := strings.Split(condition.Value, ",")
+			for i := range values {
+				values[i] = strings.TrimSpace(values[i])
+			}
+			var others []string
+			for _, option := range condition.Attribute.EnumOptions {
+				if !slices.Contains(values, option) {
+					others = append(others, option)
+				}
+			}
+			if len(others) == 0 {
+				return []SegmentRuleCondition{absent}
+			}
+			return []SegmentRuleCondition{withOperator(ConditionOperatorIn, strings.Join(others, ",")), absent}
+		}
+	}
+	if operator, ok := negatedOperators[condition.Operator]; ok {
+		return []SegmentRuleCondition{withOperator(operator, condition.Value), absent}
+	}
+	return []SegmentRuleCondition{absent}
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func referenceRule(name string, segmentID uint, referenceType SegmentReferenceType) SegmentRule {
+	return SegmentRule{Name: name, ReferencedSegmentID: &segmentID, ReferenceType: referenceType}
+}
+
+func condition(attribute string, dataType DataType, operator ConditionOperator, value string) SegmentRuleCondition {
+	return SegmentRuleCondition{Operator: operator, Value: value, Attribute: &Attribute{Name: attribute, DataType: dataType}}
+}
+
+// describeRules lists the conditions of each rule as attribute, operator and value
+func describeRules(rules []SegmentRule) [][]string {
+	described := make([][]string, len(rules))
+	for i, rule := range rules {
+		for _, c := range rule.Conditions {
+			described[i] = append(described[i], c.Attribute.Name+" "+string(c.Operator)+" "+c.Value)
+		}
+	}
+	return described
+}
+
+func TestExpandSegmentRulesIncludesReferencedRules(t *testing.T) {
+	northAmerica := &Segment{ID: 2, Name: "north america", Rules: []SegmentRule{
+		{Name: "us or ca", Conditions: []SegmentRuleCondition{condition("country", DataTypeString, ConditionOperatorIn, "US,CA")}},
+	}}
+	segment := &Segment{ID: 1, Name: "launch markets", Rules: []SegmentRule{
+		{Name: "vietnam", Conditions: []SegmentRuleCondition{condition("country", DataTypeString, ConditionOperatorEquals, "VN")}},
+		referenceRule("north america", 2, SegmentReferenceInclude),
+	}}
+
+	rules, err := ExpandSegmentRules(segment, map[uint]*Segment{2: northAmerica})
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{{"country equals VN"}, {"country in US,CA"}}, describeRules(rules))
+	for _, rule := range rules {
+		require.Equal(t, uint(1), rule.SegmentID)
+		require.False(t, rule.References())
+	}
+}
+
+func TestExpandSegmentRulesExcludesReferencedSegment(t *testing.T) {
+	iosInNorthAmerica := &Segment{ID: 2, Name: "ios in north america", Rules: []SegmentRule{
+		{Name: "ios", Conditions: []SegmentRuleCondition{
+			condition("country", DataTypeString, ConditionOperatorIn, "US,CA"),
+			condition("platform", DataTypeEnum, ConditionOperatorEquals, "ios"),
+		}},
+	}}
+	iosInNorthAmerica.Rules[0].Conditions[1].Attribute.EnumOptions = []string{"ios", "android", "web"}
+	segment := &Segment{ID: 1, Name: "adults outside ios in north america", Rules: []SegmentRule{
+		{Name: "adults", Conditions: []SegmentRuleCondition{condition("age", DataTypeNumber, ConditionOperatorGreaterThanOrEqual, "18")}},
+		referenceRule("not ios in north america", 2, SegmentReferenceExclude),
+	}}
+
+	rules, err := ExpandSegmentRules(segment, map[uint]*Segment{2: iosInNorthAmerica})
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{
+		{"age greater_than_or_equal 18", "country not_in US,CA"},
+		{"age greater_than_or_equal 18", "country is_not_set "},
+		{"age greater_than_or_equal 18", "platform in android,web"},
+		{"age greater_than_or_equal 18", "platform is_not_set "},
+	}, describeRules(rules))
+	// The referenced segment is left untouched
+	require.Equal(t, ConditionOperatorIn, iosInNorthAmerica.Rules[0].Conditions[0].Operator)
+}
+
+func TestExpandSegmentRulesWithOnlyExclusionsMatchesNoOne(t *testing.T) {
+	beta := &Segment{ID: 2, Name: "beta", Rules: []SegmentRule{
+		{Name: "beta", Conditions: []SegmentRuleCondition{condition("beta", DataTypeBoolean, ConditionOperatorEquals, "true")}},
+	}}
+	segment := &Segment{ID: 1, Name: "not beta", Rules: []SegmentRule{referenceRule("not beta", 2, SegmentReferenceExclude)}}
+
+	rules, err := ExpandSegmentRules(segment, map[uint]*Segment{2: beta})
+	require.NoError(t, err)
+	require.Empty(t, rules)
+}
+
+func TestExpandSegmentRulesRejectsCycles(t *testing.T) {
+	a := &Segment{ID: 1, Name: "a", Rules: []SegmentRule{referenceRule("b", 2, SegmentReferenceInclude)}}
+	b := &Segment{ID: 2, Name: "b", Rules: []SegmentRule{referenceRule("a", 1, SegmentReferenceExclude)}}
+
+	_, err := ExpandSegmentRules(a, map[uint]*Segment{1: a, 2: b})
+	require.True(t, errors.Is(err, ErrInvalidSegmentReference))
+	require.ErrorContains(t, err, "a -> b -> a is a cycle")
+}
+
+func TestExpandSegmentRulesRejectsDeepNesting(t *testing.T) {
+	segments := make(map[uint]*Segment)
+	for id := uint(1); id <= MaxSegmentReferenceDepth+2; id++ {
+		segments[id] = &Segment{ID: id, Name: "level", Rules: []SegmentRule{referenceRule("next", id+1, SegmentReferenceInclude)}}
+	}
+	segments[MaxSegmentReferenceDepth+2].Rules = []SegmentRule{
+		{Name: "leaf", Conditions: []SegmentRuleCondition{condition("country", DataTypeString, ConditionOperatorEquals, "VN")}},
+	}
+
+	_, err := ExpandSegmentRules(segments[2], segments)
+	require.NoError(t, err)
+
+	_, err = ExpandSegmentRules(segments[1], segments)
+	require.True(t, errors.Is(err, ErrInvalidSegmentReference))
+	require.ErrorContains(t, err, "levels deep")
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"api/internal/model"
+	"context"
+	"time"
+)
+
+// CreateAPIKey stores a new API key
+func (r *repository) CreateAPIKey(ctx context.Context, apiKey *model.APIKey) error {
+	return r.db.WithContext(ctx).Create(apiKey).Error
+}
+
+// GetAPIKeyByID retrieves an API key by ID
+func (r *repository) GetAPIKeyByID(ctx context.Context, id uint) (*model.APIKey, error) {
+	var apiKey model.APIKey
+	err := r.db.WithContext(ctx).First(&apiKey, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its plaintext
+func (r *repository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	var apiKey model.APIKey
+	err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// GetAllAPIKeys retrieves all API keys, newest first
+func (r *repository) GetAllAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	var apiKeys []*model.APIKey
+	err := r.db.WithContext(ctx).Order("created_at DESC").Find(&apiKeys).Error
+	return apiKeys, err
+}
+
+// RevokeAPIKey marks an API key as revoked at the given time
+func (r *repository) RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ?", id).
+		Update("revoked_at", revokedAt).Error
+}
@@ -1 +1,108 @@
 package repository
+
+import (
+	"api/internal/model"
+	"context"
+)
+
+// GetSegmentRuleConditionsByAttributeID retrieves all segment rule conditions referencing an attribute
+func (r *repository) GetSegmentRuleConditionsByAttributeID(ctx context.Context, attributeID uint) ([]*model.SegmentRuleCondition, error) {
+	var conditions []*model.SegmentRuleCondition
+	err := r.db.WithContext(ctx).
+		Preload("Rule").
+		Preload("Rule.Segment").
+		Where("attribute_id = ?", attributeID).
+		Order("id").
+		Find(&conditions).Error
+	return conditions, err
+}
+
+// GetParameterRuleConditionsByAttributeID retrieves all parameter rule conditions referencing an attribute
+func (r *repository) GetParameterRuleConditionsByAttributeID(ctx context.Context, attributeID uint) ([]*model.ParameterRuleCondition, error) {
+	var conditions []*model.ParameterRuleCondition
+	err := r.db.WithContext(ctx).
+		Preload("Rule").
+		Preload("Rule.Parameter").
+		Where("attribute_id = ?", attributeID).
+		Order("id").
+		Find(&conditions).Error
+	return conditions, err
+}
+
+// UpdateSegmentRuleConditionsAttribute points the given segment rule conditions to another attribute
+func (r *repository) UpdateSegmentRuleConditionsAttribute(ctx context.Context, ids []uint, attributeID uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&model.SegmentRuleCondition{}).Where("id IN ?", ids).
+		UpdateColumn("attribute_id", attributeID).Error
+}
+
+// UpdateParameterRuleConditionsAttribute points the given parameter rule conditions to another attribute
+func (r *repository) UpdateParameterRuleConditionsAttribute(ctx context.Context, ids []uint, attributeID uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&model.ParameterRuleCondition{}).Where("id IN ?", ids).
+		UpdateColumn("attribute_id", attributeID).Error
+}
+
+// DeleteSegmentRuleConditionsByIDs deletes the given segment rule conditions
+func (r *repository) DeleteSegmentRuleConditionsByIDs(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.SegmentRuleCondition{}).Error
+}
+
+// DeleteParameterRuleConditionsByIDs deletes the given parameter rule conditions
+func (r *repository) DeleteParameterRuleConditionsByIDs(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.ParameterRuleCondition{}).Error
+}
+
+// GetParameterIDsBySegmentIDs retrieves the IDs of parameters whose rules or legacy conditions reference any of the segments
+func (r *repository) GetParameterIDsBySegmentIDs(ctx context.Context, segmentIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(segmentIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.WithContext(ctx).Raw(
+		"SELECT parameter_id FROM parameter_rules WHERE segment_id IN ? UNION SELECT parameter_id FROM parameter_conditions WHERE segment_id IN ?",
+		segmentIDs, segmentIDs,
+	).Scan(&ids).Error
+	return ids, err
+}
+
+// GetExperimentIDsBySegmentIDs retrieves the IDs of experiments targeting any of the segments
+func (r *repository) GetExperimentIDsBySegmentIDs(ctx context.Context, segmentIDs []uint) ([]int, error) {
+	var ids []int
+	if len(segmentIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.WithContext(ctx).Model(&model.Experiment{}).
+		Where("segment_id IN ?", segmentIDs).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetExperimentIDsBySegmentIDsAndStatus retrieves the IDs of experiments in the given status targeting any of the segments
+func (r *repository) GetExperimentIDsBySegmentIDsAndStatus(ctx context.Context, segmentIDs []uint, status string) ([]int, error) {
+	var ids []int
+	if len(segmentIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.WithContext(ctx).Model(&model.Experiment{}).
+		Where("segment_id IN ? AND status = ?", segmentIDs, status).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// UpdateAttributeAliases re-points every attribute aliasing aliasOfID to targetID
+func (r *repository) UpdateAttributeAliases(ctx context.Context, aliasOfID uint, targetID uint) error {
+	return r.db.WithContext(ctx).Model(&model.Attribute{}).
+		Where("alias_of_id = ?", aliasOfID).
+		Update("alias_of_id", targetID).Error
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"api/internal/model"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// CreateAuditLog stores an audit log entry
+func (r *repository) CreateAuditLog(ctx context.Context, auditLog *model.AuditLog) error {
+	return r.db.WithContext(ctx).Create(auditLog).Error
+}
+
+// GetAuditLogs retrieves audit log entries matching the filter, newest first, with the total number of matches
+func (r *repository) GetAuditLogs(ctx context.Context, filter model.AuditLogFilter, limit, offset int) ([]*model.AuditLog, int64, error) {
+	var auditLogs []*model.AuditLog
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.AuditLog{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorUserID != 0 {
+		query = query.Where("actor_user_id = ?", filter.ActorUserID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	// Get total count
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	query = query.Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err := query.Find(&auditLogs).Error
+	return auditLogs, total, err
+}
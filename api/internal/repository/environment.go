@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"api/internal/model"
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateEnvironment stores a new environment
+func (r *repository) CreateEnvironment(ctx context.Context, environment *model.Environment) error {
+	return r.db.WithContext(ctx).Create(environment).Error
+}
+
+// GetEnvironmentByID retrieves an environment by ID
+func (r *repository) GetEnvironmentByID(ctx context.Context, id uint) (*model.Environment, error) {
+	var environment model.Environment
+	err := r.db.WithContext(ctx).First(&environment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+// GetEnvironmentByKey retrieves an environment by the key SDK clients identify it with
+func (r *repository) GetEnvironmentByKey(ctx context.Context, key string) (*model.Environment, error) {
+	var environment model.Environment
+	err := r.db.WithContext(ctx).Where("key = ?", key).First(&environment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+// GetAllEnvironments retrieves all environments ordered by ID
+func (r *repository) GetAllEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	var environments []*model.Environment
+	err := r.db.WithContext(ctx).Order("id").Find(&environments).Error
+	return environments, err
+}
+
+// UpdateEnvironment updates an existing environment
+func (r *repository) UpdateEnvironment(ctx context.Context, environment *model.Environment) error {
+	return r.db.WithContext(ctx).Save(environment).Error
+}
+
+// DeleteEnvironment deletes an environment. Its parameter overrides and their rules are removed
+// by cascade, so only the conditions of those rules are deleted here.
+func (r *repository) DeleteEnvironment(ctx context.Context, id uint) error {
+	overrideIDs := r.db.Model(&model.ParameterEnvironmentOverride{}).Select("id").Where("environment_id = ?", id)
+	ruleIDs := r.db.Model(&model.ParameterRule{}).Select("id").Where("environment_override_id IN (?)", overrideIDs)
+	err := r.db.WithContext(ctx).Where("rule_id IN (?)", ruleIDs).Delete(&model.ParameterRuleCondition{}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Delete(&model.Environment{}, id).Error
+}
+
+// CountExperimentsByEnvironmentID counts the experiments limited to an environment
+func (r *repository) CountExperimentsByEnvironmentID(ctx context.Context, environmentID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Experiment{}).Where("environment_id = ?", environmentID).Count(&count).Error
+	return count, err
+}
+
+// environmentRulesByPriority preloads the rules of an environment override in the order they are evaluated
+func environmentRulesByPriority(db *gorm.DB) *gorm.DB {
+	return db.Order("priority, id")
+}
+
+// GetParameterEnvironmentOverride retrieves the override of a parameter in an environment with its rules
+func (r *repository) GetParameterEnvironmentOverride(ctx context.Context, parameterID uint, environmentID uint) (*model.ParameterEnvironmentOverride, error) {
+	var override model.ParameterEnvironmentOverride
+	err := r.db.WithContext(ctx).
+		Preload("Environment").
+		Preload("Rules", environmentRulesByPriority).
+		Preload("Rules.Segment").
+		Preload("Rules.Conditions").
+		Preload("Rules.Conditions.Attribute").
+		Where("parameter_id = ? AND environment_id = ?", parameterID, environmentID).
+		First(&override).Error
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// GetParameterEnvironmentOverridesByParameterID retrieves the overrides of a parameter in every environment
+func (r *repository) GetParameterEnvironmentOverridesByParameterID(ctx context.Context, parameterID uint) ([]*model.ParameterEnvironmentOverride, error) {
+	var overrides []*model.ParameterEnvironmentOverride
+	err := r.db.WithContext(ctx).
+		Preload("Environment").
+		Preload("Rules", environmentRulesByPriority).
+		Preload("Rules.Segment").
+		Preload("Rules.Conditions").
+		Preload("Rules.Conditions.Attribute").
+		Where("parameter_id = ?", parameterID).
+		Order("environment_id").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+// SaveParameterEnvironmentOverride creates or updates an override, leaving its rules untouched
+func (r *repository) SaveParameterEnvironmentOverride(ctx context.Context, override *model.ParameterEnvironmentOverride) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(override).Error
+}
+
+// DeleteParameterEnvironmentOverride deletes an override together with its rules and their conditions
+func (r *repository) DeleteParameterEnvironmentOverride(ctx context.Context, id uint) error {
+	if err := r.DeleteParameterEnvironmentRules(ctx, id); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Delete(&model.ParameterEnvironmentOverride{}, id).Error
+}
+
+// DeleteParameterEnvironmentRules deletes the rules of an override and their conditions
+func (r *repository) DeleteParameterEnvironmentRules(ctx context.Context, overrideID uint) error {
+	ruleIDs := r.db.Model(&model.ParameterRule{}).Select("id").Where("environment_override_id = ?", overrideID)
+	err := r.db.WithContext(ctx).Where("rule_id IN (?)", ruleIDs).Delete(&model.ParameterRuleCondition{}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Where("environment_override_id = ?", overrideID).Delete(&model.ParameterRule{}).Error
+}
+
+// GetParameterEnvironmentRawValues returns the raw_value of every parameter overridden in an environment,
+// keyed by parameter ID
+func (r *repository) GetParameterEnvironmentRawValues(ctx context.Context, environmentID uint) (map[uint]json.RawMessage, error) {
+	var overrides []model.ParameterEnvironmentOverride
+	err := r.db.WithContext(ctx).
+		Select("parameter_id, raw_value").
+		Where("environment_id = ? AND raw_value IS NOT NULL", environmentID).
+		Find(&overrides).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rawValues := make(map[uint]json.RawMessage, len(overrides))
+	for _, override := range overrides {
+		rawValues[override.ParameterID] = override.RawValue
+	}
+	return rawValues, nil
+}
+
+// updateParameterEnvironmentRawValues regenerates the raw_value of every override of a parameter
+// from the parameter's base definition, which must be loaded with the same preloads as its rules
+func (r *repository) updateParameterEnvironmentRawValues(ctx context.Context, parameter *model.Parameter) error {
+	var overrides []*model.ParameterEnvironmentOverride
+	err := r.db.WithContext(ctx).
+		Preload("Rules", environmentRulesByPriority).
+		Preload("Rules.Segment").
+		Preload("Rules.Segment.Rules").
+		Preload("Rules.Segment.Rules.Conditions").
+		Preload("Rules.Segment.Rules.Conditions.Attribute").
+		Preload("Rules.Conditions").
+		Preload("Rules.Conditions.Attribute").
+		Where("parameter_id = ?", parameter.ID).
+		Find(&overrides).Error
+	if err != nil {
+		return err
+	}
+
+	for _, override := range overrides {
+		view := override.Apply(parameter)
+		if err := view.PopulateRawValue(); err != nil {
+			return err
+		}
+		err := r.db.WithContext(ctx).Model(override).UpdateColumn("raw_value", view.RawValue).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
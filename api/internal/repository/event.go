@@ -3,6 +3,10 @@ package repository
 import (
 	"api/internal/model"
 	"context"
+	"encoding/json"
+	"fmt"
+	"sdk/types"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -17,33 +21,89 @@ func NewEventRepository(db *gorm.DB) *EventRepository {
 	return &EventRepository{db: db}
 }
 
-// CreateEvent creates a new evaluation event
-func (r *EventRepository) CreateEvent(ctx context.Context, event *model.EvaluationEvent) error {
-	// Convert user attributes to JSON string
-	if event.UserAttributes != "" {
-		// Already a JSON string, no conversion needed
-	} else {
-		// This shouldn't happen if called from service layer, but handle gracefully
-		event.UserAttributes = "{}"
-	}
-
-	return r.db.WithContext(ctx).Create(event).Error
+// evaluationEventRow is an evaluation event as decoded by jsonb_to_recordset in InsertEvents
+type evaluationEventRow struct {
+	EventID           string          `json:"event_id"`
+	ServiceName       string          `json:"service_name"`
+	EventType         string          `json:"event_type"`
+	ParameterName     string          `json:"parameter_name"`
+	Source            string          `json:"source"`
+	UserAttributes    json.RawMessage `json:"user_attributes"`
+	RolloutValue      *string         `json:"rollout_value"`
+	Error             *string         `json:"error"`
+	Timestamp         time.Time       `json:"timestamp"`
+	ExperimentID      *int            `json:"experiment_id"`
+	ExperimentUUID    *string         `json:"experiment_uuid"`
+	VariantID         *int            `json:"variant_id"`
+	VariantName       *string         `json:"variant_name"`
+	VariantOverridden bool            `json:"variant_overridden"`
 }
 
-// CreateEventsBatch creates multiple evaluation events in a single transaction
-func (r *EventRepository) CreateEventsBatch(ctx context.Context, events []*model.EvaluationEvent) error {
+// insertEventsQuery inserts the events of a JSON array in one statement, whatever their number, since
+// the array is a single parameter. Events whose ID is already stored are skipped.
+const insertEventsQuery = `
+insert into evaluation_events (event_id, service_name, event_type, parameter_name, source, user_attributes, rollout_value, "error", "timestamp", experiment_id, experiment_uuid, variant_id, variant_name, variant_overridden)
+select event_id, service_name, event_type, parameter_name, source, user_attributes, rollout_value, "error", "timestamp", experiment_id, experiment_uuid, variant_id, variant_name, variant_overridden
+from jsonb_to_recordset(?::jsonb) as e(event_id varchar, service_name varchar, event_type varchar, parameter_name varchar, source varchar, user_attributes jsonb, rollout_value text, "error" text, "timestamp" timestamp, experiment_id integer, experiment_uuid varchar, variant_id integer, variant_name varchar, variant_overridden boolean)
+on conflict (event_id) do nothing
+returning event_id`
+
+// InsertEvents stores evaluation events in a single statement and returns the IDs of the inserted
+// events. Events whose ID is already stored are skipped, so retried batches are not counted twice.
+// Timestamps are stored in UTC.
+func (r *EventRepository) InsertEvents(ctx context.Context, events []model.EvaluationEvent) ([]string, error) {
 	if len(events) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	// Ensure all events have valid user attributes
-	for _, event := range events {
-		if event.UserAttributes == "" {
-			event.UserAttributes = "{}"
+	rows := make([]evaluationEventRow, len(events))
+	for i, event := range events {
+		userAttributes := json.RawMessage(event.UserAttributes)
+		if len(userAttributes) == 0 {
+			userAttributes = json.RawMessage("{}")
+		}
+		rows[i] = evaluationEventRow{
+			EventID:           event.EventID,
+			ServiceName:       event.ServiceName,
+			EventType:         event.EventType,
+			ParameterName:     event.ParameterName,
+			Source:            event.Source,
+			UserAttributes:    userAttributes,
+			RolloutValue:      event.RolloutValue,
+			Error:             event.Error,
+			Timestamp:         event.Timestamp.UTC(),
+			ExperimentID:      event.ExperimentID,
+			ExperimentUUID:    event.ExperimentUUID,
+			VariantID:         event.VariantID,
+			VariantName:       event.VariantName,
+			VariantOverridden: event.VariantOverridden,
 		}
 	}
+	payload, err := json.Marshal(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode events: %w", err)
+	}
 
-	return r.db.WithContext(ctx).CreateInBatches(events, 100).Error
+	var inserted []string
+	err = r.db.WithContext(ctx).Raw(insertEventsQuery, string(payload)).Scan(&inserted).Error
+	return inserted, err
+}
+
+// DeleteEventsBefore deletes the events that occurred before cutoff, at most limit at a time so that
+// no single statement holds locks for long, and returns the number of deleted events
+func (r *EventRepository) DeleteEventsBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	var deleted int64
+	for {
+		ids := r.db.Model(&model.EvaluationEvent{}).Select("id").Where("timestamp < ?", cutoff.UTC()).Limit(limit)
+		result := r.db.WithContext(ctx).Where("id IN (?)", ids).Delete(&model.EvaluationEvent{})
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += result.RowsAffected
+		if result.RowsAffected < int64(limit) {
+			return deleted, nil
+		}
+	}
 }
 
 // GetEventsByServiceName retrieves events for a specific service
@@ -122,3 +182,32 @@ func (r *EventRepository) GetEventStats(ctx context.Context, serviceName string)
 
 	return stats, nil
 }
+
+// GetExperimentExposures counts the experiment evaluation events of an experiment per variant and per day,
+// together with a total row per variant. Users are the distinct values of hashAttribute in the user attributes.
+func (r *EventRepository) GetExperimentExposures(ctx context.Context, experimentID int, hashAttribute string, filter model.ExposureFilter) ([]model.ExperimentExposure, error) {
+	var exposures []model.ExperimentExposure
+	err := exposuresQuery(r.db.WithContext(ctx), experimentID, hashAttribute, filter).Scan(&exposures).Error
+	return exposures, err
+}
+
+// exposuresQuery selects the exposures of an experiment. Events with a variant forced by ForceVariantAttribute
+// or by the experiment's overrides are left out since they do not reflect the traffic split.
+func exposuresQuery(db *gorm.DB, experimentID int, hashAttribute string, filter model.ExposureFilter) *gorm.DB {
+	query := db.
+		Model(&model.EvaluationEvent{}).
+		Select("variant_id, date_trunc('day', timestamp) as day, count(distinct user_attributes->>?) as users, count(*) as events", hashAttribute).
+		Where("experiment_id = ? AND event_type = ?", experimentID, string(model.EventTypeExperimentEvaluation)).
+		Where("variant_id is not null AND variant_overridden = ? AND source <> ?", false, types.EvaluationSourceOverride)
+
+	if filter.From != nil {
+		query = query.Where("timestamp >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("timestamp < ?", *filter.To)
+	}
+
+	return query.
+		Group("grouping sets ((variant_id, date_trunc('day', timestamp)), (variant_id))").
+		Order("variant_id, day nulls first")
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"api/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestExposuresQueryLeavesOutForcedVariants(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	require.NoError(t, err)
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	query := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var exposures []model.ExperimentExposure
+		return exposuresQuery(tx, 7, "user_id", model.ExposureFilter{From: &from}).Scan(&exposures)
+	})
+
+	require.Contains(t, query, "experiment_id = 7 AND event_type = 'experiment_evaluation'")
+	// Variants forced by ForceVariantAttribute or by the experiment's overrides do not count as exposures
+	require.Contains(t, query, "variant_overridden = false AND source <> 'override'")
+	require.Contains(t, query, "timestamp >= '2025-03-01 00:00:00'")
+}
@@ -6,15 +6,13 @@ import (
 
 	"api/internal/constant"
 
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CreateExperiment creates a new experiment
 func (r *repository) CreateExperiment(ctx context.Context, experiment *model.Experiment) error {
-	// Check if there's a transaction in context
-	if tx, ok := ctx.Value("tx").(*gorm.DB); ok {
-		return tx.WithContext(ctx).Create(experiment).Error
-	}
 	return r.db.WithContext(ctx).Create(experiment).Error
 }
 
@@ -29,6 +27,7 @@ func (r *repository) GetExperimentByID(ctx context.Context, id uint) (*model.Exp
 		Preload("HashAttribute").
 		Preload("Variants").
 		Preload("Variants.Parameters").
+		Preload("Overrides").
 		First(&experiment, id).Error
 	if err != nil {
 		return nil, err
@@ -62,6 +61,47 @@ func (r *repository) GetAllExperiments(ctx context.Context, limit, offset int) (
 	return experiments, err
 }
 
+// GetExperimentsPaginated retrieves a page of experiments matching the filter, newest first, with the total count.
+// Each row carries its segment name and variant count rather than the preloaded relations.
+func (r *repository) GetExperimentsPaginated(ctx context.Context, filter model.ExperimentFilter, limit, offset int) ([]*model.ExperimentSummary, int64, error) {
+	var experiments []*model.ExperimentSummary
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Experiment{})
+	if len(filter.Statuses) > 0 {
+		query = query.Where("experiments.status IN ?", filter.Statuses)
+	}
+	if filter.Search != "" {
+		query = query.Where("experiments.name ILIKE ?", "%"+filter.Search+"%")
+	}
+	if filter.StartAfter > 0 {
+		query = query.Where("experiments.start_date > ?", filter.StartAfter)
+	}
+	if filter.EndBefore > 0 {
+		query = query.Where("experiments.end_date < ?", filter.EndBefore)
+	}
+
+	// Get total count
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	query = query.
+		Select("experiments.*, segments.name AS segment_name, (SELECT COUNT(*) FROM experiment_variants WHERE experiment_variants.experiment_id = experiments.id) AS variant_count").
+		Joins("LEFT JOIN segments ON segments.id = experiments.segment_id").
+		Order("experiments.created_at DESC, experiments.id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err := query.Scan(&experiments).Error
+	return experiments, total, err
+}
+
 // UpdateExperiment updates an existing experiment
 func (r *repository) UpdateExperiment(ctx context.Context, experiment *model.Experiment) error {
 	return r.db.WithContext(ctx).Save(experiment).Error
@@ -81,10 +121,6 @@ func (r *repository) CountExperiments(ctx context.Context) (int64, error) {
 
 // CreateExperimentVariant creates a new experiment variant
 func (r *repository) CreateExperimentVariant(ctx context.Context, variant *model.ExperimentVariant) error {
-	// Check if there's a transaction in context
-	if tx, ok := ctx.Value("tx").(*gorm.DB); ok {
-		return tx.WithContext(ctx).Create(variant).Error
-	}
 	return r.db.WithContext(ctx).Create(variant).Error
 }
 
@@ -100,12 +136,18 @@ func (r *repository) DeleteExperimentVariantsByExperimentID(ctx context.Context,
 	return r.db.WithContext(ctx).Where("experiment_id = ?", experimentID).Delete(&model.ExperimentVariant{}).Error
 }
 
+// SaveExperimentOverride creates or updates an experiment override
+func (r *repository) SaveExperimentOverride(ctx context.Context, override *model.ExperimentOverride) error {
+	return r.db.WithContext(ctx).Save(override).Error
+}
+
+// DeleteExperimentOverride deletes an experiment override by ID
+func (r *repository) DeleteExperimentOverride(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.ExperimentOverride{}, id).Error
+}
+
 // CreateExperimentVariantParameter creates a new experiment variant parameter
 func (r *repository) CreateExperimentVariantParameter(ctx context.Context, parameter *model.ExperimentVariantParameter) error {
-	// Check if there's a transaction in context
-	if tx, ok := ctx.Value("tx").(*gorm.DB); ok {
-		return tx.WithContext(ctx).Create(parameter).Error
-	}
 	return r.db.WithContext(ctx).Create(parameter).Error
 }
 
@@ -140,11 +182,20 @@ func (r *repository) GetExperimentByName(ctx context.Context, name string) (*mod
 func (r *repository) GetExperimentsActive(ctx context.Context) ([]model.Experiment, error) {
 
 	result := make([]model.Experiment, 0)
+	// Paused experiments are exported as well so SDK caches pick up the paused
+	// status on the next refresh instead of keeping a stale running copy
 	err := r.db.WithContext(ctx).
-		Where("status in (?)", []string{constant.ExperimentStatusSchedule, constant.ExperimentStatusRunning}).
+		Where("status in (?)", []string{constant.ExperimentStatusSchedule, constant.ExperimentStatusRunning, constant.ExperimentStatusPause}).
+		Preload("Segment").
+		Preload("Segment.Rules").
+		Preload("Segment.Rules.Conditions").
+		Preload("Segment.Rules.Conditions.Attribute").
 		Preload("HashAttribute").
 		Preload("Variants").
 		Preload("Variants.Parameters").
+		Preload("Overrides").
+		// A stable order keeps the SDK payload, and so its ETag, unchanged between identical reads
+		Order("id").
 		Find(&result).Error
 	if err != nil {
 		return nil, err
@@ -152,12 +203,31 @@ func (r *repository) GetExperimentsActive(ctx context.Context) ([]model.Experime
 	return result, nil
 }
 
+// LockExperimentsDueForStatusChange locks the experiments whose dates have passed at now without their
+// status having moved on. Rows locked by another transaction are skipped, so concurrent callers never
+// advance the same experiment twice.
+func (r *repository) LockExperimentsDueForStatusChange(ctx context.Context, now int64, finishPausedOnEnd bool) ([]*model.Experiment, error) {
+	query := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("(status = ? AND start_date < ?) OR (status = ? AND end_date < ?)",
+			constant.ExperimentStatusSchedule, now, constant.ExperimentStatusRunning, now)
+	if finishPausedOnEnd {
+		query = query.Or("status = ? AND end_date < ?", constant.ExperimentStatusPause, now)
+	}
+
+	var experiments []*model.Experiment
+	if err := query.Order("id").Find(&experiments).Error; err != nil {
+		return nil, err
+	}
+	return experiments, nil
+}
+
 // FindConflictingExperiments finds experiments that conflict with the given parameters
 // Conflicts occur when:
 // 1. Same parameter IDs are used
 // 2. Segments can have overlapping users (sophisticated segment analysis)
 // 3. Time periods overlap
-// 4. Status is schedule or running
+// 4. Status is schedule, running or pause
 func (r *repository) FindConflictingExperiments(ctx context.Context, parameterIDs []int, segmentID int, startDate, endDate int64) ([]*model.Experiment, error) {
 	var experiments []*model.Experiment
 
@@ -166,7 +236,7 @@ func (r *repository) FindConflictingExperiments(ctx context.Context, parameterID
 	// Where [a,b] is existing experiment time window and [x,y] is new experiment time window
 	query := r.db.WithContext(ctx).
 		Joins("JOIN experiment_variant_parameters evp ON experiments.id = evp.experiment_id").
-		Where("experiments.status IN (?)", []string{constant.ExperimentStatusSchedule, constant.ExperimentStatusRunning}).
+		Where("experiments.status IN (?)", []string{constant.ExperimentStatusSchedule, constant.ExperimentStatusRunning, constant.ExperimentStatusPause}).
 		Where("evp.parameter_id IN (?)", parameterIDs).
 		Where("experiments.end_date >= ? AND ? >= experiments.start_date", startDate, endDate)
 
@@ -184,6 +254,33 @@ func (r *repository) FindConflictingExperiments(ctx context.Context, parameterID
 	return experiments, err
 }
 
+// RebuildAllExperimentRawValues regenerates the raw_value of every experiment from its related rows,
+// for experiments stored before raw_value existed or whose raw_value went stale. An experiment that
+// cannot be rebuilt does not stop the others; the number rebuilt and the IDs of the failed ones are returned.
+func (r *repository) RebuildAllExperimentRawValues(ctx context.Context) (int, []uint, error) {
+	logger := log.Ctx(ctx).With().Str("repository", "rebuild-all-experiment-raw-values").Logger()
+
+	var ids []uint
+	if err := r.db.WithContext(ctx).Model(&model.Experiment{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		return 0, nil, err
+	}
+
+	rebuilt := 0
+	failed := make([]uint, 0)
+	for _, id := range ids {
+		if err := r.UpdateExperimentRawValue(ctx, id); err != nil {
+			if ctx.Err() != nil {
+				return rebuilt, failed, ctx.Err()
+			}
+			logger.Warn().Err(err).Uint("experimentId", id).Msg("Failed to rebuild experiment raw_value")
+			failed = append(failed, id)
+			continue
+		}
+		rebuilt++
+	}
+	return rebuilt, failed, nil
+}
+
 // UpdateExperimentRawValue updates the raw_value field for an experiment after loading all related data
 func (r *repository) UpdateExperimentRawValue(ctx context.Context, id uint) error {
 	// Get the experiment with all related data loaded
@@ -196,10 +293,14 @@ func (r *repository) UpdateExperimentRawValue(ctx context.Context, id uint) erro
 		Preload("HashAttribute").
 		Preload("Variants").
 		Preload("Variants.Parameters").
+		Preload("Overrides").
 		First(&experiment, id).Error
 	if err != nil {
 		return err
 	}
+	if err := r.expandExperimentSegment(ctx, &experiment); err != nil {
+		return err
+	}
 
 	// Populate raw value with all related data
 	if err := experiment.PopulateRawValue(); err != nil {
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"api/internal/model"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CreateParameterVersion stores a snapshot of a parameter's raw_value
+func (r *repository) CreateParameterVersion(ctx context.Context, version *model.ParameterVersion) error {
+	return r.db.WithContext(ctx).Create(version).Error
+}
+
+// GetParameterVersionAt retrieves the latest snapshot of a parameter taken at or before the given time
+func (r *repository) GetParameterVersionAt(ctx context.Context, parameterID uint, at time.Time) (*model.ParameterVersion, error) {
+	var version model.ParameterVersion
+	err := r.db.WithContext(ctx).
+		Where("parameter_id = ? AND created_at <= ?", parameterID, at).
+		Order("created_at DESC").
+		First(&version).Error
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// CreateExperimentStatusTransition records a change of an experiment's status
+func (r *repository) CreateExperimentStatusTransition(ctx context.Context, transition *model.ExperimentStatusTransition) error {
+	return r.db.WithContext(ctx).Create(transition).Error
+}
+
+// GetExperimentStatusTransitionAt retrieves the latest status transition of an experiment at or before the given unix time
+func (r *repository) GetExperimentStatusTransitionAt(ctx context.Context, experimentID int, at int64) (*model.ExperimentStatusTransition, error) {
+	var transition model.ExperimentStatusTransition
+	err := r.db.WithContext(ctx).
+		Where("experiment_id = ? AND created_at <= ?", experimentID, at).
+		Order("created_at DESC").
+		First(&transition).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transition, nil
+}
+
+// GetFirstExperimentStatusTransitionAfter retrieves the earliest status transition of an experiment after the given unix time
+func (r *repository) GetFirstExperimentStatusTransitionAfter(ctx context.Context, experimentID int, at int64) (*model.ExperimentStatusTransition, error) {
+	var transition model.ExperimentStatusTransition
+	err := r.db.WithContext(ctx).
+		Where("experiment_id = ? AND created_at > ?", experimentID, at).
+		Order("created_at ASC").
+		First(&transition).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transition, nil
+}
+
+// HasParameterVersions reports whether any snapshot exists for a parameter
+func (r *repository) HasParameterVersions(ctx context.Context, parameterID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.ParameterVersion{}).Where("parameter_id = ?", parameterID).Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// GetParametersCreatedBefore retrieves parameters that existed at the given time, in batches ordered by ID.
+// Parameters deleted after that time are included.
+func (r *repository) GetParametersCreatedBefore(ctx context.Context, at time.Time, afterID uint, limit int) ([]*model.Parameter, error) {
+	var parameters []*model.Parameter
+	err := parametersCreatedBeforeQuery(r.db.WithContext(ctx), at, afterID, limit).Find(&parameters).Error
+	return parameters, err
+}
+
+// parametersCreatedBeforeQuery selects the parameters created at or before at and not deleted by then,
+// so soft-deleted parameters are looked up as well
+func parametersCreatedBeforeQuery(db *gorm.DB, at time.Time, afterID uint, limit int) *gorm.DB {
+	return db.Unscoped().
+		Select("id, name, created_at, raw_value").
+		Where("created_at <= ? AND id > ?", at, afterID).
+		Where("deleted_at IS NULL OR deleted_at > ?", at).
+		Order("id").
+		Limit(limit)
+}
+
+// GetExperimentsCreatedBefore retrieves experiments created at or before the given unix time, in batches ordered by ID
+func (r *repository) GetExperimentsCreatedBefore(ctx context.Context, at int64, afterID int, limit int) ([]*model.Experiment, error) {
+	var experiments []*model.Experiment
+	err := r.db.WithContext(ctx).
+		Where("created_at <= ? AND id > ?", at, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&experiments).Error
+	return experiments, err
+}
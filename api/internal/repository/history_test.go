@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"api/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestParametersCreatedBeforeQueryIncludesLaterDeletions(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	require.NoError(t, err)
+	at := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	query := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var parameters []*model.Parameter
+		return parametersCreatedBeforeQuery(tx, at, 20, 100).Find(&parameters)
+	})
+
+	require.Contains(t, query, `FROM "parameters" WHERE (created_at <= '2025-03-01 00:00:00' AND id > 20)`)
+	// Parameters deleted after at existed then, so only the parameters deleted by then are left out
+	require.Contains(t, query, "AND (deleted_at IS NULL OR deleted_at > '2025-03-01 00:00:00')")
+	require.NotContains(t, query, `"parameters"."deleted_at" IS NULL`)
+}
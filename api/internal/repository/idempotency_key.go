@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"api/internal/model"
+	"context"
+	"time"
+)
+
+// GetIdempotencyKey retrieves the idempotency key recorded for a scope
+func (r *repository) GetIdempotencyKey(ctx context.Context, scope string, key string) (*model.IdempotencyKey, error) {
+	var idempotencyKey model.IdempotencyKey
+	err := r.db.WithContext(ctx).Where("scope = ? AND key = ?", scope, key).First(&idempotencyKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &idempotencyKey, nil
+}
+
+// CreateIdempotencyKey records an idempotency key, replacing the same key if it was recorded before
+// expiredBefore. A key recorded since then fails the insert with a unique violation.
+func (r *repository) CreateIdempotencyKey(ctx context.Context, idempotencyKey *model.IdempotencyKey, expiredBefore time.Time) error {
+	err := r.db.WithContext(ctx).
+		Where("scope = ? AND key = ? AND created_at < ?", idempotencyKey.Scope, idempotencyKey.Key, expiredBefore).
+		Delete(&model.IdempotencyKey{}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(idempotencyKey).Error
+}
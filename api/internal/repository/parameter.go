@@ -3,10 +3,18 @@ package repository
 import (
 	"api/internal/model"
 	"context"
+	"time"
 
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 )
 
+// orderRulesByPriority preloads the base parameter rules, leaving out those of environment overrides,
+// in the order they are evaluated
+func orderRulesByPriority(db *gorm.DB) *gorm.DB {
+	return db.Where("environment_override_id IS NULL").Order("priority, id")
+}
+
 // CreateParameter creates a new parameter with its rules and conditions
 func (r *repository) CreateParameter(ctx context.Context, parameter *model.Parameter) error {
 	return r.db.WithContext(ctx).Create(parameter).Error
@@ -18,7 +26,7 @@ func (r *repository) GetParameterByID(ctx context.Context, id uint) (*model.Para
 	err := r.db.WithContext(ctx).
 		Preload("Conditions").
 		Preload("Conditions.Segment").
-		Preload("Rules").
+		Preload("Rules", orderRulesByPriority).
 		Preload("Rules.Segment").
 		Preload("Rules.Conditions").
 		Preload("Rules.Conditions.Attribute").
@@ -35,7 +43,7 @@ func (r *repository) GetParameterByName(ctx context.Context, name string) (*mode
 	err := r.db.WithContext(ctx).
 		Preload("Conditions").
 		Preload("Conditions.Segment").
-		Preload("Rules").
+		Preload("Rules", orderRulesByPriority).
 		Preload("Rules.Segment").
 		Preload("Rules.Conditions").
 		Preload("Rules.Conditions.Attribute").
@@ -53,7 +61,7 @@ func (r *repository) GetAllParameters(ctx context.Context, limit, offset int) ([
 	query := r.db.WithContext(ctx).
 		Preload("Conditions").
 		Preload("Conditions.Segment").
-		Preload("Rules").
+		Preload("Rules", orderRulesByPriority).
 		Preload("Rules.Segment").
 		Preload("Rules.Segment.Rules").
 		Preload("Rules.Segment.Rules.Conditions").
@@ -73,12 +81,92 @@ func (r *repository) GetAllParameters(ctx context.Context, limit, offset int) ([
 	return parameters, err
 }
 
+// GetParametersAfter retrieves a page of parameters with an ID greater than afterID, ordered by ID,
+// with all their rules and conditions. Archived parameters are only included when includeArchived is set.
+func (r *repository) GetParametersAfter(ctx context.Context, afterID uint, limit int, includeArchived bool) ([]*model.Parameter, error) {
+	var parameters []*model.Parameter
+	query := r.db.WithContext(ctx)
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+	err := query.
+		Preload("Conditions").
+		Preload("Conditions.Segment").
+		Preload("Rules", orderRulesByPriority).
+		Preload("Rules.Segment").
+		Preload("Rules.Conditions").
+		Preload("Rules.Conditions.Attribute").
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&parameters).Error
+	return parameters, err
+}
+
+// GetParameterByNameWithDeleted retrieves a parameter by name without its rules, including a deleted one
+func (r *repository) GetParameterByNameWithDeleted(ctx context.Context, name string) (*model.Parameter, error) {
+	var parameter model.Parameter
+	err := r.db.WithContext(ctx).Unscoped().Where("name = ?", name).First(&parameter).Error
+	if err != nil {
+		return nil, err
+	}
+	return &parameter, nil
+}
+
+// GetDeletedParametersAfter retrieves a page of deleted parameters with an ID greater than afterID,
+// ordered by ID, with all their rules and conditions
+func (r *repository) GetDeletedParametersAfter(ctx context.Context, afterID uint, limit int) ([]*model.Parameter, error) {
+	var parameters []*model.Parameter
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Preload("Conditions").
+		Preload("Conditions.Segment").
+		Preload("Rules", orderRulesByPriority).
+		Preload("Rules.Segment").
+		Preload("Rules.Conditions").
+		Preload("Rules.Conditions.Attribute").
+		Where("deleted_at IS NOT NULL AND id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&parameters).Error
+	return parameters, err
+}
+
+// RestoreParameter undoes the deletion of a parameter, returning gorm.ErrRecordNotFound when no
+// deleted parameter has the ID
+func (r *repository) RestoreParameter(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&model.Parameter{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // UpdateParameter updates an existing parameter
 func (r *repository) UpdateParameter(ctx context.Context, parameter *model.Parameter) error {
 	return r.db.WithContext(ctx).Save(parameter).Error
 }
 
-// DeleteParameter deletes a parameter by ID (cascade will handle rules and conditions)
+// SetParameterArchivedAt archives a parameter at archivedAt, or unarchives it when archivedAt is nil,
+// bumping updated_at so SDK clients see the change
+func (r *repository) SetParameterArchivedAt(ctx context.Context, id uint, archivedAt *time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.Parameter{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"archived_at": archivedAt,
+		"updated_at":  time.Now(),
+	}).Error
+}
+
+// SetParameterUpdatedBy records the user who last modified a parameter without running its hooks
+func (r *repository) SetParameterUpdatedBy(ctx context.Context, id uint, userID uint) error {
+	return r.db.WithContext(ctx).Model(&model.Parameter{}).Where("id = ?", id).UpdateColumn("updated_by_user_id", userID).Error
+}
+
+// DeleteParameter soft deletes a parameter by ID, keeping its rules and conditions for a restore
 func (r *repository) DeleteParameter(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&model.Parameter{}, id).Error
 }
@@ -91,7 +179,8 @@ func (r *repository) IncrementParameterUsageCount(ctx context.Context, id uint)
 
 // DecrementParameterUsageCount decrements the usage count for a parameter
 func (r *repository) DecrementParameterUsageCount(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Model(&model.Parameter{}).Where("id = ?", id).
+	// Counts recorded before experiments tracked usage may already be 0, so never go negative
+	return r.db.WithContext(ctx).Model(&model.Parameter{}).Where("id = ? AND usage_count > 0", id).
 		UpdateColumn("usage_count", r.db.Raw("usage_count - ?", 1)).Error
 }
 
@@ -107,13 +196,15 @@ func (r *repository) CreateParameterRule(ctx context.Context, rule *model.Parame
 	return r.db.WithContext(ctx).Create(rule).Error
 }
 
-// GetParameterRuleByID retrieves a parameter rule by ID with all its conditions
+// GetParameterRuleByID retrieves a base parameter rule by ID with all its conditions. Rules of
+// environment overrides are only changed through their override.
 func (r *repository) GetParameterRuleByID(ctx context.Context, id uint) (*model.ParameterRule, error) {
 	var rule model.ParameterRule
 	err := r.db.WithContext(ctx).
 		Preload("Conditions").
 		Preload("Conditions.Attribute").
 		Preload("Segment").
+		Where("environment_override_id IS NULL").
 		First(&rule, id).Error
 	if err != nil {
 		return nil, err
@@ -121,14 +212,15 @@ func (r *repository) GetParameterRuleByID(ctx context.Context, id uint) (*model.
 	return &rule, nil
 }
 
-// GetParameterRulesByParameterID retrieves all rules for a parameter
+// GetParameterRulesByParameterID retrieves all base rules for a parameter
 func (r *repository) GetParameterRulesByParameterID(ctx context.Context, parameterID uint) ([]*model.ParameterRule, error) {
 	var rules []*model.ParameterRule
 	err := r.db.WithContext(ctx).
 		Preload("Conditions").
 		Preload("Conditions.Attribute").
 		Preload("Segment").
-		Where("parameter_id = ?", parameterID).
+		Where("parameter_id = ? AND environment_override_id IS NULL", parameterID).
+		Order("priority, id").
 		Find(&rules).Error
 	return rules, err
 }
@@ -138,14 +230,27 @@ func (r *repository) UpdateParameterRule(ctx context.Context, rule *model.Parame
 	return r.db.WithContext(ctx).Save(rule).Error
 }
 
+// UpdateParameterRulePriorities sets the priority of each rule of a parameter to its position in ruleIDs
+func (r *repository) UpdateParameterRulePriorities(ctx context.Context, parameterID uint, ruleIDs []uint) error {
+	for priority, ruleID := range ruleIDs {
+		err := r.db.WithContext(ctx).Model(&model.ParameterRule{}).
+			Where("id = ? AND parameter_id = ?", ruleID, parameterID).
+			UpdateColumn("priority", priority).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // DeleteParameterRule deletes a parameter rule by ID
 func (r *repository) DeleteParameterRule(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&model.ParameterRule{}, id).Error
 }
 
-// DeleteParameterRulesByParameterID deletes all rules for a parameter
+// DeleteParameterRulesByParameterID deletes all base rules for a parameter, keeping those of its environment overrides
 func (r *repository) DeleteParameterRulesByParameterID(ctx context.Context, parameterID uint) error {
-	return r.db.WithContext(ctx).Where("parameter_id = ?", parameterID).Delete(&model.ParameterRule{}).Error
+	return r.db.WithContext(ctx).Where("parameter_id = ? AND environment_override_id IS NULL", parameterID).Delete(&model.ParameterRule{}).Error
 }
 
 // CreateParameterRuleCondition creates a new parameter rule condition
@@ -194,17 +299,39 @@ func (r *repository) GetParametersByIDs(ctx context.Context, ids []int) ([]model
 	return parameters, err
 }
 
-// GetAllParametersForSDK retrieves all parameters with only raw_value for SDK conversion
+// GetAllParametersForSDK retrieves all parameters that are not archived with only raw_value for SDK conversion
 // This is optimized for SDK usage since raw_value already contains all necessary data
 func (r *repository) GetAllParametersForSDK(ctx context.Context) ([]*model.Parameter, error) {
 	var parameters []*model.Parameter
 	err := r.db.WithContext(ctx).
 		Select("id, raw_value").
+		Where("archived_at IS NULL").
 		Order("id").
 		Find(&parameters).Error
 	return parameters, err
 }
 
+// GetParametersSDKVersion returns the latest updated_at across parameters that are not archived and
+// their count, which together change whenever the data served to SDK clients changes
+func (r *repository) GetParametersSDKVersion(ctx context.Context) (time.Time, int64, error) {
+	var result struct {
+		MaxUpdatedAt *time.Time
+		Count        int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.Parameter{}).
+		Select("max(updated_at) as max_updated_at, count(*) as count").
+		Where("archived_at IS NULL").
+		Scan(&result).Error
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	if result.MaxUpdatedAt == nil {
+		return time.Time{}, result.Count, nil
+	}
+	return *result.MaxUpdatedAt, result.Count, nil
+}
+
 // UpdateParameterRawValue updates the raw_value field for a parameter after loading all related data
 func (r *repository) UpdateParameterRawValue(ctx context.Context, id uint) error {
 	logger := log.Ctx(ctx).With().Str("repository", "update-parameter-raw-value").Uint("id", id).Logger()
@@ -214,7 +341,7 @@ func (r *repository) UpdateParameterRawValue(ctx context.Context, id uint) error
 	err := r.db.WithContext(ctx).
 		Preload("Conditions").
 		Preload("Conditions.Segment").
-		Preload("Rules").
+		Preload("Rules", orderRulesByPriority).
 		Preload("Rules.Segment").
 		Preload("Rules.Segment.Rules").
 		Preload("Rules.Segment.Rules.Conditions").
@@ -225,14 +352,32 @@ func (r *repository) UpdateParameterRawValue(ctx context.Context, id uint) error
 	if err != nil {
 		return err
 	}
+	if err := r.expandParameterSegments(ctx, &parameter); err != nil {
+		return err
+	}
 
 	// Populate raw value with all related data
 	if err := parameter.PopulateRawValue(); err != nil {
 		return err
 	}
 
-	// Update only the raw_value field
-	return r.db.WithContext(ctx).Model(&parameter).Select("raw_value").Updates(map[string]interface{}{
-		"raw_value": parameter.RawValue,
+	// Update only the raw_value field, bumping updated_at so SDK clients see the change
+	err = r.db.WithContext(ctx).Model(&parameter).Select("raw_value", "updated_at").Updates(map[string]interface{}{
+		"raw_value":  parameter.RawValue,
+		"updated_at": time.Now(),
 	}).Error
+	if err != nil {
+		return err
+	}
+
+	// Environments fall back to the base definition, so their views change along with it
+	if err := r.updateParameterEnvironmentRawValues(ctx, &parameter); err != nil {
+		return err
+	}
+
+	// Keep a snapshot of every configuration for point-in-time reconstruction
+	return r.CreateParameterVersion(ctx, &model.ParameterVersion{
+		ParameterID: parameter.ID,
+		RawValue:    parameter.RawValue,
+	})
 }
@@ -38,6 +38,19 @@ func (r *repository) GetPendingParameterChangeRequestByParameterID(ctx context.C
 	return &changeRequest, nil
 }
 
+// GetPendingParameterCreationRequestByName retrieves the pending request to create a parameter with the given name
+func (r *repository) GetPendingParameterCreationRequestByName(ctx context.Context, name string) (*model.ParameterChangeRequest, error) {
+	var changeRequest model.ParameterChangeRequest
+	err := r.db.WithContext(ctx).
+		Where("request_type = ? AND status = ? AND change_data->>'name' = ?", model.ChangeRequestTypeCreate, model.ChangeRequestStatusPending, name).
+		Preload("RequestedByUser").
+		First(&changeRequest).Error
+	if err != nil {
+		return nil, err
+	}
+	return &changeRequest, nil
+}
+
 // GetParameterChangeRequestsByParameterID retrieves all change requests for a specific parameter
 func (r *repository) GetParameterChangeRequestsByParameterID(ctx context.Context, parameterID uint) ([]*model.ParameterChangeRequest, error) {
 	var changeRequests []*model.ParameterChangeRequest
@@ -81,7 +94,7 @@ func (r *repository) GetParameterChangeRequestByIDWithDetails(ctx context.Contex
 	var changeRequest model.ParameterChangeRequest
 	err := r.db.WithContext(ctx).
 		Preload("Parameter").
-		Preload("Parameter.Rules").
+		Preload("Parameter.Rules", orderRulesByPriority).
 		Preload("Parameter.Rules.Conditions").
 		Preload("Parameter.Rules.Conditions.Attribute").
 		Preload("Parameter.Rules.Segment").
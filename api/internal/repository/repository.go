@@ -3,6 +3,9 @@ package repository
 import (
 	"api/internal/model"
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -13,21 +16,34 @@ type Repository interface {
 	CreateAttribute(ctx context.Context, attribute *model.Attribute) error
 	GetAttributeByID(ctx context.Context, id uint) (*model.Attribute, error)
 	GetAttributeByName(ctx context.Context, name string) (*model.Attribute, error)
-	GetAllAttributes(ctx context.Context, limit, offset int) ([]*model.Attribute, error)
+	ListAttributes(ctx context.Context, filter model.AttributeFilter) ([]*model.Attribute, int64, error)
 	UpdateAttribute(ctx context.Context, attribute *model.Attribute) error
 	DeleteAttribute(ctx context.Context, id uint) error
-	GetAttributesByDataType(ctx context.Context, dataType model.DataType, limit, offset int) ([]*model.Attribute, error)
 	IncrementAttributeUsageCount(ctx context.Context, id uint) error
 	DecrementAttributeUsageCount(ctx context.Context, id uint) error
 	CountAttributes(ctx context.Context) (int64, error)
+	GetSegmentRuleConditionsByAttributeID(ctx context.Context, attributeID uint) ([]*model.SegmentRuleCondition, error)
+	GetParameterRuleConditionsByAttributeID(ctx context.Context, attributeID uint) ([]*model.ParameterRuleCondition, error)
+	UpdateSegmentRuleConditionsAttribute(ctx context.Context, ids []uint, attributeID uint) error
+	UpdateParameterRuleConditionsAttribute(ctx context.Context, ids []uint, attributeID uint) error
+	DeleteSegmentRuleConditionsByIDs(ctx context.Context, ids []uint) error
+	DeleteParameterRuleConditionsByIDs(ctx context.Context, ids []uint) error
+	GetParameterIDsBySegmentIDs(ctx context.Context, segmentIDs []uint) ([]uint, error)
+	GetExperimentIDsBySegmentIDs(ctx context.Context, segmentIDs []uint) ([]int, error)
+	GetExperimentIDsBySegmentIDsAndStatus(ctx context.Context, segmentIDs []uint, status string) ([]int, error)
+	UpdateAttributeAliases(ctx context.Context, aliasOfID uint, targetID uint) error
 
 	// Segment operations
 	CreateSegment(ctx context.Context, segment *model.Segment) error
 	GetSegmentByID(ctx context.Context, id uint) (*model.Segment, error)
 	GetSegmentByName(ctx context.Context, name string) (*model.Segment, error)
 	GetAllSegments(ctx context.Context, limit, offset int) ([]*model.Segment, error)
+	GetAllSegmentsPaginated(ctx context.Context, limit, offset int, search string) ([]*model.Segment, int64, error)
 	UpdateSegment(ctx context.Context, segment *model.Segment) error
 	DeleteSegment(ctx context.Context, id uint) error
+	CountSegmentReferences(ctx context.Context, segmentID uint) (params int64, experiments int64, err error)
+	GetReferencingSegmentIDs(ctx context.Context, segmentID uint) ([]uint, error)
+	ExpandSegmentReferences(ctx context.Context, segment *model.Segment, replacements ...*model.Segment) error
 	CountSegments(ctx context.Context) (int64, error)
 
 	// Segment Rule operations
@@ -45,6 +61,12 @@ type Repository interface {
 	GetParameterByID(ctx context.Context, id uint) (*model.Parameter, error)
 	GetParameterByName(ctx context.Context, name string) (*model.Parameter, error)
 	GetAllParameters(ctx context.Context, limit, offset int) ([]*model.Parameter, error)
+	GetParametersAfter(ctx context.Context, afterID uint, limit int, includeArchived bool) ([]*model.Parameter, error)
+	GetParameterByNameWithDeleted(ctx context.Context, name string) (*model.Parameter, error)
+	GetDeletedParametersAfter(ctx context.Context, afterID uint, limit int) ([]*model.Parameter, error)
+	RestoreParameter(ctx context.Context, id uint) error
+	SetParameterArchivedAt(ctx context.Context, id uint, archivedAt *time.Time) error
+	SetParameterUpdatedBy(ctx context.Context, id uint, userID uint) error
 	UpdateParameter(ctx context.Context, parameter *model.Parameter) error
 	DeleteParameter(ctx context.Context, id uint) error
 	IncrementParameterUsageCount(ctx context.Context, id uint) error
@@ -52,6 +74,7 @@ type Repository interface {
 	CountParameters(ctx context.Context) (int64, error)
 	GetParametersByIDs(ctx context.Context, ids []int) ([]model.Parameter, error)
 	GetAllParametersForSDK(ctx context.Context) ([]*model.Parameter, error)
+	GetParametersSDKVersion(ctx context.Context) (time.Time, int64, error)
 	UpdateParameterRawValue(ctx context.Context, id uint) error
 
 	// Parameter Rule operations
@@ -59,6 +82,7 @@ type Repository interface {
 	GetParameterRuleByID(ctx context.Context, id uint) (*model.ParameterRule, error)
 	GetParameterRulesByParameterID(ctx context.Context, parameterID uint) ([]*model.ParameterRule, error)
 	UpdateParameterRule(ctx context.Context, rule *model.ParameterRule) error
+	UpdateParameterRulePriorities(ctx context.Context, parameterID uint, ruleIDs []uint) error
 	DeleteParameterRule(ctx context.Context, id uint) error
 	DeleteParameterRulesByParameterID(ctx context.Context, parameterID uint) error
 
@@ -77,6 +101,7 @@ type Repository interface {
 	GetExperimentByID(ctx context.Context, id uint) (*model.Experiment, error)
 	GetExperimentByUuid(ctx context.Context, uuid string) (*model.Experiment, error)
 	GetAllExperiments(ctx context.Context, limit, offset int) ([]*model.Experiment, error)
+	GetExperimentsPaginated(ctx context.Context, filter model.ExperimentFilter, limit, offset int) ([]*model.ExperimentSummary, int64, error)
 	UpdateExperiment(ctx context.Context, experiment *model.Experiment) error
 	DeleteExperiment(ctx context.Context, id uint) error
 	CountExperiments(ctx context.Context) (int64, error)
@@ -86,6 +111,10 @@ type Repository interface {
 	GetExperimentVariantsByExperimentID(ctx context.Context, experimentID uint) ([]*model.ExperimentVariant, error)
 	DeleteExperimentVariantsByExperimentID(ctx context.Context, experimentID uint) error
 
+	// Experiment Override operations
+	SaveExperimentOverride(ctx context.Context, override *model.ExperimentOverride) error
+	DeleteExperimentOverride(ctx context.Context, id uint) error
+
 	// Experiment Variant Parameter operations
 	CreateExperimentVariantParameter(ctx context.Context, parameter *model.ExperimentVariantParameter) error
 	GetExperimentVariantParametersByVariantID(ctx context.Context, variantID uint) ([]*model.ExperimentVariantParameter, error)
@@ -93,9 +122,28 @@ type Repository interface {
 	DeleteExperimentVariantParametersByVariantID(ctx context.Context, variantID uint) error
 	GetExperimentByName(ctx context.Context, name string) (*model.Experiment, error)
 	GetExperimentsActive(ctx context.Context) ([]model.Experiment, error)
+	LockExperimentsDueForStatusChange(ctx context.Context, now int64, finishPausedOnEnd bool) ([]*model.Experiment, error)
 	UpdateExperimentRawValue(ctx context.Context, id uint) error
+	RebuildAllExperimentRawValues(ctx context.Context) (int, []uint, error)
 	FindConflictingExperiments(ctx context.Context, parameterIDs []int, segmentID int, startDate, endDate int64) ([]*model.Experiment, error)
 
+	// Environment operations
+	CreateEnvironment(ctx context.Context, environment *model.Environment) error
+	GetEnvironmentByID(ctx context.Context, id uint) (*model.Environment, error)
+	GetEnvironmentByKey(ctx context.Context, key string) (*model.Environment, error)
+	GetAllEnvironments(ctx context.Context) ([]*model.Environment, error)
+	UpdateEnvironment(ctx context.Context, environment *model.Environment) error
+	DeleteEnvironment(ctx context.Context, id uint) error
+	CountExperimentsByEnvironmentID(ctx context.Context, environmentID uint) (int64, error)
+
+	// Parameter Environment Override operations
+	GetParameterEnvironmentOverride(ctx context.Context, parameterID uint, environmentID uint) (*model.ParameterEnvironmentOverride, error)
+	GetParameterEnvironmentOverridesByParameterID(ctx context.Context, parameterID uint) ([]*model.ParameterEnvironmentOverride, error)
+	SaveParameterEnvironmentOverride(ctx context.Context, override *model.ParameterEnvironmentOverride) error
+	DeleteParameterEnvironmentOverride(ctx context.Context, id uint) error
+	DeleteParameterEnvironmentRules(ctx context.Context, overrideID uint) error
+	GetParameterEnvironmentRawValues(ctx context.Context, environmentID uint) (map[uint]json.RawMessage, error)
+
 	// User operations
 	CreateUser(ctx context.Context, user *model.User) error
 	GetUserByID(ctx context.Context, id uint) (*model.User, error)
@@ -110,11 +158,37 @@ type Repository interface {
 	GetParameterChangeRequestByID(ctx context.Context, id uint) (*model.ParameterChangeRequest, error)
 	GetParameterChangeRequestByIDWithDetails(ctx context.Context, id uint) (*model.ParameterChangeRequest, error)
 	GetPendingParameterChangeRequestByParameterID(ctx context.Context, parameterID uint) (*model.ParameterChangeRequest, error)
+	GetPendingParameterCreationRequestByName(ctx context.Context, name string) (*model.ParameterChangeRequest, error)
 	GetParameterChangeRequestsByParameterID(ctx context.Context, parameterID uint) ([]*model.ParameterChangeRequest, error)
 	GetParameterChangeRequestsByStatus(ctx context.Context, status model.ParameterChangeRequestStatus, limit, offset int) ([]*model.ParameterChangeRequest, error)
 	GetParameterChangeRequestsByStatusWithPagination(ctx context.Context, status model.ParameterChangeRequestStatus, limit, offset int) ([]*model.ParameterChangeRequest, int64, error)
 	UpdateParameterChangeRequest(ctx context.Context, changeRequest *model.ParameterChangeRequest) error
 
+	// History operations
+	CreateParameterVersion(ctx context.Context, version *model.ParameterVersion) error
+	GetParameterVersionAt(ctx context.Context, parameterID uint, at time.Time) (*model.ParameterVersion, error)
+	CreateExperimentStatusTransition(ctx context.Context, transition *model.ExperimentStatusTransition) error
+	GetExperimentStatusTransitionAt(ctx context.Context, experimentID int, at int64) (*model.ExperimentStatusTransition, error)
+	GetFirstExperimentStatusTransitionAfter(ctx context.Context, experimentID int, at int64) (*model.ExperimentStatusTransition, error)
+	HasParameterVersions(ctx context.Context, parameterID uint) (bool, error)
+	GetParametersCreatedBefore(ctx context.Context, at time.Time, afterID uint, limit int) ([]*model.Parameter, error)
+	GetExperimentsCreatedBefore(ctx context.Context, at int64, afterID int, limit int) ([]*model.Experiment, error)
+
+	// Audit log operations
+	CreateAuditLog(ctx context.Context, auditLog *model.AuditLog) error
+	GetAuditLogs(ctx context.Context, filter model.AuditLogFilter, limit, offset int) ([]*model.AuditLog, int64, error)
+
+	// API key operations
+	CreateAPIKey(ctx context.Context, apiKey *model.APIKey) error
+	GetAPIKeyByID(ctx context.Context, id uint) (*model.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	GetAllAPIKeys(ctx context.Context) ([]*model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error
+
+	// Idempotency key operations
+	GetIdempotencyKey(ctx context.Context, scope string, key string) (*model.IdempotencyKey, error)
+	CreateIdempotencyKey(ctx context.Context, idempotencyKey *model.IdempotencyKey, expiredBefore time.Time) error
+
 	// Database access for transactions
 	GetDB() *gorm.DB
 }
@@ -124,7 +198,7 @@ type repository struct {
 	db *gorm.DB
 }
 
-// New creates a new repository
+// New creates a new repository. Given a transaction, every operation of the repository runs in it.
 func New(db *gorm.DB) Repository {
 	return &repository{
 		db: db,
@@ -161,20 +235,49 @@ func (r *repository) GetAttributeByName(ctx context.Context, name string) (*mode
 	return &attribute, nil
 }
 
-// GetAllAttributes retrieves all attributes with pagination
-func (r *repository) GetAllAttributes(ctx context.Context, limit, offset int) ([]*model.Attribute, error) {
+// attributeSortOrders maps attribute list sort orders to their ORDER BY clause, ties broken by ID
+var attributeSortOrders = map[string]string{
+	"":                            "created_at DESC, id DESC",
+	model.AttributeSortName:       "name ASC, id ASC",
+	model.AttributeSortCreatedAt:  "created_at DESC, id DESC",
+	model.AttributeSortUsageCount: "usage_count DESC, id ASC",
+}
+
+// ListAttributes retrieves a page of attributes matching the filter in its sort order, with the total count
+func (r *repository) ListAttributes(ctx context.Context, filter model.AttributeFilter) ([]*model.Attribute, int64, error) {
 	var attributes []*model.Attribute
-	query := r.db.WithContext(ctx).Order("created_at DESC")
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Attribute{})
+	if filter.DataType != "" {
+		query = query.Where("data_type = ?", filter.DataType)
+	}
+	if filter.Search != "" {
+		query = query.Where("name ILIKE ?", "%"+filter.Search+"%")
+	}
+	if filter.InUseOnly {
+		query = query.Where("usage_count > 0")
+	}
+
+	// Get total count
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 
-	if limit > 0 {
-		query = query.Limit(limit)
+	order, ok := attributeSortOrders[filter.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown attribute sort %q", filter.Sort)
+	}
+	query = query.Order(order)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
 	}
-	if offset > 0 {
-		query = query.Offset(offset)
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
 	}
 
 	err := query.Find(&attributes).Error
-	return attributes, err
+	return attributes, total, err
 }
 
 // UpdateAttribute updates an existing attribute
@@ -187,22 +290,6 @@ func (r *repository) DeleteAttribute(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&model.Attribute{}, id).Error
 }
 
-// GetAttributesByDataType retrieves attributes by data type with pagination
-func (r *repository) GetAttributesByDataType(ctx context.Context, dataType model.DataType, limit, offset int) ([]*model.Attribute, error) {
-	var attributes []*model.Attribute
-	query := r.db.WithContext(ctx).Where("data_type = ?", dataType).Order("created_at DESC")
-
-	if limit > 0 {
-		query = query.Limit(limit)
-	}
-	if offset > 0 {
-		query = query.Offset(offset)
-	}
-
-	err := query.Find(&attributes).Error
-	return attributes, err
-}
-
 // IncrementAttributeUsageCount increments the usage count for an attribute
 func (r *repository) IncrementAttributeUsageCount(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Model(&model.Attribute{}).Where("id = ?", id).
@@ -3,6 +3,10 @@ package repository
 import (
 	"api/internal/model"
 	"context"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
 )
 
 // CreateSegment creates a new segment with its rules and conditions
@@ -59,6 +63,40 @@ func (r *repository) GetAllSegments(ctx context.Context, limit, offset int) ([]*
 	return segments, err
 }
 
+// GetAllSegmentsPaginated retrieves a page of segments whose name or description matches search, with the total count
+func (r *repository) GetAllSegmentsPaginated(ctx context.Context, limit, offset int, search string) ([]*model.Segment, int64, error) {
+	var segments []*model.Segment
+	var total int64
+
+	filter := r.db.WithContext(ctx).Model(&model.Segment{})
+	if search != "" {
+		pattern := "%" + search + "%"
+		filter = filter.Where("name ILIKE ? OR description ILIKE ?", pattern, pattern)
+	}
+
+	// Get total count
+	if err := filter.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	query := filter.
+		Preload("Rules").
+		Preload("Rules.Conditions").
+		Preload("Rules.Conditions.Attribute").
+		Order("created_at DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err := query.Find(&segments).Error
+	return segments, total, err
+}
+
 // UpdateSegment updates an existing segment
 func (r *repository) UpdateSegment(ctx context.Context, segment *model.Segment) error {
 	return r.db.WithContext(ctx).Save(segment).Error
@@ -69,6 +107,121 @@ func (r *repository) DeleteSegment(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&model.Segment{}, id).Error
 }
 
+// CountSegmentReferences returns the number of parameters whose rules or legacy conditions reference
+// the segment and the number of experiments targeting it
+func (r *repository) CountSegmentReferences(ctx context.Context, segmentID uint) (int64, int64, error) {
+	var params int64
+	err := r.db.WithContext(ctx).Raw(
+		"SELECT count(*) FROM (SELECT parameter_id FROM parameter_rules WHERE segment_id = ? UNION SELECT parameter_id FROM parameter_conditions WHERE segment_id = ?) AS refs",
+		segmentID, segmentID,
+	).Scan(&params).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var experiments int64
+	err = r.db.WithContext(ctx).Model(&model.Experiment{}).
+		Where("segment_id = ?", segmentID).
+		Count(&experiments).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	return params, experiments, nil
+}
+
+// GetReferencingSegmentIDs returns the IDs of the segments whose rules reference the segment
+func (r *repository) GetReferencingSegmentIDs(ctx context.Context, segmentID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&model.SegmentRule{}).
+		Distinct("segment_id").
+		Where("referenced_segment_id = ?", segmentID).
+		Order("segment_id").
+		Pluck("segment_id", &ids).Error
+	return ids, err
+}
+
+// ExpandSegmentReferences replaces the rules of segment by their expansion, in which the rules referencing
+// other segments are replaced by the conditions they stand for. The referenced segments are loaded from
+// the database, except the segment itself and the replacements, which stand for the stored segments with
+// the same IDs so that changes can be checked before they are saved.
+func (r *repository) ExpandSegmentReferences(ctx context.Context, segment *model.Segment, replacements ...*model.Segment) error {
+	if !segment.HasReferences() {
+		return nil
+	}
+
+	segments := make(map[uint]*model.Segment)
+	for _, replacement := range replacements {
+		segments[replacement.ID] = replacement
+	}
+	if segment.ID != 0 {
+		segments[segment.ID] = segment
+	}
+	pending := []*model.Segment{segment}
+	for len(pending) > 0 {
+		var ids []uint
+		for _, s := range pending {
+			for _, rule := range s.Rules {
+				if rule.References() && segments[*rule.ReferencedSegmentID] == nil && !slices.Contains(ids, *rule.ReferencedSegmentID) {
+					ids = append(ids, *rule.ReferencedSegmentID)
+				}
+			}
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		var loaded []*model.Segment
+		err := r.db.WithContext(ctx).
+			Preload("Rules").
+			Preload("Rules.Conditions").
+			Preload("Rules.Conditions.Attribute").
+			Where("id IN ?", ids).
+			Find(&loaded).Error
+		if err != nil {
+			return err
+		}
+		for _, s := range loaded {
+			segments[s.ID] = s
+		}
+		pending = loaded
+	}
+
+	rules, err := model.ExpandSegmentRules(segment, segments)
+	if err != nil {
+		return err
+	}
+	segment.Rules = rules
+	return nil
+}
+
+// expandParameterSegments expands the segments referenced by the rules of the parameters, whose
+// rules are sent to SDK clients that only evaluate conditions
+func (r *repository) expandParameterSegments(ctx context.Context, parameters ...*model.Parameter) error {
+	for _, parameter := range parameters {
+		for _, rule := range parameter.Rules {
+			if rule.Segment == nil {
+				continue
+			}
+			if err := r.ExpandSegmentReferences(ctx, rule.Segment); err != nil {
+				return fmt.Errorf("failed to expand segment %d of parameter %d: %w", rule.Segment.ID, parameter.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// expandExperimentSegment expands the segment targeted by the experiment, which is sent to SDK clients
+// that only evaluate conditions
+func (r *repository) expandExperimentSegment(ctx context.Context, experiment *model.Experiment) error {
+	if experiment.Segment == nil {
+		return nil
+	}
+	if err := r.ExpandSegmentReferences(ctx, experiment.Segment); err != nil {
+		return fmt.Errorf("failed to expand segment %d of experiment %d: %w", experiment.Segment.ID, experiment.ID, err)
+	}
+	return nil
+}
+
 // CountSegments returns the total number of segments
 func (r *repository) CountSegments(ctx context.Context) (int64, error) {
 	var count int64
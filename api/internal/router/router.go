@@ -1,17 +1,39 @@
 package router
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"api/config"
+	"api/internal/apperror"
 	"api/internal/dto"
 	"api/internal/handler"
 	"api/internal/middleware"
 	"api/internal/model"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultParameterPageSize is the page size of the parameter list when no limit is given
+	defaultParameterPageSize = 50
+	// maxParameterPageSize caps the page size of the parameter list
+	maxParameterPageSize = 200
+	// sdkStreamKeepAlive is how often an idle SDK change stream sends a ping, so proxies keep it open
+	// and clients can tell a dead connection from a quiet one
+	sdkStreamKeepAlive = 30 * time.Second
 )
 
 // Router handles HTTP routing using gin
@@ -40,6 +62,7 @@ func (r *Router) SetupRoutes(engine *gin.Engine) {
 
 	// Health check
 	engine.GET("/health", r.healthCheck)
+	engine.GET("/health/live", r.livenessCheck)
 
 	// Auth routes (no versioning for OAuth)
 
@@ -61,13 +84,21 @@ func (r *Router) SetupRoutes(engine *gin.Engine) {
 			}
 		}
 
-		// SDK routes (public - no JWT middleware, accessed by client SDKs)
+		// SDK routes (no JWT middleware, accessed by client SDKs with an API key)
 		sdk := v1.Group("/sdk")
+		// Rate limiting runs before the API key check, so that requests with invalid keys are throttled
+		// before their keys are looked up in the database
+		if limit := r.config.SDK.RateLimit; limit.RequestsPerSecond > 0 {
+			sdk.Use(middleware.RateLimitMiddleware(middleware.NewRateLimiter(limit.RequestsPerSecond, limit.Burst), limit.ByAPIKey))
+		}
+		sdk.Use(middleware.APIKeyMiddleware(r.handler, r.config.SDK.RequireAPIKey))
 		{
 			sdk.POST("/metadata", r.getMetadataSDK)
 			sdk.POST("/parameters", r.getAllParametersSDK)
 			sdk.POST("/experiments", r.getAllExperimentsSDK)
 			sdk.POST("/events", r.trackEvent)
+			sdk.POST("/evaluate", r.evaluateSDK)
+			sdk.GET("/stream", r.streamSDKChanges)
 		}
 
 		// Protected routes group (require JWT authentication)
@@ -84,6 +115,7 @@ func (r *Router) SetupRoutes(engine *gin.Engine) {
 				attributes.DELETE("/:id", r.deleteAttribute)
 				attributes.PATCH("/:id/increment-usage", r.incrementAttributeUsageCount)
 				attributes.PATCH("/:id/decrement-usage", r.decrementAttributeUsageCount)
+				attributes.POST("/:id/merge-into/:targetId", r.mergeAttribute)
 			}
 
 			// Segment routes
@@ -102,10 +134,21 @@ func (r *Router) SetupRoutes(engine *gin.Engine) {
 			{
 				parameters.POST("", r.createParameter)
 				parameters.GET("", r.getAllParameters)
+				parameters.GET("/deleted", r.getDeletedParameters)
+				parameters.GET("/export", r.exportParameters)
+				parameters.POST("/import", r.importParameters)
 				parameters.GET("/:id", r.getParameterByID)
 				parameters.PATCH("/:id", r.updateParameter)
 				parameters.PUT("/:id", r.updateParameterWithRules)
 				parameters.DELETE("/:id", r.deleteParameter)
+				parameters.POST("/:id/archive", r.archiveParameter)
+				parameters.POST("/:id/unarchive", r.unarchiveParameter)
+				parameters.POST("/:id/restore", r.restoreParameter)
+				parameters.POST("/:id/clone", r.cloneParameter)
+				parameters.PATCH("/:id/rules/reorder", r.reorderParameterRules)
+				parameters.GET("/:id/environments", r.getParameterEnvironmentOverrides)
+				parameters.PUT("/:id/environments/:environmentId", r.setParameterEnvironmentOverride)
+				parameters.DELETE("/:id/environments/:environmentId", r.deleteParameterEnvironmentOverride)
 				parameters.POST("/simulate", r.simulateParameter)
 
 				// Parameter change request routes
@@ -117,10 +160,13 @@ func (r *Router) SetupRoutes(engine *gin.Engine) {
 			changeRequests := protected.Group("/parameter-change-requests")
 			{
 				changeRequests.GET("", r.getParameterChangeRequestsByStatus)
+				changeRequests.POST("", r.createParameterCreationRequest)
 				changeRequests.GET("/:id", r.getParameterChangeRequestByID)
 				changeRequests.GET("/:id/details", r.getParameterChangeRequestByIDWithDetails)
+				changeRequests.GET("/:id/diff", r.getParameterChangeRequestDiff)
 				changeRequests.PATCH("/:id/approve", r.approveParameterChangeRequest)
 				changeRequests.PATCH("/:id/reject", r.rejectParameterChangeRequest)
+				changeRequests.PATCH("/:id/cancel", r.cancelParameterChangeRequest)
 			}
 
 			// Experiment routes
@@ -128,10 +174,54 @@ func (r *Router) SetupRoutes(engine *gin.Engine) {
 			{
 				experiments.POST("", r.createExperiment)
 				experiments.GET("", r.getAllExperiments)
+				experiments.POST("/check-conflicts", r.checkExperimentConflicts)
 				experiments.GET("/:id", r.getExperimentByID)
+				experiments.GET("/uuid/:uuid", r.getExperimentByUuid)
+				experiments.GET("/uuid/:uuid/exposures", r.getExperimentExposuresByUuid)
 				experiments.PATCH("/:id/reject", r.rejectExperiment)
 				experiments.PATCH("/:id/approve", r.approveExperiment)
 				experiments.PATCH("/:id/abort", r.abortExperiment)
+				experiments.PATCH("/:id/pause", r.pauseExperiment)
+				experiments.PATCH("/:id/resume", r.resumeExperiment)
+				experiments.PATCH("/:id/variants", r.updateExperimentVariants)
+				experiments.DELETE("/:id", r.deleteExperiment)
+				experiments.POST("/:id/overrides", r.setExperimentOverride)
+				experiments.DELETE("/:id/overrides/:overrideId", r.deleteExperimentOverride)
+				experiments.POST("/:id/simulate", r.simulateExperiment)
+				experiments.GET("/:id/exposures", r.getExperimentExposures)
+			}
+
+			// Environment routes
+			environments := protected.Group("/environments")
+			{
+				environments.POST("", r.createEnvironment)
+				environments.GET("", r.getAllEnvironments)
+				environments.GET("/:id", r.getEnvironmentByID)
+				environments.PATCH("/:id", r.updateEnvironment)
+				environments.DELETE("/:id", r.deleteEnvironment)
+			}
+
+			// Audit log routes
+			protected.GET("/audit-logs", r.getAuditLogs)
+
+			// API key routes. Keys gate SDK data, so only admins may mint or revoke them.
+			apiKeys := protected.Group("/api-keys")
+			apiKeys.Use(middleware.RequireAdmin(r.config))
+			{
+				apiKeys.POST("", r.createAPIKey)
+				apiKeys.GET("", r.getAllAPIKeys)
+				apiKeys.DELETE("/:id", r.revokeAPIKey)
+			}
+
+			// Admin routes
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireAdmin(r.config))
+			{
+				admin.GET("/point-in-time", r.exportPointInTime)
+				admin.POST("/rebuild-raw-values", r.rebuildRawValues)
+				// Counters published with expvar, such as the experiments skipped from SDK responses. expvar also
+				// publishes the command line and memory statistics, so they are only served to admins.
+				admin.GET("/debug/vars", gin.WrapH(expvar.Handler()))
 			}
 		}
 	}
@@ -161,19 +251,52 @@ func (r *Router) errorHandlingMiddleware() gin.HandlerFunc {
 
 // Error handling
 func (r *Router) handleError(c *gin.Context, err error) {
+	// Experiment conflicts carry the conflicting experiments so clients can link to them
+	var conflictErr *apperror.ExperimentConflictError
+	if errors.As(err, &conflictErr) {
+		c.JSON(http.StatusConflict, dto.ToExperimentConflictResponse(conflictErr))
+		return
+	}
+
+	// Invalid rule conditions are listed one by one so clients can point at the offending condition
+	var conditionErr *apperror.ConditionValidationError
+	if errors.As(err, &conditionErr) {
+		c.JSON(http.StatusBadRequest, dto.ToConditionValidationResponse(conditionErr))
+		return
+	}
+
 	statusCode := http.StatusInternalServerError
 	errorType := "Internal Server Error"
+	errMsg := "internal server error"
 
-	errMsg := err.Error()
-	if contains(errMsg, "not found") {
+	switch errorKind(err) {
+	case apperror.KindNotFound:
 		statusCode = http.StatusNotFound
 		errorType = "Not Found"
-	} else if contains(errMsg, "already exists") || contains(errMsg, "required") || contains(errMsg, "cannot delete") {
+		errMsg = err.Error()
+	case apperror.KindConflict:
 		statusCode = http.StatusConflict
 		errorType = "Conflict"
-	} else if contains(errMsg, "invalid") || contains(errMsg, "bad request") {
+		errMsg = err.Error()
+	case apperror.KindBadRequest:
 		statusCode = http.StatusBadRequest
 		errorType = "Bad Request"
+		errMsg = err.Error()
+	case apperror.KindUnauthorized:
+		statusCode = http.StatusUnauthorized
+		errorType = "Unauthorized"
+		errMsg = err.Error()
+	case apperror.KindForbidden:
+		statusCode = http.StatusForbidden
+		errorType = "Forbidden"
+		errMsg = err.Error()
+	case apperror.KindTooManyRequests:
+		statusCode = http.StatusTooManyRequests
+		errorType = "Too Many Requests"
+		errMsg = err.Error()
+	default:
+		// Internal errors may carry driver or query details, so only the log sees them
+		log.Ctx(c.Request.Context()).Error().Err(err).Str("path", c.FullPath()).Msg("Unhandled error")
 	}
 
 	c.JSON(statusCode, dto.ErrorResponse{
@@ -182,50 +305,87 @@ func (r *Router) handleError(c *gin.Context, err error) {
 	})
 }
 
-// Helper function to check if string contains substring
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
-		(len(s) > len(substr) && containsHelper(s, substr)))
+// errorKind classifies an error, treating gorm's not found and invalid data errors that reach
+// the router unwrapped as their application error equivalents
+func errorKind(err error) apperror.Kind {
+	if kind := apperror.KindOf(err); kind != apperror.KindInternal {
+		return kind
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperror.KindNotFound
+	case errors.Is(err, gorm.ErrInvalidData):
+		return apperror.KindBadRequest
+	}
+	return apperror.KindInternal
 }
 
-func containsHelper(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
+// Helper function to parse ID from URL parameter
+// maxIdempotencyKeyLength is the longest Idempotency-Key header accepted
+const maxIdempotencyKeyLength = 255
+
+// parseIdempotencyKey returns the optional Idempotency-Key header of a creation request. Retrying the
+// request with the same key within 24 hours returns the originally created resource.
+func parseIdempotencyKey(c *gin.Context) (string, error) {
+	key := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if len(key) > maxIdempotencyKeyLength {
+		return "", apperror.BadRequest("Idempotency-Key header must be at most %d characters", maxIdempotencyKeyLength)
 	}
-	return false
+	return key, nil
 }
 
-// Helper function to parse ID from URL parameter
 func parseIDParam(c *gin.Context) (uint, error) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		return 0, err
+		return 0, apperror.BadRequest("invalid ID: %s", idStr)
 	}
 	return uint(id), nil
 }
 
+// parseListQuery flattens a query parameter that may be repeated or comma separated
+func parseListQuery(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
 // Health check handler
 func (r *Router) healthCheck(c *gin.Context) {
-	result, err := r.handler.HealthCheck(c.Request.Context())
-	if err != nil {
-		c.Error(err)
+	result := r.handler.HealthCheck(c.Request.Context())
+	if result.Status != dto.HealthStatusOK {
+		c.JSON(http.StatusServiceUnavailable, result)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"status": result})
+	c.JSON(http.StatusOK, result)
+}
+
+// Liveness check handler; it only reports that the process serves requests, without checking dependencies
+func (r *Router) livenessCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": dto.HealthStatusOK})
 }
 
 // Attribute handlers
 func (r *Router) createAttribute(c *gin.Context) {
 	var req dto.CreateAttributeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	result, err := r.handler.CreateAttribute(c.Request.Context(), &req)
+	result, err := r.handler.CreateAttribute(c.Request.Context(), userID, &req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -235,7 +395,34 @@ func (r *Router) createAttribute(c *gin.Context) {
 }
 
 func (r *Router) getAllAttributes(c *gin.Context) {
-	result, err := r.handler.GetAllAttributes(c.Request.Context())
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.Error(apperror.BadRequest("invalid limit parameter"))
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.Error(apperror.BadRequest("invalid offset parameter"))
+		return
+	}
+
+	inUseOnly, err := strconv.ParseBool(c.DefaultQuery("inUseOnly", "false"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid inUseOnly parameter"))
+		return
+	}
+
+	filter := model.AttributeFilter{
+		DataType:  model.DataType(c.Query("dataType")),
+		Search:    c.Query("search"),
+		InUseOnly: inUseOnly,
+		Sort:      c.Query("sort"),
+		Limit:     limit,
+		Offset:    offset,
+	}
+
+	result, err := r.handler.GetAllAttributes(c.Request.Context(), filter)
 	if err != nil {
 		c.Error(err)
 		return
@@ -269,11 +456,18 @@ func (r *Router) updateAttribute(c *gin.Context) {
 
 	var req dto.UpdateAttributeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+	req.Force = c.Query("force") == "true"
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	result, err := r.handler.UpdateAttribute(c.Request.Context(), id, &req)
+	result, err := r.handler.UpdateAttribute(c.Request.Context(), id, userID, &req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -289,7 +483,13 @@ func (r *Router) deleteAttribute(c *gin.Context) {
 		return
 	}
 
-	err = r.handler.DeleteAttribute(c.Request.Context(), id)
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	err = r.handler.DeleteAttribute(c.Request.Context(), id, userID)
 	if err != nil {
 		c.Error(err)
 		return
@@ -330,15 +530,51 @@ func (r *Router) decrementAttributeUsageCount(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+func (r *Router) mergeAttribute(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("targetId"), 10, 32)
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid target ID: %s", c.Param("targetId")))
+		return
+	}
+
+	dryRun := c.Query("dryRun") == "true"
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.MergeAttribute(c.Request.Context(), id, uint(targetID), userID, dryRun)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // Segment handlers
 func (r *Router) createSegment(c *gin.Context) {
 	var req dto.CreateSegmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
 
-	result, err := r.handler.CreateSegment(c.Request.Context(), &req)
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.CreateSegment(c.Request.Context(), userID, &req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -348,7 +584,19 @@ func (r *Router) createSegment(c *gin.Context) {
 }
 
 func (r *Router) getAllSegments(c *gin.Context) {
-	result, err := r.handler.GetAllSegments(c.Request.Context())
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.Error(apperror.BadRequest("invalid limit parameter"))
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.Error(apperror.BadRequest("invalid offset parameter"))
+		return
+	}
+
+	result, err := r.handler.GetAllSegments(c.Request.Context(), limit, offset, c.Query("search"))
 	if err != nil {
 		c.Error(err)
 		return
@@ -382,11 +630,17 @@ func (r *Router) updateSegment(c *gin.Context) {
 
 	var req dto.UpdateSegmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	result, err := r.handler.UpdateSegment(c.Request.Context(), id, &req)
+	result, err := r.handler.UpdateSegment(c.Request.Context(), id, userID, &req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -402,7 +656,13 @@ func (r *Router) deleteSegment(c *gin.Context) {
 		return
 	}
 
-	err = r.handler.DeleteSegment(c.Request.Context(), id)
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	err = r.handler.DeleteSegment(c.Request.Context(), id, userID)
 	if err != nil {
 		c.Error(err)
 		return
@@ -414,7 +674,7 @@ func (r *Router) deleteSegment(c *gin.Context) {
 func (r *Router) checkSegmentOverlap(c *gin.Context) {
 	var req dto.CheckSegmentOverlapRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
 
@@ -431,11 +691,24 @@ func (r *Router) checkSegmentOverlap(c *gin.Context) {
 func (r *Router) createParameter(c *gin.Context) {
 	var req dto.CreateParameterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+	idempotencyKey, err := parseIdempotencyKey(c)
+	if err != nil {
 		c.Error(err)
 		return
 	}
+	req.IdempotencyKey = idempotencyKey
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
 
-	result, err := r.handler.CreateParameter(c.Request.Context(), &req)
+	result, err := r.handler.CreateParameter(c.Request.Context(), userID, &req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -445,7 +718,45 @@ func (r *Router) createParameter(c *gin.Context) {
 }
 
 func (r *Router) getAllParameters(c *gin.Context) {
-	result, err := r.handler.GetAllParameters(c.Request.Context())
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultParameterPageSize)))
+	if err != nil || limit <= 0 {
+		c.Error(apperror.BadRequest("invalid limit parameter"))
+		return
+	}
+	limit = min(limit, maxParameterPageSize)
+
+	after, err := strconv.ParseUint(c.DefaultQuery("after", "0"), 10, 32)
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid after parameter"))
+		return
+	}
+
+	includeArchived := c.Query("includeArchived") == "true"
+
+	result, err := r.handler.GetAllParameters(c.Request.Context(), limit, uint(after), includeArchived)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) getDeletedParameters(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultParameterPageSize)))
+	if err != nil || limit <= 0 {
+		c.Error(apperror.BadRequest("invalid limit parameter"))
+		return
+	}
+	limit = min(limit, maxParameterPageSize)
+
+	after, err := strconv.ParseUint(c.DefaultQuery("after", "0"), 10, 32)
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid after parameter"))
+		return
+	}
+
+	result, err := r.handler.GetDeletedParameters(c.Request.Context(), limit, uint(after))
 	if err != nil {
 		c.Error(err)
 		return
@@ -479,11 +790,18 @@ func (r *Router) updateParameter(c *gin.Context) {
 
 	var req dto.UpdateParameterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	result, err := r.handler.UpdateParameter(c.Request.Context(), id, &req)
+	result, err := r.handler.UpdateParameter(c.Request.Context(), id, userID, &req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -501,11 +819,18 @@ func (r *Router) updateParameterWithRules(c *gin.Context) {
 
 	var req dto.UpdateParameterWithRulesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	result, err := r.handler.UpdateParameterWithRules(c.Request.Context(), id, &req)
+	result, err := r.handler.UpdateParameterWithRules(c.Request.Context(), id, userID, &req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -514,30 +839,27 @@ func (r *Router) updateParameterWithRules(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-func (r *Router) deleteParameter(c *gin.Context) {
+func (r *Router) reorderParameterRules(c *gin.Context) {
 	id, err := parseIDParam(c)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	err = r.handler.DeleteParameter(c.Request.Context(), id)
-	if err != nil {
-		c.Error(err)
+	var req dto.ReorderParameterRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
 
-	c.Status(http.StatusNoContent)
-}
-
-func (r *Router) simulateParameter(c *gin.Context) {
-	var req dto.SimulateParameterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	result, err := r.handler.SimulateParameter(c.Request.Context(), &req)
+	result, err := r.handler.ReorderParameterRules(c.Request.Context(), id, userID, &req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -546,41 +868,43 @@ func (r *Router) simulateParameter(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-// Experiment handlers
-func (r *Router) createExperiment(c *gin.Context) {
-	var req dto.CreateExperimentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+func (r *Router) deleteParameter(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	result, err := r.handler.CreateExperiment(c.Request.Context(), &req)
-	if err != nil {
-		c.Error(err)
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
-}
-
-func (r *Router) getAllExperiments(c *gin.Context) {
-	result, err := r.handler.GetAllExperiments(c.Request.Context())
+	err = r.handler.DeleteParameter(c.Request.Context(), id, userID)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.Status(http.StatusNoContent)
 }
 
-func (r *Router) getExperimentByID(c *gin.Context) {
+func (r *Router) archiveParameter(c *gin.Context) {
 	id, err := parseIDParam(c)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	result, err := r.handler.GetExperimentByID(c.Request.Context(), id)
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.ArchiveParameter(c.Request.Context(), id, userID)
 	if err != nil {
 		c.Error(err)
 		return
@@ -589,20 +913,20 @@ func (r *Router) getExperimentByID(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-func (r *Router) rejectExperiment(c *gin.Context) {
+func (r *Router) restoreParameter(c *gin.Context) {
 	id, err := parseIDParam(c)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	var req dto.RejectExperimentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	result, err := r.handler.RejectExperiment(c.Request.Context(), id, &req)
+	result, err := r.handler.RestoreParameter(c.Request.Context(), id, userID)
 	if err != nil {
 		c.Error(err)
 		return
@@ -611,42 +935,48 @@ func (r *Router) rejectExperiment(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-func (r *Router) approveExperiment(c *gin.Context) {
+func (r *Router) cloneParameter(c *gin.Context) {
 	id, err := parseIDParam(c)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	var req dto.ApproveExperimentRequest
+	var req dto.CloneParameterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	result, err := r.handler.ApproveExperiment(c.Request.Context(), id, &req)
+	result, err := r.handler.CloneParameter(c.Request.Context(), id, userID, &req)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusCreated, result)
 }
 
-func (r *Router) abortExperiment(c *gin.Context) {
+func (r *Router) unarchiveParameter(c *gin.Context) {
 	id, err := parseIDParam(c)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	var req dto.AbortExperimentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	result, err := r.handler.AbortExperiment(c.Request.Context(), id, &req)
+	result, err := r.handler.UnarchiveParameter(c.Request.Context(), id, userID)
 	if err != nil {
 		c.Error(err)
 		return
@@ -655,15 +985,14 @@ func (r *Router) abortExperiment(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-// SDK handlers
-func (r *Router) getMetadataSDK(c *gin.Context) {
-	var req dto.GetMetadataSDKRequest
+func (r *Router) simulateParameter(c *gin.Context) {
+	var req dto.SimulateParameterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
 
-	result, err := r.handler.GetMetdataSDK(c.Request.Context(), &req)
+	result, err := r.handler.SimulateParameter(c.Request.Context(), &req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -672,16 +1001,520 @@ func (r *Router) getMetadataSDK(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-func (r *Router) getAllParametersSDK(c *gin.Context) {
-
-	var req dto.GetAllParametersSDKRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+func (r *Router) exportParameters(c *gin.Context) {
+	result, err := r.handler.ExportParameters(c.Request.Context())
+	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	result, err := r.handler.GetAllParametersSDK(c.Request.Context(), &req)
-	if err != nil {
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) importParameters(c *gin.Context) {
+	var req dto.ImportParametersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	dryRun := c.Query("dryRun") == "true"
+
+	result, err := r.handler.ImportParameters(c.Request.Context(), userID, &req, dryRun)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// A failed import still returns its per-item error report
+	status := http.StatusOK
+	if !result.DryRun && len(result.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, result)
+}
+
+// Experiment handlers
+func (r *Router) createExperiment(c *gin.Context) {
+	var req dto.CreateExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+	idempotencyKey, err := parseIdempotencyKey(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	req.IdempotencyKey = idempotencyKey
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.CreateExperiment(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) getAllExperiments(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.Error(apperror.BadRequest("invalid limit parameter"))
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.Error(apperror.BadRequest("invalid offset parameter"))
+		return
+	}
+
+	filter := model.ExperimentFilter{
+		Statuses: parseListQuery(c.QueryArray("status")),
+		Search:   c.Query("search"),
+	}
+	if startAfter := c.Query("startAfter"); startAfter != "" {
+		at, err := parseTimestamp(startAfter)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		filter.StartAfter = at.Unix()
+	}
+	if endBefore := c.Query("endBefore"); endBefore != "" {
+		at, err := parseTimestamp(endBefore)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		filter.EndBefore = at.Unix()
+	}
+
+	result, err := r.handler.GetAllExperiments(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) getExperimentByID(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := r.handler.GetExperimentByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) getExperimentByUuid(c *gin.Context) {
+	result, err := r.handler.GetExperimentByUuid(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) rejectExperiment(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.RejectExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.RejectExperiment(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) approveExperiment(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.ApproveExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.ApproveExperiment(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) abortExperiment(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.AbortExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.AbortExperiment(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) pauseExperiment(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.PauseExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.PauseExperiment(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) resumeExperiment(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.ResumeExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.ResumeExperiment(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) updateExperimentVariants(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.UpdateExperimentVariantsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.UpdateExperimentVariants(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) deleteExperiment(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	err = r.handler.DeleteExperiment(c.Request.Context(), id, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (r *Router) setExperimentOverride(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.SetExperimentOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	result, err := r.handler.SetExperimentOverride(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) deleteExperimentOverride(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	overrideID, err := strconv.ParseUint(c.Param("overrideId"), 10, 32)
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid override ID: %s", c.Param("overrideId")))
+		return
+	}
+
+	if err := r.handler.DeleteExperimentOverride(c.Request.Context(), id, uint(overrideID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (r *Router) simulateExperiment(c *gin.Context) {
+	var req dto.SimulateExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	// The experiment may be addressed by its numeric ID or by its UUID
+	idStr := c.Param("id")
+	if id, err := strconv.ParseUint(idStr, 10, 32); err == nil {
+		req.ExperimentID = uint(id)
+	} else {
+		req.ExperimentUUID = idStr
+	}
+
+	result, err := r.handler.SimulateExperiment(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) checkExperimentConflicts(c *gin.Context) {
+	var req dto.CheckExperimentConflictsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	result, err := r.handler.CheckExperimentConflicts(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) getExperimentExposures(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter, err := parseExposureFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := r.handler.GetExperimentExposures(c.Request.Context(), id, filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) getExperimentExposuresByUuid(c *gin.Context) {
+	filter, err := parseExposureFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := r.handler.GetExperimentExposuresByUuid(c.Request.Context(), c.Param("uuid"), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseExposureFilter reads the time range of an exposures query from the from and to query parameters
+func parseExposureFilter(c *gin.Context) (model.ExposureFilter, error) {
+	var filter model.ExposureFilter
+	if from := c.Query("from"); from != "" {
+		at, err := parseTimestamp(from)
+		if err != nil {
+			return model.ExposureFilter{}, err
+		}
+		filter.From = &at
+	}
+	if to := c.Query("to"); to != "" {
+		at, err := parseTimestamp(to)
+		if err != nil {
+			return model.ExposureFilter{}, err
+		}
+		filter.To = &at
+	}
+	return filter, nil
+}
+
+// SDK handlers
+func (r *Router) getMetadataSDK(c *gin.Context) {
+	var req dto.GetMetadataSDKRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	result, err := r.handler.GetMetdataSDK(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) getAllParametersSDK(c *gin.Context) {
+
+	var req dto.GetAllParametersSDKRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	// Let SDK clients skip the download when nothing changed since their last fetch
+	etag, err := r.handler.GetParametersSDKETag(c.Request.Context(), req.Environment)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	result, err := r.handler.GetAllParametersSDK(c.Request.Context(), &req)
+	if err != nil {
 		c.Error(err)
 		return
 	}
@@ -690,10 +1523,39 @@ func (r *Router) getAllParametersSDK(c *gin.Context) {
 
 }
 
+// streamSDKChanges streams a server-sent "change" event whenever synced parameters or experiments
+// change. Events only name the changed entity; clients fetch the data through the other SDK endpoints.
+func (r *Router) streamSDKChanges(c *gin.Context) {
+	events, unsubscribe := r.handler.SubscribeSDKChanges()
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(sdkStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("ping", "")
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("change", event)
+		case <-keepAlive.C:
+			c.SSEvent("ping", "")
+		}
+		return true
+	})
+}
+
 func (r *Router) getAllExperimentsSDK(c *gin.Context) {
 	var req dto.GetAllExperimentsSDKRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
 
@@ -703,14 +1565,58 @@ func (r *Router) getAllExperimentsSDK(c *gin.Context) {
 		return
 	}
 
+	// Experiment statuses change with time as well as with edits, so the ETag is taken from the payload itself
+	respondWithContentETag(c, result)
+}
+
+// evaluateSDK evaluates parameters server-side. The single form, with parameterName, responds with the
+// result itself, the batch form with the results of every parameter name.
+func (r *Router) evaluateSDK(c *gin.Context) {
+	var req dto.EvaluateSDKRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	result, err := r.handler.EvaluateSDK(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if req.ParameterName != "" {
+		c.JSON(http.StatusOK, result.Results[0])
+		return
+	}
 	c.JSON(http.StatusOK, result)
 }
 
+// respondWithContentETag writes payload as JSON with an ETag hashed from its encoding,
+// answering 304 Not Modified when the client already holds the same payload
+func respondWithContentETag(c *gin.Context, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		c.Error(fmt.Errorf("failed to encode response: %w", err))
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := fmt.Sprintf(`"%x"`, sum[:16])
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
 // SDK event tracking handler - supports both single event and batch events
 func (r *Router) trackEvent(c *gin.Context) {
 	// Try to parse as batch first
+	// The body is bound twice, so it is kept after the first read
 	var batchReq dto.TrackBatchEventRequest
-	if err := c.ShouldBindJSON(&batchReq); err == nil && len(batchReq.Events) > 0 {
+	if err := c.ShouldBindBodyWith(&batchReq, binding.JSON); err == nil && len(batchReq.Events) > 0 {
 		// It's a batch request
 		result, err := r.handler.TrackBatchEvent(c.Request.Context(), &batchReq)
 		if err != nil {
@@ -723,8 +1629,8 @@ func (r *Router) trackEvent(c *gin.Context) {
 
 	// Try to parse as single event
 	var req dto.TrackEventRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
 
@@ -751,7 +1657,7 @@ func (r *Router) googleLogin(c *gin.Context) {
 func (r *Router) googleCallback(c *gin.Context) {
 	var req dto.GoogleCallbackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
 
@@ -761,50 +1667,185 @@ func (r *Router) googleCallback(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) refreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	result, err := r.handler.RefreshToken(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) getCurrentUser(c *gin.Context) {
+	// Extract user ID from JWT token (set by JWT middleware)
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := r.handler.GetCurrentUser(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Audit log handlers
+func (r *Router) getAuditLogs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.Error(apperror.BadRequest("invalid limit parameter"))
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.Error(apperror.BadRequest("invalid offset parameter"))
+		return
+	}
+
+	filter := model.AuditLogFilter{EntityType: model.AuditEntityType(c.Query("entityType"))}
+	if entityID := c.Query("entityId"); entityID != "" {
+		id, err := strconv.ParseUint(entityID, 10, 32)
+		if err != nil {
+			c.Error(apperror.BadRequest("invalid entityId parameter"))
+			return
+		}
+		filter.EntityID = uint(id)
+	}
+	if actorUserID := c.Query("userId"); actorUserID != "" {
+		id, err := strconv.ParseUint(actorUserID, 10, 32)
+		if err != nil {
+			c.Error(apperror.BadRequest("invalid userId parameter"))
+			return
+		}
+		filter.ActorUserID = uint(id)
+	}
+	if from := c.Query("from"); from != "" {
+		at, err := parseTimestamp(from)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		filter.From = &at
+	}
+	if to := c.Query("to"); to != "" {
+		at, err := parseTimestamp(to)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		filter.To = &at
+	}
+
+	result, err := r.handler.GetAuditLogs(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Admin handlers
+func (r *Router) exportPointInTime(c *gin.Context) {
+	at, err := parseTimestamp(c.Query("at"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	email, _ := middleware.GetUserEmailFromContext(c)
+
+	c.Header("Content-Type", "application/json")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="aurora-point-in-time-%d.json"`, at.Unix()))
+
+	if err := r.handler.ExportPointInTime(c.Request.Context(), userID, email, at, c.Writer); err != nil {
+		// Once streaming has started the status code is already sent, so the document is left truncated
+		if !c.Writer.Written() {
+			c.Error(err)
+		}
+		return
+	}
+}
+
+func (r *Router) rebuildRawValues(c *gin.Context) {
+	userID, _ := middleware.GetUserIDFromContext(c)
+	email, _ := middleware.GetUserEmailFromContext(c)
+
+	response, err := r.handler.RebuildRawValues(c.Request.Context(), userID, email)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
-func (r *Router) refreshToken(c *gin.Context) {
-	var req dto.RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
-		return
+// parseTimestamp parses an RFC3339 timestamp or unix seconds
+func parseTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, apperror.BadRequest("invalid timestamp: missing at query parameter")
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, apperror.BadRequest("invalid timestamp: %s", value)
 	}
+	return at, nil
+}
 
-	result, err := r.handler.RefreshToken(c.Request.Context(), &req)
+// Parameter Change Request handlers
+func (r *Router) createParameterChangeRequest(c *gin.Context) {
+	id, err := parseIDParam(c)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
-}
-
-func (r *Router) getCurrentUser(c *gin.Context) {
-	// Extract user ID from JWT token (set by JWT middleware)
+	// Extract user ID from JWT token
 	userID, exists := middleware.GetUserIDFromContext(c)
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	result, err := r.handler.GetCurrentUser(c.Request.Context(), userID)
-	if err != nil {
-		c.Error(err)
+	var req dto.CreateParameterChangeRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
-}
+	// Set parameter ID from URL
+	req.ParameterID = id
 
-// Parameter Change Request handlers
-func (r *Router) createParameterChangeRequest(c *gin.Context) {
-	id, err := parseIDParam(c)
+	result, err := r.handler.CreateParameterChangeRequest(c.Request.Context(), userID, &req)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
+	c.JSON(http.StatusCreated, result)
+}
+
+// createParameterCreationRequest requests a new parameter, which is only created once the request is approved
+func (r *Router) createParameterCreationRequest(c *gin.Context) {
 	// Extract user ID from JWT token
 	userID, exists := middleware.GetUserIDFromContext(c)
 	if !exists {
@@ -814,12 +1855,10 @@ func (r *Router) createParameterChangeRequest(c *gin.Context) {
 
 	var req dto.CreateParameterChangeRequestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
-
-	// Set parameter ID from URL
-	req.ParameterID = id
+	req.RequestType = model.ChangeRequestTypeCreate
 
 	result, err := r.handler.CreateParameterChangeRequest(c.Request.Context(), userID, &req)
 	if err != nil {
@@ -883,6 +1922,22 @@ func (r *Router) getParameterChangeRequests(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func (r *Router) getParameterChangeRequestDiff(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := r.handler.GetParameterChangeRequestDiff(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func (r *Router) approveParameterChangeRequest(c *gin.Context) {
 	id, err := parseIDParam(c)
 	if err != nil {
@@ -899,7 +1954,7 @@ func (r *Router) approveParameterChangeRequest(c *gin.Context) {
 
 	var req dto.ApproveParameterChangeRequestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
 
@@ -928,7 +1983,7 @@ func (r *Router) rejectParameterChangeRequest(c *gin.Context) {
 
 	var req dto.RejectParameterChangeRequestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(err)
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
 		return
 	}
 
@@ -941,6 +1996,36 @@ func (r *Router) rejectParameterChangeRequest(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func (r *Router) cancelParameterChangeRequest(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Extract user ID from JWT token
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// The body is optional since the cancellation reason is optional
+	var req dto.CancelParameterChangeRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	result, err := r.handler.CancelParameterChangeRequest(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func (r *Router) getParameterChangeRequestsByStatus(c *gin.Context) {
 	// Parse query parameters
 	status := c.Query("status")
@@ -1005,3 +2090,218 @@ func (r *Router) getParameterChangeRequestByIDWithDetails(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// API key handlers
+func (r *Router) createAPIKey(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	result, err := r.handler.CreateAPIKey(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (r *Router) getAllAPIKeys(c *gin.Context) {
+	result, err := r.handler.GetAllAPIKeys(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) revokeAPIKey(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := r.handler.RevokeAPIKey(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Environment handlers
+func (r *Router) createEnvironment(c *gin.Context) {
+	var req dto.CreateEnvironmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	result, err := r.handler.CreateEnvironment(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (r *Router) getAllEnvironments(c *gin.Context) {
+	result, err := r.handler.GetAllEnvironments(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) getEnvironmentByID(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := r.handler.GetEnvironmentByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) updateEnvironment(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req dto.UpdateEnvironmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	result, err := r.handler.UpdateEnvironment(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) deleteEnvironment(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := r.handler.DeleteEnvironment(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseEnvironmentIDParam parses the environment ID of parameter environment override routes
+func parseEnvironmentIDParam(c *gin.Context) (uint, error) {
+	environmentID, err := strconv.ParseUint(c.Param("environmentId"), 10, 32)
+	if err != nil {
+		return 0, apperror.BadRequest("invalid environment ID: %s", c.Param("environmentId"))
+	}
+	return uint(environmentID), nil
+}
+
+func (r *Router) getParameterEnvironmentOverrides(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := r.handler.GetParameterEnvironmentOverrides(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) setParameterEnvironmentOverride(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	environmentID, err := parseEnvironmentIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.SetParameterEnvironmentOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest("invalid request body: %v", err))
+		return
+	}
+
+	result, err := r.handler.SetParameterEnvironmentOverride(c.Request.Context(), id, environmentID, userID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *Router) deleteParameterEnvironmentOverride(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	environmentID, err := parseEnvironmentIDParam(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := r.handler.DeleteParameterEnvironmentOverride(c.Request.Context(), id, environmentID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,253 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"api/internal/apperror"
+	"api/internal/broadcast"
+	"api/internal/dto"
+	"api/internal/handler"
+	"api/internal/model"
+	"api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestHandleError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := New(nil, zerolog.Nop(), nil)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name:        "not found",
+			err:         apperror.NotFound("parameter with ID %d not found", 1),
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "parameter with ID 1 not found",
+		},
+		{
+			name:        "conflict",
+			err:         apperror.Conflict("parameter with name '%s' already exists", "not found"),
+			wantStatus:  http.StatusConflict,
+			wantMessage: "parameter with name 'not found' already exists",
+		},
+		{
+			name:        "bad request",
+			err:         apperror.BadRequest("enum options are required for enum data type"),
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "enum options are required for enum data type",
+		},
+		{
+			name:        "unauthorized",
+			err:         apperror.Unauthorized("failed to refresh token"),
+			wantStatus:  http.StatusUnauthorized,
+			wantMessage: "failed to refresh token",
+		},
+		{
+			name:        "forbidden",
+			err:         apperror.Forbidden("email domain is not allowed for this organization"),
+			wantStatus:  http.StatusForbidden,
+			wantMessage: "email domain is not allowed for this organization",
+		},
+		{
+			name:        "too many requests",
+			err:         apperror.TooManyRequests("too many requests"),
+			wantStatus:  http.StatusTooManyRequests,
+			wantMessage: "too many requests",
+		},
+		{
+			name:        "wrapped application error",
+			err:         fmt.Errorf("failed to approve: %w", apperror.Conflict("change request is not pending")),
+			wantStatus:  http.StatusConflict,
+			wantMessage: "failed to approve: change request is not pending",
+		},
+		{
+			name:        "wrapped record not found",
+			err:         fmt.Errorf("failed to get experiment: %w", gorm.ErrRecordNotFound),
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "failed to get experiment: record not found",
+		},
+		{
+			name:        "unknown error",
+			err:         errors.New("pq: invalid input syntax for type bigint"),
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			r.handleError(c, tt.err)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			var body dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			require.Equal(t, tt.wantMessage, body.Message)
+		})
+	}
+}
+
+func TestHandleErrorExperimentConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := New(nil, zerolog.Nop(), nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+
+	err := &apperror.ExperimentConflictError{Conflicts: []apperror.ExperimentConflict{
+		{ID: 7, Name: "checkout", Status: "running", SegmentID: 2, StartDate: 100, EndDate: 300, OverlapStart: 200, OverlapEnd: 300},
+	}}
+	r.handleError(c, fmt.Errorf("failed to approve: %w", err))
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	var body dto.ExperimentConflictResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, "Conflict", body.Error)
+	require.Equal(t, []dto.ConflictingExperimentResponse{
+		{ID: 7, Name: "checkout", Status: "running", SegmentID: 2, StartDate: 100, EndDate: 300, OverlapStart: 200, OverlapEnd: 300},
+	}, body.Conflicts)
+	require.True(t, errors.Is(err, apperror.ErrConflict))
+}
+
+func TestHandleErrorConditionValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := New(nil, zerolog.Nop(), nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+
+	r.handleError(c, &apperror.ConditionValidationError{Problems: []apperror.ConditionProblem{
+		{Rule: "adults", AttributeID: 1, Attribute: "age", Operator: "contains", Value: "1", Message: "operator contains is not supported for number attributes"},
+	}})
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var body dto.ConditionValidationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, "Bad Request", body.Error)
+	require.Equal(t, []dto.InvalidConditionResponse{
+		{Rule: "adults", AttributeID: 1, Attribute: "age", Operator: "contains", Value: "1", Message: "operator contains is not supported for number attributes"},
+	}, body.Conditions)
+}
+
+func TestRespondWithContentETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	payload := dto.GetAllExperimentsSDKResponse{}
+
+	respond := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/sdk/experiments", nil)
+		if ifNoneMatch != "" {
+			c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		respondWithContentETag(c, payload)
+		return w
+	}
+
+	first := respond("")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	second := respond(etag)
+	require.Equal(t, http.StatusNotModified, second.Code)
+	require.Equal(t, etag, second.Header().Get("ETag"))
+	require.Empty(t, second.Body.Bytes())
+}
+
+func TestParseListQuery(t *testing.T) {
+	require.Equal(t, []string{"running", "schedule", "draft"}, parseListQuery([]string{"running, schedule", "draft", ""}))
+	require.Nil(t, parseListQuery(nil))
+}
+
+func TestStreamSDKChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub := broadcast.NewHub()
+	r := New(handler.New(nil, nil, hub), zerolog.Nop(), nil)
+	engine := gin.New()
+	engine.GET("/stream", r.streamSDKChanges)
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/stream")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(response.Body)
+	readEvent := func() []string {
+		var event []string
+		for lines.Scan() && lines.Text() != "" {
+			event = append(event, lines.Text())
+		}
+		return event
+	}
+
+	// The first ping is sent once the stream is subscribed
+	require.Equal(t, []string{"event:ping", "data:"}, readEvent())
+	require.NoError(t, hub.Publish(context.Background(), dto.SDKChangeEvent{Entity: dto.SDKChangeEntityParameter, ID: 3}))
+	require.Equal(t, []string{"event:change", `data:{"entity":"parameter","id":3}`}, readEvent())
+}
+
+// auditLogService records the filter and page the audit logs are listed with
+type auditLogService struct {
+	service.Service
+	filter        model.AuditLogFilter
+	limit, offset int
+}
+
+func (s *auditLogService) GetAuditLogs(_ context.Context, filter model.AuditLogFilter, limit, offset int) ([]*model.AuditLog, int64, error) {
+	s.filter, s.limit, s.offset = filter, limit, offset
+	return []*model.AuditLog{{ID: 1, ActorUserID: 3, EntityType: model.AuditEntitySegment, EntityID: 6, Action: model.AuditActionUpdate}}, 21, nil
+}
+
+func TestGetAuditLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &auditLogService{}
+	r := New(handler.New(svc, nil, nil), zerolog.Nop(), nil)
+	engine := gin.New()
+	engine.Use(r.errorHandlingMiddleware())
+	engine.GET("/audit-logs", r.getAuditLogs)
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/audit-logs?entityType=segment&entityId=6&userId=3&from=1700000000&to=2024-01-01T00:00:00Z&limit=10&offset=20", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	from, to := time.Unix(1700000000, 0), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, model.AuditLogFilter{EntityType: model.AuditEntitySegment, EntityID: 6, ActorUserID: 3, From: &from, To: &to}, svc.filter)
+	require.Equal(t, 10, svc.limit)
+	require.Equal(t, 20, svc.offset)
+
+	var page dto.AuditLogPageResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Equal(t, int64(21), page.Total)
+	require.Equal(t, 10, page.Limit)
+	require.Equal(t, 20, page.Offset)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, uint(6), page.Items[0].EntityID)
+
+	for _, query := range []string{"userId=me", "entityId=-1", "from=yesterday", "limit=-1", "offset=x"} {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/audit-logs?"+query, nil))
+		require.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
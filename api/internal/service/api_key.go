@@ -0,0 +1,161 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/dto"
+	"api/internal/model"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyPrefix marks Aurora API keys so they are easy to recognise in configuration and secret scanners
+	apiKeyPrefix = "aurora_"
+	// apiKeyDisplayPrefixLength is the number of leading key characters stored to tell keys apart
+	apiKeyDisplayPrefixLength = len(apiKeyPrefix) + 6
+	// apiKeyCacheTTL bounds how long a validated key is trusted without reading it again, which is
+	// also how long a key revoked on another instance keeps working
+	apiKeyCacheTTL = 30 * time.Second
+)
+
+// apiKeyCache keeps recently read API keys by hash so SDK polls do not read the database on every request.
+// The zero value is ready to use.
+type apiKeyCache struct {
+	mu      sync.Mutex
+	entries map[string]apiKeyCacheEntry
+}
+
+type apiKeyCacheEntry struct {
+	apiKey   *model.APIKey
+	cachedAt time.Time
+}
+
+func (c *apiKeyCache) get(keyHash string, now time.Time) (*model.APIKey, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[keyHash]
+	if !ok || now.Sub(entry.cachedAt) >= apiKeyCacheTTL {
+		return nil, false
+	}
+	return entry.apiKey, true
+}
+
+func (c *apiKeyCache) put(keyHash string, apiKey *model.APIKey, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]apiKeyCacheEntry)
+	}
+	c.entries[keyHash] = apiKeyCacheEntry{apiKey: apiKey, cachedAt: now}
+}
+
+// evict drops the cached entry of a key so a revocation takes effect immediately on this instance
+func (c *apiKeyCache) evict(id uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for keyHash, entry := range c.entries {
+		if entry.apiKey.ID == id {
+			delete(c.entries, keyHash)
+		}
+	}
+}
+
+// hashAPIKey returns the hex encoded sha256 hash under which a key is stored
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateAPIKey returns a new random API key
+func generateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreateAPIKey creates an API key and returns it with its plaintext, which is not stored
+func (s *service) CreateAPIKey(ctx context.Context, userID uint, req *dto.CreateAPIKeyRequest) (*model.APIKey, string, error) {
+	if err := req.Validate(time.Now()); err != nil {
+		return nil, "", apperror.BadRequest("invalid api key: %v", err)
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey := &model.APIKey{
+		Name:            req.Name,
+		Prefix:          key[:apiKeyDisplayPrefixLength],
+		KeyHash:         hashAPIKey(key),
+		CreatedByUserID: userID,
+		ExpiresAt:       req.ExpiresAt,
+	}
+	if err := s.repo.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, "", err
+	}
+	return apiKey, key, nil
+}
+
+// GetAllAPIKeys retrieves all API keys, including revoked and expired ones
+func (s *service) GetAllAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	return s.repo.GetAllAPIKeys(ctx)
+}
+
+// RevokeAPIKey revokes an API key. Revoking a key twice keeps its first revocation time.
+func (s *service) RevokeAPIKey(ctx context.Context, id uint) (*model.APIKey, error) {
+	apiKey, err := s.repo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("api key with ID %d not found", id)
+		}
+		return nil, err
+	}
+
+	if apiKey.RevokedAt == nil {
+		now := time.Now()
+		if err := s.repo.RevokeAPIKey(ctx, id, now); err != nil {
+			return nil, err
+		}
+		apiKey.RevokedAt = &now
+	}
+	s.apiKeys.evict(id)
+	return apiKey, nil
+}
+
+// ValidateAPIKey checks that a key exists and is neither revoked nor expired
+func (s *service) ValidateAPIKey(ctx context.Context, key string) error {
+	if key == "" {
+		return apperror.Unauthorized("missing api key")
+	}
+
+	now := time.Now()
+	keyHash := hashAPIKey(key)
+	apiKey, ok := s.apiKeys.get(keyHash, now)
+	if !ok {
+		var err error
+		apiKey, err = s.repo.GetAPIKeyByHash(ctx, keyHash)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperror.Unauthorized("invalid api key")
+			}
+			return err
+		}
+		s.apiKeys.put(keyHash, apiKey, now)
+	}
+
+	if !apiKey.Active(now) {
+		return apperror.Unauthorized("invalid api key")
+	}
+	return nil
+}
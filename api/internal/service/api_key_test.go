@@ -0,0 +1,65 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// apiKeyRepository serves API keys from memory and counts the lookups by hash
+type apiKeyRepository struct {
+	repository.Repository
+	keys    map[string]*model.APIKey
+	lookups int
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(_ context.Context, keyHash string) (*model.APIKey, error) {
+	r.lookups++
+	apiKey, ok := r.keys[keyHash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *apiKey
+	return &copied, nil
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	repo := &apiKeyRepository{keys: map[string]*model.APIKey{
+		hashAPIKey("aurora_active"):  {ID: 1},
+		hashAPIKey("aurora_revoked"): {ID: 2, RevokedAt: &past},
+		hashAPIKey("aurora_expired"): {ID: 3, ExpiresAt: &past},
+	}}
+	s := &service{repo: repo}
+	ctx := context.Background()
+
+	require.NoError(t, s.ValidateAPIKey(ctx, "aurora_active"))
+	require.NoError(t, s.ValidateAPIKey(ctx, "aurora_active"))
+	require.Equal(t, 1, repo.lookups, "a validated key is served from the cache")
+
+	for _, key := range []string{"", "aurora_unknown", "aurora_revoked", "aurora_expired"} {
+		require.ErrorIs(t, s.ValidateAPIKey(ctx, key), apperror.ErrUnauthorized, key)
+	}
+}
+
+func TestAPIKeyCacheEvictsRevokedKeys(t *testing.T) {
+	s := &service{}
+	now := time.Now()
+	s.apiKeys.put(hashAPIKey("aurora_active"), &model.APIKey{ID: 1}, now)
+	s.apiKeys.put(hashAPIKey("aurora_other"), &model.APIKey{ID: 2}, now)
+
+	s.apiKeys.evict(1)
+
+	_, ok := s.apiKeys.get(hashAPIKey("aurora_active"), now)
+	require.False(t, ok)
+	_, ok = s.apiKeys.get(hashAPIKey("aurora_other"), now)
+	require.True(t, ok)
+	_, ok = s.apiKeys.get(hashAPIKey("aurora_other"), now.Add(apiKeyCacheTTL))
+	require.False(t, ok, "entries expire after the TTL")
+}
@@ -1,28 +1,38 @@
 package service
 
 import (
+	"api/internal/apperror"
+	"api/internal/constant"
 	"api/internal/dto"
 	"api/internal/model"
+	"api/internal/repository"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sdk/types"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 )
 
-func (s *service) CreateAttribute(ctx context.Context, req *dto.CreateAttributeRequest) (*model.Attribute, error) {
+func (s *service) CreateAttribute(ctx context.Context, userID uint, req *dto.CreateAttributeRequest) (*model.Attribute, error) {
 	// Check if attribute with same name already exists
 	existing, err := s.repo.GetAttributeByName(ctx, req.Name)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 	if existing != nil {
-		return nil, errors.New("attribute with name '" + req.Name + "' already exists")
+		return nil, apperror.Conflict("attribute with name '%s' already exists", req.Name)
 	}
 
 	// Validate enum options for enum data type
 	if req.DataType == model.DataTypeEnum {
 		if len(req.EnumOptions) == 0 {
-			return nil, errors.New("enum options are required for enum data type")
+			return nil, apperror.BadRequest("enum options are required for enum data type")
 		}
 	}
 
@@ -46,7 +56,17 @@ func (s *service) CreateAttribute(ctx context.Context, req *dto.CreateAttributeR
 		UsageCount:    0,
 	}
 
-	if err := s.repo.CreateAttribute(ctx, attribute); err != nil {
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.CreateAttribute(ctx, attribute); err != nil {
+			return err
+		}
+		after, err := attributeSnapshot(attribute)
+		if err != nil {
+			return err
+		}
+		return s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityAttribute, attribute.ID, model.AuditActionCreate, nil, after)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -58,7 +78,7 @@ func (s *service) GetAttributeByID(ctx context.Context, id uint) (*model.Attribu
 	attribute, err := s.repo.GetAttributeByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("attribute with ID " + string(rune(id)) + " not found")
+			return nil, apperror.NotFound("attribute with ID %d not found", id)
 		}
 		return nil, err
 	}
@@ -70,17 +90,27 @@ func (s *service) GetAttributeByName(ctx context.Context, name string) (*model.A
 	return s.repo.GetAttributeByName(ctx, name)
 }
 
-// GetAllAttributes retrieves all attributes
-func (s *service) GetAllAttributes(ctx context.Context) ([]*model.Attribute, error) {
-	return s.repo.GetAllAttributes(ctx, 0, 0) // No pagination for findAll equivalent
+// ListAttributes retrieves a page of attributes filtered by data type, name and usage, with the total count
+func (s *service) ListAttributes(ctx context.Context, filter model.AttributeFilter) ([]*model.Attribute, int64, error) {
+	if filter.DataType != "" && !slices.Contains(model.DataTypes, filter.DataType) {
+		return nil, 0, apperror.BadRequest("invalid data type %q", filter.DataType)
+	}
+	if filter.Sort != "" && !slices.Contains(model.AttributeSorts, filter.Sort) {
+		return nil, 0, apperror.BadRequest("invalid sort %q: must be one of [%s]", filter.Sort, strings.Join(model.AttributeSorts, ", "))
+	}
+	filter.Search = strings.TrimSpace(filter.Search)
+	return s.repo.ListAttributes(ctx, filter)
 }
 
-// UpdateAttribute updates an existing attribute
-func (s *service) UpdateAttribute(ctx context.Context, id uint, req *dto.UpdateAttributeRequest) (*model.Attribute, error) {
+// UpdateAttribute updates an existing attribute. When its data type changes or enum options are removed,
+// the segment and parameter rule conditions referencing it must still be valid under the new definition:
+// the update is rejected listing the offending rules, or with req.Force the invalid conditions are deleted.
+func (s *service) UpdateAttribute(ctx context.Context, id uint, userID uint, req *dto.UpdateAttributeRequest) (*model.Attribute, error) {
 	attribute, err := s.GetAttributeByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	previous := *attribute
 
 	// Check if name is being updated and if it conflicts
 	if req.Name != nil && *req.Name != attribute.Name {
@@ -89,7 +119,7 @@ func (s *service) UpdateAttribute(ctx context.Context, id uint, req *dto.UpdateA
 			return nil, err
 		}
 		if existing != nil {
-			return nil, errors.New("attribute with name '" + *req.Name + "' already exists")
+			return nil, apperror.Conflict("attribute with name '%s' already exists", *req.Name)
 		}
 		attribute.Name = *req.Name
 	}
@@ -107,7 +137,7 @@ func (s *service) UpdateAttribute(ctx context.Context, id uint, req *dto.UpdateA
 		// Validate enum options if data type is being changed to enum
 		if *req.DataType == model.DataTypeEnum {
 			if len(req.EnumOptions) == 0 {
-				return nil, errors.New("enum options are required for enum data type")
+				return nil, apperror.BadRequest("enum options are required for enum data type")
 			}
 		}
 
@@ -124,15 +154,168 @@ func (s *service) UpdateAttribute(ctx context.Context, id uint, req *dto.UpdateA
 		attribute.EnumOptions = req.EnumOptions
 	}
 
-	if err := s.repo.UpdateAttribute(ctx, attribute); err != nil {
+	// Conditions embed the attribute's name, data type and enum options in the raw_value served to SDKs
+	before, err := attributeSnapshot(&previous)
+	if err != nil {
 		return nil, err
 	}
+	if attribute.Name == previous.Name && attribute.DataType == previous.DataType && slices.Equal(attribute.EnumOptions, previous.EnumOptions) {
+		err := s.inTransaction(ctx, func(txRepo repository.Repository) error {
+			if err := txRepo.UpdateAttribute(ctx, attribute); err != nil {
+				return err
+			}
+			return s.recordAttributeUpdate(ctx, txRepo, userID, before, attribute)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return attribute, nil
+	}
 
+	revalidate := attribute.DataType != previous.DataType || slices.ContainsFunc(previous.EnumOptions, func(option string) bool {
+		return !slices.Contains(attribute.EnumOptions, option)
+	})
+	if err := s.updateAttributeDefinition(ctx, userID, before, attribute, revalidate, req.Force); err != nil {
+		return nil, err
+	}
 	return attribute, nil
 }
 
+// recordAttributeUpdate writes the audit log entry of an attribute update through txRepo, from the snapshot
+// taken before the update
+func (s *service) recordAttributeUpdate(ctx context.Context, txRepo repository.Repository, userID uint, before json.RawMessage, attribute *model.Attribute) error {
+	after, err := attributeSnapshot(attribute)
+	if err != nil {
+		return err
+	}
+	return s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityAttribute, attribute.ID, model.AuditActionUpdate, before, after)
+}
+
+// updateAttributeDefinition saves an attribute whose definition changed, refreshing the raw_value of the parameters
+// and experiments that embed its conditions and enqueuing their sync jobs in the same transaction as its audit entry.
+// With revalidate, conditions that no longer hold under the new definition reject the update, or are deleted when
+// force is set.
+func (s *service) updateAttributeDefinition(ctx context.Context, userID uint, before json.RawMessage, attribute *model.Attribute, revalidate bool, force bool) error {
+	logger := log.Ctx(ctx).With().Str("service", "update-attribute").Uint("id", attribute.ID).Logger()
+
+	segmentConditions, err := s.repo.GetSegmentRuleConditionsByAttributeID(ctx, attribute.ID)
+	if err != nil {
+		return err
+	}
+	parameterConditions, err := s.repo.GetParameterRuleConditionsByAttributeID(ctx, attribute.ID)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	var invalidSegmentConditionIDs, invalidParameterConditionIDs []uint
+	var segmentIDs, parameterIDs []uint
+	for _, condition := range segmentConditions {
+		if condition.Rule == nil {
+			continue
+		}
+		if !slices.Contains(segmentIDs, condition.Rule.SegmentID) {
+			segmentIDs = append(segmentIDs, condition.Rule.SegmentID)
+		}
+		if !revalidate {
+			continue
+		}
+		if err := validateConditionForAttribute(attribute, condition.Operator, condition.Value); err != nil {
+			var segmentName string
+			if condition.Rule.Segment != nil {
+				segmentName = condition.Rule.Segment.Name
+			}
+			problems = append(problems, fmt.Sprintf("segment '%s' rule '%s': %v", segmentName, condition.Rule.Name, err))
+			invalidSegmentConditionIDs = append(invalidSegmentConditionIDs, condition.ID)
+		}
+	}
+	for _, condition := range parameterConditions {
+		if condition.Rule == nil {
+			continue
+		}
+		if !slices.Contains(parameterIDs, condition.Rule.ParameterID) {
+			parameterIDs = append(parameterIDs, condition.Rule.ParameterID)
+		}
+		if !revalidate {
+			continue
+		}
+		if err := validateConditionForAttribute(attribute, condition.Operator, condition.Value); err != nil {
+			var parameterName string
+			if condition.Rule.Parameter != nil {
+				parameterName = condition.Rule.Parameter.Name
+			}
+			problems = append(problems, fmt.Sprintf("parameter '%s' rule '%s': %v", parameterName, condition.Rule.Name, err))
+			invalidParameterConditionIDs = append(invalidParameterConditionIDs, condition.ID)
+		}
+	}
+
+	if len(problems) > 0 && !force {
+		return apperror.Conflict("attribute '%s' update invalidates %d condition(s): %s", attribute.Name, len(problems), strings.Join(problems, "; "))
+	}
+
+	// Parameters and experiments targeting an affected segment embed the segment in their raw_value
+	segmentParameterIDs, err := s.repo.GetParameterIDsBySegmentIDs(ctx, segmentIDs)
+	if err != nil {
+		return err
+	}
+	for _, parameterID := range segmentParameterIDs {
+		if !slices.Contains(parameterIDs, parameterID) {
+			parameterIDs = append(parameterIDs, parameterID)
+		}
+	}
+	experimentIDs, err := s.repo.GetExperimentIDsBySegmentIDs(ctx, segmentIDs)
+	if err != nil {
+		return err
+	}
+
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.UpdateAttribute(ctx, attribute); err != nil {
+			return err
+		}
+		if err := s.recordAttributeUpdate(ctx, txRepo, userID, before, attribute); err != nil {
+			return err
+		}
+		if err := txRepo.DeleteSegmentRuleConditionsByIDs(ctx, invalidSegmentConditionIDs); err != nil {
+			return fmt.Errorf("failed to delete segment rule conditions: %w", err)
+		}
+		if err := txRepo.DeleteParameterRuleConditionsByIDs(ctx, invalidParameterConditionIDs); err != nil {
+			return fmt.Errorf("failed to delete parameter rule conditions: %w", err)
+		}
+
+		for _, parameterID := range parameterIDs {
+			if err := txRepo.UpdateParameterRawValue(ctx, parameterID); err != nil {
+				return fmt.Errorf("failed to update parameter raw value: %w", err)
+			}
+			if err := s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{ParameterID: int(parameterID)}); err != nil {
+				return err
+			}
+		}
+		for _, experimentID := range experimentIDs {
+			if err := txRepo.UpdateExperimentRawValue(ctx, uint(experimentID)); err != nil {
+				return fmt.Errorf("failed to update experiment raw value: %w", err)
+			}
+		}
+		if len(experimentIDs) > 0 {
+			return s.enqueueTx(ctx, txRepo, dto.SyncExperimentArgs{})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		logger.Warn().
+			Strs("conditions", problems).
+			Interface("segmentConditionIds", invalidSegmentConditionIDs).
+			Interface("parameterConditionIds", invalidParameterConditionIDs).
+			Msg("Deleted conditions invalidated by attribute update")
+	}
+	return nil
+}
+
 // DeleteAttribute deletes an attribute
-func (s *service) DeleteAttribute(ctx context.Context, id uint) error {
+func (s *service) DeleteAttribute(ctx context.Context, id uint, userID uint) error {
 	attribute, err := s.GetAttributeByID(ctx, id)
 	if err != nil {
 		return err
@@ -140,10 +323,19 @@ func (s *service) DeleteAttribute(ctx context.Context, id uint) error {
 
 	// Check if attribute is being used in experiments
 	if attribute.UsageCount > 0 {
-		return errors.New("cannot delete attribute '" + attribute.Name + "' as it is being used in " + string(rune(attribute.UsageCount)) + " experiment(s)")
+		return apperror.Conflict("cannot delete attribute '%s' as it is being used in %d experiment(s)", attribute.Name, attribute.UsageCount)
 	}
 
-	return s.repo.DeleteAttribute(ctx, id)
+	before, err := attributeSnapshot(attribute)
+	if err != nil {
+		return err
+	}
+	return s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.DeleteAttribute(ctx, id); err != nil {
+			return err
+		}
+		return s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityAttribute, id, model.AuditActionDelete, before, nil)
+	})
 }
 
 // IncrementAttributeUsageCount increments the usage count for an attribute
@@ -178,3 +370,265 @@ func (s *service) DecrementAttributeUsageCount(ctx context.Context, id uint) err
 
 	return s.repo.DecrementAttributeUsageCount(ctx, id)
 }
+
+// MergeAttribute rewrites every segment and parameter rule condition referencing the
+// source attribute to the target attribute and marks the source as an alias of the target.
+// Only draft experiments have their raw_value rewritten, so running and finished experiments keep
+// the configuration they were started with. When dryRun is set, only the report of what would
+// change is returned.
+func (s *service) MergeAttribute(ctx context.Context, sourceID uint, targetID uint, userID uint, dryRun bool) (*dto.MergeAttributeResponse, error) {
+	logger := log.Ctx(ctx).With().Str("service", "merge-attribute").Uint("sourceId", sourceID).Uint("targetId", targetID).Logger()
+
+	if sourceID == targetID {
+		return nil, apperror.BadRequest("invalid merge: source and target attribute are the same")
+	}
+
+	source, err := s.GetAttributeByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.GetAttributeByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.AliasOfID != nil {
+		return nil, apperror.BadRequest("invalid merge: target attribute '%s' is itself an alias", target.Name)
+	}
+	if source.DataType != target.DataType {
+		return nil, apperror.BadRequest("invalid merge: attribute '%s' has data type %s but target '%s' has data type %s", source.Name, source.DataType, target.Name, target.DataType)
+	}
+
+	segmentConditions, err := s.repo.GetSegmentRuleConditionsByAttributeID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	parameterConditions, err := s.repo.GetParameterRuleConditionsByAttributeID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.MergeAttributeResponse{
+		SourceAttributeID:     sourceID,
+		TargetAttributeID:     targetID,
+		DryRun:                dryRun,
+		SegmentConditionIDs:   []uint{},
+		ParameterConditionIDs: []uint{},
+		AffectedSegmentIDs:    []uint{},
+		AffectedParameterIDs:  []uint{},
+		AffectedExperimentIDs: []int{},
+	}
+
+	segmentIDSet := make(map[uint]bool)
+	for _, condition := range segmentConditions {
+		if err := validateMergedConditionValue(target, condition.Value); err != nil {
+			return nil, apperror.BadRequest("invalid merge: segment rule condition %d: %v", condition.ID, err)
+		}
+		report.SegmentConditionIDs = append(report.SegmentConditionIDs, condition.ID)
+		if condition.Rule != nil && !segmentIDSet[condition.Rule.SegmentID] {
+			segmentIDSet[condition.Rule.SegmentID] = true
+			report.AffectedSegmentIDs = append(report.AffectedSegmentIDs, condition.Rule.SegmentID)
+		}
+	}
+
+	parameterIDSet := make(map[uint]bool)
+	for _, condition := range parameterConditions {
+		if err := validateMergedConditionValue(target, condition.Value); err != nil {
+			return nil, apperror.BadRequest("invalid merge: parameter rule condition %d: %v", condition.ID, err)
+		}
+		report.ParameterConditionIDs = append(report.ParameterConditionIDs, condition.ID)
+		if condition.Rule != nil && !parameterIDSet[condition.Rule.ParameterID] {
+			parameterIDSet[condition.Rule.ParameterID] = true
+			report.AffectedParameterIDs = append(report.AffectedParameterIDs, condition.Rule.ParameterID)
+		}
+	}
+
+	// Parameters and experiments targeting an affected segment embed the segment in their raw_value
+	segmentParameterIDs, err := s.repo.GetParameterIDsBySegmentIDs(ctx, report.AffectedSegmentIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, parameterID := range segmentParameterIDs {
+		if !parameterIDSet[parameterID] {
+			parameterIDSet[parameterID] = true
+			report.AffectedParameterIDs = append(report.AffectedParameterIDs, parameterID)
+		}
+	}
+	experimentIDs, err := s.repo.GetExperimentIDsBySegmentIDsAndStatus(ctx, report.AffectedSegmentIDs, constant.ExperimentStatusDraft)
+	if err != nil {
+		return nil, err
+	}
+	report.AffectedExperimentIDs = append(report.AffectedExperimentIDs, experimentIDs...)
+
+	if dryRun {
+		return report, nil
+	}
+
+	before, err := attributeSnapshot(source)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rewrites, the raw_value refreshes, the audit entry and the sync jobs commit or roll back together
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.UpdateSegmentRuleConditionsAttribute(ctx, report.SegmentConditionIDs, targetID); err != nil {
+			return fmt.Errorf("failed to rewrite segment rule conditions: %w", err)
+		}
+		if err := txRepo.UpdateParameterRuleConditionsAttribute(ctx, report.ParameterConditionIDs, targetID); err != nil {
+			return fmt.Errorf("failed to rewrite parameter rule conditions: %w", err)
+		}
+
+		target.UsageCount += source.UsageCount
+		if err := txRepo.UpdateAttribute(ctx, target); err != nil {
+			return fmt.Errorf("failed to update target attribute: %w", err)
+		}
+
+		source.UsageCount = 0
+		source.AliasOfID = &target.ID
+		if err := txRepo.UpdateAttribute(ctx, source); err != nil {
+			return fmt.Errorf("failed to update source attribute: %w", err)
+		}
+		// SDK aliases of the source now resolve to the target directly
+		if err := txRepo.UpdateAttributeAliases(ctx, sourceID, targetID); err != nil {
+			return fmt.Errorf("failed to re-point attribute aliases: %w", err)
+		}
+
+		after, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to marshal merge report: %w", err)
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityAttribute, sourceID, model.AuditActionMerge, before, after); err != nil {
+			return err
+		}
+
+		// Refresh raw_value for everything that embeds the rewritten conditions
+		for _, parameterID := range report.AffectedParameterIDs {
+			if err := txRepo.UpdateParameterRawValue(ctx, parameterID); err != nil {
+				logger.Error().Err(err).Uint("parameterId", parameterID).Msg("Failed to update parameter raw_value")
+			}
+			if err := s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{ParameterID: int(parameterID)}); err != nil {
+				return err
+			}
+		}
+		for _, experimentID := range report.AffectedExperimentIDs {
+			if err := txRepo.UpdateExperimentRawValue(ctx, uint(experimentID)); err != nil {
+				logger.Error().Err(err).Int("experimentId", experimentID).Msg("Failed to update experiment raw_value")
+			}
+		}
+		if len(report.AffectedExperimentIDs) > 0 {
+			return s.enqueueTx(ctx, txRepo, dto.SyncExperimentArgs{})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info().
+		Str("source", source.Name).
+		Str("target", target.Name).
+		Interface("segmentConditionIds", report.SegmentConditionIDs).
+		Interface("parameterConditionIds", report.ParameterConditionIDs).
+		Msg("Attribute merged")
+
+	return report, nil
+}
+
+// validateConditionForAttribute checks that a stored condition can still match under the attribute's definition,
+// following how the SDK engine compares values of each data type
+func validateConditionForAttribute(attribute *model.Attribute, operator model.ConditionOperator, value string) error {
+	// Presence checks apply to attributes of every data type
+	if operator.ChecksPresence() {
+		return nil
+	}
+	switch attribute.DataType {
+	case model.DataTypeNumber:
+		switch operator {
+		case model.ConditionOperatorBetween, model.ConditionOperatorNotBetween:
+			_, _, err := model.ParseBetweenBounds(value)
+			return err
+		case model.ConditionOperatorIn, model.ConditionOperatorNotIn:
+			for _, v := range strings.Split(value, ",") {
+				if _, err := strconv.ParseFloat(v, 64); err != nil {
+					return fmt.Errorf("value '%s' is not a number", v)
+				}
+			}
+			return nil
+		case model.ConditionOperatorEquals, model.ConditionOperatorNotEquals,
+			model.ConditionOperatorGreaterThan, model.ConditionOperatorLessThan,
+			model.ConditionOperatorGreaterThanOrEqual, model.ConditionOperatorLessThanOrEqual:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("value '%s' is not a number", value)
+			}
+			return nil
+		}
+	case model.DataTypeBoolean:
+		if operator != model.ConditionOperatorEquals && operator != model.ConditionOperatorNotEquals {
+			break
+		}
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("value '%s' is not a boolean", value)
+		}
+		return nil
+	case model.DataTypeEnum:
+		// The engine matches enum values by membership in the condition's options, whatever the operator
+		if operator != model.ConditionOperatorEquals && operator != model.ConditionOperatorIn {
+			break
+		}
+		return validateMergedConditionValue(attribute, value)
+	case model.DataTypeDate:
+		switch operator {
+		case model.ConditionOperatorBetween, model.ConditionOperatorNotBetween:
+			bounds := strings.Split(value, ",")
+			if len(bounds) != 2 {
+				return fmt.Errorf("invalid between bounds %q: expected from,to", value)
+			}
+			from, err := types.ParseDate(strings.TrimSpace(bounds[0]))
+			if err != nil {
+				return fmt.Errorf("value '%s' is not an RFC3339 date or unix epoch", bounds[0])
+			}
+			to, err := types.ParseDate(strings.TrimSpace(bounds[1]))
+			if err != nil {
+				return fmt.Errorf("value '%s' is not an RFC3339 date or unix epoch", bounds[1])
+			}
+			if from.After(to) {
+				return fmt.Errorf("invalid between bounds %q: from is after to", value)
+			}
+			return nil
+		case model.ConditionOperatorEquals, model.ConditionOperatorNotEquals,
+			model.ConditionOperatorGreaterThan, model.ConditionOperatorLessThan,
+			model.ConditionOperatorGreaterThanOrEqual, model.ConditionOperatorLessThanOrEqual:
+			if _, err := types.ParseDate(value); err != nil {
+				return fmt.Errorf("value '%s' is not an RFC3339 date or unix epoch", value)
+			}
+			return nil
+		}
+	case model.DataTypeString:
+		switch operator {
+		case model.ConditionOperatorEquals, model.ConditionOperatorNotEquals,
+			model.ConditionOperatorContains, model.ConditionOperatorNotContains,
+			model.ConditionOperatorIn, model.ConditionOperatorNotIn,
+			model.ConditionOperatorMatchesRegex, model.ConditionOperatorNotMatchesRegex:
+			return nil
+		}
+		if operator.ComparesSemver() {
+			_, err := types.ParseSemver(value)
+			return err
+		}
+	}
+	return fmt.Errorf("operator %s is not supported for %s attributes", operator, attribute.DataType)
+}
+
+// validateMergedConditionValue checks that a condition value remains valid for the target attribute
+func validateMergedConditionValue(target *model.Attribute, value string) error {
+	if target.DataType != model.DataTypeEnum {
+		return nil
+	}
+
+	for _, v := range strings.Split(value, ",") {
+		if !slices.Contains(target.EnumOptions, strings.TrimSpace(v)) {
+			return fmt.Errorf("value '%s' is not an option of attribute '%s'", strings.TrimSpace(v), target.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/constant"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestValidateConditionForAttribute(t *testing.T) {
+	number := &model.Attribute{Name: "age", DataType: model.DataTypeNumber}
+	boolean := &model.Attribute{Name: "beta", DataType: model.DataTypeBoolean}
+	enum := &model.Attribute{Name: "country", DataType: model.DataTypeEnum, EnumOptions: []string{"VN", "US"}}
+	str := &model.Attribute{Name: "app_version", DataType: model.DataTypeString}
+	date := &model.Attribute{Name: "signed_up_at", DataType: model.DataTypeDate}
+
+	tests := []struct {
+		name      string
+		attribute *model.Attribute
+		operator  model.ConditionOperator
+		value     string
+		wantErr   bool
+	}{
+		{"number comparison", number, model.ConditionOperatorGreaterThan, "18", false},
+		{"number from string value", number, model.ConditionOperatorEquals, "adult", true},
+		{"number in list", number, model.ConditionOperatorIn, "1,2,3", false},
+		{"number between", number, model.ConditionOperatorBetween, "18,65", false},
+		{"number with string operator", number, model.ConditionOperatorContains, "1", true},
+		{"boolean equals", boolean, model.ConditionOperatorEquals, "true", false},
+		{"boolean from string value", boolean, model.ConditionOperatorEquals, "yes", true},
+		{"enum kept options", enum, model.ConditionOperatorIn, "VN, US", false},
+		{"enum pruned option", enum, model.ConditionOperatorIn, "VN,TH", true},
+		{"enum equals", enum, model.ConditionOperatorEquals, "VN", false},
+		{"enum with negated operator", enum, model.ConditionOperatorNotEquals, "VN", true},
+		{"string semver", str, model.ConditionOperatorSemverGte, "2.15.0", false},
+		{"string semver from invalid version", str, model.ConditionOperatorSemverGte, "latest", true},
+		{"number semver", number, model.ConditionOperatorSemverLt, "2.15.0", true},
+		{"date after", date, model.ConditionOperatorGreaterThan, "2025-03-01T00:00:00Z", false},
+		{"date before epoch", date, model.ConditionOperatorLessThan, "1740787200", false},
+		{"date from invalid date", date, model.ConditionOperatorGreaterThan, "March 1st", true},
+		{"date between", date, model.ConditionOperatorBetween, "2025-01-01T00:00:00Z,1740787200", false},
+		{"date between reversed bounds", date, model.ConditionOperatorBetween, "2025-06-01T00:00:00Z,2025-01-01T00:00:00Z", true},
+		{"date between single bound", date, model.ConditionOperatorNotBetween, "2025-01-01T00:00:00Z", true},
+		{"date with string operator", date, model.ConditionOperatorContains, "2025", true},
+		{"date presence", date, model.ConditionOperatorIsSet, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConditionForAttribute(tt.attribute, tt.operator, tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// attributeListRepository records the filter it was listed with
+type attributeListRepository struct {
+	repository.Repository
+	filter model.AttributeFilter
+}
+
+func (r *attributeListRepository) ListAttributes(_ context.Context, filter model.AttributeFilter) ([]*model.Attribute, int64, error) {
+	r.filter = filter
+	return []*model.Attribute{{ID: 1, Name: "country"}}, 12, nil
+}
+
+func TestListAttributes(t *testing.T) {
+	repo := &attributeListRepository{}
+	s := &service{repo: repo}
+
+	attributes, total, err := s.ListAttributes(context.Background(), model.AttributeFilter{
+		DataType: model.DataTypeEnum, Search: " coun ", InUseOnly: true, Sort: model.AttributeSortUsageCount, Limit: 1, Offset: 3,
+	})
+	require.NoError(t, err)
+	require.Len(t, attributes, 1)
+	require.Equal(t, int64(12), total)
+	require.Equal(t, model.AttributeFilter{
+		DataType: model.DataTypeEnum, Search: "coun", InUseOnly: true, Sort: model.AttributeSortUsageCount, Limit: 1, Offset: 3,
+	}, repo.filter)
+
+	// Unknown sort fields and data types are rejected rather than ignored
+	_, _, err = s.ListAttributes(context.Background(), model.AttributeFilter{Sort: "updatedAt"})
+	require.True(t, errors.Is(err, apperror.ErrBadRequest))
+	_, _, err = s.ListAttributes(context.Background(), model.AttributeFilter{DataType: "datetime"})
+	require.True(t, errors.Is(err, apperror.ErrBadRequest))
+	_, _, err = s.ListAttributes(context.Background(), model.AttributeFilter{DataType: model.DataTypeDate})
+	require.NoError(t, err)
+}
+
+// mergeRepository serves the reads of an attribute merge, while the writes go through the embedded repository
+type mergeRepository struct {
+	repository.Repository
+	attributes          map[uint]*model.Attribute
+	segmentConditions   []*model.SegmentRuleCondition
+	parameterConditions []*model.ParameterRuleCondition
+	segmentParameterIDs []uint
+	draftExperimentIDs  []int
+	experimentStatus    string
+}
+
+func (r *mergeRepository) GetAttributeByID(_ context.Context, id uint) (*model.Attribute, error) {
+	attribute, ok := r.attributes[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return attribute, nil
+}
+
+func (r *mergeRepository) GetSegmentRuleConditionsByAttributeID(context.Context, uint) ([]*model.SegmentRuleCondition, error) {
+	return r.segmentConditions, nil
+}
+
+func (r *mergeRepository) GetParameterRuleConditionsByAttributeID(context.Context, uint) ([]*model.ParameterRuleCondition, error) {
+	return r.parameterConditions, nil
+}
+
+func (r *mergeRepository) GetParameterIDsBySegmentIDs(context.Context, []uint) ([]uint, error) {
+	return r.segmentParameterIDs, nil
+}
+
+func (r *mergeRepository) GetExperimentIDsBySegmentIDsAndStatus(_ context.Context, _ []uint, status string) ([]int, error) {
+	r.experimentStatus = status
+	return r.draftExperimentIDs, nil
+}
+
+func newMergeService(t *testing.T, source, target *model.Attribute) (*service, *mergeRepository, *recordingConnector) {
+	connector := &recordingConnector{}
+	s := newRecordingService(t, connector)
+	repo := &mergeRepository{
+		Repository:          s.repo,
+		attributes:          map[uint]*model.Attribute{source.ID: source, target.ID: target},
+		segmentConditions:   []*model.SegmentRuleCondition{{ID: 10, Value: "VN", Rule: &model.SegmentRule{SegmentID: 5}}},
+		parameterConditions: []*model.ParameterRuleCondition{{ID: 20, Value: "US", Rule: &model.ParameterRule{ParameterID: 7}}},
+		segmentParameterIDs: []uint{8},
+		draftExperimentIDs:  []int{9},
+	}
+	s.repo = repo
+	return s, repo, connector
+}
+
+// committedWith returns the arguments of the committed statements starting with prefix
+func committedWith(connector *recordingConnector, prefix string) [][]driver.Value {
+	var args [][]driver.Value
+	for i, query := range connector.committed {
+		if strings.HasPrefix(query, prefix) {
+			args = append(args, connector.committedArgs[i])
+		}
+	}
+	return args
+}
+
+func TestMergeAttribute(t *testing.T) {
+	source := &model.Attribute{ID: 1, Name: "user_country", DataType: model.DataTypeString, UsageCount: 3}
+	target := &model.Attribute{ID: 2, Name: "country", DataType: model.DataTypeString, UsageCount: 4}
+	s, repo, connector := newMergeService(t, source, target)
+
+	report, err := s.MergeAttribute(context.Background(), 1, 2, 42, false)
+	require.NoError(t, err)
+	require.Equal(t, []uint{10}, report.SegmentConditionIDs)
+	require.Equal(t, []uint{20}, report.ParameterConditionIDs)
+	require.Equal(t, []uint{7, 8}, report.AffectedParameterIDs)
+	require.Equal(t, []int{9}, report.AffectedExperimentIDs)
+	// Running and finished experiments keep the raw_value they were started with
+	require.Equal(t, constant.ExperimentStatusDraft, repo.experimentStatus)
+
+	t.Run("rewrites conditions", func(t *testing.T) {
+		require.Equal(t, [][]driver.Value{{int64(2), int64(10)}}, committedWith(connector, `UPDATE "segment_rule_conditions" SET "attribute_id"`))
+		require.Equal(t, [][]driver.Value{{int64(2), int64(20)}}, committedWith(connector, `UPDATE "parameter_rule_conditions" SET "attribute_id"`))
+	})
+
+	t.Run("transfers usage count", func(t *testing.T) {
+		require.Equal(t, 7, target.UsageCount)
+		saved := committedWith(connector, `UPDATE "attributes" SET "name"`)
+		require.Len(t, saved, 2)
+		require.Equal(t, "country", saved[0][0])
+		require.EqualValues(t, 7, saved[0][5])
+	})
+
+	t.Run("retires source", func(t *testing.T) {
+		require.Zero(t, source.UsageCount)
+		require.Equal(t, &target.ID, source.AliasOfID)
+		saved := committedWith(connector, `UPDATE "attributes" SET "name"`)
+		require.Equal(t, "user_country", saved[1][0])
+		require.EqualValues(t, 0, saved[1][5])
+		require.EqualValues(t, 2, saved[1][6])
+	})
+
+	t.Run("re-points aliases of source", func(t *testing.T) {
+		aliases := committedWith(connector, `UPDATE "attributes" SET "alias_of_id"`)
+		require.Len(t, aliases, 1)
+		require.EqualValues(t, 2, aliases[0][0])
+		require.EqualValues(t, 1, aliases[0][2])
+	})
+
+	t.Run("records audit entry", func(t *testing.T) {
+		audits := connector.inserted("audit_logs")
+		require.Len(t, audits, 1)
+		require.EqualValues(t, 42, audits[0]["actor_user_id"])
+		require.Equal(t, string(model.AuditEntityAttribute), audits[0]["entity_type"])
+		require.EqualValues(t, 1, audits[0]["entity_id"])
+		require.Equal(t, string(model.AuditActionMerge), audits[0]["action"])
+	})
+
+	t.Run("enqueues syncs", func(t *testing.T) {
+		require.Equal(t, []string{"sync_parameter", "sync_parameter", "sync_experiment"}, connector.enqueuedJobs())
+	})
+}
+
+func TestMergeAttributeRejectsTypeMismatch(t *testing.T) {
+	source := &model.Attribute{ID: 1, Name: "age", DataType: model.DataTypeNumber, UsageCount: 3}
+	target := &model.Attribute{ID: 2, Name: "country", DataType: model.DataTypeString, UsageCount: 4}
+	s, _, connector := newMergeService(t, source, target)
+
+	_, err := s.MergeAttribute(context.Background(), 1, 2, 42, false)
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+	require.Empty(t, connector.committed)
+	require.Equal(t, 3, source.UsageCount)
+	require.Nil(t, source.AliasOfID)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// GetAuditLogs retrieves audit log entries matching the filter with pagination
+func (s *service) GetAuditLogs(ctx context.Context, filter model.AuditLogFilter, limit, offset int) ([]*model.AuditLog, int64, error) {
+	return s.repo.GetAuditLogs(ctx, filter, limit, offset)
+}
+
+// recordAuditLog writes an audit log entry through repo, so it commits or rolls back with the mutation it describes
+func (s *service) recordAuditLog(ctx context.Context, repo repository.Repository, actorUserID uint, entityType model.AuditEntityType, entityID uint, action model.AuditAction, before, after json.RawMessage) error {
+	auditLog := &model.AuditLog{
+		ActorUserID: actorUserID,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		Action:      action,
+		Before:      before,
+		After:       after,
+	}
+	if err := repo.CreateAuditLog(ctx, auditLog); err != nil {
+		return fmt.Errorf("failed to record audit log: %w", err)
+	}
+	return nil
+}
+
+// parameterSnapshot returns the stored raw_value of a parameter, as refreshed by UpdateParameterRawValue
+func parameterSnapshot(ctx context.Context, repo repository.Repository, id uint) (json.RawMessage, error) {
+	parameter, err := repo.GetParameterByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load parameter snapshot: %w", err)
+	}
+	return parameter.RawValue, nil
+}
+
+// experimentSnapshot returns the stored raw_value of an experiment, as refreshed by UpdateExperimentRawValue
+func experimentSnapshot(ctx context.Context, repo repository.Repository, id uint) (json.RawMessage, error) {
+	experiment, err := repo.GetExperimentByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load experiment snapshot: %w", err)
+	}
+	return experiment.RawValue, nil
+}
+
+// changeRequestSnapshot serializes a parameter change request for the audit log, without its preloaded relations
+func changeRequestSnapshot(changeRequest *model.ParameterChangeRequest) (json.RawMessage, error) {
+	withoutRelations := *changeRequest
+	withoutRelations.Parameter = nil
+	withoutRelations.RequestedByUser = nil
+	withoutRelations.ReviewedByUser = nil
+
+	snapshot, err := json.Marshal(withoutRelations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize change request snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// attributeSnapshot serializes an attribute for the audit log
+func attributeSnapshot(attribute *model.Attribute) (json.RawMessage, error) {
+	snapshot, err := json.Marshal(attribute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize attribute snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// segmentSnapshot serializes a segment with its rules and conditions for the audit log, without the
+// preloaded attributes of its conditions
+func segmentSnapshot(segment *model.Segment) (json.RawMessage, error) {
+	withoutRelations := *segment
+	withoutRelations.Rules = make([]model.SegmentRule, len(segment.Rules))
+	for i, rule := range segment.Rules {
+		rule.Segment = nil
+		rule.Conditions = slices.Clone(rule.Conditions)
+		for j := range rule.Conditions {
+			rule.Conditions[j].Rule = nil
+			rule.Conditions[j].Attribute = nil
+		}
+		withoutRelations.Rules[i] = rule
+	}
+
+	snapshot, err := json.Marshal(withoutRelations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize segment snapshot: %w", err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"api/internal/constant"
+	"api/internal/dto"
+	"api/internal/model"
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// storedRow is the row a table holds for every ID
+type storedRow struct {
+	columns []string
+	values  []driver.Value
+}
+
+// storedRows answers the lookups by ID of the tables with their row; other queries, such as lookups by name, find nothing
+func storedRows(tables map[string]storedRow) func(query string, args []driver.Value) ([]string, [][]driver.Value) {
+	return func(query string, _ []driver.Value) ([]string, [][]driver.Value) {
+		for table, row := range tables {
+			if strings.Contains(query, `"`+table+`"."id" = `) || strings.Contains(query, `FROM "`+table+`" WHERE id IN `) {
+				return row.columns, [][]driver.Value{row.values}
+			}
+		}
+		return nil, nil
+	}
+}
+
+func TestMutationsRecordAuditLog(t *testing.T) {
+	description := "updated"
+	attribute := storedRow{[]string{"id", "name", "data_type", "usage_count"}, []driver.Value{int64(5), "country", "string", int64(0)}}
+	segment := storedRow{[]string{"id", "name"}, []driver.Value{int64(6), "beta testers"}}
+	parameter := storedRow{
+		[]string{"id", "name", "data_type", "default_rollout_value", "usage_count", "raw_value"},
+		[]driver.Value{int64(8), "dark_mode", "boolean", []byte("false"), int64(0), []byte(`{"id":8}`)},
+	}
+
+	experiment := storedRow{
+		[]string{"id", "name", "status", "start_date", "end_date", "raw_value"},
+		[]driver.Value{int64(7), "checkout button", constant.ExperimentStatusDraft, time.Now().Add(time.Hour).Unix(), time.Now().Add(48 * time.Hour).Unix(), []byte(`{"id":7}`)},
+	}
+	variants := []dto.CreateExperimentVariantRequest{{
+		Name: "dark", Description: "dark mode on", TrafficAllocation: 100,
+		Parameters: []dto.CreateExperimentVariantParameterRequest{{ParameterDataType: "boolean", ParameterID: 8, ParameterName: "dark_mode", RolloutValue: "true"}},
+	}}
+
+	tests := []struct {
+		name         string
+		tables       map[string]storedRow
+		mutate       func(s *service) error
+		wantEntity   model.AuditEntityType
+		wantAction   model.AuditAction
+		wantEntityID int64
+	}{
+		{
+			name: "create attribute",
+			mutate: func(s *service) error {
+				_, err := s.CreateAttribute(context.Background(), 3, &dto.CreateAttributeRequest{Name: "country", DataType: model.DataTypeString})
+				return err
+			},
+			wantEntity: model.AuditEntityAttribute, wantAction: model.AuditActionCreate,
+		},
+		{
+			name:   "update attribute",
+			tables: map[string]storedRow{"attributes": attribute},
+			mutate: func(s *service) error {
+				_, err := s.UpdateAttribute(context.Background(), 5, 3, &dto.UpdateAttributeRequest{Description: &description})
+				return err
+			},
+			wantEntity: model.AuditEntityAttribute, wantAction: model.AuditActionUpdate, wantEntityID: 5,
+		},
+		{
+			name:       "delete attribute",
+			tables:     map[string]storedRow{"attributes": attribute},
+			mutate:     func(s *service) error { return s.DeleteAttribute(context.Background(), 5, 3) },
+			wantEntity: model.AuditEntityAttribute, wantAction: model.AuditActionDelete, wantEntityID: 5,
+		},
+		{
+			name: "create segment",
+			mutate: func(s *service) error {
+				_, err := s.CreateSegment(context.Background(), 3, &dto.CreateSegmentRequest{Name: "beta testers"})
+				return err
+			},
+			wantEntity: model.AuditEntitySegment, wantAction: model.AuditActionCreate,
+		},
+		{
+			name:   "update segment",
+			tables: map[string]storedRow{"segments": segment},
+			mutate: func(s *service) error {
+				_, err := s.UpdateSegment(context.Background(), 6, 3, &dto.UpdateSegmentRequest{Description: &description})
+				return err
+			},
+			wantEntity: model.AuditEntitySegment, wantAction: model.AuditActionUpdate, wantEntityID: 6,
+		},
+		{
+			name:       "delete segment",
+			tables:     map[string]storedRow{"segments": segment},
+			mutate:     func(s *service) error { return s.DeleteSegment(context.Background(), 6, 3) },
+			wantEntity: model.AuditEntitySegment, wantAction: model.AuditActionDelete, wantEntityID: 6,
+		},
+		{
+			name:   "create parameter",
+			tables: map[string]storedRow{"parameters": parameter},
+			mutate: func(s *service) error {
+				_, err := s.CreateParameter(context.Background(), 3, &dto.CreateParameterRequest{
+					Name: "dark_mode", DataType: model.ParameterDataTypeBoolean, DefaultRolloutValue: false,
+				})
+				return err
+			},
+			wantEntity: model.AuditEntityParameter, wantAction: model.AuditActionCreate,
+		},
+		{
+			name:   "update parameter",
+			tables: map[string]storedRow{"parameters": parameter},
+			mutate: func(s *service) error {
+				_, err := s.UpdateParameter(context.Background(), 8, 3, &dto.UpdateParameterRequest{Description: &description})
+				return err
+			},
+			wantEntity: model.AuditEntityParameter, wantAction: model.AuditActionUpdate, wantEntityID: 8,
+		},
+		{
+			name:       "delete parameter",
+			tables:     map[string]storedRow{"parameters": parameter},
+			mutate:     func(s *service) error { return s.DeleteParameter(context.Background(), 8, 3) },
+			wantEntity: model.AuditEntityParameter, wantAction: model.AuditActionDelete, wantEntityID: 8,
+		},
+		{
+			name:   "create experiment",
+			tables: map[string]storedRow{"attributes": attribute, "parameters": parameter, "experiments": experiment},
+			mutate: func(s *service) error {
+				_, err := s.CreateExperiment(context.Background(), 3, &dto.CreateExperimentRequest{
+					Name: "checkout button", Hypothesis: "dark mode converts", Description: "dark mode",
+					StartDate: time.Now().Add(time.Hour).Unix(), EndDate: time.Now().Add(48 * time.Hour).Unix(),
+					HashAttributeID: 5, PopulationSize: 100, Strategy: "percentage_split", Variants: variants,
+				})
+				return err
+			},
+			wantEntity: model.AuditEntityExperiment, wantAction: model.AuditActionCreate,
+		},
+		{
+			name:   "update experiment",
+			tables: map[string]storedRow{"parameters": parameter, "experiments": experiment},
+			mutate: func(s *service) error {
+				_, err := s.UpdateExperimentVariants(context.Background(), 7, 3, &dto.UpdateExperimentVariantsRequest{Variants: variants})
+				return err
+			},
+			wantEntity: model.AuditEntityExperiment, wantAction: model.AuditActionUpdate, wantEntityID: 7,
+		},
+		{
+			name:       "delete experiment",
+			tables:     map[string]storedRow{"experiments": experiment},
+			mutate:     func(s *service) error { return s.DeleteExperiment(context.Background(), 7, 3) },
+			wantEntity: model.AuditEntityExperiment, wantAction: model.AuditActionDelete, wantEntityID: 7,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &recordingConnector{rows: storedRows(tt.tables)}
+			s := newRecordingService(t, connector)
+
+			require.NoError(t, tt.mutate(s))
+
+			auditLogs := connector.inserted("audit_logs")
+			require.Len(t, auditLogs, 1)
+			require.Equal(t, int64(3), auditLogs[0]["actor_user_id"])
+			require.Equal(t, string(tt.wantEntity), auditLogs[0]["entity_type"])
+			require.Equal(t, string(tt.wantAction), auditLogs[0]["action"])
+			if tt.wantEntityID != 0 {
+				require.Equal(t, tt.wantEntityID, auditLogs[0]["entity_id"])
+			}
+		})
+	}
+}
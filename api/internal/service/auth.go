@@ -2,6 +2,7 @@ package service
 
 import (
 	"api/config"
+	"api/internal/apperror"
 	"api/internal/dto"
 	"api/internal/middleware"
 	"api/internal/model"
@@ -104,7 +105,7 @@ func (s *service) HandleGoogleCallback(ctx context.Context, cfg *config.Config,
 	token, err := oauthConfig.Exchange(ctx, code)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to exchange code for token")
-		return nil, fmt.Errorf("failed to exchange code: %w", err)
+		return nil, apperror.Unauthorized("failed to exchange authorization code")
 	}
 
 	// Get user info from Google
@@ -117,7 +118,7 @@ func (s *service) HandleGoogleCallback(ctx context.Context, cfg *config.Config,
 	// Validate email domain
 	if !isEmailDomainAllowed(userInfo.Email, cfg.OAuth.AllowedDomains) {
 		logger.Warn().Str("email", userInfo.Email).Msg("Email domain not allowed")
-		return nil, fmt.Errorf("email domain is not allowed for this organization")
+		return nil, apperror.Forbidden("email domain is not allowed for this organization")
 	}
 
 	// Check if user exists, create if not
@@ -244,7 +245,7 @@ func (s *service) RefreshToken(ctx context.Context, cfg *config.Config, refreshT
 	newToken, err := tokenSource.Token()
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to refresh token")
-		return nil, fmt.Errorf("failed to refresh token: %w", err)
+		return nil, apperror.Unauthorized("failed to refresh token")
 	}
 
 	// Get user info to find the user
@@ -257,14 +258,17 @@ func (s *service) RefreshToken(ctx context.Context, cfg *config.Config, refreshT
 	// Validate email domain
 	if !isEmailDomainAllowed(userInfo.Email, cfg.OAuth.AllowedDomains) {
 		logger.Warn().Str("email", userInfo.Email).Msg("Email domain not allowed during token refresh")
-		return nil, fmt.Errorf("email domain is not allowed for this organization")
+		return nil, apperror.Forbidden("email domain is not allowed for this organization")
 	}
 
 	// Update user's tokens
 	user, err := s.repo.GetUserByGoogleID(ctx, userInfo.ID)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to get user")
-		return nil, fmt.Errorf("user not found: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.Unauthorized("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	user.AccessToken = newToken.AccessToken
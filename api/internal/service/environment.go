@@ -0,0 +1,239 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/dto"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// CreateEnvironment creates an environment with a unique key
+func (s *service) CreateEnvironment(ctx context.Context, req *dto.CreateEnvironmentRequest) (*model.Environment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperror.BadRequest("invalid environment: %v", err)
+	}
+
+	existing, err := s.repo.GetEnvironmentByKey(ctx, req.Key)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperror.Conflict("environment with key '%s' already exists", req.Key)
+	}
+
+	environment := &model.Environment{
+		Name: strings.TrimSpace(req.Name),
+		Key:  req.Key,
+	}
+	if err := s.repo.CreateEnvironment(ctx, environment); err != nil {
+		return nil, fmt.Errorf("failed to create environment: %w", err)
+	}
+	return environment, nil
+}
+
+// GetAllEnvironments retrieves all environments
+func (s *service) GetAllEnvironments(ctx context.Context) ([]*model.Environment, error) {
+	return s.repo.GetAllEnvironments(ctx)
+}
+
+// GetEnvironmentByID retrieves an environment by ID
+func (s *service) GetEnvironmentByID(ctx context.Context, id uint) (*model.Environment, error) {
+	environment, err := s.repo.GetEnvironmentByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("environment with ID %d not found", id)
+		}
+		return nil, err
+	}
+	return environment, nil
+}
+
+// UpdateEnvironment renames an environment
+func (s *service) UpdateEnvironment(ctx context.Context, id uint, req *dto.UpdateEnvironmentRequest) (*model.Environment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperror.BadRequest("invalid environment: %v", err)
+	}
+
+	environment, err := s.GetEnvironmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	environment.Name = strings.TrimSpace(req.Name)
+	if err := s.repo.UpdateEnvironment(ctx, environment); err != nil {
+		return nil, fmt.Errorf("failed to update environment: %w", err)
+	}
+	return environment, nil
+}
+
+// DeleteEnvironment deletes an environment with the parameter overrides made in it. Environments that
+// experiments are limited to cannot be deleted.
+func (s *service) DeleteEnvironment(ctx context.Context, id uint) error {
+	environment, err := s.GetEnvironmentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	experiments, err := s.repo.CountExperimentsByEnvironmentID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if experiments > 0 {
+		return apperror.Conflict("cannot delete environment '%s' as %d experiment(s) are limited to it", environment.Key, experiments)
+	}
+
+	return s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		return txRepo.DeleteEnvironment(ctx, id)
+	})
+}
+
+// resolveEnvironment returns the environment identified by key, or nil when key is empty
+func (s *service) resolveEnvironment(ctx context.Context, key string) (*model.Environment, error) {
+	if key == "" {
+		return nil, nil
+	}
+	environment, err := s.repo.GetEnvironmentByKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("environment '%s' not found", key)
+		}
+		return nil, err
+	}
+	return environment, nil
+}
+
+// ensureEnvironmentExists checks that the environment an experiment is limited to exists, if it has one
+func (s *service) ensureEnvironmentExists(ctx context.Context, id *int) error {
+	if id == nil {
+		return nil
+	}
+	if _, err := s.GetEnvironmentByID(ctx, uint(*id)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// sharesEnvironment reports whether experiments limited to environments a and b can run in the same
+// environment, a nil environment standing for every environment
+func sharesEnvironment(a, b *int) bool {
+	return a == nil || b == nil || *a == *b
+}
+
+// runsInEnvironment reports whether an experiment limited to experimentEnvironmentID is served to SDK
+// clients of environment. Clients without an environment only get experiments running everywhere.
+func runsInEnvironment(experimentEnvironmentID *int, environment *model.Environment) bool {
+	if experimentEnvironmentID == nil {
+		return true
+	}
+	return environment != nil && uint(*experimentEnvironmentID) == environment.ID
+}
+
+// GetParameterEnvironmentOverrides retrieves the overrides of a parameter in every environment
+func (s *service) GetParameterEnvironmentOverrides(ctx context.Context, parameterID uint) ([]*model.ParameterEnvironmentOverride, error) {
+	if _, err := s.GetParameterByID(ctx, parameterID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetParameterEnvironmentOverridesByParameterID(ctx, parameterID)
+}
+
+// SetParameterEnvironmentOverride creates or replaces the override of a parameter in an environment.
+// Values are validated against the parameter's data type like those of its base definition.
+func (s *service) SetParameterEnvironmentOverride(ctx context.Context, parameterID uint, environmentID uint, userID uint, req *dto.SetParameterEnvironmentOverrideRequest) (*model.ParameterEnvironmentOverride, error) {
+	logger := log.Ctx(ctx).With().Str("service", "set-parameter-environment-override").Uint("parameterId", parameterID).Uint("environmentId", environmentID).Logger()
+
+	parameter, err := s.GetParameterByID(ctx, parameterID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetEnvironmentByID(ctx, environmentID); err != nil {
+		return nil, err
+	}
+
+	if req.DefaultRolloutValue != nil {
+		if err := s.validateParameterValue(req.DefaultRolloutValue, parameter.DataType, parameter.EnumOptions, parameter.NumberConstraints); err != nil {
+			return nil, err
+		}
+	}
+
+	var override *model.ParameterEnvironmentOverride
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		existing, err := txRepo.GetParameterEnvironmentOverride(ctx, parameterID, environmentID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		var before json.RawMessage
+		if existing != nil {
+			before = existing.RawValue
+		} else {
+			existing = &model.ParameterEnvironmentOverride{ParameterID: parameterID, EnvironmentID: environmentID}
+		}
+
+		existing.DefaultRolloutValue = nil
+		if req.DefaultRolloutValue != nil {
+			existing.DefaultRolloutValue = &model.RolloutValue{Data: req.DefaultRolloutValue}
+		}
+		existing.OverrideRules = req.Rules != nil
+		if err := txRepo.SaveParameterEnvironmentOverride(ctx, existing); err != nil {
+			return fmt.Errorf("failed to save environment override: %w", err)
+		}
+
+		if err := txRepo.DeleteParameterEnvironmentRules(ctx, existing.ID); err != nil {
+			return fmt.Errorf("failed to delete existing environment rules: %w", err)
+		}
+		if err := s.createParameterRulesTx(ctx, txRepo, parameterID, &existing.ID, req.Rules, parameter.DataType, parameter.EnumOptions, parameter.NumberConstraints); err != nil {
+			return err
+		}
+
+		if err := txRepo.UpdateParameterRawValue(ctx, parameterID); err != nil {
+			return fmt.Errorf("failed to update parameter raw value: %w", err)
+		}
+
+		override, err = txRepo.GetParameterEnvironmentOverride(ctx, parameterID, environmentID)
+		if err != nil {
+			return err
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, parameterID, model.AuditActionSetEnvironmentOverride, before, override.RawValue); err != nil {
+			return err
+		}
+
+		logger.Info().Msg("Enqueuing sync parameter job")
+		return s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{ParameterID: int(parameterID)})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+// DeleteParameterEnvironmentOverride removes the override of a parameter in an environment, which then
+// serves the base definition again
+func (s *service) DeleteParameterEnvironmentOverride(ctx context.Context, parameterID uint, environmentID uint, userID uint) error {
+	override, err := s.repo.GetParameterEnvironmentOverride(ctx, parameterID, environmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("parameter %d has no override in environment %d", parameterID, environmentID)
+		}
+		return err
+	}
+
+	return s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.DeleteParameterEnvironmentOverride(ctx, override.ID); err != nil {
+			return fmt.Errorf("failed to delete environment override: %w", err)
+		}
+		if err := txRepo.UpdateParameterRawValue(ctx, parameterID); err != nil {
+			return fmt.Errorf("failed to update parameter raw value: %w", err)
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, parameterID, model.AuditActionDeleteEnvironmentOverride, override.RawValue, nil); err != nil {
+			return err
+		}
+		return s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{ParameterID: int(parameterID)})
+	})
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"api/internal/apperror"
 	"api/internal/dto"
 	"api/internal/model"
 	"context"
@@ -12,12 +13,12 @@ import (
 
 // EventRepositoryInterface defines the interface for event repository
 type EventRepositoryInterface interface {
-	CreateEvent(ctx context.Context, event *model.EvaluationEvent) error
-	CreateEventsBatch(ctx context.Context, events []*model.EvaluationEvent) error
+	InsertEvents(ctx context.Context, events []model.EvaluationEvent) ([]string, error)
 	GetEventsByServiceName(ctx context.Context, serviceName string, limit, offset int) ([]model.EvaluationEvent, error)
 	GetEventsByParameterName(ctx context.Context, parameterName string, limit, offset int) ([]model.EvaluationEvent, error)
 	GetEventsByExperimentID(ctx context.Context, experimentID int, limit, offset int) ([]model.EvaluationEvent, error)
 	GetEventStats(ctx context.Context, serviceName string) (map[string]interface{}, error)
+	GetExperimentExposures(ctx context.Context, experimentID int, hashAttribute string, filter model.ExposureFilter) ([]model.ExperimentExposure, error)
 }
 
 // EventService handles business logic for evaluation events
@@ -34,43 +35,26 @@ func NewEventService(eventRepo EventRepositoryInterface, logger zerolog.Logger)
 	}
 }
 
-// TrackEvent tracks an evaluation event
+// TrackEvent tracks an evaluation event. An event whose ID is already stored is acknowledged as a duplicate.
 func (s *EventService) TrackEvent(ctx context.Context, req *dto.TrackEventRequest) (*dto.TrackEventResponse, error) {
-	// Convert user attributes to JSON string
-	userAttributesJSON, err := json.Marshal(req.UserAttributes)
+	batch, err := s.TrackBatchEvent(ctx, &dto.TrackBatchEventRequest{Events: []dto.TrackEventRequest{*req}})
 	if err != nil {
-		s.logger.Error().Err(err).Msg("failed to marshal user attributes")
 		return &dto.TrackEventResponse{
 			Success: false,
-			Message: "failed to process user attributes",
+			Message: "failed to save event",
 		}, err
 	}
 
-	// Create event model
-	event := &model.EvaluationEvent{
-		EventID:        req.ID,
-		ServiceName:    req.ServiceName,
-		EventType:      string(req.EventType),
-		ParameterName:  req.ParameterName,
-		Source:         req.Source,
-		UserAttributes: string(userAttributesJSON),
-		RolloutValue:   req.RolloutValue,
-		Error:          req.Error,
-		Timestamp:      req.Timestamp,
-		ExperimentID:   req.ExperimentID,
-		ExperimentUUID: req.ExperimentUUID,
-		VariantID:      req.VariantID,
-		VariantName:    req.VariantName,
-	}
-
-	// Save to database
-	err = s.eventRepo.CreateEvent(ctx, event)
-	if err != nil {
-		s.logger.Error().Err(err).Msg("failed to create event")
+	result := batch.Results[0]
+	switch result.Status {
+	case dto.EventStatusInvalid:
+		return nil, apperror.BadRequest("invalid event: %s", result.Error)
+	case dto.EventStatusDuplicate:
 		return &dto.TrackEventResponse{
-			Success: false,
-			Message: "failed to save event",
-		}, err
+			Success: true,
+			Message: "event already tracked",
+			Status:  result.Status,
+		}, nil
 	}
 
 	s.logger.Info().
@@ -83,6 +67,7 @@ func (s *EventService) TrackEvent(ctx context.Context, req *dto.TrackEventReques
 	return &dto.TrackEventResponse{
 		Success: true,
 		Message: "event tracked successfully",
+		Status:  result.Status,
 	}, nil
 }
 
@@ -106,7 +91,15 @@ func (s *EventService) GetEventStats(ctx context.Context, serviceName string) (m
 	return s.eventRepo.GetEventStats(ctx, serviceName)
 }
 
-// TrackBatchEvent tracks multiple evaluation events in batch
+// GetExperimentExposures retrieves the per variant and per day exposure counts of an experiment
+func (s *EventService) GetExperimentExposures(ctx context.Context, experimentID int, hashAttribute string, filter model.ExposureFilter) ([]model.ExperimentExposure, error) {
+	return s.eventRepo.GetExperimentExposures(ctx, experimentID, hashAttribute, filter)
+}
+
+// TrackBatchEvent tracks multiple evaluation events in batch, reporting the status of each event.
+// Invalid events are skipped, and events whose ID is already stored, or appears earlier in the batch,
+// are reported as duplicates, so retried batches are not counted twice. The valid events are stored
+// in a single statement.
 func (s *EventService) TrackBatchEvent(ctx context.Context, req *dto.TrackBatchEventRequest) (*dto.TrackBatchEventResponse, error) {
 	if len(req.Events) == 0 {
 		return &dto.TrackBatchEventResponse{
@@ -114,41 +107,36 @@ func (s *EventService) TrackBatchEvent(ctx context.Context, req *dto.TrackBatchE
 			Message: "no events provided",
 		}, nil
 	}
+	if len(req.Events) > dto.MaxTrackBatchEvents {
+		return nil, apperror.BadRequest("a batch holds at most %d events, got %d", dto.MaxTrackBatchEvents, len(req.Events))
+	}
 
-	// Convert events to models
-	events := make([]*model.EvaluationEvent, 0, len(req.Events))
+	results := make([]dto.TrackEventResult, len(req.Events))
+	events := make([]model.EvaluationEvent, 0, len(req.Events))
 	failedEvents := make([]int, 0)
+	seen := make(map[string]bool, len(req.Events))
+
+	for i := range req.Events {
+		eventReq := &req.Events[i]
+		results[i].ID = eventReq.ID
 
-	for i, eventReq := range req.Events {
-		// Convert user attributes to JSON string
-		userAttributesJSON, err := json.Marshal(eventReq.UserAttributes)
+		event, err := newEvaluationEvent(eventReq)
 		if err != nil {
-			s.logger.Error().Err(err).Int("eventIndex", i).Msg("failed to marshal user attributes")
+			s.logger.Warn().Err(err).Int("eventIndex", i).Str("eventId", eventReq.ID).Msg("invalid event")
+			results[i].Status = dto.EventStatusInvalid
+			results[i].Error = err.Error()
 			failedEvents = append(failedEvents, i)
 			continue
 		}
-
-		// Create event model
-		event := &model.EvaluationEvent{
-			EventID:        eventReq.ID,
-			ServiceName:    eventReq.ServiceName,
-			EventType:      string(eventReq.EventType),
-			ParameterName:  eventReq.ParameterName,
-			Source:         eventReq.Source,
-			UserAttributes: string(userAttributesJSON),
-			RolloutValue:   eventReq.RolloutValue,
-			Error:          eventReq.Error,
-			Timestamp:      eventReq.Timestamp,
-			ExperimentID:   eventReq.ExperimentID,
-			ExperimentUUID: eventReq.ExperimentUUID,
-			VariantID:      eventReq.VariantID,
-			VariantName:    eventReq.VariantName,
+		if seen[eventReq.ID] {
+			results[i].Status = dto.EventStatusDuplicate
+			continue
 		}
+		seen[eventReq.ID] = true
 		events = append(events, event)
 	}
 
-	// Save batch to database
-	err := s.eventRepo.CreateEventsBatch(ctx, events)
+	inserted, err := s.eventRepo.InsertEvents(ctx, events)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("failed to create events batch")
 		return &dto.TrackBatchEventResponse{
@@ -156,20 +144,66 @@ func (s *EventService) TrackBatchEvent(ctx context.Context, req *dto.TrackBatchE
 			Message: "failed to save events batch",
 		}, err
 	}
+	insertedIDs := make(map[string]bool, len(inserted))
+	for _, id := range inserted {
+		insertedIDs[id] = true
+	}
 
-	processed := len(events)
+	processed, duplicates := 0, 0
+	for i := range results {
+		switch {
+		case results[i].Status == dto.EventStatusInvalid:
+			// Already counted as failed
+		case results[i].Status == "" && insertedIDs[results[i].ID]:
+			results[i].Status = dto.EventStatusAccepted
+			processed++
+		default:
+			results[i].Status = dto.EventStatusDuplicate
+			duplicates++
+		}
+	}
 	failed := len(failedEvents)
 
 	s.logger.Info().
 		Int("processed", processed).
+		Int("duplicates", duplicates).
 		Int("failed", failed).
 		Msg("batch events tracked successfully")
 
 	return &dto.TrackBatchEventResponse{
 		Success:      true,
-		Message:      fmt.Sprintf("processed %d events, %d failed", processed, failed),
+		Message:      fmt.Sprintf("processed %d events, %d duplicates, %d failed", processed, duplicates, failed),
 		Processed:    processed,
+		Duplicates:   duplicates,
 		Failed:       failed,
 		FailedEvents: failedEvents,
+		Results:      results,
+	}, nil
+}
+
+// newEvaluationEvent validates a tracked event and converts it to its stored form
+func newEvaluationEvent(req *dto.TrackEventRequest) (model.EvaluationEvent, error) {
+	if err := req.Validate(); err != nil {
+		return model.EvaluationEvent{}, err
+	}
+	userAttributesJSON, err := json.Marshal(req.UserAttributes)
+	if err != nil {
+		return model.EvaluationEvent{}, fmt.Errorf("failed to process user attributes: %w", err)
+	}
+	return model.EvaluationEvent{
+		EventID:           req.ID,
+		ServiceName:       req.ServiceName,
+		EventType:         string(req.EventType),
+		ParameterName:     req.ParameterName,
+		Source:            req.Source,
+		UserAttributes:    string(userAttributesJSON),
+		RolloutValue:      req.RolloutValue,
+		Error:             req.Error,
+		Timestamp:         req.Timestamp,
+		ExperimentID:      req.ExperimentID,
+		ExperimentUUID:    req.ExperimentUUID,
+		VariantID:         req.VariantID,
+		VariantName:       req.VariantName,
+		VariantOverridden: req.VariantOverridden,
 	}, nil
 }
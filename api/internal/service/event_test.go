@@ -0,0 +1,249 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/dto"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func newTrackEventRequest(id string) dto.TrackEventRequest {
+	return dto.TrackEventRequest{
+		ID:             id,
+		ServiceName:    "checkout",
+		EventType:      dto.EventTypeParameterEvaluation,
+		ParameterName:  "theme",
+		Source:         "parameter",
+		UserAttributes: map[string]interface{}{"userId": "u1"},
+		Timestamp:      time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestTrackBatchEventReportsStatusOfEachEvent(t *testing.T) {
+	repo := &capturingEventRepository{events: []model.EvaluationEvent{{EventID: "stored"}}}
+	s := NewEventService(repo, zerolog.Nop())
+
+	invalid := newTrackEventRequest("invalid")
+	invalid.EventType = "click"
+
+	resp, err := s.TrackBatchEvent(context.Background(), &dto.TrackBatchEventRequest{Events: []dto.TrackEventRequest{
+		newTrackEventRequest("new"),
+		invalid,
+		newTrackEventRequest("new"),
+		newTrackEventRequest("stored"),
+	}})
+	require.NoError(t, err)
+
+	require.True(t, resp.Success)
+	require.Equal(t, 1, resp.Processed)
+	require.Equal(t, 2, resp.Duplicates)
+	require.Equal(t, 1, resp.Failed)
+	require.Equal(t, []int{1}, resp.FailedEvents)
+	statuses := make([]dto.EventStatus, len(resp.Results))
+	for i, result := range resp.Results {
+		statuses[i] = result.Status
+	}
+	require.Equal(t, []dto.EventStatus{dto.EventStatusAccepted, dto.EventStatusInvalid, dto.EventStatusDuplicate, dto.EventStatusDuplicate}, statuses)
+	require.NotEmpty(t, resp.Results[1].Error)
+	require.Len(t, repo.events, 2)
+}
+
+func TestTrackEventAcknowledgesRetriedEvent(t *testing.T) {
+	repo := &capturingEventRepository{}
+	s := NewEventService(repo, zerolog.Nop())
+	req := newTrackEventRequest("event-1")
+
+	resp, err := s.TrackEvent(context.Background(), &req)
+	require.NoError(t, err)
+	require.Equal(t, dto.EventStatusAccepted, resp.Status)
+
+	resp, err = s.TrackEvent(context.Background(), &req)
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	require.Equal(t, dto.EventStatusDuplicate, resp.Status)
+	require.Len(t, repo.events, 1)
+}
+
+func TestTrackEventRejectsInvalidEvent(t *testing.T) {
+	s := NewEventService(&capturingEventRepository{}, zerolog.Nop())
+	req := newTrackEventRequest("")
+
+	_, err := s.TrackEvent(context.Background(), &req)
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+}
+
+// storedEvent is an evaluation event as the event repository writes it
+type storedEvent struct {
+	EventID           string                 `json:"event_id"`
+	EventType         string                 `json:"event_type"`
+	Source            string                 `json:"source"`
+	UserAttributes    map[string]interface{} `json:"user_attributes"`
+	Timestamp         time.Time              `json:"timestamp"`
+	ExperimentID      *int                   `json:"experiment_id"`
+	VariantID         *int                   `json:"variant_id"`
+	VariantOverridden bool                   `json:"variant_overridden"`
+}
+
+// eventTable stands for the evaluation_events table: it keeps the events the event repository inserts
+// and answers its exposures query by grouping them per variant, and per variant and day, as postgres does
+type eventTable struct {
+	events []storedEvent
+}
+
+func (e *eventTable) rows(t *testing.T, query string, args []driver.Value) ([]string, [][]driver.Value) {
+	if strings.Contains(query, "insert into evaluation_events") {
+		var events []storedEvent
+		require.NoError(t, json.Unmarshal([]byte(args[0].(string)), &events))
+		var inserted [][]driver.Value
+		for _, event := range events {
+			if !slices.ContainsFunc(e.events, func(stored storedEvent) bool { return stored.EventID == event.EventID }) {
+				e.events = append(e.events, event)
+				inserted = append(inserted, []driver.Value{event.EventID})
+			}
+		}
+		return []string{"event_id"}, inserted
+	}
+
+	// The arguments follow the exposures query: the hash attribute, the experiment ID, the event type,
+	// the overridden flag and source left out, then the time range bounds that are set
+	hashAttribute, experimentID, eventType := args[0].(string), int(args[1].(int64)), args[2].(string)
+	bounds := args[5:]
+	var from, to *time.Time
+	if strings.Contains(query, "timestamp >= ") {
+		at := bounds[0].(time.Time)
+		from, bounds = &at, bounds[1:]
+	}
+	if strings.Contains(query, "timestamp < ") {
+		at := bounds[0].(time.Time)
+		to = &at
+	}
+
+	// total groups hold the variant totals, the other groups the totals of a variant on the day starting at day
+	type group struct {
+		variantID int
+		total     bool
+		day       int64
+	}
+	users := make(map[group]map[interface{}]bool)
+	events := make(map[group]int64)
+	var groups []group
+	for _, event := range e.events {
+		switch {
+		case event.ExperimentID == nil || *event.ExperimentID != experimentID || event.EventType != eventType:
+			continue
+		case event.VariantID == nil || event.VariantOverridden || event.Source == "override":
+			continue
+		case from != nil && event.Timestamp.Before(*from), to != nil && !event.Timestamp.Before(*to):
+			continue
+		}
+		day := event.Timestamp.UTC().Truncate(24 * time.Hour).Unix()
+		for _, key := range []group{{variantID: *event.VariantID, total: true}, {variantID: *event.VariantID, day: day}} {
+			if users[key] == nil {
+				users[key] = make(map[interface{}]bool)
+				groups = append(groups, key)
+			}
+			users[key][event.UserAttributes[hashAttribute]] = true
+			events[key]++
+		}
+	}
+
+	// Groups are ordered by variant, the total of a variant first, as ordering by day nulls first does
+	slices.SortFunc(groups, func(a, b group) int {
+		switch {
+		case a.variantID != b.variantID:
+			return a.variantID - b.variantID
+		case a.total != b.total && a.total:
+			return -1
+		case a.total != b.total:
+			return 1
+		}
+		return int(a.day - b.day)
+	})
+	values := make([][]driver.Value, len(groups))
+	for i, key := range groups {
+		var day driver.Value
+		if !key.total {
+			day = time.Unix(key.day, 0).UTC()
+		}
+		values[i] = []driver.Value{int64(key.variantID), day, int64(len(users[key])), events[key]}
+	}
+	return []string{"variant_id", "day", "users", "events"}, values
+}
+
+func TestTrackedEventsAreReportedAsExposures(t *testing.T) {
+	ctx := context.Background()
+	experimentUUID := "0b8e4c1e-7f4c-4d8e-9a53-5b1f2f3c9d10"
+	table := &eventTable{}
+	connector := &recordingConnector{}
+	connector.rows = func(query string, args []driver.Value) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, "evaluation_events"):
+			return table.rows(t, query, args)
+		case strings.Contains(query, `FROM "experiments"`):
+			return []string{"id", "uuid", "hash_attribute_id", "population_size"}, [][]driver.Value{{int64(7), experimentUUID, int64(5), int64(100)}}
+		case strings.Contains(query, `FROM "attributes"`):
+			return []string{"id", "name"}, [][]driver.Value{{int64(5), "userId"}}
+		case strings.Contains(query, `FROM "experiment_variants"`):
+			return []string{"id", "experiment_id", "name", "traffic_allocation"}, [][]driver.Value{
+				{int64(10), int64(7), "control", int64(50)},
+				{int64(11), int64(7), "treatment", int64(50)},
+			}
+		}
+		return nil, nil
+	}
+	s := newRecordingService(t, connector)
+	s.eventService = NewEventService(repository.NewEventRepository(s.repo.GetDB()), zerolog.Nop())
+
+	exposure := func(id, user string, variantID int, day int) dto.TrackEventRequest {
+		experimentID := 7
+		event := newTrackEventRequest(id)
+		event.EventType = dto.EventTypeExperimentEvaluation
+		event.Source = "experiment"
+		event.UserAttributes = map[string]interface{}{"userId": user}
+		event.Timestamp = time.Date(2025, 11, day, 12, 0, 0, 0, time.UTC)
+		event.ExperimentID, event.ExperimentUUID, event.VariantID = &experimentID, &experimentUUID, &variantID
+		return event
+	}
+	overridden := exposure("e6", "u5", 11, 1)
+	overridden.VariantOverridden = true
+
+	_, err := s.TrackBatchEvent(ctx, &dto.TrackBatchEventRequest{Events: []dto.TrackEventRequest{
+		exposure("e1", "u1", 10, 1),
+		exposure("e2", "u1", 10, 2),
+		exposure("e3", "u2", 10, 2),
+		exposure("e4", "u3", 11, 1),
+		exposure("e5", "u4", 11, 1),
+		overridden,
+		exposure("e7", "u6", 11, 3),
+	}})
+	require.NoError(t, err)
+	// A retried batch is not counted twice
+	_, err = s.TrackBatchEvent(ctx, &dto.TrackBatchEventRequest{Events: []dto.TrackEventRequest{exposure("e1", "u1", 10, 1)}})
+	require.NoError(t, err)
+
+	to := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	response, err := s.GetExperimentExposuresByUuid(ctx, experimentUUID, model.ExposureFilter{To: &to})
+	require.NoError(t, err)
+
+	require.Equal(t, 7, response.ExperimentID)
+	require.Equal(t, "userId", response.HashAttribute)
+	totals := make(map[string][2]int64)
+	for _, variant := range response.Exposures {
+		totals[variant.VariantName] = [2]int64{variant.Users, variant.Events}
+	}
+	require.Equal(t, map[string][2]int64{"control": {2, 3}, "treatment": {2, 2}}, totals)
+	require.Equal(t, []dto.ExperimentExposureDayResponse{
+		{Date: "2025-11-01", Users: 1, Events: 1},
+		{Date: "2025-11-02", Users: 2, Events: 2},
+	}, response.Exposures[0].Daily)
+}
@@ -1,14 +1,20 @@
 package service
 
 import (
+	"api/internal/apperror"
 	"api/internal/constant"
 	"api/internal/dto"
+	"api/internal/external/solver"
 	"api/internal/mapper"
 	"api/internal/model"
+	"api/internal/repository"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	sdk "sdk/types"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,74 +23,40 @@ import (
 	"gorm.io/gorm"
 )
 
-// contextKey is a type for context keys to avoid collisions
-type contextKey string
+// experimentCreatedMessage is the response to experiment creation
+const experimentCreatedMessage = "Experiment created successfully"
 
-const txContextKey contextKey = "tx"
+// maxExperimentOverrides caps the overrides of an experiment, which are served to every SDK client
+const maxExperimentOverrides = 100
 
-// CreateExperiment creates a new experiment with its variants and parameters
-func (s *service) CreateExperiment(ctx context.Context, req *dto.CreateExperimentRequest) (string, error) {
-
-	if err := req.Validate(); err != nil {
-		return "", err
-	}
-
-	_, err := s.repo.GetAttributeByID(ctx, uint(req.HashAttributeID))
+// CreateExperiment creates a new experiment with its variants and parameters. Requests with an idempotency
+// key already used within the last 24 hours get the original response without creating another experiment.
+func (s *service) CreateExperiment(ctx context.Context, userID uint, req *dto.CreateExperimentRequest) (string, error) {
+	// A retried request returns the response of the original one
+	experimentID, err := s.findIdempotentResource(ctx, model.IdempotencyScopeCreateExperiment, req.IdempotencyKey)
 	if err != nil {
 		return "", err
 	}
-
-	// Collect unique parameter IDs
-	parameterIDSSet := make(map[int]bool)
-	for _, variant := range req.Variants {
-		for _, parameter := range variant.Parameters {
-			parameterIDSSet[parameter.ParameterID] = true
-		}
+	if experimentID != 0 {
+		return experimentCreatedMessage, nil
 	}
 
-	parameterIDS := make([]int, 0, len(parameterIDSSet))
-	for parameterID := range parameterIDSSet {
-		parameterIDS = append(parameterIDS, parameterID)
+	if err := req.Validate(); err != nil {
+		return "", apperror.BadRequest("invalid experiment: %v", err)
 	}
-	parameters, err := s.repo.GetParametersByIDs(ctx, parameterIDS)
 
+	_, err = s.repo.GetAttributeByID(ctx, uint(req.HashAttributeID))
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", apperror.NotFound("hash attribute with ID %d not found", req.HashAttributeID)
+		}
 		return "", err
 	}
 
-	if len(parameters) != len(parameterIDS) {
-		return "", fmt.Errorf("some parameters do not exist")
-	}
-
-	mapParameters := make(map[int]model.Parameter)
-	for _, parameter := range parameters {
-		mapParameters[int(parameter.ID)] = parameter
-	}
-
-	for _, variant := range req.Variants {
-		for _, parameter := range variant.Parameters {
-			verifiedParameter, ok := mapParameters[parameter.ParameterID]
-			if !ok {
-				return "", fmt.Errorf("parameter %d does not exist", parameter.ParameterID)
-			}
-			if verifiedParameter.DataType != model.ParameterDataType(parameter.ParameterDataType) {
-				return "", fmt.Errorf("parameter %d has invalid data type", parameter.ParameterID)
-			}
-			if verifiedParameter.Name != parameter.ParameterName {
-				return "", fmt.Errorf("parameter %d has invalid name", parameter.ParameterID)
-			}
-			// Validate rollout value based on data type
-			if verifiedParameter.DataType == model.ParameterDataTypeString || verifiedParameter.DataType == model.ParameterDataTypeNumber {
-				if parameter.RolloutValue == "" {
-					return "", fmt.Errorf("parameter %d has invalid rollout value", parameter.ParameterID)
-				}
-			}
-			if verifiedParameter.DataType == model.ParameterDataTypeBoolean {
-				if parameter.RolloutValue != "true" && parameter.RolloutValue != "false" {
-					return "", fmt.Errorf("parameter %d has invalid rollout value", parameter.ParameterID)
-				}
-			}
-		}
+	// Collect unique parameter IDs
+	parameterIDS, err := s.validateExperimentVariantParameters(ctx, req.Variants)
+	if err != nil {
+		return "", err
 	}
 
 	exp, err := s.repo.GetExperimentByName(ctx, req.Name)
@@ -93,60 +65,31 @@ func (s *service) CreateExperiment(ctx context.Context, req *dto.CreateExperimen
 	}
 
 	if exp != nil {
-		return "", fmt.Errorf("experiment with name %s already exists", req.Name)
+		return "", apperror.Conflict("experiment with name %s already exists", req.Name)
 	}
 
 	if req.SegmentID != 0 {
 		_, err = s.repo.GetSegmentByID(ctx, uint(req.SegmentID))
 		if err != nil {
-			return "", fmt.Errorf("segment with id %d not found", req.SegmentID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", apperror.NotFound("segment with id %d not found", req.SegmentID)
+			}
+			return "", fmt.Errorf("failed to get segment: %w", err)
 		}
 	}
 
-	// Check for conflicting experiments with sophisticated segment analysis
-	conflictingExperiments, err := s.repo.FindConflictingExperiments(ctx, parameterIDS, req.SegmentID, req.StartDate, req.EndDate)
-	if err != nil {
-		return "", fmt.Errorf("failed to check for conflicting experiments: %w", err)
-	}
-
-	// Filter experiments based on sophisticated segment overlap analysis
-	var actualConflicts []*model.Experiment
-	for _, exp := range conflictingExperiments {
-		hasOverlap, err := s.checkSegmentOverlap(ctx, req.SegmentID, exp.SegmentID)
-		if err != nil {
-			return "", fmt.Errorf("failed to check segment overlap: %w", err)
-		}
-		if hasOverlap {
-			actualConflicts = append(actualConflicts, exp)
-		}
+	if err := s.ensureEnvironmentExists(ctx, req.EnvironmentID); err != nil {
+		return "", err
 	}
 
-	if len(actualConflicts) > 0 {
-		// Build detailed conflict message
-		var conflictDetails []string
-		for _, exp := range actualConflicts {
-			conflictDetails = append(conflictDetails, fmt.Sprintf("Experiment '%s' (ID: %d, Status: %s, Segment: %d, Period: %d-%d)",
-				exp.Name, exp.ID, exp.Status, exp.SegmentID, exp.StartDate, exp.EndDate))
-		}
-
-		return "", fmt.Errorf("experiment conflicts detected with %d existing experiment(s): [%s]",
-			len(actualConflicts), strings.Join(conflictDetails, ", "))
+	conflicts, err := s.FindExperimentConflicts(ctx, parameterIDS, req.SegmentID, req.EnvironmentID, req.StartDate, req.EndDate, 0)
+	if err != nil {
+		return "", err
 	}
-
-	// Start a transaction
-	tx := s.repo.GetDB().Begin()
-	if tx.Error != nil {
-		return "", fmt.Errorf("failed to start transaction: %w", tx.Error)
+	if len(conflicts) > 0 {
+		return "", &apperror.ExperimentConflictError{Conflicts: conflicts}
 	}
 
-	// Rollback transaction on any error
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			panic(r)
-		}
-	}()
-
 	// Create the experiment with business logic
 	now := time.Now().Unix()
 	experiment := &model.Experiment{
@@ -163,68 +106,65 @@ func (s *service) CreateExperiment(ctx context.Context, req *dto.CreateExperimen
 		UpdatedAt:       now,
 		Status:          constant.ExperimentStatusDraft, // Default status
 		SegmentID:       req.SegmentID,
+		EnvironmentID:   req.EnvironmentID,
+		CreatedByUserID: &userID,
+		UpdatedByUserID: &userID,
 	}
 
-	// Use transaction context
-	txCtx := context.WithValue(ctx, txContextKey, tx)
-	if err := s.repo.CreateExperiment(txCtx, experiment); err != nil {
-		tx.Rollback()
-		return "", fmt.Errorf("failed to create experiment: %w", err)
-	}
-
-	// Create variants and their parameters
-	for _, variantReq := range req.Variants {
-		variant := &model.ExperimentVariant{
-			ExperimentID:      experiment.ID,
-			Name:              variantReq.Name,
-			Description:       variantReq.Description,
-			TrafficAllocation: variantReq.TrafficAllocation,
-			CreatedAt:         now,
-			UpdatedAt:         now,
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.CreateExperiment(ctx, experiment); err != nil {
+			return fmt.Errorf("failed to create experiment: %w", err)
 		}
 
-		if err := s.repo.CreateExperimentVariant(txCtx, variant); err != nil {
-			tx.Rollback()
-			return "", fmt.Errorf("failed to create experiment variant: %w", err)
+		if err := createExperimentVariantsTx(ctx, txRepo, experiment.ID, req.Variants, now); err != nil {
+			return err
 		}
 
-		// Create variant parameters
-		for _, paramReq := range variantReq.Parameters {
-			parameter := &model.ExperimentVariantParameter{
-				ExperimentVariantID: variant.ID,
-				ParameterDataType:   paramReq.ParameterDataType,
-				ParameterID:         paramReq.ParameterID,
-				ParameterName:       paramReq.ParameterName,
-				RolloutValue:        paramReq.RolloutValue,
-				ExperimentID:        experiment.ID,
-				CreatedAt:           now,
-				UpdatedAt:           now,
-			}
-
-			if err := s.repo.CreateExperimentVariantParameter(txCtx, parameter); err != nil {
-				tx.Rollback()
-				return "", fmt.Errorf("failed to create experiment variant parameter: %w", err)
+		// Count the experiment once per parameter it uses, however many variants reference it
+		for _, parameterID := range parameterIDS {
+			if err := txRepo.IncrementParameterUsageCount(ctx, uint(parameterID)); err != nil {
+				return fmt.Errorf("failed to increment parameter usage count: %w", err)
 			}
 		}
-	}
 
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
-		return "", fmt.Errorf("failed to commit transaction: %w", err)
-	}
+		// Update raw_value field with all related data
+		if err := txRepo.UpdateExperimentRawValue(ctx, uint(experiment.ID)); err != nil {
+			return fmt.Errorf("failed to update experiment raw value: %w", err)
+		}
 
-	// Update raw_value field with all related data
-	if err := s.repo.UpdateExperimentRawValue(ctx, uint(experiment.ID)); err != nil {
-		// Log error but don't fail the creation since experiment was already created successfully
-		log.Ctx(ctx).Error().Err(err).Int("experimentId", experiment.ID).Msg("Failed to update experiment raw_value")
+		after, err := experimentSnapshot(ctx, txRepo, uint(experiment.ID))
+		if err != nil {
+			return err
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityExperiment, uint(experiment.ID), model.AuditActionCreate, nil, after); err != nil {
+			return err
+		}
+		return recordIdempotencyKeyTx(ctx, txRepo, model.IdempotencyScopeCreateExperiment, req.IdempotencyKey, uint(experiment.ID))
+	})
+	if err != nil {
+		// A concurrent request with the same key may have created the experiment first
+		if experimentID, findErr := s.findIdempotentResource(ctx, model.IdempotencyScopeCreateExperiment, req.IdempotencyKey); findErr == nil && experimentID != 0 {
+			return experimentCreatedMessage, nil
+		}
+		return "", err
 	}
 
-	return "Experiment created successfully", nil
+	return experimentCreatedMessage, nil
 }
 
-// GetAllExperiments retrieves all experiments without variants
-func (s *service) GetAllExperiments(ctx context.Context) ([]*model.Experiment, error) {
-	return s.repo.GetAllExperiments(ctx, 0, 0) // 0, 0 means no limit and no offset
+// GetAllExperiments retrieves a page of experiments filtered by status, name and dates, with the total count.
+// A limit of 0 returns all matching experiments.
+func (s *service) GetAllExperiments(ctx context.Context, filter model.ExperimentFilter, limit, offset int) ([]*model.ExperimentSummary, int64, error) {
+	for _, status := range filter.Statuses {
+		if !slices.Contains(constant.ExperimentStatuses, status) {
+			return nil, 0, apperror.BadRequest("invalid status %q: must be one of [%s]", status, strings.Join(constant.ExperimentStatuses, ", "))
+		}
+	}
+	if filter.StartAfter > 0 && filter.EndBefore > 0 && filter.StartAfter >= filter.EndBefore {
+		return nil, 0, apperror.BadRequest("startAfter must be before endBefore")
+	}
+	filter.Search = strings.TrimSpace(filter.Search)
+	return s.repo.GetExperimentsPaginated(ctx, filter, limit, offset)
 }
 
 // GetExperimentByID retrieves an experiment by ID with all variants and their parameters
@@ -232,6 +172,9 @@ func (s *service) GetExperimentByID(ctx context.Context, id uint) (*model.Experi
 	// Get the experiment with all preloaded data
 	experiment, err := s.repo.GetExperimentByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, nil, apperror.NotFound("experiment with ID %d not found", id)
+		}
 		return nil, nil, nil, nil, fmt.Errorf("failed to get experiment: %w", err)
 	}
 
@@ -251,238 +194,1049 @@ func (s *service) GetExperimentByID(ctx context.Context, id uint) (*model.Experi
 		variantParametersMap[variant.ID] = parameters
 	}
 
-	// Update experiment status based on dates
-	if err := s.updateExperimentStatusIfNeeded(ctx, experiment); err != nil {
-		return nil, nil, nil, nil, err
+	// Report the status the experiment's dates call for; the status worker stores it shortly after
+	if status, _, ok := experiment.DueStatus(time.Now().Unix(), s.cfg.Experiment.FinishPausedOnEnd); ok {
+		experiment.Status = status
 	}
 
 	return experiment, variants, variantParametersMap, experiment.HashAttribute, nil
 }
 
+// GetExperimentByUuid retrieves an experiment with its details like GetExperimentByID, looking it up by UUID
+func (s *service) GetExperimentByUuid(ctx context.Context, experimentUUID string) (*model.Experiment, []*model.ExperimentVariant, map[int][]*model.ExperimentVariantParameter, *model.Attribute, error) {
+	if err := uuid.Validate(experimentUUID); err != nil {
+		return nil, nil, nil, nil, apperror.BadRequest("invalid experiment UUID: %s", experimentUUID)
+	}
+
+	experiment, err := s.repo.GetExperimentByUuid(ctx, experimentUUID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, nil, apperror.NotFound("experiment with UUID %s not found", experimentUUID)
+		}
+		return nil, nil, nil, nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+
+	return s.GetExperimentByID(ctx, uint(experiment.ID))
+}
+
 // RejectExperiment rejects an experiment by updating its status to "cancel"
-func (s *service) RejectExperiment(ctx context.Context, id uint, req *dto.RejectExperimentRequest) (*model.Experiment, error) {
+func (s *service) RejectExperiment(ctx context.Context, id uint, userID uint, req *dto.RejectExperimentRequest) (*model.Experiment, error) {
 	// Get the experiment first to ensure it exists
 	experiment, err := s.repo.GetExperimentByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("experiment with ID %d not found", id)
+		}
 		return nil, fmt.Errorf("failed to get experiment: %w", err)
 	}
 
 	// Check if experiment can be rejected (business logic based on ExperimentStatus enum)
 	if experiment.Status != constant.ExperimentStatusDraft {
-		return nil, fmt.Errorf("experiment is not in draft status")
+		return nil, apperror.Conflict("experiment is not in draft status")
 	}
 
 	// Update the experiment status to cancel (reject)
+	previousStatus := experiment.Status
 	experiment.Status = constant.ExperimentStatusCancel
 	experiment.UpdatedAt = time.Now().Unix()
 
-	// Save the updated experiment and update raw_value
-	if err := s.updateExperimentAndRawValue(ctx, experiment); err != nil {
+	// Save the updated experiment and update raw_value, recording the change and enqueuing the sync in the same transaction
+	if err := s.updateExperimentWithAudit(ctx, userID, experiment, previousStatus, model.AuditActionReject); err != nil {
 		return nil, err
 	}
 
-	s.riverClient.Insert(ctx, dto.SyncExperimentArgs{}, nil)
-
 	return experiment, nil
 }
 
 // ApproveExperiment approves an experiment by updating its status to "approved"
-func (s *service) ApproveExperiment(ctx context.Context, id uint, req *dto.ApproveExperimentRequest) (*model.Experiment, error) {
+func (s *service) ApproveExperiment(ctx context.Context, id uint, userID uint, req *dto.ApproveExperimentRequest) (*model.Experiment, error) {
 	// Get the experiment first to ensure it exists
 	experiment, err := s.repo.GetExperimentByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("experiment with ID %d not found", id)
+		}
 		return nil, fmt.Errorf("failed to get experiment: %w", err)
 	}
 
 	if experiment.Status != constant.ExperimentStatusDraft {
-		return nil, fmt.Errorf("experiment is not in draft status")
+		return nil, apperror.Conflict("experiment is not in draft status")
+	}
+
+	// Parameters may have changed since the experiment was drafted
+	if err := s.revalidateExperimentVariantParameters(ctx, experiment); err != nil {
+		return nil, err
 	}
 
 	// Extract parameter IDs from experiment variants
 	parameterIDS := s.extractParameterIDsFromExperiment(experiment)
 
-	// Check for conflicting experiments with sophisticated segment analysis
-	conflictingExperiments, err := s.repo.FindConflictingExperiments(ctx, parameterIDS, experiment.SegmentID, experiment.StartDate, experiment.EndDate)
+	// Exclude the current experiment from the conflict check
+	conflicts, err := s.FindExperimentConflicts(ctx, parameterIDS, experiment.SegmentID, experiment.EnvironmentID, experiment.StartDate, experiment.EndDate, experiment.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check for conflicting experiments: %w", err)
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, &apperror.ExperimentConflictError{Conflicts: conflicts}
 	}
 
-	// Filter experiments based on sophisticated segment overlap analysis and exclude current experiment
-	var actualConflicts []*model.Experiment
-	for _, exp := range conflictingExperiments {
-		// Exclude the current experiment from conflict check
-		if exp.ID == experiment.ID {
-			continue
+	// Update the experiment status to approved
+	previousStatus := experiment.Status
+	experiment.Status = constant.ExperimentStatusSchedule
+	experiment.UpdatedAt = time.Now().Unix()
+
+	// Save the updated experiment and update raw_value, recording the change and enqueuing the sync in the same transaction
+	if err := s.updateExperimentWithAudit(ctx, userID, experiment, previousStatus, model.AuditActionApprove); err != nil {
+		return nil, err
+	}
+
+	return experiment, nil
+}
+
+// AbortExperiment aborts an experiment by updating its status to "abort"
+func (s *service) AbortExperiment(ctx context.Context, id uint, userID uint, req *dto.AbortExperimentRequest) (*model.Experiment, error) {
+	// Get the experiment first to ensure it exists
+	experiment, err := s.repo.GetExperimentByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("experiment with ID %d not found", id)
 		}
-		hasOverlap, err := s.checkSegmentOverlap(ctx, experiment.SegmentID, exp.SegmentID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check segment overlap: %w", err)
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+
+	// Check if experiment can be aborted (only scheduled, running or paused experiments can be aborted)
+	if experiment.Status != constant.ExperimentStatusSchedule && experiment.Status != constant.ExperimentStatusRunning && experiment.Status != constant.ExperimentStatusPause {
+		return nil, apperror.Conflict("experiment is not in schedule status")
+	}
+
+	// Update the experiment status to abort
+	previousStatus := experiment.Status
+	experiment.Status = constant.ExperimentStatusAbort
+	experiment.UpdatedAt = time.Now().Unix()
+
+	// Save the updated experiment, release its parameters, record the change and enqueue the sync in one transaction
+	before := experiment.RawValue
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := s.releaseParameterUsage(ctx, txRepo, experiment); err != nil {
+			return err
 		}
-		if hasOverlap {
-			actualConflicts = append(actualConflicts, exp)
+		if err := s.updateExperimentWithAuditTx(ctx, txRepo, userID, experiment, before, previousStatus, model.AuditActionAbort); err != nil {
+			return err
 		}
+		return s.enqueueTx(ctx, txRepo, dto.SyncExperimentArgs{ExperimentID: experiment.ID})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if len(actualConflicts) > 0 {
-		// Build detailed conflict message
-		var conflictDetails []string
-		for _, exp := range actualConflicts {
-			conflictDetails = append(conflictDetails, fmt.Sprintf("Experiment '%s' (ID: %d, Status: %s, Segment: %d, Period: %d-%d)",
-				exp.Name, exp.ID, exp.Status, exp.SegmentID, exp.StartDate, exp.EndDate))
+	return experiment, nil
+}
+
+// PauseExperiment temporarily stops exposing users to a running experiment.
+// The experiment stays in the SDK export with status "pause" so that cached
+// copies are overwritten on the next refresh and the engine skips it, and
+// resuming restores identical bucketing since assignment hashing is deterministic.
+func (s *service) PauseExperiment(ctx context.Context, id uint, userID uint, req *dto.PauseExperimentRequest) (*model.Experiment, error) {
+	// Get the experiment first to ensure it exists
+	experiment, err := s.repo.GetExperimentByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("experiment with ID %d not found", id)
 		}
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
 
-		return nil, fmt.Errorf("experiment conflicts detected with %d existing experiment(s): [%s]",
-			len(actualConflicts), strings.Join(conflictDetails, ", "))
+	// Only running experiments can be paused
+	if experiment.Status != constant.ExperimentStatusRunning {
+		return nil, apperror.Conflict("experiment is not in running status")
 	}
 
-	// Update the experiment status to approved
-	experiment.Status = constant.ExperimentStatusSchedule
+	previousStatus := experiment.Status
+	experiment.Status = constant.ExperimentStatusPause
 	experiment.UpdatedAt = time.Now().Unix()
 
-	// Save the updated experiment and update raw_value
-	if err := s.updateExperimentAndRawValue(ctx, experiment); err != nil {
+	// Save the updated experiment and update raw_value, recording the change and enqueuing the sync in the same transaction
+	if err := s.updateExperimentWithAudit(ctx, userID, experiment, previousStatus, model.AuditActionPause); err != nil {
 		return nil, err
 	}
 
-	s.riverClient.Insert(ctx, dto.SyncExperimentArgs{}, nil)
+	log.Ctx(ctx).Info().Int("experimentId", experiment.ID).Str("reason", req.Reason).Msg("Experiment paused")
 
 	return experiment, nil
 }
 
-// AbortExperiment aborts an experiment by updating its status to "abort"
-func (s *service) AbortExperiment(ctx context.Context, id uint, req *dto.AbortExperimentRequest) (*model.Experiment, error) {
+// ResumeExperiment resumes a paused experiment. If the end date passed while
+// the experiment was paused it is finished instead of resumed.
+func (s *service) ResumeExperiment(ctx context.Context, id uint, userID uint, req *dto.ResumeExperimentRequest) (*model.Experiment, error) {
 	// Get the experiment first to ensure it exists
 	experiment, err := s.repo.GetExperimentByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("experiment with ID %d not found", id)
+		}
 		return nil, fmt.Errorf("failed to get experiment: %w", err)
 	}
 
-	// Check if experiment can be aborted (only scheduled or running experiments can be aborted)
-	if experiment.Status != constant.ExperimentStatusSchedule && experiment.Status != constant.ExperimentStatusRunning {
-		return nil, fmt.Errorf("experiment is not in schedule status")
+	// Only paused experiments can be resumed
+	if experiment.Status != constant.ExperimentStatusPause {
+		return nil, apperror.Conflict("experiment is not in pause status")
 	}
 
-	// Update the experiment status to abort
-	experiment.Status = constant.ExperimentStatusAbort
-	experiment.UpdatedAt = time.Now().Unix()
+	now := time.Now().Unix()
+	previousStatus := experiment.Status
+	experiment.Status = constant.ExperimentStatusRunning
+	if experiment.EndDate < now {
+		experiment.Status = constant.ExperimentStatusFinish
+	}
+	experiment.UpdatedAt = now
 
-	// Save the updated experiment and update raw_value
-	if err := s.updateExperimentAndRawValue(ctx, experiment); err != nil {
+	// Save the updated experiment and update raw_value, recording the change and enqueuing the sync in the same transaction
+	if err := s.updateExperimentWithAudit(ctx, userID, experiment, previousStatus, model.AuditActionResume); err != nil {
 		return nil, err
 	}
 
-	s.riverClient.Insert(ctx, dto.SyncExperimentArgs{}, nil)
+	log.Ctx(ctx).Info().Int("experimentId", experiment.ID).Str("status", experiment.Status).Str("notes", req.Notes).Msg("Experiment resumed")
 
 	return experiment, nil
 }
 
-func (s *service) GetActiveExperimentsSDK(ctx context.Context) ([]sdk.Experiment, error) {
-	experiments, err := s.repo.GetExperimentsActive(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get active experiments: %w", err)
+// UpdateExperimentVariants replaces the variants of a draft experiment and their parameters.
+// Experiments past draft may already have exposed users, so their variants cannot change.
+func (s *service) UpdateExperimentVariants(ctx context.Context, id uint, userID uint, req *dto.UpdateExperimentVariantsRequest) (*model.Experiment, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperror.BadRequest("invalid experiment variants: %v", err)
 	}
 
-	// Use raw value conversion for better performance
-	result, err := mapper.ExperimentsToSDKFromRawValue(experiments)
+	experiment, err := s.repo.GetExperimentByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert experiments to sdk: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("experiment with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
 	}
-	return result, nil
-}
 
-// checkSegmentOverlap determines if two segments can have overlapping users
-func (s *service) checkSegmentOverlap(ctx context.Context, segmentID1, segmentID2 int) (bool, error) {
-	// Case 1: Both segments are empty (no segment)
-	if segmentID1 == 0 && segmentID2 == 0 {
-		return true, nil // All users are in both segments
+	if experiment.Status != constant.ExperimentStatusDraft {
+		return nil, apperror.Conflict("experiment is not in draft status")
 	}
 
-	// Case 2: One segment is empty, one is specific
-	if segmentID1 == 0 || segmentID2 == 0 {
-		return true, nil // Empty segment includes all users, so there's always overlap
+	parameterIDS, err := s.validateExperimentVariantParameters(ctx, req.Variants)
+	if err != nil {
+		return nil, err
 	}
 
-	// Case 3: Both segments are specific - need to analyze their conditions
-	if segmentID1 == segmentID2 {
-		return true, nil // Same segment
-	}
+	before := experiment.RawValue
+	now := time.Now().Unix()
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		for _, variant := range experiment.Variants {
+			if err := txRepo.DeleteExperimentVariantParametersByVariantID(ctx, uint(variant.ID)); err != nil {
+				return fmt.Errorf("failed to delete experiment variant parameters: %w", err)
+			}
+		}
 
-	// Load both segments with their rules and conditions
-	segment1, err := s.repo.GetSegmentByID(ctx, uint(segmentID1))
+		if err := txRepo.DeleteExperimentVariantsByExperimentID(ctx, uint(experiment.ID)); err != nil {
+			return fmt.Errorf("failed to delete experiment variants: %w", err)
+		}
+
+		if err := s.releaseParameterUsage(ctx, txRepo, experiment); err != nil {
+			return err
+		}
+
+		if err := createExperimentVariantsTx(ctx, txRepo, experiment.ID, req.Variants, now); err != nil {
+			return err
+		}
+
+		for _, parameterID := range parameterIDS {
+			if err := txRepo.IncrementParameterUsageCount(ctx, uint(parameterID)); err != nil {
+				return fmt.Errorf("failed to increment parameter usage count: %w", err)
+			}
+		}
+
+		experiment.UpdatedAt = now
+		experiment.UpdatedByUserID = &userID
+		experiment.Variants = nil
+		if err := txRepo.UpdateExperiment(ctx, experiment); err != nil {
+			return fmt.Errorf("failed to update experiment: %w", err)
+		}
+
+		if err := txRepo.UpdateExperimentRawValue(ctx, uint(experiment.ID)); err != nil {
+			return fmt.Errorf("failed to update experiment raw value: %w", err)
+		}
+
+		after, err := experimentSnapshot(ctx, txRepo, uint(experiment.ID))
+		if err != nil {
+			return err
+		}
+		return s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityExperiment, uint(experiment.ID), model.AuditActionUpdate, before, after)
+	})
 	if err != nil {
-		return false, fmt.Errorf("failed to load segment %d: %w", segmentID1, err)
+		return nil, err
 	}
 
-	segment2, err := s.repo.GetSegmentByID(ctx, uint(segmentID2))
+	experiment, err = s.repo.GetExperimentByID(ctx, id)
 	if err != nil {
-		return false, fmt.Errorf("failed to load segment %d: %w", segmentID2, err)
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
 	}
+	return experiment, nil
+}
 
-	res, err := s.solver.CheckSegmentsConflict([]model.Segment{*segment1, *segment2})
+// DeleteExperiment deletes a draft or cancelled experiment together with its variants
+// and variant parameters, and releases the usage counts of the parameters it referenced
+func (s *service) DeleteExperiment(ctx context.Context, id uint, userID uint) error {
+	experiment, err := s.repo.GetExperimentByID(ctx, id)
 	if err != nil {
-		return false, fmt.Errorf("failed to check segments conflict: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("experiment with ID %d not found", id)
+		}
+		return fmt.Errorf("failed to get experiment: %w", err)
 	}
 
-	return !res.Valid, nil
-}
+	// Only experiments that never exposed users can be deleted
+	if experiment.Status != constant.ExperimentStatusDraft && experiment.Status != constant.ExperimentStatusCancel {
+		return apperror.Conflict("experiment with status %s cannot be deleted", experiment.Status)
+	}
 
-// updateExperimentStatusIfNeeded updates the experiment status based on current date
-// and updates the raw_value field accordingly
-func (s *service) updateExperimentStatusIfNeeded(ctx context.Context, experiment *model.Experiment) error {
-	now := time.Now().Unix()
-	var newStatus string
+	tx := s.repo.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+	txRepo := repository.New(tx)
 
-	if experiment.Status == constant.ExperimentStatusSchedule && experiment.StartDate < now {
-		newStatus = constant.ExperimentStatusRunning
-	} else if experiment.Status == constant.ExperimentStatusRunning && experiment.EndDate < now {
-		newStatus = constant.ExperimentStatusFinish
-	} else {
-		return nil
+	if err := s.deleteExperimentTx(ctx, txRepo, experiment); err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	experiment.Status = newStatus
-	experiment.UpdatedAt = now
+	if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityExperiment, id, model.AuditActionDelete, experiment.RawValue, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
 
-	if err := s.repo.UpdateExperiment(ctx, experiment); err != nil {
-		return fmt.Errorf("failed to update experiment: %w", err)
+	if err := s.enqueueTx(ctx, txRepo, dto.SyncExperimentArgs{ExperimentID: experiment.ID}); err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	// Update raw_value field - log error but don't fail the operation
-	if err := s.repo.UpdateExperimentRawValue(ctx, uint(experiment.ID)); err != nil {
-		log.Ctx(ctx).Error().Err(err).Int("experimentId", experiment.ID).Msg("Failed to update experiment raw_value")
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
-// updateExperimentAndRawValue updates the experiment and its raw_value field
-// It logs errors for raw_value updates but doesn't fail the operation
-func (s *service) updateExperimentAndRawValue(ctx context.Context, experiment *model.Experiment) error {
-	if err := s.repo.UpdateExperiment(ctx, experiment); err != nil {
-		return fmt.Errorf("failed to update experiment: %w", err)
+// deleteExperimentTx removes the experiment rows using the given transactional repository
+func (s *service) deleteExperimentTx(ctx context.Context, txRepo repository.Repository, experiment *model.Experiment) error {
+	for _, variant := range experiment.Variants {
+		if err := txRepo.DeleteExperimentVariantParametersByVariantID(ctx, uint(variant.ID)); err != nil {
+			return fmt.Errorf("failed to delete experiment variant parameters: %w", err)
+		}
 	}
 
-	// Update raw_value field - log error but don't fail the update
-	if err := s.repo.UpdateExperimentRawValue(ctx, uint(experiment.ID)); err != nil {
-		log.Ctx(ctx).Error().Err(err).Int("experimentId", experiment.ID).Msg("Failed to update experiment raw_value")
+	if err := txRepo.DeleteExperimentVariantsByExperimentID(ctx, uint(experiment.ID)); err != nil {
+		return fmt.Errorf("failed to delete experiment variants: %w", err)
 	}
 
-	return nil
+	if err := txRepo.DeleteExperiment(ctx, uint(experiment.ID)); err != nil {
+		return fmt.Errorf("failed to delete experiment: %w", err)
+	}
+
+	return s.releaseParameterUsage(ctx, txRepo, experiment)
 }
 
-// extractParameterIDsFromExperiment extracts unique parameter IDs from an experiment's variants
-func (s *service) extractParameterIDsFromExperiment(experiment *model.Experiment) []int {
-	parameterIDSSet := make(map[int]bool)
-	for _, variant := range experiment.Variants {
-		for _, parameter := range variant.Parameters {
-			parameterIDSSet[parameter.ParameterID] = true
+// SetExperimentOverride forces the units whose hash attribute has the requested value into a variant of
+// the experiment, replacing the variant of an existing override for the value. Overrides only take effect
+// while the experiment is running, so they can be prepared before it starts.
+func (s *service) SetExperimentOverride(ctx context.Context, id uint, req *dto.SetExperimentOverrideRequest) (*model.ExperimentOverride, error) {
+	attributeValue := strings.TrimSpace(req.AttributeValue)
+	if attributeValue == "" {
+		return nil, apperror.BadRequest("attribute value is required")
+	}
+
+	experiment, err := s.getExperimentAcceptingOverrides(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(experiment.Variants, func(variant model.ExperimentVariant) bool {
+		return variant.ID == req.VariantID
+	}) {
+		return nil, apperror.BadRequest("variant with ID %d does not belong to experiment %d", req.VariantID, experiment.ID)
+	}
+
+	index := slices.IndexFunc(experiment.Overrides, func(override model.ExperimentOverride) bool {
+		return override.AttributeValue == attributeValue
+	})
+	var override model.ExperimentOverride
+	if index != -1 {
+		override = experiment.Overrides[index]
+	} else if len(experiment.Overrides) >= maxExperimentOverrides {
+		return nil, apperror.Conflict("experiment %d already has the maximum of %d overrides", experiment.ID, maxExperimentOverrides)
+	}
+	override.ExperimentID = experiment.ID
+	override.AttributeValue = attributeValue
+	override.VariantID = req.VariantID
+
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.SaveExperimentOverride(ctx, &override); err != nil {
+			return fmt.Errorf("failed to save experiment override: %w", err)
 		}
+		return s.syncExperimentOverridesTx(ctx, txRepo, experiment.ID)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return &override, nil
+}
 
-	parameterIDS := make([]int, 0, len(parameterIDSSet))
-	for parameterID := range parameterIDSSet {
-		parameterIDS = append(parameterIDS, parameterID)
+// DeleteExperimentOverride removes an override of the experiment
+func (s *service) DeleteExperimentOverride(ctx context.Context, id uint, overrideID uint) error {
+	experiment, err := s.getExperimentAcceptingOverrides(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	return parameterIDS
+	if !slices.ContainsFunc(experiment.Overrides, func(override model.ExperimentOverride) bool {
+		return override.ID == overrideID
+	}) {
+		return apperror.NotFound("override with ID %d not found in experiment %d", overrideID, experiment.ID)
+	}
+
+	return s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.DeleteExperimentOverride(ctx, overrideID); err != nil {
+			return fmt.Errorf("failed to delete experiment override: %w", err)
+		}
+		return s.syncExperimentOverridesTx(ctx, txRepo, experiment.ID)
+	})
+}
+
+// getExperimentAcceptingOverrides retrieves an experiment whose overrides can still change, which
+// excludes experiments that have ended
+func (s *service) getExperimentAcceptingOverrides(ctx context.Context, id uint) (*model.Experiment, error) {
+	experiment, err := s.repo.GetExperimentByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("experiment with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+
+	switch experiment.Status {
+	case constant.ExperimentStatusFinish, constant.ExperimentStatusCancel, constant.ExperimentStatusAbort:
+		return nil, apperror.Conflict("experiment with status %s cannot be overridden", experiment.Status)
+	}
+	return experiment, nil
+}
+
+// syncExperimentOverridesTx rebuilds the raw_value of an experiment whose overrides changed and
+// enqueues its sync, so that SDK clients receive the overrides
+func (s *service) syncExperimentOverridesTx(ctx context.Context, txRepo repository.Repository, experimentID int) error {
+	if err := txRepo.UpdateExperimentRawValue(ctx, uint(experimentID)); err != nil {
+		return fmt.Errorf("failed to update experiment raw value: %w", err)
+	}
+	return s.enqueueTx(ctx, txRepo, dto.SyncExperimentArgs{ExperimentID: experimentID})
+}
+
+// GetActiveExperimentsSDK returns the active experiments served to SDK clients of an environment, identified by
+// its key: those limited to the environment and those running in every environment. Without an environment only
+// the latter are returned.
+func (s *service) GetActiveExperimentsSDK(ctx context.Context, environmentKey string) ([]sdk.Experiment, error) {
+	environment, err := s.resolveEnvironment(ctx, environmentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	experiments, err := s.repo.GetExperimentsActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active experiments: %w", err)
+	}
+	experiments = slices.DeleteFunc(experiments, func(experiment model.Experiment) bool {
+		return !runsInEnvironment(experiment.EnvironmentID, environment)
+	})
+
+	// Use raw value conversion for better performance
+	return mapper.ExperimentsToSDKFromRawValue(ctx, experiments), nil
+}
+
+// RebuildRawValues regenerates the raw_value of every experiment, then checks which active experiments
+// still cannot be served from their raw_value. SDK clients are told to refresh once anything was rebuilt.
+func (s *service) RebuildRawValues(ctx context.Context) (*dto.RebuildRawValuesResponse, error) {
+	logger := log.Ctx(ctx).With().Str("service", "rebuild-raw-values").Logger()
+
+	rebuilt, failed, err := s.repo.RebuildAllExperimentRawValues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild experiment raw values: %w", err)
+	}
+	if rebuilt > 0 {
+		if _, err := s.riverClient.Insert(ctx, dto.SyncExperimentArgs{}, nil); err != nil {
+			logger.Error().Err(err).Msg("Failed to enqueue sync experiment job")
+		}
+	}
+
+	experiments, err := s.repo.GetExperimentsActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active experiments: %w", err)
+	}
+	sdkExperiments, warnings := mapper.ExperimentsToSDKFromRawValueWithWarnings(ctx, experiments)
+
+	logger.Info().Int("rebuilt", rebuilt).Int("failed", len(failed)).Int("warnings", warnings).Msg("Rebuilt experiment raw values")
+	return &dto.RebuildRawValuesResponse{
+		RebuiltExperiments:  rebuilt,
+		FailedExperimentIDs: failed,
+		ActiveExperiments:   len(experiments),
+		ServedExperiments:   len(sdkExperiments),
+		Warnings:            warnings,
+	}, nil
+}
+
+// checkSegmentOverlap determines if two segments can have overlapping users, explaining the
+// overlap when both segments have conditions
+func (s *service) checkSegmentOverlap(ctx context.Context, segmentID1, segmentID2 int) (bool, []solver.ConflictExplanation, error) {
+	// Case 1: Both segments are empty (no segment)
+	if segmentID1 == 0 && segmentID2 == 0 {
+		return true, nil, nil // All users are in both segments
+	}
+
+	// Case 2: One segment is empty, one is specific
+	if segmentID1 == 0 || segmentID2 == 0 {
+		return true, nil, nil // Empty segment includes all users, so there's always overlap
+	}
+
+	// Case 3: Both segments are specific - need to analyze their conditions
+	if segmentID1 == segmentID2 {
+		return true, nil, nil // Same segment
+	}
+
+	// Load both segments with their rules and conditions
+	segment1, err := s.repo.GetSegmentByID(ctx, uint(segmentID1))
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to load segment %d: %w", segmentID1, err)
+	}
+
+	segment2, err := s.repo.GetSegmentByID(ctx, uint(segmentID2))
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to load segment %d: %w", segmentID2, err)
+	}
+
+	// The solver only understands conditions, so references to other segments are expanded
+	for _, segment := range []*model.Segment{segment1, segment2} {
+		if err := s.repo.ExpandSegmentReferences(ctx, segment); err != nil {
+			return false, nil, fmt.Errorf("failed to expand segment %d: %w", segment.ID, err)
+		}
+	}
+
+	res, err := s.solver.CheckSegmentsConflict([]model.Segment{*segment1, *segment2})
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to check segments conflict: %w", err)
+	}
+
+	return !res.Valid, res.Explanation, nil
+}
+
+// updateExperimentAndRawValue updates the experiment, records the status transition and updates its raw_value field
+// It logs errors for raw_value updates but doesn't fail the operation
+func (s *service) updateExperimentAndRawValue(ctx context.Context, repo repository.Repository, experiment *model.Experiment, previousStatus string) error {
+	if err := repo.UpdateExperiment(ctx, experiment); err != nil {
+		return fmt.Errorf("failed to update experiment: %w", err)
+	}
+
+	if err := s.recordStatusTransition(ctx, repo, experiment.ID, previousStatus, experiment.Status, experiment.UpdatedAt); err != nil {
+		return err
+	}
+
+	// Update raw_value field - log error but don't fail the update
+	if err := repo.UpdateExperimentRawValue(ctx, uint(experiment.ID)); err != nil {
+		log.Ctx(ctx).Error().Err(err).Int("experimentId", experiment.ID).Msg("Failed to update experiment raw_value")
+	}
+
+	return nil
+}
+
+// updateExperimentWithAudit saves an experiment status change, its audit log entry and the sync job in one transaction.
+// The experiment's loaded raw_value is used as the before snapshot.
+func (s *service) updateExperimentWithAudit(ctx context.Context, userID uint, experiment *model.Experiment, previousStatus string, action model.AuditAction) error {
+	before := experiment.RawValue
+	return s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := s.updateExperimentWithAuditTx(ctx, txRepo, userID, experiment, before, previousStatus, action); err != nil {
+			return err
+		}
+		return s.enqueueTx(ctx, txRepo, dto.SyncExperimentArgs{ExperimentID: experiment.ID})
+	})
+}
+
+// updateExperimentWithAuditTx saves an experiment status change and its audit log entry using a transactional repository
+func (s *service) updateExperimentWithAuditTx(ctx context.Context, txRepo repository.Repository, userID uint, experiment *model.Experiment, before json.RawMessage, previousStatus string, action model.AuditAction) error {
+	experiment.UpdatedByUserID = &userID
+	if err := s.updateExperimentAndRawValue(ctx, txRepo, experiment, previousStatus); err != nil {
+		return err
+	}
+
+	after, err := experimentSnapshot(ctx, txRepo, uint(experiment.ID))
+	if err != nil {
+		return err
+	}
+	if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityExperiment, uint(experiment.ID), action, before, after); err != nil {
+		return err
+	}
+	return s.notifyStatusChangeTx(ctx, txRepo, experiment, previousStatus)
+}
+
+// notifyStatusChangeTx enqueues the webhook notification of an experiment's status change, when the
+// webhook is configured for its new status, so that it is only sent once the change is committed
+func (s *service) notifyStatusChangeTx(ctx context.Context, txRepo repository.Repository, experiment *model.Experiment, previousStatus string) error {
+	if previousStatus == experiment.Status || !s.cfg.NotifiesExperimentStatus(experiment.Status) {
+		return nil
+	}
+	return s.enqueueTx(ctx, txRepo, dto.ExperimentStatusWebhookArgs{
+		ExperimentID:   experiment.ID,
+		ExperimentName: experiment.Name,
+		FromStatus:     previousStatus,
+		ToStatus:       experiment.Status,
+		Timestamp:      experiment.UpdatedAt,
+	})
+}
+
+// releaseParameterUsage decrements the usage count of every parameter the experiment references
+func (s *service) releaseParameterUsage(ctx context.Context, txRepo repository.Repository, experiment *model.Experiment) error {
+	for _, parameterID := range s.extractParameterIDsFromExperiment(experiment) {
+		if err := txRepo.DecrementParameterUsageCount(ctx, uint(parameterID)); err != nil {
+			return fmt.Errorf("failed to decrement parameter usage count: %w", err)
+		}
+	}
+	return nil
+}
+
+// recordStatusTransition appends a status change to the transition log used for point-in-time reconstruction,
+// using repo so that the transition is rolled back along with the status change
+func (s *service) recordStatusTransition(ctx context.Context, repo repository.Repository, experimentID int, fromStatus, toStatus string, at int64) error {
+	if fromStatus == toStatus {
+		return nil
+	}
+
+	transition := &model.ExperimentStatusTransition{
+		ExperimentID: experimentID,
+		FromStatus:   fromStatus,
+		ToStatus:     toStatus,
+		CreatedAt:    at,
+	}
+	if err := repo.CreateExperimentStatusTransition(ctx, transition); err != nil {
+		return fmt.Errorf("failed to record experiment status transition: %w", err)
+	}
+	return nil
+}
+
+// extractParameterIDsFromVariants extracts unique parameter IDs from the variants of a request
+func extractParameterIDsFromVariants(variants []dto.CreateExperimentVariantRequest) []int {
+	parameterIDSSet := make(map[int]bool)
+	for _, variant := range variants {
+		for _, parameter := range variant.Parameters {
+			parameterIDSSet[parameter.ParameterID] = true
+		}
+	}
+
+	parameterIDS := make([]int, 0, len(parameterIDSSet))
+	for parameterID := range parameterIDSSet {
+		parameterIDS = append(parameterIDS, parameterID)
+	}
+
+	return parameterIDS
+}
+
+// validateExperimentVariantParameters checks that every variant parameter exists with the requested name and
+// data type and that its rollout value is valid for that type, returning the unique parameter IDs
+func (s *service) validateExperimentVariantParameters(ctx context.Context, variants []dto.CreateExperimentVariantRequest) ([]int, error) {
+	parameterIDS := extractParameterIDsFromVariants(variants)
+	parameters, err := s.repo.GetParametersByIDs(ctx, parameterIDS)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parameters) != len(parameterIDS) {
+		return nil, apperror.BadRequest("some parameters do not exist")
+	}
+
+	mapParameters := make(map[int]model.Parameter)
+	for _, parameter := range parameters {
+		mapParameters[int(parameter.ID)] = parameter
+	}
+
+	for _, variant := range variants {
+		for _, parameter := range variant.Parameters {
+			verifiedParameter, ok := mapParameters[parameter.ParameterID]
+			if !ok {
+				return nil, apperror.BadRequest("parameter %d of variant '%s' does not exist", parameter.ParameterID, variant.Name)
+			}
+			if verifiedParameter.DataType != model.ParameterDataType(parameter.ParameterDataType) {
+				return nil, apperror.BadRequest("parameter '%s' of variant '%s' has data type %s, not %s", verifiedParameter.Name, variant.Name, verifiedParameter.DataType, parameter.ParameterDataType)
+			}
+			if verifiedParameter.Name != parameter.ParameterName {
+				return nil, apperror.BadRequest("parameter %d of variant '%s' is named '%s', not '%s'", parameter.ParameterID, variant.Name, verifiedParameter.Name, parameter.ParameterName)
+			}
+			if err := validateVariantParameterValue(variant.Name, &verifiedParameter, parameter.RolloutValue); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return parameterIDS, nil
+}
+
+// validateVariantParameterValue checks that the rollout value a variant sets for a parameter can be read
+// as the parameter's data type by the SDK, and satisfies the parameter's number constraints
+func validateVariantParameterValue(variantName string, parameter *model.Parameter, rolloutValue string) error {
+	invalid := func(reason string) error {
+		return apperror.BadRequest("invalid rollout value %q for parameter '%s' in variant '%s': %s", rolloutValue, parameter.Name, variantName, reason)
+	}
+
+	switch parameter.DataType {
+	case model.ParameterDataTypeString:
+		if rolloutValue == "" {
+			return invalid("value must not be empty")
+		}
+	case model.ParameterDataTypeNumber:
+		number, err := strconv.ParseFloat(rolloutValue, 64)
+		if err != nil {
+			return invalid("value must be a number")
+		}
+		if err := parameter.NumberConstraints.Check(number); err != nil {
+			return invalid(err.Error())
+		}
+	case model.ParameterDataTypeBoolean:
+		if rolloutValue != "true" && rolloutValue != "false" {
+			return invalid("value must be true or false")
+		}
+	case model.ParameterDataTypeEnum:
+		if !slices.Contains(parameter.EnumOptions, rolloutValue) {
+			return invalid(fmt.Sprintf("value must be one of [%s]", strings.Join(parameter.EnumOptions, ", ")))
+		}
+	case model.ParameterDataTypeDate:
+		if _, err := sdk.ParseDate(rolloutValue); err != nil {
+			return invalid("value must be an RFC3339 date or unix epoch")
+		}
+	case model.ParameterDataTypeJSON:
+		var value interface{}
+		if err := sdk.ParseJSON(rolloutValue, &value); err != nil {
+			return invalid("value must be a JSON object or array")
+		}
+	}
+	return nil
+}
+
+// revalidateExperimentVariantParameters checks the variant parameters of a stored experiment against the
+// current parameters, which may have been deleted or changed since the experiment was drafted
+func (s *service) revalidateExperimentVariantParameters(ctx context.Context, experiment *model.Experiment) error {
+	parameters, err := s.repo.GetParametersByIDs(ctx, s.extractParameterIDsFromExperiment(experiment))
+	if err != nil {
+		return err
+	}
+	mapParameters := make(map[int]model.Parameter, len(parameters))
+	for _, parameter := range parameters {
+		mapParameters[int(parameter.ID)] = parameter
+	}
+
+	for _, variant := range experiment.Variants {
+		for _, variantParameter := range variant.Parameters {
+			parameter, ok := mapParameters[variantParameter.ParameterID]
+			if !ok {
+				return apperror.BadRequest("parameter '%s' of variant '%s' no longer exists", variantParameter.ParameterName, variant.Name)
+			}
+			if parameter.DataType != model.ParameterDataType(variantParameter.ParameterDataType) {
+				return apperror.BadRequest("parameter '%s' of variant '%s' changed data type from %s to %s", parameter.Name, variant.Name, variantParameter.ParameterDataType, parameter.DataType)
+			}
+			if err := validateVariantParameterValue(variant.Name, &parameter, variantParameter.RolloutValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createExperimentVariantsTx creates the variants of an experiment and their parameters using a transactional repository
+func createExperimentVariantsTx(ctx context.Context, txRepo repository.Repository, experimentID int, variants []dto.CreateExperimentVariantRequest, now int64) error {
+	for _, variantReq := range variants {
+		variant := &model.ExperimentVariant{
+			ExperimentID:      experimentID,
+			Name:              variantReq.Name,
+			Description:       variantReq.Description,
+			TrafficAllocation: variantReq.TrafficAllocation,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+
+		if err := txRepo.CreateExperimentVariant(ctx, variant); err != nil {
+			return fmt.Errorf("failed to create experiment variant: %w", err)
+		}
+
+		// Create variant parameters
+		for _, paramReq := range variantReq.Parameters {
+			parameter := &model.ExperimentVariantParameter{
+				ExperimentVariantID: variant.ID,
+				ParameterDataType:   paramReq.ParameterDataType,
+				ParameterID:         paramReq.ParameterID,
+				ParameterName:       paramReq.ParameterName,
+				RolloutValue:        paramReq.RolloutValue,
+				ExperimentID:        experimentID,
+				CreatedAt:           now,
+				UpdatedAt:           now,
+			}
+
+			if err := txRepo.CreateExperimentVariantParameter(ctx, parameter); err != nil {
+				return fmt.Errorf("failed to create experiment variant parameter: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// extractParameterIDsFromExperiment extracts unique parameter IDs from an experiment's variants
+func (s *service) extractParameterIDsFromExperiment(experiment *model.Experiment) []int {
+	parameterIDSSet := make(map[int]bool)
+	for _, variant := range experiment.Variants {
+		for _, parameter := range variant.Parameters {
+			parameterIDSSet[parameter.ParameterID] = true
+		}
+	}
+
+	parameterIDS := make([]int, 0, len(parameterIDSSet))
+	for parameterID := range parameterIDSSet {
+		parameterIDS = append(parameterIDS, parameterID)
+	}
+
+	return parameterIDS
+}
+
+// SimulateExperiment previews the variant a set of attributes would be assigned to. The experiment is
+// loaded from the database rather than from SDK storage, so drafts can be checked before they launch.
+func (s *service) SimulateExperiment(ctx context.Context, req *dto.SimulateExperimentRequest) (dto.SimulateExperimentResponse, error) {
+	logger := log.Ctx(ctx).With().Str("service", "simulate-experiment").Logger()
+
+	experiment, err := s.getExperimentForSimulation(ctx, req)
+	if err != nil {
+		return dto.SimulateExperimentResponse{}, err
+	}
+
+	sdkExperiment, err := mapper.ExperimentToSDKFromRawValue(experiment)
+	if err != nil {
+		return dto.SimulateExperimentResponse{}, fmt.Errorf("failed to convert experiment to SDK format: %w", err)
+	}
+
+	attribute := newSimulationAttribute(logger, req.Attributes)
+	result := s.auroraClient.EvaluateExperimentDetailed(ctx, &sdkExperiment, attribute, "")
+
+	logger.Info().Int("experimentId", experiment.ID).Bool("inPopulation", result.InPopulation).Msg("Simulated experiment")
+
+	return experimentSimulationResponse(experiment, &sdkExperiment, result), nil
+}
+
+// getExperimentForSimulation loads the experiment identified by the request, by ID or by UUID
+func (s *service) getExperimentForSimulation(ctx context.Context, req *dto.SimulateExperimentRequest) (*model.Experiment, error) {
+	id := req.ExperimentID
+	if req.ExperimentUUID != "" {
+		experiment, err := s.repo.GetExperimentByUuid(ctx, req.ExperimentUUID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, apperror.NotFound("experiment with UUID %s not found", req.ExperimentUUID)
+			}
+			return nil, fmt.Errorf("failed to get experiment: %w", err)
+		}
+		id = uint(experiment.ID)
+	}
+
+	experiment, err := s.repo.GetExperimentByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("experiment with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+	return experiment, nil
+}
+
+// experimentSimulationResponse reports the assigned variant and the parameter values it resolves
+func experimentSimulationResponse(experiment *model.Experiment, sdkExperiment *sdk.Experiment, result *sdk.ExperimentEvaluationResult) dto.SimulateExperimentResponse {
+	response := dto.SimulateExperimentResponse{
+		ExperimentID:   experiment.ID,
+		ExperimentUUID: experiment.Uuid,
+		InPopulation:   result.InPopulation,
+		VariantID:      result.VariantID,
+		VariantName:    result.VariantName,
+		Parameters:     []dto.SimulateExperimentParameterResponse{},
+	}
+	if result.VariantID == nil {
+		return response
+	}
+
+	for _, variant := range sdkExperiment.Variants {
+		if variant.ID != *result.VariantID {
+			continue
+		}
+		for _, parameter := range variant.Parameters {
+			response.Parameters = append(response.Parameters, dto.SimulateExperimentParameterResponse{
+				ParameterID:   parameter.ParameterID,
+				ParameterName: parameter.ParameterName,
+				DataType:      string(parameter.ParameterDataType),
+				Value:         parameter.RolloutValue,
+			})
+		}
+	}
+	return response
+}
+
+// GetExperimentExposures reports the configured traffic split of an experiment together with the exposures
+// observed in the ingested evaluation events over the filter's time range
+func (s *service) GetExperimentExposures(ctx context.Context, id uint, filter model.ExposureFilter) (dto.ExperimentExposureResponse, error) {
+	experiment, err := s.repo.GetExperimentByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ExperimentExposureResponse{}, apperror.NotFound("experiment with ID %d not found", id)
+		}
+		return dto.ExperimentExposureResponse{}, fmt.Errorf("failed to get experiment: %w", err)
+	}
+
+	var hashAttribute string
+	if experiment.HashAttribute != nil {
+		hashAttribute = experiment.HashAttribute.Name
+	}
+
+	exposures, err := s.eventService.GetExperimentExposures(ctx, experiment.ID, hashAttribute, filter)
+	if err != nil {
+		return dto.ExperimentExposureResponse{}, fmt.Errorf("failed to get experiment exposures: %w", err)
+	}
+
+	return experimentExposureResponse(experiment, hashAttribute, exposures), nil
+}
+
+// GetExperimentExposuresByUuid reports the exposures of an experiment like GetExperimentExposures, looking it up by UUID
+func (s *service) GetExperimentExposuresByUuid(ctx context.Context, experimentUUID string, filter model.ExposureFilter) (dto.ExperimentExposureResponse, error) {
+	if err := uuid.Validate(experimentUUID); err != nil {
+		return dto.ExperimentExposureResponse{}, apperror.BadRequest("invalid experiment UUID: %s", experimentUUID)
+	}
+
+	experiment, err := s.repo.GetExperimentByUuid(ctx, experimentUUID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ExperimentExposureResponse{}, apperror.NotFound("experiment with UUID %s not found", experimentUUID)
+		}
+		return dto.ExperimentExposureResponse{}, fmt.Errorf("failed to get experiment: %w", err)
+	}
+
+	return s.GetExperimentExposures(ctx, uint(experiment.ID), filter)
+}
+
+// experimentExposureResponse groups exposure rows by variant. Rows without a day hold the variant totals.
+func experimentExposureResponse(experiment *model.Experiment, hashAttribute string, exposures []model.ExperimentExposure) dto.ExperimentExposureResponse {
+	response := dto.ExperimentExposureResponse{
+		ExperimentID:   experiment.ID,
+		PopulationSize: experiment.PopulationSize,
+		HashAttribute:  hashAttribute,
+		Variants:       make([]dto.ExperimentVariantAllocationResponse, 0, len(experiment.Variants)),
+		Exposures:      []dto.ExperimentVariantExposureResponse{},
+	}
+
+	variantNames := make(map[int]string, len(experiment.Variants))
+	for _, variant := range experiment.Variants {
+		variantNames[variant.ID] = variant.Name
+		response.Variants = append(response.Variants, dto.ExperimentVariantAllocationResponse{
+			VariantID:         variant.ID,
+			VariantName:       variant.Name,
+			TrafficAllocation: variant.TrafficAllocation,
+		})
+	}
+
+	indexes := make(map[int]int)
+	for _, exposure := range exposures {
+		index, ok := indexes[exposure.VariantID]
+		if !ok {
+			index = len(response.Exposures)
+			indexes[exposure.VariantID] = index
+			response.Exposures = append(response.Exposures, dto.ExperimentVariantExposureResponse{
+				VariantID:   exposure.VariantID,
+				VariantName: variantNames[exposure.VariantID],
+				Daily:       []dto.ExperimentExposureDayResponse{},
+			})
+		}
+
+		variant := &response.Exposures[index]
+		if exposure.Day == nil {
+			variant.Users = exposure.Users
+			variant.Events = exposure.Events
+			continue
+		}
+		variant.Daily = append(variant.Daily, dto.ExperimentExposureDayResponse{
+			Date:   exposure.Day.Format(time.DateOnly),
+			Users:  exposure.Users,
+			Events: exposure.Events,
+		})
+	}
+	return response
+}
+
+// FindExperimentConflicts returns the scheduled, running or paused experiments that share a parameter with an
+// experiment targeting segmentID in environmentID from startDate to endDate, run in the same environment during
+// that period and target overlapping users. An experiment without an environment shares every environment.
+// The experiment with ID excludeID, if any, is left out so an experiment is not reported as conflicting with itself.
+func (s *service) FindExperimentConflicts(ctx context.Context, parameterIDs []int, segmentID int, environmentID *int, startDate, endDate int64, excludeID int) ([]apperror.ExperimentConflict, error) {
+	candidates, err := s.repo.FindConflictingExperiments(ctx, parameterIDs, segmentID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for conflicting experiments: %w", err)
+	}
+
+	conflicts := make([]apperror.ExperimentConflict, 0)
+	for _, exp := range candidates {
+		if exp.ID == excludeID || !sharesEnvironment(environmentID, exp.EnvironmentID) {
+			continue
+		}
+		hasOverlap, _, err := s.checkSegmentOverlap(ctx, segmentID, exp.SegmentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check segment overlap: %w", err)
+		}
+		if !hasOverlap {
+			continue
+		}
+		conflicts = append(conflicts, apperror.ExperimentConflict{
+			ID:           exp.ID,
+			Name:         exp.Name,
+			Status:       exp.Status,
+			SegmentID:    exp.SegmentID,
+			StartDate:    exp.StartDate,
+			EndDate:      exp.EndDate,
+			OverlapStart: max(startDate, exp.StartDate),
+			OverlapEnd:   min(endDate, exp.EndDate),
+		})
+	}
+	return conflicts, nil
+}
+
+// CheckExperimentConflicts reports the experiments an experiment would conflict with, without creating or changing anything
+func (s *service) CheckExperimentConflicts(ctx context.Context, req *dto.CheckExperimentConflictsRequest) (dto.ExperimentConflictsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return dto.ExperimentConflictsResponse{}, apperror.BadRequest("invalid conflict check: %v", err)
+	}
+
+	if req.SegmentID != 0 {
+		if _, err := s.repo.GetSegmentByID(ctx, uint(req.SegmentID)); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return dto.ExperimentConflictsResponse{}, apperror.NotFound("segment with id %d not found", req.SegmentID)
+			}
+			return dto.ExperimentConflictsResponse{}, fmt.Errorf("failed to get segment: %w", err)
+		}
+	}
+
+	if err := s.ensureEnvironmentExists(ctx, req.EnvironmentID); err != nil {
+		return dto.ExperimentConflictsResponse{}, err
+	}
+
+	parameterIDs := make([]int, 0, len(req.ParameterIDs))
+	seen := make(map[int]bool, len(req.ParameterIDs))
+	for _, id := range req.ParameterIDs {
+		if !seen[id] {
+			seen[id] = true
+			parameterIDs = append(parameterIDs, id)
+		}
+	}
+
+	conflicts, err := s.FindExperimentConflicts(ctx, parameterIDs, req.SegmentID, req.EnvironmentID, req.StartDate, req.EndDate, req.ExcludeExperimentID)
+	if err != nil {
+		return dto.ExperimentConflictsResponse{}, err
+	}
+	return dto.ExperimentConflictsResponse{Conflicts: dto.ToConflictingExperimentResponses(conflicts)}, nil
 }
@@ -0,0 +1,462 @@
+package service
+
+import (
+	"api/config"
+	"api/internal/apperror"
+	"api/internal/constant"
+	"api/internal/dto"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"database/sql/driver"
+	"errors"
+	sdk "sdk/types"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCreateExperimentCountsSharedParameterUsageOnce(t *testing.T) {
+	connector := &recordingConnector{rows: storedRows(map[string]storedRow{
+		"attributes": {[]string{"id", "name", "data_type"}, []driver.Value{int64(5), "userId", "string"}},
+		"parameters": {[]string{"id", "name", "data_type"}, []driver.Value{int64(7), "checkout_button_color", "string"}},
+		"experiments": {
+			[]string{"id", "name", "status", "raw_value"},
+			[]driver.Value{int64(1), "checkout button", constant.ExperimentStatusDraft, []byte(`{"id":1}`)},
+		},
+	})}
+	s := newRecordingService(t, connector)
+
+	// Two variants sharing a parameter use it once, so its usage count is incremented once
+	variant := func(name, color string, allocation int) dto.CreateExperimentVariantRequest {
+		return dto.CreateExperimentVariantRequest{
+			Name: name, Description: name, TrafficAllocation: allocation,
+			Parameters: []dto.CreateExperimentVariantParameterRequest{
+				{ParameterDataType: "string", ParameterID: 7, ParameterName: "checkout_button_color", RolloutValue: color},
+			},
+		}
+	}
+	_, err := s.CreateExperiment(context.Background(), 3, &dto.CreateExperimentRequest{
+		Name: "checkout button", Hypothesis: "green converts", Description: "button color",
+		StartDate: time.Now().Add(time.Hour).Unix(), EndDate: time.Now().Add(48 * time.Hour).Unix(),
+		HashAttributeID: 5, PopulationSize: 100, Strategy: "percentage_split",
+		Variants: []dto.CreateExperimentVariantRequest{variant("control", "blue", 50), variant("treatment", "green", 50)},
+	})
+	require.NoError(t, err)
+
+	var increments [][]driver.Value
+	for i, query := range connector.committed {
+		if strings.HasPrefix(query, `UPDATE "parameters" SET "usage_count"=(usage_count + `) {
+			increments = append(increments, connector.committedArgs[i])
+		}
+	}
+	require.Equal(t, [][]driver.Value{{int64(1), int64(7)}}, increments)
+}
+
+func TestExperimentSimulationResponseResolvesAssignedVariantParameters(t *testing.T) {
+	experiment := &model.Experiment{ID: 3, Uuid: "exp-uuid"}
+	sdkExperiment := &sdk.Experiment{
+		Variants: []sdk.ExperimentVariant{
+			{ID: 10, Name: "control", Parameters: []sdk.ExperimentVariantParameter{
+				{ParameterID: 7, ParameterName: "checkout_button_color", ParameterDataType: sdk.ParameterDataTypeString, RolloutValue: "blue"},
+			}},
+			{ID: 11, Name: "treatment", Parameters: []sdk.ExperimentVariantParameter{
+				{ParameterID: 7, ParameterName: "checkout_button_color", ParameterDataType: sdk.ParameterDataTypeString, RolloutValue: "green"},
+			}},
+		},
+	}
+	variantID, variantName := 11, "treatment"
+
+	response := experimentSimulationResponse(experiment, sdkExperiment, &sdk.ExperimentEvaluationResult{
+		InPopulation: true,
+		VariantID:    &variantID,
+		VariantName:  &variantName,
+	})
+
+	require.True(t, response.InPopulation)
+	require.Equal(t, "treatment", *response.VariantName)
+	require.Equal(t, []dto.SimulateExperimentParameterResponse{
+		{ParameterID: 7, ParameterName: "checkout_button_color", DataType: "string", Value: "green"},
+	}, response.Parameters)
+
+	response = experimentSimulationResponse(experiment, sdkExperiment, &sdk.ExperimentEvaluationResult{})
+	require.False(t, response.InPopulation)
+	require.Nil(t, response.VariantID)
+	require.Empty(t, response.Parameters)
+}
+
+func TestGetAllExperimentsRejectsUnknownStatus(t *testing.T) {
+	s := &service{}
+
+	_, _, err := s.GetAllExperiments(context.Background(), model.ExperimentFilter{Statuses: []string{"running", "launched"}}, 0, 0)
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+}
+
+func TestGetAllExperimentsRejectsEmptyDateRange(t *testing.T) {
+	s := &service{}
+
+	_, _, err := s.GetAllExperiments(context.Background(), model.ExperimentFilter{StartAfter: 200, EndBefore: 100}, 0, 0)
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+	require.ErrorContains(t, err, "startAfter must be before endBefore")
+}
+
+func TestUpdateExperimentVariantsRejectsAllocationNotSummingTo100(t *testing.T) {
+	s := &service{}
+	parameters := []dto.CreateExperimentVariantParameterRequest{
+		{ParameterDataType: "string", ParameterID: 7, ParameterName: "checkout_button_color", RolloutValue: "blue"},
+	}
+
+	_, err := s.UpdateExperimentVariants(context.Background(), 3, 1, &dto.UpdateExperimentVariantsRequest{
+		Variants: []dto.CreateExperimentVariantRequest{
+			{Name: "control", Description: "current color", TrafficAllocation: 50, Parameters: parameters},
+			{Name: "treatment", Description: "new color", TrafficAllocation: 40, Parameters: parameters},
+		},
+	})
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+}
+
+func TestExperimentExposureResponseGroupsRowsByVariant(t *testing.T) {
+	experiment := &model.Experiment{
+		ID:             3,
+		PopulationSize: 50,
+		Variants: []model.ExperimentVariant{
+			{ID: 10, Name: "control", TrafficAllocation: 50},
+			{ID: 11, Name: "treatment", TrafficAllocation: 50},
+		},
+	}
+	day1 := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	response := experimentExposureResponse(experiment, "user_id", []model.ExperimentExposure{
+		{VariantID: 10, Users: 3, Events: 7},
+		{VariantID: 10, Day: &day1, Users: 2, Events: 4},
+		{VariantID: 10, Day: &day2, Users: 2, Events: 3},
+		{VariantID: 11, Users: 1, Events: 1},
+		{VariantID: 11, Day: &day2, Users: 1, Events: 1},
+	})
+
+	require.Equal(t, 50, response.PopulationSize)
+	require.Len(t, response.Variants, 2)
+	require.Equal(t, []dto.ExperimentVariantExposureResponse{
+		{VariantID: 10, VariantName: "control", Users: 3, Events: 7, Daily: []dto.ExperimentExposureDayResponse{
+			{Date: "2025-11-01", Users: 2, Events: 4},
+			{Date: "2025-11-02", Users: 2, Events: 3},
+		}},
+		{VariantID: 11, VariantName: "treatment", Users: 1, Events: 1, Daily: []dto.ExperimentExposureDayResponse{
+			{Date: "2025-11-02", Users: 1, Events: 1},
+		}},
+	}, response.Exposures)
+}
+
+func TestExperimentExposureResponseWithoutEventsIsEmpty(t *testing.T) {
+	response := experimentExposureResponse(&model.Experiment{ID: 3}, "user_id", nil)
+
+	require.NotNil(t, response.Exposures)
+	require.Empty(t, response.Exposures)
+}
+
+// conflictCandidateRepository returns fixed conflict candidates and records the parameters it was searched with
+type conflictCandidateRepository struct {
+	repository.Repository
+	parameterIDs []int
+}
+
+func (r *conflictCandidateRepository) FindConflictingExperiments(_ context.Context, parameterIDs []int, _ int, _, _ int64) ([]*model.Experiment, error) {
+	r.parameterIDs = parameterIDs
+	return []*model.Experiment{
+		{ID: 3, Name: "editing", Status: "schedule", StartDate: 100, EndDate: 300},
+		{ID: 4, Name: "checkout", Status: "running", StartDate: 50, EndDate: 150},
+	}, nil
+}
+
+func TestCheckExperimentConflictsReportsOverlapAndExcludesEditedExperiment(t *testing.T) {
+	repo := &conflictCandidateRepository{}
+	s := &service{repo: repo}
+
+	response, err := s.CheckExperimentConflicts(context.Background(), &dto.CheckExperimentConflictsRequest{
+		ParameterIDs: []int{7, 7, 8}, StartDate: 100, EndDate: 200, ExcludeExperimentID: 3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{7, 8}, repo.parameterIDs)
+	require.Equal(t, []dto.ConflictingExperimentResponse{
+		{ID: 4, Name: "checkout", Status: "running", StartDate: 50, EndDate: 150, OverlapStart: 100, OverlapEnd: 150},
+	}, response.Conflicts)
+
+	_, err = s.CheckExperimentConflicts(context.Background(), &dto.CheckExperimentConflictsRequest{
+		ParameterIDs: []int{7}, StartDate: 200, EndDate: 100,
+	})
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+}
+
+// environmentCandidateRepository returns conflict candidates limited to different environments
+type environmentCandidateRepository struct {
+	repository.Repository
+}
+
+func (environmentCandidateRepository) FindConflictingExperiments(context.Context, []int, int, int64, int64) ([]*model.Experiment, error) {
+	dev, prod := 1, 2
+	return []*model.Experiment{
+		{ID: 5, Name: "everywhere", Status: "running", StartDate: 100, EndDate: 200},
+		{ID: 6, Name: "dev only", Status: "running", StartDate: 100, EndDate: 200, EnvironmentID: &dev},
+		{ID: 7, Name: "prod only", Status: "running", StartDate: 100, EndDate: 200, EnvironmentID: &prod},
+	}, nil
+}
+
+func TestFindExperimentConflictsIgnoresExperimentsOfOtherEnvironments(t *testing.T) {
+	s := &service{repo: environmentCandidateRepository{}}
+	names := func(environmentID *int) []string {
+		conflicts, err := s.FindExperimentConflicts(context.Background(), []int{1}, 0, environmentID, 100, 200, 0)
+		require.NoError(t, err)
+		var names []string
+		for _, conflict := range conflicts {
+			names = append(names, conflict.Name)
+		}
+		return names
+	}
+
+	prod := 2
+	require.Equal(t, []string{"everywhere", "prod only"}, names(&prod))
+	require.Equal(t, []string{"everywhere", "dev only", "prod only"}, names(nil))
+}
+
+// missingExperimentRepository finds no experiment by UUID
+type missingExperimentRepository struct {
+	repository.Repository
+}
+
+func (missingExperimentRepository) GetExperimentByUuid(context.Context, string) (*model.Experiment, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestGetExperimentByUuidValidatesAndReportsMissingExperiments(t *testing.T) {
+	s := &service{repo: missingExperimentRepository{}}
+
+	_, _, _, _, err := s.GetExperimentByUuid(context.Background(), "not-a-uuid")
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+
+	_, _, _, _, err = s.GetExperimentByUuid(context.Background(), "4f7c2a3e-9b1d-4c8e-a6f5-2d3b9e8c1a70")
+	require.ErrorIs(t, err, apperror.ErrNotFound)
+}
+
+func TestValidateVariantParameterValue(t *testing.T) {
+	min := 1.0
+	tests := []struct {
+		name      string
+		parameter model.Parameter
+		value     string
+		wantErr   string
+	}{
+		{name: "string", parameter: model.Parameter{DataType: model.ParameterDataTypeString}, value: "hello"},
+		{name: "empty string", parameter: model.Parameter{DataType: model.ParameterDataTypeString}, value: "", wantErr: "must not be empty"},
+		{name: "number", parameter: model.Parameter{DataType: model.ParameterDataTypeNumber}, value: "2.5"},
+		{name: "non-numeric number", parameter: model.Parameter{DataType: model.ParameterDataTypeNumber}, value: "abc", wantErr: "must be a number"},
+		{name: "number below min", parameter: model.Parameter{DataType: model.ParameterDataTypeNumber, NumberConstraints: &model.NumberConstraints{Min: &min}}, value: "0", wantErr: "at least 1"},
+		{name: "boolean", parameter: model.Parameter{DataType: model.ParameterDataTypeBoolean}, value: "true"},
+		{name: "non-boolean boolean", parameter: model.Parameter{DataType: model.ParameterDataTypeBoolean}, value: "yes", wantErr: "true or false"},
+		{name: "enum", parameter: model.Parameter{DataType: model.ParameterDataTypeEnum, EnumOptions: []string{"light", "dark"}}, value: "dark"},
+		{name: "unknown enum option", parameter: model.Parameter{DataType: model.ParameterDataTypeEnum, EnumOptions: []string{"light", "dark"}}, value: "blue", wantErr: "one of [light, dark]"},
+		{name: "date", parameter: model.Parameter{DataType: model.ParameterDataTypeDate}, value: "2025-01-02T03:04:05Z"},
+		{name: "invalid date", parameter: model.Parameter{DataType: model.ParameterDataTypeDate}, value: "tomorrow", wantErr: "RFC3339"},
+		{name: "json", parameter: model.Parameter{DataType: model.ParameterDataTypeJSON}, value: `{"layout":"grid"}`},
+		{name: "invalid json", parameter: model.Parameter{DataType: model.ParameterDataTypeJSON}, value: `{"layout":`, wantErr: "JSON object or array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.parameter.Name = "param"
+			err := validateVariantParameterValue("treatment", &tt.parameter, tt.value)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, apperror.ErrBadRequest)
+			require.ErrorContains(t, err, "parameter 'param' in variant 'treatment'")
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// changedParameterRepository serves a draft experiment whose number parameter has since become a string parameter
+type changedParameterRepository struct {
+	repository.Repository
+}
+
+func (changedParameterRepository) GetExperimentByID(context.Context, uint) (*model.Experiment, error) {
+	return &model.Experiment{ID: 1, Status: "draft", Variants: []model.ExperimentVariant{{
+		Name:       "treatment",
+		Parameters: []model.ExperimentVariantParameter{{ParameterID: 7, ParameterName: "max_retries", ParameterDataType: "number", RolloutValue: "3"}},
+	}}}, nil
+}
+
+func (changedParameterRepository) GetParametersByIDs(context.Context, []int) ([]model.Parameter, error) {
+	return []model.Parameter{{ID: 7, Name: "max_retries", DataType: model.ParameterDataTypeString}}, nil
+}
+
+func TestApproveExperimentRevalidatesVariantParameters(t *testing.T) {
+	s := &service{repo: changedParameterRepository{}}
+
+	_, err := s.ApproveExperiment(context.Background(), 1, 1, &dto.ApproveExperimentRequest{})
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+	require.ErrorContains(t, err, "parameter 'max_retries' of variant 'treatment' changed data type from number to string")
+}
+
+// endedExperimentRepository holds a running experiment that ended an hour ago. Writes are not
+// implemented, so they fail the test.
+type endedExperimentRepository struct {
+	repository.Repository
+}
+
+func (endedExperimentRepository) GetExperimentByID(_ context.Context, id uint) (*model.Experiment, error) {
+	return &model.Experiment{ID: int(id), Status: constant.ExperimentStatusRunning, EndDate: time.Now().Add(-time.Hour).Unix()}, nil
+}
+
+func TestGetExperimentByIDReportsDueStatusWithoutWriting(t *testing.T) {
+	s := &service{repo: endedExperimentRepository{}, cfg: &config.Config{}}
+
+	experiment, _, _, _, err := s.GetExperimentByID(context.Background(), 4)
+	require.NoError(t, err)
+	require.Equal(t, constant.ExperimentStatusFinish, experiment.Status)
+}
+
+// overriddenExperimentRepository holds an experiment with a full set of overrides. Writes are not
+// implemented, so they fail the test.
+type overriddenExperimentRepository struct {
+	repository.Repository
+	status string
+}
+
+func (r overriddenExperimentRepository) GetExperimentByID(_ context.Context, id uint) (*model.Experiment, error) {
+	experiment := &model.Experiment{ID: int(id), Status: r.status, Variants: []model.ExperimentVariant{{ID: 1}, {ID: 2}}}
+	for i := 0; i < maxExperimentOverrides; i++ {
+		experiment.Overrides = append(experiment.Overrides, model.ExperimentOverride{ID: uint(i + 1), AttributeValue: strconv.Itoa(i), VariantID: 1})
+	}
+	return experiment, nil
+}
+
+func TestSetExperimentOverrideValidatesRequest(t *testing.T) {
+	ctx := context.Background()
+	s := &service{repo: overriddenExperimentRepository{status: constant.ExperimentStatusRunning}}
+
+	_, err := s.SetExperimentOverride(ctx, 1, &dto.SetExperimentOverrideRequest{AttributeValue: " ", VariantID: 1})
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+
+	_, err = s.SetExperimentOverride(ctx, 1, &dto.SetExperimentOverrideRequest{AttributeValue: "0", VariantID: 3})
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+	require.ErrorContains(t, err, "variant with ID 3 does not belong to experiment 1")
+
+	_, err = s.SetExperimentOverride(ctx, 1, &dto.SetExperimentOverrideRequest{AttributeValue: "qa", VariantID: 2})
+	require.ErrorIs(t, err, apperror.ErrConflict)
+	require.ErrorContains(t, err, "maximum of 100 overrides")
+
+	err = s.DeleteExperimentOverride(ctx, 1, maxExperimentOverrides+1)
+	require.ErrorIs(t, err, apperror.ErrNotFound)
+
+	s = &service{repo: overriddenExperimentRepository{status: constant.ExperimentStatusFinish}}
+	_, err = s.SetExperimentOverride(ctx, 1, &dto.SetExperimentOverrideRequest{AttributeValue: "0", VariantID: 2})
+	require.ErrorIs(t, err, apperror.ErrConflict)
+}
+
+// newExperimentStatusService serves an experiment with the given status and end date through a recording
+// database, with the webhook notified of every status change
+func newExperimentStatusService(t *testing.T, status string, endDate int64) (*service, *recordingConnector) {
+	connector := &recordingConnector{rows: func(query string, _ []driver.Value) ([]string, [][]driver.Value) {
+		if !strings.HasPrefix(query, `SELECT * FROM "experiments"`) {
+			return nil, nil
+		}
+		return []string{"id", "name", "status", "start_date", "end_date"},
+			[][]driver.Value{{int64(7), "checkout button", status, time.Now().Add(-24 * time.Hour).Unix(), endDate}}
+	}}
+	s := newRecordingService(t, connector)
+	s.cfg = &config.Config{}
+	s.cfg.Webhook.URL = "https://hooks.example.com/aurora"
+	return s, connector
+}
+
+func TestPauseExperimentRecordsAuditAndNotifies(t *testing.T) {
+	s, connector := newExperimentStatusService(t, constant.ExperimentStatusRunning, time.Now().Add(time.Hour).Unix())
+
+	experiment, err := s.PauseExperiment(context.Background(), 7, 3, &dto.PauseExperimentRequest{Reason: "incident"})
+	require.NoError(t, err)
+	require.Equal(t, constant.ExperimentStatusPause, experiment.Status)
+	require.Equal(t, uint(3), *experiment.UpdatedByUserID)
+
+	auditLogs := connector.inserted("audit_logs")
+	require.Len(t, auditLogs, 1)
+	require.Equal(t, string(model.AuditActionPause), auditLogs[0]["action"])
+	require.Equal(t, int64(3), auditLogs[0]["actor_user_id"])
+	require.ElementsMatch(t, []string{"sync_experiment", "experiment_status_webhook"}, connector.enqueuedJobs())
+}
+
+func TestResumeExperimentRecordsAuditAndNotifies(t *testing.T) {
+	tests := []struct {
+		name       string
+		endDate    int64
+		wantStatus string
+	}{
+		{"before end date", time.Now().Add(time.Hour).Unix(), constant.ExperimentStatusRunning},
+		{"after end date", time.Now().Add(-time.Hour).Unix(), constant.ExperimentStatusFinish},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, connector := newExperimentStatusService(t, constant.ExperimentStatusPause, tt.endDate)
+
+			experiment, err := s.ResumeExperiment(context.Background(), 7, 3, &dto.ResumeExperimentRequest{})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, experiment.Status)
+
+			auditLogs := connector.inserted("audit_logs")
+			require.Len(t, auditLogs, 1)
+			require.Equal(t, string(model.AuditActionResume), auditLogs[0]["action"])
+			require.ElementsMatch(t, []string{"sync_experiment", "experiment_status_webhook"}, connector.enqueuedJobs())
+		})
+	}
+}
+
+func TestPauseAndResumeRejectInvalidStatus(t *testing.T) {
+	s, connector := newExperimentStatusService(t, constant.ExperimentStatusDraft, time.Now().Add(time.Hour).Unix())
+
+	_, err := s.PauseExperiment(context.Background(), 7, 3, &dto.PauseExperimentRequest{})
+	require.ErrorIs(t, err, apperror.ErrConflict)
+	_, err = s.ResumeExperiment(context.Background(), 7, 3, &dto.ResumeExperimentRequest{})
+	require.ErrorIs(t, err, apperror.ErrConflict)
+
+	require.Empty(t, connector.inserted("audit_logs"))
+	require.Empty(t, connector.enqueuedJobs())
+}
+
+func TestPauseExperimentRollsBackStatusTransition(t *testing.T) {
+	running := storedRow{
+		[]string{"id", "name", "status", "raw_value"},
+		[]driver.Value{int64(7), "checkout button", constant.ExperimentStatusRunning, []byte(`{"id":7}`)},
+	}
+
+	for _, failSync := range []bool{false, true} {
+		connector := &recordingConnector{rows: storedRows(map[string]storedRow{"experiments": running})}
+		if failSync {
+			connector.fail = func(query string, _ []driver.Value) error {
+				if strings.HasPrefix(query, "INSERT INTO river_job") {
+					return errors.New("queue unavailable")
+				}
+				return nil
+			}
+		}
+		s := newRecordingService(t, connector)
+		s.cfg = &config.Config{}
+
+		_, err := s.PauseExperiment(context.Background(), 7, 3, &dto.PauseExperimentRequest{Reason: "incident"})
+		transitions := connector.inserted("experiment_status_transitions")
+		if failSync {
+			// The transition is part of the status change, so it is not kept once the change rolls back
+			require.Error(t, err)
+			require.Empty(t, transitions)
+			continue
+		}
+		require.NoError(t, err)
+		require.Len(t, transitions, 1)
+		require.Equal(t, constant.ExperimentStatusRunning, transitions[0]["from_status"])
+		require.Equal(t, constant.ExperimentStatusPause, transitions[0]["to_status"])
+	}
+}
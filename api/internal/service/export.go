@@ -0,0 +1,186 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/constant"
+	"api/internal/dto"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// pointInTimeBatchSize is the number of rows loaded per query while streaming the export
+const pointInTimeBatchSize = 200
+
+// ExportPointInTime streams a JSON document describing the parameter configurations and
+// experiment statuses as they were at the given time. Parameters are taken from the
+// nearest version snapshot at or before that time, experiment statuses are derived from
+// the status transition log, and a generation report lists entities whose recorded
+// history does not reach back far enough.
+func (s *service) ExportPointInTime(ctx context.Context, at time.Time, w io.Writer) error {
+	if at.After(time.Now()) {
+		return apperror.BadRequest("invalid timestamp: must not be in the future")
+	}
+
+	report := dto.PointInTimeReport{
+		IncompleteParameterIDs:        []uint{},
+		IncompleteExperimentIDs:       []int{},
+		ParametersFromCurrentRawValue: []uint{},
+	}
+	encoder := json.NewEncoder(w)
+
+	if _, err := fmt.Fprintf(w, `{"at":%q,"generatedAt":%q,"parameters":[`, at.UTC().Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+
+	var lastParameterID uint
+	for {
+		parameters, err := s.repo.GetParametersCreatedBefore(ctx, at, lastParameterID, pointInTimeBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get parameters: %w", err)
+		}
+
+		for _, parameter := range parameters {
+			entry, err := s.parameterAt(ctx, parameter.ID, parameter.Name, parameter.RawValue, at, &report)
+			if err != nil {
+				return err
+			}
+			if err := writeArrayElement(w, encoder, report.ParameterCount, entry); err != nil {
+				return err
+			}
+			report.ParameterCount++
+		}
+
+		if len(parameters) < pointInTimeBatchSize {
+			break
+		}
+		lastParameterID = parameters[len(parameters)-1].ID
+	}
+
+	if _, err := io.WriteString(w, `],"experiments":[`); err != nil {
+		return err
+	}
+
+	var lastExperimentID int
+	for {
+		experiments, err := s.repo.GetExperimentsCreatedBefore(ctx, at.Unix(), lastExperimentID, pointInTimeBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get experiments: %w", err)
+		}
+
+		for _, experiment := range experiments {
+			entry := dto.PointInTimeExperiment{
+				ID:              experiment.ID,
+				Name:            experiment.Name,
+				Uuid:            experiment.Uuid,
+				HistoryComplete: true,
+			}
+
+			status, complete, err := s.experimentStatusAt(ctx, experiment.ID, experiment.Status, at.Unix())
+			if err != nil {
+				return err
+			}
+			entry.Status = status
+			if !complete {
+				entry.HistoryComplete = false
+				report.IncompleteExperimentIDs = append(report.IncompleteExperimentIDs, experiment.ID)
+			}
+
+			if err := writeArrayElement(w, encoder, report.ExperimentCount, entry); err != nil {
+				return err
+			}
+			report.ExperimentCount++
+		}
+
+		if len(experiments) < pointInTimeBatchSize {
+			break
+		}
+		lastExperimentID = experiments[len(experiments)-1].ID
+	}
+
+	if _, err := io.WriteString(w, `],"report":`); err != nil {
+		return err
+	}
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// parameterAt resolves a parameter's raw_value at the given time
+func (s *service) parameterAt(ctx context.Context, id uint, name string, currentRawValue json.RawMessage, at time.Time, report *dto.PointInTimeReport) (dto.PointInTimeParameter, error) {
+	entry := dto.PointInTimeParameter{ID: id, Name: name, Source: "version", HistoryComplete: true}
+
+	version, err := s.repo.GetParameterVersionAt(ctx, id, at)
+	if err == nil {
+		entry.RawValue = version.RawValue
+		return entry, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entry, fmt.Errorf("failed to get parameter version: %w", err)
+	}
+
+	hasVersions, err := s.repo.HasParameterVersions(ctx, id)
+	if err != nil {
+		return entry, fmt.Errorf("failed to check parameter versions: %w", err)
+	}
+
+	if hasVersions {
+		// The parameter changed after the requested time but its earlier configuration
+		// predates version tracking, so it cannot be reconstructed
+		entry.Source = "unknown"
+		entry.RawValue = json.RawMessage("null")
+		entry.HistoryComplete = false
+		report.IncompleteParameterIDs = append(report.IncompleteParameterIDs, id)
+		return entry, nil
+	}
+
+	// Never versioned, so the configuration has not changed since version tracking started
+	entry.Source = "current"
+	entry.RawValue = currentRawValue
+	if len(entry.RawValue) == 0 {
+		entry.RawValue = json.RawMessage("null")
+	}
+	report.ParametersFromCurrentRawValue = append(report.ParametersFromCurrentRawValue, id)
+	return entry, nil
+}
+
+// experimentStatusAt derives an experiment's status at the given unix time from the transition log.
+// It reports false when the log does not cover the requested time.
+func (s *service) experimentStatusAt(ctx context.Context, id int, currentStatus string, at int64) (string, bool, error) {
+	transition, err := s.repo.GetExperimentStatusTransitionAt(ctx, id, at)
+	if err == nil {
+		return transition.ToStatus, true, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, fmt.Errorf("failed to get experiment status transition: %w", err)
+	}
+
+	next, err := s.repo.GetFirstExperimentStatusTransitionAfter(ctx, id, at)
+	if err == nil {
+		// The status held until the next transition is the one it transitioned from
+		return next.FromStatus, next.FromStatus == constant.ExperimentStatusDraft, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, fmt.Errorf("failed to get experiment status transition: %w", err)
+	}
+
+	// No transitions at all: a draft experiment has never changed status
+	return currentStatus, currentStatus == constant.ExperimentStatusDraft, nil
+}
+
+// writeArrayElement encodes a JSON array element, prefixing a separator after the first element
+func writeArrayElement(w io.Writer, encoder *json.Encoder, index int, value interface{}) error {
+	if index > 0 {
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+	return encoder.Encode(value)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"api/internal/dto"
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog/log"
+)
+
+// healthProbeTimeout bounds each dependency check, so load balancers get an answer quickly
+const healthProbeTimeout = time.Second
+
+// bucketHeader checks that an S3 bucket exists and can be accessed
+type bucketHeader interface {
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+}
+
+// HealthCheck pings the database, reads river's default queue and, when S3 sync is enabled, heads the bucket
+func (s *service) HealthCheck(ctx context.Context) dto.HealthCheckResponse {
+	response := dto.HealthCheckResponse{
+		Status: dto.HealthStatusOK,
+		Checks: dto.HealthChecks{
+			DB:    s.probe(ctx, "database", s.pingDB),
+			River: s.probe(ctx, "river", s.pingRiver),
+		},
+	}
+	if s.s3Client != nil {
+		response.Checks.S3 = s.probe(ctx, "s3", s.headBucket)
+	}
+
+	for _, status := range []string{response.Checks.DB, response.Checks.River, response.Checks.S3} {
+		if status == dto.HealthStatusUnavailable {
+			response.Status = dto.HealthStatusUnavailable
+		}
+	}
+	return response
+}
+
+// probe runs a dependency check within healthProbeTimeout and returns the status of the dependency
+func (s *service) probe(ctx context.Context, name string, check func(ctx context.Context) error) string {
+	ctx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+	defer cancel()
+
+	if err := check(ctx); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("service", "health-check").Str("dependency", name).Msg("Health check failed")
+		return dto.HealthStatusUnavailable
+	}
+	return dto.HealthStatusOK
+}
+
+// pingDB checks that a connection to the database can be established
+func (s *service) pingDB(ctx context.Context) error {
+	sqlDB, err := s.repo.GetDB().DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// pingRiver checks that river's tables can be read without inserting a job
+func (s *service) pingRiver(ctx context.Context) error {
+	_, err := s.riverClient.QueueGet(ctx, river.QueueDefault)
+	return err
+}
+
+// headBucket checks that the bucket parameters and experiments are synced to is reachable
+func (s *service) headBucket(ctx context.Context) error {
+	_, err := s.s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.cfg.S3.BucketName)})
+	return err
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"api/config"
+	"api/internal/dto"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableQueueInserter fails to read river queues, as when its tables are unavailable
+type unreachableQueueInserter struct {
+	txJobInserter
+}
+
+func (unreachableQueueInserter) QueueGet(context.Context, string) (*rivertype.Queue, error) {
+	return nil, errors.New("relation \"river_queue\" does not exist")
+}
+
+// missingBucket fails to head the S3 bucket, as when it was deleted or credentials expired
+type missingBucket struct{}
+
+func (missingBucket) HeadBucket(context.Context, *s3.HeadBucketInput, ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	return nil, errors.New("NotFound: bucket does not exist")
+}
+
+func TestHealthCheckReportsHealthyDependencies(t *testing.T) {
+	s := newRecordingService(t, &recordingConnector{})
+
+	response := s.HealthCheck(context.Background())
+	require.Equal(t, dto.HealthCheckResponse{
+		Status: dto.HealthStatusOK,
+		Checks: dto.HealthChecks{DB: dto.HealthStatusOK, River: dto.HealthStatusOK},
+	}, response)
+}
+
+func TestHealthCheckReportsUnreachableRiverQueue(t *testing.T) {
+	s := newRecordingService(t, &recordingConnector{})
+	s.riverClient = unreachableQueueInserter{}
+
+	response := s.HealthCheck(context.Background())
+	require.Equal(t, dto.HealthStatusUnavailable, response.Status)
+	require.Equal(t, dto.HealthStatusOK, response.Checks.DB)
+	require.Equal(t, dto.HealthStatusUnavailable, response.Checks.River)
+}
+
+func TestHealthCheckReportsUnreachableS3Bucket(t *testing.T) {
+	s := newRecordingService(t, &recordingConnector{})
+	s.cfg = &config.Config{}
+	s.s3Client = missingBucket{}
+
+	response := s.HealthCheck(context.Background())
+	require.Equal(t, dto.HealthCheckResponse{
+		Status: dto.HealthStatusUnavailable,
+		Checks: dto.HealthChecks{DB: dto.HealthStatusOK, River: dto.HealthStatusOK, S3: dto.HealthStatusUnavailable},
+	}, response)
+}
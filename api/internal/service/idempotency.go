@@ -0,0 +1,46 @@
+package service
+
+import (
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// idempotencyKeyTTL is how long a repeated Idempotency-Key returns the resource it created
+const idempotencyKeyTTL = 24 * time.Hour
+
+// findIdempotentResource returns the ID of the resource created in scope with key within the last
+// idempotencyKeyTTL, or 0 when there is none or no key was given
+func (s *service) findIdempotentResource(ctx context.Context, scope string, key string) (uint, error) {
+	if key == "" {
+		return 0, nil
+	}
+	idempotencyKey, err := s.repo.GetIdempotencyKey(ctx, scope, key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if time.Since(idempotencyKey.CreatedAt) > idempotencyKeyTTL {
+		return 0, nil
+	}
+	return idempotencyKey.ResourceID, nil
+}
+
+// recordIdempotencyKeyTx records the resource created in scope with key, if a key was given. When a
+// concurrent request recorded the same key first, it fails and the creation is rolled back.
+func recordIdempotencyKeyTx(ctx context.Context, txRepo repository.Repository, scope string, key string, resourceID uint) error {
+	if key == "" {
+		return nil
+	}
+	return txRepo.CreateIdempotencyKey(ctx, &model.IdempotencyKey{
+		Scope:      scope,
+		Key:        key,
+		ResourceID: resourceID,
+	}, time.Now().Add(-idempotencyKeyTTL))
+}
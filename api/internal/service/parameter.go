@@ -1,58 +1,116 @@
 package service
 
 import (
+	"api/internal/apperror"
 	"api/internal/dto"
 	"api/internal/mapper"
 	"api/internal/model"
 	"api/internal/repository"
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"sdk"
 	"sdk/types"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 )
 
-// CreateParameter creates a new parameter
-func (s *service) CreateParameter(ctx context.Context, req *dto.CreateParameterRequest) (*model.Parameter, error) {
+// CreateParameter creates a new parameter. Requests with an idempotency key already used within the last
+// 24 hours return the parameter created by the original request.
+func (s *service) CreateParameter(ctx context.Context, userID uint, req *dto.CreateParameterRequest) (*model.Parameter, error) {
+	parameterID, err := s.findIdempotentResource(ctx, model.IdempotencyScopeCreateParameter, req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if parameterID != 0 {
+		return s.GetParameterByID(ctx, parameterID)
+	}
+
 	// Check if parameter with same name already exists
-	existing, err := s.repo.GetParameterByName(ctx, req.Name)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	if err := ensureParameterNameAvailable(ctx, s.repo, req.Name); err != nil {
 		return nil, err
 	}
-	if existing != nil {
-		return nil, errors.New("parameter with name '" + req.Name + "' already exists")
+
+	parameter, err := s.newParameter(userID, req)
+	if err != nil {
+		return nil, err
 	}
 
-	// Validate default rollout value based on data type
-	if err := s.validateParameterValue(req.DefaultRolloutValue, req.DataType); err != nil {
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.CreateParameter(ctx, parameter); err != nil {
+			return err
+		}
+
+		// Update raw_value field with all related data
+		if err := txRepo.UpdateParameterRawValue(ctx, parameter.ID); err != nil {
+			return fmt.Errorf("failed to update parameter raw value: %w", err)
+		}
+
+		after, err := parameterSnapshot(ctx, txRepo, parameter.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, parameter.ID, model.AuditActionCreate, nil, after); err != nil {
+			return err
+		}
+		return recordIdempotencyKeyTx(ctx, txRepo, model.IdempotencyScopeCreateParameter, req.IdempotencyKey, parameter.ID)
+	})
+	if err != nil {
+		// A concurrent request with the same key may have created the parameter first
+		if parameterID, findErr := s.findIdempotentResource(ctx, model.IdempotencyScopeCreateParameter, req.IdempotencyKey); findErr == nil && parameterID != 0 {
+			return s.GetParameterByID(ctx, parameterID)
+		}
 		return nil, err
 	}
 
-	parameter := &model.Parameter{
-		Name:        req.Name,
-		Description: req.Description,
-		DataType:    req.DataType,
-		DefaultRolloutValue: model.RolloutValue{
-			Data: req.DefaultRolloutValue,
-		},
-		UsageCount: 0,
+	return parameter, nil
+}
+
+// newParameter validates the request to create a parameter and builds the parameter it creates
+func (s *service) newParameter(userID uint, req *dto.CreateParameterRequest) (*model.Parameter, error) {
+	// Validate enum options for enum data type
+	enumOptions := []string{}
+	if req.DataType == model.ParameterDataTypeEnum {
+		if err := s.validateEnumOptions(req.EnumOptions); err != nil {
+			return nil, err
+		}
+		enumOptions = req.EnumOptions
 	}
 
-	if err := s.repo.CreateParameter(ctx, parameter); err != nil {
+	constraints, err := s.resolveNumberConstraints(nil, req.DataType, req.NumberConstraints)
+	if err != nil {
 		return nil, err
 	}
 
-	// Update raw_value field with all related data
-	if err := s.repo.UpdateParameterRawValue(ctx, parameter.ID); err != nil {
-		// Log error but don't fail the creation
-		log.Ctx(ctx).Error().Err(err).Uint("parameterId", parameter.ID).Msg("Failed to update parameter raw_value")
+	// Validate default rollout value based on data type
+	if err := s.validateParameterValue(req.DefaultRolloutValue, req.DataType, enumOptions, constraints); err != nil {
+		return nil, err
 	}
 
-	return parameter, nil
+	return &model.Parameter{
+		Name:        req.Name,
+		Description: req.Description,
+		DataType:    req.DataType,
+		DefaultRolloutValue: model.RolloutValue{
+			Data: req.DefaultRolloutValue,
+		},
+		EnumOptions:       enumOptions,
+		NumberConstraints: constraints,
+		UsageCount:        0,
+		CreatedByUserID:   &userID,
+		UpdatedByUserID:   &userID,
+	}, nil
 }
 
 // GetParameterByID retrieves a parameter by ID
@@ -60,7 +118,7 @@ func (s *service) GetParameterByID(ctx context.Context, id uint) (*model.Paramet
 	parameter, err := s.repo.GetParameterByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("parameter with ID %d not found", id)
+			return nil, apperror.NotFound("parameter with ID %d not found", id)
 		}
 		return nil, err
 	}
@@ -81,7 +139,7 @@ func (s *service) GetParameterByName(ctx context.Context, name string) (*model.P
 	return s.repo.GetParameterByName(ctx, name)
 }
 
-// GetAllParameters retrieves all parameters
+// GetAllParameters retrieves all parameters without pagination
 func (s *service) GetAllParameters(ctx context.Context) ([]*model.Parameter, error) {
 	parameters, err := s.repo.GetAllParameters(ctx, 0, 0) // No pagination for findAll equivalent
 	if err != nil {
@@ -101,23 +159,52 @@ func (s *service) GetAllParameters(ctx context.Context) ([]*model.Parameter, err
 	return parameters, nil
 }
 
+// GetParametersPage retrieves up to limit parameters with an ID greater than after, ordered by ID,
+// leaving out archived parameters unless includeArchived is set.
+// It returns the cursor of the next page, or nil when there are no more parameters.
+func (s *service) GetParametersPage(ctx context.Context, limit int, after uint, includeArchived bool) ([]*model.Parameter, *uint, error) {
+	if limit <= 0 {
+		return nil, nil, apperror.BadRequest("invalid limit: must be positive")
+	}
+
+	// Load one extra parameter to know whether another page follows
+	parameters, err := s.repo.GetParametersAfter(ctx, after, limit+1, includeArchived)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nextCursor *uint
+	if len(parameters) > limit {
+		parameters = parameters[:limit]
+		nextCursor = &parameters[limit-1].ID
+	}
+
+	for _, parameter := range parameters {
+		if parameter.Conditions == nil {
+			parameter.Conditions = []model.ParameterCondition{}
+		}
+		if parameter.Rules == nil {
+			parameter.Rules = []model.ParameterRule{}
+		}
+	}
+
+	return parameters, nextCursor, nil
+}
+
 // UpdateParameter updates an existing parameter
-func (s *service) UpdateParameter(ctx context.Context, id uint, req *dto.UpdateParameterRequest) (*model.Parameter, error) {
+func (s *service) UpdateParameter(ctx context.Context, id uint, userID uint, req *dto.UpdateParameterRequest) (*model.Parameter, error) {
 	logger := log.Ctx(ctx).With().Str("service", "update-parameter").Uint("id", id).Logger()
 	parameter, err := s.GetParameterByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := parameter.RawValue
 
 	// Check if name is being updated and if it conflicts
 	if req.Name != nil && *req.Name != parameter.Name {
-		existing, err := s.repo.GetParameterByName(ctx, *req.Name)
-		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := ensureParameterNameAvailable(ctx, s.repo, *req.Name); err != nil {
 			return nil, err
 		}
-		if existing != nil {
-			return nil, errors.New("parameter with name '" + *req.Name + "' already exists")
-		}
 		parameter.Name = *req.Name
 	}
 
@@ -126,13 +213,23 @@ func (s *service) UpdateParameter(ctx context.Context, id uint, req *dto.UpdateP
 		parameter.Description = *req.Description
 	}
 
+	// Determine the final data type and enum options for validation
+	dataType := parameter.DataType
+	if req.DataType != nil {
+		dataType = *req.DataType
+	}
+	enumOptions, err := s.resolveEnumOptions(parameter, dataType, req.EnumOptions)
+	if err != nil {
+		return nil, err
+	}
+	constraints, err := s.resolveNumberConstraints(parameter.NumberConstraints, dataType, req.NumberConstraints)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate default rollout value if being updated
 	if req.DefaultRolloutValue != nil {
-		dataType := parameter.DataType
-		if req.DataType != nil {
-			dataType = *req.DataType
-		}
-		if err := s.validateParameterValue(req.DefaultRolloutValue, dataType); err != nil {
+		if err := s.validateParameterValue(req.DefaultRolloutValue, dataType, enumOptions, constraints); err != nil {
 			return nil, err
 		}
 		parameter.DefaultRolloutValue = model.RolloutValue{
@@ -140,38 +237,42 @@ func (s *service) UpdateParameter(ctx context.Context, id uint, req *dto.UpdateP
 		}
 	}
 
-	// If data type is being changed, validate all existing condition values
-	if req.DataType != nil && *req.DataType != parameter.DataType {
-		for _, condition := range parameter.Conditions {
-			if err := s.validateParameterValue(condition.RolloutValue.Data, *req.DataType); err != nil {
-				return nil, fmt.Errorf("existing condition rollout value is invalid for new data type: %v", err)
-			}
-		}
-		for _, rule := range parameter.Rules {
-			if err := s.validateParameterValue(rule.RolloutValue.Data, *req.DataType); err != nil {
-				return nil, fmt.Errorf("existing rule rollout value is invalid for new data type: %v", err)
-			}
+	// If data type, enum options or number constraints are being changed, validate all existing values
+	if dataType != parameter.DataType || req.EnumOptions != nil || req.NumberConstraints != nil {
+		if err := s.validateExistingRolloutValues(parameter, dataType, enumOptions, constraints, req.DefaultRolloutValue == nil, true); err != nil {
+			return nil, err
 		}
-		parameter.DataType = *req.DataType
+		parameter.DataType = dataType
+		parameter.EnumOptions = enumOptions
+		parameter.NumberConstraints = constraints
 	}
+	parameter.UpdatedByUserID = &userID
 
-	if err := s.repo.UpdateParameter(ctx, parameter); err != nil {
-		return nil, err
-	}
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.UpdateParameter(ctx, parameter); err != nil {
+			return err
+		}
 
-	// Update raw_value field with all related data
-	logger.Info().Msg("Updating parameter raw value")
-	if err := s.repo.UpdateParameterRawValue(ctx, parameter.ID); err != nil {
-		// Log error but don't fail the update
-		logger.Error().Err(err).Uint("parameterId", parameter.ID).Msg("Failed to update parameter raw_value")
-	}
+		// Update raw_value field with all related data
+		logger.Info().Msg("Updating parameter raw value")
+		if err := txRepo.UpdateParameterRawValue(ctx, parameter.ID); err != nil {
+			return fmt.Errorf("failed to update parameter raw value: %w", err)
+		}
 
-	logger.Info().Msg("Enqueuing sync parameter job")
-	_, err = s.riverClient.Insert(ctx, dto.SyncParameterArgs{
-		ParameterID: int(id),
-	}, nil)
+		after, err := parameterSnapshot(ctx, txRepo, parameter.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, parameter.ID, model.AuditActionUpdate, before, after); err != nil {
+			return err
+		}
+
+		logger.Info().Msg("Enqueuing sync parameter job")
+		return s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{
+			ParameterID: int(id),
+		})
+	})
 	if err != nil {
-		logger.Error().Err(err).Msg("Failed to enqueue sync parameter job")
 		return nil, err
 	}
 
@@ -179,27 +280,24 @@ func (s *service) UpdateParameter(ctx context.Context, id uint, req *dto.UpdateP
 }
 
 // UpdateParameterWithRules updates a parameter and completely replaces all its rules
-func (s *service) UpdateParameterWithRules(ctx context.Context, id uint, req *dto.UpdateParameterWithRulesRequest) (*model.Parameter, error) {
+func (s *service) UpdateParameterWithRules(ctx context.Context, id uint, userID uint, req *dto.UpdateParameterWithRulesRequest) (*model.Parameter, error) {
 	logger := log.Ctx(ctx).With().Str("service", "update-parameter-with-rules").Uint("id", id).Logger()
 	// Use database transaction to ensure atomicity
 	return s.withTransaction(ctx, func(txRepo repository.Repository) (*model.Parameter, error) {
 		parameter, err := txRepo.GetParameterByID(ctx, id)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, fmt.Errorf("parameter with ID %d not found", id)
+				return nil, apperror.NotFound("parameter with ID %d not found", id)
 			}
 			return nil, err
 		}
+		before := parameter.RawValue
 
 		// Check if name is being updated and if it conflicts
 		if req.Name != nil && *req.Name != parameter.Name {
-			existing, err := txRepo.GetParameterByName(ctx, *req.Name)
-			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := ensureParameterNameAvailable(ctx, txRepo, *req.Name); err != nil {
 				return nil, err
 			}
-			if existing != nil {
-				return nil, errors.New("parameter with name '" + *req.Name + "' already exists")
-			}
 			parameter.Name = *req.Name
 		}
 
@@ -208,15 +306,23 @@ func (s *service) UpdateParameterWithRules(ctx context.Context, id uint, req *dt
 			parameter.Description = *req.Description
 		}
 
-		// Determine the final data type for validation
+		// Determine the final data type and enum options for validation
 		finalDataType := parameter.DataType
 		if req.DataType != nil {
 			finalDataType = *req.DataType
 		}
+		finalEnumOptions, err := s.resolveEnumOptions(parameter, finalDataType, req.EnumOptions)
+		if err != nil {
+			return nil, err
+		}
+		finalConstraints, err := s.resolveNumberConstraints(parameter.NumberConstraints, finalDataType, req.NumberConstraints)
+		if err != nil {
+			return nil, err
+		}
 
 		// Validate default rollout value if being updated
 		if req.DefaultRolloutValue != nil {
-			if err := s.validateParameterValue(req.DefaultRolloutValue, finalDataType); err != nil {
+			if err := s.validateParameterValue(req.DefaultRolloutValue, finalDataType, finalEnumOptions, finalConstraints); err != nil {
 				return nil, err
 			}
 			parameter.DefaultRolloutValue = model.RolloutValue{
@@ -224,17 +330,19 @@ func (s *service) UpdateParameterWithRules(ctx context.Context, id uint, req *dt
 			}
 		}
 
-		// If data type is being changed, validate all existing condition values
-		if req.DataType != nil && *req.DataType != parameter.DataType {
-			for _, condition := range parameter.Conditions {
-				if err := s.validateParameterValue(condition.RolloutValue.Data, *req.DataType); err != nil {
-					return nil, fmt.Errorf("existing condition rollout value is invalid for new data type: %v", err)
-				}
+		// If data type, enum options or number constraints are being changed, validate all existing values.
+		// Existing rules are only checked when they are not being replaced.
+		if finalDataType != parameter.DataType || req.EnumOptions != nil || req.NumberConstraints != nil {
+			if err := s.validateExistingRolloutValues(parameter, finalDataType, finalEnumOptions, finalConstraints, req.DefaultRolloutValue == nil, req.Rules == nil); err != nil {
+				return nil, err
 			}
-			parameter.DataType = *req.DataType
+			parameter.DataType = finalDataType
+			parameter.EnumOptions = finalEnumOptions
+			parameter.NumberConstraints = finalConstraints
 		}
 
 		// Update parameter metadata first
+		parameter.UpdatedByUserID = &userID
 		if err := txRepo.UpdateParameter(ctx, parameter); err != nil {
 			return nil, err
 		}
@@ -246,66 +354,9 @@ func (s *service) UpdateParameterWithRules(ctx context.Context, id uint, req *dt
 				return nil, fmt.Errorf("failed to delete existing rules: %v", err)
 			}
 
-			// Create new rules
-			for _, ruleReq := range req.Rules {
-				// Validate rollout value for the rule
-				if err := s.validateParameterValue(ruleReq.RolloutValue, finalDataType); err != nil {
-					return nil, fmt.Errorf("invalid rollout value for rule '%s': %v", ruleReq.Name, err)
-				}
-
-				rule := &model.ParameterRule{
-					Name:         ruleReq.Name,
-					Description:  ruleReq.Description,
-					Type:         ruleReq.Type,
-					ParameterID:  id,
-					RolloutValue: model.RolloutValue{Data: ruleReq.RolloutValue},
-					SegmentID:    ruleReq.SegmentID,
-					MatchType:    ruleReq.MatchType,
-				}
-
-				// Validate segment-based rule requirements
-				if ruleReq.Type == model.RuleTypeSegment {
-					if ruleReq.SegmentID == nil || ruleReq.MatchType == nil {
-						return nil, fmt.Errorf("segment ID and match type are required for segment-based rule '%s'", ruleReq.Name)
-					}
-					// Validate that segment exists
-					_, err := txRepo.GetSegmentByID(ctx, *ruleReq.SegmentID)
-					if err != nil {
-						if errors.Is(err, gorm.ErrRecordNotFound) {
-							return nil, fmt.Errorf("segment with ID %d not found for rule '%s'", *ruleReq.SegmentID, ruleReq.Name)
-						}
-						return nil, err
-					}
-				}
-
-				// Create the rule
-				if err := txRepo.CreateParameterRule(ctx, rule); err != nil {
-					return nil, fmt.Errorf("failed to create rule '%s': %v", ruleReq.Name, err)
-				}
-
-				// Add conditions if it's an attribute-based rule
-				if ruleReq.Type == model.RuleTypeAttribute && len(ruleReq.Conditions) > 0 {
-					for _, conditionReq := range ruleReq.Conditions {
-						// Validate that attribute exists
-						_, err := txRepo.GetAttributeByID(ctx, conditionReq.AttributeID)
-						if err != nil {
-							if errors.Is(err, gorm.ErrRecordNotFound) {
-								return nil, fmt.Errorf("attribute with ID %d not found for rule '%s'", conditionReq.AttributeID, ruleReq.Name)
-							}
-							return nil, err
-						}
-
-						condition := &model.ParameterRuleCondition{
-							RuleID:      rule.ID,
-							AttributeID: conditionReq.AttributeID,
-							Operator:    conditionReq.Operator,
-							Value:       conditionReq.Value,
-						}
-						if err := txRepo.CreateParameterRuleCondition(ctx, condition); err != nil {
-							return nil, fmt.Errorf("failed to create condition for rule '%s': %v", ruleReq.Name, err)
-						}
-					}
-				}
+			// Create new rules, in the order they are listed unless priorities are given
+			if err := s.createParameterRulesTx(ctx, txRepo, id, nil, req.Rules, finalDataType, finalEnumOptions, finalConstraints); err != nil {
+				return nil, err
 			}
 		}
 
@@ -313,58 +364,297 @@ func (s *service) UpdateParameterWithRules(ctx context.Context, id uint, req *dt
 			return nil, fmt.Errorf("failed to update parameter raw value: %v", err)
 		}
 
+		after, err := parameterSnapshot(ctx, txRepo, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, id, model.AuditActionUpdate, before, after); err != nil {
+			return nil, err
+		}
+
 		// Return updated parameter with all rules
 		logger.Info().Msg("Enqueuing sync parameter job")
-		_, err = s.riverClient.Insert(ctx, dto.SyncParameterArgs{
+		if err := s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{
 			ParameterID: int(id),
-		}, nil)
-		if err != nil {
+		}); err != nil {
 			logger.Error().Err(err).Msg("Failed to enqueue sync parameter job")
-			return nil, fmt.Errorf("failed to enqueue sync parameter job: %v", err)
+			return nil, err
 		}
 
 		return txRepo.GetParameterByID(ctx, id)
 	})
 }
 
+// createParameterRulesTx creates the rules of a parameter with their conditions, in the order they are
+// listed unless priorities are given. environmentOverrideID is set when the rules belong to an environment
+// override rather than to the parameter's base definition.
+func (s *service) createParameterRulesTx(ctx context.Context, txRepo repository.Repository, parameterID uint, environmentOverrideID *uint, rules []dto.CreateParameterRuleRequest, dataType model.ParameterDataType, enumOptions []string, constraints *model.NumberConstraints) error {
+	var validator conditionValidator
+	for i, ruleReq := range rules {
+		// Validate rollout value for the rule
+		if err := s.validateParameterValue(ruleReq.RolloutValue, dataType, enumOptions, constraints); err != nil {
+			return apperror.BadRequest("invalid rollout value for rule '%s': %v", ruleReq.Name, err)
+		}
+		if err := validateRuleRollout(ruleReq.Name, ruleReq.RolloutPercentage, ruleReq.HashAttributeName); err != nil {
+			return err
+		}
+
+		rule := &model.ParameterRule{
+			Name:                  ruleReq.Name,
+			Description:           ruleReq.Description,
+			Type:                  ruleReq.Type,
+			ParameterID:           parameterID,
+			RolloutValue:          model.RolloutValue{Data: ruleReq.RolloutValue},
+			SegmentID:             ruleReq.SegmentID,
+			MatchType:             ruleReq.MatchType,
+			ConditionLogic:        ruleReq.ConditionLogic,
+			Priority:              rulePriority(ruleReq.Priority, i),
+			RolloutPercentage:     ruleReq.RolloutPercentage,
+			HashAttributeName:     ruleReq.HashAttributeName,
+			Negate:                ruleReq.Negate,
+			EnvironmentOverrideID: environmentOverrideID,
+		}
+
+		// Validate segment-based rule requirements
+		if ruleReq.Type == model.RuleTypeSegment {
+			if ruleReq.SegmentID == nil || ruleReq.MatchType == nil {
+				return apperror.BadRequest("segment ID and match type are required for segment-based rule '%s'", ruleReq.Name)
+			}
+			// Validate that segment exists
+			_, err := txRepo.GetSegmentByID(ctx, *ruleReq.SegmentID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return apperror.NotFound("segment with ID %d not found for rule '%s'", *ruleReq.SegmentID, ruleReq.Name)
+				}
+				return err
+			}
+		}
+
+		// Create the rule
+		if err := txRepo.CreateParameterRule(ctx, rule); err != nil {
+			return fmt.Errorf("failed to create rule '%s': %v", ruleReq.Name, err)
+		}
+
+		// Add conditions if it's an attribute-based rule
+		if ruleReq.Type == model.RuleTypeAttribute && len(ruleReq.Conditions) > 0 {
+			for _, conditionReq := range ruleReq.Conditions {
+				// Validate that attribute exists
+				attribute, err := txRepo.GetAttributeByID(ctx, conditionReq.AttributeID)
+				if err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return apperror.NotFound("attribute with ID %d not found for rule '%s'", conditionReq.AttributeID, ruleReq.Name)
+					}
+					return err
+				}
+				if !validator.check(ruleReq.Name, attribute, conditionReq.Operator, conditionReq.Value) {
+					continue
+				}
+
+				condition := &model.ParameterRuleCondition{
+					RuleID:      rule.ID,
+					AttributeID: conditionReq.AttributeID,
+					Operator:    conditionReq.Operator,
+					Value:       conditionReq.Value,
+				}
+				if err := txRepo.CreateParameterRuleCondition(ctx, condition); err != nil {
+					return fmt.Errorf("failed to create condition for rule '%s': %v", ruleReq.Name, err)
+				}
+			}
+		}
+	}
+	return validator.err()
+}
+
 // DeleteParameter deletes a parameter
-func (s *service) DeleteParameter(ctx context.Context, id uint) error {
+func (s *service) DeleteParameter(ctx context.Context, id uint, userID uint) error {
 	parameter, err := s.GetParameterByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	// Check if parameter is being used in experiments
+	if err := ensureParameterUnused(parameter); err != nil {
+		return err
+	}
+
+	return s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.DeleteParameter(ctx, id); err != nil {
+			return err
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, id, model.AuditActionDelete, parameter.RawValue, nil); err != nil {
+			return err
+		}
+		return s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{
+			ParameterID: int(id),
+		})
+	})
+}
+
+// ensureParameterUnused rejects deleting a parameter that is being used in experiments
+func ensureParameterUnused(parameter *model.Parameter) error {
 	if parameter.UsageCount > 0 {
-		return fmt.Errorf("cannot delete parameter '%s' as it is being used in %d experiment(s)", parameter.Name, parameter.UsageCount)
+		return apperror.Conflict("cannot delete parameter '%s' as it is being used in %d experiment(s)", parameter.Name, parameter.UsageCount)
+	}
+	return nil
+}
+
+// GetDeletedParametersPage retrieves up to limit deleted parameters with an ID greater than after, ordered by ID.
+// It returns the cursor of the next page, or nil when there are no more deleted parameters.
+func (s *service) GetDeletedParametersPage(ctx context.Context, limit int, after uint) ([]*model.Parameter, *uint, error) {
+	if limit <= 0 {
+		return nil, nil, apperror.BadRequest("invalid limit: must be positive")
+	}
+
+	// Load one extra parameter to know whether another page follows
+	parameters, err := s.repo.GetDeletedParametersAfter(ctx, after, limit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nextCursor *uint
+	if len(parameters) > limit {
+		parameters = parameters[:limit]
+		nextCursor = &parameters[limit-1].ID
+	}
+
+	for _, parameter := range parameters {
+		if parameter.Conditions == nil {
+			parameter.Conditions = []model.ParameterCondition{}
+		}
+		if parameter.Rules == nil {
+			parameter.Rules = []model.ParameterRule{}
+		}
+	}
+
+	return parameters, nextCursor, nil
+}
+
+// RestoreParameter undoes the deletion of a parameter, rebuilding its raw value and serving it to SDK clients again
+func (s *service) RestoreParameter(ctx context.Context, id uint, userID uint) (*model.Parameter, error) {
+	err := s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.RestoreParameter(ctx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperror.NotFound("deleted parameter with ID %d not found", id)
+			}
+			return err
+		}
+		if err := txRepo.SetParameterUpdatedBy(ctx, id, userID); err != nil {
+			return err
+		}
+		if err := txRepo.UpdateParameterRawValue(ctx, id); err != nil {
+			return err
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, id, model.AuditActionRestore, nil, nil); err != nil {
+			return err
+		}
+		return s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{
+			ParameterID: int(id),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetParameterByID(ctx, id)
+}
+
+// ensureParameterNameAvailable rejects a name held by another parameter. Deleted parameters keep their
+// name so they can be restored, so a name held by a deleted parameter cannot be reused either.
+func ensureParameterNameAvailable(ctx context.Context, repo repository.Repository, name string) error {
+	existing, err := repo.GetParameterByNameWithDeleted(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.DeletedAt.Valid {
+		return apperror.Conflict("parameter with name '%s' was deleted; restore parameter %d or choose another name", name, existing.ID)
 	}
+	return apperror.Conflict("parameter with name '%s' already exists", name)
+}
+
+// ArchiveParameter retires a parameter without deleting it or its history. Archived parameters are
+// left out of SDK sync and the default parameter list but can still be viewed by ID.
+func (s *service) ArchiveParameter(ctx context.Context, id uint, userID uint) (*model.Parameter, error) {
+	return s.setParameterArchived(ctx, id, userID, true)
+}
 
-	return s.repo.DeleteParameter(ctx, id)
+// UnarchiveParameter serves an archived parameter to SDK clients again
+func (s *service) UnarchiveParameter(ctx context.Context, id uint, userID uint) (*model.Parameter, error) {
+	return s.setParameterArchived(ctx, id, userID, false)
+}
+
+// setParameterArchived archives or unarchives a parameter and syncs the SDK parameters
+func (s *service) setParameterArchived(ctx context.Context, id uint, userID uint, archived bool) (*model.Parameter, error) {
+	parameter, err := s.GetParameterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var archivedAt *time.Time
+	action := model.AuditActionUnarchive
+	if archived {
+		if parameter.ArchivedAt != nil {
+			return nil, apperror.Conflict("parameter '%s' is already archived", parameter.Name)
+		}
+		// Running experiments still resolve the parameter, so it cannot be retired under them
+		if parameter.UsageCount > 0 {
+			return nil, apperror.Conflict("cannot archive parameter '%s' as it is being used in %d experiment(s)", parameter.Name, parameter.UsageCount)
+		}
+		now := time.Now()
+		archivedAt = &now
+		action = model.AuditActionArchive
+	} else if parameter.ArchivedAt == nil {
+		return nil, apperror.Conflict("parameter '%s' is not archived", parameter.Name)
+	}
+
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.SetParameterArchivedAt(ctx, id, archivedAt); err != nil {
+			return err
+		}
+		if err := txRepo.SetParameterUpdatedBy(ctx, id, userID); err != nil {
+			return err
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, id, action, nil, nil); err != nil {
+			return err
+		}
+		return s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{
+			ParameterID: int(id),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetParameterByID(ctx, id)
 }
 
 // AddParameterRule adds a rule to a parameter
-func (s *service) AddParameterRule(ctx context.Context, parameterID uint, req *dto.CreateParameterRuleRequest) (*model.Parameter, error) {
+func (s *service) AddParameterRule(ctx context.Context, parameterID uint, userID uint, req *dto.CreateParameterRuleRequest) (*model.Parameter, error) {
 	parameter, err := s.GetParameterByID(ctx, parameterID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Validate rollout value based on parameter data type
-	if err := s.validateParameterValue(req.RolloutValue, parameter.DataType); err != nil {
+	if err := s.validateParameterValue(req.RolloutValue, parameter.DataType, parameter.EnumOptions, parameter.NumberConstraints); err != nil {
+		return nil, apperror.BadRequest("invalid rollout value for rule '%s': %v", req.Name, err)
+	}
+	if err := validateRuleRollout(req.Name, req.RolloutPercentage, req.HashAttributeName); err != nil {
 		return nil, err
 	}
 
 	// For segment-based rules
 	if req.Type == model.RuleTypeSegment {
 		if req.SegmentID == nil || req.MatchType == nil {
-			return nil, errors.New("segment ID and match type are required for segment-based rules")
+			return nil, apperror.BadRequest("segment ID and match type are required for segment-based rules")
 		}
 
 		// Validate that segment exists
 		_, err := s.repo.GetSegmentByID(ctx, *req.SegmentID)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, fmt.Errorf("segment with ID %d not found", *req.SegmentID)
+				return nil, apperror.NotFound("segment with ID %d not found", *req.SegmentID)
 			}
 			return nil, err
 		}
@@ -372,22 +662,27 @@ func (s *service) AddParameterRule(ctx context.Context, parameterID uint, req *d
 		// Check if rule for this segment already exists
 		for _, rule := range parameter.Rules {
 			if rule.Type == model.RuleTypeSegment && rule.SegmentID != nil && *rule.SegmentID == *req.SegmentID {
-				return nil, fmt.Errorf("rule for segment ID %d already exists for this parameter", *req.SegmentID)
+				return nil, apperror.Conflict("rule for segment ID %d already exists for this parameter", *req.SegmentID)
 			}
 		}
 	}
 
 	// For attribute-based rules
 	if req.Type == model.RuleTypeAttribute && len(req.Conditions) > 0 {
-		// Validate that attributes exist
+		// Validate that attributes exist and the conditions are valid for them
+		var validator conditionValidator
 		for _, condition := range req.Conditions {
-			_, err := s.repo.GetAttributeByID(ctx, condition.AttributeID)
+			attribute, err := s.repo.GetAttributeByID(ctx, condition.AttributeID)
 			if err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return nil, fmt.Errorf("attribute with ID %d not found", condition.AttributeID)
+					return nil, apperror.NotFound("attribute with ID %d not found", condition.AttributeID)
 				}
 				return nil, err
 			}
+			validator.check(req.Name, attribute, condition.Operator, condition.Value)
+		}
+		if err := validator.err(); err != nil {
+			return nil, err
 		}
 	}
 
@@ -398,28 +693,40 @@ func (s *service) AddParameterRule(ctx context.Context, parameterID uint, req *d
 		RolloutValue: model.RolloutValue{
 			Data: req.RolloutValue,
 		},
-		ParameterID: parameterID,
-		SegmentID:   req.SegmentID,
-		MatchType:   req.MatchType,
+		ParameterID:       parameterID,
+		SegmentID:         req.SegmentID,
+		MatchType:         req.MatchType,
+		ConditionLogic:    req.ConditionLogic,
+		Priority:          rulePriority(req.Priority, nextRulePriority(parameter.Rules)),
+		RolloutPercentage: req.RolloutPercentage,
+		HashAttributeName: req.HashAttributeName,
+		Negate:            req.Negate,
 	}
 
-	if err := s.repo.CreateParameterRule(ctx, rule); err != nil {
-		return nil, err
-	}
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.CreateParameterRule(ctx, rule); err != nil {
+			return err
+		}
 
-	// Add conditions if it's an attribute-based rule
-	if req.Type == model.RuleTypeAttribute && len(req.Conditions) > 0 {
-		for _, conditionReq := range req.Conditions {
-			condition := &model.ParameterRuleCondition{
-				RuleID:      rule.ID,
-				AttributeID: conditionReq.AttributeID,
-				Operator:    conditionReq.Operator,
-				Value:       conditionReq.Value,
-			}
-			if err := s.repo.CreateParameterRuleCondition(ctx, condition); err != nil {
-				return nil, err
+		// Add conditions if it's an attribute-based rule
+		if req.Type == model.RuleTypeAttribute && len(req.Conditions) > 0 {
+			for _, conditionReq := range req.Conditions {
+				condition := &model.ParameterRuleCondition{
+					RuleID:      rule.ID,
+					AttributeID: conditionReq.AttributeID,
+					Operator:    conditionReq.Operator,
+					Value:       conditionReq.Value,
+				}
+				if err := txRepo.CreateParameterRuleCondition(ctx, condition); err != nil {
+					return err
+				}
 			}
 		}
+
+		return s.auditParameterChange(ctx, txRepo, userID, parameter, model.AuditActionAddRule)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return updated parameter with rules
@@ -427,7 +734,7 @@ func (s *service) AddParameterRule(ctx context.Context, parameterID uint, req *d
 }
 
 // UpdateParameterRule updates a parameter rule
-func (s *service) UpdateParameterRule(ctx context.Context, parameterID uint, ruleID uint, req *dto.UpdateParameterRuleRequest) (*model.Parameter, error) {
+func (s *service) UpdateParameterRule(ctx context.Context, parameterID uint, ruleID uint, userID uint, req *dto.UpdateParameterRuleRequest) (*model.Parameter, error) {
 	parameter, err := s.GetParameterByID(ctx, parameterID)
 	if err != nil {
 		return nil, err
@@ -436,19 +743,19 @@ func (s *service) UpdateParameterRule(ctx context.Context, parameterID uint, rul
 	rule, err := s.repo.GetParameterRuleByID(ctx, ruleID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("rule with ID %d not found for parameter %d", ruleID, parameterID)
+			return nil, apperror.NotFound("rule with ID %d not found for parameter %d", ruleID, parameterID)
 		}
 		return nil, err
 	}
 
 	if rule.ParameterID != parameterID {
-		return nil, fmt.Errorf("rule with ID %d does not belong to parameter %d", ruleID, parameterID)
+		return nil, apperror.NotFound("rule with ID %d does not belong to parameter %d", ruleID, parameterID)
 	}
 
 	// Validate rollout value if being updated
 	if req.RolloutValue != nil {
-		if err := s.validateParameterValue(req.RolloutValue, parameter.DataType); err != nil {
-			return nil, err
+		if err := s.validateParameterValue(req.RolloutValue, parameter.DataType, parameter.EnumOptions, parameter.NumberConstraints); err != nil {
+			return nil, apperror.BadRequest("invalid rollout value for rule '%s': %v", rule.Name, err)
 		}
 		rule.RolloutValue = model.RolloutValue{
 			Data: req.RolloutValue,
@@ -471,6 +778,24 @@ func (s *service) UpdateParameterRule(ctx context.Context, parameterID uint, rul
 	if req.MatchType != nil {
 		rule.MatchType = req.MatchType
 	}
+	if req.ConditionLogic != nil {
+		rule.ConditionLogic = *req.ConditionLogic
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.RolloutPercentage != nil {
+		rule.RolloutPercentage = req.RolloutPercentage
+	}
+	if req.HashAttributeName != nil {
+		rule.HashAttributeName = req.HashAttributeName
+	}
+	if req.Negate != nil {
+		rule.Negate = *req.Negate
+	}
+	if err := validateRuleRollout(rule.Name, rule.RolloutPercentage, rule.HashAttributeName); err != nil {
+		return nil, err
+	}
 
 	// If type is being changed, validate new type requirements
 	if req.Type != nil && *req.Type != rule.Type {
@@ -484,13 +809,13 @@ func (s *service) UpdateParameterRule(ctx context.Context, parameterID uint, rul
 				matchType = req.MatchType
 			}
 			if segmentID == nil || matchType == nil {
-				return nil, errors.New("segment ID and match type are required for segment-based rules")
+				return nil, apperror.BadRequest("segment ID and match type are required for segment-based rules")
 			}
 			// Validate that segment exists
 			_, err := s.repo.GetSegmentByID(ctx, *segmentID)
 			if err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return nil, fmt.Errorf("segment with ID %d not found", *segmentID)
+					return nil, apperror.NotFound("segment with ID %d not found", *segmentID)
 				}
 				return nil, err
 			}
@@ -502,7 +827,7 @@ func (s *service) UpdateParameterRule(ctx context.Context, parameterID uint, rul
 		_, err := s.repo.GetSegmentByID(ctx, *req.SegmentID)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, fmt.Errorf("segment with ID %d not found", *req.SegmentID)
+				return nil, apperror.NotFound("segment with ID %d not found", *req.SegmentID)
 			}
 			return nil, err
 		}
@@ -511,43 +836,57 @@ func (s *service) UpdateParameterRule(ctx context.Context, parameterID uint, rul
 		for _, existingRule := range parameter.Rules {
 			if existingRule.Type == model.RuleTypeSegment && existingRule.SegmentID != nil &&
 				*existingRule.SegmentID == *req.SegmentID && existingRule.ID != ruleID {
-				return nil, fmt.Errorf("rule for segment ID %d already exists for this parameter", *req.SegmentID)
+				return nil, apperror.Conflict("rule for segment ID %d already exists for this parameter", *req.SegmentID)
 			}
 		}
 	}
 
-	if err := s.repo.UpdateParameterRule(ctx, rule); err != nil {
-		return nil, err
-	}
-
-	// Handle conditions update for attribute-based rules
-	if len(req.Conditions) > 0 {
-		// Remove existing conditions
-		if err := s.repo.DeleteParameterRuleConditionsByRuleID(ctx, ruleID); err != nil {
-			return nil, err
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.UpdateParameterRule(ctx, rule); err != nil {
+			return err
 		}
 
-		// Add new conditions
-		for _, conditionReq := range req.Conditions {
-			// Validate that attribute exists
-			_, err := s.repo.GetAttributeByID(ctx, conditionReq.AttributeID)
-			if err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return nil, fmt.Errorf("attribute with ID %d not found", conditionReq.AttributeID)
-				}
-				return nil, err
+		// Handle conditions update for attribute-based rules
+		if len(req.Conditions) > 0 {
+			// Remove existing conditions
+			if err := txRepo.DeleteParameterRuleConditionsByRuleID(ctx, ruleID); err != nil {
+				return err
 			}
 
-			condition := &model.ParameterRuleCondition{
-				RuleID:      ruleID,
-				AttributeID: conditionReq.AttributeID,
-				Operator:    conditionReq.Operator,
-				Value:       conditionReq.Value,
+			// Add new conditions
+			var validator conditionValidator
+			for _, conditionReq := range req.Conditions {
+				// Validate that attribute exists
+				attribute, err := txRepo.GetAttributeByID(ctx, conditionReq.AttributeID)
+				if err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return apperror.NotFound("attribute with ID %d not found", conditionReq.AttributeID)
+					}
+					return err
+				}
+				if !validator.check(rule.Name, attribute, conditionReq.Operator, conditionReq.Value) {
+					continue
+				}
+
+				condition := &model.ParameterRuleCondition{
+					RuleID:      ruleID,
+					AttributeID: conditionReq.AttributeID,
+					Operator:    conditionReq.Operator,
+					Value:       conditionReq.Value,
+				}
+				if err := txRepo.CreateParameterRuleCondition(ctx, condition); err != nil {
+					return err
+				}
 			}
-			if err := s.repo.CreateParameterRuleCondition(ctx, condition); err != nil {
-				return nil, err
+			if err := validator.err(); err != nil {
+				return err
 			}
 		}
+
+		return s.auditParameterChange(ctx, txRepo, userID, parameter, model.AuditActionUpdateRule)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return updated parameter with rules
@@ -555,20 +894,31 @@ func (s *service) UpdateParameterRule(ctx context.Context, parameterID uint, rul
 }
 
 // DeleteParameterRule deletes a parameter rule
-func (s *service) DeleteParameterRule(ctx context.Context, parameterID uint, ruleID uint) (*model.Parameter, error) {
+func (s *service) DeleteParameterRule(ctx context.Context, parameterID uint, ruleID uint, userID uint) (*model.Parameter, error) {
+	parameter, err := s.GetParameterByID(ctx, parameterID)
+	if err != nil {
+		return nil, err
+	}
+
 	rule, err := s.repo.GetParameterRuleByID(ctx, ruleID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("rule with ID %d not found for parameter %d", ruleID, parameterID)
+			return nil, apperror.NotFound("rule with ID %d not found for parameter %d", ruleID, parameterID)
 		}
 		return nil, err
 	}
 
 	if rule.ParameterID != parameterID {
-		return nil, fmt.Errorf("rule with ID %d does not belong to parameter %d", ruleID, parameterID)
+		return nil, apperror.NotFound("rule with ID %d does not belong to parameter %d", ruleID, parameterID)
 	}
 
-	if err := s.repo.DeleteParameterRule(ctx, ruleID); err != nil {
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.DeleteParameterRule(ctx, ruleID); err != nil {
+			return err
+		}
+		return s.auditParameterChange(ctx, txRepo, userID, parameter, model.AuditActionDeleteRule)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -576,6 +926,106 @@ func (s *service) DeleteParameterRule(ctx context.Context, parameterID uint, rul
 	return s.GetParameterByID(ctx, parameterID)
 }
 
+// ReorderParameterRules rewrites the priorities of a parameter's rules so they are evaluated in the
+// order of req.RuleIDs, which must list every rule of the parameter exactly once
+func (s *service) ReorderParameterRules(ctx context.Context, parameterID uint, userID uint, req *dto.ReorderParameterRulesRequest) (*model.Parameter, error) {
+	parameter, err := s.GetParameterByID(ctx, parameterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateRuleOrder(parameter.Rules, req.RuleIDs); err != nil {
+		return nil, err
+	}
+
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.UpdateParameterRulePriorities(ctx, parameterID, req.RuleIDs); err != nil {
+			return err
+		}
+		return s.auditParameterChange(ctx, txRepo, userID, parameter, model.AuditActionReorderRules)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Return updated parameter with rules
+	return s.GetParameterByID(ctx, parameterID)
+}
+
+// validateRuleOrder checks that ruleIDs lists every rule exactly once
+func validateRuleOrder(rules []model.ParameterRule, ruleIDs []uint) error {
+	if len(ruleIDs) != len(rules) {
+		return apperror.BadRequest("rule order must list all %d rules of the parameter, got %d", len(rules), len(ruleIDs))
+	}
+	existing := make(map[uint]bool, len(rules))
+	for _, rule := range rules {
+		existing[rule.ID] = true
+	}
+	seen := make(map[uint]bool, len(ruleIDs))
+	for _, ruleID := range ruleIDs {
+		if !existing[ruleID] {
+			return apperror.BadRequest("rule with ID %d does not belong to the parameter", ruleID)
+		}
+		if seen[ruleID] {
+			return apperror.BadRequest("rule with ID %d is listed more than once", ruleID)
+		}
+		seen[ruleID] = true
+	}
+	return nil
+}
+
+// rulePriority returns the requested priority of a rule, or fallback when none was requested
+func rulePriority(requested *int, fallback int) int {
+	if requested != nil {
+		return *requested
+	}
+	return fallback
+}
+
+// validateRuleRollout checks that a rule's rollout percentage is within 0-100 and that a partial
+// rollout names the attribute users are bucketed by
+func validateRuleRollout(rule string, percentage *int, hashAttributeName *string) error {
+	if percentage == nil {
+		return nil
+	}
+	if *percentage < 0 || *percentage > 100 {
+		return apperror.BadRequest("rollout percentage of rule '%s' must be between 0 and 100", rule)
+	}
+	if *percentage < 100 && (hashAttributeName == nil || *hashAttributeName == "") {
+		return apperror.BadRequest("a hash attribute is required to roll out rule '%s' to %d%% of users", rule, *percentage)
+	}
+	return nil
+}
+
+// nextRulePriority returns the priority placing a new rule after all existing ones
+func nextRulePriority(rules []model.ParameterRule) int {
+	next := 0
+	for _, rule := range rules {
+		if rule.Priority >= next {
+			next = rule.Priority + 1
+		}
+	}
+	return next
+}
+
+// auditParameterChange marks the parameter as last modified by the user, refreshes its raw_value after
+// a rule change and records the change against the parameter, using the raw_value loaded before the
+// change as the before snapshot
+func (s *service) auditParameterChange(ctx context.Context, txRepo repository.Repository, userID uint, parameter *model.Parameter, action model.AuditAction) error {
+	if err := txRepo.SetParameterUpdatedBy(ctx, parameter.ID, userID); err != nil {
+		return err
+	}
+	if err := txRepo.UpdateParameterRawValue(ctx, parameter.ID); err != nil {
+		return fmt.Errorf("failed to update parameter raw value: %w", err)
+	}
+
+	after, err := parameterSnapshot(ctx, txRepo, parameter.ID)
+	if err != nil {
+		return err
+	}
+	return s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, parameter.ID, action, parameter.RawValue, after)
+}
+
 // IncrementParameterUsageCount increments the usage count for a parameter
 func (s *service) IncrementParameterUsageCount(ctx context.Context, id uint) error {
 	return s.repo.IncrementParameterUsageCount(ctx, id)
@@ -586,62 +1036,228 @@ func (s *service) DecrementParameterUsageCount(ctx context.Context, id uint) err
 	return s.repo.DecrementParameterUsageCount(ctx, id)
 }
 
-// validateParameterValue validates a parameter value based on its data type
-func (s *service) validateParameterValue(value interface{}, dataType model.ParameterDataType) error {
+// validateParameterValue validates a parameter value based on its data type.
+// enumOptions is only used for enum parameters and constraints only for number parameters.
+func (s *service) validateParameterValue(value interface{}, dataType model.ParameterDataType, enumOptions []string, constraints *model.NumberConstraints) error {
 	switch dataType {
 	case model.ParameterDataTypeBoolean:
 		if _, ok := value.(bool); !ok {
-			return errors.New("value must be a boolean for boolean parameter")
+			return apperror.BadRequest("value must be a boolean for boolean parameter")
 		}
 	case model.ParameterDataTypeString:
 		if _, ok := value.(string); !ok {
-			return errors.New("value must be a string for string parameter")
+			return apperror.BadRequest("value must be a string for string parameter")
 		}
 	case model.ParameterDataTypeNumber:
+		number, ok := numberValue(value)
+		switch {
+		case value == nil:
+			return apperror.BadRequest("value cannot be nil for number parameter")
+		case !ok:
+			return apperror.BadRequest("value must be a valid number for number parameter, got %T", value)
+		}
+		if err := constraints.Check(number); err != nil {
+			return apperror.BadRequest("%v", err)
+		}
+	case model.ParameterDataTypeDate:
 		switch v := value.(type) {
-		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-			// Valid number types
+		case string:
+			// Strings are read as the SDK reads them, so epoch seconds may be sent as strings too
+			if _, err := types.ParseDate(v); err != nil {
+				return apperror.BadRequest("value must be an RFC3339 string or unix epoch integer for date parameter")
+			}
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			// Valid unix epoch
+		case float64:
+			// JSON numbers decode as float64, so only whole numbers are valid epochs
+			if v != math.Trunc(v) {
+				return apperror.BadRequest("value must be an RFC3339 string or unix epoch integer for date parameter")
+			}
 		case nil:
-			return errors.New("value cannot be nil for number parameter")
+			return apperror.BadRequest("value cannot be nil for date parameter")
 		default:
-			return fmt.Errorf("value must be a valid number for number parameter, got %T", v)
+			return apperror.BadRequest("value must be an RFC3339 string or unix epoch integer for date parameter, got %T", v)
+		}
+	case model.ParameterDataTypeEnum:
+		v, ok := value.(string)
+		if !ok {
+			return apperror.BadRequest("value must be a string for enum parameter")
+		}
+		if !slices.Contains(enumOptions, v) {
+			return apperror.BadRequest("invalid value %q for enum parameter: must be one of [%s]", v, strings.Join(enumOptions, ", "))
 		}
+	case model.ParameterDataTypeJSON:
+		switch v := value.(type) {
+		case map[string]interface{}, []interface{}:
+			if _, err := json.Marshal(v); err != nil {
+				return apperror.BadRequest("value must be serializable to JSON for json parameter: %v", err)
+			}
+		case string:
+			// Already serialized JSON is stored as is and reaches the SDK unchanged
+			var decoded interface{}
+			if err := types.ParseJSON(v, &decoded); err != nil {
+				return apperror.BadRequest("value must be a JSON object or array for json parameter: %v", err)
+			}
+		case nil:
+			return apperror.BadRequest("value cannot be nil for json parameter")
+		default:
+			return apperror.BadRequest("value must be a JSON object or array for json parameter, got %T", v)
+		}
+	default:
+		return apperror.BadRequest("unsupported parameter data type: %s", dataType)
+	}
+	return nil
+}
+
+// numberValue converts a value of any Go number type to float64
+func numberValue(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
 	default:
-		return fmt.Errorf("unsupported parameter data type: %s", dataType)
+		return 0, false
+	}
+}
+
+// validateEnumOptions validates the option list of an enum parameter
+func (s *service) validateEnumOptions(enumOptions []string) error {
+	if len(enumOptions) == 0 {
+		return apperror.BadRequest("enum options are required for enum data type")
+	}
+	seen := make(map[string]bool, len(enumOptions))
+	for _, option := range enumOptions {
+		if option == "" {
+			return apperror.BadRequest("invalid enum options: option cannot be empty")
+		}
+		if seen[option] {
+			return apperror.BadRequest("invalid enum options: duplicate option %q", option)
+		}
+		seen[option] = true
+	}
+	return nil
+}
+
+// resolveEnumOptions returns the enum options a parameter will have after an update
+func (s *service) resolveEnumOptions(parameter *model.Parameter, dataType model.ParameterDataType, requested []string) ([]string, error) {
+	if dataType != model.ParameterDataTypeEnum {
+		return []string{}, nil
+	}
+	enumOptions := []string(parameter.EnumOptions)
+	if requested != nil {
+		enumOptions = requested
+	}
+	if err := s.validateEnumOptions(enumOptions); err != nil {
+		return nil, err
+	}
+	return enumOptions, nil
+}
+
+// resolveNumberConstraints returns the number constraints a parameter will have after an update. Requested
+// constraints replace the current ones, and empty constraints remove them.
+func (s *service) resolveNumberConstraints(current *model.NumberConstraints, dataType model.ParameterDataType, requested *model.NumberConstraints) (*model.NumberConstraints, error) {
+	if dataType != model.ParameterDataTypeNumber {
+		if !requested.Empty() {
+			return nil, apperror.BadRequest("number constraints only apply to number parameters")
+		}
+		return nil, nil
+	}
+	constraints := current
+	if requested != nil {
+		constraints = requested
+	}
+	if constraints.Empty() {
+		return nil, nil
+	}
+	if err := constraints.Validate(); err != nil {
+		return nil, apperror.BadRequest("invalid number constraints: %v", err)
+	}
+	return constraints, nil
+}
+
+// validateExistingRolloutValues validates the stored default and rule rollout values of a parameter
+// against a new data type, option list or number constraints, so that removing an option still in use fails
+func (s *service) validateExistingRolloutValues(parameter *model.Parameter, dataType model.ParameterDataType, enumOptions []string, constraints *model.NumberConstraints, validateDefault bool, validateRules bool) error {
+	if validateDefault {
+		if err := s.validateParameterValue(parameter.DefaultRolloutValue.Data, dataType, enumOptions, constraints); err != nil {
+			return apperror.BadRequest("existing default rollout value is invalid: %v", err)
+		}
+	}
+	for _, condition := range parameter.Conditions {
+		if err := s.validateParameterValue(condition.RolloutValue.Data, dataType, enumOptions, constraints); err != nil {
+			return apperror.BadRequest("existing condition rollout value is invalid: %v", err)
+		}
+	}
+	if validateRules {
+		for _, rule := range parameter.Rules {
+			if err := s.validateParameterValue(rule.RolloutValue.Data, dataType, enumOptions, constraints); err != nil {
+				return apperror.BadRequest("existing rule '%s' rollout value is invalid: %v", rule.Name, err)
+			}
+		}
 	}
 	return nil
 }
 
 // withTransaction executes a function within a database transaction
 func (s *service) withTransaction(ctx context.Context, fn func(repository.Repository) (*model.Parameter, error)) (*model.Parameter, error) {
+	var result *model.Parameter
+	err := s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		var err error
+		result, err = fn(txRepo)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// inTransaction executes a function within a database transaction, committing only if it succeeds
+func (s *service) inTransaction(ctx context.Context, fn func(repository.Repository) error) error {
 	// Get the underlying GORM DB from the repository
 	db := s.getDB()
 
 	// Start transaction
 	tx := db.WithContext(ctx).Begin()
 	if tx.Error != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
 	}
 
 	// Create a new repository instance with the transaction
 	txRepo := repository.New(tx)
 
 	// Execute the function with the transaction repository
-	result, err := fn(txRepo)
-	if err != nil {
+	if err := fn(txRepo); err != nil {
 		// Rollback on error
 		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
-			return nil, fmt.Errorf("transaction failed: %v, rollback failed: %w", err, rollbackErr)
+			return fmt.Errorf("transaction failed: %v, rollback failed: %w", err, rollbackErr)
 		}
-		return nil, err
+		return err
 	}
 
 	// Commit the transaction
 	if commitErr := tx.Commit().Error; commitErr != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", commitErr)
+		return fmt.Errorf("failed to commit transaction: %w", commitErr)
 	}
 
-	return result, nil
+	return nil
+}
+
+// enqueueTx inserts a river job through the transaction behind txRepo, so workers only
+// see the job once the data change it syncs has been committed
+func (s *service) enqueueTx(ctx context.Context, txRepo repository.Repository, args river.JobArgs) error {
+	tx, ok := txRepo.GetDB().Statement.ConnPool.(*sql.Tx)
+	if !ok {
+		return fmt.Errorf("failed to enqueue %s job: repository is not in a transaction", args.Kind())
+	}
+	if _, err := s.riverClient.InsertTx(ctx, tx, args, nil); err != nil {
+		return fmt.Errorf("failed to enqueue %s job: %w", args.Kind(), err)
+	}
+	return nil
 }
 
 // getDB extracts the underlying GORM DB from the repository
@@ -664,8 +1280,163 @@ func (s *service) SimulateParameter(ctx context.Context, req *dto.SimulateParame
 
 	logger := log.Ctx(ctx).With().Str("service", "simulate-parameter").Logger()
 
+	attribute := newSimulationAttribute(logger, req.Attributes)
+
+	logger.Info().Interface("attribute", attribute.Keys()).Msg("Simulating parameter")
+
+	var (
+		rolloutValue sdk.RolloutValue
+		details      types.EvaluationDetails
+		// Rule and segment names are not part of the SDK data, so they come from the stored parameter
+		parameter *model.Parameter
+	)
+	if req.Environment != "" {
+		var err error
+		rolloutValue, details, parameter, err = s.simulateParameterInEnvironment(ctx, req.ParameterName, req.Environment, attribute)
+		if err != nil {
+			return dto.SimulateParameterResponse{}, err
+		}
+	} else {
+		rolloutValue, details = s.auroraClient.EvaluateParameterTraced(ctx, req.ParameterName, attribute)
+		if details.Trace != nil {
+			var err error
+			parameter, err = s.repo.GetParameterByName(ctx, req.ParameterName)
+			if err != nil {
+				logger.Warn().Err(err).Msg("Failed to load parameter rule names for simulation trace")
+			}
+		}
+	}
+
+	var value interface{}
+	switch req.ParameterType {
+	case model.ParameterDataTypeBoolean:
+		value = rolloutValue.AsBool(false)
+	case model.ParameterDataTypeString:
+		value = rolloutValue.AsString("")
+	case model.ParameterDataTypeNumber:
+		value = rolloutValue.AsNumber(0)
+	case model.ParameterDataTypeDate:
+		value = rolloutValue.AsTime(time.Time{})
+	case model.ParameterDataTypeEnum:
+		value = rolloutValue.AsEnum("")
+	case model.ParameterDataTypeJSON:
+		// Return the parsed object rather than the stored string
+		if err := rolloutValue.AsJSON(&value); err != nil {
+			value = nil
+		}
+	}
+
+	return dto.SimulateParameterResponse{
+		Value:       value,
+		EnumOptions: rolloutValue.EnumOptions(),
+		Trace:       simulationTraceResponse(details, rolloutValue.Reason(), parameter),
+	}, nil
+}
+
+// simulateParameterInEnvironment evaluates a parameter as served to SDK clients of an environment, which
+// the API's own client, fetching the base definitions, cannot do. It also returns the parameter as defined
+// in the environment, to name the rules of the trace.
+func (s *service) simulateParameterInEnvironment(ctx context.Context, parameterName string, environmentKey string, attribute *sdk.Attribute) (sdk.RolloutValue, types.EvaluationDetails, *model.Parameter, error) {
+	environment, err := s.resolveEnvironment(ctx, environmentKey)
+	if err != nil {
+		return sdk.RolloutValue{}, types.EvaluationDetails{}, nil, err
+	}
+
+	parameter, err := s.repo.GetParameterByName(ctx, parameterName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return sdk.RolloutValue{}, types.EvaluationDetails{}, nil, apperror.NotFound("parameter '%s' not found", parameterName)
+		}
+		return sdk.RolloutValue{}, types.EvaluationDetails{}, nil, err
+	}
+
+	override, err := s.repo.GetParameterEnvironmentOverride(ctx, parameter.ID, environment.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return sdk.RolloutValue{}, types.EvaluationDetails{}, nil, err
+	}
+	if override != nil {
+		rawValue := override.RawValue
+		parameter = override.Apply(parameter)
+		parameter.RawValue = rawValue
+	}
+
+	sdkParameter, err := mapper.ParameterToSDKFromRawValue(parameter)
+	if err != nil {
+		return sdk.RolloutValue{}, types.EvaluationDetails{}, nil, fmt.Errorf("failed to convert parameter: %w", err)
+	}
+	experiments, err := s.GetActiveExperimentsSDK(ctx, environmentKey)
+	if err != nil {
+		return sdk.RolloutValue{}, types.EvaluationDetails{}, nil, err
+	}
+
+	rolloutValue, details := s.auroraClient.EvaluateParameterTracedWith(ctx, &sdkParameter, experiments, attribute)
+	return rolloutValue, details, parameter, nil
+}
+
+// simulationTraceResponse converts the details of a traced evaluation, naming rules and segments
+// after those of parameter when it is set
+func simulationTraceResponse(details types.EvaluationDetails, reason types.EvaluationReason, parameter *model.Parameter) dto.SimulationTraceResponse {
+	response := dto.SimulationTraceResponse{
+		Source:         details.Source,
+		Reason:         string(reason),
+		ExperimentID:   details.ExperimentID,
+		ExperimentUUID: details.ExperimentUUID,
+		VariantID:      details.VariantID,
+		VariantName:    details.VariantName,
+		Rules:          []dto.SimulationRuleTraceResponse{},
+	}
+	if details.Trace == nil {
+		return response
+	}
+
+	rulesByID := make(map[uint]*model.ParameterRule)
+	if parameter != nil {
+		for i := range parameter.Rules {
+			rulesByID[parameter.Rules[i].ID] = &parameter.Rules[i]
+		}
+	}
+
+	response.MatchedRuleID = details.Trace.MatchedRuleID
+	if details.Trace.MatchedRuleID != nil {
+		if rule, ok := rulesByID[*details.Trace.MatchedRuleID]; ok {
+			response.MatchedRuleName = rule.Name
+		}
+	}
+
+	for _, ruleTrace := range details.Trace.Rules {
+		ruleResponse := dto.SimulationRuleTraceResponse{
+			RuleID:     ruleTrace.RuleID,
+			Type:       string(ruleTrace.Type),
+			MatchType:  string(ruleTrace.MatchType),
+			SegmentID:  ruleTrace.SegmentID,
+			Matched:    ruleTrace.Matched,
+			Conditions: make([]dto.SimulationConditionTraceResponse, len(ruleTrace.Conditions)),
+		}
+		if rule, ok := rulesByID[ruleTrace.RuleID]; ok {
+			ruleResponse.RuleName = rule.Name
+			if rule.Segment != nil {
+				ruleResponse.SegmentName = rule.Segment.Name
+			}
+		}
+		for i, condition := range ruleTrace.Conditions {
+			ruleResponse.Conditions[i] = dto.SimulationConditionTraceResponse{
+				SegmentRuleID: condition.SegmentRuleID,
+				Attribute:     condition.Attribute,
+				Operator:      string(condition.Operator),
+				Expected:      condition.Expected,
+				Actual:        condition.Actual,
+				Matched:       condition.Matched,
+			}
+		}
+		response.Rules = append(response.Rules, ruleResponse)
+	}
+	return response
+}
+
+// newSimulationAttribute builds an SDK attribute from simulated attributes, skipping values that do not parse as their data type
+func newSimulationAttribute(logger zerolog.Logger, attributes []dto.SimulateAttributeRequest) *sdk.Attribute {
 	attribute := sdk.NewAttribute()
-	for _, attributeReq := range req.Attributes {
+	for _, attributeReq := range attributes {
 		logger.Info().Str("attribute", attributeReq.Name).Str("dataType", string(attributeReq.DataType)).Str("value", attributeReq.Value).Msg("Simulating attribute")
 		switch attributeReq.DataType {
 		case model.DataTypeBoolean:
@@ -680,31 +1451,21 @@ func (s *service) SimulateParameter(ctx context.Context, req *dto.SimulateParame
 			if err == nil {
 				attribute.SetNumber(attributeReq.Name, value)
 			}
-		case model.DataTypeEnum:
+		case model.DataTypeEnum, model.DataTypeDate:
 			attribute.SetString(attributeReq.Name, attributeReq.Value)
 		}
-
 	}
+	return attribute
+}
 
-	logger.Info().Interface("attribute", attribute.Keys()).Msg("Simulating parameter")
-
-	rolloutValue := s.auroraClient.EvaluateParameter(ctx, req.ParameterName, attribute)
-	var value interface{}
-	switch req.ParameterType {
-	case model.ParameterDataTypeBoolean:
-		value = rolloutValue.AsBool(false)
-	case model.ParameterDataTypeString:
-		value = rolloutValue.AsString("")
-	case model.ParameterDataTypeNumber:
-		value = rolloutValue.AsNumber(0)
+// GetAllParametersSDK returns the parameters served to SDK clients of an environment, identified by its key.
+// Parameters overridden in the environment are served from the override's raw_value, the others as defined.
+func (s *service) GetAllParametersSDK(ctx context.Context, environmentKey string) ([]types.Parameter, error) {
+	environment, err := s.resolveEnvironment(ctx, environmentKey)
+	if err != nil {
+		return nil, err
 	}
 
-	return dto.SimulateParameterResponse{
-		Value: value,
-	}, nil
-}
-
-func (s *service) GetAllParametersSDK(ctx context.Context) ([]types.Parameter, error) {
 	// Use optimized method that only queries id and raw_value fields
 	// This avoids expensive preloading since raw_value contains all necessary data
 	parameters, err := s.repo.GetAllParametersForSDK(ctx)
@@ -712,5 +1473,31 @@ func (s *service) GetAllParametersSDK(ctx context.Context) ([]types.Parameter, e
 		return nil, err
 	}
 
+	if environment != nil {
+		rawValues, err := s.repo.GetParameterEnvironmentRawValues(ctx, environment.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, parameter := range parameters {
+			if rawValue, ok := rawValues[parameter.ID]; ok {
+				parameter.RawValue = rawValue
+			}
+		}
+	}
+
 	return mapper.ParametersToSDKFromRawValue(parameters)
 }
+
+// GetParametersSDKETag returns an ETag for the SDK parameters payload of an environment, derived from the
+// latest parameter update and the parameter count so that deletions also change it. Overrides refresh the
+// raw_value of their parameter, so they change it as well.
+func (s *service) GetParametersSDKETag(ctx context.Context, environmentKey string) (string, error) {
+	maxUpdatedAt, count, err := s.repo.GetParametersSDKVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	if environmentKey != "" {
+		return fmt.Sprintf(`"%d-%d-%s"`, maxUpdatedAt.UnixNano(), count, environmentKey), nil
+	}
+	return fmt.Sprintf(`"%d-%d"`, maxUpdatedAt.UnixNano(), count), nil
+}
@@ -1,10 +1,12 @@
 package service
 
 import (
+	"api/internal/apperror"
 	"api/internal/dto"
 	"api/internal/model"
 	"api/internal/repository"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -19,12 +21,17 @@ func (s *service) convertParameterToCurrentConfig(parameter *model.Parameter) mo
 	rules := make([]model.ParameterRuleRequest, len(parameter.Rules))
 	for i, rule := range parameter.Rules {
 		ruleReq := model.ParameterRuleRequest{
-			Name:         rule.Name,
-			Description:  rule.Description,
-			Type:         rule.Type,
-			RolloutValue: rule.RolloutValue.Data,
-			SegmentID:    rule.SegmentID,
-			MatchType:    rule.MatchType,
+			Name:              rule.Name,
+			Description:       rule.Description,
+			Type:              rule.Type,
+			RolloutValue:      rule.RolloutValue.Data,
+			SegmentID:         rule.SegmentID,
+			MatchType:         rule.MatchType,
+			ConditionLogic:    rule.ConditionLogic,
+			Priority:          &rule.Priority,
+			RolloutPercentage: rule.RolloutPercentage,
+			HashAttributeName: rule.HashAttributeName,
+			Negate:            rule.Negate,
 		}
 
 		// Convert conditions if they exist
@@ -47,34 +54,84 @@ func (s *service) convertParameterToCurrentConfig(parameter *model.Parameter) mo
 		Description:         parameter.Description,
 		DataType:            parameter.DataType,
 		DefaultRolloutValue: parameter.DefaultRolloutValue.Data,
+		EnumOptions:         parameter.EnumOptions,
+		NumberConstraints:   parameter.NumberConstraints,
 		Rules:               rules,
 	}
 }
 
-// CreateParameterChangeRequest creates a new parameter change request
+// CreateParameterChangeRequest creates a new parameter change request. Requests update a parameter
+// unless their type says otherwise; create requests carry the whole new parameter in their change data.
 func (s *service) CreateParameterChangeRequest(ctx context.Context, userID uint, req *dto.CreateParameterChangeRequestRequest) (*model.ParameterChangeRequest, error) {
 	logger := log.Ctx(ctx).With().Str("service", "create-parameter-change-request").Uint("parameterId", req.ParameterID).Logger()
 
-	// Check if parameter exists and load its rules
-	parameter, err := s.repo.GetParameterByID(ctx, req.ParameterID)
+	requestType := req.RequestType
+	if requestType == "" {
+		requestType = model.ChangeRequestTypeUpdate
+	}
+
+	changeRequest := &model.ParameterChangeRequest{
+		RequestType:       requestType,
+		RequestedByUserID: userID,
+		Status:            model.ChangeRequestStatusPending,
+		Description:       req.Description,
+	}
+
+	var err error
+	switch requestType {
+	case model.ChangeRequestTypeCreate:
+		changeRequest.ChangeData, err = s.parameterCreationChangeData(ctx, userID, req)
+	case model.ChangeRequestTypeDelete:
+		changeRequest.ParameterID = &req.ParameterID
+		changeRequest.CurrentConfig, err = s.parameterDeletionConfig(ctx, req.ParameterID)
+	case model.ChangeRequestTypeUpdate:
+		changeRequest.ParameterID = &req.ParameterID
+		changeRequest.ChangeData, changeRequest.CurrentConfig, err = s.parameterUpdateChangeData(ctx, req)
+	default:
+		return nil, apperror.BadRequest("invalid request type '%s': must be create, update or delete", requestType)
+	}
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("parameter with ID %d not found", req.ParameterID)
-		}
 		return nil, err
 	}
 
-	// Check if there's already a pending change request for this parameter
-	existing, err := s.repo.GetPendingParameterChangeRequestByParameterID(ctx, req.ParameterID)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	if err := s.repo.CreateParameterChangeRequest(ctx, changeRequest); err != nil {
+		logger.Error().Err(err).Msg("Failed to create parameter change request")
 		return nil, err
 	}
-	if existing != nil {
-		return nil, fmt.Errorf("parameter '%s' already has a pending change request (ID: %d). Please approve or reject it before creating a new one", parameter.Name, existing.ID)
+
+	// Reload with relationships
+	return s.repo.GetParameterChangeRequestByID(ctx, changeRequest.ID)
+}
+
+// parameterUpdateChangeData validates the proposed changes to a parameter, returning them along with the
+// parameter's current configuration
+func (s *service) parameterUpdateChangeData(ctx context.Context, req *dto.CreateParameterChangeRequestRequest) (model.ParameterChangeData, model.ParameterCurrentConfig, error) {
+	parameter, err := s.getParameterWithoutPendingChangeRequest(ctx, req.ParameterID)
+	if err != nil {
+		return model.ParameterChangeData{}, model.ParameterCurrentConfig{}, err
 	}
 
-	// Capture current parameter configuration
-	currentConfig := s.convertParameterToCurrentConfig(parameter)
+	// Check proposed values against the number constraints up front, so that they are not only found on approval
+	dataType := parameter.DataType
+	if req.DataType != nil {
+		dataType = *req.DataType
+	}
+	constraints, err := s.resolveNumberConstraints(parameter.NumberConstraints, dataType, req.NumberConstraints)
+	if err != nil {
+		return model.ParameterChangeData{}, model.ParameterCurrentConfig{}, err
+	}
+	if constraints != nil {
+		if req.DefaultRolloutValue != nil {
+			if err := s.validateParameterValue(req.DefaultRolloutValue, dataType, nil, constraints); err != nil {
+				return model.ParameterChangeData{}, model.ParameterCurrentConfig{}, apperror.BadRequest("invalid default rollout value: %v", err)
+			}
+		}
+		for _, rule := range req.Rules {
+			if err := s.validateParameterValue(rule.RolloutValue, dataType, nil, constraints); err != nil {
+				return model.ParameterChangeData{}, model.ParameterCurrentConfig{}, apperror.BadRequest("invalid rollout value for rule '%s': %v", rule.Name, err)
+			}
+		}
+	}
 
 	// Build the change data from the request
 	changeData := model.ParameterChangeData{
@@ -82,54 +139,178 @@ func (s *service) CreateParameterChangeRequest(ctx context.Context, userID uint,
 		Description:         req.ParameterDescription,
 		DataType:            req.DataType,
 		DefaultRolloutValue: req.DefaultRolloutValue,
+		EnumOptions:         req.EnumOptions,
+		NumberConstraints:   req.NumberConstraints,
+	}
+	changeData.Rules, err = s.changeRequestRules(ctx, req.Rules)
+	if err != nil {
+		return model.ParameterChangeData{}, model.ParameterCurrentConfig{}, err
 	}
 
-	// Convert rules if provided
-	if len(req.Rules) > 0 {
-		changeData.Rules = make([]model.ParameterRuleRequest, len(req.Rules))
-		for i, rule := range req.Rules {
-			ruleReq := model.ParameterRuleRequest{
-				Name:         rule.Name,
-				Description:  rule.Description,
-				Type:         rule.Type,
-				RolloutValue: rule.RolloutValue,
-				SegmentID:    rule.SegmentID,
-				MatchType:    rule.MatchType,
-			}
+	// Capture current parameter configuration
+	return changeData, s.convertParameterToCurrentConfig(parameter), nil
+}
 
-			// Convert conditions if provided
-			if len(rule.Conditions) > 0 {
-				ruleReq.Conditions = make([]model.ParameterRuleConditionRequest, len(rule.Conditions))
-				for j, cond := range rule.Conditions {
-					ruleReq.Conditions[j] = model.ParameterRuleConditionRequest{
-						AttributeID: cond.AttributeID,
-						Operator:    cond.Operator,
-						Value:       cond.Value,
-					}
-				}
-			}
+// parameterCreationChangeData validates the parameter a create request proposes, returning it as change data.
+// The name must be free of both existing parameters and other pending create requests.
+func (s *service) parameterCreationChangeData(ctx context.Context, userID uint, req *dto.CreateParameterChangeRequestRequest) (model.ParameterChangeData, error) {
+	if req.ParameterID != 0 {
+		return model.ParameterChangeData{}, apperror.BadRequest("create requests cannot target existing parameter %d", req.ParameterID)
+	}
+	if req.Name == nil || *req.Name == "" || req.DataType == nil || req.DefaultRolloutValue == nil {
+		return model.ParameterChangeData{}, apperror.BadRequest("create requests need a name, data type and default rollout value")
+	}
 
-			changeData.Rules[i] = ruleReq
+	if err := ensureParameterNameAvailable(ctx, s.repo, *req.Name); err != nil {
+		return model.ParameterChangeData{}, err
+	}
+	existing, err := s.repo.GetPendingParameterCreationRequestByName(ctx, *req.Name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.ParameterChangeData{}, err
+	}
+	if existing != nil {
+		return model.ParameterChangeData{}, apperror.Conflict("parameter '%s' is already requested by pending change request %d. Please approve or reject it before creating a new one", *req.Name, existing.ID)
+	}
+
+	// Validate the new parameter the same way as creating it directly
+	parameter, err := s.newParameter(userID, parameterCreationRequest(model.ParameterChangeData{
+		Name:                req.Name,
+		Description:         req.ParameterDescription,
+		DataType:            req.DataType,
+		DefaultRolloutValue: req.DefaultRolloutValue,
+		EnumOptions:         req.EnumOptions,
+		NumberConstraints:   req.NumberConstraints,
+	}))
+	if err != nil {
+		return model.ParameterChangeData{}, err
+	}
+	for _, rule := range req.Rules {
+		if err := s.validateParameterValue(rule.RolloutValue, parameter.DataType, parameter.EnumOptions, parameter.NumberConstraints); err != nil {
+			return model.ParameterChangeData{}, apperror.BadRequest("invalid rollout value for rule '%s': %v", rule.Name, err)
 		}
 	}
 
-	// Create the change request
-	changeRequest := &model.ParameterChangeRequest{
-		ParameterID:       req.ParameterID,
-		RequestedByUserID: userID,
-		Status:            model.ChangeRequestStatusPending,
-		Description:       req.Description,
-		ChangeData:        changeData,
-		CurrentConfig:     currentConfig,
+	changeData := model.ParameterChangeData{
+		Name:                &parameter.Name,
+		Description:         &parameter.Description,
+		DataType:            &parameter.DataType,
+		DefaultRolloutValue: parameter.DefaultRolloutValue.Data,
+		EnumOptions:         parameter.EnumOptions,
+		NumberConstraints:   parameter.NumberConstraints,
+	}
+	changeData.Rules, err = s.changeRequestRules(ctx, req.Rules)
+	if err != nil {
+		return model.ParameterChangeData{}, err
 	}
+	return changeData, nil
+}
 
-	if err := s.repo.CreateParameterChangeRequest(ctx, changeRequest); err != nil {
-		logger.Error().Err(err).Msg("Failed to create parameter change request")
+// parameterDeletionConfig checks that a parameter can be deleted, returning its current configuration.
+// Whether it is unused is checked again on approval, since experiments may start using it in between.
+func (s *service) parameterDeletionConfig(ctx context.Context, parameterID uint) (model.ParameterCurrentConfig, error) {
+	parameter, err := s.getParameterWithoutPendingChangeRequest(ctx, parameterID)
+	if err != nil {
+		return model.ParameterCurrentConfig{}, err
+	}
+	if err := ensureParameterUnused(parameter); err != nil {
+		return model.ParameterCurrentConfig{}, err
+	}
+	return s.convertParameterToCurrentConfig(parameter), nil
+}
+
+// getParameterWithoutPendingChangeRequest loads a parameter with its rules, rejecting parameters that
+// already have a pending change request
+func (s *service) getParameterWithoutPendingChangeRequest(ctx context.Context, parameterID uint) (*model.Parameter, error) {
+	parameter, err := s.repo.GetParameterByID(ctx, parameterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("parameter with ID %d not found", parameterID)
+		}
 		return nil, err
 	}
 
-	// Reload with relationships
-	return s.repo.GetParameterChangeRequestByID(ctx, changeRequest.ID)
+	existing, err := s.repo.GetPendingParameterChangeRequestByParameterID(ctx, parameterID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, apperror.Conflict("parameter '%s' already has a pending change request (ID: %d). Please approve or reject it before creating a new one", parameter.Name, existing.ID)
+	}
+	return parameter, nil
+}
+
+// changeRequestRules converts the rules of a change request, validating their rollout and conditions up
+// front so that an invalid enum value is not only found on approval
+func (s *service) changeRequestRules(ctx context.Context, rules []dto.CreateParameterRuleRequest) ([]model.ParameterRuleRequest, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	var validator conditionValidator
+	ruleReqs := make([]model.ParameterRuleRequest, len(rules))
+	for i, rule := range rules {
+		if err := validateRuleRollout(rule.Name, rule.RolloutPercentage, rule.HashAttributeName); err != nil {
+			return nil, err
+		}
+		ruleReq := model.ParameterRuleRequest{
+			Name:              rule.Name,
+			Description:       rule.Description,
+			Type:              rule.Type,
+			RolloutValue:      rule.RolloutValue,
+			SegmentID:         rule.SegmentID,
+			MatchType:         rule.MatchType,
+			ConditionLogic:    rule.ConditionLogic,
+			Priority:          rule.Priority,
+			RolloutPercentage: rule.RolloutPercentage,
+			HashAttributeName: rule.HashAttributeName,
+			Negate:            rule.Negate,
+		}
+
+		// Convert conditions if provided
+		if len(rule.Conditions) > 0 {
+			ruleReq.Conditions = make([]model.ParameterRuleConditionRequest, len(rule.Conditions))
+			for j, cond := range rule.Conditions {
+				attribute, err := s.repo.GetAttributeByID(ctx, cond.AttributeID)
+				if err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil, apperror.NotFound("attribute with ID %d not found", cond.AttributeID)
+					}
+					return nil, err
+				}
+				validator.check(rule.Name, attribute, cond.Operator, cond.Value)
+				ruleReq.Conditions[j] = model.ParameterRuleConditionRequest{
+					AttributeID: cond.AttributeID,
+					Operator:    cond.Operator,
+					Value:       cond.Value,
+				}
+			}
+		}
+
+		ruleReqs[i] = ruleReq
+	}
+	if err := validator.err(); err != nil {
+		return nil, err
+	}
+	return ruleReqs, nil
+}
+
+// parameterCreationRequest turns the change data of a create request back into the request to create the parameter
+func parameterCreationRequest(changeData model.ParameterChangeData) *dto.CreateParameterRequest {
+	req := &dto.CreateParameterRequest{
+		DefaultRolloutValue: changeData.DefaultRolloutValue,
+		EnumOptions:         changeData.EnumOptions,
+		NumberConstraints:   changeData.NumberConstraints,
+	}
+	if changeData.Name != nil {
+		req.Name = *changeData.Name
+	}
+	if changeData.Description != nil {
+		req.Description = *changeData.Description
+	}
+	if changeData.DataType != nil {
+		req.DataType = *changeData.DataType
+	}
+	return req
 }
 
 // GetParameterChangeRequestByID retrieves a parameter change request by ID
@@ -137,7 +318,7 @@ func (s *service) GetParameterChangeRequestByID(ctx context.Context, id uint) (*
 	changeRequest, err := s.repo.GetParameterChangeRequestByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("parameter change request with ID %d not found", id)
+			return nil, apperror.NotFound("parameter change request with ID %d not found", id)
 		}
 		return nil, err
 	}
@@ -161,22 +342,23 @@ func (s *service) GetParameterChangeRequestsByParameterID(ctx context.Context, p
 	return s.repo.GetParameterChangeRequestsByParameterID(ctx, parameterID)
 }
 
-// ApproveParameterChangeRequest approves a change request and applies the changes
-func (s *service) ApproveParameterChangeRequest(ctx context.Context, id uint, userID uint, req *dto.ApproveParameterChangeRequestRequest) (*model.ParameterChangeRequest, error) {
+// ApproveParameterChangeRequest approves a change request and creates, updates or deletes the parameter
+// according to its type, returning the diff of the applied changes against the parameter as it was when approved
+func (s *service) ApproveParameterChangeRequest(ctx context.Context, id uint, userID uint, req *dto.ApproveParameterChangeRequestRequest) (*model.ParameterChangeRequest, *dto.ParameterChangeRequestDiff, error) {
 	logger := log.Ctx(ctx).With().Str("service", "approve-parameter-change-request").Uint("id", id).Logger()
 
 	// Get the change request
 	changeRequest, err := s.repo.GetParameterChangeRequestByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("parameter change request with ID %d not found", id)
+			return nil, nil, apperror.NotFound("parameter change request with ID %d not found", id)
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check if it's still pending
 	if changeRequest.Status != model.ChangeRequestStatusPending {
-		return nil, fmt.Errorf("change request is not pending (current status: %s)", changeRequest.Status)
+		return nil, nil, apperror.Conflict("change request is not pending (current status: %s)", changeRequest.Status)
 	}
 
 	// Get the underlying GORM DB
@@ -185,97 +367,50 @@ func (s *service) ApproveParameterChangeRequest(ctx context.Context, id uint, us
 	// Start transaction
 	tx := db.WithContext(ctx).Begin()
 	if tx.Error != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
 	}
 
 	// Create a new repository instance with the transaction
 	txRepo := repository.New(tx)
 
 	// Execute the transaction logic
+	var diff *dto.ParameterChangeRequestDiff
 	err = func() error {
-		// Get parameter with transaction
-		parameter, err := txRepo.GetParameterByID(ctx, changeRequest.ParameterID)
-		if err != nil {
-			return fmt.Errorf("failed to get parameter: %w", err)
-		}
-
-		// Apply the changes from changeData
-		if changeRequest.ChangeData.Name != nil {
-			// Check if new name conflicts with existing
-			if *changeRequest.ChangeData.Name != parameter.Name {
-				existing, err := txRepo.GetParameterByName(ctx, *changeRequest.ChangeData.Name)
-				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-					return err
-				}
-				if existing != nil && existing.ID != parameter.ID {
-					return fmt.Errorf("parameter with name '%s' already exists", *changeRequest.ChangeData.Name)
-				}
-				parameter.Name = *changeRequest.ChangeData.Name
+		var parameterID uint
+		var before, after json.RawMessage
+		switch changeRequest.RequestType {
+		case model.ChangeRequestTypeCreate:
+			diff = s.diffParameterChangeRequest(changeRequest, &model.Parameter{})
+			parameter, err := s.applyParameterCreation(ctx, txRepo, userID, changeRequest.ChangeData)
+			if err != nil {
+				return err
 			}
-		}
-
-		if changeRequest.ChangeData.Description != nil {
-			parameter.Description = *changeRequest.ChangeData.Description
-		}
-
-		if changeRequest.ChangeData.DataType != nil {
-			parameter.DataType = *changeRequest.ChangeData.DataType
-		}
-
-		if changeRequest.ChangeData.DefaultRolloutValue != nil {
-			parameter.DefaultRolloutValue = model.RolloutValue{
-				Data: changeRequest.ChangeData.DefaultRolloutValue,
+			parameterID = parameter.ID
+			diff.ParameterID = parameterID
+			changeRequest.ParameterID = &parameterID
+		case model.ChangeRequestTypeDelete:
+			parameter, err := txRepo.GetParameterByID(ctx, *changeRequest.ParameterID)
+			if err != nil {
+				return fmt.Errorf("failed to get parameter: %w", err)
 			}
-		}
-
-		// Update parameter metadata
-		if err := txRepo.UpdateParameter(ctx, parameter); err != nil {
-			return fmt.Errorf("failed to update parameter: %w", err)
-		}
-
-		// Handle rules replacement if provided
-		if len(changeRequest.ChangeData.Rules) > 0 {
-			// Delete all existing rules
-			if err := txRepo.DeleteParameterRulesByParameterID(ctx, parameter.ID); err != nil {
-				return fmt.Errorf("failed to delete existing rules: %w", err)
+			diff = s.diffParameterChangeRequest(changeRequest, parameter)
+			if err := ensureParameterUnused(parameter); err != nil {
+				return err
 			}
-
-			// Create new rules
-			for _, ruleReq := range changeRequest.ChangeData.Rules {
-				rule := &model.ParameterRule{
-					Name:         ruleReq.Name,
-					Description:  ruleReq.Description,
-					Type:         ruleReq.Type,
-					ParameterID:  parameter.ID,
-					RolloutValue: model.RolloutValue{Data: ruleReq.RolloutValue},
-					SegmentID:    ruleReq.SegmentID,
-					MatchType:    ruleReq.MatchType,
-				}
-
-				if err := txRepo.CreateParameterRule(ctx, rule); err != nil {
-					return fmt.Errorf("failed to create rule '%s': %w", ruleReq.Name, err)
-				}
-
-				// Add conditions for attribute-based rules
-				if ruleReq.Type == model.RuleTypeAttribute && len(ruleReq.Conditions) > 0 {
-					for _, conditionReq := range ruleReq.Conditions {
-						condition := &model.ParameterRuleCondition{
-							RuleID:      rule.ID,
-							AttributeID: conditionReq.AttributeID,
-							Operator:    conditionReq.Operator,
-							Value:       conditionReq.Value,
-						}
-						if err := txRepo.CreateParameterRuleCondition(ctx, condition); err != nil {
-							return fmt.Errorf("failed to create condition for rule '%s': %w", ruleReq.Name, err)
-						}
-					}
-				}
+			if err := txRepo.DeleteParameter(ctx, parameter.ID); err != nil {
+				return fmt.Errorf("failed to delete parameter: %w", err)
 			}
-		}
-
-		// Update parameter raw value
-		if err := txRepo.UpdateParameterRawValue(ctx, parameter.ID); err != nil {
-			logger.Error().Err(err).Msg("Failed to update parameter raw_value")
+			parameterID, before = parameter.ID, parameter.RawValue
+		default:
+			parameter, err := txRepo.GetParameterByID(ctx, *changeRequest.ParameterID)
+			if err != nil {
+				return fmt.Errorf("failed to get parameter: %w", err)
+			}
+			diff = s.diffParameterChangeRequest(changeRequest, parameter)
+			if err := s.applyParameterUpdate(ctx, txRepo, userID, changeRequest.ChangeData, parameter); err != nil {
+				return err
+			}
+			parameterID, before = parameter.ID, parameter.RawValue
 		}
 
 		// Update the change request status
@@ -287,33 +422,194 @@ func (s *service) ApproveParameterChangeRequest(ctx context.Context, id uint, us
 			return fmt.Errorf("failed to update change request status: %w", err)
 		}
 
-		// Enqueue sync parameter job
-		logger.Info().Msg("Enqueuing sync parameter job")
-		_, err = s.riverClient.Insert(ctx, dto.SyncParameterArgs{
-			ParameterID: int(parameter.ID),
-		}, nil)
-		if err != nil {
-			logger.Error().Err(err).Msg("Failed to enqueue sync parameter job")
+		if changeRequest.RequestType != model.ChangeRequestTypeDelete {
+			after, err = parameterSnapshot(ctx, txRepo, parameterID)
+			if err != nil {
+				return err
+			}
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, parameterID, model.AuditActionApproveChangeRequest, before, after); err != nil {
+			return err
 		}
 
-		return nil
+		// Enqueue sync parameter job
+		logger.Info().Msg("Enqueuing sync parameter job")
+		return s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{
+			ParameterID: int(parameterID),
+		})
 	}()
 
 	if err != nil {
 		// Rollback on error
 		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
-			return nil, fmt.Errorf("transaction failed: %v, rollback failed: %w", err, rollbackErr)
+			return nil, nil, fmt.Errorf("transaction failed: %v, rollback failed: %w", err, rollbackErr)
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Commit the transaction
 	if commitErr := tx.Commit().Error; commitErr != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", commitErr)
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", commitErr)
 	}
 
 	// Reload with relationships
-	return s.repo.GetParameterChangeRequestByID(ctx, changeRequest.ID)
+	changeRequest, err = s.repo.GetParameterChangeRequestByID(ctx, changeRequest.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return changeRequest, diff, nil
+}
+
+// applyParameterUpdate applies the changes of an update request to parameter
+func (s *service) applyParameterUpdate(ctx context.Context, txRepo repository.Repository, userID uint, changeData model.ParameterChangeData, parameter *model.Parameter) error {
+	logger := log.Ctx(ctx).With().Str("service", "apply-parameter-update").Uint("parameterId", parameter.ID).Logger()
+
+	// Apply the changes from changeData
+	if changeData.Name != nil {
+		// Check if new name conflicts with existing
+		if *changeData.Name != parameter.Name {
+			if err := ensureParameterNameAvailable(ctx, txRepo, *changeData.Name); err != nil {
+				return err
+			}
+			parameter.Name = *changeData.Name
+		}
+	}
+
+	if changeData.Description != nil {
+		parameter.Description = *changeData.Description
+	}
+
+	if changeData.DataType != nil {
+		parameter.DataType = *changeData.DataType
+	}
+
+	if changeData.DefaultRolloutValue != nil {
+		parameter.DefaultRolloutValue = model.RolloutValue{
+			Data: changeData.DefaultRolloutValue,
+		}
+	}
+
+	// Enum parameters must keep every rollout value within their options, and constrained
+	// number parameters within their constraints
+	enumOptions, err := s.resolveEnumOptions(parameter, parameter.DataType, changeData.EnumOptions)
+	if err != nil {
+		return err
+	}
+	constraints, err := s.resolveNumberConstraints(parameter.NumberConstraints, parameter.DataType, changeData.NumberConstraints)
+	if err != nil {
+		return err
+	}
+	if parameter.DataType == model.ParameterDataTypeEnum || constraints != nil {
+		if err := s.validateParameterValue(parameter.DefaultRolloutValue.Data, parameter.DataType, enumOptions, constraints); err != nil {
+			return apperror.BadRequest("invalid default rollout value: %v", err)
+		}
+		if len(changeData.Rules) > 0 {
+			for _, ruleReq := range changeData.Rules {
+				if err := s.validateParameterValue(ruleReq.RolloutValue, parameter.DataType, enumOptions, constraints); err != nil {
+					return apperror.BadRequest("invalid rollout value for rule '%s': %v", ruleReq.Name, err)
+				}
+			}
+		} else if err := s.validateExistingRolloutValues(parameter, parameter.DataType, enumOptions, constraints, false, true); err != nil {
+			return err
+		}
+	}
+	parameter.EnumOptions = enumOptions
+	parameter.NumberConstraints = constraints
+
+	// Update parameter metadata
+	parameter.UpdatedByUserID = &userID
+	if err := txRepo.UpdateParameter(ctx, parameter); err != nil {
+		return fmt.Errorf("failed to update parameter: %w", err)
+	}
+
+	// Handle rules replacement if provided
+	if len(changeData.Rules) > 0 {
+		// Delete all existing rules
+		if err := txRepo.DeleteParameterRulesByParameterID(ctx, parameter.ID); err != nil {
+			return fmt.Errorf("failed to delete existing rules: %w", err)
+		}
+		if err := createChangeRequestRulesTx(ctx, txRepo, parameter.ID, changeData.Rules); err != nil {
+			return err
+		}
+	}
+
+	// Update parameter raw value
+	if err := txRepo.UpdateParameterRawValue(ctx, parameter.ID); err != nil {
+		logger.Error().Err(err).Msg("Failed to update parameter raw_value")
+	}
+	return nil
+}
+
+// applyParameterCreation creates the parameter proposed by a create request, checked the same way as
+// CreateParameter since the name may have been taken or the payload gone stale while the request was pending
+func (s *service) applyParameterCreation(ctx context.Context, txRepo repository.Repository, userID uint, changeData model.ParameterChangeData) (*model.Parameter, error) {
+	req := parameterCreationRequest(changeData)
+	if err := ensureParameterNameAvailable(ctx, txRepo, req.Name); err != nil {
+		return nil, err
+	}
+	parameter, err := s.newParameter(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, ruleReq := range changeData.Rules {
+		if err := s.validateParameterValue(ruleReq.RolloutValue, parameter.DataType, parameter.EnumOptions, parameter.NumberConstraints); err != nil {
+			return nil, apperror.BadRequest("invalid rollout value for rule '%s': %v", ruleReq.Name, err)
+		}
+	}
+
+	if err := txRepo.CreateParameter(ctx, parameter); err != nil {
+		return nil, fmt.Errorf("failed to create parameter: %w", err)
+	}
+	if err := createChangeRequestRulesTx(ctx, txRepo, parameter.ID, changeData.Rules); err != nil {
+		return nil, err
+	}
+
+	// Update raw_value field with all related data
+	if err := txRepo.UpdateParameterRawValue(ctx, parameter.ID); err != nil {
+		return nil, fmt.Errorf("failed to update parameter raw value: %w", err)
+	}
+	return parameter, nil
+}
+
+// createChangeRequestRulesTx creates the rules of a change request on a parameter, with the conditions
+// of attribute-based rules
+func createChangeRequestRulesTx(ctx context.Context, txRepo repository.Repository, parameterID uint, rules []model.ParameterRuleRequest) error {
+	for i, ruleReq := range rules {
+		rule := &model.ParameterRule{
+			Name:              ruleReq.Name,
+			Description:       ruleReq.Description,
+			Type:              ruleReq.Type,
+			ParameterID:       parameterID,
+			RolloutValue:      model.RolloutValue{Data: ruleReq.RolloutValue},
+			SegmentID:         ruleReq.SegmentID,
+			MatchType:         ruleReq.MatchType,
+			ConditionLogic:    ruleReq.ConditionLogic,
+			Priority:          rulePriority(ruleReq.Priority, i),
+			RolloutPercentage: ruleReq.RolloutPercentage,
+			HashAttributeName: ruleReq.HashAttributeName,
+			Negate:            ruleReq.Negate,
+		}
+
+		if err := txRepo.CreateParameterRule(ctx, rule); err != nil {
+			return fmt.Errorf("failed to create rule '%s': %w", ruleReq.Name, err)
+		}
+
+		// Add conditions for attribute-based rules
+		if ruleReq.Type == model.RuleTypeAttribute && len(ruleReq.Conditions) > 0 {
+			for _, conditionReq := range ruleReq.Conditions {
+				condition := &model.ParameterRuleCondition{
+					RuleID:      rule.ID,
+					AttributeID: conditionReq.AttributeID,
+					Operator:    conditionReq.Operator,
+					Value:       conditionReq.Value,
+				}
+				if err := txRepo.CreateParameterRuleCondition(ctx, condition); err != nil {
+					return fmt.Errorf("failed to create condition for rule '%s': %w", ruleReq.Name, err)
+				}
+			}
+		}
+	}
+	return nil
 }
 
 // RejectParameterChangeRequest rejects a change request
@@ -324,14 +620,19 @@ func (s *service) RejectParameterChangeRequest(ctx context.Context, id uint, use
 	changeRequest, err := s.repo.GetParameterChangeRequestByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("parameter change request with ID %d not found", id)
+			return nil, apperror.NotFound("parameter change request with ID %d not found", id)
 		}
 		return nil, err
 	}
 
 	// Check if it's still pending
 	if changeRequest.Status != model.ChangeRequestStatusPending {
-		return nil, fmt.Errorf("change request is not pending (current status: %s)", changeRequest.Status)
+		return nil, apperror.Conflict("change request is not pending (current status: %s)", changeRequest.Status)
+	}
+
+	before, err := changeRequestSnapshot(changeRequest)
+	if err != nil {
+		return nil, err
 	}
 
 	// Update status to rejected
@@ -340,6 +641,58 @@ func (s *service) RejectParameterChangeRequest(ctx context.Context, id uint, use
 	changeRequest.ReviewedByUserID = &userID
 	changeRequest.ReviewedAt = &now
 
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.UpdateParameterChangeRequest(ctx, changeRequest); err != nil {
+			logger.Error().Err(err).Msg("Failed to update change request status")
+			return err
+		}
+
+		after, err := changeRequestSnapshot(changeRequest)
+		if err != nil {
+			return err
+		}
+		return s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameterChangeRequest, changeRequest.ID, model.AuditActionReject, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload with relationships
+	return s.repo.GetParameterChangeRequestByID(ctx, changeRequest.ID)
+}
+
+// CancelParameterChangeRequest cancels a pending change request on behalf of its requester.
+// The parameter itself is left untouched.
+func (s *service) CancelParameterChangeRequest(ctx context.Context, id uint, userID uint, req *dto.CancelParameterChangeRequestRequest) (*model.ParameterChangeRequest, error) {
+	logger := log.Ctx(ctx).With().Str("service", "cancel-parameter-change-request").Uint("id", id).Logger()
+
+	// Get the change request
+	changeRequest, err := s.repo.GetParameterChangeRequestByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("parameter change request with ID %d not found", id)
+		}
+		return nil, err
+	}
+
+	// Only the original requester may cancel
+	if changeRequest.RequestedByUserID != userID {
+		return nil, apperror.Forbidden("only the requester can cancel parameter change request %d", id)
+	}
+
+	// Check if it's still pending
+	if changeRequest.Status != model.ChangeRequestStatusPending {
+		return nil, apperror.Conflict("change request is not pending (current status: %s)", changeRequest.Status)
+	}
+
+	// Update status to cancelled
+	now := time.Now()
+	changeRequest.Status = model.ChangeRequestStatusCancelled
+	changeRequest.ReviewedAt = &now
+	if req != nil {
+		changeRequest.CancellationReason = req.Reason
+	}
+
 	if err := s.repo.UpdateParameterChangeRequest(ctx, changeRequest); err != nil {
 		logger.Error().Err(err).Msg("Failed to update change request status")
 		return nil, err
@@ -359,7 +712,7 @@ func (s *service) GetParameterChangeRequestByIDWithDetails(ctx context.Context,
 	changeRequest, err := s.repo.GetParameterChangeRequestByIDWithDetails(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("parameter change request with ID %d not found", id)
+			return nil, apperror.NotFound("parameter change request with ID %d not found", id)
 		}
 		return nil, err
 	}
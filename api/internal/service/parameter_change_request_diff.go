@@ -0,0 +1,195 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/dto"
+	"api/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// GetParameterChangeRequestDiff compares a change request with the live parameter, so reviewers see
+// what approving it does now rather than what it would have done when it was created
+func (s *service) GetParameterChangeRequestDiff(ctx context.Context, id uint) (*dto.ParameterChangeRequestDiff, error) {
+	changeRequest, err := s.repo.GetParameterChangeRequestByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFound("parameter change request with ID %d not found", id)
+		}
+		return nil, err
+	}
+
+	// Create requests are compared with an empty parameter, so every proposed field is reported
+	parameter := &model.Parameter{}
+	if changeRequest.RequestType != model.ChangeRequestTypeCreate {
+		parameter, err = s.repo.GetParameterByID(ctx, *changeRequest.ParameterID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, apperror.NotFound("parameter with ID %d not found", *changeRequest.ParameterID)
+			}
+			return nil, err
+		}
+	}
+
+	return s.diffParameterChangeRequest(changeRequest, parameter), nil
+}
+
+// diffParameterChangeRequest lists the changes approving the change request applies to parameter.
+// Fields left out of the change data are kept on approval and so are not reported.
+func (s *service) diffParameterChangeRequest(changeRequest *model.ParameterChangeRequest, parameter *model.Parameter) *dto.ParameterChangeRequestDiff {
+	live := s.convertParameterToCurrentConfig(parameter)
+	change := changeRequest.ChangeData
+	diff := &dto.ParameterChangeRequestDiff{
+		ChangeRequestID: changeRequest.ID,
+		RequestType:     changeRequest.RequestType,
+		ParameterID:     parameter.ID,
+		Fields:          []dto.FieldChange{},
+	}
+
+	if change.Name != nil {
+		diff.Fields = appendFieldChange(diff.Fields, "name", live.Name, *change.Name)
+	}
+	if change.Description != nil {
+		diff.Fields = appendFieldChange(diff.Fields, "description", live.Description, *change.Description)
+	}
+	if change.DataType != nil {
+		diff.Fields = appendFieldChange(diff.Fields, "dataType", live.DataType, *change.DataType)
+	}
+	if change.DefaultRolloutValue != nil && !sameRolloutValue(live.DefaultRolloutValue, change.DefaultRolloutValue) {
+		diff.Fields = append(diff.Fields, dto.FieldChange{Field: "defaultRolloutValue", Old: live.DefaultRolloutValue, New: change.DefaultRolloutValue})
+	}
+	if change.EnumOptions != nil && !slices.Equal(live.EnumOptions, change.EnumOptions) {
+		diff.Fields = append(diff.Fields, dto.FieldChange{Field: "enumOptions", Old: live.EnumOptions, New: change.EnumOptions})
+	}
+	if change.NumberConstraints != nil {
+		diff.Fields = appendFieldChange(diff.Fields, "numberConstraints", live.NumberConstraints, change.NumberConstraints)
+	}
+
+	// Approval replaces the rules only when the change request lists some
+	if len(change.Rules) > 0 {
+		diff.Rules = diffRules(live.Rules, change.Rules)
+	}
+	return diff
+}
+
+// diffRules matches proposed rules with live rules by name. Rules sharing a name are matched in order.
+func diffRules(live, proposed []model.ParameterRuleRequest) *dto.RulesDiff {
+	diff := &dto.RulesDiff{
+		Added:    []model.ParameterRuleRequest{},
+		Removed:  []model.ParameterRuleRequest{},
+		Modified: []dto.RuleDiff{},
+	}
+
+	liveByName := make(map[string][]model.ParameterRuleRequest)
+	for _, rule := range live {
+		liveByName[rule.Name] = append(liveByName[rule.Name], rule)
+	}
+
+	for i, rule := range proposed {
+		// Rules created on approval without a priority take their position
+		priority := rulePriority(rule.Priority, i)
+		rule.Priority = &priority
+
+		matches := liveByName[rule.Name]
+		if len(matches) == 0 {
+			diff.Added = append(diff.Added, rule)
+			continue
+		}
+		old := matches[0]
+		liveByName[rule.Name] = matches[1:]
+
+		if ruleDiff, changed := diffRule(old, rule); changed {
+			diff.Modified = append(diff.Modified, ruleDiff)
+		}
+	}
+
+	// Live rules left unmatched are deleted on approval
+	for _, rule := range live {
+		matches := liveByName[rule.Name]
+		if len(matches) > 0 {
+			diff.Removed = append(diff.Removed, matches[0])
+			liveByName[rule.Name] = matches[1:]
+		}
+	}
+	return diff
+}
+
+// diffRule compares two rules of the same name and reports whether anything changed
+func diffRule(old, new model.ParameterRuleRequest) (dto.RuleDiff, bool) {
+	fields := []dto.FieldChange{}
+	fields = appendFieldChange(fields, "description", old.Description, new.Description)
+	fields = appendFieldChange(fields, "type", old.Type, new.Type)
+	if !sameRolloutValue(old.RolloutValue, new.RolloutValue) {
+		fields = append(fields, dto.FieldChange{Field: "rolloutValue", Old: old.RolloutValue, New: new.RolloutValue})
+	}
+	fields = appendFieldChange(fields, "segmentId", old.SegmentID, new.SegmentID)
+	fields = appendFieldChange(fields, "matchType", old.MatchType, new.MatchType)
+	fields = appendFieldChange(fields, "conditionLogic", conditionLogicOrDefault(old.ConditionLogic), conditionLogicOrDefault(new.ConditionLogic))
+	fields = appendFieldChange(fields, "priority", old.Priority, new.Priority)
+	fields = appendFieldChange(fields, "rolloutPercentage", old.RolloutPercentage, new.RolloutPercentage)
+	fields = appendFieldChange(fields, "hashAttributeName", old.HashAttributeName, new.HashAttributeName)
+	fields = appendFieldChange(fields, "negate", old.Negate, new.Negate)
+
+	conditions := diffConditions(old.Conditions, new.Conditions)
+	return dto.RuleDiff{Name: new.Name, Fields: fields, Conditions: conditions}, len(fields) > 0 || len(conditions) > 0
+}
+
+// diffConditions matches conditions by attribute, in order for several conditions on the same attribute
+func diffConditions(old, new []model.ParameterRuleConditionRequest) []dto.ConditionChange {
+	changes := []dto.ConditionChange{}
+
+	oldByAttribute := make(map[uint][]model.ParameterRuleConditionRequest)
+	for _, condition := range old {
+		oldByAttribute[condition.AttributeID] = append(oldByAttribute[condition.AttributeID], condition)
+	}
+
+	for _, condition := range new {
+		matches := oldByAttribute[condition.AttributeID]
+		if len(matches) == 0 {
+			changes = append(changes, dto.ConditionChange{Change: dto.DiffChangeAdded, AttributeID: condition.AttributeID, New: &condition})
+			continue
+		}
+		previous := matches[0]
+		oldByAttribute[condition.AttributeID] = matches[1:]
+		if previous != condition {
+			changes = append(changes, dto.ConditionChange{Change: dto.DiffChangeModified, AttributeID: condition.AttributeID, Old: &previous, New: &condition})
+		}
+	}
+
+	for _, condition := range old {
+		matches := oldByAttribute[condition.AttributeID]
+		if len(matches) > 0 {
+			removed := matches[0]
+			oldByAttribute[condition.AttributeID] = matches[1:]
+			changes = append(changes, dto.ConditionChange{Change: dto.DiffChangeRemoved, AttributeID: removed.AttributeID, Old: &removed})
+		}
+	}
+	return changes
+}
+
+// appendFieldChange appends the change of a field when its old and new values differ
+func appendFieldChange(changes []dto.FieldChange, field string, old, new interface{}) []dto.FieldChange {
+	if reflect.DeepEqual(old, new) {
+		return changes
+	}
+	return append(changes, dto.FieldChange{Field: field, Old: old, New: new})
+}
+
+// sameRolloutValue compares rollout values by their text, since a value decoded from a change
+// request may be typed differently than the stored one, such as the number 10 and the string "10"
+func sameRolloutValue(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b) || fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// conditionLogicOrDefault returns the logic rules without one are evaluated with
+func conditionLogicOrDefault(logic model.ConditionLogic) model.ConditionLogic {
+	if logic == "" {
+		return model.ConditionLogicAnd
+	}
+	return logic
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/dto"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CloneParameter creates a parameter with the data type, default value and rules of an existing one.
+// The clone takes the live state of the source, so pending change requests are not carried over.
+// Segments and attributes are referenced by the cloned rules rather than copied.
+func (s *service) CloneParameter(ctx context.Context, id uint, userID uint, req *dto.CloneParameterRequest) (*model.Parameter, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, apperror.BadRequest("name is required")
+	}
+
+	source, err := s.GetParameterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureParameterNameAvailable(ctx, s.repo, req.Name); err != nil {
+		return nil, err
+	}
+
+	description := req.Description
+	if description == "" {
+		description = source.Description
+	}
+	clone := &model.Parameter{
+		Name:                req.Name,
+		Description:         description,
+		DataType:            source.DataType,
+		DefaultRolloutValue: source.DefaultRolloutValue,
+		EnumOptions:         slices.Clone(source.EnumOptions),
+		NumberConstraints:   source.NumberConstraints,
+		UsageCount:          0,
+		CreatedByUserID:     &userID,
+		UpdatedByUserID:     &userID,
+	}
+
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.CreateParameter(ctx, clone); err != nil {
+			return err
+		}
+
+		for _, rule := range source.Rules {
+			if err := cloneParameterRuleTx(ctx, txRepo, clone.ID, rule); err != nil {
+				return err
+			}
+		}
+
+		if err := txRepo.UpdateParameterRawValue(ctx, clone.ID); err != nil {
+			return fmt.Errorf("failed to update parameter raw value: %w", err)
+		}
+
+		after, err := parameterSnapshot(ctx, txRepo, clone.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, clone.ID, model.AuditActionCreate, nil, after); err != nil {
+			return err
+		}
+		return s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{
+			ParameterID: int(clone.ID),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetParameterByID(ctx, clone.ID)
+}
+
+// cloneParameterRuleTx copies a base rule and its conditions to another parameter
+func cloneParameterRuleTx(ctx context.Context, txRepo repository.Repository, parameterID uint, source model.ParameterRule) error {
+	rule := &model.ParameterRule{
+		Name:              source.Name,
+		Description:       source.Description,
+		Type:              source.Type,
+		RolloutValue:      source.RolloutValue,
+		ParameterID:       parameterID,
+		SegmentID:         source.SegmentID,
+		MatchType:         source.MatchType,
+		ConditionLogic:    source.ConditionLogic,
+		Priority:          source.Priority,
+		RolloutPercentage: source.RolloutPercentage,
+		HashAttributeName: source.HashAttributeName,
+		Negate:            source.Negate,
+	}
+	if err := txRepo.CreateParameterRule(ctx, rule); err != nil {
+		return fmt.Errorf("failed to clone rule '%s': %w", source.Name, err)
+	}
+
+	for _, sourceCondition := range source.Conditions {
+		condition := &model.ParameterRuleCondition{
+			RuleID:      rule.ID,
+			AttributeID: sourceCondition.AttributeID,
+			Operator:    sourceCondition.Operator,
+			Value:       sourceCondition.Value,
+		}
+		if err := txRepo.CreateParameterRuleCondition(ctx, condition); err != nil {
+			return fmt.Errorf("failed to clone condition of rule '%s': %w", source.Name, err)
+		}
+	}
+	return nil
+}
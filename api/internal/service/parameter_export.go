@@ -0,0 +1,723 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/dto"
+	"api/internal/model"
+	"api/internal/repository"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// ExportParameters returns a document describing every parameter along with the segments and
+// attributes its rules reference, so that it can be imported into another environment.
+// Segments keep their references to other segments, which are exported as well.
+// Legacy parameter conditions are not exported.
+func (s *service) ExportParameters(ctx context.Context) (*dto.ParameterExportDocument, error) {
+	parameters, err := s.repo.GetAllParameters(ctx, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parameters: %w", err)
+	}
+
+	attributes := make(map[string]*model.Attribute)
+	segments := make(map[uint]*model.Segment)
+	document := &dto.ParameterExportDocument{
+		Version:    dto.ParameterExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Attributes: []dto.ExportedAttribute{},
+		Segments:   []dto.ExportedSegment{},
+		Parameters: make([]dto.ExportedParameter, 0, len(parameters)),
+	}
+
+	for _, parameter := range parameters {
+		document.Parameters = append(document.Parameters, exportParameter(parameter))
+		for _, rule := range parameter.Rules {
+			for _, condition := range rule.Conditions {
+				if condition.Attribute != nil {
+					attributes[condition.Attribute.Name] = condition.Attribute
+				}
+			}
+			if rule.Segment == nil {
+				continue
+			}
+			if err := s.collectExportedSegment(ctx, rule.Segment, segments, attributes); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(attributes)) {
+		document.Attributes = append(document.Attributes, exportAttribute(attributes[name]))
+	}
+	for _, segment := range segments {
+		document.Segments = append(document.Segments, exportSegment(segment, segments))
+	}
+	slices.SortFunc(document.Segments, func(a, b dto.ExportedSegment) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.SortFunc(document.Parameters, func(a, b dto.ExportedParameter) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return document, nil
+}
+
+// ImportParameters imports a parameter export document. Attributes and segments are matched by name
+// and, with CreateMissing, created when they do not exist. Existing parameters are skipped or
+// overwritten depending on OnConflict. The whole import runs in a single transaction and writes
+// nothing when any item fails; a dry run only returns the diff.
+func (s *service) ImportParameters(ctx context.Context, userID uint, req *dto.ImportParametersRequest, dryRun bool) (*dto.ImportParametersResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, apperror.BadRequest("invalid import request: %v", err)
+	}
+
+	if dryRun {
+		plan, err := s.planParameterImport(ctx, s.repo, req)
+		if err != nil {
+			return nil, err
+		}
+		plan.response.DryRun = true
+		return &plan.response, nil
+	}
+
+	var response *dto.ImportParametersResponse
+	err := s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		plan, err := s.planParameterImport(ctx, txRepo, req)
+		if err != nil {
+			return err
+		}
+		response = &plan.response
+		if len(plan.response.Errors) > 0 {
+			return nil
+		}
+		if err := s.applyParameterImport(ctx, txRepo, userID, plan); err != nil {
+			return err
+		}
+		response.Applied = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// parameterImportPlan holds what an import resolved before anything is written
+type parameterImportPlan struct {
+	req      *dto.ImportParametersRequest
+	response dto.ImportParametersResponse
+
+	// attributes and segments hold every resolved item by name; those created by the import have no ID yet
+	attributes    map[string]*model.Attribute
+	segments      map[string]*model.Segment
+	newAttributes []*model.Attribute
+	newSegments   []dto.ExportedSegment
+	failed        map[string]bool
+	// resolving holds the segments being resolved, whose references lead back to them when they form a cycle
+	resolving map[string]bool
+
+	parameters []plannedParameterImport
+}
+
+// plannedParameterImport is a parameter the import creates or updates
+type plannedParameterImport struct {
+	parameter dto.ExportedParameter
+	existing  *model.Parameter
+}
+
+// planParameterImport resolves the document against repo without writing anything
+func (s *service) planParameterImport(ctx context.Context, repo repository.Repository, req *dto.ImportParametersRequest) (*parameterImportPlan, error) {
+	plan := &parameterImportPlan{
+		req: req,
+		response: dto.ImportParametersResponse{
+			Attributes: []dto.ImportItemResult{},
+			Segments:   []dto.ImportItemResult{},
+			Parameters: []dto.ImportParameterResult{},
+			Errors:     []dto.ImportItemError{},
+		},
+		attributes: make(map[string]*model.Attribute),
+		segments:   make(map[string]*model.Segment),
+		failed:     make(map[string]bool),
+		resolving:  make(map[string]bool),
+	}
+
+	for _, exported := range req.Document.Parameters {
+		exported = normalizeExportedParameter(exported)
+
+		existing, err := repo.GetParameterByName(ctx, exported.Name)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if existing != nil && req.OnConflict != dto.ImportConflictOverwrite {
+			plan.response.Parameters = append(plan.response.Parameters, dto.ImportParameterResult{Name: exported.Name, Action: dto.ImportActionSkip})
+			continue
+		}
+		if existing == nil {
+			// A deleted parameter still holds the name, and has to be restored rather than imported again
+			if err := ensureParameterNameAvailable(ctx, repo, exported.Name); err != nil {
+				var appErr *apperror.Error
+				if !errors.As(err, &appErr) {
+					return nil, err
+				}
+				plan.addError("parameter", exported.Name, appErr.Message)
+				continue
+			}
+		}
+
+		// Skipped parameters are not resolved, so they never cause segments or attributes to be created
+		if err := s.resolveImportedParameter(ctx, repo, plan, exported); err != nil {
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) {
+				return nil, err
+			}
+			plan.addError("parameter", exported.Name, appErr.Message)
+			continue
+		}
+
+		result := dto.ImportParameterResult{Name: exported.Name, Action: dto.ImportActionCreate, After: &exported}
+		if existing != nil {
+			before := exportParameter(existing)
+			result.Before = &before
+			result.Action = dto.ImportActionUpdate
+			if sameExportedParameter(before, exported) {
+				result.Action = dto.ImportActionUnchanged
+			}
+		}
+		plan.response.Parameters = append(plan.response.Parameters, result)
+
+		if result.Action != dto.ImportActionUnchanged {
+			plan.parameters = append(plan.parameters, plannedParameterImport{parameter: exported, existing: existing})
+		}
+	}
+
+	return plan, nil
+}
+
+// resolveImportedParameter validates a parameter of the document and resolves the segments and
+// attributes its rules reference. Failures are returned as application errors.
+func (s *service) resolveImportedParameter(ctx context.Context, repo repository.Repository, plan *parameterImportPlan, parameter dto.ExportedParameter) error {
+	if parameter.DataType == model.ParameterDataTypeEnum {
+		if err := s.validateEnumOptions(parameter.EnumOptions); err != nil {
+			return err
+		}
+	}
+	if _, err := s.resolveNumberConstraints(nil, parameter.DataType, parameter.NumberConstraints); err != nil {
+		return err
+	}
+	if err := s.validateParameterValue(parameter.DefaultRolloutValue, parameter.DataType, parameter.EnumOptions, parameter.NumberConstraints); err != nil {
+		return apperror.BadRequest("invalid default rollout value: %v", err)
+	}
+
+	for _, rule := range parameter.Rules {
+		if err := s.validateParameterValue(rule.RolloutValue, parameter.DataType, parameter.EnumOptions, parameter.NumberConstraints); err != nil {
+			return apperror.BadRequest("invalid rollout value for rule '%s': %v", rule.Name, err)
+		}
+		if err := validateRuleRollout(rule.Name, rule.RolloutPercentage, rule.HashAttributeName); err != nil {
+			return err
+		}
+
+		switch rule.Type {
+		case model.RuleTypeSegment:
+			if rule.Segment == "" || rule.MatchType == nil {
+				return apperror.BadRequest("segment and match type are required for segment-based rule '%s'", rule.Name)
+			}
+			ok, err := s.resolveImportedSegment(ctx, repo, plan, rule.Segment)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return apperror.BadRequest("segment '%s' of rule '%s' cannot be imported", rule.Segment, rule.Name)
+			}
+		case model.RuleTypeAttribute:
+			for _, condition := range rule.Conditions {
+				ok, err := s.resolveImportedAttribute(ctx, repo, plan, condition.Attribute)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return apperror.BadRequest("attribute '%s' of rule '%s' cannot be imported", condition.Attribute, rule.Name)
+				}
+				if err := validateCondition(plan.attributes[condition.Attribute], condition.Operator, condition.Value); err != nil {
+					return err
+				}
+			}
+		default:
+			return apperror.BadRequest("unsupported type '%s' for rule '%s'", rule.Type, rule.Name)
+		}
+	}
+
+	return nil
+}
+
+// resolveImportedSegment resolves a segment by name, planning its creation when it is missing and
+// CreateMissing is set. It reports false once an error has been recorded for the segment.
+func (s *service) resolveImportedSegment(ctx context.Context, repo repository.Repository, plan *parameterImportPlan, name string) (bool, error) {
+	key := "segment:" + name
+	if plan.failed[key] {
+		return false, nil
+	}
+	if _, ok := plan.segments[name]; ok {
+		return true, nil
+	}
+
+	existing, err := repo.GetSegmentByName(ctx, name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if existing != nil {
+		plan.segments[name] = existing
+		plan.response.Segments = append(plan.response.Segments, dto.ImportItemResult{Name: name, Action: dto.ImportActionUnchanged})
+		return true, nil
+	}
+
+	exported, defined := findExportedSegment(plan.req.Document.Segments, name)
+	switch {
+	case !plan.req.CreateMissing:
+		plan.addError("segment", name, "segment not found")
+	case !defined:
+		plan.addError("segment", name, "segment not found and not defined in the document")
+	case plan.resolving[name]:
+		plan.addError("segment", name, "segment references form a cycle")
+	default:
+		plan.resolving[name] = true
+		defer delete(plan.resolving, name)
+		for _, rule := range exported.Rules {
+			if rule.Segment != "" {
+				if len(rule.Conditions) > 0 {
+					plan.addError("segment", name, fmt.Sprintf("rule '%s' must either hold conditions or reference a segment, not both", rule.Name))
+					plan.failed[key] = true
+					return false, nil
+				}
+				if rule.ReferenceType != model.SegmentReferenceInclude && rule.ReferenceType != model.SegmentReferenceExclude {
+					plan.addError("segment", name, fmt.Sprintf("rule '%s' has reference type '%s', expected include or exclude", rule.Name, rule.ReferenceType))
+					plan.failed[key] = true
+					return false, nil
+				}
+				ok, err := s.resolveImportedSegment(ctx, repo, plan, rule.Segment)
+				if err != nil {
+					return false, err
+				}
+				if !ok {
+					plan.addError("segment", name, fmt.Sprintf("segment '%s' of rule '%s' cannot be imported", rule.Segment, rule.Name))
+					plan.failed[key] = true
+					return false, nil
+				}
+				continue
+			}
+			for _, condition := range rule.Conditions {
+				ok, err := s.resolveImportedAttribute(ctx, repo, plan, condition.Attribute)
+				if err != nil {
+					return false, err
+				}
+				if !ok {
+					plan.addError("segment", name, fmt.Sprintf("attribute '%s' of rule '%s' cannot be imported", condition.Attribute, rule.Name))
+					plan.failed[key] = true
+					return false, nil
+				}
+				if err := validateCondition(plan.attributes[condition.Attribute], condition.Operator, condition.Value); err != nil {
+					plan.addError("segment", name, err.Error())
+					plan.failed[key] = true
+					return false, nil
+				}
+			}
+		}
+		plan.segments[name] = &model.Segment{Name: name, Description: exported.Description}
+		plan.newSegments = append(plan.newSegments, exported)
+		plan.response.Segments = append(plan.response.Segments, dto.ImportItemResult{Name: name, Action: dto.ImportActionCreate})
+		return true, nil
+	}
+
+	plan.failed[key] = true
+	return false, nil
+}
+
+// resolveImportedAttribute resolves an attribute by name, planning its creation when it is missing
+// and CreateMissing is set. It reports false once an error has been recorded for the attribute.
+func (s *service) resolveImportedAttribute(ctx context.Context, repo repository.Repository, plan *parameterImportPlan, name string) (bool, error) {
+	key := "attribute:" + name
+	if plan.failed[key] {
+		return false, nil
+	}
+	if _, ok := plan.attributes[name]; ok {
+		return true, nil
+	}
+
+	exported, defined := findExportedAttribute(plan.req.Document.Attributes, name)
+	existing, err := repo.GetAttributeByName(ctx, name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	switch {
+	case existing != nil && defined && existing.DataType != exported.DataType:
+		plan.addError("attribute", name, fmt.Sprintf("data type '%s' does not match '%s' in this environment", exported.DataType, existing.DataType))
+	case existing != nil:
+		plan.attributes[name] = existing
+		plan.response.Attributes = append(plan.response.Attributes, dto.ImportItemResult{Name: name, Action: dto.ImportActionUnchanged})
+		return true, nil
+	case !plan.req.CreateMissing:
+		plan.addError("attribute", name, "attribute not found")
+	case !defined:
+		plan.addError("attribute", name, "attribute not found and not defined in the document")
+	case exported.DataType == model.DataTypeEnum && len(exported.EnumOptions) == 0:
+		plan.addError("attribute", name, "enum options are required for enum data type")
+	default:
+		attribute := &model.Attribute{
+			Name:          name,
+			Description:   exported.Description,
+			DataType:      exported.DataType,
+			HashAttribute: exported.HashAttribute,
+			EnumOptions:   exported.EnumOptions,
+		}
+		if attribute.EnumOptions == nil {
+			attribute.EnumOptions = []string{}
+		}
+		plan.attributes[name] = attribute
+		plan.newAttributes = append(plan.newAttributes, attribute)
+		plan.response.Attributes = append(plan.response.Attributes, dto.ImportItemResult{Name: name, Action: dto.ImportActionCreate})
+		return true, nil
+	}
+
+	plan.failed[key] = true
+	return false, nil
+}
+
+// addError records why an item of the document cannot be imported
+func (p *parameterImportPlan) addError(kind, name, message string) {
+	p.response.Errors = append(p.response.Errors, dto.ImportItemError{Kind: kind, Name: name, Message: message})
+}
+
+// applyParameterImport writes a plan without errors through txRepo
+func (s *service) applyParameterImport(ctx context.Context, txRepo repository.Repository, userID uint, plan *parameterImportPlan) error {
+	logger := log.Ctx(ctx).With().Str("service", "import-parameters").Logger()
+
+	for _, attribute := range plan.newAttributes {
+		if err := txRepo.CreateAttribute(ctx, attribute); err != nil {
+			return fmt.Errorf("failed to create attribute '%s': %w", attribute.Name, err)
+		}
+	}
+
+	for _, exported := range plan.newSegments {
+		segment := plan.segments[exported.Name]
+		segment.Rules = make([]model.SegmentRule, len(exported.Rules))
+		for i, exportedRule := range exported.Rules {
+			rule := model.SegmentRule{
+				Name:        exportedRule.Name,
+				Description: exportedRule.Description,
+				Conditions:  make([]model.SegmentRuleCondition, len(exportedRule.Conditions)),
+			}
+			if exportedRule.Segment != "" {
+				// Referenced segments are resolved, and created, before the segments referencing them
+				referencedID := plan.segments[exportedRule.Segment].ID
+				rule.ReferencedSegmentID = &referencedID
+				rule.ReferenceType = exportedRule.ReferenceType
+			}
+			for j, condition := range exportedRule.Conditions {
+				rule.Conditions[j] = model.SegmentRuleCondition{
+					AttributeID: plan.attributes[condition.Attribute].ID,
+					Operator:    condition.Operator,
+					Value:       condition.Value,
+				}
+			}
+			segment.Rules[i] = rule
+		}
+		if err := txRepo.CreateSegment(ctx, segment); err != nil {
+			return fmt.Errorf("failed to create segment '%s': %w", segment.Name, err)
+		}
+		// The references must still expand to conditions within the depth and size limits
+		expanded := *segment
+		if err := txRepo.ExpandSegmentReferences(ctx, &expanded); err != nil {
+			if errors.Is(err, model.ErrInvalidSegmentReference) {
+				return apperror.BadRequest("segment '%s': %v", segment.Name, err)
+			}
+			return err
+		}
+	}
+
+	for _, planned := range plan.parameters {
+		exported := planned.parameter
+		parameter := planned.existing
+		action := model.AuditActionUpdate
+		var before json.RawMessage
+		if parameter == nil {
+			parameter = &model.Parameter{Name: exported.Name}
+			action = model.AuditActionCreate
+		} else {
+			before = parameter.RawValue
+			// Rules are replaced below, so they must not be saved back along with the parameter
+			parameter.Rules = nil
+			parameter.Conditions = nil
+		}
+		parameter.Description = exported.Description
+		parameter.DataType = exported.DataType
+		parameter.DefaultRolloutValue = model.RolloutValue{Data: exported.DefaultRolloutValue}
+		parameter.EnumOptions = []string{}
+		if exported.DataType == model.ParameterDataTypeEnum {
+			parameter.EnumOptions = exported.EnumOptions
+		}
+		parameter.NumberConstraints = exported.NumberConstraints
+		parameter.UpdatedByUserID = &userID
+
+		if planned.existing == nil {
+			parameter.CreatedByUserID = &userID
+			if err := txRepo.CreateParameter(ctx, parameter); err != nil {
+				return fmt.Errorf("failed to create parameter '%s': %w", parameter.Name, err)
+			}
+		} else {
+			if err := txRepo.UpdateParameter(ctx, parameter); err != nil {
+				return fmt.Errorf("failed to update parameter '%s': %w", parameter.Name, err)
+			}
+			if err := txRepo.DeleteParameterRulesByParameterID(ctx, parameter.ID); err != nil {
+				return fmt.Errorf("failed to delete existing rules of parameter '%s': %w", parameter.Name, err)
+			}
+		}
+
+		if err := createImportedParameterRules(ctx, txRepo, plan, parameter.ID, exported.Rules); err != nil {
+			return fmt.Errorf("failed to create rules of parameter '%s': %w", parameter.Name, err)
+		}
+
+		if err := txRepo.UpdateParameterRawValue(ctx, parameter.ID); err != nil {
+			return fmt.Errorf("failed to update parameter raw value: %w", err)
+		}
+		after, err := parameterSnapshot(ctx, txRepo, parameter.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.recordAuditLog(ctx, txRepo, userID, model.AuditEntityParameter, parameter.ID, action, before, after); err != nil {
+			return err
+		}
+
+		if err := s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{
+			ParameterID: int(parameter.ID),
+		}); err != nil {
+			logger.Error().Err(err).Str("parameter", parameter.Name).Msg("Failed to enqueue sync parameter job")
+			return err
+		}
+	}
+
+	logger.Info().Int("parameters", len(plan.parameters)).Int("attributes", len(plan.newAttributes)).Int("segments", len(plan.newSegments)).Msg("Imported parameters")
+	return nil
+}
+
+// createImportedParameterRules creates the rules of an imported parameter and their conditions
+func createImportedParameterRules(ctx context.Context, txRepo repository.Repository, plan *parameterImportPlan, parameterID uint, rules []dto.ExportedParameterRule) error {
+	for _, exported := range rules {
+		rule := &model.ParameterRule{
+			Name:              exported.Name,
+			Description:       exported.Description,
+			Type:              exported.Type,
+			ParameterID:       parameterID,
+			RolloutValue:      model.RolloutValue{Data: exported.RolloutValue},
+			ConditionLogic:    exported.ConditionLogic,
+			Priority:          exported.Priority,
+			RolloutPercentage: exported.RolloutPercentage,
+			HashAttributeName: exported.HashAttributeName,
+			Negate:            exported.Negate,
+		}
+		if exported.Type == model.RuleTypeSegment {
+			segmentID := plan.segments[exported.Segment].ID
+			rule.SegmentID = &segmentID
+			rule.MatchType = exported.MatchType
+		}
+		if err := txRepo.CreateParameterRule(ctx, rule); err != nil {
+			return err
+		}
+
+		if exported.Type != model.RuleTypeAttribute {
+			continue
+		}
+		for _, condition := range exported.Conditions {
+			if err := txRepo.CreateParameterRuleCondition(ctx, &model.ParameterRuleCondition{
+				RuleID:      rule.ID,
+				AttributeID: plan.attributes[condition.Attribute].ID,
+				Operator:    condition.Operator,
+				Value:       condition.Value,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exportParameter converts a parameter with its preloaded rules into its exported form
+func exportParameter(parameter *model.Parameter) dto.ExportedParameter {
+	exported := dto.ExportedParameter{
+		Name:                parameter.Name,
+		Description:         parameter.Description,
+		DataType:            parameter.DataType,
+		DefaultRolloutValue: parameter.DefaultRolloutValue.Data,
+		EnumOptions:         parameter.EnumOptions,
+		NumberConstraints:   parameter.NumberConstraints,
+		Rules:               make([]dto.ExportedParameterRule, len(parameter.Rules)),
+	}
+	for i, rule := range parameter.Rules {
+		exportedRule := dto.ExportedParameterRule{
+			Name:              rule.Name,
+			Description:       rule.Description,
+			Type:              rule.Type,
+			RolloutValue:      rule.RolloutValue.Data,
+			MatchType:         rule.MatchType,
+			ConditionLogic:    rule.ConditionLogic,
+			Priority:          rule.Priority,
+			RolloutPercentage: rule.RolloutPercentage,
+			HashAttributeName: rule.HashAttributeName,
+			Negate:            rule.Negate,
+			Conditions:        make([]dto.ExportedCondition, 0, len(rule.Conditions)),
+		}
+		if rule.Segment != nil {
+			exportedRule.Segment = rule.Segment.Name
+		}
+		for _, condition := range rule.Conditions {
+			exportedRule.Conditions = append(exportedRule.Conditions, exportCondition(condition.Attribute, condition.Operator, condition.Value))
+		}
+		exported.Rules[i] = exportedRule
+	}
+	return normalizeExportedParameter(exported)
+}
+
+// collectExportedSegment adds the segment, the segments it references directly or indirectly and the
+// attributes of their conditions to those of an export, loading the referenced segments by ID
+func (s *service) collectExportedSegment(ctx context.Context, segment *model.Segment, segments map[uint]*model.Segment, attributes map[string]*model.Attribute) error {
+	if _, ok := segments[segment.ID]; ok {
+		return nil
+	}
+	segments[segment.ID] = segment
+	for _, rule := range segment.Rules {
+		for _, condition := range rule.Conditions {
+			if condition.Attribute != nil {
+				attributes[condition.Attribute.Name] = condition.Attribute
+			}
+		}
+		if !rule.References() {
+			continue
+		}
+		referenced, ok := segments[*rule.ReferencedSegmentID]
+		if !ok {
+			var err error
+			referenced, err = s.repo.GetSegmentByID(ctx, *rule.ReferencedSegmentID)
+			if err != nil {
+				return fmt.Errorf("failed to load segment %d referenced by segment '%s': %w", *rule.ReferencedSegmentID, segment.Name, err)
+			}
+		}
+		if err := s.collectExportedSegment(ctx, referenced, segments, attributes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportSegment converts a segment with its preloaded rules into its exported form, naming the segments
+// its rules reference from segments
+func exportSegment(segment *model.Segment, segments map[uint]*model.Segment) dto.ExportedSegment {
+	exported := dto.ExportedSegment{
+		Name:        segment.Name,
+		Description: segment.Description,
+		Rules:       make([]dto.ExportedSegmentRule, len(segment.Rules)),
+	}
+	for i, rule := range segment.Rules {
+		exportedRule := dto.ExportedSegmentRule{
+			Name:        rule.Name,
+			Description: rule.Description,
+			Conditions:  make([]dto.ExportedCondition, len(rule.Conditions)),
+		}
+		if rule.References() {
+			if referenced, ok := segments[*rule.ReferencedSegmentID]; ok {
+				exportedRule.Segment = referenced.Name
+			}
+			exportedRule.ReferenceType = rule.ReferenceType
+		}
+		for j, condition := range rule.Conditions {
+			exportedRule.Conditions[j] = exportCondition(condition.Attribute, condition.Operator, condition.Value)
+		}
+		exported.Rules[i] = exportedRule
+	}
+	return exported
+}
+
+// exportAttribute converts an attribute into its exported form
+func exportAttribute(attribute *model.Attribute) dto.ExportedAttribute {
+	enumOptions := []string(attribute.EnumOptions)
+	if enumOptions == nil {
+		enumOptions = []string{}
+	}
+	return dto.ExportedAttribute{
+		Name:          attribute.Name,
+		Description:   attribute.Description,
+		DataType:      attribute.DataType,
+		HashAttribute: attribute.HashAttribute,
+		EnumOptions:   enumOptions,
+	}
+}
+
+// exportCondition references the attribute of a condition by name
+func exportCondition(attribute *model.Attribute, operator model.ConditionOperator, value string) dto.ExportedCondition {
+	condition := dto.ExportedCondition{Operator: operator, Value: value}
+	if attribute != nil {
+		condition.Attribute = attribute.Name
+	}
+	return condition
+}
+
+// normalizeExportedParameter fills the defaults of an exported parameter, so that a document
+// written by hand compares equal to the export of the same configuration
+func normalizeExportedParameter(parameter dto.ExportedParameter) dto.ExportedParameter {
+	if parameter.DataType != model.ParameterDataTypeEnum || parameter.EnumOptions == nil {
+		parameter.EnumOptions = []string{}
+	}
+	if parameter.DataType != model.ParameterDataTypeNumber || parameter.NumberConstraints.Empty() {
+		parameter.NumberConstraints = nil
+	}
+	rules := make([]dto.ExportedParameterRule, len(parameter.Rules))
+	for i, rule := range parameter.Rules {
+		if rule.ConditionLogic == "" {
+			rule.ConditionLogic = model.ConditionLogicAnd
+		}
+		if rule.Type != model.RuleTypeSegment {
+			rule.Segment = ""
+			rule.MatchType = nil
+		}
+		if rule.Type != model.RuleTypeAttribute || rule.Conditions == nil {
+			rule.Conditions = []dto.ExportedCondition{}
+		}
+		rules[i] = rule
+	}
+	parameter.Rules = rules
+	return parameter
+}
+
+// sameExportedParameter reports whether two exported parameters describe the same configuration
+func sameExportedParameter(a, b dto.ExportedParameter) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
+
+func findExportedSegment(segments []dto.ExportedSegment, name string) (dto.ExportedSegment, bool) {
+	for _, segment := range segments {
+		if segment.Name == name {
+			return segment, true
+		}
+	}
+	return dto.ExportedSegment{}, false
+}
+
+func findExportedAttribute(attributes []dto.ExportedAttribute, name string) (dto.ExportedAttribute, bool) {
+	for _, attribute := range attributes {
+		if attribute.Name == name {
+			return attribute, true
+		}
+	}
+	return dto.ExportedAttribute{}, false
+}
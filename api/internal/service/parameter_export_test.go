@@ -0,0 +1,281 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/dto"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// importRepository serves parameters, segments and attributes by name from memory
+type importRepository struct {
+	repository.Repository
+	parameters map[string]*model.Parameter
+	segments   map[string]*model.Segment
+	attributes map[string]*model.Attribute
+}
+
+func (r *importRepository) GetParameterByName(_ context.Context, name string) (*model.Parameter, error) {
+	if parameter, ok := r.parameters[name]; ok && !parameter.DeletedAt.Valid {
+		return parameter, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *importRepository) GetParameterByNameWithDeleted(_ context.Context, name string) (*model.Parameter, error) {
+	if parameter, ok := r.parameters[name]; ok {
+		return parameter, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *importRepository) GetSegmentByName(_ context.Context, name string) (*model.Segment, error) {
+	if segment, ok := r.segments[name]; ok {
+		return segment, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *importRepository) GetSegmentByID(_ context.Context, id uint) (*model.Segment, error) {
+	for _, segment := range r.segments {
+		if segment.ID == id {
+			return segment, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *importRepository) GetAllParameters(context.Context, int, int) ([]*model.Parameter, error) {
+	var parameters []*model.Parameter
+	for _, parameter := range r.parameters {
+		parameters = append(parameters, parameter)
+	}
+	return parameters, nil
+}
+
+func (r *importRepository) GetAttributeByName(_ context.Context, name string) (*model.Attribute, error) {
+	if attribute, ok := r.attributes[name]; ok {
+		return attribute, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestImportParametersDryRun(t *testing.T) {
+	match := model.ConditionMatchTypeMatch
+	country := &model.Attribute{ID: 1, Name: "country", DataType: model.DataTypeString}
+	existing := &model.Parameter{
+		ID:                  1,
+		Name:                "timeout",
+		DataType:            model.ParameterDataTypeNumber,
+		DefaultRolloutValue: model.RolloutValue{Data: float64(10)},
+	}
+	repo := &importRepository{
+		parameters: map[string]*model.Parameter{"timeout": existing},
+		segments:   map[string]*model.Segment{},
+		attributes: map[string]*model.Attribute{"country": country},
+	}
+	s := &service{repo: repo}
+
+	document := dto.ParameterExportDocument{
+		Version:    dto.ParameterExportVersion,
+		Attributes: []dto.ExportedAttribute{{Name: "plan", DataType: model.DataTypeString}},
+		Segments: []dto.ExportedSegment{{Name: "premium", Rules: []dto.ExportedSegmentRule{{
+			Name:       "paid",
+			Conditions: []dto.ExportedCondition{{Attribute: "plan", Operator: model.ConditionOperatorEquals, Value: "pro"}},
+		}}}},
+		Parameters: []dto.ExportedParameter{
+			{Name: "timeout", DataType: model.ParameterDataTypeNumber, DefaultRolloutValue: float64(20)},
+			{Name: "banner", DataType: model.ParameterDataTypeBoolean, DefaultRolloutValue: false, Rules: []dto.ExportedParameterRule{
+				{Name: "premium users", Type: model.RuleTypeSegment, RolloutValue: true, Segment: "premium", MatchType: &match},
+				{Name: "vietnam", Type: model.RuleTypeAttribute, RolloutValue: true, Priority: 1, Conditions: []dto.ExportedCondition{
+					{Attribute: "country", Operator: model.ConditionOperatorEquals, Value: "VN"},
+				}},
+			}},
+		},
+	}
+
+	// Without createMissing the missing segment and attribute are reported and the parameter is not imported
+	response, err := s.ImportParameters(context.Background(), 1, &dto.ImportParametersRequest{Document: document}, true)
+	require.NoError(t, err)
+	require.True(t, response.DryRun)
+	require.False(t, response.Applied)
+	require.Equal(t, []dto.ImportParameterResult{{Name: "timeout", Action: dto.ImportActionSkip}}, response.Parameters)
+	require.Equal(t, []dto.ImportItemError{
+		{Kind: "segment", Name: "premium", Message: "segment not found"},
+		{Kind: "parameter", Name: "banner", Message: "segment 'premium' of rule 'premium users' cannot be imported"},
+	}, response.Errors)
+
+	response, err = s.ImportParameters(context.Background(), 1, &dto.ImportParametersRequest{
+		Document:      document,
+		CreateMissing: true,
+		OnConflict:    dto.ImportConflictOverwrite,
+	}, true)
+	require.NoError(t, err)
+	require.Empty(t, response.Errors)
+	require.Equal(t, []dto.ImportItemResult{
+		{Name: "plan", Action: dto.ImportActionCreate},
+		{Name: "country", Action: dto.ImportActionUnchanged},
+	}, response.Attributes)
+	require.Equal(t, []dto.ImportItemResult{{Name: "premium", Action: dto.ImportActionCreate}}, response.Segments)
+
+	require.Len(t, response.Parameters, 2)
+	require.Equal(t, dto.ImportActionUpdate, response.Parameters[0].Action)
+	require.Equal(t, float64(10), response.Parameters[0].Before.DefaultRolloutValue)
+	require.Equal(t, float64(20), response.Parameters[0].After.DefaultRolloutValue)
+	require.Equal(t, dto.ImportActionCreate, response.Parameters[1].Action)
+	require.Nil(t, response.Parameters[1].Before)
+}
+
+func TestExportParameterRoundTripsAsUnchanged(t *testing.T) {
+	segmentID := uint(3)
+	match := model.ConditionMatchTypeNotMatch
+	parameter := &model.Parameter{
+		Name:                "banner",
+		DataType:            model.ParameterDataTypeBoolean,
+		DefaultRolloutValue: model.RolloutValue{Data: false},
+		Rules: []model.ParameterRule{
+			{
+				Name: "not premium", Type: model.RuleTypeSegment, RolloutValue: model.RolloutValue{Data: true},
+				SegmentID: &segmentID, Segment: &model.Segment{ID: segmentID, Name: "premium"}, MatchType: &match,
+			},
+			{
+				Name: "vietnam", Type: model.RuleTypeAttribute, RolloutValue: model.RolloutValue{Data: true}, Priority: 1,
+				ConditionLogic: model.ConditionLogicOr,
+				Conditions: []model.ParameterRuleCondition{
+					{AttributeID: 1, Attribute: &model.Attribute{ID: 1, Name: "country"}, Operator: model.ConditionOperatorEquals, Value: "VN"},
+				},
+			},
+		},
+	}
+
+	exported := exportParameter(parameter)
+	require.Equal(t, "premium", exported.Rules[0].Segment)
+	require.Equal(t, "country", exported.Rules[1].Conditions[0].Attribute)
+	require.True(t, sameExportedParameter(exported, normalizeExportedParameter(exported)))
+
+	handWritten := exported
+	handWritten.EnumOptions = nil
+	require.True(t, sameExportedParameter(exported, normalizeExportedParameter(handWritten)))
+}
+
+func TestParameterNameOfDeletedParameterCannotBeReused(t *testing.T) {
+	deleted := &model.Parameter{ID: 4, Name: "legacy_banner", DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+	repo := &importRepository{parameters: map[string]*model.Parameter{"legacy_banner": deleted}}
+	s := &service{repo: repo}
+
+	_, err := s.CreateParameter(context.Background(), 1, &dto.CreateParameterRequest{
+		Name: "legacy_banner", DataType: model.ParameterDataTypeBoolean, DefaultRolloutValue: false,
+	})
+	require.ErrorIs(t, err, apperror.ErrConflict)
+	require.ErrorContains(t, err, "restore parameter 4")
+
+	response, err := s.ImportParameters(context.Background(), 1, &dto.ImportParametersRequest{
+		Document: dto.ParameterExportDocument{
+			Version:    dto.ParameterExportVersion,
+			Parameters: []dto.ExportedParameter{{Name: "legacy_banner", DataType: model.ParameterDataTypeBoolean, DefaultRolloutValue: false}},
+		},
+		OnConflict: dto.ImportConflictOverwrite,
+	}, true)
+	require.NoError(t, err)
+	require.Empty(t, response.Parameters)
+	require.Len(t, response.Errors, 1)
+	require.Equal(t, "legacy_banner", response.Errors[0].Name)
+}
+
+func TestExportParametersKeepsSegmentReferences(t *testing.T) {
+	staffID, premiumID := uint(4), uint(3)
+	match := model.ConditionMatchTypeMatch
+	email := &model.Attribute{ID: 2, Name: "email", DataType: model.DataTypeString}
+	staff := &model.Segment{ID: staffID, Name: "staff", Rules: []model.SegmentRule{{
+		Name:       "company email",
+		Conditions: []model.SegmentRuleCondition{{AttributeID: 2, Attribute: email, Operator: model.ConditionOperatorContains, Value: "@aurora.dev"}},
+	}}}
+	// The rules of referenced segments are not preloaded with the parameters
+	premium := &model.Segment{ID: premiumID, Name: "premium", Rules: []model.SegmentRule{
+		{Name: "not staff", ReferencedSegmentID: &staffID, ReferenceType: model.SegmentReferenceExclude},
+	}}
+	repo := &importRepository{
+		parameters: map[string]*model.Parameter{"banner": {
+			Name:                "banner",
+			DataType:            model.ParameterDataTypeBoolean,
+			DefaultRolloutValue: model.RolloutValue{Data: false},
+			Rules: []model.ParameterRule{{
+				Name: "premium users", Type: model.RuleTypeSegment, RolloutValue: model.RolloutValue{Data: true},
+				SegmentID: &premiumID, Segment: premium, MatchType: &match,
+			}},
+		}},
+		segments: map[string]*model.Segment{"staff": staff, "premium": premium},
+	}
+	s := &service{repo: repo}
+
+	document, err := s.ExportParameters(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []dto.ExportedSegment{
+		{Name: "premium", Rules: []dto.ExportedSegmentRule{{
+			Name: "not staff", Segment: "staff", ReferenceType: model.SegmentReferenceExclude, Conditions: []dto.ExportedCondition{},
+		}}},
+		{Name: "staff", Rules: []dto.ExportedSegmentRule{{
+			Name:       "company email",
+			Conditions: []dto.ExportedCondition{{Attribute: "email", Operator: model.ConditionOperatorContains, Value: "@aurora.dev"}},
+		}}},
+	}, document.Segments)
+	require.Equal(t, []dto.ExportedAttribute{exportAttribute(email)}, document.Attributes)
+}
+
+func TestImportParametersResolvesSegmentReferences(t *testing.T) {
+	match := model.ConditionMatchTypeMatch
+	repo := &importRepository{
+		parameters: map[string]*model.Parameter{},
+		segments:   map[string]*model.Segment{},
+		attributes: map[string]*model.Attribute{"email": {ID: 2, Name: "email", DataType: model.DataTypeString}},
+	}
+	s := &service{repo: repo}
+	banner := dto.ExportedParameter{Name: "banner", DataType: model.ParameterDataTypeBoolean, DefaultRolloutValue: false, Rules: []dto.ExportedParameterRule{
+		{Name: "premium users", Type: model.RuleTypeSegment, RolloutValue: true, Segment: "premium", MatchType: &match},
+	}}
+	staff := dto.ExportedSegment{Name: "staff", Rules: []dto.ExportedSegmentRule{{
+		Name:       "company email",
+		Conditions: []dto.ExportedCondition{{Attribute: "email", Operator: model.ConditionOperatorContains, Value: "@aurora.dev"}},
+	}}}
+
+	// Referenced segments are planned before the segments referencing them
+	response, err := s.ImportParameters(context.Background(), 1, &dto.ImportParametersRequest{
+		Document: dto.ParameterExportDocument{
+			Version: dto.ParameterExportVersion,
+			Segments: []dto.ExportedSegment{
+				{Name: "premium", Rules: []dto.ExportedSegmentRule{{Name: "not staff", Segment: "staff", ReferenceType: model.SegmentReferenceExclude}}},
+				staff,
+			},
+			Parameters: []dto.ExportedParameter{banner},
+		},
+		CreateMissing: true,
+	}, true)
+	require.NoError(t, err)
+	require.Empty(t, response.Errors)
+	require.Equal(t, []dto.ImportItemResult{
+		{Name: "staff", Action: dto.ImportActionCreate},
+		{Name: "premium", Action: dto.ImportActionCreate},
+	}, response.Segments)
+
+	// Segments of the document referencing each other are rejected
+	response, err = s.ImportParameters(context.Background(), 1, &dto.ImportParametersRequest{
+		Document: dto.ParameterExportDocument{
+			Version: dto.ParameterExportVersion,
+			Segments: []dto.ExportedSegment{
+				{Name: "premium", Rules: []dto.ExportedSegmentRule{{Name: "staff", Segment: "staff", ReferenceType: model.SegmentReferenceInclude}}},
+				{Name: "staff", Rules: []dto.ExportedSegmentRule{{Name: "premium", Segment: "premium", ReferenceType: model.SegmentReferenceInclude}}},
+			},
+			Parameters: []dto.ExportedParameter{banner},
+		},
+		CreateMissing: true,
+	}, true)
+	require.NoError(t, err)
+	require.Empty(t, response.Parameters)
+	require.Contains(t, response.Errors, dto.ImportItemError{Kind: "segment", Name: "premium", Message: "segment references form a cycle"})
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/dto"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"errors"
+	"sdk/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestValidateParameterValueJSON(t *testing.T) {
+	s := &service{}
+
+	require.NoError(t, s.validateParameterValue(map[string]interface{}{"layout": "grid"}, model.ParameterDataTypeJSON, nil, nil))
+	require.NoError(t, s.validateParameterValue([]interface{}{"hero", "footer"}, model.ParameterDataTypeJSON, nil, nil))
+	require.NoError(t, s.validateParameterValue(`{"layout": "grid"}`, model.ParameterDataTypeJSON, nil, nil))
+
+	require.Error(t, s.validateParameterValue(`{"layout": `, model.ParameterDataTypeJSON, nil, nil))
+	require.Error(t, s.validateParameterValue("grid", model.ParameterDataTypeJSON, nil, nil))
+	require.Error(t, s.validateParameterValue(42.0, model.ParameterDataTypeJSON, nil, nil))
+	require.Error(t, s.validateParameterValue(nil, model.ParameterDataTypeJSON, nil, nil))
+}
+
+func TestValidateParameterValueDate(t *testing.T) {
+	s := &service{}
+
+	// The API accepts the formats the SDK's ParseDate reads
+	require.NoError(t, s.validateParameterValue("2025-03-01T00:00:00Z", model.ParameterDataTypeDate, nil, nil))
+	require.NoError(t, s.validateParameterValue("1740787200", model.ParameterDataTypeDate, nil, nil))
+	require.NoError(t, s.validateParameterValue(1740787200.0, model.ParameterDataTypeDate, nil, nil))
+	require.NoError(t, s.validateParameterValue(int64(1740787200), model.ParameterDataTypeDate, nil, nil))
+
+	require.Error(t, s.validateParameterValue("2025-03-01", model.ParameterDataTypeDate, nil, nil))
+	require.Error(t, s.validateParameterValue(1740787200.5, model.ParameterDataTypeDate, nil, nil))
+	require.Error(t, s.validateParameterValue(true, model.ParameterDataTypeDate, nil, nil))
+	require.Error(t, s.validateParameterValue(nil, model.ParameterDataTypeDate, nil, nil))
+}
+
+func TestValidateParameterValueNumberConstraints(t *testing.T) {
+	s := &service{}
+	min, max := 0.0, 5.0
+	constraints := &model.NumberConstraints{IntegerOnly: true, Min: &min, Max: &max}
+
+	require.NoError(t, s.validateParameterValue(3.0, model.ParameterDataTypeNumber, nil, constraints))
+	require.NoError(t, s.validateParameterValue(5, model.ParameterDataTypeNumber, nil, constraints))
+	require.NoError(t, s.validateParameterValue(2.5, model.ParameterDataTypeNumber, nil, nil))
+
+	require.ErrorContains(t, s.validateParameterValue(2.5, model.ParameterDataTypeNumber, nil, constraints), "must be an integer")
+	require.ErrorContains(t, s.validateParameterValue(-1.0, model.ParameterDataTypeNumber, nil, constraints), "at least 0")
+	require.ErrorContains(t, s.validateParameterValue(uint8(6), model.ParameterDataTypeNumber, nil, constraints), "at most 5")
+
+	_, err := s.resolveNumberConstraints(nil, model.ParameterDataTypeNumber, &model.NumberConstraints{Min: &max, Max: &min})
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+	_, err = s.resolveNumberConstraints(nil, model.ParameterDataTypeString, constraints)
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+	resolved, err := s.resolveNumberConstraints(constraints, model.ParameterDataTypeNumber, &model.NumberConstraints{})
+	require.NoError(t, err)
+	require.Nil(t, resolved)
+}
+
+func TestValidateRuleRollout(t *testing.T) {
+	percentage := func(value int) *int { return &value }
+	userID := "userId"
+	empty := ""
+
+	require.NoError(t, validateRuleRollout("rule", nil, nil))
+	require.NoError(t, validateRuleRollout("rule", percentage(100), nil))
+	require.NoError(t, validateRuleRollout("rule", percentage(0), &userID))
+	require.NoError(t, validateRuleRollout("rule", percentage(25), &userID))
+
+	require.Error(t, validateRuleRollout("rule", percentage(25), nil))
+	require.Error(t, validateRuleRollout("rule", percentage(25), &empty))
+	require.Error(t, validateRuleRollout("rule", percentage(-1), &userID))
+	require.Error(t, validateRuleRollout("rule", percentage(101), &userID))
+}
+
+func TestValidateRuleOrder(t *testing.T) {
+	rules := []model.ParameterRule{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	require.NoError(t, validateRuleOrder(rules, []uint{3, 1, 2}))
+	require.Error(t, validateRuleOrder(rules, []uint{3, 1}))
+	require.Error(t, validateRuleOrder(rules, []uint{3, 1, 1}))
+	require.Error(t, validateRuleOrder(rules, []uint{3, 1, 4}))
+}
+
+func TestNextRulePriority(t *testing.T) {
+	require.Equal(t, 0, nextRulePriority(nil))
+	require.Equal(t, 6, nextRulePriority([]model.ParameterRule{{Priority: 5}, {Priority: 0}}))
+}
+
+// pagedParameterRepository serves parameters ordered by ID from memory
+type pagedParameterRepository struct {
+	repository.Repository
+	parameters []*model.Parameter
+}
+
+func (r *pagedParameterRepository) GetParametersAfter(_ context.Context, afterID uint, limit int, includeArchived bool) ([]*model.Parameter, error) {
+	var page []*model.Parameter
+	for _, parameter := range r.parameters {
+		if parameter.ArchivedAt != nil && !includeArchived {
+			continue
+		}
+		if parameter.ID > afterID && len(page) < limit {
+			page = append(page, parameter)
+		}
+	}
+	return page, nil
+}
+
+func TestGetParametersPage(t *testing.T) {
+	archivedAt := time.Now()
+	s := &service{repo: &pagedParameterRepository{parameters: []*model.Parameter{{ID: 2}, {ID: 5}, {ID: 7, ArchivedAt: &archivedAt}, {ID: 9}}}}
+	ctx := context.Background()
+
+	page, next, err := s.GetParametersPage(ctx, 2, 0, false)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, uint(5), *next)
+
+	page, next, err = s.GetParametersPage(ctx, 2, *next, false)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, uint(9), page[0].ID)
+	require.NotNil(t, page[0].Rules)
+	require.Nil(t, next)
+
+	page, _, err = s.GetParametersPage(ctx, 2, 5, true)
+	require.NoError(t, err)
+	require.Equal(t, uint(7), page[0].ID)
+}
+
+func TestSimulationTraceResponseNamesRules(t *testing.T) {
+	segmentID := uint(7)
+	matchedRuleID := uint(2)
+	parameter := &model.Parameter{Rules: []model.ParameterRule{
+		{ID: 1, Name: "beta testers", SegmentID: &segmentID, Segment: &model.Segment{ID: segmentID, Name: "beta"}},
+		{ID: 2, Name: "pro plan"},
+	}}
+	details := types.EvaluationDetails{
+		Source: types.EvaluationSourceParameter,
+		Trace: &types.EvaluationTrace{
+			MatchedRuleID: &matchedRuleID,
+			Rules: []types.RuleTrace{
+				{RuleID: 1, Type: types.RuleTypeSegment, SegmentID: &segmentID, Conditions: []types.ConditionTrace{}},
+				{RuleID: 2, Type: types.RuleTypeAttribute, Matched: true, Conditions: []types.ConditionTrace{
+					{Attribute: "plan", Operator: types.ConditionOperatorEquals, Expected: "pro", Actual: "pro", Matched: true},
+				}},
+			},
+		},
+	}
+
+	response := simulationTraceResponse(details, types.EvaluationReasonRuleMatch, parameter)
+	require.Equal(t, "rule_match", response.Reason)
+	require.Equal(t, "pro plan", response.MatchedRuleName)
+	require.Equal(t, "beta", response.Rules[0].SegmentName)
+	require.Equal(t, "pro plan", response.Rules[1].RuleName)
+	require.Equal(t, "pro", response.Rules[1].Conditions[0].Actual)
+
+	// Experiments resolve the parameter without evaluating rules
+	response = simulationTraceResponse(types.EvaluationDetails{Source: types.EvaluationSourceExperiment}, types.EvaluationReasonExperimentVariant, nil)
+	require.Empty(t, response.Rules)
+	require.Nil(t, response.MatchedRuleID)
+}
+
+// changeRequestRepository serves one parameter without a pending change request and attributes from memory
+type changeRequestRepository struct {
+	segmentRepository
+	parameter *model.Parameter
+}
+
+func (r *changeRequestRepository) GetParameterByID(context.Context, uint) (*model.Parameter, error) {
+	return r.parameter, nil
+}
+
+func (r *changeRequestRepository) GetPendingParameterChangeRequestByParameterID(context.Context, uint) (*model.ParameterChangeRequest, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestCreateParameterChangeRequestRejectsInvalidEnumConditionValue(t *testing.T) {
+	s := &service{repo: &changeRequestRepository{
+		segmentRepository: segmentRepository{attributes: map[uint]*model.Attribute{
+			2: {ID: 2, Name: "country", DataType: model.DataTypeEnum, EnumOptions: []string{"VN", "US"}},
+		}},
+		parameter: &model.Parameter{ID: 1, Name: "checkout_flow", DataType: model.ParameterDataTypeString},
+	}}
+
+	_, err := s.CreateParameterChangeRequest(context.Background(), 1, &dto.CreateParameterChangeRequestRequest{
+		ParameterID: 1,
+		Rules: []dto.CreateParameterRuleRequest{{
+			Name:         "asia",
+			Type:         model.RuleTypeAttribute,
+			RolloutValue: "v2",
+			Conditions: []dto.CreateParameterRuleConditionRequest{
+				{AttributeID: 2, Operator: model.ConditionOperatorIn, Value: "VN, TH"},
+			},
+		}},
+	})
+
+	var conditionErr *apperror.ConditionValidationError
+	require.True(t, errors.As(err, &conditionErr))
+	require.Equal(t, "value 'TH' is not an option of attribute 'country'", conditionErr.Problems[0].Message)
+}
+
+// creationRequestRepository has no parameters and a pending request to create checkout_flow
+type creationRequestRepository struct {
+	repository.Repository
+}
+
+func (r *creationRequestRepository) GetParameterByNameWithDeleted(context.Context, string) (*model.Parameter, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *creationRequestRepository) GetPendingParameterCreationRequestByName(_ context.Context, name string) (*model.ParameterChangeRequest, error) {
+	if name != "checkout_flow" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model.ParameterChangeRequest{ID: 4, RequestType: model.ChangeRequestTypeCreate}, nil
+}
+
+func TestCreateParameterChangeRequestValidatesCreationRequests(t *testing.T) {
+	s := &service{repo: &creationRequestRepository{}}
+	name := func(value string) *string { return &value }
+	dataType := model.ParameterDataTypeNumber
+
+	// Create requests are keyed by the requested name
+	_, err := s.CreateParameterChangeRequest(context.Background(), 1, &dto.CreateParameterChangeRequestRequest{
+		RequestType:         model.ChangeRequestTypeCreate,
+		Name:                name("checkout_flow"),
+		DataType:            &dataType,
+		DefaultRolloutValue: 1.0,
+	})
+	require.ErrorIs(t, err, apperror.ErrConflict)
+
+	_, err = s.CreateParameterChangeRequest(context.Background(), 1, &dto.CreateParameterChangeRequestRequest{
+		RequestType:         model.ChangeRequestTypeCreate,
+		Name:                name("checkout_limit"),
+		DataType:            &dataType,
+		DefaultRolloutValue: "one",
+	})
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+
+	_, err = s.CreateParameterChangeRequest(context.Background(), 1, &dto.CreateParameterChangeRequestRequest{
+		ParameterID:         3,
+		RequestType:         model.ChangeRequestTypeCreate,
+		Name:                name("checkout_limit"),
+		DataType:            &dataType,
+		DefaultRolloutValue: 1.0,
+	})
+	require.ErrorIs(t, err, apperror.ErrBadRequest)
+}
+
+// idempotentParameterRepository has recorded an idempotency key for parameter 9
+type idempotentParameterRepository struct {
+	repository.Repository
+	recordedAt time.Time
+}
+
+func (r *idempotentParameterRepository) GetIdempotencyKey(_ context.Context, scope string, key string) (*model.IdempotencyKey, error) {
+	if scope != model.IdempotencyScopeCreateParameter || key != "retry-1" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model.IdempotencyKey{Scope: scope, Key: key, ResourceID: 9, CreatedAt: r.recordedAt}, nil
+}
+
+func (r *idempotentParameterRepository) GetParameterByID(_ context.Context, id uint) (*model.Parameter, error) {
+	return &model.Parameter{ID: id, Name: "checkout_enabled"}, nil
+}
+
+func TestCreateParameterReturnsParameterOfRepeatedIdempotencyKey(t *testing.T) {
+	repo := &idempotentParameterRepository{recordedAt: time.Now().Add(-time.Hour)}
+	s := &service{repo: repo}
+
+	parameter, err := s.CreateParameter(context.Background(), 1, &dto.CreateParameterRequest{Name: "checkout_enabled", IdempotencyKey: "retry-1"})
+	require.NoError(t, err)
+	require.Equal(t, uint(9), parameter.ID)
+
+	// Keys are scoped per endpoint and expire after 24 hours
+	id, err := s.findIdempotentResource(context.Background(), model.IdempotencyScopeCreateExperiment, "retry-1")
+	require.NoError(t, err)
+	require.Zero(t, id)
+	repo.recordedAt = time.Now().Add(-25 * time.Hour)
+	id, err = s.findIdempotentResource(context.Background(), model.IdempotencyScopeCreateParameter, "retry-1")
+	require.NoError(t, err)
+	require.Zero(t, id)
+}
+
+func TestDiffParameterChangeRequestMatchesRulesByName(t *testing.T) {
+	s := &service{}
+	parameter := &model.Parameter{
+		ID:                  3,
+		Name:                "checkout",
+		DataType:            model.ParameterDataTypeNumber,
+		DefaultRolloutValue: model.RolloutValue{Data: float64(10)},
+		Rules: []model.ParameterRule{
+			{Name: "vn", Type: model.RuleTypeAttribute, Priority: 0, RolloutValue: model.RolloutValue{Data: float64(20)}, Conditions: []model.ParameterRuleCondition{
+				{AttributeID: 1, Operator: model.ConditionOperatorEquals, Value: "VN"},
+			}},
+			{Name: "beta", Type: model.RuleTypeAttribute, Priority: 1, RolloutValue: model.RolloutValue{Data: float64(30)}},
+		},
+	}
+	name := "checkout"
+	changeRequest := &model.ParameterChangeRequest{ID: 8, ChangeData: model.ParameterChangeData{
+		Name:                &name,
+		DefaultRolloutValue: "15",
+		Rules: []model.ParameterRuleRequest{
+			{Name: "vn", Type: model.RuleTypeAttribute, RolloutValue: 20, Conditions: []model.ParameterRuleConditionRequest{
+				{AttributeID: 1, Operator: model.ConditionOperatorIn, Value: "VN,TH"},
+				{AttributeID: 2, Operator: model.ConditionOperatorIsSet},
+			}},
+			{Name: "beta testers", Type: model.RuleTypeAttribute, RolloutValue: 30},
+		},
+	}}
+
+	diff := s.diffParameterChangeRequest(changeRequest, parameter)
+
+	// The unchanged name is left out
+	require.Equal(t, []dto.FieldChange{{Field: "defaultRolloutValue", Old: float64(10), New: "15"}}, diff.Fields)
+	require.Len(t, diff.Rules.Modified, 1)
+	require.Empty(t, diff.Rules.Modified[0].Fields)
+	require.Equal(t, []dto.DiffChangeType{dto.DiffChangeModified, dto.DiffChangeAdded}, []dto.DiffChangeType{diff.Rules.Modified[0].Conditions[0].Change, diff.Rules.Modified[0].Conditions[1].Change})
+	// A renamed rule replaces the live one
+	require.Equal(t, "beta testers", diff.Rules.Added[0].Name)
+	require.Equal(t, "beta", diff.Rules.Removed[0].Name)
+}
+
+func TestSetParameterUpdatedBySkipsParameterValidation(t *testing.T) {
+	connector := &recordingConnector{}
+	s := newRecordingService(t, connector)
+
+	// Rule changes record the user without loading the parameter, so its hooks must not run
+	err := s.inTransaction(context.Background(), func(txRepo repository.Repository) error {
+		return txRepo.SetParameterUpdatedBy(context.Background(), 4, 7)
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{`UPDATE "parameters" SET "updated_by_user_id"=$1 WHERE id = $2 AND "parameters"."deleted_at" IS NULL`}, connector.committed)
+}
+
+// cloneSourceRepository holds parameter 3, and a parameter named "checkout_v2"
+type cloneSourceRepository struct {
+	repository.Repository
+}
+
+func (cloneSourceRepository) GetParameterByID(_ context.Context, id uint) (*model.Parameter, error) {
+	if id != 3 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model.Parameter{ID: 3, Name: "checkout", DataType: model.ParameterDataTypeBoolean}, nil
+}
+
+func (cloneSourceRepository) GetParameterByNameWithDeleted(_ context.Context, name string) (*model.Parameter, error) {
+	if name != "checkout_v2" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model.Parameter{ID: 5, Name: name}, nil
+}
+
+func TestCloneParameterRejectsUnavailableName(t *testing.T) {
+	s := &service{repo: cloneSourceRepository{}}
+
+	_, err := s.CloneParameter(context.Background(), 3, 1, &dto.CloneParameterRequest{Name: "checkout_v2"})
+	require.ErrorIs(t, err, apperror.ErrConflict)
+
+	_, err = s.CloneParameter(context.Background(), 4, 1, &dto.CloneParameterRequest{Name: "checkout_v3"})
+	require.ErrorIs(t, err, apperror.ErrNotFound)
+}
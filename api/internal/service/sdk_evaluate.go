@@ -0,0 +1,156 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/dto"
+	"context"
+	"sdk"
+	"sdk/types"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// EvaluateSDK evaluates parameters for clients that cannot embed the SDK, with the API's own client, or as
+// served to an environment when one is given. Evaluation events are recorded with the request's service
+// name rather than the API's.
+func (s *service) EvaluateSDK(ctx context.Context, req *dto.EvaluateSDKRequest) (*dto.EvaluateSDKResponse, error) {
+	logger := log.Ctx(ctx).With().Str("service", "evaluate-sdk").Logger()
+
+	parameterNames := req.ParameterNames
+	if req.ParameterName != "" {
+		if len(parameterNames) > 0 {
+			return nil, apperror.BadRequest("parameterName and parameterNames cannot both be set")
+		}
+		parameterNames = []string{req.ParameterName}
+	}
+	if len(parameterNames) == 0 {
+		return nil, apperror.BadRequest("parameterName or parameterNames is required")
+	}
+
+	attribute := newEvaluationAttribute(logger, req.Attributes)
+	results := make([]dto.EvaluateSDKResult, 0, len(parameterNames))
+	events := make([]dto.TrackEventRequest, 0, len(parameterNames))
+	for _, parameterName := range parameterNames {
+		var (
+			rolloutValue sdk.RolloutValue
+			details      types.EvaluationDetails
+		)
+		if req.Environment != "" {
+			var err error
+			rolloutValue, details, _, err = s.simulateParameterInEnvironment(ctx, parameterName, req.Environment, attribute)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			// The traced evaluation tracks no event, so the event below is the only one recorded
+			rolloutValue, details = s.auroraClient.EvaluateParameterTraced(ctx, parameterName, attribute)
+		}
+
+		result := dto.EvaluateSDKResult{
+			ParameterName:  parameterName,
+			Value:          evaluatedValue(rolloutValue),
+			DataType:       string(rolloutValue.DataType()),
+			Source:         details.Source,
+			Reason:         string(rolloutValue.Reason()),
+			ExperimentID:   details.ExperimentID,
+			ExperimentUUID: details.ExperimentUUID,
+			VariantID:      details.VariantID,
+			VariantName:    details.VariantName,
+		}
+		if rolloutValue.HasError() {
+			result.Error = rolloutValue.Error().Error()
+		}
+		results = append(results, result)
+		events = append(events, evaluationEvent(req, &result, rolloutValue))
+	}
+
+	// Evaluations are served even when their events cannot be recorded
+	if _, err := s.eventService.TrackBatchEvent(ctx, &dto.TrackBatchEventRequest{Events: events}); err != nil {
+		logger.Warn().Err(err).Int("events_count", len(events)).Msg("Failed to record evaluation events")
+	}
+
+	return &dto.EvaluateSDKResponse{Results: results}, nil
+}
+
+// newEvaluationAttribute builds an SDK attribute from typed JSON values, skipping nulls and values of
+// other types, which no condition can match
+func newEvaluationAttribute(logger zerolog.Logger, attributes map[string]interface{}) *sdk.Attribute {
+	attribute := sdk.NewAttribute()
+	for name, value := range attributes {
+		switch v := value.(type) {
+		case string:
+			attribute.SetString(name, v)
+		case float64:
+			attribute.SetNumber(name, v)
+		case bool:
+			attribute.SetBool(name, v)
+		case nil:
+		default:
+			logger.Warn().Str("attribute", name).Msg("Skipping attribute that is not a string, number or boolean")
+		}
+	}
+	return attribute
+}
+
+// evaluatedValue converts a rollout value to its JSON counterpart, or nil when it has none
+func evaluatedValue(rolloutValue sdk.RolloutValue) interface{} {
+	if rolloutValue.HasError() {
+		return nil
+	}
+	switch rolloutValue.DataType() {
+	case types.ParameterDataTypeBoolean:
+		return rolloutValue.AsBool(false)
+	case types.ParameterDataTypeString:
+		return rolloutValue.AsString("")
+	case types.ParameterDataTypeNumber:
+		return rolloutValue.AsNumber(0)
+	case types.ParameterDataTypeDate:
+		return rolloutValue.AsTime(time.Time{})
+	case types.ParameterDataTypeEnum:
+		return rolloutValue.AsEnum("")
+	case types.ParameterDataTypeJSON:
+		var value interface{}
+		if err := rolloutValue.AsJSON(&value); err != nil {
+			return nil
+		}
+		return value
+	}
+	return nil
+}
+
+// evaluationEvent builds the event of a server-side evaluation, as the SDK would have tracked it
+func evaluationEvent(req *dto.EvaluateSDKRequest, result *dto.EvaluateSDKResult, rolloutValue sdk.RolloutValue) dto.TrackEventRequest {
+	eventType := dto.EventTypeParameterEvaluation
+	if result.Source == types.EvaluationSourceExperiment || result.Source == types.EvaluationSourceOverride {
+		eventType = dto.EventTypeExperimentEvaluation
+	}
+	userAttributes := req.Attributes
+	if userAttributes == nil {
+		userAttributes = map[string]interface{}{}
+	}
+
+	event := dto.TrackEventRequest{
+		ID:             uuid.NewString(),
+		ServiceName:    req.ServiceName,
+		EventType:      eventType,
+		ParameterName:  result.ParameterName,
+		Source:         result.Source,
+		UserAttributes: userAttributes,
+		RolloutValue:   rolloutValue.Raw(),
+		Timestamp:      time.Now(),
+		ExperimentID:   result.ExperimentID,
+		ExperimentUUID: result.ExperimentUUID,
+		VariantID:      result.VariantID,
+		VariantName:    result.VariantName,
+		// Values forced by the experiment's overrides belong to the experiment but are not exposures
+		VariantOverridden: result.Source == types.EvaluationSourceOverride,
+	}
+	if result.Error != "" {
+		errorMessage := result.Error
+		event.Error = &errorMessage
+	}
+	return event
+}
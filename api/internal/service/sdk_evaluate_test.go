@@ -0,0 +1,112 @@
+package service
+
+import (
+	"api/internal/dto"
+	"api/internal/model"
+	"context"
+	"sdk"
+	"sdk/pkg/errors"
+	"sdk/types"
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// evaluatingClient serves rollout values by parameter name, and records the attributes it was given
+type evaluatingClient struct {
+	sdk.Client
+	values     map[string]sdk.RolloutValue
+	attributes *sdk.Attribute
+}
+
+func (c *evaluatingClient) EvaluateParameterTraced(_ context.Context, parameterName string, attribute *sdk.Attribute) (sdk.RolloutValue, types.EvaluationDetails) {
+	c.attributes = attribute
+	value, ok := c.values[parameterName]
+	if !ok {
+		return sdk.NewRolloutValueWithError(errors.NewParameterNotFoundError(parameterName)), types.EvaluationDetails{Source: types.EvaluationSourceParameter}
+	}
+	return value, types.EvaluationDetails{Source: types.EvaluationSourceParameter}
+}
+
+// capturingEventRepository keeps the inserted events and reports event IDs it already holds as duplicates
+type capturingEventRepository struct {
+	EventRepositoryInterface
+	events []model.EvaluationEvent
+}
+
+func (r *capturingEventRepository) InsertEvents(_ context.Context, events []model.EvaluationEvent) ([]string, error) {
+	var inserted []string
+	for _, event := range events {
+		if slices.ContainsFunc(r.events, func(stored model.EvaluationEvent) bool { return stored.EventID == event.EventID }) {
+			continue
+		}
+		r.events = append(r.events, event)
+		inserted = append(inserted, event.EventID)
+	}
+	return inserted, nil
+}
+
+func TestEvaluateSDKBatchRecordsEventsWithRequestServiceName(t *testing.T) {
+	limit := "25"
+	client := &evaluatingClient{values: map[string]sdk.RolloutValue{
+		"checkout_limit": sdk.NewRolloutValue(&limit, types.ParameterDataTypeNumber),
+	}}
+	events := &capturingEventRepository{}
+	s := &service{auroraClient: client, eventService: NewEventService(events, zerolog.Nop())}
+
+	response, err := s.EvaluateSDK(context.Background(), &dto.EvaluateSDKRequest{
+		ServiceName:    "python-checkout",
+		ParameterNames: []string{"checkout_limit", "missing"},
+		Attributes:     map[string]interface{}{"country": "US", "age": float64(25), "beta": true},
+	})
+	require.NoError(t, err)
+
+	// Attributes keep their JSON types instead of being parsed from strings
+	require.Equal(t, float64(25), client.attributes.Get("age"))
+	require.Equal(t, true, client.attributes.Get("beta"))
+
+	require.Len(t, response.Results, 2)
+	require.Equal(t, float64(25), response.Results[0].Value)
+	require.Equal(t, "number", response.Results[0].DataType)
+	require.Empty(t, response.Results[0].Error)
+	require.Nil(t, response.Results[1].Value)
+	require.NotEmpty(t, response.Results[1].Error)
+
+	require.Len(t, events.events, 2)
+	for _, event := range events.events {
+		require.Equal(t, "python-checkout", event.ServiceName)
+		require.Equal(t, string(dto.EventTypeParameterEvaluation), event.EventType)
+	}
+	require.Equal(t, "25", *events.events[0].RolloutValue)
+	require.NotNil(t, events.events[1].Error)
+}
+
+func TestEvaluateSDKRequiresParameterName(t *testing.T) {
+	s := &service{}
+
+	_, err := s.EvaluateSDK(context.Background(), &dto.EvaluateSDKRequest{ServiceName: "python-checkout"})
+	require.Error(t, err)
+	_, err = s.EvaluateSDK(context.Background(), &dto.EvaluateSDKRequest{ServiceName: "python-checkout", ParameterName: "a", ParameterNames: []string{"b"}})
+	require.Error(t, err)
+}
+
+func TestEvaluationEventMarksOverriddenVariant(t *testing.T) {
+	experimentID, variantID := 7, 12
+	variant := "treatment"
+	req := &dto.EvaluateSDKRequest{ServiceName: "python-checkout"}
+
+	for _, source := range []string{types.EvaluationSourceExperiment, types.EvaluationSourceOverride} {
+		event := evaluationEvent(req, &dto.EvaluateSDKResult{
+			ParameterName: "checkout_button",
+			Source:        source,
+			ExperimentID:  &experimentID,
+			VariantID:     &variantID,
+			VariantName:   &variant,
+		}, sdk.NewRolloutValue(&variant, types.ParameterDataTypeString))
+
+		require.Equal(t, dto.EventTypeExperimentEvaluation, event.EventType, source)
+		require.Equal(t, source == types.EvaluationSourceOverride, event.VariantOverridden, source)
+	}
+}
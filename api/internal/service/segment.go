@@ -1,39 +1,52 @@
 package service
 
 import (
+	"api/internal/apperror"
 	"api/internal/dto"
 	"api/internal/model"
+	"api/internal/repository"
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"sdk/types"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
 // CreateSegment creates a new segment with its rules and conditions
-func (s *service) CreateSegment(ctx context.Context, req *dto.CreateSegmentRequest) (*model.Segment, error) {
+func (s *service) CreateSegment(ctx context.Context, userID uint, req *dto.CreateSegmentRequest) (*model.Segment, error) {
 	// Check if segment with same name already exists
 	existing, err := s.repo.GetSegmentByName(ctx, req.Name)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 	if existing != nil {
-		return nil, errors.New("segment with name '" + req.Name + "' already exists")
+		return nil, apperror.Conflict("segment with name '%s' already exists", req.Name)
 	}
 
-	// Validate that all referenced attributes exist if rules are provided
+	// Validate that all referenced attributes and segments exist if rules are provided
 	if len(req.Rules) > 0 {
+		var validator conditionValidator
 		for _, ruleReq := range req.Rules {
+			if err := s.validateSegmentRuleReference(ctx, ruleReq.Name, ruleReq.ReferencedSegmentID, ruleReq.ReferenceType, len(ruleReq.Conditions)); err != nil {
+				return nil, err
+			}
 			for _, conditionReq := range ruleReq.Conditions {
-				_, err := s.repo.GetAttributeByID(ctx, conditionReq.AttributeID)
+				attribute, err := s.repo.GetAttributeByID(ctx, conditionReq.AttributeID)
 				if err != nil {
 					if errors.Is(err, gorm.ErrRecordNotFound) {
-						return nil, fmt.Errorf("attribute with ID %d not found", conditionReq.AttributeID)
+						return nil, apperror.NotFound("attribute with ID %d not found", conditionReq.AttributeID)
 					}
 					return nil, err
 				}
+				validator.check(ruleReq.Name, attribute, conditionReq.Operator, conditionReq.Value)
 			}
 		}
+		if err := validator.err(); err != nil {
+			return nil, err
+		}
 	}
 
 	// Create segment with rules and conditions
@@ -46,9 +59,11 @@ func (s *service) CreateSegment(ctx context.Context, req *dto.CreateSegmentReque
 	// Create rules and conditions
 	for i, ruleReq := range req.Rules {
 		rule := model.SegmentRule{
-			Name:        ruleReq.Name,
-			Description: ruleReq.Description,
-			Conditions:  make([]model.SegmentRuleCondition, len(ruleReq.Conditions)),
+			Name:                ruleReq.Name,
+			Description:         ruleReq.Description,
+			ReferencedSegmentID: ruleReq.ReferencedSegmentID,
+			ReferenceType:       ruleReq.ReferenceType,
+			Conditions:          make([]model.SegmentRuleCondition, len(ruleReq.Conditions)),
 		}
 
 		for j, conditionReq := range ruleReq.Conditions {
@@ -63,7 +78,20 @@ func (s *service) CreateSegment(ctx context.Context, req *dto.CreateSegmentReque
 		segment.Rules[i] = rule
 	}
 
-	if err := s.repo.CreateSegment(ctx, segment); err != nil {
+	if err := s.checkSegmentReferences(ctx, segment); err != nil {
+		return nil, err
+	}
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.CreateSegment(ctx, segment); err != nil {
+			return err
+		}
+		after, err := segmentSnapshot(segment)
+		if err != nil {
+			return err
+		}
+		return s.recordAuditLog(ctx, txRepo, userID, model.AuditEntitySegment, segment.ID, model.AuditActionCreate, nil, after)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -75,7 +103,7 @@ func (s *service) GetSegmentByID(ctx context.Context, id uint) (*model.Segment,
 	segment, err := s.repo.GetSegmentByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("segment with ID %d not found", id)
+			return nil, apperror.NotFound("segment with ID %d not found", id)
 		}
 		return nil, err
 	}
@@ -87,17 +115,22 @@ func (s *service) GetSegmentByName(ctx context.Context, name string) (*model.Seg
 	return s.repo.GetSegmentByName(ctx, name)
 }
 
-// GetAllSegments retrieves all segments
-func (s *service) GetAllSegments(ctx context.Context) ([]*model.Segment, error) {
-	return s.repo.GetAllSegments(ctx, 0, 0) // No pagination for findAll equivalent
+// GetAllSegments retrieves a page of segments filtered by name or description, with the total count.
+// A limit of 0 returns all matching segments.
+func (s *service) GetAllSegments(ctx context.Context, limit, offset int, search string) ([]*model.Segment, int64, error) {
+	return s.repo.GetAllSegmentsPaginated(ctx, limit, offset, strings.TrimSpace(search))
 }
 
 // UpdateSegment updates an existing segment
-func (s *service) UpdateSegment(ctx context.Context, id uint, req *dto.UpdateSegmentRequest) (*model.Segment, error) {
+func (s *service) UpdateSegment(ctx context.Context, id uint, userID uint, req *dto.UpdateSegmentRequest) (*model.Segment, error) {
 	segment, err := s.GetSegmentByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before, err := segmentSnapshot(segment)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if name is being updated and if it conflicts
 	if req.Name != nil && *req.Name != segment.Name {
@@ -106,7 +139,7 @@ func (s *service) UpdateSegment(ctx context.Context, id uint, req *dto.UpdateSeg
 			return nil, err
 		}
 		if existing != nil {
-			return nil, errors.New("segment with name '" + *req.Name + "' already exists")
+			return nil, apperror.Conflict("segment with name '%s' already exists", *req.Name)
 		}
 		segment.Name = *req.Name
 	}
@@ -118,21 +151,24 @@ func (s *service) UpdateSegment(ctx context.Context, id uint, req *dto.UpdateSeg
 
 	// If rules are being updated, replace all existing rules
 	if len(req.Rules) > 0 {
-		// Validate that all referenced attributes exist
+		// Validate that all referenced attributes and segments exist
+		var validator conditionValidator
 		for _, ruleReq := range req.Rules {
+			if err := s.validateSegmentRuleReference(ctx, ruleReq.Name, ruleReq.ReferencedSegmentID, ruleReq.ReferenceType, len(ruleReq.Conditions)); err != nil {
+				return nil, err
+			}
 			for _, conditionReq := range ruleReq.Conditions {
-				_, err := s.repo.GetAttributeByID(ctx, conditionReq.AttributeID)
+				attribute, err := s.repo.GetAttributeByID(ctx, conditionReq.AttributeID)
 				if err != nil {
 					if errors.Is(err, gorm.ErrRecordNotFound) {
-						return nil, fmt.Errorf("attribute with ID %d not found", conditionReq.AttributeID)
+						return nil, apperror.NotFound("attribute with ID %d not found", conditionReq.AttributeID)
 					}
 					return nil, err
 				}
+				validator.check(ruleReq.Name, attribute, conditionReq.Operator, conditionReq.Value)
 			}
 		}
-
-		// Remove existing rules (cascade will handle conditions)
-		if err := s.repo.DeleteSegmentRulesBySegmentID(ctx, id); err != nil {
+		if err := validator.err(); err != nil {
 			return nil, err
 		}
 
@@ -140,10 +176,12 @@ func (s *service) UpdateSegment(ctx context.Context, id uint, req *dto.UpdateSeg
 		newRules := make([]model.SegmentRule, len(req.Rules))
 		for i, ruleReq := range req.Rules {
 			rule := model.SegmentRule{
-				Name:        ruleReq.Name,
-				Description: ruleReq.Description,
-				SegmentID:   id,
-				Conditions:  make([]model.SegmentRuleCondition, len(ruleReq.Conditions)),
+				Name:                ruleReq.Name,
+				Description:         ruleReq.Description,
+				SegmentID:           id,
+				ReferencedSegmentID: ruleReq.ReferencedSegmentID,
+				ReferenceType:       ruleReq.ReferenceType,
+				Conditions:          make([]model.SegmentRuleCondition, len(ruleReq.Conditions)),
 			}
 
 			for j, conditionReq := range ruleReq.Conditions {
@@ -158,36 +196,214 @@ func (s *service) UpdateSegment(ctx context.Context, id uint, req *dto.UpdateSeg
 			newRules[i] = rule
 		}
 
+		// The new rules must not introduce a reference cycle, nor grow the expansion of this segment
+		// or of the segments referencing it past the limits
+		updated := *segment
+		updated.Rules = newRules
+		if err := s.checkSegmentReferences(ctx, &updated); err != nil {
+			return nil, err
+		}
+
 		segment.Rules = newRules
 	}
 
-	if err := s.repo.UpdateSegment(ctx, segment); err != nil {
+	err = s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if len(req.Rules) > 0 {
+			// Remove existing rules (cascade will handle conditions)
+			if err := txRepo.DeleteSegmentRulesBySegmentID(ctx, id); err != nil {
+				return err
+			}
+		}
+		if err := txRepo.UpdateSegment(ctx, segment); err != nil {
+			return err
+		}
+		after, err := segmentSnapshot(segment)
+		if err != nil {
+			return err
+		}
+		return s.recordAuditLog(ctx, txRepo, userID, model.AuditEntitySegment, id, model.AuditActionUpdate, before, after)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return segment, nil
 }
 
-// DeleteSegment deletes a segment
-func (s *service) DeleteSegment(ctx context.Context, id uint) error {
+// DeleteSegment deletes a segment that no parameter, experiment or other segment references
+func (s *service) DeleteSegment(ctx context.Context, id uint, userID uint) error {
 	segment, err := s.GetSegmentByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.DeleteSegment(ctx, segment.ID)
+	referrers, err := s.repo.GetReferencingSegmentIDs(ctx, segment.ID)
+	if err != nil {
+		return err
+	}
+	if len(referrers) > 0 {
+		return apperror.Conflict("segment '%s' is still referenced by %d other segment(s)", segment.Name, len(referrers))
+	}
+
+	params, experiments, err := s.repo.CountSegmentReferences(ctx, segment.ID)
+	if err != nil {
+		return err
+	}
+	if params > 0 || experiments > 0 {
+		return apperror.Conflict("segment '%s' is still referenced by %d parameter(s) and %d experiment(s)", segment.Name, params, experiments)
+	}
+
+	before, err := segmentSnapshot(segment)
+	if err != nil {
+		return err
+	}
+	return s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.DeleteSegment(ctx, segment.ID); err != nil {
+			return err
+		}
+		return s.recordAuditLog(ctx, txRepo, userID, model.AuditEntitySegment, segment.ID, model.AuditActionDelete, before, nil)
+	})
 }
 
-// CheckSegmentOverlap checks if any segments in the provided list overlap with each other
-func (s *service) CheckSegmentOverlap(ctx context.Context, segmentIDs []uint) (bool, error) {
+// CheckSegmentOverlap checks if the provided segments overlap and explains which conditions overlap
+func (s *service) CheckSegmentOverlap(ctx context.Context, segmentIDs []uint) (*dto.CheckSegmentOverlapResponse, error) {
 	if len(segmentIDs) != 2 {
-		return false, fmt.Errorf("at least 2 segment IDs are required")
+		return nil, apperror.BadRequest("at least 2 segment IDs are required")
 	}
 
-	// Check all pairs of segments for overlap
-	hasOverlap, err := s.checkSegmentOverlap(ctx, int(segmentIDs[0]), int(segmentIDs[1]))
+	hasOverlap, explanation, err := s.checkSegmentOverlap(ctx, int(segmentIDs[0]), int(segmentIDs[1]))
 	if err != nil {
-		return false, fmt.Errorf("failed to check overlap between segments %d and %d: %w", segmentIDs[0], segmentIDs[1], err)
+		return nil, fmt.Errorf("failed to check overlap between segments %d and %d: %w", segmentIDs[0], segmentIDs[1], err)
+	}
+	return dto.ToCheckSegmentOverlapResponse(hasOverlap, explanation), nil
+}
+
+// validateSegmentRuleReference checks that a rule either holds conditions or references an existing
+// segment with a valid reference type
+func (s *service) validateSegmentRuleReference(ctx context.Context, rule string, referencedSegmentID *uint, referenceType model.SegmentReferenceType, conditions int) error {
+	if referencedSegmentID == nil {
+		if referenceType != "" {
+			return apperror.BadRequest("rule '%s' has a reference type but references no segment", rule)
+		}
+		return nil
+	}
+	if conditions > 0 {
+		return apperror.BadRequest("rule '%s' must either hold conditions or reference a segment, not both", rule)
+	}
+	if referenceType != model.SegmentReferenceInclude && referenceType != model.SegmentReferenceExclude {
+		return apperror.BadRequest("rule '%s' has reference type '%s', expected include or exclude", rule, referenceType)
+	}
+	_, err := s.GetSegmentByID(ctx, *referencedSegmentID)
+	return err
+}
+
+// checkSegmentReferences checks that segment, as it is about to be saved, and the segments referencing it
+// directly or indirectly still expand to conditions: without reference cycles and within the depth and
+// size limits
+func (s *service) checkSegmentReferences(ctx context.Context, segment *model.Segment) error {
+	segments := []*model.Segment{segment}
+	if segment.ID != 0 {
+		referrers, err := s.getReferencingSegments(ctx, segment.ID)
+		if err != nil {
+			return err
+		}
+		segments = append(segments, referrers...)
+	}
+
+	for _, referrer := range segments {
+		expanded := *referrer
+		if err := s.repo.ExpandSegmentReferences(ctx, &expanded, segment); err != nil {
+			if errors.Is(err, model.ErrInvalidSegmentReference) {
+				return apperror.BadRequest("%v", err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// getReferencingSegments returns the segments referencing the segment directly or indirectly
+func (s *service) getReferencingSegments(ctx context.Context, id uint) ([]*model.Segment, error) {
+	visited := map[uint]bool{id: true}
+	pending := []uint{id}
+	var referrers []*model.Segment
+	for len(pending) > 0 {
+		ids, err := s.repo.GetReferencingSegmentIDs(ctx, pending[0])
+		if err != nil {
+			return nil, err
+		}
+		pending = pending[1:]
+		for _, referrerID := range ids {
+			if visited[referrerID] {
+				continue
+			}
+			visited[referrerID] = true
+			referrer, err := s.repo.GetSegmentByID(ctx, referrerID)
+			if err != nil {
+				return nil, err
+			}
+			referrers = append(referrers, referrer)
+			pending = append(pending, referrerID)
+		}
+	}
+	return referrers, nil
+}
+
+// conditionValidator collects the conditions of a request that are invalid for their attributes,
+// so that all of them are reported at once
+type conditionValidator struct {
+	problems []apperror.ConditionProblem
+}
+
+// check records a problem when the condition is invalid for the attribute and reports whether it is valid
+func (v *conditionValidator) check(rule string, attribute *model.Attribute, operator model.ConditionOperator, value string) bool {
+	err := validateCondition(attribute, operator, value)
+	if err == nil {
+		return true
+	}
+	v.problems = append(v.problems, apperror.ConditionProblem{
+		Rule:        rule,
+		AttributeID: attribute.ID,
+		Attribute:   attribute.Name,
+		Operator:    string(operator),
+		Value:       value,
+		Message:     err.Error(),
+	})
+	return false
+}
+
+// err returns a condition validation error listing every recorded problem, or nil if there are none
+func (v *conditionValidator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &apperror.ConditionValidationError{Problems: v.problems}
+}
+
+// validateCondition validates a condition against its operator and the data type of its attribute
+func validateCondition(attribute *model.Attribute, operator model.ConditionOperator, value string) error {
+	if err := validateConditionValue(operator, value); err != nil {
+		return err
+	}
+	if err := validateConditionForAttribute(attribute, operator, value); err != nil {
+		return apperror.BadRequest("%v", err)
+	}
+	return nil
+}
+
+// validateConditionValue validates a condition value against its operator
+func validateConditionValue(operator model.ConditionOperator, value string) error {
+	// Between bounds depend on the data type of the attribute, so validateConditionForAttribute checks them
+	switch operator {
+	case model.ConditionOperatorMatchesRegex, model.ConditionOperatorNotMatchesRegex:
+		if _, err := regexp.Compile(value); err != nil {
+			return apperror.BadRequest("invalid regex pattern %q: %v", value, err)
+		}
+	case model.ConditionOperatorSemverGt, model.ConditionOperatorSemverLt, model.ConditionOperatorSemverGte,
+		model.ConditionOperatorSemverLte, model.ConditionOperatorSemverEq:
+		if _, err := types.ParseSemver(value); err != nil {
+			return apperror.BadRequest("%v", err)
+		}
 	}
-	return hasOverlap, nil
+	return nil
 }
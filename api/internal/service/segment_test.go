@@ -0,0 +1,167 @@
+package service
+
+import (
+	"api/internal/apperror"
+	"api/internal/dto"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// segmentRepository serves attributes by ID from memory and has no segments
+type segmentRepository struct {
+	repository.Repository
+	attributes map[uint]*model.Attribute
+}
+
+func (r *segmentRepository) GetSegmentByName(context.Context, string) (*model.Segment, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *segmentRepository) GetAttributeByID(_ context.Context, id uint) (*model.Attribute, error) {
+	if attribute, ok := r.attributes[id]; ok {
+		return attribute, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestCreateSegmentReportsEveryInvalidCondition(t *testing.T) {
+	s := &service{repo: &segmentRepository{attributes: map[uint]*model.Attribute{
+		1: {ID: 1, Name: "age", DataType: model.DataTypeNumber},
+		2: {ID: 2, Name: "country", DataType: model.DataTypeEnum, EnumOptions: []string{"VN", "US"}},
+	}}}
+
+	_, err := s.CreateSegment(context.Background(), 1, &dto.CreateSegmentRequest{
+		Name: "adults in asia",
+		Rules: []dto.CreateSegmentRuleRequest{{
+			Name: "adults",
+			Conditions: []dto.CreateSegmentRuleConditionRequest{
+				{AttributeID: 1, Operator: model.ConditionOperatorGreaterThanOrEqual, Value: "18"},
+				{AttributeID: 1, Operator: model.ConditionOperatorContains, Value: "1"},
+				{AttributeID: 2, Operator: model.ConditionOperatorIn, Value: "VN,TH"},
+			},
+		}},
+	})
+
+	var conditionErr *apperror.ConditionValidationError
+	require.True(t, errors.As(err, &conditionErr))
+	require.True(t, errors.Is(err, apperror.ErrBadRequest))
+	require.Equal(t, []apperror.ConditionProblem{
+		{Rule: "adults", AttributeID: 1, Attribute: "age", Operator: "contains", Value: "1", Message: "operator contains is not supported for number attributes"},
+		{Rule: "adults", AttributeID: 2, Attribute: "country", Operator: "in", Value: "VN,TH", Message: "value 'TH' is not an option of attribute 'country'"},
+	}, conditionErr.Problems)
+}
+
+// nestedSegmentRepository serves segments that reference each other from memory
+type nestedSegmentRepository struct {
+	repository.Repository
+	segments map[uint]*model.Segment
+	deleted  []uint
+}
+
+func (r *nestedSegmentRepository) GetSegmentByID(_ context.Context, id uint) (*model.Segment, error) {
+	if segment, ok := r.segments[id]; ok {
+		return segment, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *nestedSegmentRepository) GetReferencingSegmentIDs(_ context.Context, segmentID uint) ([]uint, error) {
+	var ids []uint
+	for id, segment := range r.segments {
+		for _, rule := range segment.Rules {
+			if rule.References() && *rule.ReferencedSegmentID == segmentID {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (r *nestedSegmentRepository) ExpandSegmentReferences(_ context.Context, segment *model.Segment, replacements ...*model.Segment) error {
+	segments := maps.Clone(r.segments)
+	for _, replacement := range append(replacements, segment) {
+		segments[replacement.ID] = replacement
+	}
+	rules, err := model.ExpandSegmentRules(segment, segments)
+	if err != nil {
+		return err
+	}
+	segment.Rules = rules
+	return nil
+}
+
+func (r *nestedSegmentRepository) DeleteSegment(_ context.Context, id uint) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func TestUpdateSegmentRejectsReferenceCycle(t *testing.T) {
+	a, b := uint(1), uint(2)
+	repo := &nestedSegmentRepository{segments: map[uint]*model.Segment{
+		a: {ID: a, Name: "a", Rules: []model.SegmentRule{{Name: "b", ReferencedSegmentID: &b, ReferenceType: model.SegmentReferenceInclude}}},
+		b: {ID: b, Name: "b"},
+	}}
+	s := &service{repo: repo}
+
+	_, err := s.UpdateSegment(context.Background(), b, 1, &dto.UpdateSegmentRequest{
+		Rules: []dto.UpdateSegmentRuleRequest{{Name: "a", ReferencedSegmentID: &a, ReferenceType: model.SegmentReferenceExclude}},
+	})
+
+	require.True(t, errors.Is(err, apperror.ErrBadRequest))
+	require.ErrorContains(t, err, "b -> a -> b is a cycle")
+}
+
+func TestUpdateSegmentRejectsRuleWithConditionsAndReference(t *testing.T) {
+	a, b := uint(1), uint(2)
+	repo := &nestedSegmentRepository{segments: map[uint]*model.Segment{a: {ID: a, Name: "a"}, b: {ID: b, Name: "b"}}}
+	s := &service{repo: repo}
+
+	_, err := s.UpdateSegment(context.Background(), b, 1, &dto.UpdateSegmentRequest{
+		Rules: []dto.UpdateSegmentRuleRequest{{
+			Name:                "a",
+			ReferencedSegmentID: &a,
+			ReferenceType:       model.SegmentReferenceInclude,
+			Conditions:          []dto.UpdateSegmentRuleConditionRequest{{AttributeID: 1, Operator: model.ConditionOperatorIsSet}},
+		}},
+	})
+
+	require.True(t, errors.Is(err, apperror.ErrBadRequest))
+}
+
+func TestDeleteSegmentRefusesSegmentReferencedByAnotherSegment(t *testing.T) {
+	a, b := uint(1), uint(2)
+	repo := &nestedSegmentRepository{segments: map[uint]*model.Segment{
+		a: {ID: a, Name: "a", Rules: []model.SegmentRule{{Name: "b", ReferencedSegmentID: &b, ReferenceType: model.SegmentReferenceInclude}}},
+		b: {ID: b, Name: "b"},
+	}}
+	s := &service{repo: repo}
+
+	err := s.DeleteSegment(context.Background(), b, 1)
+
+	require.True(t, errors.Is(err, apperror.ErrConflict))
+	require.Empty(t, repo.deleted)
+}
+
+func TestCreateSegmentPersistsPresenceConditions(t *testing.T) {
+	operators := migratedEnumValues(t, "condition_operator")
+	require.Contains(t, operators, "equals")
+	connector := &recordingConnector{fail: enumColumnCheck("segment_rule_conditions", "operator", operators)}
+	s := newRecordingService(t, connector)
+
+	for _, operator := range []model.ConditionOperator{model.ConditionOperatorIsSet, model.ConditionOperatorIsNotSet} {
+		segment := &model.Segment{Name: "with " + string(operator), Rules: []model.SegmentRule{
+			{Name: "app version", Conditions: []model.SegmentRuleCondition{{AttributeID: 1, Operator: operator}}},
+		}}
+		require.NoError(t, s.repo.CreateSegment(context.Background(), segment), operator)
+	}
+	require.Contains(t, strings.Join(connector.committed, "\n"), `INSERT INTO "segment_rule_conditions"`)
+}
@@ -7,50 +7,79 @@ import (
 	"api/internal/model"
 	"api/internal/repository"
 	"context"
+	"database/sql"
+	"io"
 	"sdk"
 	"sdk/types"
+	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/oauth2"
 )
 
 // Service defines the interface for all business logic operations
 type Service interface {
+	// Health operations
+	HealthCheck(ctx context.Context) dto.HealthCheckResponse
+
 	// Attribute operations
-	CreateAttribute(ctx context.Context, req *dto.CreateAttributeRequest) (*model.Attribute, error)
+	CreateAttribute(ctx context.Context, userID uint, req *dto.CreateAttributeRequest) (*model.Attribute, error)
 	GetAttributeByID(ctx context.Context, id uint) (*model.Attribute, error)
 	GetAttributeByName(ctx context.Context, name string) (*model.Attribute, error)
-	GetAllAttributes(ctx context.Context) ([]*model.Attribute, error)
-	UpdateAttribute(ctx context.Context, id uint, req *dto.UpdateAttributeRequest) (*model.Attribute, error)
-	DeleteAttribute(ctx context.Context, id uint) error
+	ListAttributes(ctx context.Context, filter model.AttributeFilter) ([]*model.Attribute, int64, error)
+	UpdateAttribute(ctx context.Context, id uint, userID uint, req *dto.UpdateAttributeRequest) (*model.Attribute, error)
+	DeleteAttribute(ctx context.Context, id uint, userID uint) error
 	IncrementAttributeUsageCount(ctx context.Context, id uint) error
 	DecrementAttributeUsageCount(ctx context.Context, id uint) error
+	MergeAttribute(ctx context.Context, sourceID uint, targetID uint, userID uint, dryRun bool) (*dto.MergeAttributeResponse, error)
 
 	// Segment operations
-	CreateSegment(ctx context.Context, req *dto.CreateSegmentRequest) (*model.Segment, error)
+	CreateSegment(ctx context.Context, userID uint, req *dto.CreateSegmentRequest) (*model.Segment, error)
 	GetSegmentByID(ctx context.Context, id uint) (*model.Segment, error)
 	GetSegmentByName(ctx context.Context, name string) (*model.Segment, error)
-	GetAllSegments(ctx context.Context) ([]*model.Segment, error)
-	UpdateSegment(ctx context.Context, id uint, req *dto.UpdateSegmentRequest) (*model.Segment, error)
-	DeleteSegment(ctx context.Context, id uint) error
-	CheckSegmentOverlap(ctx context.Context, segmentIDs []uint) (bool, error)
+	GetAllSegments(ctx context.Context, limit, offset int, search string) ([]*model.Segment, int64, error)
+	UpdateSegment(ctx context.Context, id uint, userID uint, req *dto.UpdateSegmentRequest) (*model.Segment, error)
+	DeleteSegment(ctx context.Context, id uint, userID uint) error
+	CheckSegmentOverlap(ctx context.Context, segmentIDs []uint) (*dto.CheckSegmentOverlapResponse, error)
 
 	// Parameter operations
-	CreateParameter(ctx context.Context, req *dto.CreateParameterRequest) (*model.Parameter, error)
+	CreateParameter(ctx context.Context, userID uint, req *dto.CreateParameterRequest) (*model.Parameter, error)
 	GetParameterByID(ctx context.Context, id uint) (*model.Parameter, error)
 	GetParameterByName(ctx context.Context, name string) (*model.Parameter, error)
 	GetAllParameters(ctx context.Context) ([]*model.Parameter, error)
-	GetAllParametersSDK(ctx context.Context) ([]types.Parameter, error)
-	UpdateParameter(ctx context.Context, id uint, req *dto.UpdateParameterRequest) (*model.Parameter, error)
-	UpdateParameterWithRules(ctx context.Context, id uint, req *dto.UpdateParameterWithRulesRequest) (*model.Parameter, error)
-	DeleteParameter(ctx context.Context, id uint) error
-	AddParameterRule(ctx context.Context, parameterID uint, req *dto.CreateParameterRuleRequest) (*model.Parameter, error)
-	UpdateParameterRule(ctx context.Context, parameterID uint, ruleID uint, req *dto.UpdateParameterRuleRequest) (*model.Parameter, error)
-	DeleteParameterRule(ctx context.Context, parameterID uint, ruleID uint) (*model.Parameter, error)
+	GetParametersPage(ctx context.Context, limit int, after uint, includeArchived bool) ([]*model.Parameter, *uint, error)
+	GetAllParametersSDK(ctx context.Context, environmentKey string) ([]types.Parameter, error)
+	GetParametersSDKETag(ctx context.Context, environmentKey string) (string, error)
+	EvaluateSDK(ctx context.Context, req *dto.EvaluateSDKRequest) (*dto.EvaluateSDKResponse, error)
+	UpdateParameter(ctx context.Context, id uint, userID uint, req *dto.UpdateParameterRequest) (*model.Parameter, error)
+	UpdateParameterWithRules(ctx context.Context, id uint, userID uint, req *dto.UpdateParameterWithRulesRequest) (*model.Parameter, error)
+	DeleteParameter(ctx context.Context, id uint, userID uint) error
+	ArchiveParameter(ctx context.Context, id uint, userID uint) (*model.Parameter, error)
+	UnarchiveParameter(ctx context.Context, id uint, userID uint) (*model.Parameter, error)
+	GetDeletedParametersPage(ctx context.Context, limit int, after uint) ([]*model.Parameter, *uint, error)
+	RestoreParameter(ctx context.Context, id uint, userID uint) (*model.Parameter, error)
+	CloneParameter(ctx context.Context, id uint, userID uint, req *dto.CloneParameterRequest) (*model.Parameter, error)
+	AddParameterRule(ctx context.Context, parameterID uint, userID uint, req *dto.CreateParameterRuleRequest) (*model.Parameter, error)
+	UpdateParameterRule(ctx context.Context, parameterID uint, ruleID uint, userID uint, req *dto.UpdateParameterRuleRequest) (*model.Parameter, error)
+	DeleteParameterRule(ctx context.Context, parameterID uint, ruleID uint, userID uint) (*model.Parameter, error)
+	ReorderParameterRules(ctx context.Context, parameterID uint, userID uint, req *dto.ReorderParameterRulesRequest) (*model.Parameter, error)
 	IncrementParameterUsageCount(ctx context.Context, id uint) error
 	DecrementParameterUsageCount(ctx context.Context, id uint) error
+	ExportParameters(ctx context.Context) (*dto.ParameterExportDocument, error)
+	ImportParameters(ctx context.Context, userID uint, req *dto.ImportParametersRequest, dryRun bool) (*dto.ImportParametersResponse, error)
+	GetParameterEnvironmentOverrides(ctx context.Context, parameterID uint) ([]*model.ParameterEnvironmentOverride, error)
+	SetParameterEnvironmentOverride(ctx context.Context, parameterID uint, environmentID uint, userID uint, req *dto.SetParameterEnvironmentOverrideRequest) (*model.ParameterEnvironmentOverride, error)
+	DeleteParameterEnvironmentOverride(ctx context.Context, parameterID uint, environmentID uint, userID uint) error
+
+	// Environment operations
+	CreateEnvironment(ctx context.Context, req *dto.CreateEnvironmentRequest) (*model.Environment, error)
+	GetAllEnvironments(ctx context.Context) ([]*model.Environment, error)
+	GetEnvironmentByID(ctx context.Context, id uint) (*model.Environment, error)
+	UpdateEnvironment(ctx context.Context, id uint, req *dto.UpdateEnvironmentRequest) (*model.Environment, error)
+	DeleteEnvironment(ctx context.Context, id uint) error
 
 	// Parameter Change Request operations
 	CreateParameterChangeRequest(ctx context.Context, userID uint, req *dto.CreateParameterChangeRequestRequest) (*model.ParameterChangeRequest, error)
@@ -59,18 +88,44 @@ type Service interface {
 	GetPendingParameterChangeRequestByParameterID(ctx context.Context, parameterID uint) (*model.ParameterChangeRequest, error)
 	GetParameterChangeRequestsByParameterID(ctx context.Context, parameterID uint) ([]*model.ParameterChangeRequest, error)
 	GetParameterChangeRequestsByStatus(ctx context.Context, status model.ParameterChangeRequestStatus, limit, offset int) ([]*model.ParameterChangeRequest, int64, error)
-	ApproveParameterChangeRequest(ctx context.Context, id uint, userID uint, req *dto.ApproveParameterChangeRequestRequest) (*model.ParameterChangeRequest, error)
+	ApproveParameterChangeRequest(ctx context.Context, id uint, userID uint, req *dto.ApproveParameterChangeRequestRequest) (*model.ParameterChangeRequest, *dto.ParameterChangeRequestDiff, error)
+	GetParameterChangeRequestDiff(ctx context.Context, id uint) (*dto.ParameterChangeRequestDiff, error)
 	RejectParameterChangeRequest(ctx context.Context, id uint, userID uint, req *dto.RejectParameterChangeRequestRequest) (*model.ParameterChangeRequest, error)
+	CancelParameterChangeRequest(ctx context.Context, id uint, userID uint, req *dto.CancelParameterChangeRequestRequest) (*model.ParameterChangeRequest, error)
 
 	// Experiment operations
-	CreateExperiment(ctx context.Context, req *dto.CreateExperimentRequest) (string, error)
-	GetAllExperiments(ctx context.Context) ([]*model.Experiment, error)
+	CreateExperiment(ctx context.Context, userID uint, req *dto.CreateExperimentRequest) (string, error)
+	GetAllExperiments(ctx context.Context, filter model.ExperimentFilter, limit, offset int) ([]*model.ExperimentSummary, int64, error)
 	GetExperimentByID(ctx context.Context, id uint) (*model.Experiment, []*model.ExperimentVariant, map[int][]*model.ExperimentVariantParameter, *model.Attribute, error)
-	RejectExperiment(ctx context.Context, id uint, req *dto.RejectExperimentRequest) (*model.Experiment, error)
-	ApproveExperiment(ctx context.Context, id uint, req *dto.ApproveExperimentRequest) (*model.Experiment, error)
-	AbortExperiment(ctx context.Context, id uint, req *dto.AbortExperimentRequest) (*model.Experiment, error)
+	GetExperimentByUuid(ctx context.Context, experimentUUID string) (*model.Experiment, []*model.ExperimentVariant, map[int][]*model.ExperimentVariantParameter, *model.Attribute, error)
+	RejectExperiment(ctx context.Context, id uint, userID uint, req *dto.RejectExperimentRequest) (*model.Experiment, error)
+	ApproveExperiment(ctx context.Context, id uint, userID uint, req *dto.ApproveExperimentRequest) (*model.Experiment, error)
+	AbortExperiment(ctx context.Context, id uint, userID uint, req *dto.AbortExperimentRequest) (*model.Experiment, error)
+	PauseExperiment(ctx context.Context, id uint, userID uint, req *dto.PauseExperimentRequest) (*model.Experiment, error)
+	ResumeExperiment(ctx context.Context, id uint, userID uint, req *dto.ResumeExperimentRequest) (*model.Experiment, error)
+	UpdateExperimentVariants(ctx context.Context, id uint, userID uint, req *dto.UpdateExperimentVariantsRequest) (*model.Experiment, error)
+	DeleteExperiment(ctx context.Context, id uint, userID uint) error
+	SetExperimentOverride(ctx context.Context, id uint, req *dto.SetExperimentOverrideRequest) (*model.ExperimentOverride, error)
+	DeleteExperimentOverride(ctx context.Context, id uint, overrideID uint) error
+	SimulateExperiment(ctx context.Context, req *dto.SimulateExperimentRequest) (dto.SimulateExperimentResponse, error)
+	CheckExperimentConflicts(ctx context.Context, req *dto.CheckExperimentConflictsRequest) (dto.ExperimentConflictsResponse, error)
+	GetExperimentExposures(ctx context.Context, id uint, filter model.ExposureFilter) (dto.ExperimentExposureResponse, error)
+	GetExperimentExposuresByUuid(ctx context.Context, experimentUUID string, filter model.ExposureFilter) (dto.ExperimentExposureResponse, error)
 	SimulateParameter(ctx context.Context, req *dto.SimulateParameterRequest) (dto.SimulateParameterResponse, error)
-	GetActiveExperimentsSDK(ctx context.Context) ([]types.Experiment, error)
+	GetActiveExperimentsSDK(ctx context.Context, environmentKey string) ([]types.Experiment, error)
+	RebuildRawValues(ctx context.Context) (*dto.RebuildRawValuesResponse, error)
+
+	// Audit log operations
+	GetAuditLogs(ctx context.Context, filter model.AuditLogFilter, limit, offset int) ([]*model.AuditLog, int64, error)
+
+	// API key operations
+	CreateAPIKey(ctx context.Context, userID uint, req *dto.CreateAPIKeyRequest) (*model.APIKey, string, error)
+	GetAllAPIKeys(ctx context.Context) ([]*model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint) (*model.APIKey, error)
+	ValidateAPIKey(ctx context.Context, key string) error
+
+	// Admin operations
+	ExportPointInTime(ctx context.Context, at time.Time, w io.Writer) error
 
 	// Auth operations
 	GetGoogleOAuthConfig(cfg *config.Config) *oauth2.Config
@@ -86,28 +141,45 @@ type Service interface {
 	TrackBatchEvent(ctx context.Context, req *dto.TrackBatchEventRequest) (*dto.TrackBatchEventResponse, error)
 }
 
+// jobInserter enqueues river jobs and reads their queues; it is satisfied by *river.Client[*sql.Tx]
+type jobInserter interface {
+	Insert(ctx context.Context, args river.JobArgs, opts *river.InsertOpts) (*rivertype.JobInsertResult, error)
+	InsertTx(ctx context.Context, tx *sql.Tx, args river.JobArgs, opts *river.InsertOpts) (*rivertype.JobInsertResult, error)
+	QueueGet(ctx context.Context, name string) (*rivertype.Queue, error)
+}
+
 // service implements Service
 type service struct {
 	repo         repository.Repository
-	riverClient  *river.Client[pgx.Tx]
+	riverClient  jobInserter
 	auroraClient sdk.Client
 	solver       solver.Solver
 	eventService *EventService
+	cfg          *config.Config
+	apiKeys      apiKeyCache
+	// s3Client is nil when parameters are not synced to S3
+	s3Client bucketHeader
 }
 
 // New creates a new service
-func New(repo repository.Repository, riverClient *river.Client[pgx.Tx], auroraClient sdk.Client, solver solver.Solver) Service {
+func New(repo repository.Repository, riverClient *river.Client[*sql.Tx], auroraClient sdk.Client, solver solver.Solver, s3Client *s3.Client, cfg *config.Config) Service {
 	// Create event service
 	eventRepo := repository.NewEventRepository(repo.GetDB())
 	eventService := NewEventService(eventRepo, log.Logger)
 
-	return &service{
+	s := &service{
 		repo:         repo,
 		riverClient:  riverClient,
 		auroraClient: auroraClient,
 		solver:       solver,
 		eventService: eventService,
+		cfg:          cfg,
+	}
+	// The S3 client is nil when S3 is disabled, and must not become a non-nil interface
+	if s3Client != nil {
+		s.s3Client = s3Client
 	}
+	return s
 }
 
 // TrackEvent tracks an evaluation event
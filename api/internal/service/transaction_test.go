@@ -0,0 +1,405 @@
+package service
+
+import (
+	"api/internal/constant"
+	"api/internal/dto"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordingConnector is a database/sql driver that keeps executed statements per transaction
+// and only moves them to committed once the commit succeeds
+type recordingConnector struct {
+	mu        sync.Mutex
+	committed []string
+	// committedArgs holds the arguments of each committed statement
+	committedArgs [][]driver.Value
+	commitErr     error
+	// fail, when set, is called with every statement and its arguments and fails it by returning an error
+	fail func(query string, args []driver.Value) error
+	// rows, when set, answers SELECT queries and raw statements returning rows with columns and rows;
+	// other SELECT queries find nothing
+	rows func(query string, args []driver.Value) ([]string, [][]driver.Value)
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{connector: c}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver { return nil }
+
+type recordingConn struct {
+	connector   *recordingConnector
+	pending     []string
+	pendingArgs [][]driver.Value
+	inTx        bool
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{conn: c, query: query}, nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	return &recordingTx{conn: c}, nil
+}
+
+type recordingTx struct {
+	conn *recordingConn
+}
+
+func (t *recordingTx) Commit() error {
+	pending, pendingArgs := t.conn.pending, t.conn.pendingArgs
+	t.conn.pending, t.conn.pendingArgs, t.conn.inTx = nil, nil, false
+	connector := t.conn.connector
+	connector.mu.Lock()
+	defer connector.mu.Unlock()
+	if connector.commitErr != nil {
+		return connector.commitErr
+	}
+	connector.committed = append(connector.committed, pending...)
+	connector.committedArgs = append(connector.committedArgs, pendingArgs...)
+	return nil
+}
+
+func (t *recordingTx) Rollback() error {
+	t.conn.pending, t.conn.pendingArgs, t.conn.inTx = nil, nil, false
+	return nil
+}
+
+type recordingStmt struct {
+	conn  *recordingConn
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.record(args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+// Query supports the INSERT ... RETURNING statements GORM creates rows with, returning a new ID,
+// and SELECT queries answered by the rows hook of the connector. Other statements returning rows,
+// such as raw inserts, are recorded and answered by the rows hook when it is set.
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	answer := s.conn.connector.rows
+	if strings.HasPrefix(s.query, "SELECT") {
+		rows := &selectedRows{}
+		if answer != nil {
+			rows.columns, rows.values = answer(s.query, args)
+		}
+		return rows, nil
+	}
+	_, returning, ok := strings.Cut(s.query, " RETURNING ")
+	if !strings.HasPrefix(s.query, "INSERT") || !ok {
+		if answer == nil {
+			return nil, errors.New("only INSERT ... RETURNING queries are supported")
+		}
+		if err := s.record(args); err != nil {
+			return nil, err
+		}
+		rows := &selectedRows{}
+		rows.columns, rows.values = answer(s.query, args)
+		return rows, nil
+	}
+	if err := s.record(args); err != nil {
+		return nil, err
+	}
+	columns := strings.Split(strings.ReplaceAll(returning, `"`, ""), ",")
+	return &insertedRows{columns: columns, id: int64(len(s.conn.pending))}, nil
+}
+
+// record keeps the statement with the pending statements of the transaction, or as committed outside of one
+func (s *recordingStmt) record(args []driver.Value) error {
+	if fail := s.conn.connector.fail; fail != nil {
+		if err := fail(s.query, args); err != nil {
+			return err
+		}
+	}
+	if s.conn.inTx {
+		s.conn.pending = append(s.conn.pending, s.query)
+		s.conn.pendingArgs = append(s.conn.pendingArgs, args)
+		return nil
+	}
+	s.conn.connector.mu.Lock()
+	s.conn.connector.committed = append(s.conn.connector.committed, s.query)
+	s.conn.connector.committedArgs = append(s.conn.connector.committedArgs, args)
+	s.conn.connector.mu.Unlock()
+	return nil
+}
+
+// inserted returns the committed rows inserted into table, by column
+func (c *recordingConnector) inserted(table string) []map[string]driver.Value {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var rows []map[string]driver.Value
+	for i, query := range c.committed {
+		rows = append(rows, insertValues(table, query, c.committedArgs[i])...)
+	}
+	return rows
+}
+
+// enqueuedJobs returns the kinds of the committed jobs
+func (c *recordingConnector) enqueuedJobs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var kinds []string
+	for i, query := range c.committed {
+		if strings.HasPrefix(query, "INSERT INTO river_job") {
+			kinds = append(kinds, fmt.Sprint(c.committedArgs[i][0]))
+		}
+	}
+	return kinds
+}
+
+// insertValues returns the rows a GORM INSERT into table writes, by column, or nil for other statements.
+// Values GORM writes as SQL literals, such as (NULL), are returned as nil.
+func insertValues(table, query string, args []driver.Value) []map[string]driver.Value {
+	rest, ok := strings.CutPrefix(query, `INSERT INTO "`+table+`" (`)
+	if !ok {
+		return nil
+	}
+	columnList, values, _ := strings.Cut(rest, ") VALUES ")
+	columns := strings.Split(strings.ReplaceAll(columnList, `"`, ""), ",")
+
+	var rows []map[string]driver.Value
+	var row map[string]driver.Value
+	var token strings.Builder
+	addValue := func() {
+		var value driver.Value
+		if n, err := strconv.Atoi(strings.TrimPrefix(token.String(), "$")); err == nil && n >= 1 && n <= len(args) {
+			value = args[n-1]
+		}
+		if len(row) < len(columns) {
+			row[columns[len(row)]] = value
+		}
+		token.Reset()
+	}
+	depth := 0
+	for _, r := range values {
+		switch {
+		case depth == 0 && r == '(':
+			row = make(map[string]driver.Value, len(columns))
+		case depth == 0 && r != ',' && r != ' ':
+			// The rows are followed by ON CONFLICT or RETURNING clauses
+			return rows
+		case depth == 1 && r == ')':
+			addValue()
+			rows = append(rows, row)
+		case depth == 1 && r == ',':
+			addValue()
+		case depth > 0:
+			token.WriteRune(r)
+		}
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	return rows
+}
+
+// insertedRows is the single row returned by an INSERT ... RETURNING, holding the ID of the new row
+type insertedRows struct {
+	columns []string
+	id      int64
+	done    bool
+}
+
+func (r *insertedRows) Columns() []string { return r.columns }
+func (r *insertedRows) Close() error      { return nil }
+
+func (r *insertedRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	for i, column := range r.columns {
+		if column == "id" {
+			dest[i] = r.id
+		}
+	}
+	return nil
+}
+
+// migratedEnumValues returns the values the migrations give to the postgres enum type enumType
+func migratedEnumValues(t *testing.T, enumType string) []string {
+	files, err := filepath.Glob("../../migrations/*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	created := regexp.MustCompile(`(?is)create type ` + enumType + ` as enum \((.*?)\)`)
+	added := regexp.MustCompile(`(?i)alter type ` + enumType + ` add value (?:if not exists )?'([^']+)'`)
+	quoted := regexp.MustCompile(`'([^']+)'`)
+	var values []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		for _, match := range created.FindAllStringSubmatch(string(content), -1) {
+			for _, value := range quoted.FindAllStringSubmatch(match[1], -1) {
+				values = append(values, value[1])
+			}
+		}
+		for _, match := range added.FindAllStringSubmatch(string(content), -1) {
+			values = append(values, match[1])
+		}
+	}
+	return values
+}
+
+// enumColumnCheck fails inserts into table whose value for column is outside of allowed, as postgres
+// does for a column of an enum type
+func enumColumnCheck(table, column string, allowed []string) func(query string, args []driver.Value) error {
+	return func(query string, args []driver.Value) error {
+		for _, row := range insertValues(table, query, args) {
+			if value, ok := row[column]; ok && !slices.Contains(allowed, fmt.Sprint(value)) {
+				return fmt.Errorf("invalid input value for enum: %q", value)
+			}
+		}
+		return nil
+	}
+}
+
+// selectedRows are the rows answering a SELECT query
+type selectedRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *selectedRows) Columns() []string { return r.columns }
+func (r *selectedRows) Close() error      { return nil }
+
+func (r *selectedRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// txJobInserter writes job rows through the given transaction, as river's InsertTx does
+type txJobInserter struct{}
+
+func (txJobInserter) Insert(context.Context, river.JobArgs, *river.InsertOpts) (*rivertype.JobInsertResult, error) {
+	return nil, errors.New("jobs must be inserted within a transaction")
+}
+
+func (txJobInserter) InsertTx(ctx context.Context, tx *sql.Tx, args river.JobArgs, _ *river.InsertOpts) (*rivertype.JobInsertResult, error) {
+	if _, err := tx.ExecContext(ctx, "INSERT INTO river_job (kind) VALUES ($1)", args.Kind()); err != nil {
+		return nil, err
+	}
+	return &rivertype.JobInsertResult{}, nil
+}
+
+func (txJobInserter) QueueGet(context.Context, string) (*rivertype.Queue, error) {
+	return &rivertype.Queue{}, nil
+}
+
+func newRecordingService(t *testing.T, connector *recordingConnector) *service {
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(connector)}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	require.NoError(t, err)
+	return &service{repo: repository.New(db), riverClient: txJobInserter{}}
+}
+
+func updateParameterAndEnqueueSync(ctx context.Context, s *service) error {
+	return s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		if err := txRepo.GetDB().Exec("UPDATE parameters SET updated_at = now() WHERE id = ?", 1).Error; err != nil {
+			return err
+		}
+		return s.enqueueTx(ctx, txRepo, dto.SyncParameterArgs{ParameterID: 1})
+	})
+}
+
+func TestEnqueueTxCommitsJobWithDataChange(t *testing.T) {
+	connector := &recordingConnector{}
+	s := newRecordingService(t, connector)
+
+	require.NoError(t, updateParameterAndEnqueueSync(context.Background(), s))
+	require.Equal(t, []string{
+		"UPDATE parameters SET updated_at = now() WHERE id = $1",
+		"INSERT INTO river_job (kind) VALUES ($1)",
+	}, connector.committed)
+}
+
+func TestEnqueueTxLeavesNoJobWhenCommitFails(t *testing.T) {
+	commitErr := errors.New("connection reset during commit")
+	connector := &recordingConnector{commitErr: commitErr}
+	s := newRecordingService(t, connector)
+
+	err := updateParameterAndEnqueueSync(context.Background(), s)
+	require.ErrorIs(t, err, commitErr)
+	require.Empty(t, connector.committed)
+}
+
+func TestEnqueueTxRequiresTransaction(t *testing.T) {
+	connector := &recordingConnector{}
+	s := newRecordingService(t, connector)
+
+	err := s.enqueueTx(context.Background(), s.repo, dto.SyncParameterArgs{ParameterID: 1})
+	require.Error(t, err)
+	require.Empty(t, connector.committed)
+}
+
+func TestCreateExperimentVariantsTxRollsBackExperiment(t *testing.T) {
+	variantInserts := 0
+	insertErr := errors.New("variant insert failed")
+	connector := &recordingConnector{fail: func(query string, _ []driver.Value) error {
+		if !strings.HasPrefix(query, `INSERT INTO "experiment_variants"`) {
+			return nil
+		}
+		if variantInserts++; variantInserts > 1 {
+			return insertErr
+		}
+		return nil
+	}}
+	s := newRecordingService(t, connector)
+	ctx := context.Background()
+
+	err := s.inTransaction(ctx, func(txRepo repository.Repository) error {
+		experiment := &model.Experiment{Name: "checkout button", Status: constant.ExperimentStatusDraft}
+		if err := txRepo.CreateExperiment(ctx, experiment); err != nil {
+			return err
+		}
+		return createExperimentVariantsTx(ctx, txRepo, experiment.ID, []dto.CreateExperimentVariantRequest{
+			{Name: "control", TrafficAllocation: 50},
+			{Name: "treatment", TrafficAllocation: 50},
+		}, 1700000000)
+	})
+
+	require.ErrorIs(t, err, insertErr)
+	require.Equal(t, 2, variantInserts)
+	// Neither the experiment nor the first variant outlives the failed transaction
+	require.Empty(t, connector.committed)
+}
@@ -0,0 +1,116 @@
+package workers
+
+import (
+	"api/config"
+	"api/internal/dto"
+	"api/internal/model"
+	"api/internal/repository"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// AdvanceExperimentStatusWorker starts scheduled experiments and finishes running ones once their
+// dates have passed, so SDK clients stop serving experiments past their end date without anyone
+// opening them
+type AdvanceExperimentStatusWorker struct {
+	river.WorkerDefaults[dto.AdvanceExperimentStatusArgs]
+	Repository repository.Repository
+	Cfg        config.Config
+}
+
+func (w *AdvanceExperimentStatusWorker) Work(ctx context.Context, job *river.Job[dto.AdvanceExperimentStatusArgs]) error {
+	logger := log.Ctx(ctx).With().Str("worker", "advance-experiment-status").Logger()
+
+	now := time.Now().Unix()
+	var advanced int
+	err := w.Repository.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.New(tx)
+		experiments, err := txRepo.LockExperimentsDueForStatusChange(ctx, now, w.Cfg.Experiment.FinishPausedOnEnd)
+		if err != nil {
+			return fmt.Errorf("failed to get experiments due for a status change: %w", err)
+		}
+
+		advanced = len(experiments)
+		if advanced == 0 {
+			return nil
+		}
+
+		// The sync and webhook jobs are committed with the status changes, so the SDK payload and
+		// notifications never miss them
+		sqlTx, ok := tx.Statement.ConnPool.(*sql.Tx)
+		if !ok {
+			return fmt.Errorf("failed to enqueue sync experiment job: not in a transaction")
+		}
+		client := river.ClientFromContext[*sql.Tx](ctx)
+		for _, experiment := range experiments {
+			notification, err := w.advance(ctx, txRepo, experiment, now)
+			if err != nil {
+				return err
+			}
+			if notification == nil {
+				continue
+			}
+			if _, err := client.InsertTx(ctx, sqlTx, *notification, nil); err != nil {
+				return fmt.Errorf("failed to enqueue webhook job of experiment %d: %w", experiment.ID, err)
+			}
+		}
+		if _, err := client.InsertTx(ctx, sqlTx, dto.SyncExperimentArgs{}, nil); err != nil {
+			return fmt.Errorf("failed to enqueue sync experiment job: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to advance experiment statuses")
+		return err
+	}
+
+	if advanced > 0 {
+		logger.Info().Int("experiments_count", advanced).Msg("Advanced experiment statuses")
+	}
+	return nil
+}
+
+// advance moves a locked experiment to the status its dates call for and records the transition at
+// the date it took effect. It returns the webhook notification of the change, or nil when the
+// experiment keeps its status or the webhook is not configured for the new one.
+func (w *AdvanceExperimentStatusWorker) advance(ctx context.Context, txRepo repository.Repository, experiment *model.Experiment, now int64) (*dto.ExperimentStatusWebhookArgs, error) {
+	status, effectiveAt, ok := experiment.DueStatus(now, w.Cfg.Experiment.FinishPausedOnEnd)
+	if !ok {
+		return nil, nil
+	}
+
+	transition := &model.ExperimentStatusTransition{
+		ExperimentID: experiment.ID,
+		FromStatus:   experiment.Status,
+		ToStatus:     status,
+		CreatedAt:    effectiveAt,
+	}
+	experiment.Status = status
+	experiment.UpdatedAt = now
+	if err := txRepo.UpdateExperiment(ctx, experiment); err != nil {
+		return nil, fmt.Errorf("failed to update experiment %d: %w", experiment.ID, err)
+	}
+	if err := txRepo.CreateExperimentStatusTransition(ctx, transition); err != nil {
+		return nil, fmt.Errorf("failed to record status transition of experiment %d: %w", experiment.ID, err)
+	}
+	if err := txRepo.UpdateExperimentRawValue(ctx, uint(experiment.ID)); err != nil {
+		return nil, fmt.Errorf("failed to update raw value of experiment %d: %w", experiment.ID, err)
+	}
+
+	if !w.Cfg.NotifiesExperimentStatus(status) {
+		return nil, nil
+	}
+	return &dto.ExperimentStatusWebhookArgs{
+		ExperimentID:   experiment.ID,
+		ExperimentName: experiment.Name,
+		FromStatus:     transition.FromStatus,
+		ToStatus:       status,
+		Timestamp:      effectiveAt,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package workers
+
+import (
+	"api/config"
+	"api/internal/dto"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog/log"
+)
+
+// webhookTimeout bounds a single webhook delivery, which is retried when it fails
+const webhookTimeout = 10 * time.Second
+
+// ExperimentStatusWebhookWorker posts experiment status changes to the configured webhook. Deliveries
+// answered with a non-2xx status fail the job, so that river retries them.
+type ExperimentStatusWebhookWorker struct {
+	river.WorkerDefaults[dto.ExperimentStatusWebhookArgs]
+	Cfg    config.Config
+	Client *http.Client
+}
+
+func (w *ExperimentStatusWebhookWorker) Work(ctx context.Context, job *river.Job[dto.ExperimentStatusWebhookArgs]) error {
+	logger := log.Ctx(ctx).With().Str("worker", "experiment-status-webhook").Int("experimentId", job.Args.ExperimentID).Logger()
+
+	// The webhook may have been removed since the job was enqueued
+	if w.Cfg.Webhook.URL == "" {
+		logger.Info().Msg("Webhook is not configured, skipping notification")
+		return nil
+	}
+
+	body, err := json.Marshal(job.Args)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.Cfg.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error().Err(err).Int("attempt", job.Attempt).Msg("Failed to deliver webhook")
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		logger.Error().Int("status", resp.StatusCode).Int("attempt", job.Attempt).Msg("Webhook rejected notification")
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	logger.Info().Str("toStatus", job.Args.ToStatus).Msg("Delivered experiment status webhook")
+	return nil
+}
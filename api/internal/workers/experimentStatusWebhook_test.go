@@ -0,0 +1,40 @@
+package workers
+
+import (
+	"api/config"
+	"api/internal/dto"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperimentStatusWebhookWorkerPostsPayload(t *testing.T) {
+	var received dto.ExperimentStatusWebhookArgs
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	var cfg config.Config
+	cfg.Webhook.URL = server.URL
+	worker := &ExperimentStatusWebhookWorker{Cfg: cfg, Client: server.Client()}
+	args := dto.ExperimentStatusWebhookArgs{ExperimentID: 3, ExperimentName: "checkout", FromStatus: "running", ToStatus: "finish", Timestamp: 1700000000}
+	job := &river.Job[dto.ExperimentStatusWebhookArgs]{JobRow: &rivertype.JobRow{Attempt: 1}, Args: args}
+
+	require.NoError(t, worker.Work(context.Background(), job))
+	require.Equal(t, args, received)
+
+	// Rejected deliveries fail the job so that it is retried
+	status = http.StatusBadGateway
+	require.ErrorContains(t, worker.Work(context.Background(), job), "webhook responded with status 502")
+}
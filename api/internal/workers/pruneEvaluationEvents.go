@@ -0,0 +1,44 @@
+package workers
+
+import (
+	"api/config"
+	"api/internal/dto"
+	"api/internal/repository"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river"
+	"github.com/rs/zerolog/log"
+)
+
+// pruneEvaluationEventsBatchSize is how many events are deleted per statement
+const pruneEvaluationEventsBatchSize = 10000
+
+// PruneEvaluationEventsWorker deletes the evaluation events that are older than the retention window,
+// so the events table does not grow without bound
+type PruneEvaluationEventsWorker struct {
+	river.WorkerDefaults[dto.PruneEvaluationEventsArgs]
+	Repository repository.Repository
+	Cfg        config.Config
+}
+
+func (w *PruneEvaluationEventsWorker) Work(ctx context.Context, job *river.Job[dto.PruneEvaluationEventsArgs]) error {
+	logger := log.Ctx(ctx).With().Str("worker", "prune-evaluation-events").Logger()
+
+	retentionDays := w.Cfg.Events.RetentionDays
+	if retentionDays <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	deleted, err := repository.NewEventRepository(w.Repository.GetDB()).DeleteEventsBefore(ctx, cutoff, pruneEvaluationEventsBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to prune evaluation events before %s: %w", cutoff.Format(time.RFC3339), err)
+	}
+
+	if deleted > 0 {
+		logger.Info().Int64("deleted", deleted).Time("cutoff", cutoff).Msg("pruned evaluation events")
+	}
+	return nil
+}
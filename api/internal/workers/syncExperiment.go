@@ -2,6 +2,7 @@ package workers
 
 import (
 	"api/config"
+	"api/internal/broadcast"
 	"api/internal/dto"
 	"api/internal/mapper"
 	"api/internal/repository"
@@ -20,6 +21,7 @@ type SyncExperimentWorker struct {
 	Repository repository.Repository
 	Cfg        config.Config
 	S3         *s3.Client
+	Publisher  broadcast.Publisher
 }
 
 func (w *SyncExperimentWorker) Work(ctx context.Context, job *river.Job[dto.SyncExperimentArgs]) error {
@@ -31,6 +33,11 @@ func (w *SyncExperimentWorker) Work(ctx context.Context, job *river.Job[dto.Sync
 		return err
 	}
 
+	// The data is synced, so streaming SDK clients can fetch it now; they still poll if the event is lost
+	if err := w.Publisher.Publish(ctx, dto.SDKChangeEvent{Entity: dto.SDKChangeEntityExperiment, ID: job.Args.ExperimentID}); err != nil {
+		logger.Error().Err(err).Msg("Failed to publish SDK change event")
+	}
+
 	return nil
 }
 
@@ -50,11 +57,7 @@ func (w *SyncExperimentWorker) ProcessSyncExperiment(ctx context.Context) error
 	}
 
 	// Convert to SDK format using mapper
-	sdkExperiments, err := mapper.ExperimentsToSDKFromRawValue(experiments)
-	if err != nil {
-		logger.Error().Err(err).Msg("Failed to map experiments to SDK")
-		return err
-	}
+	sdkExperiments := mapper.ExperimentsToSDKFromRawValue(ctx, experiments)
 
 	logger.Info().Int("experiments_count", len(sdkExperiments)).Msg("Found experiments to sync")
 	if len(sdkExperiments) > 0 {
@@ -2,6 +2,7 @@ package workers
 
 import (
 	"api/config"
+	"api/internal/broadcast"
 	"api/internal/dto"
 	"api/internal/mapper"
 	"api/internal/repository"
@@ -20,6 +21,7 @@ type SyncParameterWorker struct {
 	Repository repository.Repository
 	Cfg        config.Config
 	S3         *s3.Client
+	Publisher  broadcast.Publisher
 }
 
 func (w *SyncParameterWorker) Work(ctx context.Context, job *river.Job[dto.SyncParameterArgs]) error {
@@ -31,6 +33,11 @@ func (w *SyncParameterWorker) Work(ctx context.Context, job *river.Job[dto.SyncP
 		return err
 	}
 
+	// The data is synced, so streaming SDK clients can fetch it now; they still poll if the event is lost
+	if err := w.Publisher.Publish(ctx, dto.SDKChangeEvent{Entity: dto.SDKChangeEntityParameter, ID: job.Args.ParameterID}); err != nil {
+		logger.Error().Err(err).Msg("Failed to publish SDK change event")
+	}
+
 	return nil
 }
 
@@ -57,7 +64,9 @@ func (w *SyncParameterWorker) ProcessSyncParameter(ctx context.Context) error {
 	}
 
 	logger.Info().Int("parameters_count", len(sdkParameters)).Msg("Found parameters to sync")
-	logger.Debug().Interface("parameters", sdkParameters[0]).Msg("Parameters to sync")
+	if len(sdkParameters) > 0 {
+		logger.Debug().Interface("parameters", sdkParameters[0]).Msg("Parameters to sync")
+	}
 	jsonParameters, err := json.Marshal(sdkParameters)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to marshal parameters")
@@ -0,0 +1 @@
+alter table attributes drop column alias_of_id;
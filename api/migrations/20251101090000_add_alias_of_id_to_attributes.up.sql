@@ -0,0 +1 @@
+alter table attributes add column alias_of_id integer references attributes(id) on delete set null;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS experiment_status_transitions;
+DROP TABLE IF EXISTS parameter_versions;
@@ -0,0 +1,20 @@
+-- Snapshots of parameter raw_value taken on every configuration change
+CREATE TABLE parameter_versions (
+    id SERIAL PRIMARY KEY,
+    parameter_id INTEGER NOT NULL,
+    raw_value JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_parameter_versions_parameter_id_created_at ON parameter_versions(parameter_id, created_at);
+
+-- Log of experiment status transitions
+CREATE TABLE experiment_status_transitions (
+    id SERIAL PRIMARY KEY,
+    experiment_id INTEGER NOT NULL,
+    from_status VARCHAR(50) NOT NULL DEFAULT '',
+    to_status VARCHAR(50) NOT NULL,
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_experiment_status_transitions_experiment_id_created_at ON experiment_status_transitions(experiment_id, created_at);
@@ -0,0 +1 @@
+-- postgres cannot drop a value from an enum type; 'between' and 'not_between' are left in condition_operator
@@ -0,0 +1,2 @@
+alter type condition_operator add value if not exists 'between';
+alter type condition_operator add value if not exists 'not_between';
@@ -0,0 +1,2 @@
+alter table parameters drop column enum_options;
+-- postgres cannot drop a value from an enum type; 'enum' is left in parameter_data_type
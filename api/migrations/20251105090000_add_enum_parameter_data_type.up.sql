@@ -0,0 +1,2 @@
+alter type parameter_data_type add value if not exists 'enum';
+alter table parameters add column enum_options text[] default '{}';
@@ -0,0 +1 @@
+-- postgres cannot drop a value from an enum type; 'matches_regex' and 'not_matches_regex' are left in condition_operator
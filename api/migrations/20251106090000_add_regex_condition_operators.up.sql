@@ -0,0 +1,2 @@
+alter type condition_operator add value if not exists 'matches_regex';
+alter type condition_operator add value if not exists 'not_matches_regex';
@@ -0,0 +1 @@
+alter table parameter_change_requests drop column cancellation_reason;
@@ -0,0 +1 @@
+alter table parameter_change_requests add column cancellation_reason text;
@@ -0,0 +1,14 @@
+-- Record of who changed parameters, experiments and change requests
+CREATE TABLE audit_logs (
+    id SERIAL PRIMARY KEY,
+    actor_user_id INTEGER NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_logs_entity_type_entity_id_created_at ON audit_logs(entity_type, entity_id, created_at);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at);
@@ -0,0 +1 @@
+-- postgres cannot drop a value from an enum type; 'json' is left in parameter_data_type
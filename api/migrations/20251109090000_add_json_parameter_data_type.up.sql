@@ -0,0 +1 @@
+alter type parameter_data_type add value if not exists 'json';
@@ -0,0 +1 @@
+alter table parameter_rules drop column condition_logic;
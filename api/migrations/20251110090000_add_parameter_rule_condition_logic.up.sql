@@ -0,0 +1 @@
+alter table parameter_rules add column condition_logic varchar(10) not null default 'and';
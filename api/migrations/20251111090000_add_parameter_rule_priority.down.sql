@@ -0,0 +1,2 @@
+drop index if exists idx_parameter_rules_parameter_id_priority;
+alter table parameter_rules drop column priority;
@@ -0,0 +1,11 @@
+alter table parameter_rules add column priority integer not null default 0;
+
+update parameter_rules
+set priority = ranked.priority
+from (
+    select id, row_number() over (partition by parameter_id order by id) - 1 as priority
+    from parameter_rules
+) ranked
+where parameter_rules.id = ranked.id;
+
+create index idx_parameter_rules_parameter_id_priority on parameter_rules (parameter_id, priority);
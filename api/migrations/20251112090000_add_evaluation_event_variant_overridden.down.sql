@@ -0,0 +1 @@
+alter table evaluation_events drop column variant_overridden;
@@ -0,0 +1 @@
+alter table evaluation_events add column variant_overridden boolean not null default false;
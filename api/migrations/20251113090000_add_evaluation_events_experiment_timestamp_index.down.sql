@@ -0,0 +1 @@
+drop index if exists idx_evaluation_events_experiment_id_timestamp;
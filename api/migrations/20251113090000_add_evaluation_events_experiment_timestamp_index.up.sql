@@ -0,0 +1 @@
+create index idx_evaluation_events_experiment_id_timestamp on evaluation_events (experiment_id, timestamp);
@@ -0,0 +1,14 @@
+-- Keys authenticating SDK clients; only a sha256 hash of each key is stored
+create table api_keys (
+    id serial primary key,
+    name varchar(255) not null,
+    prefix varchar(32) not null,
+    key_hash varchar(64) not null,
+    created_by_user_id integer not null references users(id),
+    expires_at timestamp with time zone,
+    revoked_at timestamp with time zone,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now()
+);
+
+create unique index idx_api_keys_key_hash on api_keys (key_hash);
@@ -0,0 +1 @@
+alter table parameters drop column if exists archived_at;
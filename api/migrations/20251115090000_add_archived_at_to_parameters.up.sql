@@ -0,0 +1,2 @@
+-- Archived parameters are kept for their history but no longer served to SDK clients
+alter table parameters add column archived_at timestamp with time zone;
@@ -0,0 +1,2 @@
+alter table parameter_rules drop column if exists hash_attribute_name;
+alter table parameter_rules drop column if exists rollout_percentage;
@@ -0,0 +1,3 @@
+-- A matching rule only applies to the given percentage of users, bucketed by hashing hash_attribute_name
+alter table parameter_rules add column rollout_percentage integer;
+alter table parameter_rules add column hash_attribute_name varchar(255);
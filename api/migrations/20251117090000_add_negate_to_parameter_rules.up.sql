@@ -0,0 +1,2 @@
+-- A negated rule applies to the users its conditions or segment match type do not match
+alter table parameter_rules add column negate boolean not null default false;
@@ -0,0 +1,2 @@
+drop index if exists idx_parameters_deleted_at;
+alter table parameters drop column if exists deleted_at;
@@ -0,0 +1,3 @@
+-- Deleted parameters are kept, with their rules and name, so they can be restored
+alter table parameters add column deleted_at timestamp;
+create index idx_parameters_deleted_at on parameters (deleted_at);
@@ -0,0 +1,5 @@
+alter table experiments drop column if exists environment_id;
+drop index if exists idx_parameter_rules_environment_override_id;
+alter table parameter_rules drop column if exists environment_override_id;
+drop table if exists parameter_environment_overrides;
+drop table if exists environments;
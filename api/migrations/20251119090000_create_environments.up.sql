@@ -0,0 +1,32 @@
+-- Deployment environments, each able to override the rollout data of parameters and to scope experiments
+create table environments (
+    id serial primary key,
+    name varchar(255) not null,
+    key varchar(64) not null,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now()
+);
+
+create unique index idx_environments_key on environments (key);
+
+-- A null default_rollout_value, or override_rules left false, falls back to the base definition of the parameter
+create table parameter_environment_overrides (
+    id serial primary key,
+    parameter_id integer not null references parameters(id) on delete cascade,
+    environment_id integer not null references environments(id) on delete cascade,
+    default_rollout_value jsonb,
+    override_rules boolean not null default false,
+    raw_value jsonb,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now()
+);
+
+create unique index idx_parameter_environment_overrides_parameter_environment on parameter_environment_overrides (parameter_id, environment_id);
+create index idx_parameter_environment_overrides_environment_id on parameter_environment_overrides (environment_id);
+
+-- Rules of an override replace the base rules, which keep a null environment_override_id, in its environment
+alter table parameter_rules add column environment_override_id integer references parameter_environment_overrides(id) on delete cascade;
+create index idx_parameter_rules_environment_override_id on parameter_rules (environment_override_id);
+
+-- Experiments without an environment run in every environment
+alter table experiments add column environment_id integer references environments(id);
@@ -0,0 +1 @@
+alter table parameters drop column if exists number_constraints;
@@ -0,0 +1,2 @@
+-- Optional integer-only and min/max constraints of number parameters
+alter table parameters add column number_constraints jsonb;
@@ -0,0 +1,10 @@
+-- Idempotency keys of creation requests, scoped per endpoint, mapped to the resource they created
+create table idempotency_keys (
+    id serial primary key,
+    scope varchar(64) not null,
+    key varchar(255) not null,
+    resource_id integer not null,
+    created_at timestamp with time zone not null default now()
+);
+
+create unique index idx_idempotency_keys_scope_key on idempotency_keys (scope, key);
@@ -0,0 +1,4 @@
+alter table experiments drop column if exists updated_by_user_id;
+alter table experiments drop column if exists created_by_user_id;
+alter table parameters drop column if exists updated_by_user_id;
+alter table parameters drop column if exists created_by_user_id;
@@ -0,0 +1,5 @@
+-- Users who created and last modified each parameter and experiment; null for rows written before auditing
+alter table parameters add column created_by_user_id integer;
+alter table parameters add column updated_by_user_id integer;
+alter table experiments add column created_by_user_id integer;
+alter table experiments add column updated_by_user_id integer;
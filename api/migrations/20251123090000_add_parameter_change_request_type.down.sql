@@ -0,0 +1,3 @@
+delete from parameter_change_requests where parameter_id is null;
+alter table parameter_change_requests alter column parameter_id set not null;
+alter table parameter_change_requests drop column if exists request_type;
@@ -0,0 +1,4 @@
+-- Change requests can create and delete parameters as well as update them; create requests have no
+-- parameter until they are approved
+alter table parameter_change_requests add column request_type varchar(16) not null default 'update';
+alter table parameter_change_requests alter column parameter_id drop not null;
@@ -0,0 +1 @@
+-- postgres cannot drop a value from an enum type; the semver operators are left in condition_operator
@@ -0,0 +1,5 @@
+alter type condition_operator add value if not exists 'semver_gt';
+alter type condition_operator add value if not exists 'semver_lt';
+alter type condition_operator add value if not exists 'semver_gte';
+alter type condition_operator add value if not exists 'semver_lte';
+alter type condition_operator add value if not exists 'semver_eq';
@@ -0,0 +1,10 @@
+-- Overrides force units, identified by the value of the experiment's hash attribute, into a variant
+create table experiment_overrides (
+    id serial primary key,
+    experiment_id integer not null references experiments(id) on delete cascade,
+    attribute_value varchar(255) not null,
+    variant_id integer not null references experiment_variants(id) on delete cascade,
+    created_at timestamp with time zone not null default now()
+);
+
+create unique index idx_experiment_overrides_experiment_attribute_value on experiment_overrides (experiment_id, attribute_value);
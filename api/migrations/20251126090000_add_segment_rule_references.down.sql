@@ -0,0 +1,3 @@
+drop index if exists idx_segment_rules_referenced_segment_id;
+alter table segment_rules drop column reference_type;
+alter table segment_rules drop column referenced_segment_id;
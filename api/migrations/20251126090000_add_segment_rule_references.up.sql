@@ -0,0 +1,5 @@
+-- A segment rule either holds conditions or references another segment whose users it includes or excludes
+alter table segment_rules add column referenced_segment_id integer references segments(id) on delete restrict;
+alter table segment_rules add column reference_type varchar(16);
+
+create index idx_segment_rules_referenced_segment_id on segment_rules (referenced_segment_id);
@@ -0,0 +1 @@
+-- postgres cannot drop a value from an enum type; 'is_set' and 'is_not_set' are left in condition_operator
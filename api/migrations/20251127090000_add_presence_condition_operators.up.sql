@@ -0,0 +1,2 @@
+alter type condition_operator add value if not exists 'is_set';
+alter type condition_operator add value if not exists 'is_not_set';
@@ -0,0 +1 @@
+-- postgres cannot drop a value from an enum type; 'date' is left in data_type
@@ -0,0 +1 @@
+alter type data_type add value if not exists 'date';
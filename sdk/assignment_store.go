@@ -0,0 +1,39 @@
+package sdk
+
+import (
+	"context"
+	"sdk/types"
+	"sync"
+)
+
+// memoryAssignmentStore keeps experiment assignments in memory, by experiment UUID then unit ID
+type memoryAssignmentStore struct {
+	mu          sync.RWMutex
+	assignments map[string]map[string]int
+}
+
+// NewInMemoryAssignmentStore returns an assignment store that keeps assignments in the memory of the
+// process. Assignments are lost on restart and are not shared between processes; back
+// types.AssignmentStore with a shared store when they must be.
+func NewInMemoryAssignmentStore() types.AssignmentStore {
+	return &memoryAssignmentStore{assignments: make(map[string]map[string]int)}
+}
+
+func (s *memoryAssignmentStore) GetAssignment(_ context.Context, experimentUUID string, unitID string) (int, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	variantID, ok := s.assignments[experimentUUID][unitID]
+	return variantID, ok, nil
+}
+
+func (s *memoryAssignmentStore) SaveAssignment(_ context.Context, experimentUUID string, unitID string, variantID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	units, ok := s.assignments[experimentUUID]
+	if !ok {
+		units = make(map[string]int)
+		s.assignments[experimentUUID] = units
+	}
+	units[unitID] = variantID
+	return nil
+}
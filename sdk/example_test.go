@@ -0,0 +1,50 @@
+package sdk_test
+
+import (
+	"context"
+	"fmt"
+	"sdk"
+	"sdk/types"
+)
+
+func ExampleEvaluate() {
+	client, err := sdk.NewClient(sdk.ClientOptions{EndpointURL: "https://aurora.example.com", ServiceName: "checkout"})
+	if err != nil {
+		panic(err)
+	}
+	attrs := sdk.NewAttribute().SetString("user_id", "12345")
+
+	// The type of the default value picks how the parameter is read
+	enabled := sdk.Evaluate(context.Background(), client, "checkout_enabled", attrs, false)
+	limit := sdk.Evaluate(context.Background(), client, "max_items", attrs, 20)
+	fmt.Println(enabled, limit)
+}
+
+func ExampleGetValue() {
+	raw := "2.5"
+	rv := sdk.NewRolloutValue(&raw, types.ParameterDataTypeNumber)
+
+	fmt.Println(sdk.GetValue(&rv, 1.0))
+	// 2.5 is not an int, so the default is returned and the mismatch recorded
+	fmt.Println(sdk.GetValue(&rv, 1))
+	fmt.Println(rv.TypeMismatch() != nil)
+	// Output:
+	// 2.5
+	// 1
+	// true
+}
+
+func ExampleBindStruct() {
+	raw := `{"columns": 3, "theme": "dark"}`
+	rv := sdk.NewRolloutValue(&raw, types.ParameterDataTypeJSON)
+
+	var layout struct {
+		Columns int    `json:"columns"`
+		Theme   string `json:"theme"`
+	}
+	if err := sdk.BindStruct(rv, &layout); err != nil {
+		panic(err)
+	}
+	fmt.Println(layout.Columns, layout.Theme)
+	// Output: 3 dark
+}
@@ -0,0 +1,186 @@
+// Package ginaurora provides gin middleware that attaches a request-scoped
+// Aurora evaluator to every request.
+//
+// Basic usage:
+//
+//	router := gin.New()
+//	router.Use(ginaurora.Middleware(client,
+//		ginaurora.WithHeader("X-User-ID", "user_id"),
+//		ginaurora.WithQueryParam("country", "country"),
+//	))
+//
+//	router.GET("/", func(c *gin.Context) {
+//		message := ginaurora.FromContext(c).EvaluateParameter(c.Request.Context(), "welcome_message").AsString("Hello!")
+//		c.String(http.StatusOK, message)
+//	})
+package ginaurora
+
+import (
+	"context"
+	"fmt"
+	"sdk"
+	"sdk/pkg/errors"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextKey is the gin context key the evaluator is stored under
+const contextKey = "aurora.evaluator"
+
+// ClaimsExtractor extracts attribute values (e.g. JWT claims) from a request
+type ClaimsExtractor func(c *gin.Context) map[string]interface{}
+
+// Option configures the middleware
+type Option func(*options)
+
+type options struct {
+	headers         map[string]string
+	queryParams     map[string]string
+	claimsExtractor ClaimsExtractor
+}
+
+// WithHeader maps a request header to an attribute
+func WithHeader(header string, attributeName string) Option {
+	return func(o *options) {
+		o.headers[header] = attributeName
+	}
+}
+
+// WithQueryParam maps a query parameter to an attribute
+func WithQueryParam(param string, attributeName string) Option {
+	return func(o *options) {
+		o.queryParams[param] = attributeName
+	}
+}
+
+// WithClaimsExtractor sets a user-supplied extractor, typically reading JWT claims
+// placed on the context by an authentication middleware
+func WithClaimsExtractor(extractor ClaimsExtractor) Option {
+	return func(o *options) {
+		o.claimsExtractor = extractor
+	}
+}
+
+// Middleware builds an Attribute from the configured request sources and attaches
+// a request-scoped Evaluator to the gin context
+func Middleware(client sdk.Client, opts ...Option) gin.HandlerFunc {
+	o := &options{
+		headers:     make(map[string]string),
+		queryParams: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(c *gin.Context) {
+		attribute := sdk.NewAttribute()
+
+		for header, name := range o.headers {
+			if value := c.GetHeader(header); value != "" {
+				attribute.SetString(name, value)
+			}
+		}
+
+		for param, name := range o.queryParams {
+			if value, ok := c.GetQuery(param); ok {
+				attribute.SetString(name, value)
+			}
+		}
+
+		if o.claimsExtractor != nil {
+			for name, value := range o.claimsExtractor(c) {
+				setAttribute(attribute, name, value)
+			}
+		}
+
+		c.Set(contextKey, NewEvaluator(client, attribute))
+		c.Next()
+	}
+}
+
+// FromContext returns the request-scoped evaluator. When the middleware is not
+// installed an evaluator without a client is returned, so handlers never panic.
+func FromContext(c *gin.Context) *Evaluator {
+	if value, ok := c.Get(contextKey); ok {
+		if evaluator, ok := value.(*Evaluator); ok {
+			return evaluator
+		}
+	}
+	return NewEvaluator(nil, sdk.NewAttribute())
+}
+
+// Evaluator evaluates parameters for a single request, memoizing results so the
+// same parameter evaluated twice in one request is only resolved once
+type Evaluator struct {
+	client    sdk.Client
+	attribute *sdk.Attribute
+	mu        sync.Mutex
+	cache     map[string]sdk.RolloutValue
+}
+
+// NewEvaluator creates a new request-scoped evaluator
+func NewEvaluator(client sdk.Client, attribute *sdk.Attribute) *Evaluator {
+	return &Evaluator{
+		client:    client,
+		attribute: attribute,
+		cache:     make(map[string]sdk.RolloutValue),
+	}
+}
+
+// Attribute returns the attributes extracted for the request
+func (e *Evaluator) Attribute() *sdk.Attribute {
+	return e.attribute
+}
+
+// EvaluateParameter evaluates a parameter for the request, returning the memoized
+// value when the parameter was already evaluated
+func (e *Evaluator) EvaluateParameter(ctx context.Context, parameterName string) sdk.RolloutValue {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if value, ok := e.cache[parameterName]; ok {
+		return value
+	}
+
+	value := e.evaluate(ctx, parameterName)
+	e.cache[parameterName] = value
+	return value
+}
+
+// evaluate calls the client, converting a missing client or a panic into an error value
+func (e *Evaluator) evaluate(ctx context.Context, parameterName string) (value sdk.RolloutValue) {
+	if e.client == nil {
+		return sdk.NewRolloutValueWithError(errors.NewEvaluationFailedError(parameterName, "aurora client is not configured"))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			value = sdk.NewRolloutValueWithError(errors.NewEvaluationFailedError(parameterName, fmt.Sprintf("client panicked: %v", r)))
+		}
+	}()
+
+	return e.client.EvaluateParameter(ctx, parameterName, e.attribute)
+}
+
+// setAttribute sets a value on the attribute using the setter matching its type
+func setAttribute(attribute *sdk.Attribute, name string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		attribute.SetString(name, v)
+	case bool:
+		attribute.SetBool(name, v)
+	case float64:
+		attribute.SetNumber(name, v)
+	case float32:
+		attribute.SetNumber(name, float64(v))
+	case int:
+		attribute.SetNumber(name, float64(v))
+	case int64:
+		attribute.SetNumber(name, float64(v))
+	case nil:
+		// Skip absent claims
+	default:
+		attribute.SetString(name, fmt.Sprintf("%v", v))
+	}
+}
@@ -0,0 +1,143 @@
+package ginaurora
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sdk"
+	"sdk/types"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	calls     int
+	attribute *sdk.Attribute
+	panics    bool
+}
+
+func (f *fakeClient) Start(ctx context.Context) error { return nil }
+
+func (f *fakeClient) Stop(ctx context.Context) error { return nil }
+
+func (f *fakeClient) Close() {}
+
+func (f *fakeClient) Refresh(ctx context.Context) error { return nil }
+
+func (f *fakeClient) EvaluateParameter(ctx context.Context, parameterName string, attribute *sdk.Attribute) sdk.RolloutValue {
+	f.calls++
+	f.attribute = attribute
+	if f.panics {
+		panic("client not started")
+	}
+	value := "on"
+	return sdk.NewRolloutValue(&value, types.ParameterDataTypeString)
+}
+
+func (f *fakeClient) EvaluateParameterDetailed(ctx context.Context, parameterName string, attribute *sdk.Attribute) (sdk.RolloutValue, types.EvaluationDetails) {
+	return f.EvaluateParameter(ctx, parameterName, attribute), types.EvaluationDetails{Source: types.EvaluationSourceParameter}
+}
+
+func (f *fakeClient) EvaluateParameterTraced(ctx context.Context, parameterName string, attribute *sdk.Attribute) (sdk.RolloutValue, types.EvaluationDetails) {
+	return f.EvaluateParameterDetailed(ctx, parameterName, attribute)
+}
+
+func (f *fakeClient) EvaluateParameterTracedWith(ctx context.Context, parameter *types.Parameter, experiments []types.Experiment, attribute *sdk.Attribute) (sdk.RolloutValue, types.EvaluationDetails) {
+	return f.EvaluateParameterDetailed(ctx, parameter.Name, attribute)
+}
+
+func (f *fakeClient) EvaluateParameters(ctx context.Context, parameterNames []string, attribute *sdk.Attribute) map[string]sdk.RolloutValue {
+	values := make(map[string]sdk.RolloutValue, len(parameterNames))
+	for _, name := range parameterNames {
+		values[name] = f.EvaluateParameter(ctx, name, attribute)
+	}
+	return values
+}
+
+func (f *fakeClient) EvaluateExperimentDetailed(ctx context.Context, experiment *types.Experiment, attribute *sdk.Attribute, parameterName string) *types.ExperimentEvaluationResult {
+	return &types.ExperimentEvaluationResult{ExperimentID: &experiment.ID, ExperimentUUID: &experiment.Uuid}
+}
+
+func (f *fakeClient) GetMetadata(ctx context.Context) (*types.MetadataResponse, error) {
+	return &types.MetadataResponse{}, nil
+}
+
+func (f *fakeClient) GetParameterDebug(ctx context.Context, parameterName string) (*types.Parameter, error) {
+	return nil, nil
+}
+
+func (f *fakeClient) SyncStatus() types.SyncStatus {
+	return types.SyncStatus{}
+}
+
+func newRouter(client sdk.Client, handler gin.HandlerFunc, opts ...Option) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(client, opts...))
+	router.GET("/", handler)
+	return router
+}
+
+func TestMiddlewareMemoizesEvaluation(t *testing.T) {
+	client := &fakeClient{}
+	router := newRouter(client, func(c *gin.Context) {
+		evaluator := FromContext(c)
+		first := evaluator.EvaluateParameter(c.Request.Context(), "feature")
+		second := evaluator.EvaluateParameter(c.Request.Context(), "feature")
+		c.String(http.StatusOK, first.AsString("")+second.AsString(""))
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "onon", recorder.Body.String())
+	require.Equal(t, 1, client.calls)
+}
+
+func TestMiddlewareExtractsAttributes(t *testing.T) {
+	client := &fakeClient{}
+	router := newRouter(client, func(c *gin.Context) {
+		FromContext(c).EvaluateParameter(c.Request.Context(), "feature")
+		c.Status(http.StatusOK)
+	},
+		WithHeader("X-User-ID", "user_id"),
+		WithQueryParam("country", "country"),
+		WithClaimsExtractor(func(c *gin.Context) map[string]interface{} {
+			return map[string]interface{}{"age": float64(30), "premium": true}
+		}),
+	)
+
+	request := httptest.NewRequest(http.MethodGet, "/?country=VN", nil)
+	request.Header.Set("X-User-ID", "42")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	require.NotNil(t, client.attribute)
+	require.Equal(t, "42", client.attribute.Get("user_id"))
+	require.Equal(t, "VN", client.attribute.Get("country"))
+	require.Equal(t, float64(30), client.attribute.Get("age"))
+	require.Equal(t, true, client.attribute.Get("premium"))
+}
+
+func TestEvaluatorIsPanicSafe(t *testing.T) {
+	client := &fakeClient{panics: true}
+	router := newRouter(client, func(c *gin.Context) {
+		value := FromContext(c).EvaluateParameter(c.Request.Context(), "feature")
+		require.True(t, value.HasError())
+		c.Status(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestFromContextWithoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	value := FromContext(c).EvaluateParameter(context.Background(), "feature")
+	require.True(t, value.HasError())
+}
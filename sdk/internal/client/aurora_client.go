@@ -2,11 +2,16 @@ package client
 
 import (
 	"context"
+	stderrors "errors"
+	"fmt"
 	"sdk/internal/config"
 	"sdk/pkg/errors"
 	"sdk/pkg/logger"
 	"sdk/types"
+	"sync"
 	"time"
+
+	"github.com/dgraph-io/badger/v4"
 )
 
 // AuroraClient implements the Client interface
@@ -17,19 +22,53 @@ type AuroraClient struct {
 	engine       Engine
 	eventTracker EventTracker
 	dataFetcher  DataFetcher
-	quit         chan struct{}
+	// bootstrapFetcher reads the bootstrap files loaded at start before the first fetch; it is nil
+	// without bootstrap files or when they are the data fetcher's own source
+	bootstrapFetcher DataFetcher
+	metrics          types.MetricsCollector
+	defaults         map[string]config.DefaultValue
+	quit             chan struct{}
+	// dispatchDone is closed when the background refresh loop exits; it is nil when no loop was started
+	dispatchDone chan struct{}
+	// streamDone is closed when the change stream exits; it is nil when streaming is disabled
+	streamDone chan struct{}
+	// persistMu serializes persists, so data fetched earlier never overwrites newer data
+	persistMu sync.Mutex
+	// syncMu guards syncStatus, which persist updates while callers read it
+	syncMu     sync.RWMutex
+	syncStatus types.SyncStatus
+	// stopOnce makes Stop run once; later calls return the error of the first
+	stopOnce sync.Once
+	stopErr  error
 }
 
 // NewAuroraClient creates a new Aurora client
 func NewAuroraClient(cfg *config.Config, storage Storage, engine Engine, eventTracker EventTracker, dataFetcher DataFetcher) Client {
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = types.NoopMetricsCollector{}
+	}
+	defaults := make(map[string]config.DefaultValue, len(cfg.Defaults))
+	for parameterName, value := range cfg.Defaults {
+		if defaultValue, err := config.ParseDefaultValue(value); err == nil {
+			defaults[parameterName] = defaultValue
+		}
+	}
+	var bootstrapFetcher DataFetcher
+	if cfg.BootstrapPath != "" && !cfg.Offline() {
+		bootstrapFetcher = NewFileDataFetcher(cfg.BootstrapPath, metrics, cfg.Logger)
+	}
 	return &AuroraClient{
-		config:       cfg,
-		logger:       cfg.Logger,
-		storage:      storage,
-		engine:       engine,
-		eventTracker: eventTracker,
-		dataFetcher:  dataFetcher,
-		quit:         make(chan struct{}),
+		config:           cfg,
+		logger:           cfg.Logger,
+		storage:          storage,
+		engine:           engine,
+		eventTracker:     eventTracker,
+		dataFetcher:      dataFetcher,
+		bootstrapFetcher: bootstrapFetcher,
+		metrics:          metrics,
+		defaults:         defaults,
+		quit:             make(chan struct{}),
 	}
 }
 
@@ -42,37 +81,138 @@ func (c *AuroraClient) Start(ctx context.Context) error {
 		return ctx.Err()
 	}
 
+	if c.bootstrapFetcher != nil {
+		// The bootstrap data replaces the persisted data, so the versions stored with it are not restored
+		if err := c.persistFrom(ctx, c.bootstrapFetcher); err != nil {
+			c.logger.ErrorContext(ctx, "failed to load bootstrap data", "error", err)
+		}
+	} else {
+		c.restoreDataVersions(ctx)
+	}
+
 	err := c.persist(ctx)
 	if err != nil {
 		c.logger.ErrorContext(ctx, "failed to persist parameters", "error", err)
 	}
 
+	if c.config.DisableRefresh {
+		c.logger.Info("refresh is disabled, data is only loaded at start")
+		return nil
+	}
+
+	if c.config.Streaming {
+		c.streamDone = make(chan struct{})
+		go c.stream(ctx)
+	}
+
+	if c.config.RefreshRate == 0 {
+		c.logger.Info("refresh rate is 0, background refresh is disabled")
+		return nil
+	}
+
+	c.dispatchDone = make(chan struct{})
 	go c.dispatch(ctx)
 	return nil
 }
 
-// Stop shuts down the client gracefully
-func (c *AuroraClient) Stop() {
+// Refresh fetches and stores the latest data on demand. Clients created with a refresh
+// rate of 0 do not refresh in the background and must call Refresh themselves.
+func (c *AuroraClient) Refresh(ctx context.Context) error {
+	if err := c.persist(ctx); err != nil {
+		c.logger.ErrorContext(ctx, "failed to refresh data", "error", err)
+		return err
+	}
+	return nil
+}
+
+// Stop shuts down the client gracefully: it stops the refresh loop, sends the pending events and
+// closes storage. ctx bounds the whole shutdown; an error is returned when the events could not be sent
+// or the refresh loop did not stop in time, in which case storage is left open since the loop may still
+// write to it. Stop runs once; calling it again returns the error of the first call.
+func (c *AuroraClient) Stop(ctx context.Context) error {
+	c.stopOnce.Do(func() {
+		c.stopErr = c.stop(ctx)
+	})
+	return c.stopErr
+}
+
+func (c *AuroraClient) stop(ctx context.Context) error {
 	c.logger.Info("stopping Aurora client")
 
-	// Flush any pending events before stopping
+	// Stop the refresh loop and the change stream so they no longer write to storage; closing quit is safe even when neither was started
+	close(c.quit)
+	var stopErr error
+	if c.dispatchDone != nil {
+		select {
+		case <-c.dispatchDone:
+		case <-ctx.Done():
+			c.logger.WarnContext(ctx, "refresh loop did not stop before the shutdown deadline")
+			stopErr = errors.NewTimeoutError("stop refresh loop", ctx.Err())
+		}
+	}
+	if c.streamDone != nil {
+		select {
+		case <-c.streamDone:
+		case <-ctx.Done():
+			c.logger.WarnContext(ctx, "change stream did not stop before the shutdown deadline")
+			stopErr = errors.NewTimeoutError("stop change stream", ctx.Err())
+		}
+	}
+
+	// Flush any pending events before closing storage
 	if c.eventTracker != nil {
-		c.eventTracker.Stop(context.Background())
+		if err := c.eventTracker.Stop(ctx); err != nil {
+			if ctx.Err() != nil {
+				stopErr = errors.NewTimeoutError("flush events", err)
+			} else {
+				stopErr = err
+			}
+		}
 	}
 
-	// Close storage
+	// Storage is only closed once nothing writes to it anymore
+	if !isClosed(c.dispatchDone) || !isClosed(c.streamDone) {
+		c.logger.WarnContext(ctx, "storage is left open since the refresh loop may still write to it")
+		return stopErr
+	}
 	if c.storage != nil {
-		c.storage.Close(context.Background())
+		if err := c.storage.Close(ctx); err != nil {
+			c.logger.ErrorContext(ctx, "failed to close storage", "error", err)
+		}
 	}
 
-	close(c.quit)
+	return stopErr
+}
+
+// isClosed reports whether done, which is nil when its goroutine was not started, is closed
+func isClosed(done chan struct{}) bool {
+	if done == nil {
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
 }
 
 // EvaluateParameter evaluates a parameter against the given attributes
 func (c *AuroraClient) EvaluateParameter(ctx context.Context, parameterName string, attribute Attribute) RolloutValue {
+	res, _ := c.EvaluateParameterDetailed(ctx, parameterName, attribute)
+	return res
+}
+
+// EvaluateParameterDetailed evaluates a parameter and reports whether the value came from
+// an experiment, and if so which experiment and variant the attributes were assigned to
+func (c *AuroraClient) EvaluateParameterDetailed(ctx context.Context, parameterName string, attribute Attribute) (RolloutValue, types.EvaluationDetails) {
+	start := time.Now()
+	defer func() { c.metrics.ObserveEvaluationLatency(time.Since(start)) }()
+
 	// Try experiments first
 	experimentResult, resExperiments := c.resolveFromExperiments(ctx, parameterName, attribute)
 	if !resExperiments.HasError() {
+		c.metrics.IncEvaluation("experiment", parameterName)
 		if c.config.OnEvaluate != nil {
 			c.config.OnEvaluate("experiment", parameterName, attribute, resExperiments.Raw(), resExperiments.Error())
 		}
@@ -88,18 +228,27 @@ func (c *AuroraClient) EvaluateParameter(ctx context.Context, parameterName stri
 				experimentResult.ExperimentUUID,
 				experimentResult.VariantID,
 				experimentResult.VariantName,
+				experimentResult.VariantOverridden,
 			)
+			event.Source = experimentDetails(experimentResult).Source
 			c.eventTracker.TrackEvent(ctx, event)
 		}
 
-		return resExperiments
+		return resExperiments, experimentDetails(experimentResult)
 	}
 
-	// Fall back to parameters
-	res := c.resolveFromParameter(ctx, parameterName, attribute)
+	// Fall back to parameters, then to the registered default
+	res := c.resolveFromParameter(ctx, parameterName, attribute, missReason(resExperiments))
+	source := types.EvaluationSourceParameter
+	if res.HasError() {
+		if defaultValue, ok := c.registeredDefault(parameterName); ok {
+			res, source = defaultValue, types.EvaluationSourceDefault
+		}
+	}
 
+	c.metrics.IncEvaluation(evaluationOutcome(source, res), parameterName)
 	if c.config.OnEvaluate != nil {
-		c.config.OnEvaluate("parameter", parameterName, attribute, res.Raw(), res.Error())
+		c.config.OnEvaluate(source, parameterName, attribute, res.Raw(), res.Error())
 	}
 
 	// Track parameter evaluation event
@@ -110,23 +259,181 @@ func (c *AuroraClient) EvaluateParameter(ctx context.Context, parameterName stri
 			res.Raw(),
 			res.Error(),
 		)
+		event.Source = source
 		c.eventTracker.TrackEvent(ctx, event)
 	}
 
-	return res
+	return res, types.EvaluationDetails{Source: source}
+}
+
+// EvaluateParameterTraced evaluates a parameter like EvaluateParameterDetailed and, when the value
+// falls back to the parameter, records every rule and condition decision in the details' trace.
+// No evaluation event is tracked, so it can be used to debug why attributes get a value.
+func (c *AuroraClient) EvaluateParameterTraced(ctx context.Context, parameterName string, attribute Attribute) (RolloutValue, types.EvaluationDetails) {
+	experimentResult, resExperiments := c.resolveFromExperiments(ctx, parameterName, attribute)
+	if !resExperiments.HasError() {
+		return resExperiments, experimentDetails(experimentResult)
+	}
+
+	parameter, err := c.storage.GetParameterByName(ctx, parameterName)
+	if err != nil {
+		c.observeStorageRead("get parameter", err)
+		c.logger.ErrorContext(ctx, "failed to get parameter", "error", err)
+		if defaultValue, ok := c.registeredDefault(parameterName); ok {
+			return defaultValue, types.EvaluationDetails{Source: types.EvaluationSourceDefault}
+		}
+		return NewRolloutValueWithError(errors.NewParameterNotFoundError(parameterName)), types.EvaluationDetails{Source: types.EvaluationSourceParameter}
+	}
+
+	rolloutValueStr, reason, trace := c.engine.EvaluateParameterTraced(&parameter, attribute)
+	if reason == types.EvaluationReasonDefault {
+		reason = missReason(resExperiments)
+	}
+	res := newParameterRolloutValue(&rolloutValueStr, parameter.DataType, parameter.EnumOptions, reason)
+	return res, types.EvaluationDetails{Source: types.EvaluationSourceParameter, Trace: trace}
+}
+
+// EvaluateParameterTracedWith evaluates a parameter like EvaluateParameterTraced, but from a parameter and
+// experiments supplied by the caller instead of those in storage, such as the data served to another
+// environment. Variants hashed for the attributes are not saved to the assignment store.
+func (c *AuroraClient) EvaluateParameterTracedWith(ctx context.Context, parameter *types.Parameter, experiments []types.Experiment, attribute Attribute) (RolloutValue, types.EvaluationDetails) {
+	experimentResult, resExperiments := c.evaluateExperiments(ctx, experiments, parameter.Name, attribute, parameter.EnumOptions, false)
+	if !resExperiments.HasError() {
+		return resExperiments, experimentDetails(experimentResult)
+	}
+
+	rolloutValueStr, reason, trace := c.engine.EvaluateParameterTraced(parameter, attribute)
+	if reason == types.EvaluationReasonDefault {
+		reason = missReason(resExperiments)
+	}
+	res := newParameterRolloutValue(&rolloutValueStr, parameter.DataType, parameter.EnumOptions, reason)
+	return res, types.EvaluationDetails{Source: types.EvaluationSourceParameter, Trace: trace}
+}
+
+// EvaluateParameters evaluates several parameters for the same attributes. Experiments and
+// parameters are read from storage once, and the evaluation events are tracked as one batch.
+// A parameter that cannot be resolved maps to a RolloutValue carrying a ParameterNotFoundError.
+func (c *AuroraClient) EvaluateParameters(ctx context.Context, parameterNames []string, attribute Attribute) map[string]RolloutValue {
+	start := time.Now()
+	defer func() { c.metrics.ObserveEvaluationLatency(time.Since(start)) }()
+	results := make(map[string]RolloutValue, len(parameterNames))
+
+	experimentsByParameter, err := c.storage.GetExperimentsByParameterNames(ctx, parameterNames)
+	if err != nil {
+		c.observeStorageRead("get experiments by parameter names", err)
+		c.logger.ErrorContext(ctx, "failed to get experiments by parameter names", "error", err)
+		experimentsByParameter = map[string][]types.Experiment{}
+	}
+
+	parameters, err := c.storage.GetParametersByNames(ctx, parameterNames)
+	if err != nil {
+		c.observeStorageRead("get parameters by names", err)
+		c.logger.ErrorContext(ctx, "failed to get parameters by names", "error", err)
+		parameters = map[string]types.Parameter{}
+	}
+
+	events := make([]types.EvaluationEvent, 0, len(parameterNames))
+	for _, parameterName := range parameterNames {
+		if _, ok := results[parameterName]; ok {
+			continue
+		}
+
+		// Try experiments first
+		experimentResult, resExperiments := c.evaluateExperiments(ctx, experimentsByParameter[parameterName], parameterName, attribute, parameters[parameterName].EnumOptions, true)
+		if !resExperiments.HasError() {
+			c.metrics.IncEvaluation("experiment", parameterName)
+			if c.config.OnEvaluate != nil {
+				c.config.OnEvaluate("experiment", parameterName, attribute, resExperiments.Raw(), resExperiments.Error())
+			}
+			if c.eventTracker != nil {
+				event := c.eventTracker.CreateExperimentEvaluationEvent(
+					parameterName,
+					attribute,
+					resExperiments.Raw(),
+					resExperiments.Error(),
+					experimentResult.ExperimentID,
+					experimentResult.ExperimentUUID,
+					experimentResult.VariantID,
+					experimentResult.VariantName,
+					experimentResult.VariantOverridden,
+				)
+				event.Source = experimentDetails(experimentResult).Source
+				events = append(events, event)
+			}
+			results[parameterName] = resExperiments
+			continue
+		}
+
+		// Fall back to parameters, then to the registered default
+		var res RolloutValue
+		source := types.EvaluationSourceParameter
+		if parameter, ok := parameters[parameterName]; ok {
+			res = c.evaluateParameter(&parameter, attribute, missReason(resExperiments))
+		} else if defaultValue, ok := c.registeredDefault(parameterName); ok {
+			res, source = defaultValue, types.EvaluationSourceDefault
+		} else {
+			res = NewRolloutValueWithError(errors.NewParameterNotFoundError(parameterName))
+		}
+
+		c.metrics.IncEvaluation(evaluationOutcome(source, res), parameterName)
+		if c.config.OnEvaluate != nil {
+			c.config.OnEvaluate(source, parameterName, attribute, res.Raw(), res.Error())
+		}
+		if c.eventTracker != nil {
+			event := c.eventTracker.CreateParameterEvaluationEvent(
+				parameterName,
+				attribute,
+				res.Raw(),
+				res.Error(),
+			)
+			event.Source = source
+			events = append(events, event)
+		}
+		results[parameterName] = res
+	}
+
+	// Track all evaluation events as one batch
+	if c.eventTracker != nil {
+		c.eventTracker.TrackEvents(ctx, events)
+	}
+
+	return results
+}
+
+// EvaluateExperimentDetailed evaluates an experiment supplied by the caller instead of one from storage.
+// No evaluation event is tracked, so it can be used to preview assignments before an experiment is launched.
+// Assignments stored by WithAssignmentStore are honored but the preview does not record any.
+func (c *AuroraClient) EvaluateExperimentDetailed(ctx context.Context, experiment *types.Experiment, attribute Attribute, parameterName string) *types.ExperimentEvaluationResult {
+	return c.evaluateExperiment(ctx, experiment, attribute, parameterName, false)
 }
 
 // GetMetadata retrieves metadata from the upstream service
 func (c *AuroraClient) GetMetadata(ctx context.Context) (*types.MetadataResponse, error) {
 	// This would be implemented by the data fetcher
 	// For now, return a default response
-	return &types.MetadataResponse{
+	metadata := &types.MetadataResponse{
 		EnableS3: c.config.EnableS3,
-	}, nil
+	}
+	if storage, ok := c.storage.(RecoverableStorage); ok {
+		metadata.StorageDegraded = storage.Degraded()
+	}
+	return metadata, nil
+}
+
+// GetParameterDebug returns a parameter with its rules as stored by the client, without evaluating it
+// or tracking an event
+func (c *AuroraClient) GetParameterDebug(ctx context.Context, parameterName string) (*types.Parameter, error) {
+	parameter, err := c.storage.GetParameterByName(ctx, parameterName)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to get parameter", "error", err)
+		return nil, errors.NewParameterNotFoundError(parameterName)
+	}
+	return &parameter, nil
 }
 
 // dispatch runs the background refresh loop
 func (c *AuroraClient) dispatch(ctx context.Context) {
+	defer close(c.dispatchDone)
 	c.logger.Info("starting dispatch loop")
 	ticker := time.NewTicker(c.config.RefreshRate)
 	defer ticker.Stop()
@@ -149,62 +456,304 @@ func (c *AuroraClient) dispatch(ctx context.Context) {
 	}
 }
 
-// persist fetches and stores the latest data
+// persist fetches and stores the latest data. Data the fetcher reports as not modified
+// is left as it is in storage. The outcome is recorded in the sync status.
 func (c *AuroraClient) persist(ctx context.Context) error {
+	return c.persistFrom(ctx, c.dataFetcher)
+}
+
+// persistFrom stores the data of fetcher like persist
+func (c *AuroraClient) persistFrom(ctx context.Context, fetcher DataFetcher) (err error) {
+	c.persistMu.Lock()
+	defer c.persistMu.Unlock()
+
+	start := time.Now()
+	parameterCount, experimentCount := -1, -1
+	defer func() {
+		c.recordSync(err, parameterCount, experimentCount)
+		c.metrics.ObserveRefresh(time.Since(start), fetchStatus(err), parameterCount, experimentCount)
+	}()
+
+	c.recoverStorage(ctx)
+
 	// Fetch and persist experiments
-	experiments, err := c.dataFetcher.GetExperiments(ctx)
-	if err != nil {
-		return err
-	}
-	err = c.storage.PersistExperiments(ctx, experiments)
-	if err != nil {
+	experiments, err := fetcher.GetExperiments(ctx)
+	switch {
+	case errors.IsNotModified(err):
+		c.logger.Debug("experiments not modified, keeping persisted data")
+	case err != nil:
 		return err
+	default:
+		if err := c.storage.PersistExperiments(ctx, experiments); err != nil {
+			c.metrics.IncFetchError(string(errors.ErrorTypeStorageError))
+			return err
+		}
+		c.persistDataVersion(ctx, fetcher, DatasetExperiments)
+		experimentCount = len(experiments)
 	}
 
 	// Fetch and persist parameters
-	parameters, err := c.dataFetcher.GetParameters(ctx)
-	if err != nil {
-		return err
-	}
-	err = c.storage.PersistParameters(ctx, parameters)
-	if err != nil {
+	parameters, err := fetcher.GetParameters(ctx)
+	switch {
+	case errors.IsNotModified(err):
+		c.logger.Debug("parameters not modified, keeping persisted data")
+	case err != nil:
 		return err
+	default:
+		if err := c.storage.PersistParameters(ctx, parameters); err != nil {
+			c.metrics.IncFetchError(string(errors.ErrorTypeStorageError))
+			return err
+		}
+		c.persistDataVersion(ctx, fetcher, DatasetParameters)
+		parameterCount = len(parameters)
 	}
 
 	c.logger.Info("data persisted successfully", "parameters", len(parameters), "experiments", len(experiments))
 	return nil
 }
 
+// evaluationOutcome returns the source an evaluation is counted under in metrics
+func evaluationOutcome(source string, res RolloutValue) string {
+	if res.HasError() {
+		return "error"
+	}
+	return source
+}
+
+// observeStorageRead counts a failed storage read in metrics. A missing key is how storage reports
+// an unknown parameter, so it is not counted.
+func (c *AuroraClient) observeStorageRead(operation string, err error) {
+	if err != nil && !stderrors.Is(err, badger.ErrKeyNotFound) {
+		c.metrics.IncStorageReadError(operation)
+	}
+}
+
+// recordSync updates the sync status after a persist. Counts are negative for datasets that were
+// not stored, which keep the count of the last refresh that stored them.
+func (c *AuroraClient) recordSync(err error, parameterCount, experimentCount int) {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
+	c.syncStatus.LastError = err
+	if parameterCount >= 0 {
+		c.syncStatus.ParameterCount = parameterCount
+	}
+	if experimentCount >= 0 {
+		c.syncStatus.ExperimentCount = experimentCount
+	}
+	if err == nil {
+		c.syncStatus.LastSuccess = time.Now()
+	}
+}
+
+// SyncStatus returns the outcome of the client's refreshes
+func (c *AuroraClient) SyncStatus() types.SyncStatus {
+	c.syncMu.RLock()
+	defer c.syncMu.RUnlock()
+	return c.syncStatus
+}
+
+// recoverStorage rebuilds a storage whose reads keep failing. The fetcher versions are cleared
+// afterwards, so the rebuilt storage receives full data instead of not modified responses.
+func (c *AuroraClient) recoverStorage(ctx context.Context) {
+	storage, ok := c.storage.(RecoverableStorage)
+	if !ok || !storage.NeedsRebuild() {
+		return
+	}
+	c.logger.WarnContext(ctx, "rebuilding storage after consistent read failures")
+	if err := storage.Rebuild(ctx); err != nil {
+		c.logger.ErrorContext(ctx, "failed to rebuild storage", "error", err)
+		return
+	}
+	if fetcher, ok := c.dataFetcher.(VersionedDataFetcher); ok {
+		for _, dataset := range []string{DatasetExperiments, DatasetParameters} {
+			fetcher.SetDataVersion(dataset, "")
+		}
+	}
+}
+
+// restoreDataVersions hands the versions stored with the persisted data back to the fetcher,
+// so a restarted client with on-disk storage does not download unchanged data again
+func (c *AuroraClient) restoreDataVersions(ctx context.Context) {
+	fetcher, ok := c.dataFetcher.(VersionedDataFetcher)
+	if !ok {
+		return
+	}
+	for _, dataset := range []string{DatasetExperiments, DatasetParameters} {
+		version, err := c.storage.GetDataVersion(ctx, dataset)
+		if err != nil {
+			c.logger.WarnContext(ctx, "failed to get data version", "dataset", dataset, "error", err)
+			continue
+		}
+		if version != "" {
+			fetcher.SetDataVersion(dataset, version)
+		}
+	}
+}
+
+// persistDataVersion stores the fetcher's version of a dataset once its data has been persisted.
+// A failure only costs a full download after the next restart, so it is logged and ignored.
+func (c *AuroraClient) persistDataVersion(ctx context.Context, dataFetcher DataFetcher, dataset string) {
+	fetcher, ok := dataFetcher.(VersionedDataFetcher)
+	if !ok {
+		return
+	}
+	if err := c.storage.PersistDataVersion(ctx, dataset, fetcher.DataVersion(dataset)); err != nil {
+		c.logger.WarnContext(ctx, "failed to persist data version", "dataset", dataset, "error", err)
+	}
+}
+
 // resolveFromExperiments tries to resolve a parameter from experiments
 func (c *AuroraClient) resolveFromExperiments(ctx context.Context, parameterName string, attribute Attribute) (*types.ExperimentEvaluationResult, RolloutValue) {
 	experiments, err := c.storage.GetExperimentsByParameterName(ctx, parameterName)
 	if err != nil {
+		c.observeStorageRead("get experiments by parameter name", err)
 		c.logger.ErrorContext(ctx, "failed to get experiments by parameter name", "error", err)
 		return nil, NewRolloutValueWithError(errors.NewParameterNotFoundError(parameterName))
 	}
-	if len(experiments) == 0 {
-		return nil, NewRolloutValueWithError(errors.NewParameterNotFoundError(parameterName))
+	result, value := c.evaluateExperiments(ctx, experiments, parameterName, attribute, nil, true)
+	if result != nil && result.DataType == types.ParameterDataTypeEnum {
+		value = newParameterRolloutValue(value.Raw(), result.DataType, c.enumOptionsFor(ctx, parameterName), types.EvaluationReasonExperimentVariant)
 	}
+	return result, value
+}
 
+// evaluateExperiments returns the first experiment result that resolves the parameter.
+// When none does and the attributes were outside the population of one of the experiments,
+// the returned error value carries the not in population reason. record is passed on to evaluateExperiment.
+func (c *AuroraClient) evaluateExperiments(ctx context.Context, experiments []types.Experiment, parameterName string, attribute Attribute, enumOptions []string, record bool) (*types.ExperimentEvaluationResult, RolloutValue) {
+	notInPopulation := false
 	for _, experiment := range experiments {
-		result := c.engine.EvaluateExperimentDetailed(&experiment, attribute, parameterName)
+		result := c.evaluateExperiment(ctx, &experiment, attribute, parameterName, record)
 		if result.Success {
-			return result, NewRolloutValue(&result.Value, result.DataType)
+			return result, newParameterRolloutValue(&result.Value, result.DataType, enumOptions, types.EvaluationReasonExperimentVariant)
+		}
+		if !result.InPopulation {
+			notInPopulation = true
+		}
+	}
+	err := errors.NewParameterNotFoundError(parameterName)
+	if notInPopulation {
+		return nil, &RolloutValueImpl{reason: types.EvaluationReasonNotInPopulation, err: err}
+	}
+	return nil, NewRolloutValueWithError(err)
+}
+
+// evaluateExperiment evaluates an experiment, keeping the variant stored for the attributes in the
+// configured assignment store. When record is set, a newly hashed variant is saved to the store.
+// Store failures are logged and the attributes are hashed, so evaluation never fails because of the store.
+func (c *AuroraClient) evaluateExperiment(ctx context.Context, experiment *types.Experiment, attribute Attribute, parameterName string, record bool) *types.ExperimentEvaluationResult {
+	store := c.config.AssignmentStore
+	if store == nil {
+		return c.engine.EvaluateExperimentDetailed(experiment, attribute, parameterName)
+	}
+	unit := attribute.Get(experiment.HashAttributeName)
+	if unit == nil {
+		return c.engine.EvaluateExperimentDetailed(experiment, attribute, parameterName)
+	}
+	unitID := fmt.Sprintf("%v", unit)
+
+	variantID, assigned, err := store.GetAssignment(ctx, experiment.Uuid, unitID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to get experiment assignment", "experimentUUID", experiment.Uuid, "error", err)
+		assigned = false
+	}
+
+	var result *types.ExperimentEvaluationResult
+	if assigned {
+		result = c.engine.EvaluateAssignedExperiment(experiment, attribute, parameterName, variantID)
+	} else {
+		result = c.engine.EvaluateExperimentDetailed(experiment, attribute, parameterName)
+	}
+
+	if record && result.VariantID != nil && !result.VariantOverridden && !result.Overridden && (!assigned || *result.VariantID != variantID) {
+		if err := store.SaveAssignment(ctx, experiment.Uuid, unitID, *result.VariantID); err != nil {
+			c.logger.ErrorContext(ctx, "failed to save experiment assignment", "experimentUUID", experiment.Uuid, "error", err)
 		}
 	}
-	return nil, NewRolloutValueWithError(errors.NewParameterNotFoundError(parameterName))
+	return result
 }
 
-// resolveFromParameter resolves a parameter from the parameter store
-func (c *AuroraClient) resolveFromParameter(ctx context.Context, parameterName string, attribute Attribute) RolloutValue {
+// experimentDetails describes the experiment and variant an evaluation was resolved from. Values
+// forced by the experiment's overrides are reported with the override source, so that exposure
+// analyses can leave them out.
+func experimentDetails(result *types.ExperimentEvaluationResult) types.EvaluationDetails {
+	source := types.EvaluationSourceExperiment
+	if result.Overridden {
+		source = types.EvaluationSourceOverride
+	}
+	return types.EvaluationDetails{
+		Source:         source,
+		ExperimentID:   result.ExperimentID,
+		ExperimentUUID: result.ExperimentUUID,
+		VariantID:      result.VariantID,
+		VariantName:    result.VariantName,
+	}
+}
+
+// missReason returns the reason reported for a parameter's default value once its experiments
+// did not resolve it
+func missReason(experimentValue RolloutValue) types.EvaluationReason {
+	if experimentValue.Reason() == types.EvaluationReasonNotInPopulation {
+		return types.EvaluationReasonNotInPopulation
+	}
+	return types.EvaluationReasonDefault
+}
+
+// resolveFromParameter resolves a parameter from the parameter store.
+// defaultReason is reported when no rule of the parameter matches.
+func (c *AuroraClient) resolveFromParameter(ctx context.Context, parameterName string, attribute Attribute, defaultReason types.EvaluationReason) RolloutValue {
 	c.logger.InfoContext(ctx, "resolving parameter", "parameterName", parameterName)
 	parameter, err := c.storage.GetParameterByName(ctx, parameterName)
 	if err != nil {
+		c.observeStorageRead("get parameter", err)
 		c.logger.ErrorContext(ctx, "failed to get parameter", "error", err)
 		return NewRolloutValueWithError(errors.NewParameterNotFoundError(parameterName))
 	}
 
-	rolloutValueStr := c.engine.EvaluateParameter(&parameter, attribute)
-	c.logger.InfoContext(ctx, "resolved parameter", "parameterName", parameterName, "rolloutValue", rolloutValueStr, "dataType", parameter.DataType, "rules count", len(parameter.Rules))
-	return NewRolloutValue(&rolloutValueStr, parameter.DataType)
+	res := c.evaluateParameter(&parameter, attribute, defaultReason)
+	c.logger.InfoContext(ctx, "resolved parameter", "parameterName", parameterName, "rolloutValue", res.Raw(), "reason", res.Reason(), "dataType", parameter.DataType, "rules count", len(parameter.Rules))
+	return res
+}
+
+// registeredDefault returns the default registered with WithDefaults for a parameter that cannot be evaluated
+func (c *AuroraClient) registeredDefault(parameterName string) (RolloutValue, bool) {
+	defaultValue, ok := c.defaults[parameterName]
+	if !ok {
+		return nil, false
+	}
+	value := defaultValue.Value
+	return newParameterRolloutValue(&value, defaultValue.DataType, nil, types.EvaluationReasonParameterNotFound), true
+}
+
+// evaluateParameter evaluates the rules of a parameter, reporting defaultReason when none matches
+func (c *AuroraClient) evaluateParameter(parameter *types.Parameter, attribute Attribute, defaultReason types.EvaluationReason) RolloutValue {
+	rolloutValueStr, reason := c.engine.EvaluateParameterDetailed(parameter, attribute)
+	if reason == types.EvaluationReasonDefault {
+		reason = defaultReason
+	}
+	return newParameterRolloutValue(&rolloutValueStr, parameter.DataType, parameter.EnumOptions, reason)
+}
+
+// enumOptionsFor looks up the options of an enum parameter, since experiments only carry the data type
+func (c *AuroraClient) enumOptionsFor(ctx context.Context, parameterName string) []string {
+	parameter, err := c.storage.GetParameterByName(ctx, parameterName)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to get enum options", "parameterName", parameterName, "error", err)
+		return nil
+	}
+	return parameter.EnumOptions
+}
+
+// newParameterRolloutValue creates a RolloutValue with its evaluation reason, attaching the options of enum parameters
+func newParameterRolloutValue(value *string, dataType types.ParameterDataType, enumOptions []string, reason types.EvaluationReason) RolloutValue {
+	rv := &RolloutValueImpl{
+		value:    value,
+		DataType: dataType,
+		reason:   reason,
+	}
+	if dataType == types.ParameterDataTypeEnum {
+		rv.enumOptions = enumOptions
+	}
+	return rv
 }
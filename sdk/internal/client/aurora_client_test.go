@@ -0,0 +1,364 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sdk/internal/config"
+	"sdk/internal/storage"
+	"sdk/pkg/errors"
+	"sdk/pkg/logger"
+	"sdk/types"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuroraClientRestoresDataVersionsAfterRestart(t *testing.T) {
+	const etag = `"v1"`
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.URL.Path+" "+r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/sdk/parameters" {
+			io.WriteString(w, `{"parameters":[{"name":"from_http","dataType":"string"}]}`)
+			return
+		}
+		io.WriteString(w, `{"experiments":[]}`)
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.RefreshRate = 0
+	cfg.Logger = logger.NewDefaultLogger(slog.LevelError + 4)
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	defer db.Close()
+	store := storage.NewBadgerStorage(db, cfg.Logger)
+
+	// Each client gets a fresh fetcher, as a restarted process would
+	for i := 0; i < 2; i++ {
+		c := NewAuroraClient(cfg, store, nil, nil, NewHTTPDataFetcher(server.URL, "", "", server.Client(), RetryPolicy{}, types.NoopMetricsCollector{}, cfg.Logger))
+		require.NoError(t, c.Start(context.Background()))
+	}
+
+	require.Equal(t, []string{
+		"/api/v1/sdk/experiments ",
+		"/api/v1/sdk/parameters ",
+		"/api/v1/sdk/experiments " + etag,
+		"/api/v1/sdk/parameters " + etag,
+	}, ifNoneMatch)
+
+	parameter, err := store.GetParameterByName(context.Background(), "from_http")
+	require.NoError(t, err)
+	require.Equal(t, "from_http", parameter.Name)
+}
+
+func TestAuroraClientSyncStatus(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/sdk/parameters" {
+			io.WriteString(w, `{"parameters":[{"name":"a","dataType":"string"},{"name":"b","dataType":"string"}]}`)
+			return
+		}
+		io.WriteString(w, `{"experiments":[]}`)
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.RefreshRate = 0
+	cfg.Logger = logger.NewDefaultLogger(slog.LevelError + 4)
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	defer db.Close()
+	c := NewAuroraClient(cfg, storage.NewBadgerStorage(db, cfg.Logger), nil, nil, NewHTTPDataFetcher(server.URL, "", "", server.Client(), RetryPolicy{}, types.NoopMetricsCollector{}, cfg.Logger))
+	require.Zero(t, c.SyncStatus())
+
+	require.NoError(t, c.Refresh(context.Background()))
+	status := c.SyncStatus()
+	require.False(t, status.LastSuccess.IsZero())
+	require.NoError(t, status.LastError)
+	require.Equal(t, 2, status.ParameterCount)
+	require.Equal(t, 0, status.ExperimentCount)
+
+	failing.Store(true)
+	require.Error(t, c.Refresh(context.Background()))
+	failed := c.SyncStatus()
+	require.Error(t, failed.LastError)
+	require.Equal(t, status.LastSuccess, failed.LastSuccess)
+	require.Equal(t, 2, failed.ParameterCount)
+}
+
+// reasonEngine resolves parameters to their default and experiments to the configured result
+type reasonEngine struct {
+	experimentResult types.ExperimentEvaluationResult
+}
+
+func (e *reasonEngine) EvaluateParameter(parameter *types.Parameter, attribute Attribute) string {
+	return parameter.DefaultRolloutValue
+}
+
+func (e *reasonEngine) EvaluateParameterDetailed(parameter *types.Parameter, attribute Attribute) (string, types.EvaluationReason) {
+	return parameter.DefaultRolloutValue, types.EvaluationReasonDefault
+}
+
+func (e *reasonEngine) EvaluateParameterTraced(parameter *types.Parameter, attribute Attribute) (string, types.EvaluationReason, *types.EvaluationTrace) {
+	return parameter.DefaultRolloutValue, types.EvaluationReasonDefault, &types.EvaluationTrace{}
+}
+
+func (e *reasonEngine) EvaluateExperiment(experiment *types.Experiment, attribute Attribute, parameterName string) (string, types.ParameterDataType, bool) {
+	return e.experimentResult.Value, e.experimentResult.DataType, e.experimentResult.Success
+}
+
+func (e *reasonEngine) EvaluateExperimentDetailed(experiment *types.Experiment, attribute Attribute, parameterName string) *types.ExperimentEvaluationResult {
+	result := e.experimentResult
+	return &result
+}
+
+func (e *reasonEngine) EvaluateAssignedExperiment(experiment *types.Experiment, attribute Attribute, parameterName string, variantID int) *types.ExperimentEvaluationResult {
+	return e.EvaluateExperimentDetailed(experiment, attribute, parameterName)
+}
+
+func TestAuroraClientEvaluationReason(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Logger = logger.NewDefaultLogger(slog.LevelError + 4)
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	defer db.Close()
+	store := storage.NewBadgerStorage(db, cfg.Logger)
+	require.NoError(t, store.PersistParameters(ctx, []types.Parameter{
+		{Name: "with_experiment", DataType: types.ParameterDataTypeString, DefaultRolloutValue: "default"},
+		{Name: "without_experiment", DataType: types.ParameterDataTypeString, DefaultRolloutValue: "default"},
+	}))
+	require.NoError(t, store.PersistExperiments(ctx, []types.Experiment{{
+		Name: "experiment",
+		Variants: []types.ExperimentVariant{{Parameters: []types.ExperimentVariantParameter{
+			{ParameterName: "with_experiment", ParameterDataType: types.ParameterDataTypeString, RolloutValue: "variant"},
+		}}},
+	}}))
+
+	engine := &reasonEngine{}
+	c := NewAuroraClient(cfg, store, engine, nil, nil)
+
+	require.Equal(t, types.EvaluationReasonNotInPopulation, c.EvaluateParameter(ctx, "with_experiment", nil).Reason())
+	require.Equal(t, types.EvaluationReasonDefault, c.EvaluateParameter(ctx, "without_experiment", nil).Reason())
+	require.Equal(t, types.EvaluationReasonParameterNotFound, c.EvaluateParameter(ctx, "missing", nil).Reason())
+
+	engine.experimentResult = types.ExperimentEvaluationResult{Success: true, InPopulation: true, Value: "variant", DataType: types.ParameterDataTypeString}
+	require.Equal(t, types.EvaluationReasonExperimentVariant, c.EvaluateParameter(ctx, "with_experiment", nil).Reason())
+	results := c.EvaluateParameters(ctx, []string{"with_experiment", "without_experiment"}, nil)
+	require.Equal(t, types.EvaluationReasonExperimentVariant, results["with_experiment"].Reason())
+	require.Equal(t, types.EvaluationReasonDefault, results["without_experiment"].Reason())
+}
+
+func TestAuroraClientRefreshesOnStreamedChange(t *testing.T) {
+	var mu sync.Mutex
+	parameterValue := "before"
+	parameterFetches := 0
+	changes := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/sdk/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case <-changes:
+					io.WriteString(w, "event:change\ndata:{\"entity\":\"parameter\",\"id\":1}\n\n")
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		case "/api/v1/sdk/parameters":
+			w.Header().Set("Content-Type", "application/json")
+			mu.Lock()
+			defer mu.Unlock()
+			parameterFetches++
+			io.WriteString(w, `{"parameters":[{"name":"flag","dataType":"string","defaultRolloutValue":"`+parameterValue+`"}]}`)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"experiments":[]}`)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.EndpointURL = server.URL
+	cfg.RefreshRate = 0
+	cfg.Streaming = true
+	cfg.Logger = logger.NewDefaultLogger(slog.LevelError + 4)
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	store := storage.NewBadgerStorage(db, cfg.Logger)
+	c := NewAuroraClient(cfg, store, nil, nil, NewHTTPDataFetcher(server.URL, "", "", server.Client(), RetryPolicy{}, types.NoopMetricsCollector{}, cfg.Logger))
+	require.NoError(t, c.Start(context.Background()))
+	defer c.Stop(context.Background())
+
+	// Wait for the refresh done once the stream connects, so only the change event can pick up the new value
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return parameterFetches == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	parameterValue = "after"
+	mu.Unlock()
+	changes <- struct{}{}
+
+	require.Eventually(t, func() bool {
+		parameter, err := store.GetParameterByName(context.Background(), "flag")
+		return err == nil && parameter.DefaultRolloutValue == "after"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+// alternatingFetcher returns the stable parameter with a different set of other parameters on every fetch
+type alternatingFetcher struct {
+	fetches atomic.Int64
+}
+
+func (f *alternatingFetcher) GetParameters(ctx context.Context) ([]types.Parameter, error) {
+	fetch := f.fetches.Add(1)
+	parameters := []types.Parameter{{Name: "stable", DataType: types.ParameterDataTypeString, DefaultRolloutValue: "value"}}
+	for i := 0; i < 50; i++ {
+		parameters = append(parameters, types.Parameter{Name: fmt.Sprintf("fetch-%d-%d", fetch%2, i), DataType: types.ParameterDataTypeString})
+	}
+	return parameters, nil
+}
+
+func (f *alternatingFetcher) GetExperiments(ctx context.Context) ([]types.Experiment, error) {
+	return []types.Experiment{}, nil
+}
+
+func TestAuroraClientEvaluationNeverSeesHalfPersistedParameters(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Logger = logger.NewDefaultLogger(slog.LevelError + 4)
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	defer db.Close()
+	c := NewAuroraClient(cfg, storage.NewBadgerStorage(db, cfg.Logger), &reasonEngine{}, nil, &alternatingFetcher{})
+	require.NoError(t, c.Refresh(ctx))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			if err := c.Refresh(ctx); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		// The stable parameter is in every dataset, so it must never be reported missing
+		value := c.EvaluateParameter(ctx, "stable", nil)
+		require.NoError(t, value.Error())
+		require.Equal(t, "value", value.AsString(""))
+	}
+}
+
+// blockingFetcher returns no data on its first fetch and blocks the later ones until release is closed
+type blockingFetcher struct {
+	fetches atomic.Int64
+	blocked chan struct{}
+	release chan struct{}
+}
+
+func (f *blockingFetcher) GetParameters(ctx context.Context) ([]types.Parameter, error) {
+	if f.fetches.Add(1) > 1 {
+		close(f.blocked)
+		<-f.release
+	}
+	return []types.Parameter{}, nil
+}
+
+func (f *blockingFetcher) GetExperiments(ctx context.Context) ([]types.Experiment, error) {
+	return []types.Experiment{}, nil
+}
+
+// closeRecordingStorage records whether it was closed
+type closeRecordingStorage struct {
+	Storage
+	closed atomic.Bool
+}
+
+func (s *closeRecordingStorage) Close(ctx context.Context) error {
+	s.closed.Store(true)
+	return s.Storage.Close(ctx)
+}
+
+func TestAuroraClientStopLeavesStorageOpenWhileRefreshing(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RefreshRate = 10 * time.Millisecond
+	cfg.Logger = logger.NewDefaultLogger(slog.LevelError + 4)
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	defer db.Close()
+	store := &closeRecordingStorage{Storage: storage.NewBadgerStorage(db, cfg.Logger)}
+	fetcher := &blockingFetcher{blocked: make(chan struct{}), release: make(chan struct{})}
+	c := NewAuroraClient(cfg, store, &reasonEngine{}, nil, fetcher)
+	require.NoError(t, c.Start(context.Background()))
+	defer func() {
+		close(fetcher.release)
+		<-c.(*AuroraClient).dispatchDone
+	}()
+	<-fetcher.blocked
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = c.Stop(ctx)
+	var sdkErr *errors.SDKError
+	require.ErrorAs(t, err, &sdkErr)
+	require.True(t, sdkErr.IsType(errors.ErrorTypeTimeoutError))
+	require.False(t, store.closed.Load(), "the refresh loop may still write to storage")
+
+	// Stopping again, as a deferred Close does, returns the same error instead of panicking
+	require.Equal(t, err, c.Stop(context.Background()))
+}
+
+func TestAuroraClientStopTwice(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RefreshRate = time.Hour
+	cfg.Logger = logger.NewDefaultLogger(slog.LevelError + 4)
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	store := &closeRecordingStorage{Storage: storage.NewBadgerStorage(db, cfg.Logger)}
+	c := NewAuroraClient(cfg, store, &reasonEngine{}, nil, &alternatingFetcher{})
+	require.NoError(t, c.Start(context.Background()))
+
+	require.NoError(t, c.Stop(context.Background()))
+	require.True(t, store.closed.Load())
+	require.NoError(t, c.Stop(context.Background()))
+}
@@ -3,14 +3,23 @@ package client
 import (
 	"context"
 	"sdk/types"
+	"time"
 )
 
 // Client interface defines the main SDK operations
 type Client interface {
 	Start(ctx context.Context) error
-	Stop()
+	Stop(ctx context.Context) error
+	Refresh(ctx context.Context) error
 	EvaluateParameter(ctx context.Context, parameterName string, attribute Attribute) RolloutValue
+	EvaluateParameterDetailed(ctx context.Context, parameterName string, attribute Attribute) (RolloutValue, types.EvaluationDetails)
+	EvaluateParameterTraced(ctx context.Context, parameterName string, attribute Attribute) (RolloutValue, types.EvaluationDetails)
+	EvaluateParameterTracedWith(ctx context.Context, parameter *types.Parameter, experiments []types.Experiment, attribute Attribute) (RolloutValue, types.EvaluationDetails)
+	EvaluateParameters(ctx context.Context, parameterNames []string, attribute Attribute) map[string]RolloutValue
+	EvaluateExperimentDetailed(ctx context.Context, experiment *types.Experiment, attribute Attribute, parameterName string) *types.ExperimentEvaluationResult
 	GetMetadata(ctx context.Context) (*types.MetadataResponse, error)
+	GetParameterDebug(ctx context.Context, parameterName string) (*types.Parameter, error)
+	SyncStatus() types.SyncStatus
 }
 
 // Attribute interface for dependency injection
@@ -27,6 +36,11 @@ type RolloutValue interface {
 	AsNumber(defaultValue float64) float64
 	AsInt(defaultValue int) int
 	AsBool(defaultValue bool) bool
+	AsTime(defaultValue time.Time) time.Time
+	AsEnum(defaultValue string) string
+	AsJSON(target interface{}) error
+	EnumOptions() []string
+	Reason() types.EvaluationReason
 	Raw() *string
 }
 
@@ -36,26 +50,57 @@ type DataFetcher interface {
 	GetExperiments(ctx context.Context) ([]types.Experiment, error)
 }
 
+// Dataset names shared by versioned data fetchers and storage
+const (
+	DatasetParameters  = "parameters"
+	DatasetExperiments = "experiments"
+)
+
+// VersionedDataFetcher is implemented by data fetchers that make conditional requests.
+// The version of a dataset identifies the last full response received for it, so it can be
+// stored next to the persisted data and restored after a restart.
+type VersionedDataFetcher interface {
+	DataVersion(dataset string) string
+	SetDataVersion(dataset string, version string)
+}
+
+// RecoverableStorage is implemented by storages that recover from failures of the underlying store.
+// Degraded reports that the storage runs in a fallback mode; NeedsRebuild reports that reads keep
+// failing and the store should be rebuilt before new data is written to it.
+type RecoverableStorage interface {
+	Degraded() bool
+	NeedsRebuild() bool
+	Rebuild(ctx context.Context) error
+}
+
 // Storage interface for data persistence
 type Storage interface {
 	PersistParameters(ctx context.Context, parameters []types.Parameter) error
 	GetParameterByName(ctx context.Context, name string) (types.Parameter, error)
+	GetParametersByNames(ctx context.Context, names []string) (map[string]types.Parameter, error)
 	PersistExperiments(ctx context.Context, experiments []types.Experiment) error
 	GetExperimentsByParameterName(ctx context.Context, parameterName string) ([]types.Experiment, error)
+	GetExperimentsByParameterNames(ctx context.Context, parameterNames []string) (map[string][]types.Experiment, error)
+	GetDataVersion(ctx context.Context, dataset string) (string, error)
+	PersistDataVersion(ctx context.Context, dataset string, version string) error
 	Close(ctx context.Context) error
 }
 
 // Engine interface for evaluation logic
 type Engine interface {
 	EvaluateParameter(parameter *types.Parameter, attribute Attribute) string
+	EvaluateParameterDetailed(parameter *types.Parameter, attribute Attribute) (string, types.EvaluationReason)
+	EvaluateParameterTraced(parameter *types.Parameter, attribute Attribute) (string, types.EvaluationReason, *types.EvaluationTrace)
 	EvaluateExperiment(experiment *types.Experiment, attribute Attribute, parameterName string) (string, types.ParameterDataType, bool)
 	EvaluateExperimentDetailed(experiment *types.Experiment, attribute Attribute, parameterName string) *types.ExperimentEvaluationResult
+	EvaluateAssignedExperiment(experiment *types.Experiment, attribute Attribute, parameterName string, variantID int) *types.ExperimentEvaluationResult
 }
 
 // EventTracker interface for event tracking
 type EventTracker interface {
 	TrackEvent(ctx context.Context, event types.EvaluationEvent)
+	TrackEvents(ctx context.Context, events []types.EvaluationEvent)
 	CreateParameterEvaluationEvent(parameterName string, attribute Attribute, rolloutValue *string, err error) types.EvaluationEvent
-	CreateExperimentEvaluationEvent(parameterName string, attribute Attribute, rolloutValue *string, err error, experimentID *int, experimentUUID *string, variantID *int, variantName *string) types.EvaluationEvent
-	Stop(ctx context.Context)
+	CreateExperimentEvaluationEvent(parameterName string, attribute Attribute, rolloutValue *string, err error, experimentID *int, experimentUUID *string, variantID *int, variantName *string, variantOverridden bool) types.EvaluationEvent
+	Stop(ctx context.Context) error
 }
@@ -2,129 +2,320 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
 	"sdk/pkg/errors"
 	"sdk/pkg/logger"
 	"sdk/types"
+	"sync"
+	"time"
 
+	"golang.org/x/sync/singleflight"
 	"resty.dev/v3"
 )
 
-// HTTPDataFetcher implements DataFetcher using HTTP
+// HTTPDataFetcher implements DataFetcher using HTTP.
+// It remembers the ETag or Last-Modified validator of each endpoint and sends it back on
+// the next request, so unchanged data comes back as a not modified error instead of a full payload.
+// Concurrent fetches of a dataset share a single upstream request and its result.
 type HTTPDataFetcher struct {
 	endpointURL string
+	apiKey      string
+	environment string
+	client      *resty.Client
+	retry       RetryPolicy
+	metrics     types.MetricsCollector
 	logger      logger.Logger
+	mu          sync.Mutex
+	validators  map[string]cacheValidator
+	// inflight deduplicates concurrent fetches, keyed by dataset name
+	inflight singleflight.Group
 }
 
-// NewHTTPDataFetcher creates a new HTTP data fetcher
-func NewHTTPDataFetcher(endpointURL string, logger logger.Logger) DataFetcher {
+// RetryPolicy controls how failed upstream requests are retried. Network errors, throttling and
+// 5xx responses are retried up to MaxRetries times, waiting Backoff doubled on every retry with jitter.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+// delay returns the wait before the given retry, counted from 0: the exponential backoff for the
+// retry, reduced by a random jitter of up to half of it so clients do not retry in lockstep
+func (p RetryPolicy) delay(retry int) time.Duration {
+	backoff := p.Backoff << retry
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff - rand.N(backoff/2+1)
+}
+
+// cacheValidator holds the validators returned with the last full response of an endpoint
+type cacheValidator struct {
+	etag         string
+	lastModified string
+}
+
+// datasetPaths maps dataset names to the SDK endpoints serving them
+var datasetPaths = map[string]string{
+	DatasetParameters:  "/api/v1/sdk/parameters",
+	DatasetExperiments: "/api/v1/sdk/experiments",
+}
+
+// NewHTTPDataFetcher creates a new HTTP data fetcher sending its requests through httpClient and
+// retrying failed requests following retry. It authenticates with apiKey when it is set, fetches the
+// data of the environment with key environment when that is set, and counts every failed request,
+// including the retried ones, in metrics.
+func NewHTTPDataFetcher(endpointURL string, apiKey string, environment string, httpClient *http.Client, retry RetryPolicy, metrics types.MetricsCollector, logger logger.Logger) DataFetcher {
 	return &HTTPDataFetcher{
 		endpointURL: endpointURL,
+		apiKey:      apiKey,
+		environment: environment,
+		client:      resty.NewWithClient(httpClient),
+		retry:       retry,
+		metrics:     metrics,
 		logger:      logger,
+		validators:  make(map[string]cacheValidator),
 	}
 }
 
-// GetParameters fetches parameters from the upstream service
+// GetParameters fetches parameters from the upstream service. A call made while another is in flight
+// waits for it and gets the same parameters or error, fetched with the context of the first call.
 func (f *HTTPDataFetcher) GetParameters(ctx context.Context) ([]types.Parameter, error) {
-	client := resty.New()
-	defer client.Close()
+	parameters, err, _ := f.inflight.Do(DatasetParameters, func() (interface{}, error) {
+		var res types.UpstreamParametersResponse
+		err := f.fetch(ctx, datasetPaths[DatasetParameters], &res)
+		if err != nil {
+			if !errors.IsNotModified(err) {
+				f.logger.ErrorContext(ctx, "failed to get parameters from upstream", "error", err)
+			}
+			return nil, err
+		}
+		return res.Parameters, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parameters.([]types.Parameter), nil
+}
 
-	var res types.UpstreamParametersResponse
-	response, err := client.R().
-		SetContext(ctx).
-		SetResult(&res).
-		SetBody(map[string]interface{}{}).
-		Post(fmt.Sprintf("%s/api/v1/sdk/parameters", f.endpointURL))
+// GetExperiments fetches experiments from the upstream service, sharing in-flight requests like GetParameters
+func (f *HTTPDataFetcher) GetExperiments(ctx context.Context) ([]types.Experiment, error) {
+	experiments, err, _ := f.inflight.Do(DatasetExperiments, func() (interface{}, error) {
+		var res types.UpstreamExperimentsResponse
+		err := f.fetch(ctx, datasetPaths[DatasetExperiments], &res)
+		if err != nil {
+			if !errors.IsNotModified(err) {
+				f.logger.ErrorContext(ctx, "failed to get experiments from upstream", "error", err)
+			}
+			return nil, err
+		}
+		return res.Experiments, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return experiments.([]types.Experiment), nil
+}
 
-	f.logger.Debug("parameters from upstream", "response", response)
+// DataVersion returns the ETag of the last full response for a dataset
+func (f *HTTPDataFetcher) DataVersion(dataset string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.validators[datasetPaths[dataset]].etag
+}
 
-	if err != nil {
-		f.logger.ErrorContext(ctx, "failed to get parameters from upstream", "error", err)
-		return nil, errors.NewNetworkError("get parameters from upstream", err)
+// SetDataVersion restores the ETag sent with the next request for a dataset
+func (f *HTTPDataFetcher) SetDataVersion(dataset string, version string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.validators[datasetPaths[dataset]] = cacheValidator{etag: version}
+}
+
+// fetch posts to an SDK endpoint and decodes the response into result, sending the
+// validators of the previous response so the server can answer 304 Not Modified
+func (f *HTTPDataFetcher) fetch(ctx context.Context, path string, result interface{}) error {
+	for retry := 0; ; retry++ {
+		start := time.Now()
+		err := f.fetchOnce(ctx, path, result)
+		f.metrics.ObserveFetch("http", path, fetchStatus(err), time.Since(start))
+		if err != nil && !errors.IsNotModified(err) {
+			f.metrics.IncFetchError(string(errors.TypeOf(err)))
+		}
+		if err == nil || retry >= f.retry.MaxRetries || !errors.IsRetryable(err) {
+			return err
+		}
+
+		// Give up early rather than wait past the deadline of the request context
+		delay := f.retry.delay(retry)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		f.logger.WarnContext(ctx, "upstream request failed, retrying", "path", path, "retry", retry+1, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
 	}
+}
 
-	castResp := response.Result().(*types.UpstreamParametersResponse)
-	return castResp.Parameters, nil
+// fetchStatus returns the status of a request reported in metrics
+func fetchStatus(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.IsNotModified(err):
+		return "not_modified"
+	case errors.TypeOf(err) == "":
+		return "error"
+	default:
+		return string(errors.TypeOf(err))
+	}
 }
 
-// GetExperiments fetches experiments from the upstream service
-func (f *HTTPDataFetcher) GetExperiments(ctx context.Context) ([]types.Experiment, error) {
-	client := resty.New()
-	defer client.Close()
+// requestBody returns the body of SDK endpoint requests, naming the environment to fetch when there is one
+func (f *HTTPDataFetcher) requestBody() map[string]interface{} {
+	if f.environment == "" {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"environment": f.environment}
+}
 
-	var res types.UpstreamExperimentsResponse
-	response, err := client.R().
+// fetchOnce sends a single request to an SDK endpoint and decodes its response into result
+func (f *HTTPDataFetcher) fetchOnce(ctx context.Context, path string, result interface{}) error {
+	request := f.client.R().
 		SetContext(ctx).
-		SetResult(&res).
-		SetBody(map[string]interface{}{}).
-		Post(fmt.Sprintf("%s/api/v1/sdk/experiments", f.endpointURL))
+		SetResult(result).
+		SetBody(f.requestBody())
+	if f.apiKey != "" {
+		request.SetHeader(types.APIKeyHeader, f.apiKey)
+	}
 
-	f.logger.Debug("experiments from upstream", "response", response)
+	f.mu.Lock()
+	validator := f.validators[path]
+	f.mu.Unlock()
+	if validator.etag != "" {
+		request.SetHeader("If-None-Match", validator.etag)
+	} else if validator.lastModified != "" {
+		request.SetHeader("If-Modified-Since", validator.lastModified)
+	}
 
+	response, err := request.Post(f.endpointURL + path)
+	f.logger.Debug("response from upstream", "path", path, "response", response)
 	if err != nil {
-		f.logger.ErrorContext(ctx, "failed to get experiments from upstream", "error", err)
-		return nil, errors.NewNetworkError("get experiments from upstream", err)
+		return errors.NewNetworkError(fmt.Sprintf("post %s", path), err)
 	}
 
-	castResp := response.Result().(*types.UpstreamExperimentsResponse)
-	return castResp.Experiments, nil
+	if response.StatusCode() == http.StatusNotModified {
+		return errors.NewNotModifiedError(path)
+	}
+	if response.StatusCode() == http.StatusUnauthorized {
+		return errors.NewConfigurationError("upstream rejected the api key", fmt.Errorf("HTTP %d: %s", response.StatusCode(), response.String()))
+	}
+	if response.StatusCode() == http.StatusTooManyRequests {
+		return errors.NewThrottledError(fmt.Sprintf("post %s", path), fmt.Errorf("HTTP %d: %s", response.StatusCode(), response.String()))
+	}
+	if response.StatusCode() >= 500 {
+		return errors.NewNetworkError(fmt.Sprintf("post %s", path), fmt.Errorf("HTTP %d: %s", response.StatusCode(), response.String()))
+	}
+	if response.StatusCode() >= 400 {
+		return errors.NewRequestRejectedError(fmt.Sprintf("post %s", path), fmt.Errorf("HTTP %d: %s", response.StatusCode(), response.String()))
+	}
+
+	f.mu.Lock()
+	f.validators[path] = cacheValidator{
+		etag:         response.Header().Get("ETag"),
+		lastModified: response.Header().Get("Last-Modified"),
+	}
+	f.mu.Unlock()
+	return nil
 }
 
+// Object keys written by the Aurora sync workers
+const (
+	s3ParametersKey  = "parameters.json"
+	s3ExperimentsKey = "experiments.json"
+)
+
 // S3DataFetcher implements DataFetcher using S3
 type S3DataFetcher struct {
 	s3Client    S3Client
 	bucketName  string
+	metrics     types.MetricsCollector
 	logger      logger.Logger
 	httpFetcher DataFetcher // Fallback to HTTP
 }
 
-// S3Client interface for dependency injection
+// S3Client interface for dependency injection.
+// GetObject returns the object body, or an SDK error classifying the failure
+// (object not found, throttled or network) so the fetcher can decide whether to fall back.
 type S3Client interface {
-	GetObject(ctx context.Context, input interface{}) (interface{}, error)
+	GetObject(ctx context.Context, bucket string, key string) (io.ReadCloser, error)
 }
 
-// NewS3DataFetcher creates a new S3 data fetcher
-func NewS3DataFetcher(s3Client S3Client, bucketName string, logger logger.Logger, httpFetcher DataFetcher) DataFetcher {
+// NewS3DataFetcher creates a new S3 data fetcher counting failed downloads in metrics
+func NewS3DataFetcher(s3Client S3Client, bucketName string, metrics types.MetricsCollector, logger logger.Logger, httpFetcher DataFetcher) DataFetcher {
 	return &S3DataFetcher{
 		s3Client:    s3Client,
 		bucketName:  bucketName,
+		metrics:     metrics,
 		logger:      logger,
 		httpFetcher: httpFetcher,
 	}
 }
 
-// GetParameters fetches parameters from S3 with HTTP fallback
+// GetParameters fetches parameters from S3, falling back to HTTP on retryable errors
 func (f *S3DataFetcher) GetParameters(ctx context.Context) ([]types.Parameter, error) {
 	// Try S3 first
-	parameters, err := f.getParametersFromS3(ctx)
+	var parameters []types.Parameter
+	err := f.getObject(ctx, s3ParametersKey, &parameters)
 	if err != nil {
+		if !errors.IsRetryable(err) {
+			f.logger.ErrorContext(ctx, "failed to get parameters from S3", "error", err)
+			return nil, err
+		}
 		f.logger.WarnContext(ctx, "failed to get parameters from S3, falling back to HTTP", "error", err)
 		return f.httpFetcher.GetParameters(ctx)
 	}
 	return parameters, nil
 }
 
-// GetExperiments fetches experiments from S3 with HTTP fallback
+// GetExperiments fetches experiments from S3, falling back to HTTP on retryable errors
 func (f *S3DataFetcher) GetExperiments(ctx context.Context) ([]types.Experiment, error) {
 	// Try S3 first
-	experiments, err := f.getExperimentsFromS3(ctx)
+	var experiments []types.Experiment
+	err := f.getObject(ctx, s3ExperimentsKey, &experiments)
 	if err != nil {
+		if !errors.IsRetryable(err) {
+			f.logger.ErrorContext(ctx, "failed to get experiments from S3", "error", err)
+			return nil, err
+		}
 		f.logger.WarnContext(ctx, "failed to get experiments from S3, falling back to HTTP", "error", err)
 		return f.httpFetcher.GetExperiments(ctx)
 	}
 	return experiments, nil
 }
 
-// getParametersFromS3 fetches parameters from S3
-func (f *S3DataFetcher) getParametersFromS3(ctx context.Context) ([]types.Parameter, error) {
-	// This would be implemented with actual S3 client calls
-	// For now, return an error to trigger fallback
-	return nil, errors.NewNetworkError("S3 not implemented", fmt.Errorf("S3 data fetcher not implemented"))
-}
+// getObject downloads an object from the bucket and decodes its JSON body into v
+func (f *S3DataFetcher) getObject(ctx context.Context, key string, v interface{}) (err error) {
+	start := time.Now()
+	defer func() { f.metrics.ObserveFetch("s3", key, fetchStatus(err), time.Since(start)) }()
 
-// getExperimentsFromS3 fetches experiments from S3
-func (f *S3DataFetcher) getExperimentsFromS3(ctx context.Context) ([]types.Experiment, error) {
-	// This would be implemented with actual S3 client calls
-	// For now, return an error to trigger fallback
-	return nil, errors.NewNetworkError("S3 not implemented", fmt.Errorf("S3 data fetcher not implemented"))
+	body, err := f.s3Client.GetObject(ctx, f.bucketName, key)
+	if err != nil {
+		f.metrics.IncFetchError(string(errors.TypeOf(err)))
+		return err
+	}
+	defer body.Close()
+
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		f.metrics.IncFetchError(string(errors.ErrorTypeValidationError))
+		return errors.NewValidationError(fmt.Sprintf("invalid %s in bucket %s", key, f.bucketName), err)
+	}
+	return nil
 }
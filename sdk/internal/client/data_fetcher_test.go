@@ -0,0 +1,293 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sdk/pkg/errors"
+	"sdk/pkg/logger"
+	"sdk/types"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeS3Client struct {
+	objects map[string]string
+	err     error
+	keys    []string
+}
+
+func (f *fakeS3Client) GetObject(ctx context.Context, bucket string, key string) (io.ReadCloser, error) {
+	f.keys = append(f.keys, bucket+"/"+key)
+	if f.err != nil {
+		return nil, f.err
+	}
+	body, ok := f.objects[key]
+	if !ok {
+		return nil, errors.NewObjectNotFoundError(key, nil)
+	}
+	return io.NopCloser(strings.NewReader(body)), nil
+}
+
+type fakeHTTPFetcher struct {
+	calls int
+}
+
+func (f *fakeHTTPFetcher) GetParameters(ctx context.Context) ([]types.Parameter, error) {
+	f.calls++
+	return []types.Parameter{{Name: "from_http"}}, nil
+}
+
+func (f *fakeHTTPFetcher) GetExperiments(ctx context.Context) ([]types.Experiment, error) {
+	f.calls++
+	return []types.Experiment{{Name: "from_http"}}, nil
+}
+
+func TestS3DataFetcher(t *testing.T) {
+	log := logger.NewDefaultLogger(slog.LevelError + 4)
+
+	t.Run("prefers S3", func(t *testing.T) {
+		s3Client := &fakeS3Client{objects: map[string]string{
+			"parameters.json":  `[{"name":"from_s3","dataType":"string"}]`,
+			"experiments.json": `[{"name":"from_s3"}]`,
+		}}
+		httpFetcher := &fakeHTTPFetcher{}
+		fetcher := NewS3DataFetcher(s3Client, "bucket", types.NoopMetricsCollector{}, log, httpFetcher)
+
+		parameters, err := fetcher.GetParameters(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "from_s3", parameters[0].Name)
+
+		experiments, err := fetcher.GetExperiments(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "from_s3", experiments[0].Name)
+
+		require.Equal(t, []string{"bucket/parameters.json", "bucket/experiments.json"}, s3Client.keys)
+		require.Zero(t, httpFetcher.calls)
+	})
+
+	t.Run("falls back to HTTP on throttling", func(t *testing.T) {
+		s3Client := &fakeS3Client{err: errors.NewThrottledError("get S3 object", nil)}
+		httpFetcher := &fakeHTTPFetcher{}
+		fetcher := NewS3DataFetcher(s3Client, "bucket", types.NoopMetricsCollector{}, log, httpFetcher)
+
+		parameters, err := fetcher.GetParameters(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "from_http", parameters[0].Name)
+		require.Equal(t, 1, httpFetcher.calls)
+	})
+
+	t.Run("does not fall back on missing object", func(t *testing.T) {
+		s3Client := &fakeS3Client{objects: map[string]string{}}
+		httpFetcher := &fakeHTTPFetcher{}
+		fetcher := NewS3DataFetcher(s3Client, "bucket", types.NoopMetricsCollector{}, log, httpFetcher)
+
+		_, err := fetcher.GetExperiments(context.Background())
+		var sdkErr *errors.SDKError
+		require.ErrorAs(t, err, &sdkErr)
+		require.True(t, sdkErr.IsType(errors.ErrorTypeObjectNotFound))
+		require.Zero(t, httpFetcher.calls)
+	})
+
+	t.Run("does not fall back on invalid body", func(t *testing.T) {
+		s3Client := &fakeS3Client{objects: map[string]string{"parameters.json": `{`}}
+		httpFetcher := &fakeHTTPFetcher{}
+		fetcher := NewS3DataFetcher(s3Client, "bucket", types.NoopMetricsCollector{}, log, httpFetcher)
+
+		_, err := fetcher.GetParameters(context.Background())
+		require.Error(t, err)
+		require.Zero(t, httpFetcher.calls)
+	})
+}
+
+func TestHTTPDataFetcherConditionalFetch(t *testing.T) {
+	const etag = `"1700000000-2"`
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"parameters":[{"name":"from_http","dataType":"string"}]}`)
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPDataFetcher(server.URL, "", "", server.Client(), RetryPolicy{}, types.NoopMetricsCollector{}, logger.NewDefaultLogger(slog.LevelError+4))
+
+	parameters, err := fetcher.GetParameters(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "from_http", parameters[0].Name)
+
+	_, err = fetcher.GetParameters(context.Background())
+	require.True(t, errors.IsNotModified(err))
+	require.Equal(t, []string{"", etag}, ifNoneMatch)
+}
+
+func TestHTTPDataFetcherSharesInFlightRequests(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPDataFetcher(server.URL, "", "", server.Client(), RetryPolicy{}, types.NoopMetricsCollector{}, logger.NewDefaultLogger(slog.LevelError+4))
+
+	const callers = 5
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			_, err := fetcher.GetParameters(context.Background())
+			errs <- err
+		}()
+	}
+	require.Eventually(t, func() bool { return requests.Load() == 1 }, time.Second, time.Millisecond)
+	// Let the other callers join the request in flight before it completes
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	// Every caller gets the error of the shared request
+	for i := 0; i < callers; i++ {
+		require.Error(t, <-errs)
+	}
+	require.Equal(t, int32(1), requests.Load())
+
+	// The dataset is fetched again once the shared request completed
+	_, err := fetcher.GetParameters(context.Background())
+	require.Error(t, err)
+	require.Equal(t, int32(2), requests.Load())
+}
+
+func TestHTTPDataFetcherSendsAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(types.APIKeyHeader) != "aurora_valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":"Unauthorized","message":"invalid api key"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"parameters":[{"name":"from_http","dataType":"string"}]}`)
+	}))
+	defer server.Close()
+
+	log := logger.NewDefaultLogger(slog.LevelError + 4)
+	parameters, err := NewHTTPDataFetcher(server.URL, "aurora_valid", "", server.Client(), RetryPolicy{}, types.NoopMetricsCollector{}, log).GetParameters(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "from_http", parameters[0].Name)
+
+	_, err = NewHTTPDataFetcher(server.URL, "aurora_revoked", "", server.Client(), RetryPolicy{}, types.NoopMetricsCollector{}, log).GetParameters(context.Background())
+	var sdkErr *errors.SDKError
+	require.ErrorAs(t, err, &sdkErr)
+	require.True(t, sdkErr.IsType(errors.ErrorTypeConfigurationError))
+}
+
+func TestHTTPDataFetcherRequestsEnvironment(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, strings.TrimSpace(string(body)))
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"parameters":[]}`)
+	}))
+	defer server.Close()
+
+	log := logger.NewDefaultLogger(slog.LevelError + 4)
+	_, err := NewHTTPDataFetcher(server.URL, "", "prod", server.Client(), RetryPolicy{}, types.NoopMetricsCollector{}, log).GetParameters(context.Background())
+	require.NoError(t, err)
+	_, err = NewHTTPDataFetcher(server.URL, "", "", server.Client(), RetryPolicy{}, types.NoopMetricsCollector{}, log).GetParameters(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{`{"environment":"prod"}`, `{}`}, bodies)
+}
+
+func TestHTTPDataFetcherRetriesTransientFailures(t *testing.T) {
+	var requests int
+	statuses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= len(statuses) {
+			w.WriteHeader(statuses[requests-1])
+			return
+		}
+		if r.URL.Path == "/api/v1/sdk/experiments" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"parameters":[{"name":"from_http","dataType":"string"}]}`)
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPDataFetcher(server.URL, "", "", server.Client(), RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}, types.NoopMetricsCollector{}, logger.NewDefaultLogger(slog.LevelError+4))
+	parameters, err := fetcher.GetParameters(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "from_http", parameters[0].Name)
+	require.Equal(t, 3, requests)
+
+	// Rejected requests are not retried
+	_, err = fetcher.GetExperiments(context.Background())
+	var sdkErr *errors.SDKError
+	require.ErrorAs(t, err, &sdkErr)
+	require.True(t, sdkErr.IsType(errors.ErrorTypeRequestRejected))
+	require.Equal(t, 4, requests)
+}
+
+func TestS3DataFetcherFallsBackToHTTPServer(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"experiments":[{"name":"from_server"}]}`)
+	}))
+	defer server.Close()
+
+	log := logger.NewDefaultLogger(slog.LevelError + 4)
+	s3Client := &fakeS3Client{err: errors.NewNetworkError("get S3 object experiments.json", io.ErrUnexpectedEOF)}
+	fetcher := NewS3DataFetcher(s3Client, "bucket", types.NoopMetricsCollector{}, log, NewHTTPDataFetcher(server.URL, "", "", server.Client(), RetryPolicy{}, types.NoopMetricsCollector{}, log))
+
+	experiments, err := fetcher.GetExperiments(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "from_server", experiments[0].Name)
+	require.Equal(t, []string{"bucket/experiments.json"}, s3Client.keys)
+	require.Equal(t, []string{"/api/v1/sdk/experiments"}, paths)
+}
+
+func TestFileDataFetcher(t *testing.T) {
+	log := logger.NewDefaultLogger(slog.LevelError + 4)
+	dir := t.TempDir()
+	fetcher := NewFileDataFetcher(dir, types.NoopMetricsCollector{}, log)
+
+	_, err := fetcher.GetParameters(context.Background())
+	var sdkErr *errors.SDKError
+	require.ErrorAs(t, err, &sdkErr)
+	require.True(t, sdkErr.IsType(errors.ErrorTypeObjectNotFound))
+	require.Contains(t, err.Error(), filepath.Join(dir, "parameters.json"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "parameters.json"), []byte(`[{"name":"from_file","dataType":"string"}]`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "experiments.json"), []byte(`[{"name":"from_file"}]`), 0o644))
+
+	parameters, err := fetcher.GetParameters(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "from_file", parameters[0].Name)
+
+	experiments, err := fetcher.GetExperiments(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "from_file", experiments[0].Name)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "parameters.json"), []byte(`[{"name":"updated","dataType":"string"}]`), 0o644))
+	parameters, err = fetcher.GetParameters(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "updated", parameters[0].Name)
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sdk/pkg/errors"
+	"sdk/pkg/logger"
+	"sdk/types"
+	"time"
+)
+
+// FileDataFetcher implements DataFetcher by reading the files written by the Aurora sync
+// workers from a local directory. Files are read again on every fetch, so editing them
+// is picked up by the next refresh.
+type FileDataFetcher struct {
+	dir     string
+	metrics types.MetricsCollector
+	logger  logger.Logger
+}
+
+// NewFileDataFetcher creates a data fetcher reading parameters.json and experiments.json from dir,
+// counting files that cannot be read in metrics
+func NewFileDataFetcher(dir string, metrics types.MetricsCollector, logger logger.Logger) DataFetcher {
+	return &FileDataFetcher{
+		dir:     dir,
+		metrics: metrics,
+		logger:  logger,
+	}
+}
+
+// GetParameters reads parameters from the local directory
+func (f *FileDataFetcher) GetParameters(ctx context.Context) ([]types.Parameter, error) {
+	var parameters []types.Parameter
+	if err := f.readFile(s3ParametersKey, &parameters); err != nil {
+		f.metrics.IncFetchError(string(errors.TypeOf(err)))
+		f.logger.ErrorContext(ctx, "failed to get parameters from local path", "error", err)
+		return nil, err
+	}
+	return parameters, nil
+}
+
+// GetExperiments reads experiments from the local directory
+func (f *FileDataFetcher) GetExperiments(ctx context.Context) ([]types.Experiment, error) {
+	var experiments []types.Experiment
+	if err := f.readFile(s3ExperimentsKey, &experiments); err != nil {
+		f.metrics.IncFetchError(string(errors.TypeOf(err)))
+		f.logger.ErrorContext(ctx, "failed to get experiments from local path", "error", err)
+		return nil, err
+	}
+	return experiments, nil
+}
+
+// readFile decodes the JSON content of a file in the directory into v
+func (f *FileDataFetcher) readFile(name string, v interface{}) (err error) {
+	start := time.Now()
+	defer func() { f.metrics.ObserveFetch("file", name, fetchStatus(err), time.Since(start)) }()
+
+	path := filepath.Join(f.dir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if stderrors.Is(err, fs.ErrNotExist) {
+			return errors.NewObjectNotFoundError(path, err)
+		}
+		return errors.NewStorageError(fmt.Sprintf("read %s", path), err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.NewValidationError(fmt.Sprintf("invalid %s in local path %s", name, f.dir), err)
+	}
+	return nil
+}
@@ -1,15 +1,22 @@
 package client
 
 import (
+	stderrors "errors"
+	"fmt"
+	"sdk/pkg/errors"
 	"sdk/types"
+	"slices"
 	"strconv"
+	"time"
 )
 
 // RolloutValueImpl implements the RolloutValue interface
 type RolloutValueImpl struct {
-	value    *string
-	DataType types.ParameterDataType
-	err      error
+	value       *string
+	DataType    types.ParameterDataType
+	enumOptions []string
+	reason      types.EvaluationReason
+	err         error
 }
 
 // NewRolloutValue creates a new RolloutValue instance
@@ -17,24 +24,51 @@ func NewRolloutValue(value *string, dataType types.ParameterDataType) RolloutVal
 	return &RolloutValueImpl{
 		value:    value,
 		DataType: dataType,
+		reason:   types.EvaluationReasonDefault,
 		err:      nil,
 	}
 }
 
+// NewEnumRolloutValue creates a new enum RolloutValue that is validated against its options
+func NewEnumRolloutValue(value *string, enumOptions []string) RolloutValue {
+	return &RolloutValueImpl{
+		value:       value,
+		DataType:    types.ParameterDataTypeEnum,
+		enumOptions: enumOptions,
+		reason:      types.EvaluationReasonDefault,
+		err:         nil,
+	}
+}
+
 // NewRolloutValueWithError creates a new RolloutValue with an error
 func NewRolloutValueWithError(err error) RolloutValue {
 	return &RolloutValueImpl{
 		value:    nil,
 		DataType: "",
+		reason:   ReasonForError(err),
 		err:      err,
 	}
 }
 
+// ReasonForError returns the evaluation reason of a value that failed with err
+func ReasonForError(err error) types.EvaluationReason {
+	var sdkErr *errors.SDKError
+	if stderrors.As(err, &sdkErr) && sdkErr.IsType(errors.ErrorTypeParameterNotFound) {
+		return types.EvaluationReasonParameterNotFound
+	}
+	return types.EvaluationReasonDefault
+}
+
 // HasError returns true if the RolloutValue contains an error
 func (rv *RolloutValueImpl) HasError() bool {
 	return rv.err != nil
 }
 
+// Reason returns why the evaluation returned this value
+func (rv *RolloutValueImpl) Reason() types.EvaluationReason {
+	return rv.reason
+}
+
 // Error returns the error if present
 func (rv *RolloutValueImpl) Error() error {
 	return rv.err
@@ -42,6 +76,9 @@ func (rv *RolloutValueImpl) Error() error {
 
 // AsString returns the value as a string, or defaultValue if conversion fails or there's an error
 func (rv *RolloutValueImpl) AsString(defaultValue string) string {
+	if rv.DataType == types.ParameterDataTypeEnum {
+		return rv.AsEnum(defaultValue)
+	}
 	if rv.HasError() || rv.DataType != types.ParameterDataTypeString {
 		return defaultValue
 	}
@@ -96,6 +133,57 @@ func (rv *RolloutValueImpl) AsBool(defaultValue bool) bool {
 	return value
 }
 
+// AsTime returns the value as a time.Time, or defaultValue if conversion fails or there's an error
+func (rv *RolloutValueImpl) AsTime(defaultValue time.Time) time.Time {
+	if rv.HasError() || rv.DataType != types.ParameterDataTypeDate {
+		return defaultValue
+	}
+	if rv.value == nil {
+		return defaultValue
+	}
+	value, err := types.ParseDate(*rv.value)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// AsEnum returns the value of an enum parameter, or defaultValue if the value is not one of its options or there's an error
+func (rv *RolloutValueImpl) AsEnum(defaultValue string) string {
+	if rv.HasError() || rv.DataType != types.ParameterDataTypeEnum {
+		return defaultValue
+	}
+	if rv.value == nil {
+		return defaultValue
+	}
+	if !slices.Contains(rv.enumOptions, *rv.value) {
+		return defaultValue
+	}
+	return *rv.value
+}
+
+// AsJSON unmarshals the value of a json parameter into target
+func (rv *RolloutValueImpl) AsJSON(target interface{}) error {
+	if rv.HasError() {
+		return rv.err
+	}
+	if rv.DataType != types.ParameterDataTypeJSON {
+		return errors.NewValidationError(fmt.Sprintf("value has data type %s, not json", rv.DataType), nil)
+	}
+	if rv.value == nil {
+		return errors.NewValidationError("json value is empty", nil)
+	}
+	if err := types.ParseJSON(*rv.value, target); err != nil {
+		return errors.NewValidationError("failed to unmarshal json value", err)
+	}
+	return nil
+}
+
+// EnumOptions returns the allowed options of an enum value
+func (rv *RolloutValueImpl) EnumOptions() []string {
+	return rv.enumOptions
+}
+
 // Raw returns the raw string value
 func (rv *RolloutValueImpl) Raw() *string {
 	return rv.value
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"sdk/pkg/errors"
+	"sdk/types"
+	"strings"
+	"time"
+)
+
+const (
+	// streamPath is the SDK endpoint streaming change events
+	streamPath = "/api/v1/sdk/stream"
+	// streamIdleTimeout drops a stream that sent nothing, not even the server's keep-alive ping
+	streamIdleTimeout = 90 * time.Second
+	// streamMinBackoff and streamMaxBackoff bound the wait before reconnecting a dropped stream
+	streamMinBackoff = time.Second
+	streamMaxBackoff = time.Minute
+)
+
+// stream keeps the change stream open until the client stops, persisting the latest data whenever a
+// change is announced. A dropped stream is reconnected with backoff while the refresh loop keeps polling.
+func (c *AuroraClient) stream(ctx context.Context) {
+	defer close(c.streamDone)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	backoff := streamMinBackoff
+	for {
+		connected, err := c.readStream(ctx)
+		if ctx.Err() != nil {
+			c.logger.Info("change stream stopped")
+			return
+		}
+		if connected {
+			backoff = streamMinBackoff
+		}
+		c.logger.WarnContext(ctx, "change stream dropped, polling until it reconnects", "error", err, "retryIn", backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, streamMaxBackoff)
+	}
+}
+
+// readStream connects to the change stream and persists the latest data on every change event until
+// the stream ends. It reports whether the connection was established.
+func (c *AuroraClient) readStream(ctx context.Context) (bool, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	idle := time.AfterFunc(streamIdleTimeout, cancel)
+	defer idle.Stop()
+
+	request, err := http.NewRequestWithContext(streamCtx, http.MethodGet, c.config.EndpointURL+streamPath, nil)
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Accept", "text/event-stream")
+	if c.config.APIKey != "" {
+		request.Header.Set(types.APIKeyHeader, c.config.APIKey)
+	}
+
+	// The stream stays open, so the request timeout of the configured client must not apply
+	httpClient := *c.config.HTTPClient
+	httpClient.Timeout = 0
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return false, errors.NewNetworkError(fmt.Sprintf("get %s", streamPath), err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return false, errors.NewNetworkError(fmt.Sprintf("get %s", streamPath), fmt.Errorf("HTTP %d", response.StatusCode))
+	}
+
+	// Changes made while the stream was down were not announced
+	c.logger.Info("change stream connected")
+	c.refreshOnChange(ctx)
+
+	var event string
+	lines := bufio.NewScanner(response.Body)
+	for lines.Scan() {
+		idle.Reset(streamIdleTimeout)
+		line := lines.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case line == "":
+			if event == "change" {
+				c.refreshOnChange(ctx)
+			}
+			event = ""
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return true, err
+	}
+	return true, fmt.Errorf("stream closed by the server")
+}
+
+// refreshOnChange persists the latest data after a change was announced
+func (c *AuroraClient) refreshOnChange(ctx context.Context) {
+	if err := c.persist(ctx); err != nil {
+		c.logger.ErrorContext(ctx, "failed to refresh data after a change", "error", err)
+	}
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"sdk/types"
+	"strconv"
 	"time"
 
 	"log/slog"
@@ -15,27 +19,55 @@ type Config struct {
 	EndpointURL  string
 	S3BucketName string
 	ServiceName  string
+	APIKey       string
+	Environment  string
 
 	// Storage configuration
-	InMemoryOnly bool
-	Path         string
+	InMemoryOnly    bool
+	Path            string
+	StorageFallback bool
+	// StoragePrefix namespaces the storage keys of the client; empty keeps the unprefixed keys
+	StoragePrefix string
 
 	// S3 configuration
 	EnableS3 bool
 	S3Client *s3.Client
 
+	// HTTP client used to call the Aurora backend, the timeout overriding its own, and how its failed
+	// requests are retried
+	HTTPClient   *http.Client
+	HTTPTimeout  time.Duration
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	// Local file configuration, takes precedence over HTTP and S3 when set
+	LocalPath string
+	// Directory holding parameters.json and experiments.json loaded into storage when the client starts
+	BootstrapPath string
+
 	// Refresh configuration
-	RefreshRate time.Duration
+	RefreshRate    time.Duration
+	Streaming      bool
+	DisableRefresh bool
 
 	// Logging configuration
 	LogLevel slog.Level
 	Logger   Logger
 
+	// Evaluation configuration
+	AllowVariantOverride bool
+	AssignmentStore      types.AssignmentStore
+	// NumberEpsilon is the tolerance of number equals and not_equals conditions; 0 compares exactly
+	NumberEpsilon float64
+	// Defaults holds the fallback value of parameters, returned when they cannot be evaluated
+	Defaults map[string]interface{}
+
 	// Event tracking configuration
 	BatchConfig types.BatchConfig
 
 	// Callback configuration
 	OnEvaluate func(source string, parameterName string, attribute Attribute, rolloutValueRaw *string, err error)
+	Metrics    types.MetricsCollector
 }
 
 // Logger interface for dependency injection
@@ -59,11 +91,16 @@ type Attribute interface {
 // DefaultConfig returns a configuration with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
-		InMemoryOnly: false,
-		Path:         "/sdk-dump",
-		EnableS3:     true,
-		RefreshRate:  1 * time.Minute,
-		LogLevel:     slog.LevelDebug,
+		InMemoryOnly:    false,
+		Path:            "/sdk-dump",
+		StorageFallback: true,
+		EnableS3:        true,
+		RefreshRate:     1 * time.Minute,
+		LogLevel:        slog.LevelDebug,
+		HTTPClient:      &http.Client{Timeout: 10 * time.Second}, // a hung backend must not block refreshes forever
+		MaxRetries:      2,                                       // 3 attempts in total
+		RetryBackoff:    200 * time.Millisecond,
+		Metrics:         types.NoopMetricsCollector{},
 		BatchConfig: types.BatchConfig{
 			MaxSize:     100,              // 100 events per batch
 			MaxBytes:    1048576,          // 1MB per batch
@@ -76,14 +113,29 @@ func DefaultConfig() *Config {
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.EndpointURL == "" {
+	if c.EndpointURL == "" && !c.Offline() {
 		return NewValidationError("endpoint URL is required", nil)
 	}
 	if c.ServiceName == "" {
 		return NewValidationError("service name is required", nil)
 	}
-	if c.RefreshRate <= 0 {
-		return NewValidationError("refresh rate must be positive", nil)
+	if c.HTTPClient == nil {
+		return NewValidationError("http client is required", nil)
+	}
+	if c.HTTPTimeout < 0 {
+		return NewValidationError("http timeout must not be negative", nil)
+	}
+	if c.MaxRetries < 0 {
+		return NewValidationError("max retries must not be negative", nil)
+	}
+	if c.RetryBackoff < 0 {
+		return NewValidationError("retry backoff must not be negative", nil)
+	}
+	if c.RefreshRate < 0 {
+		return NewValidationError("refresh rate must not be negative", nil)
+	}
+	if c.NumberEpsilon < 0 {
+		return NewValidationError("number epsilon must not be negative", nil)
 	}
 	if c.BatchConfig.MaxSize <= 0 {
 		return NewValidationError("batch max size must be positive", nil)
@@ -94,9 +146,57 @@ func (c *Config) Validate() error {
 	if c.BatchConfig.MaxWaitTime <= 0 {
 		return NewValidationError("batch max wait time must be positive", nil)
 	}
+	for parameterName, value := range c.Defaults {
+		if _, err := ParseDefaultValue(value); err != nil {
+			return NewValidationError(fmt.Sprintf("invalid default for parameter %s", parameterName), err)
+		}
+	}
 	return nil
 }
 
+// Offline reports whether the client runs from its bootstrap files alone, without an Aurora backend
+// to refresh data from or send evaluation events to
+func (c *Config) Offline() bool {
+	return c.EndpointURL == "" && c.BootstrapPath != "" && c.DisableRefresh
+}
+
+// DefaultValue is a registered parameter default encoded like the values fetched from the backend
+type DefaultValue struct {
+	Value    string
+	DataType types.ParameterDataType
+}
+
+// ParseDefaultValue encodes a registered default and infers its data type: strings, booleans,
+// numbers and times map to their data types, and any other value is encoded as json.
+func ParseDefaultValue(value interface{}) (DefaultValue, error) {
+	switch v := value.(type) {
+	case nil:
+		return DefaultValue{}, fmt.Errorf("default value must not be nil")
+	case string:
+		return DefaultValue{Value: v, DataType: types.ParameterDataTypeString}, nil
+	case bool:
+		return DefaultValue{Value: strconv.FormatBool(v), DataType: types.ParameterDataTypeBoolean}, nil
+	case int:
+		return DefaultValue{Value: strconv.Itoa(v), DataType: types.ParameterDataTypeNumber}, nil
+	case int32:
+		return DefaultValue{Value: strconv.FormatInt(int64(v), 10), DataType: types.ParameterDataTypeNumber}, nil
+	case int64:
+		return DefaultValue{Value: strconv.FormatInt(v, 10), DataType: types.ParameterDataTypeNumber}, nil
+	case float32:
+		return DefaultValue{Value: strconv.FormatFloat(float64(v), 'f', -1, 32), DataType: types.ParameterDataTypeNumber}, nil
+	case float64:
+		return DefaultValue{Value: strconv.FormatFloat(v, 'f', -1, 64), DataType: types.ParameterDataTypeNumber}, nil
+	case time.Time:
+		return DefaultValue{Value: v.Format(time.RFC3339), DataType: types.ParameterDataTypeDate}, nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return DefaultValue{}, err
+		}
+		return DefaultValue{Value: string(encoded), DataType: types.ParameterDataTypeJSON}, nil
+	}
+}
+
 // NewValidationError creates a validation error
 func NewValidationError(message string, cause error) error {
 	// This will be replaced with the proper error type from pkg/errors
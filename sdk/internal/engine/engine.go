@@ -8,10 +8,13 @@ import (
 type Engine interface {
 	// Parameter evaluation
 	EvaluateParameter(parameter *types.Parameter, attribute Attribute) string
+	EvaluateParameterDetailed(parameter *types.Parameter, attribute Attribute) (string, types.EvaluationReason)
+	EvaluateParameterTraced(parameter *types.Parameter, attribute Attribute) (string, types.EvaluationReason, *types.EvaluationTrace)
 
 	// Experiment evaluation
 	EvaluateExperiment(experiment *types.Experiment, attribute Attribute, parameterName string) (string, types.ParameterDataType, bool)
 	EvaluateExperimentDetailed(experiment *types.Experiment, attribute Attribute, parameterName string) *types.ExperimentEvaluationResult
+	EvaluateAssignedExperiment(experiment *types.Experiment, attribute Attribute, parameterName string, variantID int) *types.ExperimentEvaluationResult
 }
 
 // Attribute interface for dependency injection
@@ -1,12 +1,17 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"regexp"
 	"sdk/pkg/logger"
 	"sdk/types"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spaolacci/murmur3"
 )
@@ -15,54 +20,202 @@ const bucketSize = 10000
 
 // EvaluationEngine implements the Engine interface
 type EvaluationEngine struct {
-	logger logger.Logger
+	logger               logger.Logger
+	allowVariantOverride bool
+	numberEpsilon        float64
+	regexMu              sync.Mutex
+	regexCache           map[string]*regexp.Regexp
 }
 
-// NewEvaluationEngine creates a new evaluation engine
-func NewEvaluationEngine(logger logger.Logger) Engine {
+// NewEvaluationEngine creates a new evaluation engine. When allowVariantOverride is set, attributes
+// carrying types.ForceVariantAttribute are assigned the named variant instead of a hashed one.
+// Number equality conditions treat values within numberEpsilon as equal; 0 compares exactly.
+func NewEvaluationEngine(logger logger.Logger, allowVariantOverride bool, numberEpsilon float64) Engine {
 	return &EvaluationEngine{
-		logger: logger,
+		logger:               logger,
+		allowVariantOverride: allowVariantOverride,
+		numberEpsilon:        numberEpsilon,
+		regexCache:           make(map[string]*regexp.Regexp),
 	}
 }
 
 // EvaluateParameter evaluates a parameter against the given attributes
 func (e *EvaluationEngine) EvaluateParameter(parameter *types.Parameter, attribute Attribute) string {
+	value, _ := e.EvaluateParameterDetailed(parameter, attribute)
+	return value
+}
+
+// EvaluateParameterDetailed evaluates a parameter and reports whether the value came from
+// a matching rule or is the parameter's default
+func (e *EvaluationEngine) EvaluateParameterDetailed(parameter *types.Parameter, attribute Attribute) (string, types.EvaluationReason) {
 	if len(parameter.Rules) == 0 {
 		e.logger.Info("no rules found for parameter", "parameter", parameter)
-		return parameter.DefaultRolloutValue
+		return parameter.DefaultRolloutValue, types.EvaluationReasonDefault
+	}
+
+	for _, rule := range rulesByPriority(parameter.Rules) {
+		matched := false
+		switch rule.Type {
+		case types.RuleTypeSegment:
+			matched = (rule.MatchType == types.ConditionMatchTypeMatch && e.evaluateSegmentRule(&rule, attribute)) ||
+				(rule.MatchType == types.ConditionMatchTypeNotMatch && !e.evaluateSegmentRule(&rule, attribute))
+			matched = matched != rule.Negate
+		case types.RuleTypeAttribute:
+			matched = e.evaluateRuleConditions(&rule, attribute) != rule.Negate
+		}
+		if matched && e.inRuleRollout(&rule, attribute) {
+			return rule.RolloutValue, types.EvaluationReasonRuleMatch
+		}
+	}
+
+	return parameter.DefaultRolloutValue, types.EvaluationReasonDefault
+}
+
+// EvaluateParameterTraced evaluates a parameter like EvaluateParameterDetailed and records the
+// decision taken for every evaluated rule and condition. Every condition of a rule is evaluated so the
+// trace is complete; the outcome is the same as with the short-circuiting untraced evaluation.
+func (e *EvaluationEngine) EvaluateParameterTraced(parameter *types.Parameter, attribute Attribute) (string, types.EvaluationReason, *types.EvaluationTrace) {
+	trace := &types.EvaluationTrace{Rules: []types.RuleTrace{}}
+	for _, rule := range rulesByPriority(parameter.Rules) {
+		ruleTrace := e.traceRule(&rule, attribute)
+		trace.Rules = append(trace.Rules, ruleTrace)
+		if ruleTrace.Matched {
+			ruleID := rule.ID
+			trace.MatchedRuleID = &ruleID
+			return rule.RolloutValue, types.EvaluationReasonRuleMatch, trace
+		}
 	}
+	return parameter.DefaultRolloutValue, types.EvaluationReasonDefault, trace
+}
 
-	for _, rule := range parameter.Rules {
-		count := 0
-		if rule.Type == types.RuleTypeAttribute {
-			for _, condition := range rule.Conditions {
-				if e.evaluateCondition(&condition, attribute) {
-					count++
+// traceRule evaluates a rule, recording each of its conditions, or those of its segment for segment rules
+func (e *EvaluationEngine) traceRule(rule *types.ParameterRule, attribute Attribute) types.RuleTrace {
+	ruleTrace := types.RuleTrace{
+		RuleID:     rule.ID,
+		Type:       rule.Type,
+		Conditions: []types.ConditionTrace{},
+	}
+
+	switch rule.Type {
+	case types.RuleTypeSegment:
+		ruleTrace.MatchType = rule.MatchType
+		ruleTrace.SegmentID = rule.SegmentID
+		inSegment := false
+		if rule.Segment != nil {
+			for _, segmentRule := range rule.Segment.Rules {
+				segmentRuleID := segmentRule.ID
+				matched := len(segmentRule.Conditions) > 0
+				for _, condition := range segmentRule.Conditions {
+					conditionTrace := e.traceCondition(&condition, attribute)
+					conditionTrace.SegmentRuleID = &segmentRuleID
+					ruleTrace.Conditions = append(ruleTrace.Conditions, conditionTrace)
+					matched = matched && conditionTrace.Matched
 				}
+				inSegment = inSegment || matched
 			}
-		} else if rule.Type == types.RuleTypeSegment {
-			if rule.MatchType == types.ConditionMatchTypeMatch && e.evaluateSegmentRule(&rule, attribute) {
-				return rule.RolloutValue
-			} else if rule.MatchType == types.ConditionMatchTypeNotMatch && !e.evaluateSegmentRule(&rule, attribute) {
-				return rule.RolloutValue
-			}
-			continue
 		}
-		if count == len(rule.Conditions) {
-			return rule.RolloutValue
+		ruleTrace.Matched = (rule.MatchType == types.ConditionMatchTypeMatch && inSegment) ||
+			(rule.MatchType == types.ConditionMatchTypeNotMatch && !inSegment)
+	case types.RuleTypeAttribute:
+		anyMatched, allMatched := false, true
+		for _, condition := range rule.Conditions {
+			conditionTrace := e.traceCondition(&condition, attribute)
+			ruleTrace.Conditions = append(ruleTrace.Conditions, conditionTrace)
+			anyMatched = anyMatched || conditionTrace.Matched
+			allMatched = allMatched && conditionTrace.Matched
 		}
+		if len(rule.Conditions) == 0 {
+			ruleTrace.Matched = true
+		} else if rule.ConditionLogic == types.ConditionLogicOr {
+			ruleTrace.Matched = anyMatched
+		} else {
+			ruleTrace.Matched = allMatched
+		}
+	}
+	if rule.Negate && (rule.Type == types.RuleTypeSegment || rule.Type == types.RuleTypeAttribute) {
+		ruleTrace.Negated = true
+		ruleTrace.Matched = !ruleTrace.Matched
+	}
+	if ruleTrace.Matched && rule.RolloutPercentage != nil {
+		inRollout := e.inRuleRollout(rule, attribute)
+		ruleTrace.InRollout = &inRollout
+		ruleTrace.Matched = inRollout
+	}
+	return ruleTrace
+}
+
+// inRuleRollout reports whether the user falls within a rule's rollout percentage. Users are bucketed
+// per rule by the hash attribute, so they keep their assignment; users without it are never included.
+func (e *EvaluationEngine) inRuleRollout(rule *types.ParameterRule, attribute Attribute) bool {
+	if rule.RolloutPercentage == nil || *rule.RolloutPercentage >= 100 {
+		return true
 	}
+	value := attribute.Get(rule.HashAttributeName)
+	if value == nil {
+		return false
+	}
+	key := fmt.Sprintf("parameter:rule:%d:%v", rule.ID, value)
+	return e.inPopulation(key, 0, *rule.RolloutPercentage)
+}
+
+// traceCondition evaluates a condition and records its expected and actual values
+func (e *EvaluationEngine) traceCondition(condition Condition, attribute Attribute) types.ConditionTrace {
+	return types.ConditionTrace{
+		Attribute: condition.GetAttributeName(),
+		Operator:  condition.GetOperator(),
+		Expected:  condition.GetValue(),
+		Actual:    attribute.Get(condition.GetAttributeName()),
+		Matched:   e.evaluateCondition(condition, attribute),
+	}
+}
+
+// rulesByPriority returns the rules in evaluation order, lowest priority first. Rules with the same
+// priority keep their stored order. Rules already in order are returned as they are.
+func rulesByPriority(rules []types.ParameterRule) []types.ParameterRule {
+	byPriority := func(a, b types.ParameterRule) int {
+		return a.Priority - b.Priority
+	}
+	if slices.IsSortedFunc(rules, byPriority) {
+		return rules
+	}
+	sorted := slices.Clone(rules)
+	slices.SortStableFunc(sorted, byPriority)
+	return sorted
+}
 
-	return parameter.DefaultRolloutValue
+// evaluateRuleConditions reports whether the conditions of an attribute rule match. With or logic
+// the first matching condition is enough; otherwise every condition must match. A rule without
+// conditions always matches.
+func (e *EvaluationEngine) evaluateRuleConditions(rule *types.ParameterRule, attribute Attribute) bool {
+	if len(rule.Conditions) == 0 {
+		return true
+	}
+	if rule.ConditionLogic == types.ConditionLogicOr {
+		for _, condition := range rule.Conditions {
+			if e.evaluateCondition(&condition, attribute) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, condition := range rule.Conditions {
+		if !e.evaluateCondition(&condition, attribute) {
+			return false
+		}
+	}
+	return true
 }
 
 // EvaluateExperiment evaluates an experiment and returns the result
 func (e *EvaluationEngine) EvaluateExperiment(experiment *types.Experiment, attribute Attribute, parameterName string) (string, types.ParameterDataType, bool) {
-	if err := experiment.IsValid(); err != nil {
-		e.logger.Debug("experiment is invalid", "experiment", experiment, "error", err)
+	if !e.isValidExperiment(experiment) {
 		return "", "", false
 	}
 
+	if index := overrideVariantIndex(experiment, attribute); index != -1 {
+		return variantParameter(&experiment.Variants[index], parameterName)
+	}
+
 	if experiment.Segment != nil {
 		rules := experiment.Segment.Rules
 		passRule := 0
@@ -78,6 +231,10 @@ func (e *EvaluationEngine) EvaluateExperiment(experiment *types.Experiment, attr
 		}
 	}
 
+	if index := e.forcedVariantIndex(experiment, attribute); index != -1 {
+		return variantParameter(&experiment.Variants[index], parameterName)
+	}
+
 	valuePopulation := fmt.Sprintf("%v", attribute.Get(experiment.HashAttributeName))
 	keyPopulation := fmt.Sprintf("experiment:population:%s:%s", experiment.Uuid, valuePopulation)
 	inPopulation := e.inPopulation(keyPopulation, 0, experiment.PopulationSize)
@@ -114,29 +271,40 @@ func (e *EvaluationEngine) EvaluateExperiment(experiment *types.Experiment, attr
 		e.logger.Debug("not in traffic allocation", "experiment", experiment)
 		return "", "", false
 	}
-	variant := experiment.Variants[index]
-	for _, parameter := range variant.Parameters {
-		if parameter.ParameterName == parameterName {
-			return parameter.RolloutValue, parameter.ParameterDataType, true
-		}
-	}
-
-	return "", "", false
+	return variantParameter(&experiment.Variants[index], parameterName)
 }
 
 // EvaluateExperimentDetailed returns detailed experiment evaluation result
 func (e *EvaluationEngine) EvaluateExperimentDetailed(experiment *types.Experiment, attribute Attribute, parameterName string) *types.ExperimentEvaluationResult {
+	return e.evaluateExperimentDetailed(experiment, attribute, parameterName, nil)
+}
+
+// EvaluateAssignedExperiment evaluates an experiment for attributes previously assigned the variant
+// with variantID. Once the segment check passes, that variant is kept without checking the population,
+// unless a variant is forced. When the experiment no longer has the variant, the attributes are hashed.
+func (e *EvaluationEngine) EvaluateAssignedExperiment(experiment *types.Experiment, attribute Attribute, parameterName string, variantID int) *types.ExperimentEvaluationResult {
+	return e.evaluateExperimentDetailed(experiment, attribute, parameterName, &variantID)
+}
+
+// evaluateExperimentDetailed evaluates an experiment, keeping the assigned variant when there is one
+func (e *EvaluationEngine) evaluateExperimentDetailed(experiment *types.Experiment, attribute Attribute, parameterName string, assignedVariantID *int) *types.ExperimentEvaluationResult {
 	result := &types.ExperimentEvaluationResult{
 		ExperimentID:   &experiment.ID,
 		ExperimentUUID: &experiment.Uuid,
 		Success:        false,
 	}
 
-	if err := experiment.IsValid(); err != nil {
-		e.logger.Debug("experiment is invalid", "experiment", experiment, "error", err)
+	if !e.isValidExperiment(experiment) {
 		return result
 	}
 
+	if index := overrideVariantIndex(experiment, attribute); index != -1 {
+		e.logger.Debug("variant overridden", "experiment", experiment, "variant", experiment.Variants[index].Name)
+		result.InPopulation = true
+		result.Overridden = true
+		return variantResult(result, &experiment.Variants[index], parameterName)
+	}
+
 	if experiment.Segment != nil {
 		rules := experiment.Segment.Rules
 		passRule := 0
@@ -152,6 +320,24 @@ func (e *EvaluationEngine) EvaluateExperimentDetailed(experiment *types.Experime
 		}
 	}
 
+	if index := e.forcedVariantIndex(experiment, attribute); index != -1 {
+		e.logger.Debug("variant forced by attribute", "experiment", experiment, "variant", experiment.Variants[index].Name)
+		result.InPopulation = true
+		result.VariantOverridden = true
+		return variantResult(result, &experiment.Variants[index], parameterName)
+	}
+
+	if assignedVariantID != nil {
+		index := slices.IndexFunc(experiment.Variants, func(variant types.ExperimentVariant) bool {
+			return variant.ID == *assignedVariantID
+		})
+		if index != -1 {
+			result.InPopulation = true
+			return variantResult(result, &experiment.Variants[index], parameterName)
+		}
+		e.logger.Debug("assigned variant no longer exists", "experiment", experiment, "variantID", *assignedVariantID)
+	}
+
 	valuePopulation := fmt.Sprintf("%v", attribute.Get(experiment.HashAttributeName))
 	keyPopulation := fmt.Sprintf("experiment:population:%s:%s", experiment.Uuid, valuePopulation)
 	inPopulation := e.inPopulation(keyPopulation, 0, experiment.PopulationSize)
@@ -159,6 +345,7 @@ func (e *EvaluationEngine) EvaluateExperimentDetailed(experiment *types.Experime
 		e.logger.Debug("not in population", "experiment", experiment)
 		return result
 	}
+	result.InPopulation = true
 
 	trafficAllocation := make([]int, 0)
 	for _, variant := range experiment.Variants {
@@ -189,25 +376,89 @@ func (e *EvaluationEngine) EvaluateExperimentDetailed(experiment *types.Experime
 		return result
 	}
 
-	variant := experiment.Variants[index]
-	result.VariantID = &variant.ID
-	result.VariantName = &variant.Name
+	return variantResult(result, &experiment.Variants[index], parameterName)
+}
+
+// isValidExperiment reports whether an experiment can be evaluated. Paused experiments are skipped
+// quietly, while experiments whose allocation is broken are skipped with a warning: users falling
+// outside their variants would otherwise silently get the default value.
+func (e *EvaluationEngine) isValidExperiment(experiment *types.Experiment) bool {
+	err := experiment.IsValid()
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, types.ErrExperimentPaused):
+		e.logger.Debug("experiment is paused", "experiment", experiment.Uuid)
+	default:
+		e.logger.Warn("skipping invalid experiment", "experiment", experiment.Uuid, "error", err)
+	}
+	return false
+}
 
+// forcedVariantIndex returns the index of the variant named by the types.ForceVariantAttribute of
+// the attributes, or -1 when overrides are not allowed or the experiment has no such variant
+func (e *EvaluationEngine) forcedVariantIndex(experiment *types.Experiment, attribute Attribute) int {
+	if !e.allowVariantOverride {
+		return -1
+	}
+	name, ok := attribute.Get(types.ForceVariantAttribute).(string)
+	if !ok || name == "" {
+		return -1
+	}
+	return slices.IndexFunc(experiment.Variants, func(variant types.ExperimentVariant) bool {
+		return variant.Name == name
+	})
+}
+
+// overrideVariantIndex returns the index of the variant the experiment's overrides force the value of
+// its hash attribute into, or -1 when the experiment is not running or has no override for the value
+func overrideVariantIndex(experiment *types.Experiment, attribute Attribute) int {
+	if experiment.Status != types.ExperimentStatusRunning || len(experiment.Overrides) == 0 {
+		return -1
+	}
+	value := attribute.Get(experiment.HashAttributeName)
+	if value == nil {
+		return -1
+	}
+	variantID, ok := experiment.Overrides[fmt.Sprintf("%v", value)]
+	if !ok {
+		return -1
+	}
+	return slices.IndexFunc(experiment.Variants, func(variant types.ExperimentVariant) bool {
+		return variant.ID == variantID
+	})
+}
+
+// variantParameter returns the rollout value and data type of a parameter within a variant
+func variantParameter(variant *types.ExperimentVariant, parameterName string) (string, types.ParameterDataType, bool) {
 	for _, parameter := range variant.Parameters {
 		if parameter.ParameterName == parameterName {
-			result.Value = parameter.RolloutValue
-			result.DataType = parameter.ParameterDataType
-			result.Success = true
-			return result
+			return parameter.RolloutValue, parameter.ParameterDataType, true
 		}
 	}
+	return "", "", false
+}
 
+// variantResult completes result with the assigned variant and its value for the parameter
+func variantResult(result *types.ExperimentEvaluationResult, variant *types.ExperimentVariant, parameterName string) *types.ExperimentEvaluationResult {
+	result.VariantID = &variant.ID
+	result.VariantName = &variant.Name
+	result.Value, result.DataType, result.Success = variantParameter(variant, parameterName)
 	return result
 }
 
 // evaluateCondition is a unified method to evaluate any condition type
 func (e *EvaluationEngine) evaluateCondition(condition Condition, attribute Attribute) bool {
 	e.logger.Debug("evaluating condition", "dataType", condition.GetAttributeDataType(), "condition", condition, "attribute", attribute)
+	switch condition.GetOperator() {
+	case types.ConditionOperatorIsSet:
+		return attribute.Get(condition.GetAttributeName()) != nil
+	case types.ConditionOperatorIsNotSet:
+		return attribute.Get(condition.GetAttributeName()) == nil
+	case types.ConditionOperatorSemverGt, types.ConditionOperatorSemverLt,
+		types.ConditionOperatorSemverGte, types.ConditionOperatorSemverLte, types.ConditionOperatorSemverEq:
+		return e.evaluateSemverCondition(condition, attribute)
+	}
 	switch condition.GetAttributeDataType() {
 	case "string":
 		return e.evaluateStringCondition(condition, attribute)
@@ -217,6 +468,8 @@ func (e *EvaluationEngine) evaluateCondition(condition Condition, attribute Attr
 		return e.evaluateBooleanCondition(condition, attribute)
 	case "enum":
 		return e.evaluateEnumCondition(condition, attribute)
+	case "date":
+		return e.evaluateDateCondition(condition, attribute)
 	}
 	return false
 }
@@ -243,6 +496,63 @@ func (e *EvaluationEngine) evaluateStringCondition(condition Condition, attribut
 	case types.ConditionOperatorNotIn:
 		values := strings.Split(condition.GetValue(), ",")
 		return !slices.Contains(values, value)
+	case types.ConditionOperatorMatchesRegex:
+		pattern, ok := e.compileRegex(condition.GetValue())
+		return ok && pattern.MatchString(value)
+	case types.ConditionOperatorNotMatchesRegex:
+		pattern, ok := e.compileRegex(condition.GetValue())
+		return ok && !pattern.MatchString(value)
+	}
+	return false
+}
+
+// compileRegex returns the compiled pattern, caching it by its source since compilation is expensive.
+// Invalid patterns are cached too, so they are only reported once.
+func (e *EvaluationEngine) compileRegex(expr string) (*regexp.Regexp, bool) {
+	e.regexMu.Lock()
+	defer e.regexMu.Unlock()
+
+	if pattern, ok := e.regexCache[expr]; ok {
+		return pattern, pattern != nil
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		e.logger.Debug("invalid regex pattern in condition", "pattern", expr, "error", err)
+		pattern = nil
+	}
+	e.regexCache[expr] = pattern
+	return pattern, pattern != nil
+}
+
+// evaluateSemverCondition compares a string attribute with the condition value as semantic versions
+func (e *EvaluationEngine) evaluateSemverCondition(condition Condition, attribute Attribute) bool {
+	value, ok := attribute.Get(condition.GetAttributeName()).(string)
+	if !ok {
+		return false
+	}
+	actual, err := types.ParseSemver(value)
+	if err != nil {
+		e.logger.Debug("attribute is not a semantic version", "attribute", condition.GetAttributeName(), "value", value)
+		return false
+	}
+	expected, err := types.ParseSemver(condition.GetValue())
+	if err != nil {
+		e.logger.Debug("invalid semantic version in condition", "value", condition.GetValue(), "error", err)
+		return false
+	}
+	c := actual.Compare(expected)
+	switch condition.GetOperator() {
+	case types.ConditionOperatorSemverGt:
+		return c > 0
+	case types.ConditionOperatorSemverLt:
+		return c < 0
+	case types.ConditionOperatorSemverGte:
+		return c >= 0
+	case types.ConditionOperatorSemverLte:
+		return c <= 0
+	case types.ConditionOperatorSemverEq:
+		return c == 0
 	}
 	return false
 }
@@ -253,15 +563,24 @@ func (e *EvaluationEngine) evaluateNumberCondition(condition Condition, attribut
 	if !ok {
 		return false
 	}
+	// Range operators hold a "min,max" pair rather than a single number
+	switch condition.GetOperator() {
+	case types.ConditionOperatorBetween:
+		min, max, ok := parseBetweenBounds(condition.GetValue())
+		return ok && value >= min && value <= max
+	case types.ConditionOperatorNotBetween:
+		min, max, ok := parseBetweenBounds(condition.GetValue())
+		return ok && (value < min || value > max)
+	}
 	expectedValue, err := strconv.ParseFloat(condition.GetValue(), 64)
 	if err != nil {
 		return false
 	}
 	switch condition.GetOperator() {
 	case types.ConditionOperatorEquals:
-		return value == expectedValue
+		return e.numbersEqual(value, expectedValue)
 	case types.ConditionOperatorNotEquals:
-		return value != expectedValue
+		return !e.numbersEqual(value, expectedValue)
 	case types.ConditionOperatorGreaterThan:
 		return value > expectedValue
 	case types.ConditionOperatorLessThan:
@@ -280,6 +599,29 @@ func (e *EvaluationEngine) evaluateNumberCondition(condition Condition, attribut
 	return false
 }
 
+// numbersEqual reports whether two numbers are within the engine's epsilon, so that values computed
+// with floating-point math such as 0.1+0.2 can still equal 0.3
+func (e *EvaluationEngine) numbersEqual(a, b float64) bool {
+	return a == b || math.Abs(a-b) <= e.numberEpsilon
+}
+
+// parseBetweenBounds parses the inclusive "min,max" bounds of a between condition
+func parseBetweenBounds(value string) (float64, float64, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	min, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	max, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return min, max, true
+}
+
 // evaluateBooleanCondition evaluates boolean-type conditions
 func (e *EvaluationEngine) evaluateBooleanCondition(condition Condition, attribute Attribute) bool {
 	value, ok := attribute.Get(condition.GetAttributeName()).(bool)
@@ -316,6 +658,70 @@ func (e *EvaluationEngine) evaluateEnumCondition(condition Condition, attribute
 	return slices.Contains(values, value)
 }
 
+// evaluateDateCondition evaluates date-type conditions, where greater_than means after, less_than means
+// before and between holds inclusive "from,to" bounds. Attribute values may be RFC3339 strings or unix epoch seconds.
+func (e *EvaluationEngine) evaluateDateCondition(condition Condition, attribute Attribute) bool {
+	var value time.Time
+	switch v := attribute.Get(condition.GetAttributeName()).(type) {
+	case string:
+		parsed, err := types.ParseDate(v)
+		if err != nil {
+			return false
+		}
+		value = parsed
+	case float64:
+		value = time.Unix(int64(v), 0)
+	case time.Time:
+		value = v
+	default:
+		return false
+	}
+	switch condition.GetOperator() {
+	case types.ConditionOperatorBetween:
+		from, to, ok := parseDateBounds(condition.GetValue())
+		return ok && !value.Before(from) && !value.After(to)
+	case types.ConditionOperatorNotBetween:
+		from, to, ok := parseDateBounds(condition.GetValue())
+		return ok && (value.Before(from) || value.After(to))
+	}
+	expectedValue, err := types.ParseDate(condition.GetValue())
+	if err != nil {
+		return false
+	}
+	switch condition.GetOperator() {
+	case types.ConditionOperatorEquals:
+		return value.Equal(expectedValue)
+	case types.ConditionOperatorNotEquals:
+		return !value.Equal(expectedValue)
+	case types.ConditionOperatorGreaterThan:
+		return value.After(expectedValue)
+	case types.ConditionOperatorLessThan:
+		return value.Before(expectedValue)
+	case types.ConditionOperatorGreaterThanOrEqual:
+		return !value.Before(expectedValue)
+	case types.ConditionOperatorLessThanOrEqual:
+		return !value.After(expectedValue)
+	}
+	return false
+}
+
+// parseDateBounds parses the inclusive "from,to" bounds of a date between condition
+func parseDateBounds(value string) (time.Time, time.Time, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{}, false
+	}
+	from, err := types.ParseDate(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := types.ParseDate(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
 // evaluateSegmentRule evaluates whether an attribute matches a segment rule
 func (e *EvaluationEngine) evaluateSegmentRule(rule *types.ParameterRule, attribute Attribute) bool {
 	e.logger.Debug("evaluating segment rule", "rule", rule, "segmentID", rule.SegmentID)
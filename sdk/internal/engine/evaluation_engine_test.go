@@ -0,0 +1,472 @@
+package engine
+
+import (
+	"log/slog"
+	"sdk/pkg/logger"
+	"sdk/types"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mapAttribute map[string]interface{}
+
+func (a mapAttribute) Get(key string) interface{}    { return a[key] }
+func (a mapAttribute) ToMap() map[string]interface{} { return a }
+
+func TestEvaluateParameterConditionLogic(t *testing.T) {
+	conditions := []types.RuleCondition{
+		{AttributeName: "country", AttributeDataType: "string", Operator: types.ConditionOperatorEquals, Value: "VN"},
+		{AttributeName: "plan", AttributeDataType: "string", Operator: types.ConditionOperatorEquals, Value: "pro"},
+	}
+	parameter := func(logic types.ConditionLogic) *types.Parameter {
+		return &types.Parameter{
+			DefaultRolloutValue: "default",
+			Rules: []types.ParameterRule{
+				{Type: types.RuleTypeAttribute, ConditionLogic: logic, RolloutValue: "matched", Conditions: conditions},
+			},
+		}
+	}
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+	onlyCountry := mapAttribute{"country": "VN", "plan": "free"}
+
+	// Rules stored before condition logic existed keep and semantics
+	require.Equal(t, "default", e.EvaluateParameter(parameter(""), onlyCountry))
+	require.Equal(t, "default", e.EvaluateParameter(parameter(types.ConditionLogicAnd), onlyCountry))
+	require.Equal(t, "matched", e.EvaluateParameter(parameter(types.ConditionLogicOr), onlyCountry))
+	require.Equal(t, "default", e.EvaluateParameter(parameter(types.ConditionLogicOr), mapAttribute{"country": "US", "plan": "free"}))
+
+	_, reason := e.EvaluateParameterDetailed(parameter(types.ConditionLogicOr), onlyCountry)
+	require.Equal(t, types.EvaluationReasonRuleMatch, reason)
+	_, reason = e.EvaluateParameterDetailed(parameter(types.ConditionLogicAnd), onlyCountry)
+	require.Equal(t, types.EvaluationReasonDefault, reason)
+}
+
+func TestEvaluateParameterRulePriority(t *testing.T) {
+	condition := types.RuleCondition{AttributeName: "country", AttributeDataType: "string", Operator: types.ConditionOperatorEquals, Value: "VN"}
+	parameter := &types.Parameter{
+		DefaultRolloutValue: "default",
+		Rules: []types.ParameterRule{
+			{Type: types.RuleTypeAttribute, Priority: 2, RolloutValue: "second", Conditions: []types.RuleCondition{condition}},
+			{Type: types.RuleTypeAttribute, Priority: 1, RolloutValue: "first", Conditions: []types.RuleCondition{condition}},
+		},
+	}
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+
+	require.Equal(t, "first", e.EvaluateParameter(parameter, mapAttribute{"country": "VN"}))
+	// Evaluation does not reorder the caller's rules
+	require.Equal(t, "second", parameter.Rules[0].RolloutValue)
+}
+
+func TestEvaluateSemverCondition(t *testing.T) {
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+	parameter := func(operator types.ConditionOperator, value string) *types.Parameter {
+		return &types.Parameter{
+			DefaultRolloutValue: "default",
+			Rules: []types.ParameterRule{{Type: types.RuleTypeAttribute, RolloutValue: "matched", Conditions: []types.RuleCondition{
+				{AttributeName: "app_version", AttributeDataType: "string", Operator: operator, Value: value},
+			}}},
+		}
+	}
+	version := func(value string) mapAttribute { return mapAttribute{"app_version": value} }
+
+	// Versions are ordered by their numbers rather than as strings
+	require.Equal(t, "matched", e.EvaluateParameter(parameter(types.ConditionOperatorSemverGte, "2.15.0"), version("2.15.0")))
+	require.Equal(t, "default", e.EvaluateParameter(parameter(types.ConditionOperatorSemverGte, "2.15.0"), version("2.9.0")))
+	require.Equal(t, "matched", e.EvaluateParameter(parameter(types.ConditionOperatorSemverLt, "2.15.0"), version("2.9.0")))
+	require.Equal(t, "matched", e.EvaluateParameter(parameter(types.ConditionOperatorSemverGt, "v2.14"), version("2.14.3")))
+	require.Equal(t, "matched", e.EvaluateParameter(parameter(types.ConditionOperatorSemverEq, "2.14.0"), version("2.14")))
+	// A prerelease precedes its release
+	require.Equal(t, "matched", e.EvaluateParameter(parameter(types.ConditionOperatorSemverLte, "3.0.0"), version("3.0.0-beta.2")))
+	require.Equal(t, "default", e.EvaluateParameter(parameter(types.ConditionOperatorSemverEq, "3.0.0"), version("3.0.0-beta.2")))
+
+	// Invalid versions fail the condition
+	require.Equal(t, "default", e.EvaluateParameter(parameter(types.ConditionOperatorSemverLt, "2.15.0"), version("latest")))
+	require.Equal(t, "default", e.EvaluateParameter(parameter(types.ConditionOperatorSemverLt, "next"), version("2.9.0")))
+	require.Equal(t, "default", e.EvaluateParameter(parameter(types.ConditionOperatorSemverLt, "2.15.0"), mapAttribute{"app_version": 2.9}))
+}
+
+func TestSemverCompare(t *testing.T) {
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1+build.5", "1.10.0"}
+	for i := 1; i < len(ordered); i++ {
+		a, err := types.ParseSemver(ordered[i-1])
+		require.NoError(t, err)
+		b, err := types.ParseSemver(ordered[i])
+		require.NoError(t, err)
+		require.Equal(t, -1, a.Compare(b), "%s < %s", ordered[i-1], ordered[i])
+		require.Equal(t, 1, b.Compare(a), "%s > %s", ordered[i], ordered[i-1])
+	}
+
+	for _, invalid := range []string{"", "1.2.3.4", "1.x", "1.0.0-", "1.0.0-beta..1"} {
+		_, err := types.ParseSemver(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestEvaluateNumberConditionEpsilon(t *testing.T) {
+	parameter := func(operator types.ConditionOperator) *types.Parameter {
+		return &types.Parameter{
+			DefaultRolloutValue: "default",
+			Rules: []types.ParameterRule{{Type: types.RuleTypeAttribute, RolloutValue: "matched", Conditions: []types.RuleCondition{
+				{AttributeName: "score", AttributeDataType: "number", Operator: operator, Value: "0.3"},
+			}}},
+		}
+	}
+	// Constant arithmetic is exact, so compute the score at run time
+	a, b := 0.1, 0.2
+	score := mapAttribute{"score": a + b}
+	exact := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+	tolerant := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 1e-9)
+
+	// Exact matching stays the default
+	require.Equal(t, "default", exact.EvaluateParameter(parameter(types.ConditionOperatorEquals), score))
+	require.Equal(t, "matched", exact.EvaluateParameter(parameter(types.ConditionOperatorNotEquals), score))
+
+	require.Equal(t, "matched", tolerant.EvaluateParameter(parameter(types.ConditionOperatorEquals), score))
+	require.Equal(t, "default", tolerant.EvaluateParameter(parameter(types.ConditionOperatorNotEquals), score))
+	require.Equal(t, "default", tolerant.EvaluateParameter(parameter(types.ConditionOperatorEquals), mapAttribute{"score": 0.31}))
+}
+
+func TestEvaluateParameterTraced(t *testing.T) {
+	segmentID := uint(7)
+	parameter := &types.Parameter{
+		DefaultRolloutValue: "default",
+		Rules: []types.ParameterRule{
+			{ID: 1, Type: types.RuleTypeSegment, MatchType: types.ConditionMatchTypeMatch, SegmentID: &segmentID, RolloutValue: "segment",
+				Segment: &types.Segment{ID: segmentID, Rules: []types.SegmentRule{{ID: 70, Conditions: []types.RuleCondition{
+					{AttributeName: "age", AttributeDataType: "number", Operator: types.ConditionOperatorGreaterThan, Value: "30"},
+				}}}}},
+			{ID: 2, Type: types.RuleTypeAttribute, ConditionLogic: types.ConditionLogicOr, RolloutValue: "attribute", Conditions: []types.RuleCondition{
+				{AttributeName: "country", AttributeDataType: "string", Operator: types.ConditionOperatorEquals, Value: "US"},
+				{AttributeName: "plan", AttributeDataType: "string", Operator: types.ConditionOperatorEquals, Value: "pro"},
+			}},
+			{ID: 3, Type: types.RuleTypeAttribute, RolloutValue: "unreached"},
+		},
+	}
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+	attribute := mapAttribute{"age": float64(20), "plan": "pro"}
+
+	value, reason, trace := e.EvaluateParameterTraced(parameter, attribute)
+	untracedValue, untracedReason := e.EvaluateParameterDetailed(parameter, attribute)
+	require.Equal(t, untracedValue, value)
+	require.Equal(t, untracedReason, reason)
+	require.Equal(t, "attribute", value)
+
+	require.Equal(t, uint(2), *trace.MatchedRuleID)
+	require.Len(t, trace.Rules, 2)
+	require.False(t, trace.Rules[0].Matched)
+	require.Equal(t, uint(70), *trace.Rules[0].Conditions[0].SegmentRuleID)
+	require.Equal(t, float64(20), trace.Rules[0].Conditions[0].Actual)
+	require.True(t, trace.Rules[1].Matched)
+	require.Equal(t, []types.ConditionTrace{
+		{Attribute: "country", Operator: types.ConditionOperatorEquals, Expected: "US", Actual: nil, Matched: false},
+		{Attribute: "plan", Operator: types.ConditionOperatorEquals, Expected: "pro", Actual: "pro", Matched: true},
+	}, trace.Rules[1].Conditions)
+}
+
+func TestEvaluateParameterRuleRollout(t *testing.T) {
+	percentage := 30
+	parameter := &types.Parameter{
+		DefaultRolloutValue: "default",
+		Rules: []types.ParameterRule{
+			{ID: 1, Type: types.RuleTypeAttribute, RolloutValue: "rolled out", RolloutPercentage: &percentage, HashAttributeName: "userId"},
+		},
+	}
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+
+	included := 0
+	for i := 0; i < 1000; i++ {
+		attribute := mapAttribute{"userId": i}
+		value, _, trace := e.EvaluateParameterTraced(parameter, attribute)
+		// Assignments are stable and the traced evaluation agrees with the untraced one
+		require.Equal(t, value, e.EvaluateParameter(parameter, attribute))
+		require.Equal(t, value == "rolled out", *trace.Rules[0].InRollout)
+		if value == "rolled out" {
+			included++
+		}
+	}
+	require.InDelta(t, 300, included, 60)
+
+	// Users without the hash attribute are never included
+	require.Equal(t, "default", e.EvaluateParameter(parameter, mapAttribute{}))
+}
+
+func TestEvaluateParameterNegatedRule(t *testing.T) {
+	condition := types.RuleCondition{AttributeName: "country", AttributeDataType: "string", Operator: types.ConditionOperatorEquals, Value: "US"}
+	segment := &types.Segment{ID: 5, Rules: []types.SegmentRule{{ID: 50, Conditions: []types.RuleCondition{condition}}}}
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+
+	for _, rule := range []types.ParameterRule{
+		{ID: 1, Type: types.RuleTypeAttribute, RolloutValue: "outside us", Negate: true, Conditions: []types.RuleCondition{condition}},
+		{ID: 2, Type: types.RuleTypeSegment, MatchType: types.ConditionMatchTypeMatch, RolloutValue: "outside us", Negate: true, Segment: segment},
+	} {
+		parameter := &types.Parameter{DefaultRolloutValue: "default", Rules: []types.ParameterRule{rule}}
+		for country, expected := range map[string]string{"US": "default", "VN": "outside us"} {
+			attribute := mapAttribute{"country": country}
+			require.Equal(t, expected, e.EvaluateParameter(parameter, attribute))
+
+			value, _, trace := e.EvaluateParameterTraced(parameter, attribute)
+			require.Equal(t, expected, value)
+			require.True(t, trace.Rules[0].Negated)
+		}
+	}
+}
+
+func TestEvaluateParameterPresenceOperators(t *testing.T) {
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+	parameter := func(operator types.ConditionOperator) *types.Parameter {
+		return &types.Parameter{
+			DefaultRolloutValue: "default",
+			Rules: []types.ParameterRule{{Type: types.RuleTypeAttribute, RolloutValue: "matched", Conditions: []types.RuleCondition{
+				{AttributeName: "loyalty_tier", AttributeDataType: "number", Operator: operator},
+			}}},
+		}
+	}
+
+	// Presence is checked whatever the value and its data type
+	require.Equal(t, "matched", e.EvaluateParameter(parameter(types.ConditionOperatorIsSet), mapAttribute{"loyalty_tier": "gold"}))
+	require.Equal(t, "default", e.EvaluateParameter(parameter(types.ConditionOperatorIsSet), mapAttribute{}))
+	require.Equal(t, "default", e.EvaluateParameter(parameter(types.ConditionOperatorIsNotSet), mapAttribute{"loyalty_tier": 0.0}))
+	require.Equal(t, "matched", e.EvaluateParameter(parameter(types.ConditionOperatorIsNotSet), mapAttribute{}))
+}
+
+func TestEvaluateDateCondition(t *testing.T) {
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+	parameter := func(operator types.ConditionOperator, value string) *types.Parameter {
+		return &types.Parameter{
+			DefaultRolloutValue: "default",
+			Rules: []types.ParameterRule{{Type: types.RuleTypeAttribute, RolloutValue: "matched", Conditions: []types.RuleCondition{
+				{AttributeName: "signed_up_at", AttributeDataType: "date", Operator: operator, Value: value},
+			}}},
+		}
+	}
+	// 2025-03-01T00:00:00Z
+	march := mapAttribute{"signed_up_at": "2025-03-01T00:00:00Z"}
+	marchEpoch := mapAttribute{"signed_up_at": 1740787200.0}
+	marchEpochString := mapAttribute{"signed_up_at": "1740787200"}
+
+	tests := []struct {
+		name      string
+		operator  types.ConditionOperator
+		value     string
+		attribute mapAttribute
+		want      string
+	}{
+		{"after", types.ConditionOperatorGreaterThan, "2025-01-01T00:00:00Z", march, "matched"},
+		{"not after", types.ConditionOperatorGreaterThan, "2025-06-01T00:00:00Z", march, "default"},
+		{"before", types.ConditionOperatorLessThan, "2025-06-01T00:00:00Z", march, "matched"},
+		{"not before", types.ConditionOperatorLessThan, "2025-01-01T00:00:00Z", march, "default"},
+		{"equals across time zones", types.ConditionOperatorEquals, "2025-03-01T07:00:00+07:00", march, "matched"},
+		{"epoch attribute", types.ConditionOperatorGreaterThanOrEqual, "2025-03-01T00:00:00Z", marchEpoch, "matched"},
+		{"epoch string attribute", types.ConditionOperatorLessThanOrEqual, "2025-03-01T00:00:00Z", marchEpochString, "matched"},
+		{"epoch condition value", types.ConditionOperatorEquals, "1740787200", march, "matched"},
+		{"between", types.ConditionOperatorBetween, "2025-01-01T00:00:00Z,2025-06-01T00:00:00Z", march, "matched"},
+		{"between inclusive bounds", types.ConditionOperatorBetween, "2025-03-01T00:00:00Z,1740787200", march, "matched"},
+		{"outside between", types.ConditionOperatorBetween, "2025-04-01T00:00:00Z,2025-06-01T00:00:00Z", march, "default"},
+		{"not between", types.ConditionOperatorNotBetween, "2025-04-01T00:00:00Z,2025-06-01T00:00:00Z", march, "matched"},
+		{"invalid bounds", types.ConditionOperatorBetween, "2025-01-01T00:00:00Z", march, "default"},
+		{"unparsable attribute", types.ConditionOperatorGreaterThan, "2025-01-01T00:00:00Z", mapAttribute{"signed_up_at": "yesterday"}, "default"},
+		{"missing attribute", types.ConditionOperatorLessThan, "2025-06-01T00:00:00Z", mapAttribute{}, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, e.EvaluateParameter(parameter(tt.operator, tt.value), tt.attribute))
+		})
+	}
+}
+
+func TestEvaluateParameterPresenceInNotMatchSegment(t *testing.T) {
+	// Users without a subscription tier are outside the segment, so a not_match rule targets them
+	segment := &types.Segment{ID: 8, Rules: []types.SegmentRule{{ID: 80, Conditions: []types.RuleCondition{
+		{AttributeName: "subscription_tier", AttributeDataType: "string", Operator: types.ConditionOperatorIsSet},
+	}}}}
+	parameter := &types.Parameter{
+		DefaultRolloutValue: "default",
+		Rules: []types.ParameterRule{
+			{ID: 1, Type: types.RuleTypeSegment, MatchType: types.ConditionMatchTypeNotMatch, Segment: segment, RolloutValue: "no tier"},
+		},
+	}
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+
+	for _, tc := range []struct {
+		attribute mapAttribute
+		expected  string
+	}{
+		{mapAttribute{}, "no tier"},
+		{mapAttribute{"subscription_tier": nil}, "no tier"},
+		{mapAttribute{"subscription_tier": ""}, "default"},
+		{mapAttribute{"subscription_tier": "pro"}, "default"},
+	} {
+		require.Equal(t, tc.expected, e.EvaluateParameter(parameter, tc.attribute))
+		value, _, _ := e.EvaluateParameterTraced(parameter, tc.attribute)
+		require.Equal(t, tc.expected, value)
+	}
+}
+
+func TestEvaluateExperimentSkipsInvalidAllocation(t *testing.T) {
+	experiment := func(populationSize int, allocations ...int) *types.Experiment {
+		experiment := &types.Experiment{Uuid: "experiment", HashAttributeName: "user_id", PopulationSize: populationSize}
+		for i, allocation := range allocations {
+			experiment.Variants = append(experiment.Variants, types.ExperimentVariant{
+				ID: i + 1, Name: strconv.Itoa(i + 1), TrafficAllocation: allocation,
+				Parameters: []types.ExperimentVariantParameter{{ParameterName: "color", ParameterDataType: types.ParameterDataTypeString, RolloutValue: "variant"}},
+			})
+		}
+		return experiment
+	}
+	tests := []struct {
+		name       string
+		experiment *types.Experiment
+		wantErr    bool
+	}{
+		{name: "allocations sum to 100", experiment: experiment(100, 40, 60)},
+		{name: "allocations sum to 99", experiment: experiment(100, 40, 59), wantErr: true},
+		{name: "allocations sum to 101", experiment: experiment(100, 40, 61), wantErr: true},
+		{name: "overlapping allocation", experiment: experiment(100, 110, -10), wantErr: true},
+		{name: "no variants", experiment: experiment(100), wantErr: true},
+		{name: "population above 100", experiment: experiment(101, 100), wantErr: true},
+	}
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantErr, tt.experiment.IsValid() != nil)
+
+			// Everyone is in the population, so only invalid experiments leave users unassigned
+			for i := 0; i < 100; i++ {
+				_, _, ok := e.EvaluateExperiment(tt.experiment, mapAttribute{"user_id": i}, "color")
+				require.Equal(t, !tt.wantErr, ok)
+				require.Equal(t, !tt.wantErr, e.EvaluateExperimentDetailed(tt.experiment, mapAttribute{"user_id": i}, "color").Success)
+			}
+		})
+	}
+}
+
+func TestEvaluateExperimentVariantOverride(t *testing.T) {
+	variant := func(id int, name string) types.ExperimentVariant {
+		return types.ExperimentVariant{
+			ID:                id,
+			Name:              name,
+			TrafficAllocation: 50,
+			Parameters: []types.ExperimentVariantParameter{
+				{ParameterName: "color", ParameterDataType: types.ParameterDataTypeString, RolloutValue: name},
+			},
+		}
+	}
+	// Nobody is in the population, so only an override can assign a variant
+	experiment := &types.Experiment{
+		Uuid:              "experiment",
+		HashAttributeName: "user_id",
+		PopulationSize:    0,
+		Variants:          []types.ExperimentVariant{variant(1, "control"), variant(2, "treatment")},
+		Segment: &types.Segment{Rules: []types.SegmentRule{{Conditions: []types.RuleCondition{
+			{AttributeName: "country", AttributeDataType: "string", Operator: types.ConditionOperatorEquals, Value: "VN"},
+		}}}},
+	}
+	forced := mapAttribute{"user_id": "qa", "country": "VN", types.ForceVariantAttribute: "treatment"}
+	log := logger.NewDefaultLogger(slog.LevelError + 4)
+
+	e := NewEvaluationEngine(log, true, 0)
+	result := e.EvaluateExperimentDetailed(experiment, forced, "color")
+	require.True(t, result.Success)
+	require.True(t, result.VariantOverridden)
+	require.Equal(t, "treatment", *result.VariantName)
+	require.Equal(t, "treatment", result.Value)
+	value, _, ok := e.EvaluateExperiment(experiment, forced, "color")
+	require.True(t, ok)
+	require.Equal(t, "treatment", value)
+
+	// Unknown variants and failed segment checks fall back to hashing
+	require.False(t, e.EvaluateExperimentDetailed(experiment, mapAttribute{"user_id": "qa", "country": "VN", types.ForceVariantAttribute: "missing"}, "color").Success)
+	require.False(t, e.EvaluateExperimentDetailed(experiment, mapAttribute{"user_id": "qa", "country": "US", types.ForceVariantAttribute: "treatment"}, "color").Success)
+
+	disabled := NewEvaluationEngine(log, false, 0)
+	result = disabled.EvaluateExperimentDetailed(experiment, forced, "color")
+	require.False(t, result.Success)
+	require.False(t, result.VariantOverridden)
+}
+
+func TestEvaluateExperimentOverrides(t *testing.T) {
+	variant := func(id int, name string) types.ExperimentVariant {
+		return types.ExperimentVariant{
+			ID:                id,
+			Name:              name,
+			TrafficAllocation: 50,
+			Parameters: []types.ExperimentVariantParameter{
+				{ParameterName: "color", ParameterDataType: types.ParameterDataTypeString, RolloutValue: name},
+			},
+		}
+	}
+	// Nobody is in the population or the segment, so only an override can assign a variant
+	experiment := &types.Experiment{
+		Uuid:              "experiment",
+		HashAttributeName: "user_id",
+		PopulationSize:    0,
+		Status:            types.ExperimentStatusRunning,
+		Variants:          []types.ExperimentVariant{variant(1, "control"), variant(2, "treatment")},
+		Segment: &types.Segment{Rules: []types.SegmentRule{{Conditions: []types.RuleCondition{
+			{AttributeName: "country", AttributeDataType: "string", Operator: types.ConditionOperatorEquals, Value: "VN"},
+		}}}},
+		Overrides: map[string]int{"qa": 2, "42": 1, "stale": 3},
+	}
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+
+	result := e.EvaluateExperimentDetailed(experiment, mapAttribute{"user_id": "qa", "country": "US"}, "color")
+	require.True(t, result.Success)
+	require.True(t, result.Overridden)
+	require.Equal(t, "treatment", *result.VariantName)
+	require.Equal(t, "treatment", result.Value)
+	value, _, ok := e.EvaluateExperiment(experiment, mapAttribute{"user_id": 42}, "color")
+	require.True(t, ok)
+	require.Equal(t, "control", value)
+
+	// Overrides win over assigned variants
+	result = e.EvaluateAssignedExperiment(experiment, mapAttribute{"user_id": "qa"}, "color", 1)
+	require.True(t, result.Overridden)
+	require.Equal(t, "treatment", result.Value)
+
+	// Values without an override, or overriding a removed variant, are evaluated normally
+	require.False(t, e.EvaluateExperimentDetailed(experiment, mapAttribute{"user_id": "other", "country": "VN"}, "color").Success)
+	require.False(t, e.EvaluateExperimentDetailed(experiment, mapAttribute{"user_id": "stale", "country": "VN"}, "color").Success)
+
+	// Overrides require a running experiment
+	for _, status := range []string{types.ExperimentStatusSchedule, types.ExperimentStatusPause} {
+		experiment.Status = status
+		require.False(t, e.EvaluateExperimentDetailed(experiment, mapAttribute{"user_id": "qa"}, "color").Success)
+		_, _, ok := e.EvaluateExperiment(experiment, mapAttribute{"user_id": "qa"}, "color")
+		require.False(t, ok)
+	}
+}
+
+func TestEvaluateAssignedExperiment(t *testing.T) {
+	variant := func(id int, name string) types.ExperimentVariant {
+		return types.ExperimentVariant{
+			ID:                id,
+			Name:              name,
+			TrafficAllocation: 50,
+			Parameters: []types.ExperimentVariantParameter{
+				{ParameterName: "color", ParameterDataType: types.ParameterDataTypeString, RolloutValue: name},
+			},
+		}
+	}
+	// Nobody is in the population, so only a stored assignment can assign a variant
+	experiment := &types.Experiment{
+		Uuid:              "experiment",
+		HashAttributeName: "user_id",
+		PopulationSize:    0,
+		Variants:          []types.ExperimentVariant{variant(1, "control"), variant(2, "treatment")},
+		Segment: &types.Segment{Rules: []types.SegmentRule{{Conditions: []types.RuleCondition{
+			{AttributeName: "country", AttributeDataType: "string", Operator: types.ConditionOperatorEquals, Value: "VN"},
+		}}}},
+	}
+	e := NewEvaluationEngine(logger.NewDefaultLogger(slog.LevelError+4), false, 0)
+
+	result := e.EvaluateAssignedExperiment(experiment, mapAttribute{"user_id": "u1", "country": "VN"}, "color", 2)
+	require.True(t, result.Success)
+	require.True(t, result.InPopulation)
+	require.Equal(t, "treatment", result.Value)
+
+	// The segment check still applies, and removed variants fall back to hashing
+	require.False(t, e.EvaluateAssignedExperiment(experiment, mapAttribute{"user_id": "u1", "country": "US"}, "color", 2).Success)
+	require.False(t, e.EvaluateAssignedExperiment(experiment, mapAttribute{"user_id": "u1", "country": "VN"}, "color", 3).Success)
+}
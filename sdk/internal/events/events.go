@@ -12,9 +12,10 @@ import (
 // EventTracker interface defines event tracking operations
 type EventTracker interface {
 	TrackEvent(ctx context.Context, event types.EvaluationEvent)
+	TrackEvents(ctx context.Context, events []types.EvaluationEvent)
 	CreateParameterEvaluationEvent(parameterName string, attribute Attribute, rolloutValue *string, err error) types.EvaluationEvent
-	CreateExperimentEvaluationEvent(parameterName string, attribute Attribute, rolloutValue *string, err error, experimentID *int, experimentUUID *string, variantID *int, variantName *string) types.EvaluationEvent
-	Stop(ctx context.Context)
+	CreateExperimentEvaluationEvent(parameterName string, attribute Attribute, rolloutValue *string, err error, experimentID *int, experimentUUID *string, variantID *int, variantName *string, variantOverridden bool) types.EvaluationEvent
+	Stop(ctx context.Context) error
 }
 
 // Attribute interface for dependency injection
@@ -39,10 +40,14 @@ type BatchEventTracker struct {
 	flushTimer  *time.Timer
 	flushChan   chan struct{}
 	sender      EventSender
+	metrics     types.MetricsCollector
 }
 
-// NewBatchEventTracker creates a new batch event tracker
-func NewBatchEventTracker(endpointURL, serviceName string, logger logger.Logger, batchConfig types.BatchConfig, sender EventSender) EventTracker {
+// NewBatchEventTracker creates a new batch event tracker reporting the size of each batch sent in metrics
+func NewBatchEventTracker(endpointURL, serviceName string, logger logger.Logger, batchConfig types.BatchConfig, sender EventSender, metrics types.MetricsCollector) EventTracker {
+	if metrics == nil {
+		metrics = types.NoopMetricsCollector{}
+	}
 	return &BatchEventTracker{
 		endpointURL: endpointURL,
 		serviceName: serviceName,
@@ -52,12 +57,21 @@ func NewBatchEventTracker(endpointURL, serviceName string, logger logger.Logger,
 		lastFlush:   time.Now(),
 		flushChan:   make(chan struct{}, 1),
 		sender:      sender,
+		metrics:     metrics,
 	}
 }
 
 // TrackEvent adds an event to the batch
 func (t *BatchEventTracker) TrackEvent(ctx context.Context, event types.EvaluationEvent) {
-	t.eventBatch = append(t.eventBatch, event)
+	t.TrackEvents(ctx, []types.EvaluationEvent{event})
+}
+
+// TrackEvents adds several events to the batch at once, checking the flush threshold a single time
+func (t *BatchEventTracker) TrackEvents(ctx context.Context, events []types.EvaluationEvent) {
+	if len(events) == 0 {
+		return
+	}
+	t.eventBatch = append(t.eventBatch, events...)
 
 	// Check if we should flush immediately
 	if len(t.eventBatch) >= t.batchConfig.FlushSize {
@@ -98,20 +112,21 @@ func (t *BatchEventTracker) CreateParameterEvaluationEvent(parameterName string,
 }
 
 // CreateExperimentEvaluationEvent creates an experiment evaluation event
-func (t *BatchEventTracker) CreateExperimentEvaluationEvent(parameterName string, attribute Attribute, rolloutValue *string, err error, experimentID *int, experimentUUID *string, variantID *int, variantName *string) types.EvaluationEvent {
+func (t *BatchEventTracker) CreateExperimentEvaluationEvent(parameterName string, attribute Attribute, rolloutValue *string, err error, experimentID *int, experimentUUID *string, variantID *int, variantName *string, variantOverridden bool) types.EvaluationEvent {
 	event := types.EvaluationEvent{
-		ID:             generateEventID(),
-		ServiceName:    t.serviceName,
-		EventType:      types.EventTypeExperimentEvaluation,
-		ParameterName:  parameterName,
-		Source:         "experiment",
-		UserAttributes: attribute.ToMap(),
-		RolloutValue:   rolloutValue,
-		Timestamp:      time.Now(),
-		ExperimentID:   experimentID,
-		ExperimentUUID: experimentUUID,
-		VariantID:      variantID,
-		VariantName:    variantName,
+		ID:                generateEventID(),
+		ServiceName:       t.serviceName,
+		EventType:         types.EventTypeExperimentEvaluation,
+		ParameterName:     parameterName,
+		Source:            "experiment",
+		UserAttributes:    attribute.ToMap(),
+		RolloutValue:      rolloutValue,
+		Timestamp:         time.Now(),
+		ExperimentID:      experimentID,
+		ExperimentUUID:    experimentUUID,
+		VariantID:         variantID,
+		VariantName:       variantName,
+		VariantOverridden: variantOverridden,
 	}
 
 	if err != nil {
@@ -122,19 +137,20 @@ func (t *BatchEventTracker) CreateExperimentEvaluationEvent(parameterName string
 	return event
 }
 
-// Stop stops the event tracker and flushes any pending events
-func (t *BatchEventTracker) Stop(ctx context.Context) {
+// Stop stops the event tracker and flushes any pending events, returning the error of the final send
+func (t *BatchEventTracker) Stop(ctx context.Context) error {
 	if t.flushTimer != nil {
 		t.flushTimer.Stop()
 	}
-	t.flushEvents(ctx)
+	err := t.flushEvents(ctx)
 	close(t.flushChan)
+	return err
 }
 
 // flushEvents sends the current batch of events
-func (t *BatchEventTracker) flushEvents(ctx context.Context) {
+func (t *BatchEventTracker) flushEvents(ctx context.Context) error {
 	if len(t.eventBatch) == 0 {
-		return
+		return nil
 	}
 
 	events := make([]types.EvaluationEvent, len(t.eventBatch))
@@ -147,9 +163,12 @@ func (t *BatchEventTracker) flushEvents(ctx context.Context) {
 	}
 
 	if err := t.sender.SendEvents(ctx, events); err != nil {
+		t.metrics.ObserveEventBatch(len(events), true)
 		t.logger.Error("failed to send events", "error", err)
+		return err
 	}
-	
+	t.metrics.ObserveEventBatch(len(events), false)
+	return nil
 }
 
 // generateEventID generates a unique event ID
@@ -3,6 +3,7 @@ package events
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sdk/pkg/errors"
 	"sdk/pkg/logger"
 	"sdk/types"
@@ -13,13 +14,18 @@ import (
 // HTTPEventSender implements EventSender using HTTP
 type HTTPEventSender struct {
 	endpointURL string
+	apiKey      string
+	client      *resty.Client
 	logger      logger.Logger
 }
 
-// NewHTTPEventSender creates a new HTTP event sender
-func NewHTTPEventSender(endpointURL string, logger logger.Logger) EventSender {
+// NewHTTPEventSender creates a new HTTP event sender sending its requests through httpClient.
+// It authenticates with apiKey when it is set.
+func NewHTTPEventSender(endpointURL string, apiKey string, httpClient *http.Client, logger logger.Logger) EventSender {
 	return &HTTPEventSender{
 		endpointURL: endpointURL,
+		apiKey:      apiKey,
+		client:      resty.NewWithClient(httpClient),
 		logger:      logger,
 	}
 }
@@ -30,9 +36,6 @@ func (s *HTTPEventSender) SendEvents(ctx context.Context, events []types.Evaluat
 		return nil
 	}
 
-	client := resty.New()
-	defer client.Close()
-
 	// Convert SDK events to API format
 	apiEvents := make([]map[string]interface{}, len(events))
 	for i, event := range events {
@@ -64,6 +67,9 @@ func (s *HTTPEventSender) SendEvents(ctx context.Context, events []types.Evaluat
 		if event.VariantName != nil {
 			apiEvent["variantName"] = *event.VariantName
 		}
+		if event.VariantOverridden {
+			apiEvent["variantOverridden"] = true
+		}
 
 		apiEvents[i] = apiEvent
 	}
@@ -75,10 +81,14 @@ func (s *HTTPEventSender) SendEvents(ctx context.Context, events []types.Evaluat
 
 	s.logger.Debug("sending events batch", "count", len(events), "endpoint", fmt.Sprintf("%s/api/v1/sdk/events", s.endpointURL))
 
-	response, err := client.R().
+	request := s.client.R().
 		SetContext(ctx).
-		SetBody(batchRequest).
-		Post(fmt.Sprintf("%s/api/v1/sdk/events", s.endpointURL))
+		SetBody(batchRequest)
+	if s.apiKey != "" {
+		request.SetHeader(types.APIKeyHeader, s.apiKey)
+	}
+
+	response, err := request.Post(fmt.Sprintf("%s/api/v1/sdk/events", s.endpointURL))
 
 	if err != nil {
 		s.logger.Error("failed to send events", "error", err, "count", len(events))
@@ -1,12 +1,15 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"sdk/pkg/errors"
 	"sdk/pkg/logger"
 	"sdk/types"
+	"slices"
+	"strconv"
+	"sync"
 
 	"github.com/dgraph-io/badger/v4"
 )
@@ -15,29 +18,58 @@ import (
 type BadgerStorage struct {
 	db     *badger.DB
 	logger logger.Logger
+	// keyPrefix namespaces every key, so several clients can share a database
+	keyPrefix string
+	// persistMu serializes the replacements of datasets, which each pick the next generation
+	persistMu sync.Mutex
 }
 
 // NewBadgerStorage creates a new BadgerDB storage instance
 func NewBadgerStorage(db *badger.DB, logger logger.Logger) Storage {
-	return &BadgerStorage{
+	return NewPrefixedBadgerStorage(db, "", logger)
+}
+
+// NewPrefixedBadgerStorage creates a BadgerDB storage instance whose keys are namespaced by prefix.
+// Storages with different prefixes can share a database without overwriting each other's data.
+func NewPrefixedBadgerStorage(db *badger.DB, prefix string, logger logger.Logger) Storage {
+	return newBadgerStorage(db, prefix, logger)
+}
+
+func newBadgerStorage(db *badger.DB, prefix string, logger logger.Logger) *BadgerStorage {
+	s := &BadgerStorage{
 		db:     db,
 		logger: logger,
 	}
+	// The separator keeps the keys of a prefix from starting with the keys of an unprefixed storage
+	if prefix != "" {
+		s.keyPrefix = prefix + "/"
+	}
+	return s
 }
 
-// PersistParameters stores parameters in the database
+// PersistParameters replaces the stored parameters. They are written under a new generation that readers
+// switch to at once, so readers see either the previous or the new parameters and never a dataset that
+// is half written. Parameters missing from parameters, such as those that were deleted, are removed.
 func (s *BadgerStorage) PersistParameters(ctx context.Context, parameters []types.Parameter) error {
-	for _, parameter := range parameters {
-		jsonParameters, err := json.Marshal(parameter)
+	encoded := make([][]byte, len(parameters))
+	for i, parameter := range parameters {
+		jsonParameter, err := json.Marshal(parameter)
 		if err != nil {
 			return errors.NewStorageError("marshal parameter", err)
 		}
-		err = s.db.Update(func(txn *badger.Txn) error {
-			return txn.Set([]byte(fmt.Sprintf("parameters:%s", parameter.Name)), jsonParameters)
-		})
-		if err != nil {
-			return errors.NewStorageError("store parameter", err)
+		encoded[i] = jsonParameter
+	}
+
+	err := s.replaceDataset(parameterDataset, []string{parameterKeyPrefix}, func(batch *badger.WriteBatch, generation string) error {
+		for i, parameter := range parameters {
+			if err := batch.Set(s.parameterKey(generation, parameter.Name), encoded[i]); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return errors.NewStorageError("store parameters", err)
 	}
 	return nil
 }
@@ -46,7 +78,11 @@ func (s *BadgerStorage) PersistParameters(ctx context.Context, parameters []type
 func (s *BadgerStorage) GetParameterByName(ctx context.Context, name string) (types.Parameter, error) {
 	var parameter types.Parameter
 	err := s.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte(fmt.Sprintf("parameters:%s", name)))
+		generation, err := s.generation(txn, parameterDataset)
+		if err != nil {
+			return err
+		}
+		item, err := txn.Get(s.parameterKey(generation, name))
 		if err != nil {
 			return err
 		}
@@ -60,93 +96,354 @@ func (s *BadgerStorage) GetParameterByName(ctx context.Context, name string) (ty
 	return parameter, nil
 }
 
-// PersistExperiments stores experiments in the database
-func (s *BadgerStorage) PersistExperiments(ctx context.Context, experiments []types.Experiment) error {
-	mapParameters := make(map[string][]string)
-
-	// Store experiments
-	for _, experiment := range experiments {
-		jsonExperiments, err := json.Marshal(experiment)
+// GetParametersByNames retrieves several parameters in a single read transaction.
+// Parameters that do not exist are omitted from the result.
+func (s *BadgerStorage) GetParametersByNames(ctx context.Context, names []string) (map[string]types.Parameter, error) {
+	parameters := make(map[string]types.Parameter, len(names))
+	err := s.db.View(func(txn *badger.Txn) error {
+		generation, err := s.generation(txn, parameterDataset)
 		if err != nil {
-			return errors.NewStorageError("marshal experiment", err)
+			return err
 		}
-		err = s.db.Update(func(txn *badger.Txn) error {
-			return txn.Set([]byte(experiment.Name), jsonExperiments)
-		})
-		if err != nil {
-			return errors.NewStorageError("store experiment", err)
+		for _, name := range names {
+			item, err := txn.Get(s.parameterKey(generation, name))
+			if err == badger.ErrKeyNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			var parameter types.Parameter
+			if err := item.Value(func(val []byte) error {
+				return json.Unmarshal(val, &parameter)
+			}); err != nil {
+				return err
+			}
+			parameters[name] = parameter
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.NewStorageError("get parameters by names", err)
 	}
+	return parameters, nil
+}
+
+const (
+	// parameterKeyPrefix prefixes parameters, stored by generation and name
+	parameterKeyPrefix = "parameters:"
+	// experimentKeyPrefix prefixes experiments, stored by generation and UUID
+	experimentKeyPrefix = "experiments:"
+	// experimentIndexKeyPrefix prefixes the UUIDs of the experiments setting a parameter, stored by
+	// generation and parameter name
+	experimentIndexKeyPrefix = "idx:experiment-param:"
+	// generationKeyPrefix prefixes the generation the keys of a dataset are read from, stored by dataset
+	generationKeyPrefix = "generations:"
+	// versionKeyPrefix prefixes data versions. It names the key layout, so that a store written with
+	// an older layout fetches its data again instead of serving it from keys that are no longer read.
+	versionKeyPrefix = "versions:v3:"
+)
+
+const (
+	// parameterDataset names the generation of the parameters
+	parameterDataset = "parameters"
+	// experimentDataset names the generation of the experiments and their index
+	experimentDataset = "experiments"
+)
+
+func (s *BadgerStorage) parameterKey(generation string, name string) []byte {
+	return s.key(parameterKeyPrefix, generation+":"+name)
+}
+
+func (s *BadgerStorage) experimentKey(generation string, uuid string) []byte {
+	return s.key(experimentKeyPrefix, generation+":"+uuid)
+}
+
+func (s *BadgerStorage) experimentIndexKey(generation string, parameterName string) []byte {
+	return s.key(experimentIndexKeyPrefix, generation+":"+parameterName)
+}
+
+func (s *BadgerStorage) generationKey(dataset string) []byte {
+	return s.key(generationKeyPrefix, dataset)
+}
+
+func (s *BadgerStorage) versionKey(dataset string) []byte {
+	return s.key(versionKeyPrefix, dataset)
+}
+
+// key returns the key of name among the keys starting with kindPrefix
+func (s *BadgerStorage) key(kindPrefix string, name string) []byte {
+	return []byte(s.keyPrefix + kindPrefix + name)
+}
+
+// PersistExperiments replaces the stored experiments and their index by parameter name under a new
+// generation, so readers see either the previous or the new experiments and never a half-written index.
+// Experiments missing from experiments, such as those that ended, are removed along with their index entries.
+func (s *BadgerStorage) PersistExperiments(ctx context.Context, experiments []types.Experiment) error {
+	encoded := make([][]byte, len(experiments))
+	index := make(map[string][]string)
+	for i, experiment := range experiments {
+		jsonExperiment, err := json.Marshal(experiment)
+		if err != nil {
+			return errors.NewStorageError("marshal experiment", err)
+		}
+		encoded[i] = jsonExperiment
 
-	// Build parameter to experiment mapping
-	for _, experiment := range experiments {
 		for _, variant := range experiment.Variants {
 			for _, parameter := range variant.Parameters {
-				mapParameters[parameter.ParameterName] = append(mapParameters[parameter.ParameterName], experiment.Name)
+				// Variants usually set the same parameters, so each experiment is indexed once per parameter
+				if !slices.Contains(index[parameter.ParameterName], experiment.Uuid) {
+					index[parameter.ParameterName] = append(index[parameter.ParameterName], experiment.Uuid)
+				}
 			}
 		}
 	}
 
-	// Store parameter mappings
-	for k, v := range mapParameters {
-		jsonParameters, err := json.Marshal(v)
+	encodedIndex := make(map[string][]byte, len(index))
+	for parameterName, uuids := range index {
+		jsonUUIDs, err := json.Marshal(uuids)
 		if err != nil {
-			return errors.NewStorageError("marshal parameters", err)
+			return errors.NewStorageError("marshal experiment index", err)
 		}
-		err = s.db.Update(func(txn *badger.Txn) error {
-			return txn.Set([]byte(fmt.Sprintf("experiments:parameters:%s", k)), jsonParameters)
-		})
-		if err != nil {
-			return errors.NewStorageError("store parameters", err)
+		encodedIndex[parameterName] = jsonUUIDs
+	}
+
+	err := s.replaceDataset(experimentDataset, []string{experimentKeyPrefix, experimentIndexKeyPrefix}, func(batch *badger.WriteBatch, generation string) error {
+		for i, experiment := range experiments {
+			if err := batch.Set(s.experimentKey(generation, experiment.Uuid), encoded[i]); err != nil {
+				return err
+			}
+		}
+		for parameterName, jsonUUIDs := range encodedIndex {
+			if err := batch.Set(s.experimentIndexKey(generation, parameterName), jsonUUIDs); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return errors.NewStorageError("store experiments", err)
 	}
 	return nil
 }
 
+// generation returns the generation of dataset that txn reads, "0" when none was written yet
+func (s *BadgerStorage) generation(txn *badger.Txn, dataset string) (string, error) {
+	item, err := txn.Get(s.generationKey(dataset))
+	if err == badger.ErrKeyNotFound {
+		return "0", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// replaceDataset writes a dataset under the next generation and then switches readers to it. write sets
+// the keys of the generation through batch, which commits them in as many transactions as needed, since
+// a single transaction cannot hold a large dataset. Keys of the previous generations, under kindPrefixes,
+// are removed once readers switched.
+func (s *BadgerStorage) replaceDataset(dataset string, kindPrefixes []string, write func(batch *badger.WriteBatch, generation string) error) error {
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	var current string
+	if err := s.db.View(func(txn *badger.Txn) error {
+		var err error
+		current, err = s.generation(txn, dataset)
+		return err
+	}); err != nil {
+		return err
+	}
+	number, err := strconv.ParseUint(current, 10, 64)
+	if err != nil {
+		return err
+	}
+	next := strconv.FormatUint(number+1, 10)
+
+	// A write that failed may have left keys under the next generation
+	for _, kindPrefix := range kindPrefixes {
+		if err := s.deleteKeys(s.key(kindPrefix, next+":"), nil); err != nil {
+			return err
+		}
+	}
+
+	batch := s.db.NewWriteBatch()
+	defer batch.Cancel()
+	if err := write(batch, next); err != nil {
+		return err
+	}
+	if err := batch.Flush(); err != nil {
+		return err
+	}
+
+	if err := s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(s.generationKey(dataset), []byte(next))
+	}); err != nil {
+		return err
+	}
+
+	// Readers no longer see the previous generations; keys left by a failed cleanup are removed next time
+	for _, kindPrefix := range kindPrefixes {
+		if err := s.deleteKeys(s.key(kindPrefix, ""), s.key(kindPrefix, next+":")); err != nil {
+			s.logger.Warn("failed to remove previous generation", "dataset", dataset, "error", err)
+		}
+	}
+	return nil
+}
+
+// deleteKeys deletes every key starting with prefix, except the keys starting with keep when it is set.
+// The deletions are batched, so any number of keys can be deleted.
+func (s *BadgerStorage) deleteKeys(prefix []byte, keep []byte) error {
+	var keys [][]byte
+	err := s.db.View(func(txn *badger.Txn) error {
+		options := badger.DefaultIteratorOptions
+		options.PrefetchValues = false
+		options.Prefix = prefix
+
+		it := txn.NewIterator(options)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			if keep != nil && bytes.HasPrefix(it.Item().Key(), keep) {
+				continue
+			}
+			keys = append(keys, it.Item().KeyCopy(nil))
+		}
+		return nil
+	})
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+
+	batch := s.db.NewWriteBatch()
+	defer batch.Cancel()
+	for _, key := range keys {
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+	}
+	return batch.Flush()
+}
+
 // GetExperimentsByParameterName retrieves experiments that contain a specific parameter
 func (s *BadgerStorage) GetExperimentsByParameterName(ctx context.Context, parameterName string) ([]types.Experiment, error) {
-	var experimentNames []string
 	var experiments []types.Experiment
 	err := s.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte(fmt.Sprintf("experiments:parameters:%s", parameterName)))
+		generation, err := s.generation(txn, experimentDataset)
 		if err != nil {
 			return err
 		}
-
-		return item.Value(func(val []byte) error {
-			return json.Unmarshal(val, &experimentNames)
-		})
+		experiments, err = s.experimentsByParameterName(txn, generation, parameterName, nil)
+		return err
 	})
 	if err != nil {
 		return []types.Experiment{}, errors.NewStorageError("get experiments by parameter name", err)
 	}
+	return experiments, nil
+}
 
-	for _, experimentName := range experimentNames {
-		experiment, err := s.getExperimentByName(ctx, experimentName)
+// GetExperimentsByParameterNames retrieves the experiments for several parameters in a single read
+// transaction, decoding each experiment once even when it is shared by several parameters.
+// Parameters without experiments are omitted from the result.
+func (s *BadgerStorage) GetExperimentsByParameterNames(ctx context.Context, parameterNames []string) (map[string][]types.Experiment, error) {
+	result := make(map[string][]types.Experiment, len(parameterNames))
+	resolved := make(map[string]types.Experiment)
+	err := s.db.View(func(txn *badger.Txn) error {
+		generation, err := s.generation(txn, experimentDataset)
 		if err != nil {
-			return []types.Experiment{}, errors.NewStorageError("get experiments by parameter name", err)
+			return err
+		}
+		for _, parameterName := range parameterNames {
+			experiments, err := s.experimentsByParameterName(txn, generation, parameterName, resolved)
+			if err != nil {
+				return err
+			}
+			if len(experiments) > 0 {
+				result[parameterName] = experiments
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.NewStorageError("get experiments by parameter names", err)
+	}
+	return result, nil
+}
+
+// experimentsByParameterName looks up the index entry of a parameter in generation and gets each experiment
+// it lists. Experiments already in resolved are not decoded again; a nil resolved disables the cache.
+func (s *BadgerStorage) experimentsByParameterName(txn *badger.Txn, generation string, parameterName string, resolved map[string]types.Experiment) ([]types.Experiment, error) {
+	item, err := txn.Get(s.experimentIndexKey(generation, parameterName))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var uuids []string
+	if err := item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &uuids)
+	}); err != nil {
+		return nil, err
+	}
+
+	experiments := make([]types.Experiment, 0, len(uuids))
+	for _, uuid := range uuids {
+		experiment, ok := resolved[uuid]
+		if !ok {
+			item, err := txn.Get(s.experimentKey(generation, uuid))
+			if err != nil {
+				return nil, err
+			}
+			if err := item.Value(func(val []byte) error {
+				return json.Unmarshal(val, &experiment)
+			}); err != nil {
+				return nil, err
+			}
+			if resolved != nil {
+				resolved[uuid] = experiment
+			}
 		}
 		experiments = append(experiments, experiment)
 	}
 	return experiments, nil
 }
 
-// getExperimentByName retrieves an experiment by name
-func (s *BadgerStorage) getExperimentByName(ctx context.Context, name string) (types.Experiment, error) {
-	var experiment types.Experiment
+// GetDataVersion returns the stored version of a dataset, or an empty string if none was stored
+func (s *BadgerStorage) GetDataVersion(ctx context.Context, dataset string) (string, error) {
+	var version string
 	err := s.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte(name))
+		item, err := txn.Get(s.versionKey(dataset))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
 		if err != nil {
 			return err
 		}
 		return item.Value(func(val []byte) error {
-			return json.Unmarshal(val, &experiment)
+			version = string(val)
+			return nil
 		})
 	})
 	if err != nil {
-		return types.Experiment{}, errors.NewStorageError("get experiment by name", err)
+		return "", errors.NewStorageError("get data version", err)
 	}
-	return experiment, nil
+	return version, nil
+}
+
+// PersistDataVersion stores the version of a dataset
+func (s *BadgerStorage) PersistDataVersion(ctx context.Context, dataset string, version string) error {
+	err := s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(s.versionKey(dataset), []byte(version))
+	})
+	if err != nil {
+		return errors.NewStorageError("store data version", err)
+	}
+	return nil
 }
 
 // Close closes the storage
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sdk/pkg/logger"
+	"sdk/types"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func openInMemoryStorage(t testing.TB) Storage {
+	t.Helper()
+	store, err := OpenBadgerStorage("", "", true, false, logger.NewDefaultLogger(slog.LevelError+4))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close(context.Background()) })
+	return store
+}
+
+// experimentsOnParameters returns count experiments, experiment i setting parameter "param-<i % parameters>"
+func experimentsOnParameters(count, parameters int) []types.Experiment {
+	experiments := make([]types.Experiment, count)
+	for i := range experiments {
+		experiments[i] = types.Experiment{
+			ID:   i + 1,
+			Name: fmt.Sprintf("experiment-%d", i),
+			Uuid: fmt.Sprintf("uuid-%d", i),
+			Variants: []types.ExperimentVariant{{
+				Parameters: []types.ExperimentVariantParameter{{ParameterName: fmt.Sprintf("param-%d", i%parameters)}},
+			}},
+		}
+	}
+	return experiments
+}
+
+func TestPersistExperimentsReplacesIndex(t *testing.T) {
+	ctx := context.Background()
+	store := openInMemoryStorage(t)
+
+	require.NoError(t, store.PersistExperiments(ctx, experimentsOnParameters(4, 2)))
+	experiments, err := store.GetExperimentsByParameterName(ctx, "param-0")
+	require.NoError(t, err)
+	require.Len(t, experiments, 2)
+
+	// Experiments that ended are no longer returned, even for parameters no experiment sets anymore
+	require.NoError(t, store.PersistExperiments(ctx, experimentsOnParameters(1, 2)))
+	experiments, err = store.GetExperimentsByParameterName(ctx, "param-0")
+	require.NoError(t, err)
+	require.Equal(t, []string{"uuid-0"}, []string{experiments[0].Uuid})
+
+	experiments, err = store.GetExperimentsByParameterName(ctx, "param-1")
+	require.NoError(t, err)
+	require.Empty(t, experiments)
+
+	byParameter, err := store.GetExperimentsByParameterNames(ctx, []string{"param-0", "param-1"})
+	require.NoError(t, err)
+	require.Len(t, byParameter["param-0"], 1)
+	require.NotContains(t, byParameter, "param-1")
+}
+
+func BenchmarkGetExperimentsByParameterName(b *testing.B) {
+	ctx := context.Background()
+	store := openInMemoryStorage(b)
+	require.NoError(b, store.PersistExperiments(ctx, experimentsOnParameters(500, 100)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.GetExperimentsByParameterName(ctx, fmt.Sprintf("param-%d", i%100)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestPrefixedStoragesShareDatabase(t *testing.T) {
+	ctx := context.Background()
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	defer db.Close()
+	log := logger.NewDefaultLogger(slog.LevelError + 4)
+	unprefixed := NewBadgerStorage(db, log)
+	checkout := NewPrefixedBadgerStorage(db, "checkout", log)
+	search := NewPrefixedBadgerStorage(db, "search", log)
+
+	for _, store := range []Storage{unprefixed, checkout, search} {
+		require.NoError(t, store.PersistExperiments(ctx, experimentsOnParameters(2, 1)))
+	}
+	require.NoError(t, unprefixed.PersistParameters(ctx, []types.Parameter{{Name: "color"}}))
+	require.NoError(t, checkout.PersistParameters(ctx, []types.Parameter{{Name: "color"}}))
+	require.NoError(t, checkout.PersistDataVersion(ctx, "parameters", "v1"))
+
+	// Replacing the datasets of one storage leaves the others untouched
+	require.NoError(t, search.PersistParameters(ctx, []types.Parameter{{Name: "query"}}))
+	require.NoError(t, search.PersistExperiments(ctx, nil))
+
+	_, err = checkout.GetParameterByName(ctx, "color")
+	require.NoError(t, err)
+	_, err = unprefixed.GetParameterByName(ctx, "color")
+	require.NoError(t, err)
+	_, err = search.GetParameterByName(ctx, "color")
+	require.Error(t, err)
+
+	experiments, err := checkout.GetExperimentsByParameterName(ctx, "param-0")
+	require.NoError(t, err)
+	require.Len(t, experiments, 2)
+	experiments, err = search.GetExperimentsByParameterName(ctx, "param-0")
+	require.NoError(t, err)
+	require.Empty(t, experiments)
+
+	version, err := unprefixed.GetDataVersion(ctx, "parameters")
+	require.NoError(t, err)
+	require.Empty(t, version)
+}
+
+// largerThanTransaction is a number of 2KB entries above the 10MB a transaction holds with the default options
+const largerThanTransaction = 6000
+
+func TestPersistParametersLargerThanTransaction(t *testing.T) {
+	ctx := context.Background()
+	store := openInMemoryStorage(t)
+
+	parameters := make([]types.Parameter, largerThanTransaction)
+	for i := range parameters {
+		parameters[i] = types.Parameter{Name: fmt.Sprintf("param-%d", i), DefaultRolloutValue: strings.Repeat("x", 2048)}
+	}
+	require.NoError(t, store.PersistParameters(ctx, parameters))
+	require.NoError(t, store.PersistParameters(ctx, parameters[:500]))
+
+	stored, err := store.GetParametersByNames(ctx, []string{"param-0", "param-499", "param-500", "param-5999"})
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	require.Contains(t, stored, "param-499")
+}
+
+func TestPersistExperimentsLargerThanTransaction(t *testing.T) {
+	ctx := context.Background()
+	store := openInMemoryStorage(t)
+
+	experiments := experimentsOnParameters(largerThanTransaction, 100)
+	for i := range experiments {
+		experiments[i].Name = strings.Repeat("x", 2048)
+	}
+	require.NoError(t, store.PersistExperiments(ctx, experiments))
+	byParameter, err := store.GetExperimentsByParameterNames(ctx, []string{"param-0", "param-99"})
+	require.NoError(t, err)
+	require.Len(t, byParameter["param-0"], 60)
+	require.Len(t, byParameter["param-99"], 60)
+
+	require.NoError(t, store.PersistExperiments(ctx, experiments[:100]))
+	experimentsOnParameter, err := store.GetExperimentsByParameterName(ctx, "param-0")
+	require.NoError(t, err)
+	require.Len(t, experimentsOnParameter, 1)
+}
+
+func TestPersistParametersKeepsReadersOnPreviousGeneration(t *testing.T) {
+	ctx := context.Background()
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	defer db.Close()
+	store := newBadgerStorage(db, "", logger.NewDefaultLogger(slog.LevelError+4))
+	require.NoError(t, store.PersistParameters(ctx, []types.Parameter{{Name: "color", DefaultRolloutValue: "red"}}))
+
+	// A read that started before the switch keeps seeing the whole previous generation
+	txn := db.NewTransaction(false)
+	defer txn.Discard()
+	require.NoError(t, store.PersistParameters(ctx, []types.Parameter{{Name: "color", DefaultRolloutValue: "blue"}}))
+
+	generation, err := store.generation(txn, parameterDataset)
+	require.NoError(t, err)
+	item, err := txn.Get(store.parameterKey(generation, "color"))
+	require.NoError(t, err)
+	value, err := item.ValueCopy(nil)
+	require.NoError(t, err)
+	require.Contains(t, string(value), "red")
+
+	parameter, err := store.GetParameterByName(ctx, "color")
+	require.NoError(t, err)
+	require.Equal(t, "blue", parameter.DefaultRolloutValue)
+}
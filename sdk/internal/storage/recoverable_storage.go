@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"sdk/pkg/errors"
+	"sdk/pkg/logger"
+	"sdk/types"
+	"sync"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// maxConsecutiveReadFailures is the number of failed reads in a row after which the
+// on-disk store is considered corrupted and rebuilt on the next refresh
+const maxConsecutiveReadFailures = 3
+
+// RecoverableBadgerStorage wraps an on-disk BadgerStorage. When the directory cannot be opened,
+// for example because another process holds its lock, it falls back to an in-memory database
+// and reports itself as degraded. When reads keep failing on an opened store, it asks to be
+// rebuilt, which wipes the directory and opens it again.
+type RecoverableBadgerStorage struct {
+	path   string
+	prefix string
+	logger logger.Logger
+
+	mu       sync.RWMutex
+	storage  *BadgerStorage
+	degraded bool
+
+	failuresMu   sync.Mutex
+	readFailures int
+}
+
+// OpenBadgerStorage opens a BadgerDB storage at path, or in memory when inMemory is set, with its keys
+// namespaced by prefix. With fallback enabled, a store that cannot be opened on disk is replaced by an
+// in-memory one instead of returning an error.
+func OpenBadgerStorage(path string, prefix string, inMemory bool, fallback bool, logger logger.Logger) (Storage, error) {
+	if inMemory {
+		db, err := openInMemory()
+		if err != nil {
+			return nil, errors.NewConfigurationError("failed to open storage", err)
+		}
+		return NewPrefixedBadgerStorage(db, prefix, logger), nil
+	}
+
+	db, err := openOnDisk(path)
+	if err != nil && !fallback {
+		return nil, errors.NewConfigurationError("failed to open storage", err)
+	}
+
+	s := &RecoverableBadgerStorage{
+		path:   path,
+		prefix: prefix,
+		logger: logger,
+	}
+	if err != nil {
+		if db, err = s.fallbackToMemory(err); err != nil {
+			return nil, errors.NewConfigurationError("failed to open storage", err)
+		}
+	}
+	s.storage = newBadgerStorage(db, prefix, logger)
+	return s, nil
+}
+
+func openOnDisk(path string) (*badger.DB, error) {
+	return badger.Open(badger.DefaultOptions(path))
+}
+
+func openInMemory() (*badger.DB, error) {
+	return badger.Open(badger.DefaultOptions("").WithInMemory(true))
+}
+
+// fallbackToMemory opens an in-memory database after the on-disk store failed to open with cause
+func (s *RecoverableBadgerStorage) fallbackToMemory(cause error) (*badger.DB, error) {
+	s.logger.Warn("STORAGE DEGRADED: failed to open on-disk storage, falling back to in-memory storage. "+
+		"Evaluations keep working but data is not kept across restarts",
+		"path", s.path, "error", cause)
+	db, err := openInMemory()
+	if err != nil {
+		return nil, err
+	}
+	s.degraded = true
+	return db, nil
+}
+
+// Degraded reports whether the storage runs in memory because the on-disk store could not be opened
+func (s *RecoverableBadgerStorage) Degraded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.degraded
+}
+
+// NeedsRebuild reports whether reads have failed consistently since the last successful one
+func (s *RecoverableBadgerStorage) NeedsRebuild() bool {
+	s.failuresMu.Lock()
+	defer s.failuresMu.Unlock()
+	return s.readFailures >= maxConsecutiveReadFailures
+}
+
+// Rebuild closes the store, wipes the on-disk directory and opens it again empty.
+// A degraded storage only replaces its in-memory database.
+func (s *RecoverableBadgerStorage) Rebuild(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.storage.db.Close(); err != nil {
+		s.logger.WarnContext(ctx, "failed to close storage before rebuild", "error", err)
+	}
+
+	var db *badger.DB
+	var err error
+	if s.degraded {
+		db, err = openInMemory()
+	} else {
+		if err := os.RemoveAll(s.path); err != nil {
+			return errors.NewStorageError("wipe storage directory", err)
+		}
+		db, err = openOnDisk(s.path)
+		if err != nil {
+			db, err = s.fallbackToMemory(err)
+		}
+	}
+	if err != nil {
+		return errors.NewStorageError("rebuild storage", err)
+	}
+
+	s.storage = newBadgerStorage(db, s.prefix, s.logger)
+	s.failuresMu.Lock()
+	s.readFailures = 0
+	s.failuresMu.Unlock()
+	s.logger.InfoContext(ctx, "storage rebuilt", "path", s.path, "degraded", s.degraded)
+	return nil
+}
+
+// recordRead counts consecutive failed reads. Missing keys are not failures.
+func (s *RecoverableBadgerStorage) recordRead(err error) {
+	s.failuresMu.Lock()
+	defer s.failuresMu.Unlock()
+	if err == nil || stderrors.Is(err, badger.ErrKeyNotFound) {
+		s.readFailures = 0
+		return
+	}
+	s.readFailures++
+	if s.readFailures == maxConsecutiveReadFailures {
+		s.logger.Warn("storage reads keep failing, storage will be rebuilt on the next refresh", "path", s.path, "error", err)
+	}
+}
+
+// PersistParameters stores parameters in the database
+func (s *RecoverableBadgerStorage) PersistParameters(ctx context.Context, parameters []types.Parameter) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.storage.PersistParameters(ctx, parameters)
+}
+
+// GetParameterByName retrieves a parameter by name
+func (s *RecoverableBadgerStorage) GetParameterByName(ctx context.Context, name string) (types.Parameter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	parameter, err := s.storage.GetParameterByName(ctx, name)
+	s.recordRead(err)
+	return parameter, err
+}
+
+// GetParametersByNames retrieves several parameters in a single read transaction
+func (s *RecoverableBadgerStorage) GetParametersByNames(ctx context.Context, names []string) (map[string]types.Parameter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	parameters, err := s.storage.GetParametersByNames(ctx, names)
+	s.recordRead(err)
+	return parameters, err
+}
+
+// PersistExperiments stores experiments in the database
+func (s *RecoverableBadgerStorage) PersistExperiments(ctx context.Context, experiments []types.Experiment) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.storage.PersistExperiments(ctx, experiments)
+}
+
+// GetExperimentsByParameterName retrieves experiments that contain a specific parameter
+func (s *RecoverableBadgerStorage) GetExperimentsByParameterName(ctx context.Context, parameterName string) ([]types.Experiment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	experiments, err := s.storage.GetExperimentsByParameterName(ctx, parameterName)
+	s.recordRead(err)
+	return experiments, err
+}
+
+// GetExperimentsByParameterNames retrieves the experiments for several parameters in a single read transaction
+func (s *RecoverableBadgerStorage) GetExperimentsByParameterNames(ctx context.Context, parameterNames []string) (map[string][]types.Experiment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	experiments, err := s.storage.GetExperimentsByParameterNames(ctx, parameterNames)
+	s.recordRead(err)
+	return experiments, err
+}
+
+// GetDataVersion returns the stored version of a dataset
+func (s *RecoverableBadgerStorage) GetDataVersion(ctx context.Context, dataset string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	version, err := s.storage.GetDataVersion(ctx, dataset)
+	s.recordRead(err)
+	return version, err
+}
+
+// PersistDataVersion stores the version of a dataset
+func (s *RecoverableBadgerStorage) PersistDataVersion(ctx context.Context, dataset string, version string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.storage.PersistDataVersion(ctx, dataset, version)
+}
+
+// Close closes the storage
+func (s *RecoverableBadgerStorage) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storage.Close(ctx)
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sdk/pkg/errors"
+	"sdk/pkg/logger"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func openRecoverable(t *testing.T, path string) *RecoverableBadgerStorage {
+	t.Helper()
+	store, err := OpenBadgerStorage(path, "", false, true, logger.NewDefaultLogger(slog.LevelError+4))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close(context.Background()) })
+	return store.(*RecoverableBadgerStorage)
+}
+
+func TestOpenBadgerStorageFallsBackOnLockConflict(t *testing.T) {
+	path := t.TempDir()
+	first := openRecoverable(t, path)
+	require.False(t, first.Degraded())
+
+	second := openRecoverable(t, path)
+	require.True(t, second.Degraded())
+
+	_, err := OpenBadgerStorage(path, "", false, false, logger.NewDefaultLogger(slog.LevelError+4))
+	var sdkErr *errors.SDKError
+	require.ErrorAs(t, err, &sdkErr)
+	require.True(t, sdkErr.IsType(errors.ErrorTypeConfigurationError))
+}
+
+func TestOpenBadgerStorageFallsBackOnPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	parent := t.TempDir()
+	require.NoError(t, os.Chmod(parent, 0o500))
+	t.Cleanup(func() { os.Chmod(parent, 0o700) })
+
+	store := openRecoverable(t, filepath.Join(parent, "sdk-dump"))
+	require.True(t, store.Degraded())
+}
+
+func TestOpenBadgerStorageFallsBackOnCorruptedManifest(t *testing.T) {
+	path := t.TempDir()
+	db, err := badger.Open(badger.DefaultOptions(path))
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(path, badger.ManifestFilename), []byte("corrupted"), 0o644))
+
+	store := openRecoverable(t, path)
+	require.True(t, store.Degraded())
+}
+
+func TestRecoverableBadgerStorageRebuildsAfterConsistentReadFailures(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+	store := openRecoverable(t, path)
+
+	require.NoError(t, store.storage.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(store.storage.parameterKey("0", "broken"), []byte("{"))
+	}))
+
+	// Missing keys are not failures
+	_, err := store.GetParameterByName(ctx, "missing")
+	require.Error(t, err)
+	require.False(t, store.NeedsRebuild())
+
+	for i := 0; i < maxConsecutiveReadFailures; i++ {
+		_, err := store.GetParameterByName(ctx, "broken")
+		require.Error(t, err)
+	}
+	require.True(t, store.NeedsRebuild())
+
+	require.NoError(t, store.Rebuild(ctx))
+	require.False(t, store.NeedsRebuild())
+	require.False(t, store.Degraded())
+
+	_, err = store.GetParameterByName(ctx, "broken")
+	require.ErrorIs(t, err, badger.ErrKeyNotFound)
+}
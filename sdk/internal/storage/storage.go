@@ -10,10 +10,16 @@ type Storage interface {
 	// Parameter operations
 	PersistParameters(ctx context.Context, parameters []types.Parameter) error
 	GetParameterByName(ctx context.Context, name string) (types.Parameter, error)
+	GetParametersByNames(ctx context.Context, names []string) (map[string]types.Parameter, error)
 
 	// Experiment operations
 	PersistExperiments(ctx context.Context, experiments []types.Experiment) error
 	GetExperimentsByParameterName(ctx context.Context, parameterName string) ([]types.Experiment, error)
+	GetExperimentsByParameterNames(ctx context.Context, parameterNames []string) (map[string][]types.Experiment, error)
+
+	// Version operations
+	GetDataVersion(ctx context.Context, dataset string) (string, error)
+	PersistDataVersion(ctx context.Context, dataset string, version string) error
 
 	// Lifecycle operations
 	Close(ctx context.Context) error
@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // ErrorType represents the category of error
 type ErrorType string
@@ -22,6 +25,14 @@ const (
 	ErrorTypeValidationError ErrorType = "validation_error"
 	// ErrorTypeTimeoutError indicates a timeout error
 	ErrorTypeTimeoutError ErrorType = "timeout_error"
+	// ErrorTypeObjectNotFound indicates a remote object does not exist
+	ErrorTypeObjectNotFound ErrorType = "object_not_found"
+	// ErrorTypeThrottled indicates a remote service rejected the request due to rate limiting
+	ErrorTypeThrottled ErrorType = "throttled"
+	// ErrorTypeNotModified indicates remote data has not changed since it was last fetched
+	ErrorTypeNotModified ErrorType = "not_modified"
+	// ErrorTypeRequestRejected indicates a remote service rejected the request itself, so retrying it cannot succeed
+	ErrorTypeRequestRejected ErrorType = "request_rejected"
 )
 
 // SDKError represents different types of errors that can occur in the SDK
@@ -129,3 +140,68 @@ func NewTimeoutError(operation string, cause error) *SDKError {
 		cause,
 	)
 }
+
+// NewObjectNotFoundError creates an object not found error
+func NewObjectNotFoundError(key string, cause error) *SDKError {
+	return NewSDKError(
+		ErrorTypeObjectNotFound,
+		fmt.Sprintf("object '%s' not found", key),
+		cause,
+	)
+}
+
+// NewThrottledError creates a throttled error
+func NewThrottledError(operation string, cause error) *SDKError {
+	return NewSDKError(
+		ErrorTypeThrottled,
+		fmt.Sprintf("operation throttled: %s", operation),
+		cause,
+	)
+}
+
+// NewRequestRejectedError creates a request rejected error
+func NewRequestRejectedError(operation string, cause error) *SDKError {
+	return NewSDKError(
+		ErrorTypeRequestRejected,
+		fmt.Sprintf("request rejected: %s", operation),
+		cause,
+	)
+}
+
+// NewNotModifiedError creates a not modified error
+func NewNotModifiedError(resource string) *SDKError {
+	return NewSDKError(
+		ErrorTypeNotModified,
+		fmt.Sprintf("%s not modified", resource),
+		nil,
+	)
+}
+
+// IsNotModified reports whether err signals that remote data has not changed
+func IsNotModified(err error) bool {
+	var sdkErr *SDKError
+	return errors.As(err, &sdkErr) && sdkErr.Type == ErrorTypeNotModified
+}
+
+// IsRetryable reports whether err is a transient failure, such as a network error,
+// a timeout or throttling, that may succeed if retried or served from another source
+func IsRetryable(err error) bool {
+	var sdkErr *SDKError
+	if !errors.As(err, &sdkErr) {
+		return false
+	}
+	switch sdkErr.Type {
+	case ErrorTypeNetworkError, ErrorTypeTimeoutError, ErrorTypeThrottled:
+		return true
+	}
+	return false
+}
+
+// TypeOf returns the type of an SDK error, or an empty type when err is not one
+func TypeOf(err error) ErrorType {
+	var sdkErr *SDKError
+	if !errors.As(err, &sdkErr) {
+		return ""
+	}
+	return sdkErr.Type
+}
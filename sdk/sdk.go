@@ -26,7 +26,7 @@
 //	if err != nil {
 //		log.Fatal(err)
 //	}
-//	defer client.Stop()
+//	defer client.Stop(context.Background())
 //
 //	// Create user attributes
 //	attrs := sdk.NewAttribute().
@@ -46,8 +46,11 @@ package sdk
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
 	"sdk/internal/client"
 	"sdk/internal/config"
 	"sdk/internal/engine"
@@ -56,19 +59,45 @@ import (
 	"sdk/pkg/errors"
 	"sdk/pkg/logger"
 	"sdk/types"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/dgraph-io/badger/v4"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
 // Client interface defines the main SDK operations
 type Client interface {
 	Start(ctx context.Context) error
-	Stop()
+	// Stop stops background refreshes, sends the pending evaluation events and closes storage.
+	// It returns an error when the events could not be sent before ctx is done.
+	Stop(ctx context.Context) error
+	// Close stops the client without a deadline, ignoring events that could not be sent.
+	//
+	// Deprecated: use Stop, which takes a context bounding the shutdown.
+	Close()
+	Refresh(ctx context.Context) error
 	EvaluateParameter(ctx context.Context, parameterName string, attribute *Attribute) RolloutValue
+	EvaluateParameterDetailed(ctx context.Context, parameterName string, attribute *Attribute) (RolloutValue, types.EvaluationDetails)
+	// EvaluateParameterTraced also records each rule and condition decision in the details' trace.
+	// It tracks no evaluation event and is meant for debugging and simulation.
+	EvaluateParameterTraced(ctx context.Context, parameterName string, attribute *Attribute) (RolloutValue, types.EvaluationDetails)
+	// EvaluateParameterTracedWith evaluates like EvaluateParameterTraced a parameter and experiments supplied
+	// by the caller instead of those fetched by the client, for example to simulate another environment.
+	EvaluateParameterTracedWith(ctx context.Context, parameter *types.Parameter, experiments []types.Experiment, attribute *Attribute) (RolloutValue, types.EvaluationDetails)
+	EvaluateParameters(ctx context.Context, parameterNames []string, attribute *Attribute) map[string]RolloutValue
+	EvaluateExperimentDetailed(ctx context.Context, experiment *types.Experiment, attribute *Attribute, parameterName string) *types.ExperimentEvaluationResult
 	GetMetadata(ctx context.Context) (*types.MetadataResponse, error)
+	// GetParameterDebug returns a parameter and its rules as cached by the client, to inspect why attributes
+	// get a value. Nothing is evaluated and no event is tracked. A ParameterNotFoundError is returned
+	// when the parameter is not cached.
+	GetParameterDebug(ctx context.Context, parameterName string) (*types.Parameter, error)
+	// SyncStatus reports when data was last refreshed successfully, the error of the last refresh
+	// and how many parameters and experiments were received. It is safe to call concurrently.
+	SyncStatus() types.SyncStatus
 }
 
 // Attribute represents a collection of key-value pairs used for evaluation
@@ -150,9 +179,13 @@ func (a *Attribute) ToMap() map[string]interface{} {
 
 // RolloutValue represents a value with its associated data type
 type RolloutValue struct {
-	value    *string
-	dataType types.ParameterDataType
-	err      error
+	value       *string
+	dataType    types.ParameterDataType
+	enumOptions []string
+	reason      types.EvaluationReason
+	err         error
+	// typeMismatch is set by GetValue when the value could not be read as the requested type
+	typeMismatch error
 }
 
 // NewRolloutValue creates a new RolloutValue instance
@@ -160,15 +193,28 @@ func NewRolloutValue(value *string, dataType types.ParameterDataType) RolloutVal
 	return RolloutValue{
 		value:    value,
 		dataType: dataType,
+		reason:   types.EvaluationReasonDefault,
 		err:      nil,
 	}
 }
 
+// NewEnumRolloutValue creates a new enum RolloutValue that is validated against its options
+func NewEnumRolloutValue(value *string, enumOptions []string) RolloutValue {
+	return RolloutValue{
+		value:       value,
+		dataType:    types.ParameterDataTypeEnum,
+		enumOptions: enumOptions,
+		reason:      types.EvaluationReasonDefault,
+		err:         nil,
+	}
+}
+
 // NewRolloutValueWithError creates a new RolloutValue with an error
 func NewRolloutValueWithError(err error) RolloutValue {
 	return RolloutValue{
 		value:    nil,
 		dataType: "",
+		reason:   client.ReasonForError(err),
 		err:      err,
 	}
 }
@@ -185,6 +231,9 @@ func (rv RolloutValue) Error() error {
 
 // AsString returns the value as a string, or defaultValue if conversion fails or there's an error
 func (rv RolloutValue) AsString(defaultValue string) string {
+	if rv.dataType == types.ParameterDataTypeEnum {
+		return rv.AsEnum(defaultValue)
+	}
 	if rv.HasError() || rv.dataType != types.ParameterDataTypeString {
 		return defaultValue
 	}
@@ -239,27 +288,113 @@ func (rv RolloutValue) AsBool(defaultValue bool) bool {
 	return value
 }
 
-func (rv RolloutValue) raw() *string {
+// AsTime returns the value as a time.Time, or defaultValue if conversion fails or there's an error
+func (rv RolloutValue) AsTime(defaultValue time.Time) time.Time {
+	if rv.HasError() || rv.dataType != types.ParameterDataTypeDate {
+		return defaultValue
+	}
+	if rv.value == nil {
+		return defaultValue
+	}
+	value, err := types.ParseDate(*rv.value)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// AsEnum returns the value of an enum parameter, or defaultValue if the value is not one of its options or there's an error
+func (rv RolloutValue) AsEnum(defaultValue string) string {
+	if rv.HasError() || rv.dataType != types.ParameterDataTypeEnum {
+		return defaultValue
+	}
+	if rv.value == nil {
+		return defaultValue
+	}
+	if !slices.Contains(rv.enumOptions, *rv.value) {
+		return defaultValue
+	}
+	return *rv.value
+}
+
+// AsJSON unmarshals the value of a json parameter into target, which must be a pointer
+func (rv RolloutValue) AsJSON(target interface{}) error {
+	if rv.HasError() {
+		return rv.err
+	}
+	if rv.dataType != types.ParameterDataTypeJSON {
+		return errors.NewValidationError(fmt.Sprintf("value has data type %s, not json", rv.dataType), nil)
+	}
+	if rv.value == nil {
+		return errors.NewValidationError("json value is empty", nil)
+	}
+	if err := types.ParseJSON(*rv.value, target); err != nil {
+		return errors.NewValidationError("failed to unmarshal json value", err)
+	}
+	return nil
+}
+
+// EnumOptions returns the allowed options of an enum value
+func (rv RolloutValue) EnumOptions() []string {
+	return rv.enumOptions
+}
+
+// Reason returns why the evaluation returned this value, for example a matching rule
+// or the parameter's default because the attributes were outside every experiment population
+func (rv RolloutValue) Reason() types.EvaluationReason {
+	return rv.reason
+}
+
+// DataType returns the data type of the value, or an empty data type when there's an error
+func (rv RolloutValue) DataType() types.ParameterDataType {
+	return rv.dataType
+}
+
+// Raw returns the value as stored, before any conversion, or nil when there's none
+func (rv RolloutValue) Raw() *string {
 	return rv.value
 }
 
+// TypeMismatch returns why the last GetValue on the value returned its default because the value was
+// not of the requested type, or nil. Evaluation errors are reported by Error instead.
+func (rv RolloutValue) TypeMismatch() error {
+	return rv.typeMismatch
+}
+
 // ClientOptions holds the required configuration options for the client
 type ClientOptions struct {
 	S3BucketName string
 	EndpointURL  string
 	ServiceName  string
+	// APIKey is sent in the X-Aurora-Api-Key header of every request to the Aurora backend
+	APIKey string
+	// Environment is the key of the environment, such as "prod", whose parameters and experiments are
+	// fetched. Without one the client gets the base parameters and the experiments of every environment.
+	Environment string
 }
 
 // Option represents a functional option for configuring the client
 type Option func(*config.Config)
 
-// WithRefreshRate sets the refresh rate for parameter updates
+// WithRefreshRate sets the refresh rate for parameter updates.
+// A refresh rate of 0 disables the background refresh loop; the caller is then
+// responsible for calling Client.Refresh to pick up configuration changes.
 func WithRefreshRate(refreshRate time.Duration) Option {
 	return func(c *config.Config) {
 		c.RefreshRate = refreshRate
 	}
 }
 
+// WithStreaming keeps a connection to the Aurora backend's change stream open and refreshes as soon as
+// a parameter or experiment change is announced, instead of waiting for the next refresh. The refresh
+// loop keeps polling at the refresh rate, so changes still arrive while the stream is down.
+// Streaming is ignored when data is loaded with WithLocalPath.
+func WithStreaming(enabled bool) Option {
+	return func(c *config.Config) {
+		c.Streaming = enabled
+	}
+}
+
 // WithLogLevel sets the logging level
 func WithLogLevel(logLevel slog.Level) Option {
 	return func(c *config.Config) {
@@ -288,6 +423,53 @@ func WithPath(path string) Option {
 	}
 }
 
+// WithAllowVariantOverride lets attributes carrying types.ForceVariantAttribute pick their experiment
+// variant, so QA can check a variant without editing traffic allocation. It is disabled by default
+// and should stay disabled for production clients.
+func WithAllowVariantOverride(allow bool) Option {
+	return func(c *config.Config) {
+		c.AllowVariantOverride = allow
+	}
+}
+
+// WithNumberEpsilon makes number equals and not_equals conditions treat values within epsilon as equal,
+// for attributes computed with floating-point math: with an epsilon of 1e-9, a score of 0.1+0.2 equals 0.3.
+// Comparisons are exact by default. Other number operators are not affected.
+func WithNumberEpsilon(epsilon float64) Option {
+	return func(c *config.Config) {
+		c.NumberEpsilon = epsilon
+	}
+}
+
+// WithAssignmentStore makes experiment assignments sticky: the variant a unit is assigned is saved in
+// store and reused on later evaluations instead of hashing again, so raising an experiment's population
+// size does not move units between variants. Units without a stored assignment are hashed as usual.
+// Use NewInMemoryAssignmentStore for a single process, or implement types.AssignmentStore on a shared
+// store such as Redis.
+func WithAssignmentStore(store types.AssignmentStore) Option {
+	return func(c *config.Config) {
+		c.AssignmentStore = store
+	}
+}
+
+// WithStorageFallback controls what happens when the on-disk storage cannot be opened, for example
+// because another process holds its lock. When enabled, which is the default, the client keeps its
+// data in memory and reports the degraded mode through GetMetadata; otherwise NewClient fails.
+func WithStorageFallback(enabled bool) Option {
+	return func(c *config.Config) {
+		c.StorageFallback = enabled
+	}
+}
+
+// WithStoragePrefix namespaces the keys the client stores, so clients tracking different services can
+// share a storage path without overwriting each other's parameters and experiments. Without it keys
+// are not prefixed, as stored by earlier versions.
+func WithStoragePrefix(prefix string) Option {
+	return func(c *config.Config) {
+		c.StoragePrefix = prefix
+	}
+}
+
 // WithEnableS3 enables or disables S3 usage
 func WithEnableS3(enableS3 bool) Option {
 	return func(c *config.Config) {
@@ -295,6 +477,91 @@ func WithEnableS3(enableS3 bool) Option {
 	}
 }
 
+// WithAPIKey sets the API key sent in the X-Aurora-Api-Key header of every request to the Aurora backend,
+// overriding ClientOptions.APIKey
+func WithAPIKey(apiKey string) Option {
+	return func(c *config.Config) {
+		c.APIKey = apiKey
+	}
+}
+
+// WithSDKKey is an alias of WithAPIKey
+func WithSDKKey(key string) Option {
+	return WithAPIKey(key)
+}
+
+// WithHTTPClient sets the HTTP client used to fetch data from the Aurora backend and send
+// evaluation events, for example to go through a proxy or use custom TLS settings.
+// The default client times out requests after 10 seconds.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *config.Config) {
+		c.HTTPClient = httpClient
+	}
+}
+
+// WithHTTPTimeout bounds every request to the Aurora backend, including reading the response, for the
+// HTTP client set with WithHTTPClient or the default one. The configured client is left unchanged.
+// The change stream is not bounded, since it stays open.
+func WithHTTPTimeout(d time.Duration) Option {
+	return func(c *config.Config) {
+		c.HTTPTimeout = d
+	}
+}
+
+// WithRetry sets how many attempts are made for a request to the Aurora backend, at least 1, and the
+// wait before the first retry; it is a shorthand for WithMaxRetries(attempts-1) and WithRetryBackoff(backoff).
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(c *config.Config) {
+		c.MaxRetries = attempts - 1
+		c.RetryBackoff = backoff
+	}
+}
+
+// WithMaxRetries sets how many times a failed request to the Aurora backend is retried before the
+// refresh gives up. Network errors, throttling and 5xx responses are retried; other 4xx responses are not.
+// The default is 2 retries, so 3 attempts in total; 0 disables retries.
+func WithMaxRetries(n int) Option {
+	return func(c *config.Config) {
+		c.MaxRetries = n
+	}
+}
+
+// WithRetryBackoff sets the wait before the first retry of a failed request to the Aurora backend.
+// It doubles on every retry and is reduced by a random jitter of up to half. The default is 200ms.
+// Retries stop early when the wait would outlast the deadline of the refresh context.
+func WithRetryBackoff(base time.Duration) Option {
+	return func(c *config.Config) {
+		c.RetryBackoff = base
+	}
+}
+
+// WithLocalPath loads parameters.json and experiments.json from a local directory
+// instead of fetching them from the Aurora backend or S3. The files are read again
+// on every refresh, which makes evaluation deterministic in tests and usable offline.
+func WithLocalPath(dir string) Option {
+	return func(c *config.Config) {
+		c.LocalPath = dir
+	}
+}
+
+// WithBootstrapFile loads parameters.json and experiments.json, in the format of the objects the Aurora
+// sync workers write to S3, from the directory at path into storage when the client starts, before the
+// first fetch. Combined with WithDisableRefresh(true), the client runs offline from these files: no
+// endpoint URL is required, and evaluation events are not tracked since there is no backend to send them to.
+func WithBootstrapFile(path string) Option {
+	return func(c *config.Config) {
+		c.BootstrapPath = path
+	}
+}
+
+// WithDisableRefresh loads data only when the client starts: neither the background refresh loop
+// nor the change stream is started. Client.Refresh still fetches data on demand.
+func WithDisableRefresh(disable bool) Option {
+	return func(c *config.Config) {
+		c.DisableRefresh = disable
+	}
+}
+
 func WithOnEvaluate(onEvaluate func(source string, parameterName string, attribute *Attribute, rolloutValueRaw *string, err error)) Option {
 	return func(c *config.Config) {
 		// Convert the public Attribute to the internal interface
@@ -306,6 +573,32 @@ func WithOnEvaluate(onEvaluate func(source string, parameterName string, attribu
 	}
 }
 
+// WithDefaults registers fallback values for parameters, returned by evaluations when a parameter is
+// missing from storage. The data type follows the Go value: strings, bools, numbers and time.Time map to
+// string, boolean, number and date, and maps, slices and structs to json. Evaluations falling back to a
+// registered default report the "default" source. Calling it again adds to the defaults registered before.
+func WithDefaults(defaults map[string]interface{}) Option {
+	return func(c *config.Config) {
+		if c.Defaults == nil {
+			c.Defaults = make(map[string]interface{}, len(defaults))
+		}
+		for parameterName, value := range defaults {
+			c.Defaults[parameterName] = value
+		}
+	}
+}
+
+// WithMetrics reports evaluation counts and latencies and failed fetches to collector, for example to
+// export them to Prometheus. Without it, or with a nil collector, metrics are discarded.
+func WithMetrics(collector types.MetricsCollector) Option {
+	return func(c *config.Config) {
+		if collector == nil {
+			collector = types.NoopMetricsCollector{}
+		}
+		c.Metrics = collector
+	}
+}
+
 // WithBatchMaxSize sets the maximum number of events per batch
 func WithBatchMaxSize(maxSize int) Option {
 	return func(c *config.Config) {
@@ -343,68 +636,79 @@ func WithBatchFlushBytes(flushBytes int) Option {
 
 // NewClient creates a new Aurora SDK client with the given options
 func NewClient(clientOptions ClientOptions, options ...Option) (Client, error) {
-	// Validate required fields
-	if clientOptions.EndpointURL == "" {
-		return nil, errors.NewConfigurationError("endpoint URL is required", nil)
-	}
-
 	// Create configuration
 	cfg := config.DefaultConfig()
 	cfg.EndpointURL = clientOptions.EndpointURL
 	cfg.S3BucketName = clientOptions.S3BucketName
 	cfg.ServiceName = clientOptions.ServiceName
+	cfg.APIKey = clientOptions.APIKey
+	cfg.Environment = clientOptions.Environment
 
 	// Apply options
 	for _, option := range options {
 		option(cfg)
 	}
 
-	// Validate configuration
+	// Validate configuration; an offline client runs from its bootstrap files without an endpoint URL
+	if cfg.EndpointURL == "" && !cfg.Offline() {
+		return nil, errors.NewConfigurationError("endpoint URL is required", nil)
+	}
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
+	// Apply the timeout to a copy, so a client shared with the application keeps its own
+	if cfg.HTTPTimeout > 0 {
+		httpClient := *cfg.HTTPClient
+		httpClient.Timeout = cfg.HTTPTimeout
+		cfg.HTTPClient = &httpClient
+	}
+
 	// Initialize logger
 	if cfg.Logger == nil {
 		cfg.Logger = logger.NewDefaultLogger(cfg.LogLevel)
 	}
 
-	// Initialize BadgerDB
-	opt := badger.DefaultOptions(cfg.Path)
-	if cfg.InMemoryOnly {
-		opt = opt.WithInMemory(true)
-	}
-	db, err := badger.Open(opt)
+	// Initialize components
+	storage, err := storage.OpenBadgerStorage(cfg.Path, cfg.StoragePrefix, cfg.InMemoryOnly, cfg.StorageFallback, cfg.Logger)
 	if err != nil {
-		return nil, errors.NewConfigurationError("failed to open storage", err)
+		return nil, err
 	}
-
-	// Initialize components
-	storage := storage.NewBadgerStorage(db, cfg.Logger)
-	engineImpl := engine.NewEvaluationEngine(cfg.Logger)
+	engineImpl := engine.NewEvaluationEngine(cfg.Logger, cfg.AllowVariantOverride, cfg.NumberEpsilon)
 
 	// Create engine adapter
 	engineAdapter := &engineAdapter{engine: engineImpl}
 
 	// Initialize data fetcher
+	retryPolicy := client.RetryPolicy{MaxRetries: cfg.MaxRetries, Backoff: cfg.RetryBackoff}
 	var dataFetcher client.DataFetcher
-	if cfg.EnableS3 && cfg.S3Client != nil {
+	if cfg.LocalPath != "" {
+		dataFetcher = client.NewFileDataFetcher(cfg.LocalPath, cfg.Metrics, cfg.Logger)
+		cfg.Streaming = false
+	} else if cfg.Offline() {
+		dataFetcher = client.NewFileDataFetcher(cfg.BootstrapPath, cfg.Metrics, cfg.Logger)
+		cfg.Streaming = false
+	} else if cfg.EnableS3 && cfg.S3Client != nil && cfg.Environment == "" {
+		// The S3 objects hold the base data, so clients of an environment always fetch over HTTP
 		// Create S3 adapter
 		s3Adapter := &s3ClientAdapter{client: cfg.S3Client}
-		dataFetcher = client.NewS3DataFetcher(s3Adapter, cfg.S3BucketName, cfg.Logger, client.NewHTTPDataFetcher(cfg.EndpointURL, cfg.Logger))
+		dataFetcher = client.NewS3DataFetcher(s3Adapter, cfg.S3BucketName, cfg.Metrics, cfg.Logger, client.NewHTTPDataFetcher(cfg.EndpointURL, cfg.APIKey, cfg.Environment, cfg.HTTPClient, retryPolicy, cfg.Metrics, cfg.Logger))
 	} else {
-		dataFetcher = client.NewHTTPDataFetcher(cfg.EndpointURL, cfg.Logger)
+		dataFetcher = client.NewHTTPDataFetcher(cfg.EndpointURL, cfg.APIKey, cfg.Environment, cfg.HTTPClient, retryPolicy, cfg.Metrics, cfg.Logger)
 	}
 
-	// Initialize event tracker
-	eventSender := events.NewHTTPEventSender(cfg.EndpointURL, cfg.Logger)
-	eventTrackerImpl := events.NewBatchEventTracker(cfg.EndpointURL, cfg.ServiceName, cfg.Logger, cfg.BatchConfig, eventSender)
+	// Initialize event tracker; an offline client has no backend to send events to, so it tracks none
+	var eventTracker client.EventTracker
+	if !cfg.Offline() {
+		eventSender := events.NewHTTPEventSender(cfg.EndpointURL, cfg.APIKey, cfg.HTTPClient, cfg.Logger)
+		eventTrackerImpl := events.NewBatchEventTracker(cfg.EndpointURL, cfg.ServiceName, cfg.Logger, cfg.BatchConfig, eventSender, cfg.Metrics)
 
-	// Create event tracker adapter
-	eventTrackerAdapter := &eventTrackerAdapter{tracker: eventTrackerImpl}
+		// Create event tracker adapter
+		eventTracker = &eventTrackerAdapter{tracker: eventTrackerImpl}
+	}
 
 	// Create client
-	auroraClient := client.NewAuroraClient(cfg, storage, engineAdapter, eventTrackerAdapter, dataFetcher)
+	auroraClient := client.NewAuroraClient(cfg, storage, engineAdapter, eventTracker, dataFetcher)
 
 	// Wrap with adapter to match public interface
 	return &clientAdapter{
@@ -421,8 +725,16 @@ func (a *clientAdapter) Start(ctx context.Context) error {
 	return a.client.Start(ctx)
 }
 
-func (a *clientAdapter) Stop() {
-	a.client.Stop()
+func (a *clientAdapter) Stop(ctx context.Context) error {
+	return a.client.Stop(ctx)
+}
+
+func (a *clientAdapter) Close() {
+	a.client.Stop(context.Background())
+}
+
+func (a *clientAdapter) Refresh(ctx context.Context) error {
+	return a.client.Refresh(ctx)
 }
 
 func (a *clientAdapter) EvaluateParameter(ctx context.Context, parameterName string, attribute *Attribute) RolloutValue {
@@ -430,12 +742,60 @@ func (a *clientAdapter) EvaluateParameter(ctx context.Context, parameterName str
 	internalAttr := &attributeAdapter{attribute: attribute}
 	result := a.client.EvaluateParameter(ctx, parameterName, internalAttr)
 
-	// Convert internal RolloutValue to public type
+	return toRolloutValue(result)
+}
+
+func (a *clientAdapter) EvaluateParameterDetailed(ctx context.Context, parameterName string, attribute *Attribute) (RolloutValue, types.EvaluationDetails) {
+	// Convert public Attribute to internal interface
+	internalAttr := &attributeAdapter{attribute: attribute}
+	result, details := a.client.EvaluateParameterDetailed(ctx, parameterName, internalAttr)
+
+	return toRolloutValue(result), details
+}
+
+func (a *clientAdapter) EvaluateParameterTraced(ctx context.Context, parameterName string, attribute *Attribute) (RolloutValue, types.EvaluationDetails) {
+	// Convert public Attribute to internal interface
+	internalAttr := &attributeAdapter{attribute: attribute}
+	result, details := a.client.EvaluateParameterTraced(ctx, parameterName, internalAttr)
+
+	return toRolloutValue(result), details
+}
+
+func (a *clientAdapter) EvaluateParameterTracedWith(ctx context.Context, parameter *types.Parameter, experiments []types.Experiment, attribute *Attribute) (RolloutValue, types.EvaluationDetails) {
+	// Convert public Attribute to internal interface
+	internalAttr := &attributeAdapter{attribute: attribute}
+	result, details := a.client.EvaluateParameterTracedWith(ctx, parameter, experiments, internalAttr)
+
+	return toRolloutValue(result), details
+}
+
+func (a *clientAdapter) EvaluateParameters(ctx context.Context, parameterNames []string, attribute *Attribute) map[string]RolloutValue {
+	// Convert public Attribute to internal interface
+	internalAttr := &attributeAdapter{attribute: attribute}
+	results := a.client.EvaluateParameters(ctx, parameterNames, internalAttr)
+
+	values := make(map[string]RolloutValue, len(results))
+	for name, result := range results {
+		values[name] = toRolloutValue(result)
+	}
+	return values
+}
+
+func (a *clientAdapter) EvaluateExperimentDetailed(ctx context.Context, experiment *types.Experiment, attribute *Attribute, parameterName string) *types.ExperimentEvaluationResult {
+	// Convert public Attribute to internal interface
+	internalAttr := &attributeAdapter{attribute: attribute}
+	return a.client.EvaluateExperimentDetailed(ctx, experiment, internalAttr, parameterName)
+}
+
+// toRolloutValue converts an internal RolloutValue to the public type
+func toRolloutValue(result client.RolloutValue) RolloutValue {
 	if impl, ok := result.(*client.RolloutValueImpl); ok {
 		return RolloutValue{
-			value:    impl.Raw(),
-			dataType: impl.DataType,
-			err:      impl.Error(),
+			value:       impl.Raw(),
+			dataType:    impl.DataType,
+			enumOptions: impl.EnumOptions(),
+			reason:      impl.Reason(),
+			err:         impl.Error(),
 		}
 	}
 
@@ -443,6 +803,7 @@ func (a *clientAdapter) EvaluateParameter(ctx context.Context, parameterName str
 	return RolloutValue{
 		value:    result.Raw(),
 		dataType: types.ParameterDataTypeString, // Default type
+		reason:   result.Reason(),
 		err:      result.Error(),
 	}
 }
@@ -451,6 +812,14 @@ func (a *clientAdapter) GetMetadata(ctx context.Context) (*types.MetadataRespons
 	return a.client.GetMetadata(ctx)
 }
 
+func (a *clientAdapter) GetParameterDebug(ctx context.Context, parameterName string) (*types.Parameter, error) {
+	return a.client.GetParameterDebug(ctx, parameterName)
+}
+
+func (a *clientAdapter) SyncStatus() types.SyncStatus {
+	return a.client.SyncStatus()
+}
+
 // attributeAdapter adapts public Attribute to internal interface
 type attributeAdapter struct {
 	attribute *Attribute
@@ -469,10 +838,33 @@ type s3ClientAdapter struct {
 	client *s3.Client
 }
 
-func (a *s3ClientAdapter) GetObject(ctx context.Context, input interface{}) (interface{}, error) {
-	// This would need to be implemented with actual S3 calls
-	// For now, return an error to trigger fallback
-	return nil, errors.NewNetworkError("S3 not implemented", fmt.Errorf("S3 client adapter not implemented"))
+func (a *s3ClientAdapter) GetObject(ctx context.Context, bucket string, key string) (io.ReadCloser, error) {
+	output, err := a.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, classifyS3Error(key, err)
+	}
+	return output.Body, nil
+}
+
+// s3ThrottlingCodes are the S3 error codes returned when requests are rate limited
+var s3ThrottlingCodes = []string{"SlowDown", "Throttling", "ThrottlingException", "RequestLimitExceeded", "TooManyRequestsException"}
+
+// classifyS3Error maps an S3 error to the SDK error type the data fetcher uses to decide on fallback
+func classifyS3Error(key string, err error) error {
+	var noSuchKey *s3types.NoSuchKey
+	if stderrors.As(err, &noSuchKey) {
+		return errors.NewObjectNotFoundError(key, err)
+	}
+
+	var apiErr smithy.APIError
+	if stderrors.As(err, &apiErr) && slices.Contains(s3ThrottlingCodes, apiErr.ErrorCode()) {
+		return errors.NewThrottledError(fmt.Sprintf("get S3 object %s", key), err)
+	}
+
+	return errors.NewNetworkError(fmt.Sprintf("get S3 object %s", key), err)
 }
 
 // engineAdapter adapts engine.Engine to client.Engine
@@ -486,6 +878,18 @@ func (a *engineAdapter) EvaluateParameter(parameter *types.Parameter, attribute
 	return a.engine.EvaluateParameter(parameter, attrAdapter)
 }
 
+func (a *engineAdapter) EvaluateParameterDetailed(parameter *types.Parameter, attribute client.Attribute) (string, types.EvaluationReason) {
+	// Convert client.Attribute to engine.Attribute
+	attrAdapter := &attributeToEngineAdapter{attr: attribute}
+	return a.engine.EvaluateParameterDetailed(parameter, attrAdapter)
+}
+
+func (a *engineAdapter) EvaluateParameterTraced(parameter *types.Parameter, attribute client.Attribute) (string, types.EvaluationReason, *types.EvaluationTrace) {
+	// Convert client.Attribute to engine.Attribute
+	attrAdapter := &attributeToEngineAdapter{attr: attribute}
+	return a.engine.EvaluateParameterTraced(parameter, attrAdapter)
+}
+
 func (a *engineAdapter) EvaluateExperiment(experiment *types.Experiment, attribute client.Attribute, parameterName string) (string, types.ParameterDataType, bool) {
 	// Convert client.Attribute to engine.Attribute
 	attrAdapter := &attributeToEngineAdapter{attr: attribute}
@@ -498,6 +902,12 @@ func (a *engineAdapter) EvaluateExperimentDetailed(experiment *types.Experiment,
 	return a.engine.EvaluateExperimentDetailed(experiment, attrAdapter, parameterName)
 }
 
+func (a *engineAdapter) EvaluateAssignedExperiment(experiment *types.Experiment, attribute client.Attribute, parameterName string, variantID int) *types.ExperimentEvaluationResult {
+	// Convert client.Attribute to engine.Attribute
+	attrAdapter := &attributeToEngineAdapter{attr: attribute}
+	return a.engine.EvaluateAssignedExperiment(experiment, attrAdapter, parameterName, variantID)
+}
+
 // eventTrackerAdapter adapts events.EventTracker to client.EventTracker
 type eventTrackerAdapter struct {
 	tracker events.EventTracker
@@ -507,20 +917,24 @@ func (a *eventTrackerAdapter) TrackEvent(ctx context.Context, event types.Evalua
 	a.tracker.TrackEvent(ctx, event)
 }
 
+func (a *eventTrackerAdapter) TrackEvents(ctx context.Context, events []types.EvaluationEvent) {
+	a.tracker.TrackEvents(ctx, events)
+}
+
 func (a *eventTrackerAdapter) CreateParameterEvaluationEvent(parameterName string, attribute client.Attribute, rolloutValue *string, err error) types.EvaluationEvent {
 	// Convert client.Attribute to events.Attribute
 	attrAdapter := &attributeToEventsAdapter{attr: attribute}
 	return a.tracker.CreateParameterEvaluationEvent(parameterName, attrAdapter, rolloutValue, err)
 }
 
-func (a *eventTrackerAdapter) CreateExperimentEvaluationEvent(parameterName string, attribute client.Attribute, rolloutValue *string, err error, experimentID *int, experimentUUID *string, variantID *int, variantName *string) types.EvaluationEvent {
+func (a *eventTrackerAdapter) CreateExperimentEvaluationEvent(parameterName string, attribute client.Attribute, rolloutValue *string, err error, experimentID *int, experimentUUID *string, variantID *int, variantName *string, variantOverridden bool) types.EvaluationEvent {
 	// Convert client.Attribute to events.Attribute
 	attrAdapter := &attributeToEventsAdapter{attr: attribute}
-	return a.tracker.CreateExperimentEvaluationEvent(parameterName, attrAdapter, rolloutValue, err, experimentID, experimentUUID, variantID, variantName)
+	return a.tracker.CreateExperimentEvaluationEvent(parameterName, attrAdapter, rolloutValue, err, experimentID, experimentUUID, variantID, variantName, variantOverridden)
 }
 
-func (a *eventTrackerAdapter) Stop(ctx context.Context) {
-	a.tracker.Stop(ctx)
+func (a *eventTrackerAdapter) Stop(ctx context.Context) error {
+	return a.tracker.Stop(ctx)
 }
 
 // attributeToEngineAdapter adapts client.Attribute to engine.Attribute
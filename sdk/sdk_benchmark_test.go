@@ -0,0 +1,104 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sdk/internal/client"
+	"sdk/internal/config"
+	"sdk/internal/engine"
+	"sdk/internal/events"
+	"sdk/internal/storage"
+	"sdk/pkg/logger"
+	"sdk/types"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+const benchmarkParameterCount = 50
+
+// discardSender drops events so the benchmark measures evaluation, not network I/O
+type discardSender struct{}
+
+func (discardSender) SendEvents(ctx context.Context, events []types.EvaluationEvent) error {
+	return nil
+}
+
+// newBenchmarkClient builds a client over in-memory storage seeded with count string parameters,
+// each with one attribute rule, and returns the client with the parameter names
+func newBenchmarkClient(b *testing.B, count int) (Client, []string) {
+	b.Helper()
+
+	cfg := config.DefaultConfig()
+	cfg.ServiceName = "benchmark"
+	// Parameters without experiments log a storage miss, so silence logging entirely
+	cfg.Logger = logger.NewDefaultLogger(slog.LevelError + 4)
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	store := storage.NewBadgerStorage(db, cfg.Logger)
+	names := make([]string, count)
+	parameters := make([]types.Parameter, count)
+	for i := range parameters {
+		names[i] = fmt.Sprintf("parameter_%d", i)
+		parameters[i] = types.Parameter{
+			Name:                names[i],
+			DataType:            types.ParameterDataTypeString,
+			DefaultRolloutValue: "default",
+			Rules: []types.ParameterRule{
+				{
+					Type:         types.RuleTypeAttribute,
+					MatchType:    types.ConditionMatchTypeMatch,
+					RolloutValue: "matched",
+					Conditions: []types.RuleCondition{
+						{AttributeName: "country", AttributeDataType: "string", Operator: types.ConditionOperatorEquals, Value: "VN"},
+					},
+				},
+			},
+		}
+	}
+	if err := store.PersistParameters(context.Background(), parameters); err != nil {
+		b.Fatal(err)
+	}
+
+	tracker := events.NewBatchEventTracker(cfg.EndpointURL, cfg.ServiceName, cfg.Logger, cfg.BatchConfig, discardSender{}, nil)
+	auroraClient := client.NewAuroraClient(
+		cfg,
+		store,
+		&engineAdapter{engine: engine.NewEvaluationEngine(cfg.Logger, false, 0)},
+		&eventTrackerAdapter{tracker: tracker},
+		nil,
+	)
+	c := &clientAdapter{client: auroraClient}
+	b.Cleanup(func() { c.Stop(context.Background()) })
+
+	return c, names
+}
+
+func BenchmarkEvaluateParameterLoop(b *testing.B) {
+	c, names := newBenchmarkClient(b, benchmarkParameterCount)
+	ctx := context.Background()
+	attribute := NewAttribute().SetString("country", "VN")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, name := range names {
+			c.EvaluateParameter(ctx, name, attribute)
+		}
+	}
+}
+
+func BenchmarkEvaluateParameters(b *testing.B) {
+	c, names := newBenchmarkClient(b, benchmarkParameterCount)
+	ctx := context.Background()
+	attribute := NewAttribute().SetString("country", "VN")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.EvaluateParameters(ctx, names, attribute)
+	}
+}
@@ -0,0 +1,491 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sdk/internal/config"
+	"sdk/pkg/errors"
+	"sdk/types"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newStopTestClient(t *testing.T, endpointURL string) Client {
+	t.Helper()
+	c, err := NewClient(ClientOptions{EndpointURL: endpointURL, ServiceName: "stop-test"},
+		WithRefreshRate(0),
+		WithInMemoryOnly(true),
+		WithLogLevel(slog.LevelError+4),
+		WithBatchFlushSize(100),
+	)
+	require.NoError(t, err)
+	return c
+}
+
+func TestStopFlushesPendingEvents(t *testing.T) {
+	var mu sync.Mutex
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []map[string]interface{} `json:"events"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		received = append(received, body.Events...)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := newStopTestClient(t, server.URL)
+	attribute := NewAttribute().SetString("user_id", "42")
+	c.EvaluateParameter(context.Background(), "first", attribute)
+	c.EvaluateParameter(context.Background(), "second", attribute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, c.Stop(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 2)
+	require.Equal(t, "first", received[0]["parameterName"])
+	require.Equal(t, "second", received[1]["parameterName"])
+}
+
+func TestStopReturnsTimeoutWhenFlushExceedsDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c := newStopTestClient(t, server.URL)
+	c.EvaluateParameter(context.Background(), "pending", NewAttribute())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := c.Stop(ctx)
+
+	var sdkErr *errors.SDKError
+	require.ErrorAs(t, err, &sdkErr)
+	require.True(t, sdkErr.IsType(errors.ErrorTypeTimeoutError))
+}
+
+// recordingTransport records the paths of the requests sent through it
+type recordingTransport struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (t *recordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.paths = append(t.paths, r.URL.Path)
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestWithHTTPClientSendsRequestsThroughClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	c, err := NewClient(ClientOptions{EndpointURL: server.URL, ServiceName: "http-client-test"},
+		WithRefreshRate(0),
+		WithInMemoryOnly(true),
+		WithEnableS3(false),
+		WithLogLevel(slog.LevelError+4),
+		WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Refresh(context.Background()))
+	c.EvaluateParameter(context.Background(), "missing", NewAttribute())
+	require.NoError(t, c.Stop(context.Background()))
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	require.ElementsMatch(t, []string{"/api/v1/sdk/experiments", "/api/v1/sdk/parameters", "/api/v1/sdk/events"}, transport.paths)
+}
+
+func TestWithAPIKeySetsHeaderOnRequests(t *testing.T) {
+	var mu sync.Mutex
+	keys := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys[r.URL.Path] = r.Header.Get("X-Aurora-Api-Key")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(ClientOptions{EndpointURL: server.URL, ServiceName: "api-key-test", APIKey: "overridden"},
+		WithRefreshRate(0),
+		WithInMemoryOnly(true),
+		WithEnableS3(false),
+		WithLogLevel(slog.LevelError+4),
+		WithAPIKey("aurora-key"),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Refresh(context.Background()))
+	c.EvaluateParameter(context.Background(), "missing", NewAttribute())
+	require.NoError(t, c.Stop(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, map[string]string{
+		"/api/v1/sdk/experiments": "aurora-key",
+		"/api/v1/sdk/parameters":  "aurora-key",
+		"/api/v1/sdk/events":      "aurora-key",
+	}, keys)
+}
+
+func TestWithSDKKeyAliasesWithAPIKey(t *testing.T) {
+	var cfg config.Config
+	WithSDKKey("aurora-key")(&cfg)
+	require.Equal(t, "aurora-key", cfg.APIKey)
+}
+
+func TestWithHTTPClientRejectsNilClient(t *testing.T) {
+	_, err := NewClient(ClientOptions{EndpointURL: "http://localhost", ServiceName: "http-client-test"}, WithHTTPClient(nil))
+	require.Error(t, err)
+}
+
+func TestWithRetryRecoversFromTransientFailures(t *testing.T) {
+	var mu sync.Mutex
+	failures := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		failures[r.URL.Path]++
+		attempt := failures[r.URL.Path]
+		mu.Unlock()
+		if attempt <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(ClientOptions{EndpointURL: server.URL, ServiceName: "retry-test"},
+		WithRefreshRate(0),
+		WithInMemoryOnly(true),
+		WithEnableS3(false),
+		WithLogLevel(slog.LevelError+4),
+		WithRetry(3, time.Millisecond),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Refresh(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 3, failures["/api/v1/sdk/experiments"])
+	require.Equal(t, 3, failures["/api/v1/sdk/parameters"])
+}
+
+func TestWithHTTPTimeoutAbortsHangingRequests(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c, err := NewClient(ClientOptions{EndpointURL: server.URL, ServiceName: "timeout-test"},
+		WithRefreshRate(0),
+		WithInMemoryOnly(true),
+		WithEnableS3(false),
+		WithLogLevel(slog.LevelError+4),
+		WithHTTPTimeout(50*time.Millisecond),
+		WithRetry(1, 0),
+	)
+	require.NoError(t, err)
+
+	start := time.Now()
+	require.Error(t, c.Refresh(context.Background()))
+	require.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestWithAssignmentStoreKeepsVariantsWhenAllocationChanges(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeExperiment := func(controlAllocation int) {
+		variant := func(id int, name string, allocation int) types.ExperimentVariant {
+			return types.ExperimentVariant{ID: id, Name: name, TrafficAllocation: allocation, Parameters: []types.ExperimentVariantParameter{
+				{ParameterName: "color", ParameterDataType: types.ParameterDataTypeString, RolloutValue: name},
+			}}
+		}
+		data, err := json.Marshal([]types.Experiment{{
+			ID:                1,
+			Uuid:              "experiment",
+			HashAttributeName: "user_id",
+			PopulationSize:    100,
+			Variants:          []types.ExperimentVariant{variant(1, "control", controlAllocation), variant(2, "treatment", 100-controlAllocation)},
+		}})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "experiments.json"), data, 0o644))
+	}
+	writeExperiment(50)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "parameters.json"), []byte(`[{"name":"color","dataType":"string"}]`), 0o644))
+
+	c, err := NewClient(ClientOptions{EndpointURL: "http://localhost", ServiceName: "sticky-test"},
+		WithLocalPath(dir),
+		WithRefreshRate(0),
+		WithInMemoryOnly(true),
+		WithLogLevel(slog.LevelError+4),
+		WithAssignmentStore(NewInMemoryAssignmentStore()),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(ctx))
+	defer c.Stop(ctx)
+
+	assigned := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		userID := strconv.Itoa(i)
+		assigned[userID] = c.EvaluateParameter(ctx, "color", NewAttribute().SetString("user_id", userID)).AsString("")
+	}
+	require.Contains(t, assigned, "0")
+
+	// Moving every new user to treatment does not move users already assigned control
+	writeExperiment(0)
+	require.NoError(t, c.Refresh(ctx))
+	for userID, color := range assigned {
+		require.Equal(t, color, c.EvaluateParameter(ctx, "color", NewAttribute().SetString("user_id", userID)).AsString(""), "user %s", userID)
+	}
+	require.Equal(t, "treatment", c.EvaluateParameter(ctx, "color", NewAttribute().SetString("user_id", "new")).AsString(""))
+}
+
+// recordingMetrics counts the metrics reported to it
+type recordingMetrics struct {
+	types.NoopMetricsCollector
+	mu          sync.Mutex
+	evaluations map[string]int
+	latencies   int
+	fetchErrors map[string]int
+	refreshes   []string
+	fetches     map[string]int
+}
+
+func (m *recordingMetrics) IncEvaluation(source string, parameterName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evaluations[source+":"+parameterName]++
+}
+
+func (m *recordingMetrics) ObserveEvaluationLatency(time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latencies++
+}
+
+func (m *recordingMetrics) IncFetchError(kind string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fetchErrors[kind]++
+}
+
+func (m *recordingMetrics) ObserveFetch(source string, target string, status string, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fetches[source+":"+target+":"+status]++
+}
+
+func (m *recordingMetrics) ObserveRefresh(_ time.Duration, status string, parameterCount int, experimentCount int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refreshes = append(m.refreshes, fmt.Sprintf("%s:%d:%d", status, parameterCount, experimentCount))
+}
+
+func TestWithDefaultsServesRegisteredDefaultsForMissingParameters(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "experiments.json"), []byte(`[]`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "parameters.json"), []byte(`[{"name":"color","dataType":"string","defaultRolloutValue":"red"}]`), 0o644))
+
+	sources := map[string]string{}
+	c, err := NewClient(ClientOptions{EndpointURL: "http://localhost", ServiceName: "defaults-test"},
+		WithLocalPath(dir),
+		WithRefreshRate(0),
+		WithInMemoryOnly(true),
+		WithLogLevel(slog.LevelError+4),
+		WithDefaults(map[string]interface{}{"color": "blue", "enabled": true, "limit": 5}),
+		WithOnEvaluate(func(source string, parameterName string, _ *Attribute, _ *string, _ error) {
+			sources[parameterName] = source
+		}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(ctx))
+	defer c.Stop(ctx)
+
+	// Stored parameters win over their registered default
+	require.Equal(t, "red", c.EvaluateParameter(ctx, "color", NewAttribute()).AsString(""))
+
+	enabled, details := c.EvaluateParameterDetailed(ctx, "enabled", NewAttribute())
+	require.NoError(t, enabled.Error())
+	require.True(t, enabled.AsBool(false))
+	require.Equal(t, types.EvaluationSourceDefault, details.Source)
+
+	// Accessors of another data type still fall back to their argument
+	values := c.EvaluateParameters(ctx, []string{"limit", "missing"}, NewAttribute())
+	require.Equal(t, 5, values["limit"].AsInt(0))
+	require.Equal(t, "fallback", values["limit"].AsString("fallback"))
+	require.Error(t, values["missing"].Error())
+
+	require.Equal(t, map[string]string{"color": "parameter", "enabled": "default", "limit": "default", "missing": "parameter"}, sources)
+}
+
+func TestWithDefaultsRejectsUnencodableDefault(t *testing.T) {
+	_, err := NewClient(ClientOptions{EndpointURL: "http://localhost", ServiceName: "defaults-test"},
+		WithDefaults(map[string]interface{}{"broken": nil}))
+	require.Error(t, err)
+}
+
+func TestWithMetricsReportsEvaluationsAndFetchErrors(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	metrics := &recordingMetrics{evaluations: map[string]int{}, fetchErrors: map[string]int{}, fetches: map[string]int{}}
+
+	c, err := NewClient(ClientOptions{EndpointURL: "http://localhost", ServiceName: "metrics-test"},
+		WithLocalPath(dir),
+		WithRefreshRate(0),
+		WithInMemoryOnly(true),
+		WithLogLevel(slog.LevelError+4),
+		WithMetrics(metrics),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(ctx))
+	defer c.Stop(ctx)
+
+	// Neither file exists yet, and the refresh stops at the first one
+	require.Equal(t, map[string]int{string(errors.ErrorTypeObjectNotFound): 1}, metrics.fetchErrors)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "experiments.json"), []byte(`[]`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "parameters.json"), []byte(`[{"name":"color","dataType":"string","defaultRolloutValue":"red"}]`), 0o644))
+	require.NoError(t, c.Refresh(ctx))
+
+	require.Equal(t, "red", c.EvaluateParameter(ctx, "color", NewAttribute()).AsString(""))
+	c.EvaluateParameters(ctx, []string{"color", "size"}, NewAttribute())
+
+	require.Equal(t, map[string]int{"parameter:color": 2, "error:size": 1}, metrics.evaluations)
+	require.Equal(t, 2, metrics.latencies)
+
+	// The failed refresh stored nothing, the second one both datasets
+	require.Equal(t, []string{"object_not_found:-1:-1", "ok:1:0"}, metrics.refreshes)
+	require.Equal(t, map[string]int{
+		"file:experiments.json:object_not_found": 1,
+		"file:experiments.json:ok":               1,
+		"file:parameters.json:ok":                1,
+	}, metrics.fetches)
+}
+
+func TestWithBootstrapFileRunsOfflineWithoutEndpoint(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "experiments.json"), []byte(`[]`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "parameters.json"), []byte(`[{"name":"color","dataType":"string","defaultRolloutValue":"red"}]`), 0o644))
+
+	// Refresh must be disabled to run without an endpoint
+	_, err := NewClient(ClientOptions{ServiceName: "bootstrap-test"}, WithBootstrapFile(dir))
+	require.Error(t, err)
+
+	sources := map[string]string{}
+	c, err := NewClient(ClientOptions{ServiceName: "bootstrap-test"},
+		WithBootstrapFile(dir),
+		WithDisableRefresh(true),
+		WithInMemoryOnly(true),
+		WithLogLevel(slog.LevelError+4),
+		WithDefaults(map[string]interface{}{"enabled": true}),
+		WithOnEvaluate(func(source string, parameterName string, _ *Attribute, _ *string, _ error) {
+			sources[parameterName] = source
+		}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(ctx))
+
+	require.Equal(t, "red", c.EvaluateParameter(ctx, "color", NewAttribute()).AsString(""))
+	require.True(t, c.EvaluateParameter(ctx, "enabled", NewAttribute()).AsBool(false))
+	require.Equal(t, map[string]string{"color": "parameter", "enabled": "default"}, sources)
+	require.Equal(t, 1, c.SyncStatus().ParameterCount)
+	require.NoError(t, c.Stop(ctx))
+}
+
+func TestGetParameterDebugReturnsCachedRules(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "experiments.json"), []byte(`[]`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "parameters.json"), []byte(`[{"name":"color","dataType":"string","defaultRolloutValue":"red","rules":[{"name":"vn","type":"attribute","rolloutValue":"blue","conditions":[{"attributeName":"country","attributeDataType":"string","operator":"equals","value":"VN"}]}]}]`), 0o644))
+
+	c, err := NewClient(ClientOptions{EndpointURL: "http://localhost", ServiceName: "debug-test"},
+		WithLocalPath(dir),
+		WithRefreshRate(0),
+		WithInMemoryOnly(true),
+		WithLogLevel(slog.LevelError+4),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(ctx))
+	defer c.Stop(ctx)
+
+	parameter, err := c.GetParameterDebug(ctx, "color")
+	require.NoError(t, err)
+	require.Len(t, parameter.Rules, 1)
+	require.Equal(t, "VN", parameter.Rules[0].Conditions[0].Value)
+
+	_, err = c.GetParameterDebug(ctx, "missing")
+	require.Equal(t, errors.ErrorTypeParameterNotFound, errors.TypeOf(err))
+}
+
+func TestGetValue(t *testing.T) {
+	value := func(raw string) *string { return &raw }
+
+	enum := NewEnumRolloutValue(value("grid"), []string{"grid", "list"})
+	require.Equal(t, "grid", GetValue(&enum, "list"))
+	require.NoError(t, enum.TypeMismatch())
+
+	boolean := NewRolloutValue(value("true"), types.ParameterDataTypeBoolean)
+	require.Equal(t, "fallback", GetValue(&boolean, "fallback"))
+	require.ErrorContains(t, boolean.TypeMismatch(), "cannot be read as string")
+	// Each read replaces the recorded mismatch
+	require.True(t, GetValue(&boolean, false))
+	require.NoError(t, boolean.TypeMismatch())
+
+	type layout struct {
+		Columns int `json:"columns"`
+	}
+	jsonValue := NewRolloutValue(value(`{"columns": 3}`), types.ParameterDataTypeJSON)
+	require.Equal(t, layout{Columns: 3}, GetValue(&jsonValue, layout{Columns: 2}))
+	require.Error(t, BindStruct(jsonValue, map[string]interface{}{}))
+
+	launch := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	fallback := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	date := NewRolloutValue(value("2025-03-01T00:00:00Z"), types.ParameterDataTypeDate)
+	require.True(t, launch.Equal(date.AsTime(fallback)))
+	epoch := NewRolloutValue(value("1740787200"), types.ParameterDataTypeDate)
+	require.True(t, launch.Equal(epoch.AsTime(fallback)))
+	invalidDate := NewRolloutValue(value("next week"), types.ParameterDataTypeDate)
+	require.Equal(t, fallback, invalidDate.AsTime(fallback))
+	// Only date parameters are read as times
+	require.Equal(t, fallback, NewRolloutValue(value("1740787200"), types.ParameterDataTypeNumber).AsTime(fallback))
+
+	// Evaluation errors are not type mismatches
+	failed := NewRolloutValueWithError(errors.NewParameterNotFoundError("checkout"))
+	require.Equal(t, 20, GetValue(&failed, 20))
+	require.NoError(t, failed.TypeMismatch())
+}
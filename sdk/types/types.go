@@ -1,6 +1,14 @@
 package types
 
-import "time"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // ParameterDataType represents the data type of a parameter
 type ParameterDataType string
@@ -9,8 +17,116 @@ const (
 	ParameterDataTypeBoolean ParameterDataType = "boolean"
 	ParameterDataTypeString  ParameterDataType = "string"
 	ParameterDataTypeNumber  ParameterDataType = "number"
+	ParameterDataTypeDate    ParameterDataType = "date"
+	ParameterDataTypeEnum    ParameterDataType = "enum"
+	ParameterDataTypeJSON    ParameterDataType = "json"
 )
 
+// ParseDate parses a date value stored either as an RFC3339 string or as unix epoch seconds
+func ParseDate(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// ParseJSON unmarshals a json parameter value into target. The value must hold an object or an array.
+func ParseJSON(value string, target interface{}) error {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return errors.New("json value must be an object or an array")
+	}
+	return json.Unmarshal([]byte(trimmed), target)
+}
+
+// Semver is a semantic version such as 2.14.3 or 3.0.0-beta.1
+type Semver struct {
+	Major, Minor, Patch int
+	// Prerelease holds the dot-separated identifiers after the hyphen, empty for a release
+	Prerelease []string
+}
+
+// ParseSemver parses a semantic version. A leading "v" and build metadata after "+" are ignored, and missing
+// minor and patch numbers are taken as 0, since mobile clients often report versions such as "2.15".
+func ParseSemver(value string) (Semver, error) {
+	version := strings.TrimPrefix(strings.TrimSpace(value), "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, prerelease, hasPrerelease := strings.Cut(version, "-")
+
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		return Semver{}, fmt.Errorf("invalid semantic version %q", value)
+	}
+	numbers := [3]int{}
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return Semver{}, fmt.Errorf("invalid semantic version %q", value)
+		}
+		numbers[i] = number
+	}
+
+	semver := Semver{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}
+	if hasPrerelease {
+		semver.Prerelease = strings.Split(prerelease, ".")
+		for _, identifier := range semver.Prerelease {
+			if identifier == "" {
+				return Semver{}, fmt.Errorf("invalid semantic version %q", value)
+			}
+		}
+	}
+	return semver, nil
+}
+
+// Compare returns -1, 0 or 1 when v precedes, equals or follows other. A prerelease precedes its release,
+// and prerelease identifiers are compared numerically when both are numbers and lexically otherwise.
+func (v Semver) Compare(other Semver) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if c := compareInts(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		a, aErr := strconv.Atoi(v.Prerelease[i])
+		b, bErr := strconv.Atoi(other.Prerelease[i])
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = compareInts(a, b)
+		case aErr == nil:
+			// Numeric identifiers precede alphanumeric ones
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(v.Prerelease[i], other.Prerelease[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(v.Prerelease), len(other.Prerelease))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
 // ConditionMatchType represents how conditions should be matched
 type ConditionMatchType string
 
@@ -19,6 +135,15 @@ const (
 	ConditionMatchTypeNotMatch ConditionMatchType = "not_match"
 )
 
+// ConditionLogic represents how the conditions of an attribute rule are combined.
+// Rules without a condition logic combine their conditions with and.
+type ConditionLogic string
+
+const (
+	ConditionLogicAnd ConditionLogic = "and"
+	ConditionLogicOr  ConditionLogic = "or"
+)
+
 // RuleType represents the type of rule (segment or attribute)
 type RuleType string
 
@@ -41,6 +166,21 @@ const (
 	ConditionOperatorLessThanOrEqual    ConditionOperator = "less_than_or_equal"
 	ConditionOperatorIn                 ConditionOperator = "in"
 	ConditionOperatorNotIn              ConditionOperator = "not_in"
+	ConditionOperatorBetween            ConditionOperator = "between"
+	ConditionOperatorNotBetween         ConditionOperator = "not_between"
+	ConditionOperatorMatchesRegex       ConditionOperator = "matches_regex"
+	ConditionOperatorNotMatchesRegex    ConditionOperator = "not_matches_regex"
+	// ConditionOperatorIsSet and ConditionOperatorIsNotSet check whether the attribute is present,
+	// whatever its data type; the condition value is ignored
+	ConditionOperatorIsSet    ConditionOperator = "is_set"
+	ConditionOperatorIsNotSet ConditionOperator = "is_not_set"
+	// Semver operators compare string attributes such as app_version as semantic versions, so that
+	// 2.9.0 precedes 2.15.0. Attributes or condition values that are not versions fail the condition.
+	ConditionOperatorSemverGt  ConditionOperator = "semver_gt"
+	ConditionOperatorSemverLt  ConditionOperator = "semver_lt"
+	ConditionOperatorSemverGte ConditionOperator = "semver_gte"
+	ConditionOperatorSemverLte ConditionOperator = "semver_lte"
+	ConditionOperatorSemverEq  ConditionOperator = "semver_eq"
 )
 
 // EventType represents the type of event being tracked
@@ -59,25 +199,48 @@ const (
 	ExperimentStatusFinish   = "finish"
 	ExperimentStatusCancel   = "cancel"
 	ExperimentStatusAbort    = "abort"
+	ExperimentStatusPause    = "pause"
 )
 
+// ErrExperimentPaused is returned by Experiment.IsValid for paused experiments
+var ErrExperimentPaused = errors.New("experiment is paused")
+
 // Parameter represents an experiment parameter with rules and conditions
 type Parameter struct {
 	Name                string            `json:"name"`
 	DataType            ParameterDataType `json:"dataType"`
 	DefaultRolloutValue string            `json:"defaultRolloutValue"`
-	Rules               []ParameterRule   `json:"rules"`
+	EnumOptions         []string          `json:"enumOptions,omitempty"`
+	// NumberConstraints are the constraints the backend enforces on the values of a number parameter.
+	// They are informational: the SDK does not check them.
+	NumberConstraints *NumberConstraints `json:"numberConstraints,omitempty"`
+	Rules             []ParameterRule    `json:"rules"`
+}
+
+// NumberConstraints restricts the values of a number parameter. Min and Max are inclusive.
+type NumberConstraints struct {
+	IntegerOnly bool     `json:"integerOnly,omitempty"`
+	Min         *float64 `json:"min,omitempty"`
+	Max         *float64 `json:"max,omitempty"`
 }
 
 // ParameterRule represents a rule for parameter evaluation
 type ParameterRule struct {
-	ID           uint               `json:"id"`
-	Type         RuleType           `json:"type"`
-	MatchType    ConditionMatchType `json:"matchType"`
-	RolloutValue string             `json:"rolloutValue"`
-	SegmentID    *uint              `json:"segmentId,omitempty"`
-	Segment      *Segment           `json:"segment,omitempty"`
-	Conditions   []RuleCondition    `gorm:"foreignKey:RuleID" json:"conditions"`
+	ID             uint               `json:"id"`
+	Type           RuleType           `json:"type"`
+	MatchType      ConditionMatchType `json:"matchType"`
+	ConditionLogic ConditionLogic     `json:"conditionLogic,omitempty"`
+	Priority       int                `json:"priority"`
+	RolloutValue   string             `json:"rolloutValue"`
+	SegmentID      *uint              `json:"segmentId,omitempty"`
+	Segment        *Segment           `json:"segment,omitempty"`
+	Conditions     []RuleCondition    `gorm:"foreignKey:RuleID" json:"conditions"`
+	// RolloutPercentage limits a matching rule to a share of users, bucketed by hashing the
+	// HashAttributeName attribute; nil applies the rule to every matching user
+	RolloutPercentage *int   `json:"rolloutPercentage,omitempty"`
+	HashAttributeName string `json:"hashAttributeName,omitempty"`
+	// Negate applies the rule to users its conditions, or its segment match type, do not match
+	Negate bool `json:"negate,omitempty"`
 }
 
 // RuleCondition represents a condition within a rule
@@ -148,6 +311,8 @@ type Experiment struct {
 	Segment           *Segment            `json:"segment,omitempty"`
 	Variants          []ExperimentVariant `json:"variants"`
 	HashAttributeName string              `json:"hashAttributeName"`
+	// Overrides maps values of the hash attribute to the ID of the variant they are forced into
+	Overrides map[string]int `json:"overrides,omitempty"`
 }
 
 // ExperimentVariant represents a variant within an experiment
@@ -173,19 +338,20 @@ type ExperimentVariantParameter struct {
 
 // EvaluationEvent represents an event that occurred during parameter or experiment evaluation
 type EvaluationEvent struct {
-	ID             string                 `json:"id"`
-	ServiceName    string                 `json:"serviceName"`
-	EventType      EventType              `json:"eventType"`
-	ParameterName  string                 `json:"parameterName"`
-	Source         string                 `json:"source"` // "parameter" or "experiment"
-	UserAttributes map[string]interface{} `json:"userAttributes"`
-	RolloutValue   *string                `json:"rolloutValue,omitempty"`
-	Error          *string                `json:"error,omitempty"`
-	Timestamp      time.Time              `json:"timestamp"`
-	ExperimentID   *int                   `json:"experimentId,omitempty"`
-	ExperimentUUID *string                `json:"experimentUuid,omitempty"`
-	VariantID      *int                   `json:"variantId,omitempty"`
-	VariantName    *string                `json:"variantName,omitempty"`
+	ID                string                 `json:"id"`
+	ServiceName       string                 `json:"serviceName"`
+	EventType         EventType              `json:"eventType"`
+	ParameterName     string                 `json:"parameterName"`
+	Source            string                 `json:"source"` // "parameter", "experiment", "override" or "default"
+	UserAttributes    map[string]interface{} `json:"userAttributes"`
+	RolloutValue      *string                `json:"rolloutValue,omitempty"`
+	Error             *string                `json:"error,omitempty"`
+	Timestamp         time.Time              `json:"timestamp"`
+	ExperimentID      *int                   `json:"experimentId,omitempty"`
+	ExperimentUUID    *string                `json:"experimentUuid,omitempty"`
+	VariantID         *int                   `json:"variantId,omitempty"`
+	VariantName       *string                `json:"variantName,omitempty"`
+	VariantOverridden bool                   `json:"variantOverridden,omitempty"` // variant forced by ForceVariantAttribute
 }
 
 // ExperimentEvaluationResult contains the result of experiment evaluation with metadata
@@ -197,13 +363,159 @@ type ExperimentEvaluationResult struct {
 	ExperimentUUID *string
 	VariantID      *int
 	VariantName    *string
+	// InPopulation reports whether the attributes passed the segment and population checks
+	InPopulation bool
+	// VariantOverridden reports that the variant was forced by ForceVariantAttribute instead of hashed
+	VariantOverridden bool
+	// Overridden reports that the variant was taken from the experiment's overrides
+	Overridden bool
+}
+
+// AssignmentStore persists the variant each unit was assigned in an experiment, so the unit keeps its
+// variant when the experiment's population size changes. Units are identified by the value of the
+// experiment's hash attribute. Implementations must be safe for concurrent use.
+type AssignmentStore interface {
+	// GetAssignment returns the ID of the variant stored for the unit, reporting false when there is none
+	GetAssignment(ctx context.Context, experimentUUID string, unitID string) (int, bool, error)
+	SaveAssignment(ctx context.Context, experimentUUID string, unitID string, variantID int) error
+}
+
+// MetricsCollector receives evaluation and fetch metrics, to be exported to a monitoring system such
+// as Prometheus. Implementations must be safe for concurrent use and should return quickly, since
+// they are called on the evaluation path.
+type MetricsCollector interface {
+	// IncEvaluation counts an evaluation of a parameter; source is "experiment", "parameter" or
+	// "default", or "error" when the evaluation returned an error instead of a value
+	IncEvaluation(source string, parameterName string)
+	// ObserveEvaluationLatency records the time taken by an EvaluateParameter call, or by a whole
+	// EvaluateParameters call
+	ObserveEvaluationLatency(d time.Duration)
+	// IncFetchError counts a failed upstream request or a failure to store fetched data;
+	// kind is the type of the SDK error, such as "network_error" or "throttled"
+	IncFetchError(kind string)
+	// ObserveFetch records the duration of a single upstream request; source is "http", "s3" or "file",
+	// target the requested path, object key or file name, and status "ok", "not_modified" or the type
+	// of the SDK error
+	ObserveFetch(source string, target string, status string, d time.Duration)
+	// ObserveRefresh records the duration of a refresh of the cached data; status is "ok" or the type
+	// of the SDK error. Counts are those of the datasets stored, and negative for datasets left unchanged.
+	ObserveRefresh(d time.Duration, status string, parameterCount int, experimentCount int)
+	// IncStorageReadError counts a failed read of the local storage; reads of missing keys are not counted
+	IncStorageReadError(operation string)
+	// ObserveEventBatch records the size of a batch of evaluation events sent upstream, and whether sending failed
+	ObserveEventBatch(size int, failed bool)
+}
+
+// NoopMetricsCollector discards all metrics, and is used when no collector is configured.
+// Collectors only interested in some metrics can embed it.
+type NoopMetricsCollector struct{}
+
+func (NoopMetricsCollector) IncEvaluation(string, string)                       {}
+func (NoopMetricsCollector) ObserveEvaluationLatency(time.Duration)             {}
+func (NoopMetricsCollector) IncFetchError(string)                               {}
+func (NoopMetricsCollector) ObserveFetch(string, string, string, time.Duration) {}
+func (NoopMetricsCollector) ObserveRefresh(time.Duration, string, int, int)     {}
+func (NoopMetricsCollector) IncStorageReadError(string)                         {}
+func (NoopMetricsCollector) ObserveEventBatch(int, bool)                        {}
+
+// ForceVariantAttribute is the reserved attribute QA can set to the name of a variant to be assigned
+// that variant in every experiment having it, once the segment check passes. It is only honored by
+// clients created with variant overrides allowed.
+const ForceVariantAttribute = "__force_variant__"
+
+// Evaluation sources reported in EvaluationDetails
+const (
+	EvaluationSourceParameter  = "parameter"
+	EvaluationSourceExperiment = "experiment"
+	// EvaluationSourceOverride means the value came from a variant the experiment's overrides force the attributes into
+	EvaluationSourceOverride = "override"
+	// EvaluationSourceDefault means the parameter could not be evaluated and its registered default was returned
+	EvaluationSourceDefault = "default"
+)
+
+// EvaluationReason explains why an evaluation returned its value
+type EvaluationReason string
+
+const (
+	// EvaluationReasonDefault means no rule matched and the parameter's default value was returned
+	EvaluationReasonDefault EvaluationReason = "default"
+	// EvaluationReasonRuleMatch means a rule of the parameter matched the attributes
+	EvaluationReasonRuleMatch EvaluationReason = "rule_match"
+	// EvaluationReasonExperimentVariant means the value came from the variant the attributes were assigned to
+	EvaluationReasonExperimentVariant EvaluationReason = "experiment_variant"
+	// EvaluationReasonNotInPopulation means the parameter has experiments, the attributes were outside
+	// their population and no rule of the parameter matched
+	EvaluationReasonNotInPopulation EvaluationReason = "not_in_population"
+	// EvaluationReasonParameterNotFound means the parameter does not exist
+	EvaluationReasonParameterNotFound EvaluationReason = "parameter_not_found"
+)
+
+// EvaluationDetails describes where an evaluated value came from.
+// The experiment fields are nil when the value falls back to the parameter.
+type EvaluationDetails struct {
+	Source         string  `json:"source"` // "parameter", "experiment", "override" or "default"
+	ExperimentID   *int    `json:"experimentId,omitempty"`
+	ExperimentUUID *string `json:"experimentUuid,omitempty"`
+	VariantID      *int    `json:"variantId,omitempty"`
+	VariantName    *string `json:"variantName,omitempty"`
+	// Trace is only set by traced evaluations
+	Trace *EvaluationTrace `json:"trace,omitempty"`
+}
+
+// EvaluationTrace records the rule and condition decisions of a parameter evaluation, in evaluation
+// order. Rules after the matching one are not evaluated and do not appear.
+type EvaluationTrace struct {
+	MatchedRuleID *uint       `json:"matchedRuleId,omitempty"`
+	Rules         []RuleTrace `json:"rules"`
+}
+
+// RuleTrace records how a parameter rule was evaluated. For segment rules, the conditions of every
+// segment rule are listed with the segment rule they belong to. InRollout is set when the rule matched
+// and has a rollout percentage, and tells whether the user fell within it.
+type RuleTrace struct {
+	RuleID     uint               `json:"ruleId"`
+	Type       RuleType           `json:"type"`
+	MatchType  ConditionMatchType `json:"matchType,omitempty"`
+	SegmentID  *uint              `json:"segmentId,omitempty"`
+	Matched    bool               `json:"matched"`
+	Negated    bool               `json:"negated,omitempty"`
+	InRollout  *bool              `json:"inRollout,omitempty"`
+	Conditions []ConditionTrace   `json:"conditions"`
+}
+
+// ConditionTrace records the outcome of a single condition. Actual is nil when the attributes do not set the attribute.
+type ConditionTrace struct {
+	SegmentRuleID *uint             `json:"segmentRuleId,omitempty"`
+	Attribute     string            `json:"attribute"`
+	Operator      ConditionOperator `json:"operator"`
+	Expected      string            `json:"expected"`
+	Actual        interface{}       `json:"actual"`
+	Matched       bool              `json:"matched"`
 }
 
 // MetadataResponse represents the response from the metadata API
 type MetadataResponse struct {
 	EnableS3 bool `json:"enableS3"`
+	// StorageDegraded is set when the on-disk storage could not be opened and
+	// the client keeps its data in memory instead
+	StorageDegraded bool `json:"storageDegraded"`
 }
 
+// SyncStatus reports the outcome of the client's refreshes, e.g. for application health pages
+type SyncStatus struct {
+	// LastSuccess is when data was last fetched and stored successfully; it is zero until then
+	LastSuccess time.Time
+	// LastError is the error of the last refresh, or nil when it succeeded
+	LastError error
+	// ParameterCount and ExperimentCount are the numbers of parameters and experiments received by the
+	// last successful refreshes
+	ParameterCount  int
+	ExperimentCount int
+}
+
+// APIKeyHeader is the header carrying the API key on requests to the upstream SDK endpoints
+const APIKeyHeader = "X-Aurora-Api-Key"
+
 // UpstreamParametersResponse represents the response from the upstream parameters API
 type UpstreamParametersResponse struct {
 	Parameters []Parameter `json:"parameters"`
@@ -216,8 +528,27 @@ type UpstreamExperimentsResponse struct {
 
 // IsValid validates that an experiment is valid for evaluation
 func (e *Experiment) IsValid() error {
-	// Add validation logic here
-	// For now, just return nil (valid)
+	// Paused experiments keep their assignments but must not expose users
+	if e.Status == ExperimentStatusPause {
+		return fmt.Errorf("experiment %s: %w", e.Name, ErrExperimentPaused)
+	}
+	if e.PopulationSize < 0 || e.PopulationSize > 100 {
+		return fmt.Errorf("experiment %s: population size %d is not between 0 and 100", e.Name, e.PopulationSize)
+	}
+	if len(e.Variants) == 0 {
+		return fmt.Errorf("experiment %s has no variants", e.Name)
+	}
+	// Variants take consecutive ranges of the traffic, which must cover all of it without overlapping
+	total := 0
+	for _, variant := range e.Variants {
+		if variant.TrafficAllocation < 0 {
+			return fmt.Errorf("experiment %s: variant %s has a negative traffic allocation", e.Name, variant.Name)
+		}
+		total += variant.TrafficAllocation
+	}
+	if total != 100 {
+		return fmt.Errorf("experiment %s: variant traffic allocations sum to %d instead of 100", e.Name, total)
+	}
 	return nil
 }
 
@@ -0,0 +1,80 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sdk/pkg/errors"
+	"sdk/types"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Evaluate evaluates a parameter and returns its value as a T, or defaultValue when the parameter cannot
+// be evaluated or its value is not a T. See GetValue for how T is read; to log type mismatches, call
+// EvaluateParameter and GetValue and check TypeMismatch on the evaluated value.
+func Evaluate[T any](ctx context.Context, client Client, parameterName string, attribute *Attribute, defaultValue T) T {
+	rv := client.EvaluateParameter(ctx, parameterName, attribute)
+	return GetValue(&rv, defaultValue)
+}
+
+// GetValue returns the value of rv as a T, or defaultValue when rv has an error or its value is not a T.
+// Strings read string and enum parameters, bool boolean parameters, int and float64 number parameters,
+// time.Time date parameters, and any other type is unmarshalled from a json parameter. A value that is
+// not a T is recorded on rv and reported by its TypeMismatch.
+func GetValue[T any](rv *RolloutValue, defaultValue T) T {
+	rv.typeMismatch = nil
+	if rv.HasError() || rv.value == nil {
+		return defaultValue
+	}
+
+	var value T
+	var err error
+	switch target := any(&value).(type) {
+	case *string:
+		switch {
+		case rv.dataType == types.ParameterDataTypeString:
+			*target = *rv.value
+		case rv.dataType == types.ParameterDataTypeEnum && slices.Contains(rv.enumOptions, *rv.value):
+			*target = *rv.value
+		default:
+			err = errors.NewValidationError("value is not a string", nil)
+		}
+	case *bool:
+		*target, err = parseTyped(rv, types.ParameterDataTypeBoolean, strconv.ParseBool)
+	case *int:
+		*target, err = parseTyped(rv, types.ParameterDataTypeNumber, strconv.Atoi)
+	case *float64:
+		*target, err = parseTyped(rv, types.ParameterDataTypeNumber, func(value string) (float64, error) {
+			return strconv.ParseFloat(value, 64)
+		})
+	case *time.Time:
+		*target, err = parseTyped(rv, types.ParameterDataTypeDate, types.ParseDate)
+	default:
+		err = rv.AsJSON(&value)
+	}
+	if err != nil {
+		rv.typeMismatch = errors.NewValidationError(fmt.Sprintf("value of data type %s cannot be read as %T", rv.dataType, value), err)
+		return defaultValue
+	}
+	return value
+}
+
+// parseTyped parses the value of rv when it has the given data type
+func parseTyped[T any](rv *RolloutValue, dataType types.ParameterDataType, parse func(string) (T, error)) (T, error) {
+	if rv.dataType != dataType {
+		var zero T
+		return zero, errors.NewValidationError(fmt.Sprintf("value has data type %s, not %s", rv.dataType, dataType), nil)
+	}
+	return parse(*rv.value)
+}
+
+// BindStruct unmarshals the value of a json parameter into target, which must be a pointer to a struct
+func BindStruct(result RolloutValue, target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.NewValidationError(fmt.Sprintf("target must be a non-nil pointer to a struct, got %T", target), nil)
+	}
+	return result.AsJSON(target)
+}